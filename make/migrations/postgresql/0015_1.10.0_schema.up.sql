@@ -57,4 +57,195 @@ DROP TABLE IF EXISTS img_scan_job;
 DROP TRIGGER IF EXISTS TRIGGER ON img_scan_overview;
 DROP TABLE IF EXISTS img_scan_overview;
 
-DROP TABLE IF EXISTS clair_vuln_timestamp
\ No newline at end of file
+DROP TABLE IF EXISTS clair_vuln_timestamp;
+
+/** Add table for the history of project configuration **/
+CREATE TABLE project_config_history
+(
+  id          SERIAL PRIMARY KEY NOT NULL,
+  project_id  int NOT NULL,
+  changed_at  timestamp default CURRENT_TIMESTAMP,
+  changed_by  varchar(255),
+  diff        text
+);
+
+CREATE INDEX idx_project_config_history_project_id ON project_config_history (project_id);
+
+/** Add index to look up the artifacts by the layer digest **/
+CREATE INDEX idx_artifact_blob_digest_blob ON artifact_blob (digest_blob);
+
+/** Add table for the audit log of the admin job schedules **/
+CREATE TABLE schedule_audit_log
+(
+  id          SERIAL PRIMARY KEY NOT NULL,
+  actor       varchar(255),
+  job_name    varchar(64) NOT NULL,
+  old_cron    varchar(256),
+  new_cron    varchar(256),
+  changed_at  timestamp default CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_schedule_audit_log_job_name ON schedule_audit_log (job_name);
+
+/** Add the content hash of the check-in data to deduplicate the check-ins of scan report **/
+ALTER TABLE scan_report ADD COLUMN checkin_hash varchar(64) DEFAULT '' NOT NULL;
+CREATE INDEX idx_scan_report_checkin_hash ON scan_report (checkin_hash);
+
+/** Add table for the delivery logs of webhook **/
+CREATE TABLE webhook_delivery_log (
+  id SERIAL NOT NULL,
+  policy_id int NOT NULL,
+  job_id int NOT NULL,
+  /* status_code is 0 if no response is received from the target */
+  status_code int NOT NULL DEFAULT 0,
+  duration_ms int NOT NULL DEFAULT 0,
+  success boolean NOT NULL DEFAULT false,
+  creation_time timestamp default CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_webhook_delivery_log_policy_time ON webhook_delivery_log (policy_id, creation_time);
+
+/** Add the last login time and the disabled flag of user **/
+ALTER TABLE harbor_user ADD COLUMN last_login_at timestamp;
+ALTER TABLE harbor_user ADD COLUMN disabled boolean DEFAULT false NOT NULL;
+
+/** Add table for the audit log of the changes of user accounts **/
+CREATE TABLE user_audit_log
+(
+  id          SERIAL PRIMARY KEY NOT NULL,
+  actor       varchar(255),
+  user_id     int NOT NULL,
+  username    varchar(255),
+  action      varchar(32) NOT NULL,
+  op_time     timestamp default CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_audit_log_user_id ON user_audit_log (user_id);
+
+/** Add the parameters of admin job to re-submit the job which failed to be submitted to the job service **/
+ALTER TABLE admin_job ADD COLUMN job_parameters varchar(255) DEFAULT '' NOT NULL;
+
+/** Add index to look up the tags by prefix for the tag suggestions **/
+CREATE INDEX idx_artifact_tag_prefix ON artifact (project_id, repo, tag varchar_pattern_ops);
+
+/** Add table for the keys signing the tokens issued by Harbor, e.g. the robot account tokens, during the key rotation **/
+CREATE TABLE token_signing_key
+(
+  id            SERIAL PRIMARY KEY NOT NULL,
+  /* the private key in PEM format encrypted by the secret key */
+  private_key   text NOT NULL,
+  /* active or retiring, there is at most one key in each status */
+  status        varchar(16) NOT NULL,
+  creation_time timestamp default CURRENT_TIMESTAMP,
+  CONSTRAINT unique_token_signing_key_status UNIQUE (status)
+);
+
+/** Add column to record the mode the image blobs are transferred in by the replication task, "copy" or "mount" **/
+ALTER TABLE replication_task ADD COLUMN transfer_mode varchar(16) DEFAULT '' NOT NULL;
+
+/** Add column to record the last time the robot account is authenticated successfully **/
+ALTER TABLE robot ADD COLUMN last_access_time timestamp;
+
+/** Add table for the API keys of users, only the hash of the key is stored **/
+CREATE TABLE user_api_key
+(
+  id            SERIAL PRIMARY KEY NOT NULL,
+  user_id       int NOT NULL,
+  name          varchar(255) NOT NULL,
+  key_hash      varchar(64) NOT NULL,
+  /* the unix timestamp after which the key is expired, 0 means never */
+  expires_at    bigint DEFAULT 0 NOT NULL,
+  creation_time timestamp default CURRENT_TIMESTAMP,
+  update_time   timestamp default CURRENT_TIMESTAMP,
+  FOREIGN KEY (user_id) REFERENCES harbor_user(user_id),
+  CONSTRAINT unique_user_api_key_hash UNIQUE (key_hash),
+  CONSTRAINT unique_user_api_key_name UNIQUE (user_id, name)
+);
+
+/** Add the report polling settings of the scanner registrations, 0 means the defaults of the scan job **/
+ALTER TABLE scanner_registration ADD COLUMN IF NOT EXISTS report_check_timeout bigint NOT NULL DEFAULT 0;
+ALTER TABLE scanner_registration ADD COLUMN IF NOT EXISTS report_check_interval bigint NOT NULL DEFAULT 0;
+
+/** Add the max number of the concurrent scans of the scanner registrations, 0 means unlimited **/
+ALTER TABLE scanner_registration ADD COLUMN IF NOT EXISTS max_concurrent_scans bigint NOT NULL DEFAULT 0;
+
+/** Add the severity threshold of the scan completed event of the notification policies, empty means always sending it **/
+ALTER TABLE notification_policy ADD COLUMN IF NOT EXISTS scan_severity_threshold varchar(16) NOT NULL DEFAULT '';
+
+/** Add the producer of the scan reports, the schema version is 0 for the reports checked in by the previous versions **/
+ALTER TABLE scan_report ADD COLUMN IF NOT EXISTS schema_version int NOT NULL DEFAULT 0;
+ALTER TABLE scan_report ADD COLUMN IF NOT EXISTS scanner_name varchar(128) NOT NULL DEFAULT '';
+ALTER TABLE scan_report ADD COLUMN IF NOT EXISTS scanner_version varchar(64) NOT NULL DEFAULT '';
+
+/** Add the name of the schedules of admin job so one job can have multiple schedules, the existing schedules become the default one whose name is empty **/
+ALTER TABLE admin_job ADD COLUMN IF NOT EXISTS schedule_name varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE schedule_audit_log ADD COLUMN IF NOT EXISTS schedule_name varchar(64) NOT NULL DEFAULT '';
+
+/** Index the executions of admin job by the status and the update time to filter them efficiently **/
+CREATE INDEX IF NOT EXISTS idx_admin_job_name_status_update_time ON admin_job (job_name, status, update_time);
+
+/** Add the summary of the admin job execution, e.g. the blobs found by the dry run of GC, stored as JSON **/
+ALTER TABLE admin_job ADD COLUMN IF NOT EXISTS summary JSON;
+
+/** Add the summary of the results of the retention task, e.g. the deleted number and the reclaimed size, stored as JSON **/
+ALTER TABLE retention_task ADD COLUMN IF NOT EXISTS summary text NOT NULL DEFAULT '';
+
+/** Add the retry policy of the scan jobs to the scanner registration, the one of the scan job is used by default **/
+ALTER TABLE scanner_registration ADD COLUMN IF NOT EXISTS max_retries int NOT NULL DEFAULT 0;
+ALTER TABLE scanner_registration ADD COLUMN IF NOT EXISTS retry_disabled boolean NOT NULL DEFAULT false;
+
+/** Add the platform of the scan report as the multi-arch artifact is scanned per platform, it's empty for the single platform artifact **/
+ALTER TABLE scan_report ADD COLUMN IF NOT EXISTS platform varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE scan_report DROP CONSTRAINT IF EXISTS scan_report_digest_registration_uuid_mime_type_key;
+ALTER TABLE scan_report ADD CONSTRAINT scan_report_digest_registration_uuid_mime_type_platform_key UNIQUE (digest, registration_uuid, mime_type, platform);
+
+/** Add the statistics of the admin job execution checked in when it completes, e.g. the blobs deleted and the bytes freed by GC, stored as JSON **/
+ALTER TABLE admin_job ADD COLUMN IF NOT EXISTS stats JSON;
+
+/** Add the token ID to the robot account to rotate its token, 0 means the robot ID is used, the previous token ID keeps validating during the overlap window of the rotation **/
+ALTER TABLE robot ADD COLUMN IF NOT EXISTS token_id bigint NOT NULL DEFAULT 0;
+ALTER TABLE robot ADD COLUMN IF NOT EXISTS previous_token_id bigint NOT NULL DEFAULT 0;
+ALTER TABLE robot ADD COLUMN IF NOT EXISTS previous_token_expires_at bigint NOT NULL DEFAULT 0;
+
+/** Add the access of the robot account stored as JSON to issue the new token when rotating **/
+ALTER TABLE robot ADD COLUMN IF NOT EXISTS access text NOT NULL DEFAULT '';
+
+/** Bind the users onboarded via the SAML IdP to the issuer and the subject asserted by the IdP, they are never matched by the username **/
+CREATE TABLE IF NOT EXISTS saml_user (
+ id SERIAL NOT NULL,
+ user_id int NOT NULL,
+ issuer varchar(255) NOT NULL,
+ subject varchar(255) NOT NULL,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ PRIMARY KEY (id),
+ FOREIGN KEY (user_id) REFERENCES harbor_user(user_id),
+ UNIQUE (issuer, subject),
+ UNIQUE (user_id)
+);
+
+/** Add table for the internal secrets rotated via the API so that all the core instances load them, the secret is encrypted by the secret key **/
+CREATE TABLE IF NOT EXISTS internal_secret (
+ id SERIAL NOT NULL,
+ username varchar(64) NOT NULL,
+ secret text NOT NULL,
+ /* null for the current secret, the previous one is accepted until the time */
+ expires_at timestamp,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ PRIMARY KEY (id)
+);
+
+/** Add table for the last conflict analysis of the retention policy, so it's shared by the core instances, the result is stored as JSON **/
+CREATE TABLE IF NOT EXISTS retention_conflict_analysis (
+ id SERIAL NOT NULL,
+ policy_id integer NOT NULL,
+ /* identifies the run of the analysis, the result of the replaced run is dropped */
+ run_id varchar(64) NOT NULL,
+ status varchar(20) NOT NULL,
+ start_time timestamp NOT NULL,
+ end_time timestamp,
+ error text NOT NULL DEFAULT '',
+ result text NOT NULL DEFAULT '',
+ PRIMARY KEY (id),
+ UNIQUE (policy_id)
+);
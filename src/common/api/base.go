@@ -239,6 +239,11 @@ func (b *BaseAPI) SendPreconditionFailedError(err error) {
 	b.RenderFormattedError(http.StatusPreconditionFailed, err.Error())
 }
 
+// SendTooManyRequestsError sends too many requests error to the client.
+func (b *BaseAPI) SendTooManyRequestsError(err error) {
+	b.RenderFormattedError(http.StatusTooManyRequests, err.Error())
+}
+
 // SendStatusServiceUnavailableError sends service unavailable error to the client.
 func (b *BaseAPI) SendStatusServiceUnavailableError(err error) {
 	b.RenderFormattedError(http.StatusServiceUnavailable, err.Error())
@@ -46,6 +46,7 @@ const (
 	UAAGroup       = "uaa"
 	HTTPAuthGroup  = "http_auth"
 	OIDCGroup      = "oidc"
+	SAMLGroup      = "saml"
 	DatabaseGroup  = "database"
 	QuotaGroup     = "quota"
 	// Put all config items do not belong a existing group into basic
@@ -83,6 +84,7 @@ var (
 		{Name: common.EmailHost, Scope: UserScope, Group: EmailGroup, EnvKey: "EMAIL_HOST", DefaultValue: "smtp.mydomain.com", ItemType: &StringType{}, Editable: false},
 		{Name: common.EmailIdentity, Scope: UserScope, Group: EmailGroup, EnvKey: "EMAIL_IDENTITY", DefaultValue: "", ItemType: &StringType{}, Editable: false},
 		{Name: common.EmailInsecure, Scope: UserScope, Group: EmailGroup, EnvKey: "EMAIL_INSECURE", DefaultValue: "false", ItemType: &BoolType{}, Editable: false},
+		{Name: common.RiskScoreWeights, Scope: UserScope, Group: BasicGroup, EnvKey: "RISK_SCORE_WEIGHTS", DefaultValue: `{"scan_coverage_percent":25,"critical_cve_density":25,"unsigned_images_percent":25,"policy_compliance_score":25}`, ItemType: &MapType{}, Editable: true},
 		{Name: common.EmailPassword, Scope: UserScope, Group: EmailGroup, EnvKey: "EMAIL_PWD", DefaultValue: "", ItemType: &PasswordType{}, Editable: false},
 		{Name: common.EmailPort, Scope: UserScope, Group: EmailGroup, EnvKey: "EMAIL_PORT", DefaultValue: "25", ItemType: &PortType{}, Editable: false},
 		{Name: common.EmailSSL, Scope: UserScope, Group: EmailGroup, EnvKey: "EMAIL_SSL", DefaultValue: "false", ItemType: &BoolType{}, Editable: false},
@@ -139,6 +141,8 @@ var (
 		{Name: common.HTTPAuthProxyTokenReviewEndpoint, Scope: UserScope, Group: HTTPAuthGroup, ItemType: &StringType{}},
 		{Name: common.HTTPAuthProxyVerifyCert, Scope: UserScope, Group: HTTPAuthGroup, DefaultValue: "true", ItemType: &BoolType{}},
 		{Name: common.HTTPAuthProxySkipSearch, Scope: UserScope, Group: HTTPAuthGroup, DefaultValue: "false", ItemType: &BoolType{}},
+		{Name: common.HTTPAuthProxyAutoOnboard, Scope: UserScope, Group: HTTPAuthGroup, DefaultValue: "false", ItemType: &BoolType{}},
+		{Name: common.HTTPAuthProxyAPIAccess, Scope: UserScope, Group: HTTPAuthGroup, DefaultValue: "false", ItemType: &BoolType{}},
 
 		{Name: common.OIDCName, Scope: UserScope, Group: OIDCGroup, ItemType: &StringType{}},
 		{Name: common.OIDCEndpoint, Scope: UserScope, Group: OIDCGroup, ItemType: &StringType{}},
@@ -147,6 +151,15 @@ var (
 		{Name: common.OIDCGroupsClaim, Scope: UserScope, Group: OIDCGroup, ItemType: &StringType{}},
 		{Name: common.OIDCScope, Scope: UserScope, Group: OIDCGroup, ItemType: &StringType{}},
 		{Name: common.OIDCVerifyCert, Scope: UserScope, Group: OIDCGroup, DefaultValue: "true", ItemType: &BoolType{}},
+		// whether the client ID and secret are registered dynamically
+		{Name: common.OIDCClientDynamic, Scope: UserScope, Group: OIDCGroup, DefaultValue: "false", ItemType: &BoolType{}},
+
+		{Name: common.SAMLIdPSSOURL, Scope: UserScope, Group: SAMLGroup, ItemType: &StringType{}},
+		{Name: common.SAMLIdPCertificate, Scope: UserScope, Group: SAMLGroup, ItemType: &StringType{}},
+		// the entity ID of Harbor as the service provider, the URL of the SP metadata is used if it's empty
+		{Name: common.SAMLSPEntityID, Scope: UserScope, Group: SAMLGroup, ItemType: &StringType{}},
+		// maps the fields of Harbor user to the names of the SAML attributes
+		{Name: common.SAMLAttributeMapping, Scope: UserScope, Group: SAMLGroup, DefaultValue: `{"username":"uid","email":"mail","realname":"cn"}`, ItemType: &MapType{}},
 
 		{Name: common.WithChartMuseum, Scope: SystemScope, Group: BasicGroup, EnvKey: "WITH_CHARTMUSEUM", DefaultValue: "false", ItemType: &BoolType{}, Editable: true},
 		{Name: common.WithClair, Scope: SystemScope, Group: BasicGroup, EnvKey: "WITH_CLAIR", DefaultValue: "false", ItemType: &BoolType{}, Editable: true},
@@ -154,6 +167,34 @@ var (
 		// the unit of expiration is minute, 43200 minutes = 30 days
 		{Name: common.RobotTokenDuration, Scope: UserScope, Group: BasicGroup, EnvKey: "ROBOT_TOKEN_DURATION", DefaultValue: "43200", ItemType: &IntType{}, Editable: true},
 		{Name: common.NotificationEnable, Scope: UserScope, Group: BasicGroup, EnvKey: "NOTIFICATION_ENABLE", DefaultValue: "true", ItemType: &BoolType{}, Editable: true},
+		{Name: common.AdminJobNotificationEnable, Scope: UserScope, Group: BasicGroup, EnvKey: "ADMIN_JOB_NOTIFICATION_ENABLE", DefaultValue: "false", ItemType: &BoolType{}, Editable: true},
+		{Name: common.AdminJobNotificationStatuses, Scope: UserScope, Group: BasicGroup, EnvKey: "ADMIN_JOB_NOTIFICATION_STATUSES", DefaultValue: "error,stopped", ItemType: &StringType{}, Editable: true},
+		{Name: common.AdminJobNotificationAddress, Scope: UserScope, Group: BasicGroup, EnvKey: "ADMIN_JOB_NOTIFICATION_ADDRESS", DefaultValue: "", ItemType: &StringType{}, Editable: true},
+		{Name: common.AllowImpersonation, Scope: UserScope, Group: BasicGroup, EnvKey: "ALLOW_IMPERSONATION", DefaultValue: "false", ItemType: &BoolType{}, Editable: true},
+		{Name: common.AllowAdminOverride, Scope: SystemScope, Group: BasicGroup, EnvKey: "ALLOW_ADMIN_OVERRIDE", DefaultValue: "false", ItemType: &BoolType{}, Editable: false},
+		// the unit is second
+		{Name: common.BasicAuthCacheTTL, Scope: SystemScope, Group: BasicGroup, EnvKey: "BASIC_AUTH_CACHE_TTL", DefaultValue: "30", ItemType: &IntType{}, Editable: false},
+		{Name: common.BasicAuthMaxFailures, Scope: SystemScope, Group: BasicGroup, EnvKey: "BASIC_AUTH_MAX_FAILURES", DefaultValue: "10", ItemType: &IntType{}, Editable: false},
+		{Name: common.BasicAuthFailureWindow, Scope: SystemScope, Group: BasicGroup, EnvKey: "BASIC_AUTH_FAILURE_WINDOW", DefaultValue: "60", ItemType: &IntType{}, Editable: false},
+		{Name: common.BasicAuthLockout, Scope: SystemScope, Group: BasicGroup, EnvKey: "BASIC_AUTH_LOCKOUT", DefaultValue: "300", ItemType: &IntType{}, Editable: false},
+		{Name: common.TrustedProxies, Scope: SystemScope, Group: BasicGroup, EnvKey: "TRUSTED_PROXIES", DefaultValue: "", ItemType: &StringType{}, Editable: false},
+		{Name: common.SecurityFilterSkipPaths, Scope: SystemScope, Group: BasicGroup, EnvKey: "SECURITY_FILTER_SKIP_PATHS", DefaultValue: "/api/ping,/api/health,/metrics", ItemType: &StringType{}, Editable: false},
+		{Name: common.ReadOnlyAllowedPaths, Scope: SystemScope, Group: BasicGroup, EnvKey: "READ_ONLY_ALLOWED_PATHS", DefaultValue: "/c/login,/c/oidc/*,/c/saml/callback,/api/configurations,/service/notifications*", ItemType: &StringType{}, Editable: false},
+		// the unit is second
+		{Name: common.ReadOnlyRetryAfter, Scope: SystemScope, Group: BasicGroup, EnvKey: "READ_ONLY_RETRY_AFTER", DefaultValue: "300", ItemType: &IntType{}, Editable: false},
+		{Name: common.AnonymousAccessCIDRs, Scope: SystemScope, Group: BasicGroup, EnvKey: "ANONYMOUS_ACCESS_CIDRS", DefaultValue: "", ItemType: &StringType{}, Editable: false},
+		{Name: common.ScanReportCacheTTL, Scope: SystemScope, Group: BasicGroup, EnvKey: "SCAN_REPORT_CACHE_TTL", DefaultValue: "0", ItemType: &IntType{}, Editable: false},
+		{Name: common.DisableBasicAuthAPI, Scope: SystemScope, Group: BasicGroup, EnvKey: "DISABLE_BASIC_AUTH_API", DefaultValue: "false", ItemType: &BoolType{}, Editable: false},
+		{Name: common.ClientCertAuth, Scope: SystemScope, Group: BasicGroup, EnvKey: "CLIENT_CERT_AUTH", DefaultValue: "false", ItemType: &BoolType{}, Editable: false},
+		{Name: common.ClientCertMapping, Scope: SystemScope, Group: BasicGroup, EnvKey: "CLIENT_CERT_MAPPING", DefaultValue: "{}", ItemType: &MapType{}, Editable: false},
+		{Name: common.ReqCtxModifiers, Scope: SystemScope, Group: BasicGroup, EnvKey: "REQ_CTX_MODIFIERS", DefaultValue: "", ItemType: &StringType{}, Editable: false},
+		{Name: common.AuthMetricsEnabled, Scope: SystemScope, Group: BasicGroup, EnvKey: "AUTH_METRICS_ENABLED", DefaultValue: "false", ItemType: &BoolType{}, Editable: false},
+		// the unit is second
+		{Name: common.InternalSecretGracePeriod, Scope: SystemScope, Group: BasicGroup, EnvKey: "INTERNAL_SECRET_GRACE_PERIOD", DefaultValue: "300", ItemType: &IntType{}, Editable: false},
+		// the unit is second
+		{Name: common.RobotTokenClockSkew, Scope: SystemScope, Group: BasicGroup, EnvKey: "ROBOT_TOKEN_CLOCK_SKEW", DefaultValue: "0", ItemType: &IntType{}, Editable: false},
+		{Name: common.AdminJobRetentionCount, Scope: SystemScope, Group: BasicGroup, EnvKey: "ADMIN_JOB_RETENTION_COUNT", DefaultValue: "100", ItemType: &IntType{}, Editable: false},
+		{Name: common.AdminJobRetentionDays, Scope: SystemScope, Group: BasicGroup, EnvKey: "ADMIN_JOB_RETENTION_DAYS", DefaultValue: "0", ItemType: &IntType{}, Editable: false},
 
 		{Name: common.QuotaPerProjectEnable, Scope: UserScope, Group: QuotaGroup, EnvKey: "QUOTA_PER_PROJECT_ENABLE", DefaultValue: "true", ItemType: &BoolType{}, Editable: true},
 		{Name: common.CountPerProject, Scope: UserScope, Group: QuotaGroup, EnvKey: "COUNT_PER_PROJECT", DefaultValue: "-1", ItemType: &QuotaType{}, Editable: true},
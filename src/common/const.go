@@ -23,6 +23,7 @@ const (
 	UAAAuth             = "uaa_auth"
 	HTTPAuth            = "http_auth"
 	OIDCAuth            = "oidc_auth"
+	SAMLAuth            = "saml_auth"
 	ProCrtRestrEveryone = "everyone"
 	ProCrtRestrAdmOnly  = "adminonly"
 	LDAPScopeBase       = 0
@@ -104,6 +105,8 @@ const (
 	HTTPAuthProxyTokenReviewEndpoint = "http_authproxy_tokenreview_endpoint"
 	HTTPAuthProxyVerifyCert          = "http_authproxy_verify_cert"
 	HTTPAuthProxySkipSearch          = "http_authproxy_skip_search"
+	HTTPAuthProxyAutoOnboard         = "http_authproxy_auto_onboard"
+	HTTPAuthProxyAPIAccess           = "http_authproxy_api_access"
 	OIDCName                         = "oidc_name"
 	OIDCEndpoint                     = "oidc_endpoint"
 	OIDCCLientID                     = "oidc_client_id"
@@ -111,6 +114,11 @@ const (
 	OIDCVerifyCert                   = "oidc_verify_cert"
 	OIDCGroupsClaim                  = "oidc_groups_claim"
 	OIDCScope                        = "oidc_scope"
+	OIDCClientDynamic                = "oidc_client_dynamic"
+	SAMLIdPSSOURL                    = "saml_idp_sso_url"
+	SAMLIdPCertificate               = "saml_idp_certificate"
+	SAMLSPEntityID                   = "saml_sp_entity_id"
+	SAMLAttributeMapping             = "saml_attribute_mapping"
 
 	DefaultClairEndpoint              = "http://clair:6060"
 	CfgDriverDB                       = "db"
@@ -147,12 +155,75 @@ const (
 	OIDCCallbackPath = "/c/oidc/callback"
 	OIDCLoginPath    = "/c/oidc/login"
 
+	SAMLLoginPath    = "/c/saml/login"
+	SAMLCallbackPath = "/c/saml/callback"
+	SAMLMetadataPath = "/c/saml/metadata"
+
 	ChartUploadCtxKey   = contextKey("chart_upload_event")
 	ChartDownloadCtxKey = contextKey("chart_download_event")
 
 	// Global notification enable configuration
 	NotificationEnable = "notification_enable"
 
+	// AllowImpersonation enables the system admin to impersonate the other users
+	AllowImpersonation = "allow_impersonation"
+	// ImpersonationTokenHeader is the header carrying the impersonation token
+	ImpersonationTokenHeader = "X-Harbor-Impersonation-Token"
+	// APIKeyHeader is the header carrying the API key of user
+	APIKeyHeader = "X-Harbor-API-Key"
+	// AllowAdminOverride enables the system admin to override the security context in debug mode
+	AllowAdminOverride = "allow_admin_override"
+	// OverrideUserHeader is the header carrying the signed override token
+	OverrideUserHeader = "X-Harbor-Override-User"
+	// BasicAuthCacheTTL is how long in seconds the users authenticated by basic auth are cached, 0 disables the cache
+	BasicAuthCacheTTL = "basic_auth_cache_ttl"
+	// RobotTokenClockSkew is the grace period in seconds allowed for the clock skew when validating the time of robot tokens
+	RobotTokenClockSkew = "robot_token_clock_skew"
+	// ClientCertAuth enables the authentication by the verified client certificate
+	ClientCertAuth = "client_cert_auth"
+	// ClientCertMapping is the JSON map from the CN or SAN of the client certificate to the name of user or robot account
+	ClientCertMapping = "client_cert_mapping"
+	// ReqCtxModifiers is the comma separated names of the modifiers chained in order in the security filter
+	ReqCtxModifiers = "req_ctx_modifiers"
+	// AuthMetricsEnabled enables the metrics of the authentication outcomes of the security filter
+	AuthMetricsEnabled = "auth_metrics_enabled"
+	// BasicAuthMaxFailures is the max number of failed basic auth attempts of one IP within the window, 0 disables the limit
+	BasicAuthMaxFailures = "basic_auth_max_failures"
+	// BasicAuthFailureWindow is the window in seconds in which the failed basic auth attempts are counted
+	BasicAuthFailureWindow = "basic_auth_failure_window"
+	// BasicAuthLockout is how long in seconds the IP is locked out once the failed basic auth attempts reach the limit
+	BasicAuthLockout = "basic_auth_lockout"
+	// TrustedProxies is the comma separated IPs or CIDRs of the proxies whose X-Forwarded-For header is honored
+	TrustedProxies = "trusted_proxies"
+	// SecurityFilterSkipPaths is the comma separated paths skipped by the security filter, only the ones requiring no authorization take effect
+	SecurityFilterSkipPaths = "security_filter_skip_paths"
+	// AnonymousAccessCIDRs is the comma separated IPs or CIDRs from which the anonymous access is allowed, empty means no restriction
+	AnonymousAccessCIDRs = "anonymous_access_cidrs"
+	// DisableBasicAuthAPI disables the basic auth for the requests other than the ones of the token service and registry
+	DisableBasicAuthAPI = "disable_basic_auth_api"
+	// ScanReportCacheTTL is the seconds within which the report of the digest is reused instead of scanning again, 0 means no reuse
+	ScanReportCacheTTL = "scan_report_cache_ttl"
+	// InternalSecretGracePeriod is how long in seconds the previous internal secret is still valid after the rotation
+	InternalSecretGracePeriod = "internal_secret_grace_period"
+	// ReadOnlyAllowedPaths is the comma separated paths which can still be modified in read only mode, the path ending with "*" matches the prefix
+	ReadOnlyAllowedPaths = "read_only_allowed_paths"
+	// ReadOnlyRetryAfter is the seconds in the Retry-After header of the requests rejected in read only mode
+	ReadOnlyRetryAfter = "read_only_retry_after"
+	// HarborErrorHeader is the header carrying the reason why the request is rejected
+	HarborErrorHeader = "X-Harbor-Error"
+	// RiskScoreWeights is the JSON map of the weights of the factors combined into the risk score of project
+	RiskScoreWeights = "risk_score_weights"
+	// AdminJobRetentionCount is how many finished executions are kept per admin job, 0 means no limit
+	AdminJobRetentionCount = "admin_job_retention_count"
+	// AdminJobRetentionDays is how many days the finished executions of admin job are kept, 0 means no limit
+	AdminJobRetentionDays = "admin_job_retention_days"
+	// AdminJobNotificationEnable enables the notification of the status changes of admin jobs
+	AdminJobNotificationEnable = "admin_job_notification_enable"
+	// AdminJobNotificationStatuses is the comma separated statuses of admin job which trigger the notification
+	AdminJobNotificationStatuses = "admin_job_notification_statuses"
+	// AdminJobNotificationAddress is the webhook address the notification of admin jobs is sent to
+	AdminJobNotificationAddress = "admin_job_notification_address"
+
 	// Quota setting items for project
 	QuotaPerProjectEnable = "quota_per_project_enable"
 	CountPerProject       = "count_per_project"
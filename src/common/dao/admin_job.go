@@ -15,6 +15,7 @@
 package dao
 
 import (
+	"errors"
 	"time"
 
 	"github.com/astaxie/beego/orm"
@@ -22,16 +23,59 @@ import (
 	"github.com/goharbor/harbor/src/common/utils/log"
 )
 
+// ErrAdminJobLimitReached is returned when the max number of the active admin jobs is reached
+var ErrAdminJobLimitReached = errors.New("the max number of the active admin jobs is reached")
+
+// AdminJobStaleAfter is the duration after which the active admin job isn't updated is considered stale,
+// e.g. the job service is restarted without reporting its status, the stale jobs don't take the slots
+// of the concurrency limit
+var AdminJobStaleAfter = 24 * time.Hour
+
 // AddAdminJob ...
 func AddAdminJob(job *models.AdminJob) (int64, error) {
-	o := GetOrmer()
+	return addAdminJob(GetOrmer(), job)
+}
+
+// AddAdminJobWithLimit adds the admin job only if less than max jobs with the same name and kind
+// are active, i.e. waiting to run or running and updated within AdminJobStaleAfter, otherwise
+// ErrAdminJobLimitReached is returned.
+// The count and the insertion are serialized per job name by a transaction level advisory lock,
+// so the concurrent submissions can't exceed the limit
+func AddAdminJobWithLimit(job *models.AdminJob, max int) (int64, error) {
+	var id int64
+	err := WithTransaction(func(o orm.Ormer) error {
+		if _, err := o.Raw(`select pg_advisory_xact_lock(hashtext(?))`, "admin_job:"+job.Name).Exec(); err != nil {
+			return err
+		}
+		var count int
+		if err := o.Raw(`select count(*) from admin_job
+			where job_name = ? and job_kind = ? and status in (?, ?, ?, ?) and deleted = false
+			and update_time > ?`,
+			job.Name, job.Kind, models.JobPending, models.JobPendingSubmission, models.JobRunning,
+			models.JobRetrying, time.Now().Add(-AdminJobStaleAfter)).QueryRow(&count); err != nil {
+			return err
+		}
+		if count >= max {
+			return ErrAdminJobLimitReached
+		}
+		var err error
+		id, err = addAdminJob(o, job)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func addAdminJob(o orm.Ormer, job *models.AdminJob) (int64, error) {
 	if len(job.Status) == 0 {
 		job.Status = models.JobPending
 	}
-	sql := "insert into admin_job (job_name, job_kind, status, job_uuid, cron_str, creation_time, update_time) values (?, ?, ?, ?, ?, ?, ?) RETURNING id"
+	sql := "insert into admin_job (job_name, job_kind, status, job_uuid, cron_str, job_parameters, schedule_name, creation_time, update_time) values (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id"
 	var id int64
 	now := time.Now()
-	err := o.Raw(sql, job.Name, job.Kind, job.Status, job.UUID, job.Cron, now, now).QueryRow(&id)
+	err := o.Raw(sql, job.Name, job.Kind, job.Status, job.UUID, job.Cron, job.Parameters, job.ScheduleName, now, now).QueryRow(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -50,6 +94,44 @@ func GetAdminJob(id int64) (*models.AdminJob, error) {
 	return &aj, nil
 }
 
+// GetRunningAdminJobsByName returns the count of the running admin jobs with the name
+func GetRunningAdminJobsByName(name string) (int, error) {
+	var count int
+	err := GetOrmer().Raw(`select count(*) from admin_job
+		where job_name = ? and status = ? and deleted = false`, name, models.JobRunning).QueryRow(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetPendingSubmissionJobs returns the admin jobs which failed to be submitted to the job service
+func GetPendingSubmissionJobs() ([]*models.AdminJob, error) {
+	jobs := []*models.AdminJob{}
+	_, err := GetOrmer().Raw(`select * from admin_job
+		where status = ? and deleted = false order by id`, models.JobPendingSubmission).QueryRows(&jobs)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ClaimPendingSubmissionJob moves the admin job out of the pending submission status, it
+// returns false if the job isn't pending submission, e.g. it has been claimed by others
+func ClaimPendingSubmissionJob(id int64) (bool, error) {
+	r, err := GetOrmer().Raw(`update admin_job set status = ?, update_time = ?
+		where id = ? and status = ? and deleted = false`,
+		models.JobPending, time.Now(), id, models.JobPendingSubmission).Exec()
+	if err != nil {
+		return false, err
+	}
+	n, err := r.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
 // DeleteAdminJob ...
 func DeleteAdminJob(id int64) error {
 	o := GetOrmer()
@@ -73,6 +155,51 @@ func UpdateAdminJobStatus(id int64, status string) error {
 	return err
 }
 
+// UpdateAdminJobCron ...
+func UpdateAdminJobCron(id int64, cron string) error {
+	o := GetOrmer()
+	j := models.AdminJob{
+		ID:         id,
+		Cron:       cron,
+		UpdateTime: time.Now(),
+	}
+	n, err := o.Update(&j, "Cron", "UpdateTime")
+	if n == 0 {
+		log.Warningf("no records are updated when updating admin job %d", id)
+	}
+	return err
+}
+
+// UpdateAdminJobSummary updates the summary of the admin job, the summary must be a JSON string
+func UpdateAdminJobSummary(id int64, summary string) error {
+	o := GetOrmer()
+	j := models.AdminJob{
+		ID:         id,
+		Summary:    summary,
+		UpdateTime: time.Now(),
+	}
+	n, err := o.Update(&j, "Summary", "UpdateTime")
+	if n == 0 {
+		log.Warningf("no records are updated when updating admin job %d", id)
+	}
+	return err
+}
+
+// UpdateAdminJobStats updates the statistics of the execution of the admin job, the stats must be a JSON string
+func UpdateAdminJobStats(id int64, stats string) error {
+	o := GetOrmer()
+	j := models.AdminJob{
+		ID:         id,
+		Stats:      stats,
+		UpdateTime: time.Now(),
+	}
+	n, err := o.Update(&j, "Stats", "UpdateTime")
+	if n == 0 {
+		log.Warningf("no records are updated when updating admin job %d", id)
+	}
+	return err
+}
+
 // SetAdminJobUUID ...
 func SetAdminJobUUID(id int64, uuid string) error {
 	o := GetOrmer()
@@ -87,6 +214,46 @@ func SetAdminJobUUID(id int64, uuid string) error {
 	return err
 }
 
+// the kind of the periodic admin job, it's the same as job.JobKindPeriodic which can't be imported here
+const periodicAdminJobKind = "Periodic"
+
+// prunableAdminJobs matches the executions of the admin job which can be pruned, the schedules are never
+// pruned and the executions must be deleted or in the final status
+const prunableAdminJobs = `job_name = ? and job_kind != ? and (deleted = true or status in (?,?,?,?))`
+
+func prunableAdminJobsParams(name string) []interface{} {
+	return []interface{}{name, periodicAdminJobKind,
+		models.JobFinished, models.JobError, models.JobStopped, models.JobCanceled}
+}
+
+// PruneAdminJobsByCount deletes the prunable executions of the admin job except the latest keep ones,
+// it returns the deleted executions for cleaning up their logs
+func PruneAdminJobsByCount(name string, keep int) ([]*models.AdminJob, error) {
+	if keep < 0 {
+		keep = 0
+	}
+	params := append(prunableAdminJobsParams(name), keep)
+	return pruneAdminJobs(`delete from admin_job where id in (
+		select id from admin_job where `+prunableAdminJobs+`
+		order by update_time desc, id desc offset ?) returning *`, params...)
+}
+
+// PruneAdminJobsByAge deletes the prunable executions of the admin job which haven't been updated
+// since the time, it returns the deleted executions for cleaning up their logs
+func PruneAdminJobsByAge(name string, before time.Time) ([]*models.AdminJob, error) {
+	params := append(prunableAdminJobsParams(name), before)
+	return pruneAdminJobs(`delete from admin_job where `+prunableAdminJobs+`
+		and update_time < ? returning *`, params...)
+}
+
+func pruneAdminJobs(sql string, params ...interface{}) ([]*models.AdminJob, error) {
+	jobs := []*models.AdminJob{}
+	if _, err := GetOrmer().Raw(sql, params...).QueryRows(&jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
 // GetTop10AdminJobsOfName ...
 func GetTop10AdminJobsOfName(name string) ([]*models.AdminJob, error) {
 	o := GetOrmer()
@@ -103,6 +270,31 @@ func GetTop10AdminJobsOfName(name string) ([]*models.AdminJob, error) {
 	return jobs, err
 }
 
+// GetAdminJobsPaged returns the page of admin jobs matching the query conditions ordered by the
+// update time in descending order, along with the total count of the matched jobs
+func GetAdminJobsPaged(query *models.AdminJobQuery) ([]*models.AdminJob, int64, error) {
+	qs := adminQueryConditions(query)
+	total, err := qs.Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	qs = qs.OrderBy("-update_time", "-id")
+	if query.Size > 0 {
+		qs = qs.Limit(query.Size)
+		if query.Page > 0 {
+			qs = qs.Offset((query.Page - 1) * query.Size)
+		}
+	}
+
+	adjs := []*models.AdminJob{}
+	if _, err := qs.All(&adjs); err != nil {
+		return nil, 0, err
+	}
+
+	return adjs, total, nil
+}
+
 // GetAdminJobs get admin jobs bases on query conditions
 func GetAdminJobs(query *models.AdminJobQuery) ([]*models.AdminJob, error) {
 	adjs := []*models.AdminJob{}
@@ -136,6 +328,18 @@ func adminQueryConditions(query *models.AdminJobQuery) orm.QuerySeter {
 	if len(query.UUID) > 0 {
 		qs = qs.Filter("UUID", query.UUID)
 	}
+	if query.ScheduleName != nil {
+		qs = qs.Filter("ScheduleName", *query.ScheduleName)
+	}
+	if len(query.Statuses) > 0 {
+		qs = qs.Filter("Status__in", query.Statuses)
+	}
+	if query.StartTime != nil {
+		qs = qs.Filter("UpdateTime__gte", query.StartTime)
+	}
+	if query.EndTime != nil {
+		qs = qs.Filter("UpdateTime__lte", query.EndTime)
+	}
 	qs = qs.Filter("Deleted", false)
 	return qs
 
@@ -15,7 +15,10 @@
 package dao
 
 import (
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/stretchr/testify/assert"
@@ -57,6 +60,13 @@ func TestAddAdminJob(t *testing.T) {
 	require.Nil(t, err)
 	assert.Equal(t, job3.UUID, "f5ef34f4cb3588d663176132")
 
+	// update cron
+	err = UpdateAdminJobCron(id, `{"type":"Daily","cron":"0 0 0 * * *"}`)
+	require.Nil(t, err)
+	job4, err := GetAdminJob(id)
+	require.Nil(t, err)
+	assert.Equal(t, job4.Cron, `{"type":"Daily","cron":"0 0 0 * * *"}`)
+
 	// get admin jobs
 	_, err = AddAdminJob(job)
 	require.Nil(t, err)
@@ -73,3 +83,451 @@ func TestAddAdminJob(t *testing.T) {
 	jobs, _ = GetTop10AdminJobsOfName("job")
 	assert.Equal(t, len(jobs), 2)
 }
+
+func TestGetAdminJobsPaged(t *testing.T) {
+	name := "paged-job"
+	ids := []int64{}
+	for i := 0; i < 3; i++ {
+		id, err := AddAdminJob(&models.AdminJob{
+			Name: name,
+			Kind: "testKind",
+		})
+		require.Nil(t, err)
+		ids = append(ids, id)
+	}
+	defer func() {
+		for _, id := range ids {
+			_, err := GetOrmer().Raw(`delete from admin_job where id = ?`, id).Exec()
+			assert.Nil(t, err)
+		}
+	}()
+	require.Nil(t, UpdateAdminJobStatus(ids[0], models.JobError))
+
+	// the latest job comes first
+	query := &models.AdminJobQuery{
+		Name:       name,
+		Pagination: models.Pagination{Page: 1, Size: 2},
+	}
+	jobs, total, err := GetAdminJobsPaged(query)
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Equal(t, 2, len(jobs))
+	assert.Equal(t, ids[0], jobs[0].ID)
+
+	// the last page
+	query.Page = 2
+	jobs, total, err = GetAdminJobsPaged(query)
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, 1, len(jobs))
+
+	// the page out of range
+	query.Page = 3
+	jobs, total, err = GetAdminJobsPaged(query)
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, 0, len(jobs))
+
+	// filtered by the status
+	query.Page = 1
+	query.Status = models.JobError
+	jobs, total, err = GetAdminJobsPaged(query)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Equal(t, 1, len(jobs))
+	assert.Equal(t, ids[0], jobs[0].ID)
+}
+
+func TestGetRunningAdminJobsByName(t *testing.T) {
+	name := "running-job"
+	ids := []int64{}
+	for i := 0; i < 3; i++ {
+		id, err := AddAdminJob(&models.AdminJob{
+			Name: name,
+			Kind: "testKind",
+		})
+		require.Nil(t, err)
+		ids = append(ids, id)
+	}
+	defer func() {
+		for _, id := range ids {
+			_, err := GetOrmer().Raw(`delete from admin_job where id = ?`, id).Exec()
+			assert.Nil(t, err)
+		}
+	}()
+
+	// pending jobs aren't counted
+	count, err := GetRunningAdminJobsByName(name)
+	require.Nil(t, err)
+	assert.Equal(t, 0, count)
+
+	for _, id := range ids {
+		require.Nil(t, UpdateAdminJobStatus(id, models.JobRunning))
+	}
+	count, err = GetRunningAdminJobsByName(name)
+	require.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	// the completed job frees a slot
+	require.Nil(t, UpdateAdminJobStatus(ids[0], models.JobFinished))
+	count, err = GetRunningAdminJobsByName(name)
+	require.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	// the deleted job isn't counted
+	require.Nil(t, DeleteAdminJob(ids[1]))
+	count, err = GetRunningAdminJobsByName(name)
+	require.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestAddAdminJobWithLimit(t *testing.T) {
+	name := "limited-job"
+	ids := []int64{}
+	defer func() {
+		for _, id := range ids {
+			_, err := GetOrmer().Raw(`delete from admin_job where id = ?`, id).Exec()
+			assert.Nil(t, err)
+		}
+	}()
+
+	// the pending jobs are counted
+	for i := 0; i < 2; i++ {
+		id, err := AddAdminJobWithLimit(&models.AdminJob{
+			Name: name,
+			Kind: "testKind",
+		}, 2)
+		require.Nil(t, err)
+		ids = append(ids, id)
+	}
+	_, err := AddAdminJobWithLimit(&models.AdminJob{
+		Name: name,
+		Kind: "testKind",
+	}, 2)
+	assert.Equal(t, ErrAdminJobLimitReached, err)
+
+	// the jobs of other kinds aren't counted
+	id, err := AddAdminJobWithLimit(&models.AdminJob{
+		Name: name,
+		Kind: "otherKind",
+	}, 2)
+	require.Nil(t, err)
+	ids = append(ids, id)
+
+	// the completed job frees a slot
+	require.Nil(t, UpdateAdminJobStatus(ids[0], models.JobRunning))
+	require.Nil(t, UpdateAdminJobStatus(ids[1], models.JobFinished))
+	id, err = AddAdminJobWithLimit(&models.AdminJob{
+		Name: name,
+		Kind: "testKind",
+	}, 2)
+	require.Nil(t, err)
+	ids = append(ids, id)
+
+	// the deleted job isn't counted
+	require.Nil(t, DeleteAdminJob(ids[0]))
+	id, err = AddAdminJobWithLimit(&models.AdminJob{
+		Name: name,
+		Kind: "testKind",
+	}, 2)
+	require.Nil(t, err)
+	ids = append(ids, id)
+	_, err = AddAdminJobWithLimit(&models.AdminJob{
+		Name: name,
+		Kind: "testKind",
+	}, 2)
+	assert.Equal(t, ErrAdminJobLimitReached, err)
+
+	// the stale job isn't counted, e.g. its status is never reported
+	_, err = GetOrmer().Raw(`update admin_job set update_time = ? where id = ?`,
+		time.Now().Add(-AdminJobStaleAfter-time.Hour), id).Exec()
+	require.Nil(t, err)
+	id, err = AddAdminJobWithLimit(&models.AdminJob{
+		Name: name,
+		Kind: "testKind",
+	}, 2)
+	require.Nil(t, err)
+	ids = append(ids, id)
+}
+
+func TestAddAdminJobWithLimitConcurrently(t *testing.T) {
+	name := "concurrent-job"
+	defer func() {
+		_, err := GetOrmer().Raw(`delete from admin_job where job_name = ?`, name).Exec()
+		assert.Nil(t, err)
+	}()
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	added := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := AddAdminJobWithLimit(&models.AdminJob{
+				Name: name,
+				Kind: "testKind",
+			}, 3)
+			if err == nil {
+				lock.Lock()
+				added++
+				lock.Unlock()
+				return
+			}
+			assert.Equal(t, ErrAdminJobLimitReached, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 3, added)
+}
+
+func TestUpdateAdminJobSummary(t *testing.T) {
+	id, err := AddAdminJob(&models.AdminJob{
+		Name: "summary-job",
+		Kind: "testKind",
+	})
+	require.Nil(t, err)
+	defer GetOrmer().Raw(`delete from admin_job where id = ?`, id).Exec()
+
+	// the job has no summary by default
+	job, err := GetAdminJob(id)
+	require.Nil(t, err)
+	assert.Empty(t, job.Summary)
+
+	require.Nil(t, UpdateAdminJobSummary(id, `{"dry_run":true,"blob_count":2,"blob_size":1024}`))
+	job, err = GetAdminJob(id)
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"dry_run":true,"blob_count":2,"blob_size":1024}`, job.Summary)
+
+	// the summary must be JSON
+	assert.NotNil(t, UpdateAdminJobSummary(id, "not json"))
+}
+
+func TestUpdateAdminJobStats(t *testing.T) {
+	id, err := AddAdminJob(&models.AdminJob{
+		Name: "stats-job",
+		Kind: "testKind",
+	})
+	require.Nil(t, err)
+	defer GetOrmer().Raw(`delete from admin_job where id = ?`, id).Exec()
+
+	// the job has no stats by default
+	job, err := GetAdminJob(id)
+	require.Nil(t, err)
+	assert.Empty(t, job.Stats)
+
+	stats := `{"kind":"stats","blobs_deleted":2,"manifests_deleted":1,"bytes_freed":1024,"duration":3,"errors":0}`
+	require.Nil(t, UpdateAdminJobStats(id, stats))
+	job, err = GetAdminJob(id)
+	require.Nil(t, err)
+	assert.JSONEq(t, stats, job.Stats)
+
+	// the stats must be JSON
+	assert.NotNil(t, UpdateAdminJobStats(id, "not json"))
+}
+
+func TestGetAdminJobsByScheduleName(t *testing.T) {
+	name := "named-schedules-job"
+	defer GetOrmer().Raw(`delete from admin_job where job_name = ?`, name).Exec()
+
+	ids := []int64{}
+	for _, scheduleName := range []string{"", "weekly", "daily"} {
+		id, err := AddAdminJob(&models.AdminJob{
+			Name:         name,
+			Kind:         "Periodic",
+			ScheduleName: scheduleName,
+		})
+		require.Nil(t, err)
+		ids = append(ids, id)
+
+		// the schedule name is persisted
+		job, err := GetAdminJob(id)
+		require.Nil(t, err)
+		require.NotNil(t, job)
+		assert.Equal(t, scheduleName, job.ScheduleName)
+	}
+
+	query := func(scheduleName *string) []int64 {
+		jobs, err := GetAdminJobs(&models.AdminJobQuery{
+			Name:         name,
+			Kind:         "Periodic",
+			ScheduleName: scheduleName,
+		})
+		require.Nil(t, err)
+		res := []int64{}
+		for _, job := range jobs {
+			res = append(res, job.ID)
+		}
+		return res
+	}
+	scheduleName := func(s string) *string {
+		return &s
+	}
+
+	assert.ElementsMatch(t, ids, query(nil))
+	// the empty name matches the default schedule only
+	assert.Equal(t, []int64{ids[0]}, query(scheduleName("")))
+	assert.Equal(t, []int64{ids[1]}, query(scheduleName("weekly")))
+	assert.Empty(t, query(scheduleName("monthly")))
+
+	// the deleted schedule isn't matched
+	require.Nil(t, DeleteAdminJob(ids[1]))
+	assert.Empty(t, query(scheduleName("weekly")))
+	assert.ElementsMatch(t, []int64{ids[0], ids[2]}, query(nil))
+}
+
+func TestGetPendingSubmissionJobs(t *testing.T) {
+	id, err := AddAdminJob(&models.AdminJob{
+		Name:       "pending_job",
+		Kind:       "Generic",
+		Status:     models.JobPendingSubmission,
+		Parameters: `{"workers":2}`,
+	})
+	require.Nil(t, err)
+	defer DeleteAdminJob(id)
+
+	jobs, err := GetPendingSubmissionJobs()
+	require.Nil(t, err)
+	require.Equal(t, 1, len(jobs))
+	assert.Equal(t, id, jobs[0].ID)
+	assert.Equal(t, `{"workers":2}`, jobs[0].Parameters)
+
+	// the job can be claimed only once
+	claimed, err := ClaimPendingSubmissionJob(id)
+	require.Nil(t, err)
+	assert.True(t, claimed)
+	claimed, err = ClaimPendingSubmissionJob(id)
+	require.Nil(t, err)
+	assert.False(t, claimed)
+
+	jobs, err = GetPendingSubmissionJobs()
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(jobs))
+}
+
+// addPrunableAdminJobs adds the executions of the admin job in the statuses, the update time of
+// them increases in order
+func addPrunableAdminJobs(t *testing.T, name, kind string, statuses ...string) []int64 {
+	ids := []int64{}
+	for i, status := range statuses {
+		id, err := AddAdminJob(&models.AdminJob{
+			Name: name,
+			Kind: kind,
+		})
+		require.Nil(t, err)
+		_, err = GetOrmer().Raw(`update admin_job set status = ?, update_time = ? where id = ?`,
+			status, time.Now().Add(time.Duration(i-len(statuses))*time.Hour), id).Exec()
+		require.Nil(t, err)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func remainingAdminJobs(t *testing.T, name string) []int64 {
+	jobs := []*models.AdminJob{}
+	_, err := GetOrmer().Raw(`select * from admin_job where job_name = ? order by id`, name).QueryRows(&jobs)
+	require.Nil(t, err)
+	ids := []int64{}
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	return ids
+}
+
+// prunedAdminJobs returns the sorted IDs of the pruned admin jobs
+func prunedAdminJobs(jobs []*models.AdminJob) []int64 {
+	ids := []int64{}
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestGetAdminJobsByStatusesAndTimeRange(t *testing.T) {
+	name := "statuses-time-range-job"
+	defer GetOrmer().Raw(`delete from admin_job where job_name = ?`, name).Exec()
+
+	// updated 4, 3, 2 and 1 hours ago
+	ids := addPrunableAdminJobs(t, name, "Generic", models.JobError, models.JobFinished,
+		models.JobError, models.JobRunning)
+
+	query := func(statuses []string, start, end *time.Time) []int64 {
+		jobs, total, err := GetAdminJobsPaged(&models.AdminJobQuery{
+			Name:      name,
+			Statuses:  statuses,
+			StartTime: start,
+			EndTime:   end,
+		})
+		require.Nil(t, err)
+		res := []int64{}
+		for _, job := range jobs {
+			res = append(res, job.ID)
+		}
+		assert.Equal(t, int64(len(res)), total)
+		return res
+	}
+	ago := func(d time.Duration) *time.Time {
+		t := time.Now().Add(-d)
+		return &t
+	}
+
+	assert.Equal(t, []int64{ids[3], ids[2], ids[1], ids[0]}, query(nil, nil, nil))
+	assert.Equal(t, []int64{ids[2], ids[0]}, query([]string{models.JobError}, nil, nil))
+	assert.Equal(t, []int64{ids[2], ids[1], ids[0]},
+		query([]string{models.JobError, models.JobFinished}, nil, nil))
+	assert.Equal(t, []int64{ids[2]}, query([]string{models.JobError}, ago(150*time.Minute), nil))
+	assert.Equal(t, []int64{ids[2], ids[1], ids[0]}, query(nil, nil, ago(90*time.Minute)))
+	assert.Equal(t, []int64{ids[2], ids[1]},
+		query([]string{models.JobError, models.JobFinished}, ago(210*time.Minute), ago(90*time.Minute)))
+	assert.Empty(t, query([]string{models.JobRunning}, nil, ago(90*time.Minute)))
+}
+
+func TestPruneAdminJobsByCount(t *testing.T) {
+	name := "prune-by-count-job"
+	defer GetOrmer().Raw(`delete from admin_job where job_name = ?`, name).Exec()
+
+	ids := addPrunableAdminJobs(t, name, "Generic", models.JobPending, models.JobFinished, models.JobError,
+		models.JobRunning, models.JobStopped, models.JobFinished)
+	schedules := addPrunableAdminJobs(t, name, "Periodic", models.JobFinished)
+	// the deleted execution can be pruned whatever the status is
+	require.Nil(t, DeleteAdminJob(ids[0]))
+
+	// the latest 2 executions in the final status are kept, along with the running one and the schedule
+	pruned, err := PruneAdminJobsByCount(name, 2)
+	require.Nil(t, err)
+	assert.Equal(t, []int64{ids[0], ids[1], ids[2]}, prunedAdminJobs(pruned))
+	assert.Equal(t, []int64{ids[3], ids[4], ids[5], schedules[0]}, remainingAdminJobs(t, name))
+
+	// nothing more to prune
+	pruned, err = PruneAdminJobsByCount(name, 2)
+	require.Nil(t, err)
+	assert.Empty(t, pruned)
+
+	// the schedule is never pruned
+	pruned, err = PruneAdminJobsByCount(name, 0)
+	require.Nil(t, err)
+	assert.Equal(t, []int64{ids[4], ids[5]}, prunedAdminJobs(pruned))
+	assert.Equal(t, []int64{ids[3], schedules[0]}, remainingAdminJobs(t, name))
+}
+
+func TestPruneAdminJobsByAge(t *testing.T) {
+	name := "prune-by-age-job"
+	defer GetOrmer().Raw(`delete from admin_job where job_name = ?`, name).Exec()
+
+	// updated 4, 3, 2 and 1 hours ago
+	ids := addPrunableAdminJobs(t, name, "Generic", models.JobFinished, models.JobRunning,
+		models.JobCanceled, models.JobFinished)
+	schedules := addPrunableAdminJobs(t, name, "Periodic", models.JobFinished, models.JobFinished)
+
+	pruned, err := PruneAdminJobsByAge(name, time.Now().Add(-90*time.Minute))
+	require.Nil(t, err)
+	assert.Equal(t, []int64{ids[0], ids[2]}, prunedAdminJobs(pruned))
+	assert.Equal(t, []int64{ids[1], ids[3], schedules[0], schedules[1]}, remainingAdminJobs(t, name))
+
+	// the running execution and the schedules are never pruned
+	pruned, err = PruneAdminJobsByAge(name, time.Now())
+	require.Nil(t, err)
+	assert.Equal(t, []int64{ids[3]}, prunedAdminJobs(pruned))
+	assert.Equal(t, []int64{ids[1], schedules[0], schedules[1]}, remainingAdminJobs(t, name))
+}
@@ -0,0 +1,87 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// HashAPIKey returns the hash of the API key which is stored instead of the key, the keys are
+// random enough so the hash isn't salted and can be looked up directly
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// AddAPIKey adds the API key, ErrDupRows is returned if the user has a key with the same name
+func AddAPIKey(key *models.APIKey) (int64, error) {
+	id, err := GetOrmer().Insert(key)
+	if err != nil && isDupRecErr(err) {
+		return 0, ErrDupRows
+	}
+	return id, err
+}
+
+// GetAPIKey returns the API key specified by the ID, nil is returned if it doesn't exist
+func GetAPIKey(id int64) (*models.APIKey, error) {
+	key := &models.APIKey{ID: id}
+	if err := GetOrmer().Read(key); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// GetAPIKeyByHash returns the API key whose hash is the one provided, nil is returned if it doesn't exist
+func GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	err := GetOrmer().QueryTable(&models.APIKey{}).Filter("KeyHash", hash).One(key)
+	if err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns the API keys of the user
+func ListAPIKeys(userID int) ([]*models.APIKey, error) {
+	keys := []*models.APIKey{}
+	_, err := GetOrmer().QueryTable(&models.APIKey{}).Filter("UserID", userID).OrderBy("id").All(&keys)
+	return keys, err
+}
+
+// UpdateAPIKey updates the name and the expiry of the API key, ErrDupRows is returned if the user
+// has another key with the same name
+func UpdateAPIKey(key *models.APIKey) error {
+	_, err := GetOrmer().Update(key, "Name", "ExpiresAt", "UpdateTime")
+	if err != nil && isDupRecErr(err) {
+		return ErrDupRows
+	}
+	return err
+}
+
+// DeleteAPIKey deletes the API key, the key is revoked once it's deleted
+func DeleteAPIKey(id int64) error {
+	_, err := GetOrmer().Delete(&models.APIKey{ID: id})
+	return err
+}
@@ -0,0 +1,83 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKey(t *testing.T) {
+	defer GetOrmer().Raw(`delete from user_api_key`).Exec()
+
+	id, err := AddAPIKey(&models.APIKey{
+		UserID:  1,
+		Name:    "ci",
+		KeyHash: HashAPIKey("key1"),
+	})
+	require.Nil(t, err)
+
+	key, err := GetAPIKey(id)
+	require.Nil(t, err)
+	require.NotNil(t, key)
+	assert.Equal(t, 1, key.UserID)
+	assert.Equal(t, "ci", key.Name)
+	assert.Equal(t, int64(0), key.ExpiresAt)
+
+	key, err = GetAPIKeyByHash(HashAPIKey("key1"))
+	require.Nil(t, err)
+	require.NotNil(t, key)
+	assert.Equal(t, id, key.ID)
+	key, err = GetAPIKeyByHash(HashAPIKey("key2"))
+	require.Nil(t, err)
+	assert.Nil(t, key)
+
+	// the name is unique per user
+	_, err = AddAPIKey(&models.APIKey{
+		UserID:  1,
+		Name:    "ci",
+		KeyHash: HashAPIKey("key2"),
+	})
+	assert.Equal(t, ErrDupRows, err)
+
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	require.Nil(t, UpdateAPIKey(&models.APIKey{ID: id, Name: "build", ExpiresAt: expiresAt}))
+	keys, err := ListAPIKeys(1)
+	require.Nil(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "build", keys[0].Name)
+	assert.Equal(t, expiresAt, keys[0].ExpiresAt)
+	// the hash isn't changed by the update
+	assert.Equal(t, HashAPIKey("key1"), keys[0].KeyHash)
+
+	require.Nil(t, DeleteAPIKey(id))
+	key, err = GetAPIKey(id)
+	require.Nil(t, err)
+	assert.Nil(t, key)
+	keys, err = ListAPIKeys(1)
+	require.Nil(t, err)
+	assert.Len(t, keys, 0)
+}
+
+func TestHashAPIKey(t *testing.T) {
+	assert.Equal(t, HashAPIKey("key"), HashAPIKey("key"))
+	assert.NotEqual(t, HashAPIKey("key"), HashAPIKey("another"))
+	assert.Len(t, HashAPIKey("key"), 64)
+	assert.NotContains(t, HashAPIKey("key"), "key")
+}
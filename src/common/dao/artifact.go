@@ -97,6 +97,51 @@ func ListArtifacts(query *models.ArtifactQuery) ([]*models.Artifact, error) {
 	return afs, err
 }
 
+// GetUntaggedArtifacts returns the artifacts without tag(pushed by digest) in the repository,
+// the ones whose digest is also referenced by a tagged artifact in the repository are excluded
+func GetUntaggedArtifacts(projectID int64, repo string) ([]*models.Artifact, error) {
+	sql := `SELECT af.* FROM artifact af
+		WHERE af.project_id = ? AND af.repo = ? AND af.tag = ''
+		AND NOT EXISTS (
+			SELECT 1 FROM artifact tagged
+			WHERE tagged.project_id = af.project_id AND tagged.repo = af.repo
+			AND tagged.digest = af.digest AND tagged.tag <> ''
+		)
+		ORDER BY af.id`
+	afs := []*models.Artifact{}
+	if _, err := GetOrmer().Raw(sql, projectID, repo).QueryRows(&afs); err != nil {
+		return nil, err
+	}
+	return afs, nil
+}
+
+// GetLatestPushedArtifacts returns at most limit tagged artifacts in the repository ordered by push time descending
+func GetLatestPushedArtifacts(projectID int64, repo string, limit int) ([]*models.Artifact, error) {
+	afs := []*models.Artifact{}
+	_, err := GetOrmer().QueryTable(&models.Artifact{}).
+		Filter("PID", projectID).
+		Filter("Repo", repo).
+		Exclude("Tag", "").
+		OrderBy("-PushTime", "-ID").
+		Limit(limit).
+		All(&afs)
+	return afs, err
+}
+
+// GetTagSuggestions returns at most limit tags in the repository starting with the prefix ordered by push time descending,
+// the special characters of LIKE in the prefix are escaped
+func GetTagSuggestions(projectID int64, repo, prefix string, limit int) ([]string, error) {
+	sql := `SELECT tag FROM artifact
+		WHERE project_id = ? AND repo = ? AND tag LIKE ? AND tag <> ''
+		ORDER BY push_time DESC, id DESC
+		LIMIT ?`
+	tags := []string{}
+	if _, err := GetOrmer().Raw(sql, projectID, repo, Escape(prefix)+"%", limit).QueryRows(&tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
 // GetArtifact by repository and tag
 func GetArtifact(repo, tag string) (*models.Artifact, error) {
 	artifact := &models.Artifact{}
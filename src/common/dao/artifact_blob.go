@@ -108,3 +108,91 @@ func CountSizeOfArtifact(digest string) (int64, error) {
 	}
 	return -1, err
 }
+
+// GetReclaimableSizes returns the size of the blobs which would be freed by deleting the tags of the repository
+// all together, keyed by the manifest digests of the tags. The blobs still referenced by any other artifact, no
+// matter which repository it's under, are excluded, and the blobs referenced by more than one of the deleting
+// manifests are counted only once under the first pushed one. The tags not tracked are absent in the result.
+func GetReclaimableSizes(projectID int64, repository string, tags []string) (map[string]int64, error) {
+	sizes := map[string]int64{}
+	if len(tags) == 0 {
+		return sizes, nil
+	}
+
+	sql := fmt.Sprintf(`SELECT af.digest AS artifact_digest, b.digest AS blob_digest, b.size,
+		NOT EXISTS (
+			SELECT 1 FROM artifact_blob o_afnb
+			JOIN artifact o ON o.digest = o_afnb.digest_af
+			WHERE o_afnb.digest_blob = afnb.digest_blob
+			AND NOT (o.project_id = ? AND o.repo = ? AND o.tag IN (%s))) AS reclaimable
+		FROM artifact af
+		JOIN artifact_blob afnb ON afnb.digest_af = af.digest
+		JOIN blob b ON b.digest = afnb.digest_blob
+		WHERE af.project_id = ? AND af.repo = ? AND af.tag IN (%s)
+		ORDER BY af.id`, ParamPlaceholderForIn(len(tags)), ParamPlaceholderForIn(len(tags)))
+	params := []interface{}{}
+	for i := 0; i < 2; i++ {
+		params = append(params, projectID, repository)
+		for _, tag := range tags {
+			params = append(params, tag)
+		}
+	}
+
+	var rows []struct {
+		ArtifactDigest string
+		BlobDigest     string
+		Size           int64
+		Reclaimable    bool
+	}
+	if _, err := GetOrmer().Raw(sql, params...).QueryRows(&rows); err != nil {
+		return nil, err
+	}
+
+	counted := map[string]bool{}
+	for _, row := range rows {
+		if _, ok := sizes[row.ArtifactDigest]; !ok {
+			sizes[row.ArtifactDigest] = 0
+		}
+		if !row.Reclaimable || counted[row.BlobDigest] {
+			continue
+		}
+		counted[row.BlobDigest] = true
+		sizes[row.ArtifactDigest] += row.Size
+	}
+
+	return sizes, nil
+}
+
+// GetTotalOfArtifactsByLayerDigest returns the count of the artifacts containing the layer
+func GetTotalOfArtifactsByLayerDigest(layerDigest string) (int64, error) {
+	var total int64
+	err := GetOrmer().Raw(`SELECT count(*) FROM artifact_blob afnb
+		JOIN artifact af ON af.digest = afnb.digest_af
+		WHERE afnb.digest_blob = ?`, layerDigest).QueryRow(&total)
+	return total, err
+}
+
+// GetArtifactsByLayerDigest returns the artifacts whose manifest contains the layer
+func GetArtifactsByLayerDigest(layerDigest string, pagination ...*models.Pagination) ([]*models.ArtifactReference, error) {
+	sql := `SELECT af.project_id, p.name AS project_name, af.repo, af.tag, af.digest
+		FROM artifact_blob afnb
+		JOIN artifact af ON af.digest = afnb.digest_af
+		JOIN project p ON p.project_id = af.project_id
+		WHERE afnb.digest_blob = ?
+		ORDER BY af.id`
+	params := []interface{}{layerDigest}
+	if len(pagination) > 0 && pagination[0] != nil && pagination[0].Size > 0 {
+		sql += ` LIMIT ?`
+		params = append(params, pagination[0].Size)
+		if pagination[0].Page > 0 {
+			sql += ` OFFSET ?`
+			params = append(params, (pagination[0].Page-1)*pagination[0].Size)
+		}
+	}
+
+	refs := []*models.ArtifactReference{}
+	if _, err := GetOrmer().Raw(sql, params...).QueryRows(&refs); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
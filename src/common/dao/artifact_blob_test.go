@@ -15,7 +15,9 @@
 package dao
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/goharbor/harbor/src/common/models"
 
@@ -129,3 +131,195 @@ func TestCountSizeOfArtifact(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, imageSize, int64(600))
 }
+
+func TestGetArtifactsByLayerDigest(t *testing.T) {
+	base := "sha256:layer-sharing-base"
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		digest := fmt.Sprintf("sha256:layer-sharing-%d", i)
+		id, err := AddArtifact(&models.Artifact{
+			PID:    1,
+			Repo:   "library/layer-sharing",
+			Tag:    fmt.Sprintf("v%d", i),
+			Digest: digest,
+			Kind:   "Docker-Image",
+		})
+		require.Nil(t, err)
+		ids = append(ids, id)
+
+		blobs := []*models.ArtifactAndBlob{
+			{DigestAF: digest, DigestBlob: digest},
+			{DigestAF: digest, DigestBlob: fmt.Sprintf("sha256:layer-sharing-top-%d", i)},
+		}
+		// the last artifact doesn't share the base layer
+		if i < 2 {
+			blobs = append(blobs, &models.ArtifactAndBlob{DigestAF: digest, DigestBlob: base})
+		}
+		require.Nil(t, AddArtifactNBlobs(blobs))
+	}
+	defer func() {
+		for i, id := range ids {
+			assert.Nil(t, DeleteArtifact(id))
+			assert.Nil(t, DeleteArtifactAndBlobByDigest(fmt.Sprintf("sha256:layer-sharing-%d", i)))
+		}
+	}()
+
+	total, err := GetTotalOfArtifactsByLayerDigest(base)
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+
+	refs, err := GetArtifactsByLayerDigest(base)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(refs))
+	assert.Equal(t, int64(1), refs[0].ProjectID)
+	assert.Equal(t, "library", refs[0].ProjectName)
+	assert.Equal(t, "library/layer-sharing", refs[0].Repo)
+	assert.Equal(t, "v0", refs[0].Tag)
+	assert.Equal(t, "sha256:layer-sharing-0", refs[0].Digest)
+
+	refs, err = GetArtifactsByLayerDigest(base, &models.Pagination{Page: 2, Size: 1})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(refs))
+	assert.Equal(t, "v1", refs[0].Tag)
+
+	refs, err = GetArtifactsByLayerDigest("sha256:layer-sharing-top-2")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(refs))
+	assert.Equal(t, "v2", refs[0].Tag)
+
+	refs, err = GetArtifactsByLayerDigest("sha256:non-existing")
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(refs))
+}
+
+func TestGetReclaimableSizes(t *testing.T) {
+	// the tags "v1" and "v1-alias" point to the same manifest, all the manifests of "library/reclaim"
+	// share the base layer, "b" and "c" share the layer "bc" and "c" shares the layer "c" with the
+	// manifest under another repository
+	artifacts := []*models.Artifact{
+		{PID: 1, Repo: "library/reclaim", Tag: "v1", Digest: "sha256:reclaim-a"},
+		{PID: 1, Repo: "library/reclaim", Tag: "v1-alias", Digest: "sha256:reclaim-a"},
+		{PID: 1, Repo: "library/reclaim", Tag: "v2", Digest: "sha256:reclaim-b"},
+		{PID: 1, Repo: "library/reclaim", Tag: "v3", Digest: "sha256:reclaim-c"},
+		{PID: 1, Repo: "library/reclaim-other", Tag: "v1", Digest: "sha256:reclaim-d"},
+	}
+	layers := map[string][]string{
+		"sha256:reclaim-a": {"sha256:reclaim-layer-base", "sha256:reclaim-layer-a"},
+		"sha256:reclaim-b": {"sha256:reclaim-layer-base", "sha256:reclaim-layer-b", "sha256:reclaim-layer-bc"},
+		"sha256:reclaim-c": {"sha256:reclaim-layer-base", "sha256:reclaim-layer-bc", "sha256:reclaim-layer-c"},
+		"sha256:reclaim-d": {"sha256:reclaim-layer-c"},
+	}
+	blobs := map[string]int64{
+		"sha256:reclaim-layer-base": 100,
+		"sha256:reclaim-layer-a":    10,
+		"sha256:reclaim-layer-b":    20,
+		"sha256:reclaim-layer-bc":   5,
+		"sha256:reclaim-layer-c":    30,
+	}
+	var ids []int64
+	for _, af := range artifacts {
+		af.Kind = "Docker-Image"
+		id, err := AddArtifact(af)
+		require.Nil(t, err)
+		ids = append(ids, id)
+	}
+	for af, bs := range layers {
+		afnbs := []*models.ArtifactAndBlob{}
+		for _, b := range bs {
+			afnbs = append(afnbs, &models.ArtifactAndBlob{DigestAF: af, DigestBlob: b})
+		}
+		require.Nil(t, AddArtifactNBlobs(afnbs))
+	}
+	for digest, size := range blobs {
+		_, err := AddBlob(&models.Blob{Digest: digest, ContentType: "v2.blob", Size: size})
+		require.Nil(t, err)
+	}
+	defer func() {
+		for _, id := range ids {
+			assert.Nil(t, DeleteArtifact(id))
+		}
+		for af := range layers {
+			assert.Nil(t, DeleteArtifactAndBlobByDigest(af))
+		}
+		for digest := range blobs {
+			assert.Nil(t, DeleteBlob(digest))
+		}
+	}()
+
+	// the base layer is kept by "v1", the layer "bc" is counted once and the layer "c" is kept by another repository
+	sizes, err := GetReclaimableSizes(1, "library/reclaim", []string{"v2", "v3"})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]int64{"sha256:reclaim-b": 25, "sha256:reclaim-c": 0}, sizes)
+
+	// the manifest is kept by the tag "v1-alias"
+	sizes, err = GetReclaimableSizes(1, "library/reclaim", []string{"v1", "v2", "v3"})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]int64{"sha256:reclaim-a": 0, "sha256:reclaim-b": 25, "sha256:reclaim-c": 0}, sizes)
+
+	sizes, err = GetReclaimableSizes(1, "library/reclaim", []string{"v1", "v1-alias", "v2", "v3"})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]int64{"sha256:reclaim-a": 110, "sha256:reclaim-b": 25, "sha256:reclaim-c": 0}, sizes)
+
+	// the tags not tracked are absent
+	sizes, err = GetReclaimableSizes(1, "library/reclaim", []string{"non-existing"})
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(sizes))
+}
+
+// BenchmarkGetArtifactsByLayerDigest looks up the artifacts by layer in 100k artifacts sharing 10 base layers
+func BenchmarkGetArtifactsByLayerDigest(b *testing.B) {
+	const (
+		artifactCount = 100000
+		baseLayers    = 10
+		batch         = 1000
+	)
+
+	o := GetOrmer()
+	now := time.Now()
+	for i := 0; i < artifactCount; i += batch {
+		afs := make([]*models.Artifact, 0, batch)
+		afnbs := make([]*models.ArtifactAndBlob, 0, batch*2)
+		for j := i; j < i+batch; j++ {
+			digest := fmt.Sprintf("sha256:bench-layer-sharing-%d", j)
+			afs = append(afs, &models.Artifact{
+				PID:          1,
+				Repo:         fmt.Sprintf("library/bench-layer-sharing-%d", j%100),
+				Tag:          fmt.Sprintf("%d", j),
+				Digest:       digest,
+				Kind:         "Docker-Image",
+				PushTime:     now,
+				CreationTime: now,
+			})
+			afnbs = append(afnbs,
+				&models.ArtifactAndBlob{DigestAF: digest, DigestBlob: fmt.Sprintf("sha256:bench-base-layer-%d", j%baseLayers)},
+				&models.ArtifactAndBlob{DigestAF: digest, DigestBlob: fmt.Sprintf("sha256:bench-top-layer-%d", j)},
+			)
+		}
+		if _, err := o.InsertMulti(batch, afs); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := o.InsertMulti(batch*2, afnbs); err != nil {
+			b.Fatal(err)
+		}
+	}
+	defer func() {
+		if _, err := o.Raw(`DELETE FROM artifact WHERE digest LIKE 'sha256:bench-layer-sharing-%'`).Exec(); err != nil {
+			b.Error(err)
+		}
+		if _, err := o.Raw(`DELETE FROM artifact_blob WHERE digest_af LIKE 'sha256:bench-layer-sharing-%'`).Exec(); err != nil {
+			b.Error(err)
+		}
+	}()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		refs, err := GetArtifactsByLayerDigest(fmt.Sprintf("sha256:bench-base-layer-%d", i%baseLayers),
+			&models.Pagination{Page: 1, Size: 10})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(refs) != 10 {
+			b.Fatalf("expected 10 artifacts but got %d", len(refs))
+		}
+	}
+}
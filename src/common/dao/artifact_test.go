@@ -15,7 +15,12 @@
 package dao
 
 import (
+	"math/rand"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"testing/quick"
 	"time"
 
 	"github.com/goharbor/harbor/src/common/models"
@@ -162,6 +167,191 @@ func TestListArtifacts(t *testing.T) {
 	assert.Equal(t, 1, len(afs))
 }
 
+func TestGetUntaggedArtifacts(t *testing.T) {
+	afs := []*models.Artifact{
+		{
+			PID:    1,
+			Repo:   "library/untagged",
+			Digest: "TestGetUntaggedArtifacts-1",
+			Kind:   "image",
+		},
+		{
+			PID:    1,
+			Repo:   "library/untagged",
+			Tag:    "latest",
+			Digest: "TestGetUntaggedArtifacts-2",
+			Kind:   "image",
+		},
+		{
+			PID:    1,
+			Repo:   "library/untagged-shared",
+			Digest: "TestGetUntaggedArtifacts-3",
+			Kind:   "image",
+		},
+		// the untagged one in repository "library/untagged-shared" shares the digest with this one
+		{
+			PID:    1,
+			Repo:   "library/untagged-shared",
+			Tag:    "latest",
+			Digest: "TestGetUntaggedArtifacts-3",
+			Kind:   "image",
+		},
+	}
+	for _, af := range afs {
+		id, err := AddArtifact(af)
+		require.Nil(t, err)
+		defer DeleteArtifact(id)
+	}
+
+	untagged, err := GetUntaggedArtifacts(1, "library/untagged")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(untagged))
+	assert.Equal(t, "TestGetUntaggedArtifacts-1", untagged[0].Digest)
+	assert.Equal(t, "", untagged[0].Tag)
+
+	untagged, err = GetUntaggedArtifacts(1, "library/untagged-shared")
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(untagged))
+
+	untagged, err = GetUntaggedArtifacts(2, "library/untagged")
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(untagged))
+}
+
+func TestGetLatestPushedArtifacts(t *testing.T) {
+	afs := []*models.Artifact{
+		{
+			PID:    1,
+			Repo:   "library/latest-pushed",
+			Tag:    "v1",
+			Digest: "TestGetLatestPushedArtifacts-1",
+			Kind:   "image",
+		},
+		{
+			PID:    1,
+			Repo:   "library/latest-pushed",
+			Tag:    "v2",
+			Digest: "TestGetLatestPushedArtifacts-2",
+			Kind:   "image",
+		},
+		{
+			PID:    1,
+			Repo:   "library/latest-pushed",
+			Tag:    "v3",
+			Digest: "TestGetLatestPushedArtifacts-3",
+			Kind:   "image",
+		},
+		{
+			PID:    1,
+			Repo:   "library/latest-pushed",
+			Digest: "TestGetLatestPushedArtifacts-4",
+			Kind:   "image",
+		},
+	}
+	for _, af := range afs {
+		id, err := AddArtifact(af)
+		require.Nil(t, err)
+		defer DeleteArtifact(id)
+	}
+
+	latest, err := GetLatestPushedArtifacts(1, "library/latest-pushed", 2)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(latest))
+	assert.Equal(t, "v3", latest[0].Tag)
+	assert.Equal(t, "v2", latest[1].Tag)
+
+	latest, err = GetLatestPushedArtifacts(1, "library/latest-pushed", 10)
+	require.Nil(t, err)
+	assert.Equal(t, 3, len(latest))
+}
+
+func TestGetTagSuggestions(t *testing.T) {
+	tags := []string{"v1.0", "v1.1", "v10", "v1_0", "v1%0", `v1\0`, "latest"}
+	for _, tag := range tags {
+		id, err := AddArtifact(&models.Artifact{
+			PID:    1,
+			Repo:   "library/tag-suggestions",
+			Tag:    tag,
+			Digest: "TestGetTagSuggestions-" + tag,
+			Kind:   "image",
+		})
+		require.Nil(t, err)
+		defer DeleteArtifact(id)
+	}
+
+	suggestions, err := GetTagSuggestions(1, "library/tag-suggestions", "v1.", 10)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"v1.1", "v1.0"}, suggestions)
+
+	suggestions, err = GetTagSuggestions(1, "library/tag-suggestions", "v1", 2)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(suggestions))
+
+	// the special characters are matched literally
+	suggestions, err = GetTagSuggestions(1, "library/tag-suggestions", "v1_", 10)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"v1_0"}, suggestions)
+	suggestions, err = GetTagSuggestions(1, "library/tag-suggestions", "v1%", 10)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"v1%0"}, suggestions)
+	suggestions, err = GetTagSuggestions(1, "library/tag-suggestions", `v1\`, 10)
+	require.Nil(t, err)
+	assert.Equal(t, []string{`v1\0`}, suggestions)
+
+	// no match
+	suggestions, err = GetTagSuggestions(1, "library/tag-suggestions", "v2", 10)
+	require.Nil(t, err)
+	assert.NotNil(t, suggestions)
+	assert.Equal(t, 0, len(suggestions))
+}
+
+// TestEscapeLikePrefix fuzzes the prefixes with the special characters of LIKE to make sure
+// the escaped prefix only matches the strings starting with it literally
+func TestEscapeLikePrefix(t *testing.T) {
+	matches := func(prefix, s string) bool {
+		return likeRegexp(Escape(prefix) + "%").MatchString(s)
+	}
+	f := func(prefix, rest, other string) bool {
+		return matches(prefix, prefix+rest) && matches(prefix, other) == strings.HasPrefix(other, prefix)
+	}
+	// generate the strings from a small alphabet to make the special characters and the common prefixes frequent
+	values := func(args []reflect.Value, r *rand.Rand) {
+		alphabet := []rune(`%_\v1.`)
+		for i := range args {
+			s := make([]rune, r.Intn(6))
+			for j := range s {
+				s[j] = alphabet[r.Intn(len(alphabet))]
+			}
+			args[i] = reflect.ValueOf(string(s))
+		}
+	}
+	assert.Nil(t, quick.Check(f, &quick.Config{MaxCount: 10000, Values: values}))
+}
+
+// likeRegexp converts the pattern of LIKE whose escape character is backslash into a regular expression
+func likeRegexp(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^(?s)")
+	escaped := false
+	for _, c := range pattern {
+		switch {
+		case escaped:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '%':
+			expr.WriteString(".*")
+		case c == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
 func TestGetTotalOfArtifacts(t *testing.T) {
 	af := &models.Artifact{
 		PID:    2,
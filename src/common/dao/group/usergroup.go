@@ -15,7 +15,6 @@
 package group
 
 import (
-	"strings"
 	"time"
 
 	"github.com/goharbor/harbor/src/common/utils"
@@ -101,17 +100,18 @@ func GetUserGroup(id int) (*models.UserGroup, error) {
 // GetGroupIDByGroupName - Return the group ID by given group name. it is possible less group ID than the given group name if some group doesn't exist.
 func GetGroupIDByGroupName(groupName []string, groupType int) ([]int, error) {
 	var retGroupID []int
-	var conditions []string
 	if len(groupName) == 0 {
 		return retGroupID, nil
 	}
+	// the group names come from the external identity providers, pass them as parameters
+	var params []interface{}
 	for _, gName := range groupName {
-		con := "'" + gName + "'"
-		conditions = append(conditions, con)
+		params = append(params, gName)
 	}
-	sql := fmt.Sprintf("select id from user_group where group_name in ( %s ) and group_type = %v", strings.Join(conditions, ","), groupType)
+	params = append(params, groupType)
+	sql := fmt.Sprintf("select id from user_group where group_name in ( %s ) and group_type = ?", dao.ParamPlaceholderForIn(len(groupName)))
 	o := dao.GetOrmer()
-	cnt, err := o.Raw(sql).QueryRows(&retGroupID)
+	cnt, err := o.Raw(sql, params...).QueryRows(&retGroupID)
 	if err != nil {
 		return retGroupID, err
 	}
@@ -119,6 +119,25 @@ func GetGroupIDByGroupName(groupName []string, groupType int) ([]int, error) {
 	return retGroupID, nil
 }
 
+// PopulateGroup onboards the groups which don't exist yet and returns the IDs of all the groups
+func PopulateGroup(groupNames []string, groupType int) ([]int, error) {
+	var groupIDs []int
+	for _, name := range groupNames {
+		if len(name) == 0 {
+			continue
+		}
+		g := &models.UserGroup{
+			GroupName: name,
+			GroupType: groupType,
+		}
+		if err := OnBoardUserGroup(g); err != nil {
+			return nil, err
+		}
+		groupIDs = append(groupIDs, g.ID)
+	}
+	return groupIDs, nil
+}
+
 // DeleteUserGroup ...
 func DeleteUserGroup(id int) error {
 	userGroup := models.UserGroup{ID: id}
@@ -480,3 +480,37 @@ func TestGetGroupIDByGroupName(t *testing.T) {
 		})
 	}
 }
+
+func TestPopulateGroup(t *testing.T) {
+	names := []string{"test_populate_group", "test_populate_group's", "test_populate_group\\users"}
+	ids, err := PopulateGroup(names, common.HTTPGroupType)
+	if err != nil {
+		t.Fatalf("failed to populate the groups: %v", err)
+	}
+	defer func() {
+		for _, id := range ids {
+			DeleteUserGroup(id)
+		}
+	}()
+	if len(ids) != len(names) {
+		t.Fatalf("expected %d groups, got %d", len(names), len(ids))
+	}
+
+	// the existing groups are not onboarded again
+	ids2, err := PopulateGroup(names[:1], common.HTTPGroupType)
+	if err != nil {
+		t.Fatalf("failed to populate the groups: %v", err)
+	}
+	if !reflect.DeepEqual(ids[:1], ids2) {
+		t.Errorf("expected %v, got %v", ids[:1], ids2)
+	}
+
+	// the names with the special characters are queried correctly
+	got, err := GetGroupIDByGroupName(names[1:], common.HTTPGroupType)
+	if err != nil {
+		t.Fatalf("failed to get the group IDs: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("expected 2 groups, got %v", got)
+	}
+}
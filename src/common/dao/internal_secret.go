@@ -0,0 +1,64 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// GetInternalSecrets returns the current secret of the user and the previous ones which aren't expired,
+// the current one goes first
+func GetInternalSecrets(username string) ([]*models.InternalSecret, error) {
+	secrets := []*models.InternalSecret{}
+	if _, err := GetOrmer().Raw(`select * from internal_secret
+		where username = ? and (expires_at is null or expires_at > ?)
+		order by expires_at desc nulls first, id desc`, username, time.Now()).QueryRows(&secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// RotateInternalSecret makes the current secret of the user expire after the grace period and adds the
+// new one in a transaction. The previous secret is added if there is no current one, e.g. the secret
+// read from the environment is used before the first rotation. The expired secrets are deleted.
+func RotateInternalSecret(username, previous, current string, grace time.Duration) error {
+	now := time.Now()
+	return WithTransaction(func(o orm.Ormer) error {
+		if _, err := o.Raw(`delete from internal_secret where username = ? and expires_at <= ?`,
+			username, now).Exec(); err != nil {
+			return err
+		}
+		result, err := o.Raw(`update internal_secret set expires_at = ?
+			where username = ? and expires_at is null`, now.Add(grace), username).Exec()
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 && len(previous) > 0 && grace > 0 {
+			if _, err = o.Raw(`insert into internal_secret (username, secret, expires_at) values (?, ?, ?)`,
+				username, previous, now.Add(grace)).Exec(); err != nil {
+				return err
+			}
+		}
+		_, err = o.Raw(`insert into internal_secret (username, secret) values (?, ?)`, username, current).Exec()
+		return err
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateInternalSecret(t *testing.T) {
+	defer GetOrmer().Raw(`delete from internal_secret`).Exec()
+
+	// the first rotation adds the previous secret
+	require.Nil(t, RotateInternalSecret("user", "secret0", "secret1", time.Minute))
+	secrets, err := GetInternalSecrets("user")
+	require.Nil(t, err)
+	require.Equal(t, 2, len(secrets))
+	assert.Equal(t, "secret1", secrets[0].Secret)
+	assert.True(t, secrets[0].ExpiresAt.IsZero())
+	assert.Equal(t, "secret0", secrets[1].Secret)
+	assert.False(t, secrets[1].ExpiresAt.IsZero())
+
+	// the current secret becomes the previous one
+	require.Nil(t, RotateInternalSecret("user", "ignored", "secret2", time.Minute))
+	secrets, err = GetInternalSecrets("user")
+	require.Nil(t, err)
+	require.Equal(t, 3, len(secrets))
+	assert.Equal(t, "secret2", secrets[0].Secret)
+	assert.Equal(t, "secret1", secrets[1].Secret)
+	assert.Equal(t, "secret0", secrets[2].Secret)
+
+	// the current secret expires immediately without the grace period
+	require.Nil(t, RotateInternalSecret("user", "ignored", "secret3", 0))
+	secrets, err = GetInternalSecrets("user")
+	require.Nil(t, err)
+	require.Equal(t, 3, len(secrets))
+	assert.Equal(t, "secret3", secrets[0].Secret)
+	assert.Equal(t, "secret1", secrets[1].Secret)
+	assert.Equal(t, "secret0", secrets[2].Secret)
+
+	// the secrets of the other users are not affected
+	secrets, err = GetInternalSecrets("other")
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(secrets))
+}
@@ -28,6 +28,11 @@ func GetJobLog(uuid string) (*models.JobLog, error) {
 	return &jl, nil
 }
 
+// DeleteJobLog deletes the log of the job, it returns the count of the deleted logs
+func DeleteJobLog(uuid string) (int64, error) {
+	return GetOrmer().Delete(&models.JobLog{UUID: uuid}, "UUID")
+}
+
 // DeleteJobLogsBefore ...
 func DeleteJobLogsBefore(t time.Time) (int64, error) {
 	o := GetOrmer()
@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/pkg/errors"
+)
+
+// AddWebhookDeliveryLog insert the delivery log of webhook to DB
+func AddWebhookDeliveryLog(log *models.WebhookDeliveryLog) (int64, error) {
+	if log == nil {
+		return 0, errors.New("nil delivery log")
+	}
+	return dao.GetOrmer().Insert(log)
+}
+
+// GetWebhookDeliveryStats returns the statistics of the deliveries of the webhook policy in the last days
+func GetWebhookDeliveryStats(policyID int64, days int) (*models.WebhookDeliveryStats, error) {
+	if days <= 0 {
+		return nil, errors.Errorf("invalid period: %d days", days)
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	o := dao.GetOrmer()
+
+	stats := &models.WebhookDeliveryStats{
+		PeriodDays:     days,
+		ErrorBreakdown: []*models.WebhookDeliveryErrorCount{},
+	}
+	sql := `SELECT COUNT(*), COALESCE(SUM(CASE WHEN success THEN 1 ELSE 0 END), 0), COALESCE(AVG(duration_ms), 0)
+		FROM webhook_delivery_log WHERE policy_id = ? AND creation_time >= ?`
+	if err := o.Raw(sql, policyID, since).QueryRow(&stats.TotalDeliveries, &stats.Successful, &stats.AvgDurationMS); err != nil {
+		return nil, err
+	}
+	stats.Failed = stats.TotalDeliveries - stats.Successful
+
+	sql = `SELECT status_code, COUNT(*) AS count FROM webhook_delivery_log
+		WHERE policy_id = ? AND creation_time >= ? AND success = false
+		GROUP BY status_code ORDER BY count DESC, status_code`
+	if _, err := o.Raw(sql, policyID, since).QueryRows(&stats.ErrorBreakdown); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWebhookDeliveryStats(t *testing.T) {
+	var policyID int64 = 2222
+	logs := []*models.WebhookDeliveryLog{
+		{PolicyID: policyID, JobID: 1, StatusCode: 200, DurationMS: 10, Success: true},
+		{PolicyID: policyID, JobID: 2, StatusCode: 204, DurationMS: 20, Success: true},
+		{PolicyID: policyID, JobID: 3, StatusCode: 500, DurationMS: 30, Success: false},
+		{PolicyID: policyID, JobID: 3, StatusCode: 500, DurationMS: 40, Success: false},
+		{PolicyID: policyID, JobID: 4, StatusCode: 404, DurationMS: 50, Success: false},
+		{PolicyID: policyID, JobID: 5, StatusCode: 0, DurationMS: 1000, Success: false},
+		// the deliveries of other policies are ignored
+		{PolicyID: policyID + 1, JobID: 6, StatusCode: 500, DurationMS: 10, Success: false},
+	}
+	for _, l := range logs {
+		_, err := AddWebhookDeliveryLog(l)
+		require.Nil(t, err)
+	}
+	// the delivery out of the period is ignored
+	old := &models.WebhookDeliveryLog{PolicyID: policyID, JobID: 7, StatusCode: 502, DurationMS: 10}
+	_, err := AddWebhookDeliveryLog(old)
+	require.Nil(t, err)
+	_, err = dao.GetOrmer().Raw(`UPDATE webhook_delivery_log SET creation_time = ? WHERE id = ?`,
+		time.Now().AddDate(0, 0, -8), old.ID).Exec()
+	require.Nil(t, err)
+	defer dao.GetOrmer().Raw(`DELETE FROM webhook_delivery_log WHERE policy_id IN (?, ?)`, policyID, policyID+1).Exec()
+
+	stats, err := GetWebhookDeliveryStats(policyID, 7)
+	require.Nil(t, err)
+	assert.Equal(t, 7, stats.PeriodDays)
+	assert.Equal(t, int64(6), stats.TotalDeliveries)
+	assert.Equal(t, int64(2), stats.Successful)
+	assert.Equal(t, int64(4), stats.Failed)
+	assert.InDelta(t, 191.67, stats.AvgDurationMS, 0.01)
+	require.Equal(t, 3, len(stats.ErrorBreakdown))
+	assert.Equal(t, 500, stats.ErrorBreakdown[0].StatusCode)
+	assert.Equal(t, int64(2), stats.ErrorBreakdown[0].Count)
+	assert.Equal(t, 0, stats.ErrorBreakdown[1].StatusCode)
+	assert.Equal(t, int64(1), stats.ErrorBreakdown[1].Count)
+	assert.Equal(t, 404, stats.ErrorBreakdown[2].StatusCode)
+	assert.Equal(t, int64(1), stats.ErrorBreakdown[2].Count)
+
+	// no deliveries
+	stats, err = GetWebhookDeliveryStats(policyID+2, 7)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), stats.TotalDeliveries)
+	assert.Equal(t, 0, len(stats.ErrorBreakdown))
+
+	_, err = GetWebhookDeliveryStats(policyID, 0)
+	assert.NotNil(t, err)
+}
@@ -148,6 +148,68 @@ AND bb.content_type != ?
 	return size, err
 }
 
+// GetStorageByArtifactType returns the storage used by the artifacts of the project grouped by the artifact type,
+// the blobs shared by the artifacts of the same type are counted once and the foreign blobs are not calculated
+func GetStorageByArtifactType(projectID int64) ([]*models.ArtifactTypeStorage, error) {
+	sql := `
+SELECT
+    af.kind AS artifact_type,
+    af.artifact_count,
+    COALESCE(bb.size_bytes, 0) AS size_bytes
+FROM (
+    SELECT kind, COUNT(*) AS artifact_count
+    FROM artifact
+    WHERE project_id = ?
+    GROUP BY kind
+) af
+LEFT JOIN (
+    SELECT t.kind, SUM(t.size) AS size_bytes
+    FROM (
+        SELECT DISTINCT a.kind, b.digest, b.size
+        FROM artifact a
+        JOIN artifact_blob afnb
+            ON a.digest = afnb.digest_af
+        JOIN blob b
+            ON afnb.digest_blob = b.digest
+        WHERE a.project_id = ?
+        AND b.content_type != ?
+    ) t
+    GROUP BY t.kind
+) bb
+    ON af.kind = bb.kind
+ORDER BY af.kind
+`
+	storages := []*models.ArtifactTypeStorage{}
+	if _, err := GetOrmer().Raw(sql, projectID, projectID, common.ForeignLayer).QueryRows(&storages); err != nil {
+		return nil, err
+	}
+	return storages, nil
+}
+
+// GetUntaggedStorage returns the size of the blobs in the project which are not referenced by any artifact of the project
+func GetUntaggedStorage(projectID int64) (int64, error) {
+	sql := `
+SELECT COALESCE(SUM(bb.size), 0)
+FROM project_blob pb
+JOIN blob bb
+    ON pb.blob_id = bb.id
+WHERE pb.project_id = ?
+AND bb.content_type != ?
+AND bb.digest NOT IN (
+    SELECT afnb.digest_blob
+    FROM artifact af
+    JOIN artifact_blob afnb
+        ON af.digest = afnb.digest_af
+    WHERE af.project_id = ?
+)
+`
+	var size int64
+	if err := GetOrmer().Raw(sql, projectID, common.ForeignLayer, projectID).QueryRow(&size); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
 // RemoveUntaggedBlobs ...
 func RemoveUntaggedBlobs(pid int64) error {
 	var blobs []models.Blob
@@ -445,3 +445,69 @@ func TestRemoveUntaggedBlobsWithNoUntagged(t *testing.T) {
 	require.Nil(t, err)
 	assert.False(t, has)
 }
+
+func TestGetStorageByArtifactType(t *testing.T) {
+	pid, err := AddProject(models.Project{
+		Name:    "GetStorageByArtifactType_project1",
+		OwnerID: 1,
+	})
+	require.Nil(t, err)
+	defer DeleteProject(pid)
+
+	blobs := map[string]int64{
+		"GetStorageByArtifactType_manifest1": 10,
+		"GetStorageByArtifactType_manifest2": 20,
+		"GetStorageByArtifactType_layer":     100,
+		"GetStorageByArtifactType_chart":     300,
+		"GetStorageByArtifactType_untagged":  1000,
+	}
+	var total int64
+	for dgt, size := range blobs {
+		id, err := AddBlob(&models.Blob{
+			Digest:      dgt,
+			ContentType: "application/vnd.docker.image.rootfs.diff.tar.gzip",
+			Size:        size,
+		})
+		require.Nil(t, err)
+		_, err = AddBlobToProject(id, pid)
+		require.Nil(t, err)
+		total += size
+	}
+
+	// two images share the same layer and two tags of a chart point to the same digest
+	artifacts := []*models.Artifact{
+		{PID: pid, Repo: "library/image", Tag: "v1", Digest: "GetStorageByArtifactType_af1", Kind: "image"},
+		{PID: pid, Repo: "library/image", Tag: "v2", Digest: "GetStorageByArtifactType_af2", Kind: "image"},
+		{PID: pid, Repo: "library/chart", Tag: "1.0", Digest: "GetStorageByArtifactType_af3", Kind: "chart"},
+		{PID: pid, Repo: "library/chart", Tag: "latest", Digest: "GetStorageByArtifactType_af3", Kind: "chart"},
+	}
+	for _, af := range artifacts {
+		_, err = AddArtifact(af)
+		require.Nil(t, err)
+	}
+	err = AddArtifactNBlobs([]*models.ArtifactAndBlob{
+		{DigestAF: "GetStorageByArtifactType_af1", DigestBlob: "GetStorageByArtifactType_manifest1"},
+		{DigestAF: "GetStorageByArtifactType_af1", DigestBlob: "GetStorageByArtifactType_layer"},
+		{DigestAF: "GetStorageByArtifactType_af2", DigestBlob: "GetStorageByArtifactType_manifest2"},
+		{DigestAF: "GetStorageByArtifactType_af2", DigestBlob: "GetStorageByArtifactType_layer"},
+		{DigestAF: "GetStorageByArtifactType_af3", DigestBlob: "GetStorageByArtifactType_chart"},
+	})
+	require.Nil(t, err)
+
+	storages, err := GetStorageByArtifactType(pid)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(storages))
+	assert.Equal(t, "chart", storages[0].ArtifactType)
+	assert.Equal(t, int64(300), storages[0].SizeBytes)
+	assert.Equal(t, int64(2), storages[0].ArtifactCount)
+	assert.Equal(t, "image", storages[1].ArtifactType)
+	assert.Equal(t, int64(130), storages[1].SizeBytes)
+	assert.Equal(t, int64(2), storages[1].ArtifactCount)
+
+	untagged, err := GetUntaggedStorage(pid)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1000), untagged)
+
+	// the sums add up to the total usage of the project
+	assert.Equal(t, total, storages[0].SizeBytes+storages[1].SizeBytes+untagged)
+}
@@ -0,0 +1,66 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"encoding/json"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// InsertProjectConfigHistory inserts a change record of the project configuration
+func InsertProjectConfigHistory(change *models.ProjectConfigChange) error {
+	if change.Diff != nil {
+		change.DiffText = string(change.Diff)
+	}
+	_, err := GetOrmer().Insert(change)
+	return err
+}
+
+// GetTotalOfProjectConfigHistory returns the total count of the change records matching the query
+func GetTotalOfProjectConfigHistory(query *models.ProjectConfigChangeQuery) (int64, error) {
+	return projectConfigHistoryQueryConditions(query).Count()
+}
+
+// GetProjectConfigHistory returns the change records matching the query, the latest comes first
+func GetProjectConfigHistory(query *models.ProjectConfigChangeQuery) ([]*models.ProjectConfigChange, error) {
+	qs := projectConfigHistoryQueryConditions(query).OrderBy("-changed_at", "-id")
+	if query != nil && query.Size > 0 {
+		qs = qs.Limit(query.Size)
+		if query.Page > 0 {
+			qs = qs.Offset((query.Page - 1) * query.Size)
+		}
+	}
+
+	changes := []*models.ProjectConfigChange{}
+	if _, err := qs.All(&changes); err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		if len(change.DiffText) > 0 {
+			change.Diff = json.RawMessage(change.DiffText)
+		}
+	}
+	return changes, nil
+}
+
+func projectConfigHistoryQueryConditions(query *models.ProjectConfigChangeQuery) orm.QuerySeter {
+	qs := GetOrmer().QueryTable(&models.ProjectConfigChange{})
+	if query != nil && query.ProjectID > 0 {
+		qs = qs.Filter("ProjectID", query.ProjectID)
+	}
+	return qs
+}
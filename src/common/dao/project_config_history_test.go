@@ -0,0 +1,60 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectConfigHistory(t *testing.T) {
+	require.Nil(t, ClearTable("project_config_history"))
+	defer ClearTable("project_config_history")
+
+	for _, diff := range []string{
+		`[{"op":"add","path":"/metadata/auto_scan","value":"true"}]`,
+		`[{"op":"test","path":"/metadata/public","value":"false"},{"op":"replace","path":"/metadata/public","value":"true"}]`,
+	} {
+		require.Nil(t, InsertProjectConfigHistory(&models.ProjectConfigChange{
+			ProjectID: 1,
+			ChangedBy: "admin",
+			Diff:      json.RawMessage(diff),
+		}))
+	}
+	require.Nil(t, InsertProjectConfigHistory(&models.ProjectConfigChange{
+		ProjectID: 2,
+		ChangedBy: "admin",
+		Diff:      json.RawMessage(`[]`),
+	}))
+
+	query := &models.ProjectConfigChangeQuery{ProjectID: 1}
+	total, err := GetTotalOfProjectConfigHistory(query)
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+
+	query.Page = 1
+	query.Size = 1
+	changes, err := GetProjectConfigHistory(query)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(changes))
+	assert.Equal(t, int64(1), changes[0].ProjectID)
+	assert.Equal(t, "admin", changes[0].ChangedBy)
+	assert.JSONEq(t, `[{"op":"test","path":"/metadata/public","value":"false"},{"op":"replace","path":"/metadata/public","value":"true"}]`,
+		string(changes[0].Diff))
+}
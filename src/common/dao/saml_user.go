@@ -0,0 +1,72 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"fmt"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/pkg/errors"
+)
+
+// GetUserBySAMLSubject returns the user bound to the subject asserted by the SAML IdP, nil if it doesn't exist
+func GetUserBySAMLSubject(issuer, subject string) (*models.User, error) {
+	samlUser := &models.SAMLUser{}
+	err := GetOrmer().Raw(`select * from saml_user where issuer = ? and subject = ?`, issuer, subject).QueryRow(samlUser)
+	if err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	user, err := GetUser(models.User{
+		UserID: samlUser.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("can not get user %d", samlUser.UserID)
+	}
+	return user, nil
+}
+
+// OnBoardSAMLUser inserts the user and binds it to the subject asserted by the SAML IdP in a transaction.
+// It never binds the subject to an existing user, the ErrDupUser is returned if the username is taken.
+func OnBoardSAMLUser(u *models.User, issuer, subject string) error {
+	return WithTransaction(func(o orm.Ormer) error {
+		id, err := o.Insert(u)
+		if err != nil {
+			if isDupRecErr(err) {
+				return errors.Wrap(err, ErrDupUser.Error())
+			}
+			return err
+		}
+		u.UserID = int(id)
+		// current orm framework doesn't support to insert the null email
+		if len(u.Email) == 0 {
+			if _, err = o.Raw("update harbor_user set email = null where user_id = ? ", id).Exec(); err != nil {
+				return err
+			}
+		}
+		_, err = o.Insert(&models.SAMLUser{
+			UserID:  u.UserID,
+			Issuer:  issuer,
+			Subject: subject,
+		})
+		return err
+	})
+}
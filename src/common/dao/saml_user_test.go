@@ -0,0 +1,61 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSAMLUserDaoMethods(t *testing.T) {
+	u := &models.User{
+		Username: "saml-user01",
+		Email:    "saml-user01@harbor.test",
+	}
+	require.Nil(t, OnBoardSAMLUser(u, "https://idp.test", "subject01"))
+	defer CleanUser(int64(u.UserID))
+	defer func() {
+		_, err := GetOrmer().Raw(`delete from saml_user where user_id = ?`, u.UserID).Exec()
+		assert.Nil(t, err)
+	}()
+
+	user, err := GetUserBySAMLSubject("https://idp.test", "subject01")
+	require.Nil(t, err)
+	require.NotNil(t, user)
+	assert.Equal(t, u.UserID, user.UserID)
+
+	// the subject of other IdP
+	user, err = GetUserBySAMLSubject("https://other-idp.test", "subject01")
+	require.Nil(t, err)
+	assert.Nil(t, user)
+
+	// the username is taken by other user, e.g. the admin
+	err = OnBoardSAMLUser(&models.User{Username: "admin"}, "https://idp.test", "subject02")
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrDupUser.Error())
+	user, err = GetUserBySAMLSubject("https://idp.test", "subject02")
+	require.Nil(t, err)
+	assert.Nil(t, user)
+
+	// the subject is bound to other user
+	err = OnBoardSAMLUser(&models.User{Username: "saml-user02"}, "https://idp.test", "subject01")
+	require.NotNil(t, err)
+	user, err = GetUser(models.User{Username: "saml-user02"})
+	require.Nil(t, err)
+	assert.Nil(t, user)
+}
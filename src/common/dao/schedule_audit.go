@@ -0,0 +1,43 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// InsertScheduleAuditLog inserts an audit entry of the admin job schedule change
+func InsertScheduleAuditLog(entry *models.ScheduleAuditEntry) (int64, error) {
+	return GetOrmer().Insert(entry)
+}
+
+// GetScheduleAuditLog returns the latest audit entries of the schedule changes of the job,
+// all the jobs are included if the jobName is empty and all the entries are returned if
+// the limit isn't larger than 0
+func GetScheduleAuditLog(jobName string, limit int) ([]*models.ScheduleAuditEntry, error) {
+	qs := GetOrmer().QueryTable(&models.ScheduleAuditEntry{})
+	if len(jobName) > 0 {
+		qs = qs.Filter("JobName", jobName)
+	}
+	qs = qs.OrderBy("-changed_at", "-id")
+	if limit > 0 {
+		qs = qs.Limit(limit)
+	}
+	entries := []*models.ScheduleAuditEntry{}
+	if _, err := qs.All(&entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,51 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleAuditLog(t *testing.T) {
+	require.Nil(t, ClearTable("schedule_audit_log"))
+	defer ClearTable("schedule_audit_log")
+
+	for _, entry := range []*models.ScheduleAuditEntry{
+		{Actor: "admin", JobName: "IMAGE_GC", OldCron: "", NewCron: "0 0 0 * * *"},
+		{Actor: "admin", JobName: "IMAGE_SCAN_ALL", OldCron: "", NewCron: "0 0 1 * * *"},
+		{Actor: "admin", JobName: "IMAGE_GC", OldCron: "0 0 0 * * *", NewCron: "0 0 0 * * 0"},
+	} {
+		_, err := InsertScheduleAuditLog(entry)
+		require.Nil(t, err)
+	}
+
+	entries, err := GetScheduleAuditLog("IMAGE_GC", 0)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(entries))
+
+	entries, err = GetScheduleAuditLog("IMAGE_GC", 1)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "0 0 0 * * *", entries[0].OldCron)
+	assert.Equal(t, "0 0 0 * * 0", entries[0].NewCron)
+
+	entries, err = GetScheduleAuditLog("", 0)
+	require.Nil(t, err)
+	assert.Equal(t, 3, len(entries))
+}
@@ -0,0 +1,66 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"strings"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// GetSigningKeys returns the signing keys of the tokens
+func GetSigningKeys() ([]*models.SigningKey, error) {
+	keys := []*models.SigningKey{}
+	if _, err := GetOrmer().QueryTable(&models.SigningKey{}).OrderBy("id").All(&keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RotateSigningKey retires the active key and adds the new active one in a transaction. The
+// retiring key is added if there is no active key yet, e.g. the key read from the file is
+// used before the first rotation. ErrDupRows is returned if a rotation is in progress.
+func RotateSigningKey(retiring, active *models.SigningKey) error {
+	err := WithTransaction(func(o orm.Ormer) error {
+		n, err := o.QueryTable(&models.SigningKey{}).
+			Filter("Status", models.SigningKeyActive).
+			Update(orm.Params{"status": models.SigningKeyRetiring})
+		if err != nil {
+			return err
+		}
+		if n == 0 && retiring != nil {
+			retiring.Status = models.SigningKeyRetiring
+			if _, err = o.Insert(retiring); err != nil {
+				return err
+			}
+		}
+		active.Status = models.SigningKeyActive
+		_, err = o.Insert(active)
+		return err
+	})
+	if err != nil && strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		return ErrDupRows
+	}
+	return err
+}
+
+// DeleteRetiringSigningKeys deletes the retiring keys to complete the rotation
+func DeleteRetiringSigningKeys() error {
+	_, err := GetOrmer().QueryTable(&models.SigningKey{}).
+		Filter("Status", models.SigningKeyRetiring).
+		Delete()
+	return err
+}
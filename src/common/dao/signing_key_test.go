@@ -0,0 +1,59 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateSigningKey(t *testing.T) {
+	defer GetOrmer().Raw(`delete from token_signing_key`).Exec()
+
+	// the first rotation retires the key from the file
+	err := RotateSigningKey(&models.SigningKey{PrivateKey: "key0"}, &models.SigningKey{PrivateKey: "key1"})
+	require.Nil(t, err)
+	keys, err := GetSigningKeys()
+	require.Nil(t, err)
+	require.Equal(t, 2, len(keys))
+	assert.Equal(t, "key0", keys[0].PrivateKey)
+	assert.Equal(t, models.SigningKeyRetiring, keys[0].Status)
+	assert.Equal(t, "key1", keys[1].PrivateKey)
+	assert.Equal(t, models.SigningKeyActive, keys[1].Status)
+
+	// the rotation is in progress
+	err = RotateSigningKey(nil, &models.SigningKey{PrivateKey: "key2"})
+	assert.Equal(t, ErrDupRows, err)
+	keys, err = GetSigningKeys()
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(keys))
+
+	// complete the rotation
+	require.Nil(t, DeleteRetiringSigningKeys())
+
+	// the active key is retired by the next rotation
+	err = RotateSigningKey(&models.SigningKey{PrivateKey: "ignored"}, &models.SigningKey{PrivateKey: "key2"})
+	require.Nil(t, err)
+	keys, err = GetSigningKeys()
+	require.Nil(t, err)
+	require.Equal(t, 2, len(keys))
+	assert.Equal(t, "key1", keys[0].PrivateKey)
+	assert.Equal(t, models.SigningKeyRetiring, keys[0].Status)
+	assert.Equal(t, "key2", keys[1].PrivateKey)
+	assert.Equal(t, models.SigningKeyActive, keys[1].Status)
+}
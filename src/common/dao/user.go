@@ -32,7 +32,7 @@ func GetUser(query models.User) (*models.User, error) {
 	o := GetOrmer()
 
 	sql := `select user_id, username, password, password_version, email, realname, comment, reset_uuid, salt,
-		sysadmin_flag, creation_time, update_time
+		sysadmin_flag, creation_time, update_time, disabled
 		from harbor_user u
 		where deleted = false `
 	queryParam := make([]interface{}, 1)
@@ -244,7 +244,11 @@ func OnBoardUser(u *models.User) error {
 	o := GetOrmer()
 	created, id, err := o.ReadOrCreate(u, "Username")
 	if err != nil {
-		return err
+		// the user may be onboarded by a concurrent request between the read and the insert
+		if !isDupRecErr(err) {
+			return err
+		}
+		created = false
 	}
 	if created {
 		u.UserID = int(id)
@@ -257,8 +261,12 @@ func OnBoardUser(u *models.User) error {
 			}
 		}
 	} else {
-		existing, err := GetUser(*u)
-		if err != nil {
+		existing, e := GetUser(models.User{Username: u.Username})
+		if e != nil {
+			return e
+		}
+		if existing == nil {
+			// the duplicated record isn't the user name, e.g. the email is used by another user
 			return err
 		}
 		u.Email = existing.Email
@@ -298,3 +306,88 @@ func matchPassword(u *models.User, password string) bool {
 	}
 	return true
 }
+
+// lastLoginUpdateInterval avoids writing the last login time of the user for every request
+const lastLoginUpdateInterval = time.Minute
+
+// UpdateLastLoginTime records the time when the user logs in
+func UpdateLastLoginTime(userID int, t time.Time) error {
+	_, err := GetOrmer().Raw(`update harbor_user set last_login_at = ?
+		where user_id = ? and (last_login_at is null or last_login_at < ?)`,
+		t, userID, t.Add(-lastLoginUpdateInterval)).Exec()
+	return err
+}
+
+type lastSeenUser struct {
+	UserID      int       `orm:"column(user_id)"`
+	Username    string    `orm:"column(username)"`
+	Email       string    `orm:"column(email)"`
+	LastLoginAt time.Time `orm:"column(last_login_at)"`
+	LastSeenAt  time.Time `orm:"column(last_seen_at)"`
+}
+
+// ListInactiveUsers returns at most limit users who haven't logged in for more than the days, the
+// creation time is used for the users who never log in. The most inactive ones are returned first.
+// The deleted users, the disabled users and the super user are excluded.
+func ListInactiveUsers(days, limit int) ([]*models.InactiveUser, error) {
+	now := time.Now()
+	rows := []*lastSeenUser{}
+	if _, err := GetOrmer().Raw(`select user_id, username, email, last_login_at,
+		coalesce(last_login_at, creation_time) as last_seen_at
+		from harbor_user
+		where deleted = false and disabled = false and user_id <> 1
+		and coalesce(last_login_at, creation_time) < ?
+		order by last_seen_at, user_id
+		limit ?`, now.AddDate(0, 0, -days), limit).QueryRows(&rows); err != nil {
+		return nil, err
+	}
+
+	users := make([]*models.InactiveUser, 0, len(rows))
+	for _, row := range rows {
+		user := &models.InactiveUser{
+			UserID:       row.UserID,
+			Username:     row.Username,
+			Email:        row.Email,
+			DaysInactive: int(now.Sub(row.LastSeenAt).Hours() / 24),
+		}
+		if !row.LastLoginAt.IsZero() {
+			t := row.LastLoginAt
+			user.LastLoginAt = &t
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// DisableUsers disables the users and records the audit log for each of them in one transaction,
+// the users which have been disabled or deleted are skipped
+func DisableUsers(actor string, users []*models.InactiveUser) error {
+	if len(users) == 0 {
+		return nil
+	}
+	return WithTransaction(func(o orm.Ormer) error {
+		for _, user := range users {
+			result, err := o.Raw(`update harbor_user set disabled = true
+				where user_id = ? and disabled = false and deleted = false`, user.UserID).Exec()
+			if err != nil {
+				return err
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				continue
+			}
+			if _, err = o.Insert(&models.UserAuditEntry{
+				Actor:    actor,
+				UserID:   user.UserID,
+				Username: user.Username,
+				Action:   models.UserAuditActionDisable,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
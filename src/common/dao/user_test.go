@@ -16,10 +16,12 @@ package dao
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDeleteUser(t *testing.T) {
@@ -90,6 +92,32 @@ func TestOnBoardUser(t *testing.T) {
 	assert.True(u.UserID == id)
 	CleanUser(int64(id))
 }
+func TestOnBoardUserConcurrently(t *testing.T) {
+	n := 5
+	ids := make([]int, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			u := &models.User{
+				Username: "concurrent_onboard",
+				Realname: "concurrent_onboard",
+			}
+			errs[i] = OnBoardUser(u)
+			ids[i] = u.UserID
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < n; i++ {
+		require.Nil(t, errs[i])
+		assert.Equal(t, ids[0], ids[i])
+	}
+	assert.True(t, ids[0] > 0)
+	CleanUser(int64(ids[0]))
+}
+
 func TestOnBoardUser_EmptyEmail(t *testing.T) {
 	assert := assert.New(t)
 	u := &models.User{
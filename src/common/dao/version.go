@@ -32,3 +32,13 @@ func GetSchemaVersion() (*models.SchemaVersion, error) {
 	}
 	return version, nil
 }
+
+// GetMigrationVersion returns the version of database schema recorded by the migrator
+func GetMigrationVersion() (*models.MigrationVersion, error) {
+	version := &models.MigrationVersion{}
+	if err := GetOrmer().Raw("select version, dirty from schema_migrations").
+		QueryRow(version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
@@ -26,3 +26,10 @@ func TestGetSchemaVersion(t *testing.T) {
 	require.Nil(t, err)
 	assert.Equal(t, SchemaVersion, version.Version)
 }
+
+func TestGetMigrationVersion(t *testing.T) {
+	version, err := GetMigrationVersion()
+	require.Nil(t, err)
+	assert.True(t, version.Version > 0)
+	assert.False(t, version.Dirty)
+}
@@ -16,6 +16,7 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -34,6 +35,8 @@ import (
 type Client struct {
 	modifiers []modifier.Modifier
 	client    *http.Client
+	// the context which the requests are bound to, nil means no context
+	ctx context.Context
 }
 
 var defaultHTTPTransport, secureHTTPTransport, insecureHTTPTransport *http.Transport
@@ -86,6 +89,21 @@ func NewClient(c *http.Client, modifiers ...modifier.Modifier) *Client {
 	return client
 }
 
+// WithContext returns a copy of the client whose requests are bound to the context,
+// the requests in flight are aborted once the context is done
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cp := *c
+	cp.ctx = ctx
+	return &cp
+}
+
+func (c *Client) newRequest(method, url string, body io.Reader) (*http.Request, error) {
+	if c.ctx == nil {
+		return http.NewRequest(method, url, body)
+	}
+	return http.NewRequestWithContext(c.ctx, method, url, body)
+}
+
 // Do ...
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	for _, modifier := range c.modifiers {
@@ -99,7 +117,7 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 
 // Get ...
 func (c *Client) Get(url string, v ...interface{}) error {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := c.newRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return err
 	}
@@ -118,7 +136,7 @@ func (c *Client) Get(url string, v ...interface{}) error {
 
 // Head ...
 func (c *Client) Head(url string) error {
-	req, err := http.NewRequest(http.MethodHead, url, nil)
+	req, err := c.newRequest(http.MethodHead, url, nil)
 	if err != nil {
 		return err
 	}
@@ -126,7 +144,7 @@ func (c *Client) Head(url string) error {
 	return err
 }
 
-// Post ...
+// Post sends the v[0] as the request body, the response is unmarshalled into v[1] if specified
 func (c *Client) Post(url string, v ...interface{}) error {
 	var reader io.Reader
 	if len(v) > 0 {
@@ -142,13 +160,21 @@ func (c *Client) Post(url string, v ...interface{}) error {
 		}
 	}
 
-	req, err := http.NewRequest(http.MethodPost, url, reader)
+	req, err := c.newRequest(http.MethodPost, url, reader)
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	_, err = c.do(req)
-	return err
+	data, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	if len(v) < 2 || len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, v[1])
 }
 
 // Put ...
@@ -163,7 +189,7 @@ func (c *Client) Put(url string, v ...interface{}) error {
 		reader = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequest(http.MethodPut, url, reader)
+	req, err := c.newRequest(http.MethodPut, url, reader)
 	if err != nil {
 		return err
 	}
@@ -173,13 +199,22 @@ func (c *Client) Put(url string, v ...interface{}) error {
 }
 
 // Delete ...
-func (c *Client) Delete(url string) error {
-	req, err := http.NewRequest(http.MethodDelete, url, nil)
+func (c *Client) Delete(url string, v ...interface{}) error {
+	req, err := c.newRequest(http.MethodDelete, url, nil)
 	if err != nil {
 		return err
 	}
-	_, err = c.do(req)
-	return err
+
+	data, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	if len(v) == 0 || len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, v[0])
 }
 
 func (c *Client) do(req *http.Request) ([]byte, error) {
@@ -223,7 +258,7 @@ func (c *Client) GetAndIteratePagination(endpoint string, v interface{}) error {
 
 	resources := reflect.Indirect(reflect.New(elemType))
 	for len(endpoint) > 0 {
-		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		req, err := c.newRequest(http.MethodGet, endpoint, nil)
 		if err != nil {
 			return err
 		}
@@ -1,9 +1,15 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetHTTPTransport(t *testing.T) {
@@ -12,3 +18,30 @@ func TestGetHTTPTransport(t *testing.T) {
 	transport = GetHTTPTransport(false)
 	assert.False(t, transport.TLSClientConfig.InsecureSkipVerify)
 }
+
+func TestWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stuck" {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"name":"value"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	ctxClient := client.WithContext(ctx)
+
+	// the request in flight is aborted once the context is done
+	err := ctxClient.Get(server.URL + "/stuck")
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// the original client isn't bound to the context
+	v := map[string]string{}
+	require.Nil(t, client.Get(server.URL, &v))
+	assert.Equal(t, "value", v["name"])
+	assert.NotNil(t, ctxClient.Get(server.URL, &v))
+}
@@ -3,6 +3,7 @@ package job
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -22,15 +23,21 @@ var (
 	GlobalClient             Client
 	statusBehindErrorPattern = "mismatch job status for stopping job: .*, job status (.*) is behind Running"
 	statusBehindErrorReg     = regexp.MustCompile(statusBehindErrorPattern)
+
+	// ErrScheduleUpdateUnsupported is returned when the jobservice doesn't support updating
+	// the schedule of the periodic job in place, the caller should recreate the job instead
+	ErrScheduleUpdateUnsupported = errors.New("updating the schedule of job is not supported by the jobservice")
 )
 
 // Client wraps interface to access jobservice.
 type Client interface {
 	SubmitJob(*models.JobData) (string, error)
 	GetJobLog(uuid string) ([]byte, error)
+	GetJobLogStream(uuid, rangeHeader string) (*http.Response, error)
+	DeleteJobLog(uuid string) error
 	PostAction(uuid, action string) error
 	GetExecutions(uuid string) ([]job.Stats, error)
-	// TODO Redirect joblog when we see there's memory issue.
+	UpdateJobSchedule(uuid, cron string) error
 }
 
 // StatusBehindError represents the error got when trying to stop a success/failed job
@@ -136,6 +143,61 @@ func (d *DefaultClient) GetJobLog(uuid string) ([]byte, error) {
 	return data, nil
 }
 
+// DeleteJobLog call jobservice API to delete the log of a job. It only accepts the UUID of the job
+func (d *DefaultClient) DeleteJobLog(uuid string) error {
+	url := d.endpoint + "/api/v1/jobs/" + uuid + "/log"
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return &commonhttp.Error{
+		Code:    resp.StatusCode,
+		Message: string(data),
+	}
+}
+
+// GetJobLogStream calls jobservice API to get the log of a job without loading it into memory, the
+// range header is passed through if it isn't empty. The response is returned only when the status is
+// 200 or 206 and the caller must close its body, otherwise the status is returned as an error.
+func (d *DefaultClient) GetJobLogStream(uuid, rangeHeader string) (*http.Response, error) {
+	url := d.endpoint + "/api/v1/jobs/" + uuid + "/log"
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(rangeHeader) > 0 {
+		req.Header.Set("Range", rangeHeader)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return nil, &commonhttp.Error{
+		Code:    resp.StatusCode,
+		Message: string(data),
+	}
+}
+
 // GetExecutions ...
 func (d *DefaultClient) GetExecutions(periodicJobID string) ([]job.Stats, error) {
 	url := fmt.Sprintf("%s/api/v1/jobs/%s/executions?page_number=1&page_size=100", d.endpoint, periodicJobID)
@@ -196,3 +258,41 @@ func isStatusBehindError(err error) (string, bool) {
 	}
 	return strs[1], true
 }
+
+// UpdateJobSchedule call jobservice's API to update the cron spec of the periodic job specified by uuid
+// without recreating it. ErrScheduleUpdateUnsupported is returned if the jobservice doesn't support it
+func (d *DefaultClient) UpdateJobSchedule(uuid, cron string) error {
+	url := d.endpoint + "/api/v1/jobs/" + uuid
+	b, err := json.Marshal(&job.ScheduleUpdateRequest{
+		CronSpec: cron,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	// the jobservice of old versions only accepts the POST method on the job
+	case http.StatusMethodNotAllowed:
+		return ErrScheduleUpdateUnsupported
+	default:
+		return &commonhttp.Error{
+			Code:    resp.StatusCode,
+			Message: string(data),
+		}
+	}
+}
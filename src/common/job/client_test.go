@@ -2,12 +2,17 @@ package job
 
 import (
 	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
+	commonhttp "github.com/goharbor/harbor/src/common/http"
 	"github.com/goharbor/harbor/src/common/job/models"
 	"github.com/goharbor/harbor/src/common/job/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -49,6 +54,41 @@ func TestGetJobLog(t *testing.T) {
 	assert.Contains(text, "The content in this file is for mocking the get log api.")
 }
 
+func TestDeleteJobLog(t *testing.T) {
+	err := testClient.DeleteJobLog("non")
+	require.NotNil(t, err)
+	httpErr, ok := err.(*commonhttp.Error)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, httpErr.Code)
+
+	assert.Nil(t, testClient.DeleteJobLog(ID))
+}
+
+func TestGetJobLogStream(t *testing.T) {
+	_, err := testClient.GetJobLogStream("non", "")
+	require.NotNil(t, err)
+	httpErr, ok := err.(*commonhttp.Error)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, httpErr.Code)
+
+	resp, err := testClient.GetJobLogStream(ID, "")
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(b), "The content in this file is for mocking the get log api.")
+
+	// the range header is passed through
+	resp, err = testClient.GetJobLogStream(ID, "bytes=4-10")
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	b, err = ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "content", string(b))
+}
+
 func TestGetExecutions(t *testing.T) {
 	assert := assert.New(t)
 	exes, err := testClient.GetExecutions(ID)
@@ -65,6 +105,22 @@ func TestPostAction(t *testing.T) {
 	assert.Nil(err2)
 }
 
+func TestUpdateJobSchedule(t *testing.T) {
+	assert := assert.New(t)
+	err := testClient.UpdateJobSchedule(ID, "")
+	assert.NotNil(err)
+	err = testClient.UpdateJobSchedule(ID, "0 0 * * * *")
+	assert.Nil(err)
+
+	// the jobservice doesn't support updating the schedule
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer server.Close()
+	err = NewDefaultClient(server.URL, "").UpdateJobSchedule(ID, "0 0 * * * *")
+	assert.Equal(ErrScheduleUpdateUnsupported, err)
+}
+
 func TestIsStatusBehindError(t *testing.T) {
 	// nil error
 	status, flag := isStatusBehindError(nil)
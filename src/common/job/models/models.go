@@ -23,7 +23,14 @@ type JobMetadata struct {
 	JobKind       string `json:"kind"`
 	ScheduleDelay uint64 `json:"schedule_delay,omitempty"`
 	Cron          string `json:"cron_spec,omitempty"`
-	IsUnique      bool   `json:"unique"`
+	// Timezone is the IANA name of the timezone the cron is evaluated in, the local one of job service if empty
+	Timezone string `json:"timezone,omitempty"`
+	IsUnique bool   `json:"unique"`
+	// MaxConcurrent is the max number of the running instances of the job, 0 means no limit.
+	// It's checked by core before submitting the job, so it isn't sent to job service
+	MaxConcurrent int `json:"-"`
+	// MaxFails overrides the max fails declared by the job if it's set, 1 means no retry
+	MaxFails uint `json:"max_fails,omitempty"`
 }
 
 // JobStats keeps the result of job launching.
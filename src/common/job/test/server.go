@@ -1,6 +1,7 @@
 package test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -34,18 +35,19 @@ func NewJobServiceServer() *httptest.Server {
 	mux := http.NewServeMux()
 	mux.HandleFunc(fmt.Sprintf("%s/%s/log", jobsPrefix, jobUUID),
 		func(rw http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodDelete {
+				rw.WriteHeader(http.StatusNoContent)
+				return
+			}
 			if req.Method != http.MethodGet {
 				rw.WriteHeader(http.StatusMethodNotAllowed)
 				return
 			}
 			rw.Header().Add("Content-Type", "text/plain")
-			rw.WriteHeader(http.StatusOK)
 			f := path.Join(currPath(), "test.log")
 			b, _ := ioutil.ReadFile(f)
-			_, err := rw.Write(b)
-			if err != nil {
-				panic(err)
-			}
+			// the Range header is honored as the job service does
+			http.ServeContent(rw, req, "", time.Time{}, bytes.NewReader(b))
 		})
 	mux.HandleFunc(fmt.Sprintf("%s/%s/executions", jobsPrefix, jobUUID),
 		func(rw http.ResponseWriter, req *http.Request) {
@@ -72,7 +74,7 @@ func NewJobServiceServer() *httptest.Server {
 		})
 	mux.HandleFunc(fmt.Sprintf("%s/%s", jobsPrefix, jobUUID),
 		func(rw http.ResponseWriter, req *http.Request) {
-			if req.Method != http.MethodPost {
+			if req.Method != http.MethodPost && req.Method != http.MethodPatch {
 				rw.WriteHeader(http.StatusMethodNotAllowed)
 				return
 			}
@@ -80,6 +82,18 @@ func NewJobServiceServer() *httptest.Server {
 			if err != nil {
 				panic(err)
 			}
+			if req.Method == http.MethodPatch {
+				update := job.ScheduleUpdateRequest{}
+				if err := json.Unmarshal(data, &update); err != nil {
+					panic(err)
+				}
+				if len(update.CronSpec) == 0 {
+					rw.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				rw.WriteHeader(http.StatusNoContent)
+				return
+			}
 			action := models.JobActionRequest{}
 			if err := json.Unmarshal(data, &action); err != nil {
 				panic(err)
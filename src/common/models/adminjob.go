@@ -22,6 +22,8 @@ import (
 const (
 	// AdminJobTable is table name for admin job
 	AdminJobTable = "admin_job"
+	// AdminJobCheckInKindStats is the kind of the check-in carrying the statistics of the execution of admin job
+	AdminJobCheckInKindStats = "stats"
 )
 
 // AdminJob ...
@@ -32,11 +34,28 @@ type AdminJob struct {
 	Cron         string    `orm:"column(cron_str)"  json:"cron_str"`
 	Status       string    `orm:"column(status)"  json:"job_status"`
 	UUID         string    `orm:"column(job_uuid)" json:"-"`
+	Parameters   string    `orm:"column(job_parameters)" json:"-"`
+	Summary      string    `orm:"column(summary);type(json)" json:"-"`
+	Stats        string    `orm:"column(stats);type(json)" json:"-"`
+	ScheduleName string    `orm:"column(schedule_name)" json:"schedule_name"`
 	Deleted      bool      `orm:"column(deleted)" json:"deleted"`
 	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
 	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
 }
 
+// GCStats is the statistics of the execution of GC, it's checked in by the GC job
+// with the kind AdminJobCheckInKindStats when the GC completes
+type GCStats struct {
+	Kind             string `json:"kind,omitempty"`
+	BlobsDeleted     int    `json:"blobs_deleted"`
+	ManifestsDeleted int    `json:"manifests_deleted"`
+	BytesFreed       int64  `json:"bytes_freed"`
+	// Duration of the GC in seconds
+	Duration int64 `json:"duration"`
+	// Count of the errors which don't fail the GC, e.g. the size of the blob is unknown
+	Errors int `json:"errors"`
+}
+
 // TableName is required by by beego orm to map AdminJob to table AdminJob
 func (a *AdminJob) TableName() string {
 	return AdminJobTable
@@ -51,6 +70,12 @@ type AdminJobQuery struct {
 	UUID    string
 	Deleted bool
 	Pagination
+	// ScheduleName matches the schedules with the name, the empty string matches the default schedule
+	// and nil matches all
+	ScheduleName *string
+	Statuses     []string   // the jobs in any of the statuses
+	StartTime    *time.Time // the time after which the job is updated
+	EndTime      *time.Time // the time before which the job is updated
 }
 
 // ScheduleParam ...
@@ -0,0 +1,41 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+// APIKey is the key authenticating the requests on behalf of the user, only the hash of the key is stored
+type APIKey struct {
+	ID      int64  `orm:"pk;auto;column(id)" json:"id"`
+	UserID  int    `orm:"column(user_id)" json:"user_id"`
+	Name    string `orm:"column(name)" json:"name"`
+	KeyHash string `orm:"column(key_hash)" json:"-"`
+	// the unix timestamp after which the key is expired, 0 means never
+	ExpiresAt    int64     `orm:"column(expires_at)" json:"expires_at"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (a *APIKey) TableName() string {
+	return "user_api_key"
+}
+
+// IsExpired checks whether the key is expired at the time
+func (a *APIKey) IsExpired(t time.Time) bool {
+	return a.ExpiresAt > 0 && t.Unix() >= a.ExpiresAt
+}
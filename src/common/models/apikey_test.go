@@ -0,0 +1,30 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyIsExpired(t *testing.T) {
+	now := time.Now()
+	assert.False(t, (&APIKey{}).IsExpired(now))
+	assert.False(t, (&APIKey{ExpiresAt: now.Add(time.Minute).Unix()}).IsExpired(now))
+	assert.True(t, (&APIKey{ExpiresAt: now.Unix()}).IsExpired(now))
+	assert.True(t, (&APIKey{ExpiresAt: now.Add(-time.Minute).Unix()}).IsExpired(now))
+}
@@ -30,3 +30,27 @@ type ArtifactQuery struct {
 	Digest string
 	Pagination
 }
+
+// ArtifactReference locates the artifact with the names of its project and repository
+type ArtifactReference struct {
+	ProjectID   int64  `orm:"column(project_id)" json:"project_id"`
+	ProjectName string `orm:"column(project_name)" json:"project_name"`
+	Repo        string `orm:"column(repo)" json:"repository_name"`
+	Tag         string `orm:"column(tag)" json:"tag"`
+	Digest      string `orm:"column(digest)" json:"digest"`
+}
+
+// ArtifactTypeStorage is the storage used by the artifacts of one type in a project
+type ArtifactTypeStorage struct {
+	ArtifactType  string  `orm:"column(artifact_type)" json:"artifact_type"`
+	SizeBytes     int64   `orm:"column(size_bytes)" json:"size_bytes"`
+	ArtifactCount int64   `orm:"column(artifact_count)" json:"artifact_count"`
+	Percentage    float64 `orm:"-" json:"percentage"`
+}
+
+// StorageBreakdown is the storage of a project broken down by the artifact type
+type StorageBreakdown struct {
+	StorageByType []*ArtifactTypeStorage `json:"storage_by_type"`
+	// the size of the blobs not referenced by any artifact of the project
+	UntaggedStorage int64 `json:"untagged_storage"`
+}
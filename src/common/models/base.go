@@ -34,8 +34,10 @@ func init() {
 		new(AdminJob),
 		new(JobLog),
 		new(OIDCUser),
+		new(SAMLUser),
 		new(NotificationPolicy),
 		new(NotificationJob),
+		new(WebhookDeliveryLog),
 		new(Blob),
 		new(ProjectBlob),
 		new(Artifact),
@@ -43,5 +45,11 @@ func init() {
 		new(CVEWhitelist),
 		new(Quota),
 		new(QuotaUsage),
+		new(ProjectConfigChange),
+		new(ScheduleAuditEntry),
+		new(UserAuditEntry),
+		new(SigningKey),
+		new(InternalSecret),
+		new(APIKey),
 	)
 }
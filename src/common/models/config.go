@@ -73,6 +73,8 @@ type HTTPAuthProxy struct {
 	TokenReviewEndpoint string `json:"tokenreivew_endpoint"`
 	VerifyCert          bool   `json:"verify_cert"`
 	SkipSearch          bool   `json:"skip_search"`
+	AutoOnboard         bool   `json:"auto_onboard"`
+	APIAccess           bool   `json:"api_access"`
 }
 
 // OIDCSetting wraps the settings for OIDC auth endpoint
@@ -87,6 +89,15 @@ type OIDCSetting struct {
 	Scope        []string `json:"scope"`
 }
 
+// SAMLSetting wraps the settings for SAML auth
+type SAMLSetting struct {
+	IdPSSOURL        string            `json:"idp_sso_url"`
+	IdPCertificate   string            `json:"idp_certificate"`
+	EntityID         string            `json:"entity_id"`
+	ACSURL           string            `json:"acs_url"`
+	AttributeMapping map[string]string `json:"attribute_mapping"`
+}
+
 // QuotaSetting wraps the settings for Quota
 type QuotaSetting struct {
 	CountPerProject   int64 `json:"count_per_project"`
@@ -10,6 +10,8 @@ const (
 	NotificationPolicyTable = "notification_policy"
 	// NotificationJobTable is table name for notification job
 	NotificationJobTable = "notification_job"
+	// WebhookDeliveryLogTable is table name for the delivery logs of webhook
+	WebhookDeliveryLogTable = "webhook_delivery_log"
 )
 
 // NotificationPolicy is the model for a notification policy.
@@ -26,6 +28,9 @@ type NotificationPolicy struct {
 	CreationTime time.Time     `orm:"column(creation_time);auto_now_add" json:"creation_time"`
 	UpdateTime   time.Time     `orm:"column(update_time);auto_now_add" json:"update_time"`
 	Enabled      bool          `orm:"column(enabled)" json:"enabled"`
+	// The scan completed event is only sent when the severity of the report reaches the threshold,
+	// empty means always sending it
+	ScanSeverityThreshold string `orm:"column(scan_severity_threshold)" json:"scan_severity_threshold,omitempty"`
 }
 
 // TableName set table name for ORM.
@@ -109,3 +114,37 @@ type EventTarget struct {
 	AuthHeader     string `json:"auth_header,omitempty"`
 	SkipCertVerify bool   `json:"skip_cert_verify"`
 }
+
+// WebhookDeliveryLog records the result of one delivery of the webhook notification job
+type WebhookDeliveryLog struct {
+	ID         int64 `orm:"pk;auto;column(id)" json:"id"`
+	PolicyID   int64 `orm:"column(policy_id)" json:"policy_id"`
+	JobID      int64 `orm:"column(job_id)" json:"job_id"`
+	StatusCode int   `orm:"column(status_code)" json:"status_code"`
+	DurationMS int64 `orm:"column(duration_ms)" json:"duration_ms"`
+	// Success is true when the target responds with 2xx status code
+	Success      bool      `orm:"column(success)" json:"success"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+}
+
+// TableName set table name for ORM.
+func (w *WebhookDeliveryLog) TableName() string {
+	return WebhookDeliveryLogTable
+}
+
+// WebhookDeliveryStats is the statistics of the deliveries of a webhook policy in a period
+type WebhookDeliveryStats struct {
+	PeriodDays      int                          `json:"period_days"`
+	TotalDeliveries int64                        `json:"total_deliveries"`
+	Successful      int64                        `json:"successful"`
+	Failed          int64                        `json:"failed"`
+	AvgDurationMS   float64                      `json:"avg_duration_ms"`
+	ErrorBreakdown  []*WebhookDeliveryErrorCount `json:"error_breakdown"`
+}
+
+// WebhookDeliveryErrorCount is the count of the failed deliveries with the status code,
+// the status code is 0 if no response is received from the target
+type WebhookDeliveryErrorCount struct {
+	StatusCode int   `orm:"column(status_code)" json:"status_code"`
+	Count      int64 `orm:"column(count)" json:"count"`
+}
@@ -0,0 +1,35 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+// InternalSecret is the secret of the internal component rotated via the API
+type InternalSecret struct {
+	ID       int64  `orm:"pk;auto;column(id)" json:"id"`
+	Username string `orm:"column(username)" json:"username"`
+	// the secret encrypted by the secret key
+	Secret string `orm:"column(secret)" json:"-"`
+	// zero for the current secret, the previous one is accepted until the time
+	ExpiresAt    time.Time `orm:"column(expires_at);null" json:"expires_at"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+}
+
+// TableName ...
+func (s *InternalSecret) TableName() string {
+	return "internal_secret"
+}
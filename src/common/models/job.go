@@ -17,6 +17,8 @@ package models
 const (
 	// JobPending ...
 	JobPending string = "pending"
+	// JobPendingSubmission indicates the job failed to be submitted to the job service and will be re-submitted.
+	JobPendingSubmission string = "pending_submission"
 	// JobRunning ...
 	JobRunning string = "running"
 	// JobError ...
@@ -0,0 +1,41 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ProjectConfigChange records one change of the configuration of a project
+type ProjectConfigChange struct {
+	ID        int64           `orm:"pk;auto;column(id)" json:"id"`
+	ProjectID int64           `orm:"column(project_id)" json:"project_id"`
+	ChangedAt time.Time       `orm:"column(changed_at);auto_now_add" json:"changed_at"`
+	ChangedBy string          `orm:"column(changed_by)" json:"changed_by"`
+	Diff      json.RawMessage `orm:"-" json:"diff"`
+	DiffText  string          `orm:"column(diff)" json:"-"`
+}
+
+// TableName ...
+func (p *ProjectConfigChange) TableName() string {
+	return "project_config_history"
+}
+
+// ProjectConfigChangeQuery is the query parameters for the history of project configuration
+type ProjectConfigChangeQuery struct {
+	ProjectID int64
+	Pagination
+}
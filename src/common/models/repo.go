@@ -61,6 +61,19 @@ type TagResp struct {
 	PullTime     time.Time              `json:"pull_time"`
 }
 
+// TagsDeletionResult is the result of deleting the tags of a repository in batch
+type TagsDeletionResult struct {
+	Deleted  []string `json:"deleted"`
+	NotFound []string `json:"not_found"`
+	// the error messages keyed by the tags failed to be deleted
+	Failed map[string]string `json:"failed"`
+}
+
+// ReclaimableSizesRequest specifies the tags of a repository deleted all together
+type ReclaimableSizesRequest struct {
+	Tags []string `json:"tags"`
+}
+
 // TagDetail ...
 type TagDetail struct {
 	Digest        string    `json:"digest"`
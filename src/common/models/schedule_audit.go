@@ -0,0 +1,36 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+// ScheduleAuditEntry records one change of the schedule of an admin job
+type ScheduleAuditEntry struct {
+	ID        int64     `orm:"pk;auto;column(id)" json:"id"`
+	Actor     string    `orm:"column(actor)" json:"actor"`
+	JobName   string    `orm:"column(job_name)" json:"job_name"`
+	OldCron   string    `orm:"column(old_cron)" json:"old_cron"`
+	NewCron   string    `orm:"column(new_cron)" json:"new_cron"`
+	ChangedAt time.Time `orm:"column(changed_at);auto_now_add" json:"changed_at"`
+	// The name of the schedule, it's empty for the default schedule
+	ScheduleName string `orm:"column(schedule_name)" json:"schedule_name,omitempty"`
+}
+
+// TableName ...
+func (s *ScheduleAuditEntry) TableName() string {
+	return "schedule_audit_log"
+}
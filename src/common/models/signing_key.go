@@ -0,0 +1,41 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+const (
+	// SigningKeyActive is the status of the key signing the tokens
+	SigningKeyActive = "active"
+	// SigningKeyRetiring is the status of the key which is replaced by the active one but still
+	// accepted to verify the tokens during the grace period of the key rotation
+	SigningKeyRetiring = "retiring"
+)
+
+// SigningKey is the key signing the tokens issued by Harbor
+type SigningKey struct {
+	ID int64 `orm:"pk;auto;column(id)" json:"id"`
+	// the private key in PEM format encrypted by the secret key
+	PrivateKey   string    `orm:"column(private_key)" json:"-"`
+	Status       string    `orm:"column(status)" json:"status"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+}
+
+// TableName ...
+func (s *SigningKey) TableName() string {
+	return "token_signing_key"
+}
@@ -42,7 +42,21 @@ type User struct {
 	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
 	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
 	GroupIDs     []int     `orm:"-" json:"-"`
+	// the DNs of the LDAP groups including the nested ones the user is a member of, only populated by LDAP auth
+	LDAPGroupDNs []string  `orm:"-" json:"-"`
 	OIDCUserMeta *OIDCUser `orm:"-" json:"oidc_user_meta,omitempty"`
+	// the disabled user can not login any more
+	Disabled bool `orm:"column(disabled)" json:"disabled"`
+}
+
+// InactiveUser is the user who hasn't logged in for a period
+type InactiveUser struct {
+	UserID      int        `json:"user_id"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	// the days since the last login or the creation if the user never logs in
+	DaysInactive int `json:"days_inactive"`
 }
 
 // UserQuery ...
@@ -0,0 +1,39 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+// the actions recorded in the user audit log
+const (
+	UserAuditActionDisable = "disable"
+)
+
+// UserAuditEntry records one change of a user account
+type UserAuditEntry struct {
+	ID       int64     `orm:"pk;auto;column(id)" json:"id"`
+	Actor    string    `orm:"column(actor)" json:"actor"`
+	UserID   int       `orm:"column(user_id)" json:"user_id"`
+	Username string    `orm:"column(username)" json:"username"`
+	Action   string    `orm:"column(action)" json:"action"`
+	OpTime   time.Time `orm:"column(op_time);auto_now_add" json:"op_time"`
+}
+
+// TableName ...
+func (u *UserAuditEntry) TableName() string {
+	return "user_audit_log"
+}
@@ -18,3 +18,9 @@ package models
 type SchemaVersion struct {
 	Version string `json:"version" orm:"column(version_num)"`
 }
+
+// MigrationVersion is the version of database schema recorded by the migrator
+type MigrationVersion struct {
+	Version int64 `json:"version" orm:"column(version)"`
+	Dirty   bool  `json:"dirty" orm:"column(dirty)"`
+}
@@ -14,6 +14,11 @@
 
 package secret
 
+import (
+	"sync"
+	"time"
+)
+
 const (
 	// JobserviceUser is the name of jobservice user
 	JobserviceUser = "harbor-jobservice"
@@ -23,15 +28,24 @@ const (
 
 // Store the secrets and provides methods to validate secrets
 type Store struct {
+	lock sync.RWMutex
 	// the key is secret
 	// the value is username
 	secrets map[string]string
+	// the secrets replaced by the rotation, they are valid until the expiration
+	previous map[string]*previousSecret
+}
+
+type previousSecret struct {
+	username  string
+	expiresAt time.Time
 }
 
 // NewStore ...
 func NewStore(secrets map[string]string) *Store {
 	return &Store{
-		secrets: secrets,
+		secrets:  secrets,
+		previous: map[string]*previousSecret{},
 	}
 }
 
@@ -42,5 +56,68 @@ func (s *Store) IsValid(secret string) bool {
 
 // GetUsername returns the corresponding username of the secret
 func (s *Store) GetUsername(secret string) string {
-	return s.secrets[secret]
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if username, ok := s.secrets[secret]; ok {
+		return username
+	}
+	if p, ok := s.previous[secret]; ok && time.Now().Before(p.expiresAt) {
+		return p.username
+	}
+	return ""
+}
+
+// Rotate replaces the secret of the user with the new one, the previous secret is still
+// valid within the grace period so that the components holding it keep working until
+// they get the new one
+func (s *Store) Rotate(username, secret string, grace time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	for scrt, p := range s.previous {
+		if !now.Before(p.expiresAt) {
+			delete(s.previous, scrt)
+		}
+	}
+	for scrt, name := range s.secrets {
+		if name != username {
+			continue
+		}
+		delete(s.secrets, scrt)
+		if scrt != secret && grace > 0 {
+			s.previous[scrt] = &previousSecret{
+				username:  username,
+				expiresAt: now.Add(grace),
+			}
+		}
+	}
+	delete(s.previous, secret)
+	s.secrets[secret] = username
+}
+
+// Set replaces the secrets of the user with the current one and the previous ones which are valid
+// until the time, it applies the secrets rotated by the other instances
+func (s *Store) Set(username, current string, previous map[string]time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for scrt, name := range s.secrets {
+		if name == username {
+			delete(s.secrets, scrt)
+		}
+	}
+	for scrt, p := range s.previous {
+		if p.username == username {
+			delete(s.previous, scrt)
+		}
+	}
+	now := time.Now()
+	for scrt, expiresAt := range previous {
+		if scrt != current && now.Before(expiresAt) {
+			s.previous[scrt] = &previousSecret{
+				username:  username,
+				expiresAt: expiresAt,
+			}
+		}
+	}
+	s.secrets[current] = username
 }
@@ -16,6 +16,7 @@ package secret
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -37,3 +38,65 @@ func TestGetUsername(t *testing.T) {
 	assert.Equal(t, "", store.GetUsername("invalid_secret"))
 	assert.Equal(t, "username1", store.GetUsername("secret1"))
 }
+
+func TestRotate(t *testing.T) {
+	store := NewStore(map[string]string{
+		"secret1": "username1",
+		"secret2": "username2",
+	})
+
+	store.Rotate("username1", "secret3", 50*time.Millisecond)
+	// both the current and the previous secrets are valid within the grace period
+	assert.Equal(t, "username1", store.GetUsername("secret3"))
+	assert.Equal(t, "username1", store.GetUsername("secret1"))
+	// the secrets of the other users are not affected
+	assert.Equal(t, "username2", store.GetUsername("secret2"))
+
+	// only the current one is valid after the grace period
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, store.IsValid("secret3"))
+	assert.False(t, store.IsValid("secret1"))
+	assert.True(t, store.IsValid("secret2"))
+}
+
+func TestRotateWithoutGracePeriod(t *testing.T) {
+	store := NewStore(map[string]string{
+		"secret1": "username1",
+	})
+	store.Rotate("username1", "secret2", 0)
+	assert.False(t, store.IsValid("secret1"))
+	assert.True(t, store.IsValid("secret2"))
+}
+
+func TestRotateBack(t *testing.T) {
+	store := NewStore(map[string]string{
+		"secret1": "username1",
+	})
+	store.Rotate("username1", "secret2", time.Minute)
+	// rotating back to the previous secret makes it the current one
+	store.Rotate("username1", "secret1", 0)
+	assert.True(t, store.IsValid("secret1"))
+	assert.False(t, store.IsValid("secret2"))
+}
+
+func TestSet(t *testing.T) {
+	store := NewStore(map[string]string{
+		"secret1": "username1",
+		"secret2": "username2",
+	})
+	store.Rotate("username1", "secret3", time.Minute)
+
+	store.Set("username1", "secret4", map[string]time.Time{
+		"secret5": time.Now().Add(time.Minute),
+		"secret6": time.Now().Add(-time.Minute),
+	})
+	assert.Equal(t, "username1", store.GetUsername("secret4"))
+	assert.Equal(t, "username1", store.GetUsername("secret5"))
+	// the expired one is ignored
+	assert.False(t, store.IsValid("secret6"))
+	// the secrets not in the set are replaced
+	assert.False(t, store.IsValid("secret1"))
+	assert.False(t, store.IsValid("secret3"))
+	// the secrets of the other users are not affected
+	assert.Equal(t, "username2", store.GetUsername("secret2"))
+}
@@ -0,0 +1,68 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impersonation
+
+import (
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/security/local"
+	"github.com/goharbor/harbor/src/core/promgr"
+)
+
+// SecurityContext implements security.Context interface for the system admin impersonating
+// a user, it sees Harbor as the impersonated user does and only the read operations are
+// permitted unless the write is allowed explicitly
+type SecurityContext struct {
+	*local.SecurityContext
+	impersonator string
+	allowWrite   bool
+}
+
+// NewSecurityContext ...
+func NewSecurityContext(user *models.User, impersonator string, allowWrite bool,
+	pm promgr.ProjectManager) *SecurityContext {
+	return &SecurityContext{
+		SecurityContext: local.NewSecurityContext(user, pm),
+		impersonator:    impersonator,
+		allowWrite:      allowWrite,
+	}
+}
+
+// GetImpersonator returns the username of the system admin impersonating the user
+func (s *SecurityContext) GetImpersonator() string {
+	return s.impersonator
+}
+
+// AllowWrite returns whether the write operations are permitted
+func (s *SecurityContext) AllowWrite() bool {
+	return s.allowWrite
+}
+
+// Can returns whether the impersonated user can do action on resource
+func (s *SecurityContext) Can(action rbac.Action, resource rbac.Resource) bool {
+	if !s.allowWrite && !IsReadAction(action) {
+		return false
+	}
+	return s.SecurityContext.Can(action, resource)
+}
+
+// IsReadAction returns whether the action doesn't change anything
+func IsReadAction(action rbac.Action) bool {
+	switch action {
+	case rbac.ActionRead, rbac.ActionList, rbac.ActionPull:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impersonation
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/utils/test"
+	"github.com/goharbor/harbor/src/core/promgr"
+	"github.com/goharbor/harbor/src/core/promgr/pmsdriver/local"
+	"github.com/stretchr/testify/assert"
+)
+
+var pm = promgr.NewDefaultProjectManager(local.NewDriver(), true)
+
+func TestMain(m *testing.M) {
+	test.InitDatabaseFromEnv()
+	os.Exit(m.Run())
+}
+
+func TestCan(t *testing.T) {
+	user := &models.User{
+		UserID:       1,
+		Username:     "admin",
+		HasAdminRole: true,
+	}
+	resource := rbac.NewProjectNamespace(1).Resource(rbac.ResourceRepository)
+
+	// read only
+	ctx := NewSecurityContext(user, "impersonator", false, pm)
+	assert.True(t, ctx.IsAuthenticated())
+	assert.Equal(t, "admin", ctx.GetUsername())
+	assert.Equal(t, "impersonator", ctx.GetImpersonator())
+	assert.True(t, ctx.Can(rbac.ActionPull, resource))
+	assert.True(t, ctx.Can(rbac.ActionList, resource))
+	assert.False(t, ctx.Can(rbac.ActionPush, resource))
+	assert.False(t, ctx.Can(rbac.ActionDelete, resource))
+
+	// write allowed
+	ctx = NewSecurityContext(user, "impersonator", true, pm)
+	assert.True(t, ctx.Can(rbac.ActionPush, resource))
+	assert.True(t, ctx.Can(rbac.ActionDelete, resource))
+}
+
+func TestIsReadAction(t *testing.T) {
+	assert.True(t, IsReadAction(rbac.ActionRead))
+	assert.True(t, IsReadAction(rbac.ActionList))
+	assert.True(t, IsReadAction(rbac.ActionPull))
+	assert.False(t, IsReadAction(rbac.ActionCreate))
+	assert.False(t, IsReadAction(rbac.ActionUpdate))
+	assert.False(t, IsReadAction(rbac.ActionPush))
+	assert.False(t, IsReadAction(rbac.ActionOperate))
+}
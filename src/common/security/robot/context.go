@@ -73,6 +73,9 @@ func (s *SecurityContext) GetProjectRoles(projectIDOrName interface{}) []int {
 
 // Can returns whether the robot can do action on resource
 func (s *SecurityContext) Can(action rbac.Action, resource rbac.Resource) bool {
+	if !s.IsAuthenticated() {
+		return false
+	}
 	ns, err := resource.GetNamespace()
 	if err == nil {
 		switch ns.Kind() {
@@ -80,7 +83,13 @@ func (s *SecurityContext) Can(action rbac.Action, resource rbac.Resource) bool {
 			projectID := ns.Identity().(int64)
 			isPublicProject, _ := s.pm.IsPublic(projectID)
 			projectNamespace := rbac.NewProjectNamespace(projectID, isPublicProject)
-			robot := NewRobot(s.GetUsername(), projectNamespace, s.policy)
+			policies := s.policy
+			// the system level robot isn't bound to any project, its access to the project
+			// is evaluated against the policies in its token when the request comes
+			if s.robot.IsSystemLevel() {
+				policies = expandPolicies(projectNamespace, policies)
+			}
+			robot := NewRobot(s.GetUsername(), projectNamespace, policies)
 			return rbac.HasPermission(robot, resource, action)
 		}
 	}
@@ -190,6 +190,77 @@ func TestHasPushPullPerm(t *testing.T) {
 	assert.True(t, ctx.Can(rbac.ActionPush, resource) && ctx.Can(rbac.ActionPull, resource))
 }
 
+func TestProjectRobotScopedToItsProject(t *testing.T) {
+	other, err := dao.AddProject(models.Project{Name: "testrobot_other", OwnerID: 1})
+	require.Nil(t, err)
+	defer dao.DeleteProject(other)
+
+	policies := []*rbac.Policy{
+		{
+			Resource: rbac.Resource(fmt.Sprintf("/project/%d/repository", private.ProjectID)),
+			Action:   rbac.ActionPull,
+		},
+	}
+	robot := &model.Robot{
+		Name:      "test_robot_4",
+		ProjectID: private.ProjectID,
+	}
+
+	ctx := NewSecurityContext(robot, pm, policies)
+	assert.True(t, ctx.Can(rbac.ActionPull, rbac.NewProjectNamespace(private.ProjectID).Resource(rbac.ResourceRepository)))
+	assert.False(t, ctx.Can(rbac.ActionPull, rbac.NewProjectNamespace(other).Resource(rbac.ResourceRepository)))
+}
+
+func TestSystemRobotPerm(t *testing.T) {
+	var ids []int64
+	for _, name := range []string{"testrobot_system_1", "testrobot_system_2"} {
+		id, err := dao.AddProject(models.Project{Name: name, OwnerID: 1})
+		require.Nil(t, err)
+		defer dao.DeleteProject(id)
+		ids = append(ids, id)
+	}
+
+	robot := &model.Robot{
+		Name:        "test_robot_system",
+		Description: "desc",
+	}
+
+	// scoped to the listed projects
+	policies := []*rbac.Policy{
+		{
+			Resource: rbac.Resource(fmt.Sprintf("/project/%d/repository", private.ProjectID)),
+			Action:   rbac.ActionPull,
+		},
+		{
+			Resource: rbac.Resource(fmt.Sprintf("/project/%d/repository", ids[0])),
+			Action:   rbac.ActionPull,
+		},
+	}
+	ctx := NewSecurityContext(robot, pm, policies)
+	assert.True(t, ctx.Can(rbac.ActionPull, rbac.NewProjectNamespace(private.ProjectID).Resource(rbac.ResourceRepository)))
+	assert.True(t, ctx.Can(rbac.ActionPull, rbac.NewProjectNamespace(ids[0]).Resource(rbac.ResourceRepository)))
+	assert.False(t, ctx.Can(rbac.ActionPush, rbac.NewProjectNamespace(ids[0]).Resource(rbac.ResourceRepository)))
+	assert.False(t, ctx.Can(rbac.ActionPull, rbac.NewProjectNamespace(ids[1]).Resource(rbac.ResourceRepository)))
+
+	// scoped to every project
+	policies = []*rbac.Policy{
+		{
+			Resource: model.AllProjects.Subresource(rbac.ResourceRepository),
+			Action:   rbac.ActionPull,
+		},
+	}
+	ctx = NewSecurityContext(robot, pm, policies)
+	for _, id := range append(ids, private.ProjectID) {
+		assert.True(t, ctx.Can(rbac.ActionPull, rbac.NewProjectNamespace(id).Resource(rbac.ResourceRepository)))
+		assert.False(t, ctx.Can(rbac.ActionPush, rbac.NewProjectNamespace(id).Resource(rbac.ResourceRepository)))
+	}
+
+	// the wildcard is ignored for the project robot
+	robot.ProjectID = private.ProjectID
+	ctx = NewSecurityContext(robot, pm, policies)
+	assert.False(t, ctx.Can(rbac.ActionPull, rbac.NewProjectNamespace(private.ProjectID).Resource(rbac.ResourceRepository)))
+}
+
 func TestGetMyProjects(t *testing.T) {
 	ctx := NewSecurityContext(nil, nil, nil)
 	projects, err := ctx.GetMyProjects()
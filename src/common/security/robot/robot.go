@@ -1,8 +1,11 @@
 package robot
 
 import (
+	"strings"
+
 	"github.com/goharbor/harbor/src/common/rbac"
 	"github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/pkg/robot/model"
 )
 
 // robot implement the rbac.User interface for project robot account
@@ -60,3 +63,26 @@ func filterPolicies(namespace rbac.Namespace, policies []*rbac.Policy) []*rbac.P
 
 	return results
 }
+
+// expandPolicies rewrites the policies granting the access to every project, e.g. "/project/*/repository",
+// to the policies of the namespace, the other policies are returned as they are
+func expandPolicies(namespace rbac.Namespace, policies []*rbac.Policy) []*rbac.Policy {
+	var results []*rbac.Policy
+	for _, policy := range policies {
+		if !strings.HasPrefix(policy.Resource.String(), model.AllProjects.String()+"/") {
+			results = append(results, policy)
+			continue
+		}
+		sub, err := policy.Resource.RelativeTo(model.AllProjects)
+		if err != nil {
+			continue
+		}
+		results = append(results, &rbac.Policy{
+			Resource: namespace.Resource(sub),
+			Action:   policy.Action,
+			Effect:   policy.Effect,
+		})
+	}
+
+	return results
+}
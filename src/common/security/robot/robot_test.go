@@ -51,3 +51,30 @@ func TestNewRobot(t *testing.T) {
 	robot := NewRobot("test", rbac.NewProjectNamespace(1, false), policies)
 	assert.Len(t, robot.GetPolicies(), 1)
 }
+
+func TestExpandPolicies(t *testing.T) {
+	policies := []*rbac.Policy{
+		{Resource: "/project/*/repository", Action: "pull"},
+		{Resource: "/project/*/helm-chart", Action: "read", Effect: "deny"},
+		{Resource: "/project/2/repository", Action: "push"},
+		{Resource: "/project/*foo/repository", Action: "push"},
+	}
+
+	expanded := expandPolicies(rbac.NewProjectNamespace(1, false), policies)
+	assert.Equal(t, []*rbac.Policy{
+		{Resource: "/project/1/repository", Action: "pull"},
+		{Resource: "/project/1/helm-chart", Action: "read", Effect: "deny"},
+		{Resource: "/project/2/repository", Action: "push"},
+		{Resource: "/project/*foo/repository", Action: "push"},
+	}, expanded)
+
+	robot := NewRobot("test", rbac.NewProjectNamespace(1, false), expanded)
+	assert.Len(t, robot.GetPolicies(), 1)
+}
+
+func TestCanUnauthenticated(t *testing.T) {
+	ctx := NewSecurityContext(nil, nil, []*rbac.Policy{
+		{Resource: "/project/*/repository", Action: "pull"},
+	})
+	assert.False(t, ctx.Can(rbac.ActionPull, rbac.NewProjectNamespace(1).Resource(rbac.ResourceRepository)))
+}
@@ -2,8 +2,10 @@ package token
 
 import (
 	"errors"
+	"fmt"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/goharbor/harbor/src/common/rbac"
+	"time"
 )
 
 // RobotClaims implements the interface of jwt.Claims
@@ -12,6 +14,9 @@ type RobotClaims struct {
 	TokenID   int64          `json:"id"`
 	ProjectID int64          `json:"pid"`
 	Access    []*rbac.Policy `json:"access"`
+	// Leeway is the grace period allowed for the clock skew when validating the time claims,
+	// it's set by the verifier rather than carried in the token
+	Leeway time.Duration `json:"-"`
 }
 
 // Valid valid the claims "tokenID, projectID and access".
@@ -25,9 +30,57 @@ func (rc RobotClaims) Valid() error {
 	if rc.Access == nil {
 		return errors.New("The access info cannot be nil")
 	}
-	stdErr := rc.StandardClaims.Valid()
+	stdErr := rc.validTime()
 	if stdErr != nil {
 		return stdErr
 	}
 	return nil
 }
+
+// validTime validates the time claims "exp, iat and nbf" as jwt.StandardClaims does but
+// takes the leeway into account
+func (rc RobotClaims) validTime() error {
+	now := jwt.TimeFunc().Unix()
+	leeway := int64(rc.Leeway / time.Second)
+	vErr := &jwt.ValidationError{}
+	if !rc.VerifyExpiresAt(now-leeway, false) {
+		vErr.Inner = fmt.Errorf("token is expired by %v", time.Unix(now, 0).Sub(time.Unix(rc.ExpiresAt, 0)))
+		vErr.Errors |= jwt.ValidationErrorExpired
+	}
+	if !rc.VerifyIssuedAt(now+leeway, false) {
+		vErr.Inner = errors.New("token used before issued")
+		vErr.Errors |= jwt.ValidationErrorIssuedAt
+	}
+	if !rc.VerifyNotBefore(now+leeway, false) {
+		vErr.Inner = errors.New("token is not valid yet")
+		vErr.Errors |= jwt.ValidationErrorNotValidYet
+	}
+	if vErr.Errors == 0 {
+		return nil
+	}
+	return vErr
+}
+
+// IsExpired checks whether the error returned by ParseWithClaims is caused by the expired token
+// which is signed by Harbor
+func IsExpired(err error) bool {
+	return isTimeError(err, jwt.ValidationErrorExpired)
+}
+
+// IsNotValidYet checks whether the error returned by ParseWithClaims is caused by the token
+// which is signed by Harbor but used before it's issued or valid
+func IsNotValidYet(err error) bool {
+	return isTimeError(err, jwt.ValidationErrorIssuedAt|jwt.ValidationErrorNotValidYet)
+}
+
+func isTimeError(err error, flags uint32) bool {
+	e, ok := err.(*jwt.ValidationError)
+	if !ok {
+		return false
+	}
+	// the time claims of the tokens not signed by Harbor are meaningless
+	if e.Errors&(jwt.ValidationErrorMalformed|jwt.ValidationErrorUnverifiable|jwt.ValidationErrorSignatureInvalid) != 0 {
+		return false
+	}
+	return e.Errors&flags != 0
+}
@@ -1,9 +1,12 @@
 package token
 
 import (
+	"errors"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/goharbor/harbor/src/common/rbac"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestValid(t *testing.T) {
@@ -66,3 +69,78 @@ func TestUnValidPolicy(t *testing.T) {
 	}
 	assert.NotNil(t, rClaims.Valid())
 }
+
+func TestValidTime(t *testing.T) {
+	now := time.Now().Unix()
+	policies := []*rbac.Policy{{Resource: "/project/libray/repository", Action: "pull"}}
+	cases := []struct {
+		name        string
+		claims      jwt.StandardClaims
+		leeway      time.Duration
+		expired     bool
+		notValidYet bool
+	}{
+		{
+			name:   "valid",
+			claims: jwt.StandardClaims{IssuedAt: now - 60, ExpiresAt: now + 60},
+		},
+		{
+			name:    "expired",
+			claims:  jwt.StandardClaims{IssuedAt: now - 120, ExpiresAt: now - 60},
+			expired: true,
+		},
+		{
+			name:   "expired within the leeway",
+			claims: jwt.StandardClaims{IssuedAt: now - 120, ExpiresAt: now - 60},
+			leeway: 2 * time.Minute,
+		},
+		{
+			name:    "expired beyond the leeway",
+			claims:  jwt.StandardClaims{IssuedAt: now - 300, ExpiresAt: now - 180},
+			leeway:  2 * time.Minute,
+			expired: true,
+		},
+		{
+			name:        "not valid yet",
+			claims:      jwt.StandardClaims{NotBefore: now + 60, ExpiresAt: now + 120},
+			notValidYet: true,
+		},
+		{
+			name:        "issued in the future",
+			claims:      jwt.StandardClaims{IssuedAt: now + 60, ExpiresAt: now + 120},
+			notValidYet: true,
+		},
+		{
+			name:   "issued in the future within the leeway",
+			claims: jwt.StandardClaims{IssuedAt: now + 60, NotBefore: now + 60, ExpiresAt: now + 120},
+			leeway: 2 * time.Minute,
+		},
+	}
+	for _, c := range cases {
+		rClaims := &RobotClaims{
+			StandardClaims: c.claims,
+			TokenID:        1,
+			ProjectID:      2,
+			Access:         policies,
+			Leeway:         c.leeway,
+		}
+		err := rClaims.Valid()
+		if !c.expired && !c.notValidYet {
+			assert.Nil(t, err, c.name)
+			continue
+		}
+		assert.Equal(t, c.expired, IsExpired(err), c.name)
+		assert.Equal(t, c.notValidYet, IsNotValidYet(err), c.name)
+	}
+}
+
+func TestIsTimeError(t *testing.T) {
+	assert.False(t, IsExpired(nil))
+	assert.False(t, IsExpired(errors.New("error")))
+	// the time claims are ignored when the signature is invalid
+	assert.False(t, IsExpired(&jwt.ValidationError{
+		Errors: jwt.ValidationErrorExpired | jwt.ValidationErrorSignatureInvalid,
+	}))
+	assert.True(t, IsExpired(&jwt.ValidationError{Errors: jwt.ValidationErrorExpired}))
+	assert.True(t, IsNotValidYet(&jwt.ValidationError{Errors: jwt.ValidationErrorNotValidYet}))
+}
@@ -1,8 +1,6 @@
 package token
 
 import (
-	"crypto/ecdsa"
-	"crypto/rsa"
 	"errors"
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
@@ -41,7 +39,7 @@ func New(tokenID, projectID, expiresAt int64, access []*rbac.Policy) (*HToken, e
 
 // Raw get the Raw string of token
 func (htk *HToken) Raw() (string, error) {
-	key, err := DefaultOptions().GetKey()
+	key, err := DefaultKeyRotationManager.signingKey()
 	if err != nil {
 		return "", nil
 	}
@@ -55,30 +53,32 @@ func (htk *HToken) Raw() (string, error) {
 
 // ParseWithClaims ...
 func ParseWithClaims(rawToken string, claims jwt.Claims) (*HToken, error) {
-	key, err := DefaultOptions().GetKey()
+	// the retiring key is also accepted during the grace period of the key rotation
+	keys, err := DefaultKeyRotationManager.verificationKeys()
 	if err != nil {
 		return nil, err
 	}
-	token, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
-		if token.Method.Alg() != DefaultOptions().SignMethod.Alg() {
-			return nil, errors.New("invalid signing method")
-		}
-		switch k := key.(type) {
-		case *rsa.PrivateKey:
-			return &k.PublicKey, nil
-		case *ecdsa.PrivateKey:
-			return &k.PublicKey, nil
-		default:
+	var token *jwt.Token
+	for _, key := range keys {
+		token, err = jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+			if token.Method.Alg() != DefaultOptions().SignMethod.Alg() {
+				return nil, errors.New("invalid signing method")
+			}
 			return key, nil
+		})
+		if e, ok := err.(*jwt.ValidationError); !ok || e.Errors&jwt.ValidationErrorSignatureInvalid == 0 {
+			break
 		}
-	})
+	}
+	// the callers decide how to log the error, as the tokens not issued by Harbor are
+	// also parsed when detecting the type of the bearer tokens
 	if err != nil {
-		log.Errorf(fmt.Sprintf("parse token error, %v", err))
+		log.Debugf("parse token error, %v", err)
 		return nil, err
 	}
 
 	if !token.Valid {
-		log.Errorf(fmt.Sprintf("invalid jwt token, %v", token))
+		log.Debugf("invalid jwt token, %v", token)
 		return nil, errors.New("invalid jwt token")
 	}
 	return &HToken{
@@ -0,0 +1,57 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// impersonationAudience distinguishes the impersonation tokens from the other tokens signed by the same key
+const impersonationAudience = "harbor-impersonation"
+
+// ImpersonationClaims implements the interface of jwt.Claims, the subject is the impersonated user
+type ImpersonationClaims struct {
+	jwt.StandardClaims
+	UserID         int    `json:"uid"`
+	ImpersonatedBy string `json:"impersonated_by"`
+	// AllowWrite indicates whether the token can be used for the write operations
+	AllowWrite bool `json:"allow_write,omitempty"`
+}
+
+// Valid validates the claims "uid, sub and impersonated_by" and the standard ones.
+func (ic ImpersonationClaims) Valid() error {
+	if ic.UserID <= 0 || len(ic.Subject) == 0 {
+		return errors.New("the impersonated user is required")
+	}
+	if len(ic.ImpersonatedBy) == 0 {
+		return errors.New("the impersonating user is required")
+	}
+	if !ic.VerifyAudience(impersonationAudience, true) {
+		return errors.New("not an impersonation token")
+	}
+	return ic.StandardClaims.Valid()
+}
+
+// NewImpersonation creates a token which expires after the ttl for the user impersonated by another one
+func NewImpersonation(userID int, username, impersonatedBy string, allowWrite bool, ttl time.Duration) (*HToken, error) {
+	now := time.Now().UTC()
+	claims := &ImpersonationClaims{
+		UserID:         userID,
+		ImpersonatedBy: impersonatedBy,
+		AllowWrite:     allowWrite,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   username,
+			Audience:  impersonationAudience,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
+			Issuer:    DefaultOptions().Issuer,
+		},
+	}
+	if err := claims.Valid(); err != nil {
+		return nil, err
+	}
+	return &HToken{
+		Token: *jwt.NewWithClaims(DefaultOptions().SignMethod, claims),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpersonation(t *testing.T) {
+	tk, err := NewImpersonation(2, "user", "admin", false, 5*time.Minute)
+	require.Nil(t, err)
+	raw, err := tk.Raw()
+	require.Nil(t, err)
+
+	claims := &ImpersonationClaims{}
+	_, err = ParseWithClaims(raw, claims)
+	require.Nil(t, err)
+	assert.Equal(t, 2, claims.UserID)
+	assert.Equal(t, "user", claims.Subject)
+	assert.Equal(t, "admin", claims.ImpersonatedBy)
+	assert.False(t, claims.AllowWrite)
+
+	// the robot token can't be used as the impersonation token
+	robot, err := New(1, 1, time.Now().Add(time.Hour).Unix(), []*rbac.Policy{})
+	require.Nil(t, err)
+	raw, err = robot.Raw()
+	require.Nil(t, err)
+	_, err = ParseWithClaims(raw, &ImpersonationClaims{})
+	assert.NotNil(t, err)
+
+	// expired
+	tk, err = NewImpersonation(2, "user", "admin", false, -time.Minute)
+	assert.NotNil(t, err)
+	assert.Nil(t, tk)
+}
@@ -0,0 +1,62 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// OverrideMaxTTL is the max lifetime of the override token, the longer ones are rejected
+const OverrideMaxTTL = 10 * time.Minute
+
+// OverrideClaims implements the interface of jwt.Claims, it's carried by the system admin
+// to make the requests in the security context of the user
+type OverrideClaims struct {
+	jwt.StandardClaims
+	Username       string `json:"username"`
+	IssuedBy       string `json:"issued_by"`
+	AdminSignature bool   `json:"admin_signature"`
+}
+
+// Valid validates the claims "username, issued_by, admin_signature and exp" and the standard ones.
+func (oc OverrideClaims) Valid() error {
+	if len(oc.Username) == 0 {
+		return errors.New("the username is required")
+	}
+	if len(oc.IssuedBy) == 0 {
+		return errors.New("the issuer of the override is required")
+	}
+	if !oc.AdminSignature {
+		return errors.New("not an override token")
+	}
+	if oc.ExpiresAt == 0 {
+		return errors.New("the expiration is required")
+	}
+	if time.Unix(oc.ExpiresAt, 0).After(time.Now().Add(OverrideMaxTTL)) {
+		return errors.New("the override token lives too long")
+	}
+	return oc.StandardClaims.Valid()
+}
+
+// NewOverride creates a token which expires after the ttl for the admin "issuedBy" to override
+// the security context by the user
+func NewOverride(username, issuedBy string, ttl time.Duration) (*HToken, error) {
+	now := time.Now().UTC()
+	claims := &OverrideClaims{
+		Username:       username,
+		IssuedBy:       issuedBy,
+		AdminSignature: true,
+		StandardClaims: jwt.StandardClaims{
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
+			Issuer:    DefaultOptions().Issuer,
+		},
+	}
+	if err := claims.Valid(); err != nil {
+		return nil, err
+	}
+	return &HToken{
+		Token: *jwt.NewWithClaims(DefaultOptions().SignMethod, claims),
+	}, nil
+}
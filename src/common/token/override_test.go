@@ -0,0 +1,43 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverride(t *testing.T) {
+	tk, err := NewOverride("alice", "admin", 5*time.Minute)
+	require.Nil(t, err)
+	raw, err := tk.Raw()
+	require.Nil(t, err)
+
+	claims := &OverrideClaims{}
+	_, err = ParseWithClaims(raw, claims)
+	require.Nil(t, err)
+	assert.Equal(t, "alice", claims.Username)
+	assert.Equal(t, "admin", claims.IssuedBy)
+	assert.True(t, claims.AdminSignature)
+
+	// the impersonation token can't be used as the override token
+	imp, err := NewImpersonation(2, "alice", "admin", false, 5*time.Minute)
+	require.Nil(t, err)
+	raw, err = imp.Raw()
+	require.Nil(t, err)
+	_, err = ParseWithClaims(raw, &OverrideClaims{})
+	assert.NotNil(t, err)
+
+	// the issuer is required
+	_, err = NewOverride("alice", "", 5*time.Minute)
+	assert.NotNil(t, err)
+
+	// expired
+	_, err = NewOverride("alice", "admin", -time.Minute)
+	assert.NotNil(t, err)
+
+	// lives too long
+	_, err = NewOverride("alice", "admin", OverrideMaxTTL+time.Minute)
+	assert.NotNil(t, err)
+}
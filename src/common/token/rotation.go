@@ -0,0 +1,279 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/config"
+)
+
+// the bits of the RSA key generated by the rotation
+const rotationKeyBits = 4096
+
+// KeyCacheTTL is how long the keys loaded from the database are cached, so the rotation
+// started by another core instance takes effect in it at most
+const KeyCacheTTL = time.Minute
+
+// ErrRotationInProgress is returned when starting a rotation before the previous one is completed
+var ErrRotationInProgress = errors.New("the rotation of the signing key is in progress")
+
+// DefaultKeyRotationManager is the manager of the key signing the tokens issued by Harbor
+var DefaultKeyRotationManager = NewKeyRotationManager()
+
+// keyStore persists the signing keys in PEM format
+type keyStore interface {
+	// list returns all the keys
+	list() ([]*models.SigningKey, error)
+	// rotate retires the active key, or adds the retiring key if there is no active one, and
+	// adds the new active key
+	rotate(retiring, active string) error
+	// deleteRetiring deletes the retiring keys
+	deleteRetiring() error
+}
+
+// keyring holds the keys loaded from the store
+type keyring struct {
+	// nil means the key read from the file is used
+	active    *rsa.PrivateKey
+	rotatedAt time.Time
+	retiring  []*rsa.PrivateKey
+}
+
+// KeyRotationManager rotates the key signing the tokens. The key read from the file is used until
+// the first rotation. During the grace period of a rotation, both the new key and the retiring one
+// are accepted to verify the tokens while only the new one is used to sign them.
+type KeyRotationManager struct {
+	store keyStore
+	bits  int
+	// the func to read the key from the file
+	fileKey  func() (*rsa.PrivateKey, error)
+	lock     sync.Mutex
+	ring     *keyring
+	loadedAt time.Time
+}
+
+// NewKeyRotationManager returns an instance of KeyRotationManager storing the keys in the database
+func NewKeyRotationManager() *KeyRotationManager {
+	return &KeyRotationManager{
+		store:   &daoKeyStore{},
+		bits:    rotationKeyBits,
+		fileKey: readFileKey,
+	}
+}
+
+// StartRotation generates a new key to sign the tokens and begins the grace period
+// in which the old key is still accepted to verify the tokens
+func (m *KeyRotationManager) StartRotation() error {
+	ring, err := m.load()
+	if err != nil {
+		return err
+	}
+	if len(ring.retiring) > 0 {
+		return ErrRotationInProgress
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, m.bits)
+	if err != nil {
+		return err
+	}
+	// the key from the file is retired by the first rotation
+	fileKey, err := m.fileKey()
+	if err != nil {
+		return err
+	}
+	if err = m.store.rotate(encodeKey(fileKey), encodeKey(key)); err != nil {
+		if err == dao.ErrDupRows {
+			return ErrRotationInProgress
+		}
+		return err
+	}
+	m.invalidate()
+	log.Info("the rotation of the token signing key is started")
+	return nil
+}
+
+// IsInGracePeriod returns whether the rotation is in progress and the retiring key is still accepted
+func (m *KeyRotationManager) IsInGracePeriod() bool {
+	ring, err := m.load()
+	if err != nil {
+		log.Errorf("failed to load the token signing keys: %v", err)
+		return false
+	}
+	return len(ring.retiring) > 0
+}
+
+// RotationStartedAt returns the time the rotation in progress was started at, the
+// bool is false if there is no rotation in progress
+func (m *KeyRotationManager) RotationStartedAt() (time.Time, bool, error) {
+	ring, err := m.load()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if len(ring.retiring) == 0 {
+		return time.Time{}, false, nil
+	}
+	return ring.rotatedAt, true, nil
+}
+
+// CompleteRotation removes the retiring key, the tokens signed by it are rejected afterwards
+func (m *KeyRotationManager) CompleteRotation() error {
+	if err := m.store.deleteRetiring(); err != nil {
+		return err
+	}
+	m.invalidate()
+	log.Info("the rotation of the token signing key is completed")
+	return nil
+}
+
+// signingKey returns the key to sign the tokens
+func (m *KeyRotationManager) signingKey() (*rsa.PrivateKey, error) {
+	ring, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	if ring.active != nil {
+		return ring.active, nil
+	}
+	return m.fileKey()
+}
+
+// verificationKeys returns the keys accepted to verify the tokens, the one signing the tokens goes first
+func (m *KeyRotationManager) verificationKeys() ([]*rsa.PublicKey, error) {
+	key, err := m.signingKey()
+	if err != nil {
+		return nil, err
+	}
+	ring, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	keys := []*rsa.PublicKey{&key.PublicKey}
+	for _, k := range ring.retiring {
+		keys = append(keys, &k.PublicKey)
+	}
+	return keys, nil
+}
+
+func (m *KeyRotationManager) load() (*keyring, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.ring != nil && time.Since(m.loadedAt) < KeyCacheTTL {
+		return m.ring, nil
+	}
+
+	keys, err := m.store.list()
+	if err != nil {
+		return nil, err
+	}
+	ring := &keyring{}
+	for _, k := range keys {
+		key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(k.PrivateKey))
+		if err != nil {
+			return nil, err
+		}
+		switch k.Status {
+		case models.SigningKeyActive:
+			ring.active = key
+			ring.rotatedAt = k.CreationTime
+		case models.SigningKeyRetiring:
+			ring.retiring = append(ring.retiring, key)
+		}
+	}
+	m.ring = ring
+	m.loadedAt = time.Now()
+	return ring, nil
+}
+
+func (m *KeyRotationManager) invalidate() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ring = nil
+}
+
+func readFileKey() (*rsa.PrivateKey, error) {
+	opt := DefaultOptions()
+	if opt == nil {
+		return nil, errors.New("failed to read the private key file")
+	}
+	key, err := opt.GetKey()
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the key read from the file isn't a RSA private key")
+	}
+	return privateKey, nil
+}
+
+func encodeKey(key *rsa.PrivateKey) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
+
+// daoKeyStore stores the keys in the database encrypted by the secret key
+type daoKeyStore struct{}
+
+func (d *daoKeyStore) list() ([]*models.SigningKey, error) {
+	keys, err := dao.GetSigningKeys()
+	if err != nil {
+		return nil, err
+	}
+	secretKey, err := config.SecretKey()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if k.PrivateKey, err = utils.ReversibleDecrypt(k.PrivateKey, secretKey); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func (d *daoKeyStore) rotate(retiring, active string) error {
+	secretKey, err := config.SecretKey()
+	if err != nil {
+		return err
+	}
+	encryptedRetiring, err := utils.ReversibleEncrypt(retiring, secretKey)
+	if err != nil {
+		return err
+	}
+	encryptedActive, err := utils.ReversibleEncrypt(active, secretKey)
+	if err != nil {
+		return err
+	}
+	return dao.RotateSigningKey(&models.SigningKey{PrivateKey: encryptedRetiring},
+		&models.SigningKey{PrivateKey: encryptedActive})
+}
+
+func (d *daoKeyStore) deleteRetiring() error {
+	return dao.DeleteRetiringSigningKeys()
+}
@@ -0,0 +1,126 @@
+package token
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memKeyStore struct {
+	lock sync.Mutex
+	keys []*models.SigningKey
+}
+
+func (m *memKeyStore) list() ([]*models.SigningKey, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	keys := make([]*models.SigningKey, len(m.keys))
+	copy(keys, m.keys)
+	return keys, nil
+}
+
+func (m *memKeyStore) rotate(retiring, active string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	retired := false
+	for _, k := range m.keys {
+		if k.Status == models.SigningKeyRetiring {
+			return dao.ErrDupRows
+		}
+		if k.Status == models.SigningKeyActive {
+			k.Status = models.SigningKeyRetiring
+			retired = true
+		}
+	}
+	if !retired {
+		m.keys = append(m.keys, &models.SigningKey{PrivateKey: retiring, Status: models.SigningKeyRetiring})
+	}
+	m.keys = append(m.keys, &models.SigningKey{
+		PrivateKey:   active,
+		Status:       models.SigningKeyActive,
+		CreationTime: time.Now(),
+	})
+	return nil
+}
+
+func (m *memKeyStore) deleteRetiring() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	keys := []*models.SigningKey{}
+	for _, k := range m.keys {
+		if k.Status != models.SigningKeyRetiring {
+			keys = append(keys, k)
+		}
+	}
+	m.keys = keys
+	return nil
+}
+
+func newRobotToken(t *testing.T) string {
+	tk, err := New(1, 1, time.Now().Add(time.Hour).Unix(), []*rbac.Policy{
+		{Resource: "/project/1/repository", Action: "pull"},
+	})
+	require.Nil(t, err)
+	raw, err := tk.Raw()
+	require.Nil(t, err)
+	require.NotEmpty(t, raw)
+	return raw
+}
+
+func TestKeyRotationGracePeriod(t *testing.T) {
+	m := DefaultKeyRotationManager
+	defer func() {
+		m.store = &memKeyStore{}
+		m.invalidate()
+	}()
+
+	require.False(t, m.IsInGracePeriod())
+	_, inProgress, err := m.RotationStartedAt()
+	require.Nil(t, err)
+	assert.False(t, inProgress)
+
+	// signed by the key from the file
+	oldToken := newRobotToken(t)
+
+	require.Nil(t, m.StartRotation())
+	assert.True(t, m.IsInGracePeriod())
+	startedAt, inProgress, err := m.RotationStartedAt()
+	require.Nil(t, err)
+	assert.True(t, inProgress)
+	assert.False(t, startedAt.IsZero())
+	// can't start another rotation during the grace period
+	assert.Equal(t, ErrRotationInProgress, m.StartRotation())
+
+	// signed by the new key
+	newToken := newRobotToken(t)
+	assert.NotEqual(t, oldToken, newToken)
+
+	// both the tokens are accepted during the grace period
+	_, err = ParseWithClaims(oldToken, &RobotClaims{})
+	assert.Nil(t, err)
+	_, err = ParseWithClaims(newToken, &RobotClaims{})
+	assert.Nil(t, err)
+
+	require.Nil(t, m.CompleteRotation())
+	assert.False(t, m.IsInGracePeriod())
+
+	// only the token signed by the new key is accepted after the rotation is completed
+	_, err = ParseWithClaims(oldToken, &RobotClaims{})
+	assert.NotNil(t, err)
+	_, err = ParseWithClaims(newToken, &RobotClaims{})
+	assert.Nil(t, err)
+
+	// the next rotation retires the key generated by the previous one
+	require.Nil(t, m.StartRotation())
+	_, err = ParseWithClaims(newToken, &RobotClaims{})
+	assert.Nil(t, err)
+	require.Nil(t, m.CompleteRotation())
+	_, err = ParseWithClaims(newToken, &RobotClaims{})
+	assert.NotNil(t, err)
+}
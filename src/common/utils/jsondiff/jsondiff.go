@@ -0,0 +1,155 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsondiff generates the JSON Patch(RFC 6902) between two documents for the
+// project configuration history. It stands in for github.com/wI2L/jsondiff, which can't
+// be resolved by the module proxy this tree is built against, and produces patches in
+// the shape of that library's invertible mode: every remove and replace operation is
+// preceded by a test operation. Unlike the library, the arrays are replaced as a whole
+// rather than diffed element by element. The stored diffs are standard JSON patches, so
+// switching to the library later doesn't affect the recorded history.
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The types of the operations defined by JSON Patch(RFC 6902)
+const (
+	OperationAdd     = "add"
+	OperationRemove  = "remove"
+	OperationReplace = "replace"
+	OperationTest    = "test"
+)
+
+// Operation is one operation of the JSON Patch
+type Operation struct {
+	Type  string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON omits the value of the remove operation, the value of the
+// other operations is kept even if it is null
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Type == OperationRemove {
+		return json.Marshal(struct {
+			Type string `json:"op"`
+			Path string `json:"path"`
+		}{
+			Type: o.Type,
+			Path: o.Path,
+		})
+	}
+	type operation Operation
+	return json.Marshal(operation(o))
+}
+
+// Patch is the JSON Patch(RFC 6902) which converts the source document to the target one
+type Patch []Operation
+
+// Compare marshals the source and target to JSON and returns the patch which
+// converts the source document to the target one. Objects are compared field by
+// field, arrays and scalars are replaced as a whole. Every remove and replace
+// operation is preceded by a test operation carrying the old value, so the
+// patch can be inverted to get the source document back
+func Compare(source, target interface{}) (Patch, error) {
+	src, err := normalize(source)
+	if err != nil {
+		return nil, err
+	}
+	tgt, err := normalize(target)
+	if err != nil {
+		return nil, err
+	}
+	patch := Patch{}
+	compare(&patch, "", src, tgt)
+	return patch, nil
+}
+
+func normalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var r interface{}
+	if err = json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func compare(patch *Patch, path string, src, tgt interface{}) {
+	srcMap, srcIsMap := src.(map[string]interface{})
+	tgtMap, tgtIsMap := tgt.(map[string]interface{})
+	if !srcIsMap || !tgtIsMap {
+		if !reflect.DeepEqual(src, tgt) {
+			*patch = append(*patch, Operation{
+				Type:  OperationTest,
+				Path:  path,
+				Value: src,
+			}, Operation{
+				Type:  OperationReplace,
+				Path:  path,
+				Value: tgt,
+			})
+		}
+		return
+	}
+
+	// iterate the keys in order to get a stable result
+	keys := make([]string, 0, len(srcMap)+len(tgtMap))
+	for k := range srcMap {
+		keys = append(keys, k)
+	}
+	for k := range tgtMap {
+		if _, exist := srcMap[k]; !exist {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := path + "/" + escape(k)
+		s, inSrc := srcMap[k]
+		t, inTgt := tgtMap[k]
+		switch {
+		case inSrc && !inTgt:
+			*patch = append(*patch, Operation{
+				Type:  OperationTest,
+				Path:  p,
+				Value: s,
+			}, Operation{
+				Type: OperationRemove,
+				Path: p,
+			})
+		case !inSrc && inTgt:
+			*patch = append(*patch, Operation{
+				Type:  OperationAdd,
+				Path:  p,
+				Value: t,
+			})
+		default:
+			compare(patch, p, s, t)
+		}
+	}
+}
+
+// escape the key according to the JSON Pointer(RFC 6901)
+func escape(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
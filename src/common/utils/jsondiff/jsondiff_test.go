@@ -0,0 +1,144 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsondiff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	source := map[string]interface{}{
+		"metadata": map[string]string{
+			"public":         "false",
+			"auto_scan":      "true",
+			"severity":       "high",
+			"reuse_sys_cve/": "true",
+		},
+		"cve_whitelist": map[string]interface{}{
+			"items": []string{"CVE-2019-0001"},
+		},
+	}
+	target := map[string]interface{}{
+		"metadata": map[string]string{
+			"public":    "true",
+			"auto_scan": "true",
+			"prevent":   "true",
+		},
+		"cve_whitelist": map[string]interface{}{
+			"items": []string{"CVE-2019-0001", "CVE-2019-0002"},
+		},
+	}
+
+	patch, err := Compare(source, target)
+	require.Nil(t, err)
+	assert.Equal(t, Patch{
+		{
+			Type:  OperationTest,
+			Path:  "/cve_whitelist/items",
+			Value: []interface{}{"CVE-2019-0001"},
+		},
+		{
+			Type:  OperationReplace,
+			Path:  "/cve_whitelist/items",
+			Value: []interface{}{"CVE-2019-0001", "CVE-2019-0002"},
+		},
+		{
+			Type:  OperationAdd,
+			Path:  "/metadata/prevent",
+			Value: "true",
+		},
+		{
+			Type:  OperationTest,
+			Path:  "/metadata/public",
+			Value: "false",
+		},
+		{
+			Type:  OperationReplace,
+			Path:  "/metadata/public",
+			Value: "true",
+		},
+		{
+			Type:  OperationTest,
+			Path:  "/metadata/reuse_sys_cve~1",
+			Value: "true",
+		},
+		{
+			Type: OperationRemove,
+			Path: "/metadata/reuse_sys_cve~1",
+		},
+		{
+			Type:  OperationTest,
+			Path:  "/metadata/severity",
+			Value: "high",
+		},
+		{
+			Type: OperationRemove,
+			Path: "/metadata/severity",
+		},
+	}, patch)
+}
+
+func TestMarshalPatch(t *testing.T) {
+	data, err := json.Marshal(Patch{
+		{
+			Type:  OperationTest,
+			Path:  "/expires_at",
+			Value: nil,
+		},
+		{
+			Type:  OperationReplace,
+			Path:  "/expires_at",
+			Value: 1,
+		},
+		{
+			Type: OperationRemove,
+			Path: "/metadata/severity",
+		},
+	})
+	require.Nil(t, err)
+	assert.JSONEq(t, `[
+		{"op":"test","path":"/expires_at","value":null},
+		{"op":"replace","path":"/expires_at","value":1},
+		{"op":"remove","path":"/metadata/severity"}
+	]`, string(data))
+}
+
+func TestCompareEqual(t *testing.T) {
+	v := map[string]string{"public": "true"}
+	patch, err := Compare(v, v)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(patch))
+}
+
+func TestCompareNil(t *testing.T) {
+	patch, err := Compare(nil, map[string]string{"public": "true"})
+	require.Nil(t, err)
+	assert.Equal(t, Patch{
+		{
+			Type:  OperationTest,
+			Path:  "",
+			Value: nil,
+		},
+		{
+			Type:  OperationReplace,
+			Path:  "",
+			Value: map[string]interface{}{"public": "true"},
+		},
+	}, patch)
+}
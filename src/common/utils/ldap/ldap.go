@@ -464,3 +464,33 @@ func normalizeFilter(filter string) string {
 	norFilter = strings.TrimSuffix(norFilter, ")")
 	return norFilter
 }
+
+// InGroup - check whether the group DN is one of the group DNs, which include the nested groups
+// the user is a member of. The DNs are compared case insensitively and regardless of the spaces
+// between the RDNs
+func InGroup(groupDNList []string, groupDN string) bool {
+	target, err := parseDN(groupDN)
+	if err != nil {
+		log.Debugf("invalid group DN %s: %v", groupDN, err)
+		return false
+	}
+	for _, dn := range groupDNList {
+		d, err := parseDN(dn)
+		if err != nil {
+			log.Debugf("invalid group DN %s: %v", dn, err)
+			continue
+		}
+		if d.Equal(target) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseDN(dn string) (*goldap.DN, error) {
+	dn = strings.ToLower(strings.TrimSpace(dn))
+	if len(dn) == 0 {
+		return nil, ErrDNSyntax
+	}
+	return goldap.ParseDN(dn)
+}
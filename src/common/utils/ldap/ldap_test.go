@@ -391,3 +391,36 @@ func TestNormalizeFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestInGroup(t *testing.T) {
+	groups := []string{
+		"cn=harbor_users,ou=groups,dc=example,dc=com",
+		// the nested group of harbor_users
+		"CN=Harbor_Admins, OU=Groups, DC=example, DC=com",
+		"not a dn",
+	}
+	tests := []struct {
+		name    string
+		groupDN string
+		want    bool
+	}{
+		{"direct group", "cn=harbor_users,ou=groups,dc=example,dc=com", true},
+		{"nested group", "cn=harbor_admins,ou=groups,dc=example,dc=com", true},
+		{"different case and spaces", " CN=Harbor_Users, ou=Groups,dc=example,dc=com ", true},
+		{"parent of the group", "ou=groups,dc=example,dc=com", false},
+		{"child of the group", "cn=ops,cn=harbor_admins,ou=groups,dc=example,dc=com", false},
+		{"other group", "cn=harbor_guests,ou=groups,dc=example,dc=com", false},
+		{"empty", "", false},
+		{"invalid", "not a dn", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InGroup(groups, tt.groupDN); got != tt.want {
+				t.Errorf("InGroup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if InGroup(nil, "cn=harbor_users,ou=groups,dc=example,dc=com") {
+		t.Errorf("InGroup() = true for no group")
+	}
+}
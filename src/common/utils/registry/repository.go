@@ -30,6 +30,7 @@ import (
 	"github.com/docker/distribution/manifest/schema2"
 	commonhttp "github.com/goharbor/harbor/src/common/http"
 	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
 )
 
 // Repository holds information of a repository entity
@@ -281,6 +282,68 @@ func (r *Repository) MountBlob(digest, from string) error {
 	return nil
 }
 
+// TryMountBlob mounts the blob from the repository "from" in the same registry. The "mounted" is
+// false if the registry can't mount it, e.g. the blob doesn't exist in "from", and the upload
+// session started by the registry instead is canceled
+func (r *Repository) TryMountBlob(digest, from string) (bool, error) {
+	req, err := http.NewRequest("POST", buildMountBlobURL(r.Endpoint.String(), r.Name, digest, from), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set(http.CanonicalHeaderKey("Content-Length"), "0")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false, parseError(err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return true, nil
+	case http.StatusAccepted:
+		location := resp.Header.Get(http.CanonicalHeaderKey("Location"))
+		if err := r.cancelBlobUpload(location); err != nil {
+			log.Warningf("failed to cancel the blob upload %s: %v", location, err)
+		}
+		return false, nil
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	return false, &commonhttp.Error{
+		Code:    resp.StatusCode,
+		Message: string(b),
+	}
+}
+
+func (r *Repository) cancelBlobUpload(location string) error {
+	relative, err := isRelativeURL(location)
+	if err != nil {
+		return err
+	}
+	if relative {
+		location = r.Endpoint.String() + location
+	}
+	req, err := http.NewRequest("DELETE", location, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return parseError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return &commonhttp.Error{
+		Code: resp.StatusCode,
+	}
+}
+
 // DeleteTag ...
 func (r *Repository) DeleteTag(tag string) error {
 	digest, exist, err := r.ManifestExist(tag)
@@ -456,3 +456,46 @@ func TestMountBlob(t *testing.T) {
 		t.Fatalf("failed to mount blob: %v", err)
 	}
 }
+
+func TestTryMountBlob(t *testing.T) {
+	canceled := false
+	mountHandler := func(w http.ResponseWriter, r *http.Request) {
+		// the blob only exists in "library/hi-world"
+		if r.URL.Query().Get("from") == "library/hi-world" {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Header().Set(http.CanonicalHeaderKey("Location"), fmt.Sprintf("/v2/%s/blobs/uploads/%s", repository, uuid))
+		w.WriteHeader(http.StatusAccepted)
+	}
+	cancelHandler := func(w http.ResponseWriter, r *http.Request) {
+		canceled = strings.HasSuffix(r.URL.Path, uuid)
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	server := test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  http.MethodPost,
+			Pattern: fmt.Sprintf("/v2/%s/blobs/uploads/", repository),
+			Handler: mountHandler,
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodDelete,
+			Pattern: fmt.Sprintf("/v2/%s/blobs/uploads/", repository),
+			Handler: cancelHandler,
+		})
+	defer server.Close()
+
+	client, err := newRepository(server.URL)
+	require.Nil(t, err)
+
+	mounted, err := client.TryMountBlob(digest, "library/hi-world")
+	require.Nil(t, err)
+	assert.True(t, mounted)
+	assert.False(t, canceled)
+
+	mounted, err = client.TryMountBlob(digest, "library/other")
+	require.Nil(t, err)
+	assert.False(t, mounted)
+	assert.True(t, canceled)
+}
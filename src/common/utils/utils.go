@@ -49,6 +49,56 @@ func ParseEndpoint(endpoint string) (*url.URL, error) {
 	return url.ParseRequestURI(endpoint)
 }
 
+// internalNetworks are the private, shared and other special purpose networks which aren't reachable
+// from the internet, besides the loopback, link-local, multicast and unspecified ones checked by net.IP
+var internalNetworks = func() []*net.IPNet {
+	networks := []*net.IPNet{}
+	for _, cidr := range []string{"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12",
+		"192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "240.0.0.0/4", "fc00::/7"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// lookupIP resolves the host, it's replaced in tests
+var lookupIP = net.LookupIP
+
+// IsInternalIP returns whether the IP is a loopback, link-local, private or other internal address
+func IsInternalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range internalNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckExternalEndpoint returns an error if the host of the endpoint is or resolves to an internal IP
+func CheckExternalEndpoint(endpoint string) error {
+	u, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		if ips, err = lookupIP(host); err != nil {
+			return err
+		}
+	}
+	for _, ip := range ips {
+		if IsInternalIP(ip) {
+			return fmt.Errorf("the host %s of the endpoint is the internal address %s", host, ip)
+		}
+	}
+	return nil
+}
+
 // ParseRepository splits a repository into two parts: project and rest
 func ParseRepository(repository string) (project, rest string) {
 	repository = strings.TrimLeft(repository, "/")
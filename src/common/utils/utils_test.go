@@ -16,6 +16,8 @@ package utils
 
 import (
 	"encoding/base64"
+	"fmt"
+	"net"
 	"net/http/httptest"
 	"reflect"
 	"strconv"
@@ -51,6 +53,41 @@ func TestParseEndpoint(t *testing.T) {
 	}
 }
 
+func TestIsInternalIP(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "::1", "169.254.169.254", "fe80::1", "10.0.0.1",
+		"172.17.0.2", "192.168.1.1", "100.64.0.1", "0.0.0.0", "::", "fd00::1", "224.0.0.1"} {
+		assert.True(t, IsInternalIP(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"8.8.8.8", "172.32.0.1", "2001:4860:4860::8888"} {
+		assert.False(t, IsInternalIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestCheckExternalEndpoint(t *testing.T) {
+	defer func(f func(string) ([]net.IP, error)) {
+		lookupIP = f
+	}(lookupIP)
+	hosts := map[string][]net.IP{
+		"external.test": {net.ParseIP("8.8.8.8")},
+		"internal.test": {net.ParseIP("8.8.8.8"), net.ParseIP("10.0.0.1")},
+	}
+	lookupIP = func(host string) ([]net.IP, error) {
+		ips, ok := hosts[host]
+		if !ok {
+			return nil, fmt.Errorf("no such host: %s", host)
+		}
+		return ips, nil
+	}
+
+	assert.Nil(t, CheckExternalEndpoint("https://external.test/webhook"))
+	assert.Nil(t, CheckExternalEndpoint("http://8.8.8.8:8080"))
+	assert.NotNil(t, CheckExternalEndpoint("https://internal.test/webhook"))
+	assert.NotNil(t, CheckExternalEndpoint("http://127.0.0.1:8080"))
+	assert.NotNil(t, CheckExternalEndpoint("http://[::1]/webhook"))
+	assert.NotNil(t, CheckExternalEndpoint("http://169.254.169.254/latest/meta-data"))
+	assert.NotNil(t, CheckExternalEndpoint("http://unknown.test"))
+}
+
 func TestParseRepository(t *testing.T) {
 	repository := "library/ubuntu"
 	project, rest := ParseRepository(repository)
@@ -15,20 +15,58 @@
 package api
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/astaxie/beego/orm"
 	"github.com/goharbor/harbor/src/common/dao"
 	common_http "github.com/goharbor/harbor/src/common/http"
 	common_job "github.com/goharbor/harbor/src/common/job"
 	common_models "github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/api/models"
-	utils_core "github.com/goharbor/harbor/src/core/utils"
+	"github.com/goharbor/harbor/src/core/config"
 	"github.com/pkg/errors"
 )
 
+const (
+	// defaultAdminJobPageSize is the page size of the admin job list if it isn't specified,
+	// it's aligned with the top 10 jobs returned by the previous versions
+	defaultAdminJobPageSize int64 = 10
+	// maxAdminJobPageSize is the max page size of the admin job list
+	maxAdminJobPageSize int64 = 100
+	// maxLogTailLines is the max number of the lines returned when tailing the log of admin job
+	maxLogTailLines = 10000
+)
+
+// adminJobStatuses are the statuses which the admin job list can be filtered by
+var adminJobStatuses = map[string]struct{}{
+	common_models.JobPending:   {},
+	common_models.JobScheduled: {},
+	common_models.JobRunning:   {},
+	common_models.JobRetrying:  {},
+	common_models.JobStopped:   {},
+	common_models.JobCanceled:  {},
+	common_models.JobError:     {},
+	common_models.JobFinished:  {},
+}
+
+// adminJobFinalStatuses are the statuses in which the admin job can't be stopped
+var adminJobFinalStatuses = map[string]struct{}{
+	common_models.JobStopped:  {},
+	common_models.JobCanceled: {},
+	common_models.JobError:    {},
+	common_models.JobFinished: {},
+}
+
 // AJAPI manages the CRUD of admin job and its schedule, any API wants to handle manual and cron job like ScanAll and GC cloud reuse it.
 type AJAPI struct {
 	BaseController
@@ -46,27 +84,30 @@ func (aj *AJAPI) updateSchedule(ajr models.AdminJobReq) {
 		return
 	}
 
-	query := &common_models.AdminJobQuery{
-		Name: ajr.Name,
-		Kind: common_job.JobKindPeriodic,
-	}
-	jobs, err := dao.GetAdminJobs(query)
+	jobs, err := dao.GetAdminJobs(scheduleQuery(ajr.Name, ajr.ScheduleName))
 	if err != nil {
 		aj.SendInternalServerError(err)
 		return
 	}
+	// the named schedule must be created by POST, while the default one can be created by PUT as before
+	if len(jobs) == 0 && len(ajr.ScheduleName) > 0 {
+		aj.SendNotFoundError(fmt.Errorf("schedule %s of admin job %s not found", ajr.ScheduleName, ajr.Name))
+		return
+	}
 	if len(jobs) != 1 {
 		aj.SendInternalServerError(errors.New("fail to update admin job schedule as we found more than one schedule in system, please ensure that only one schedule left for your job"))
 		return
 	}
 
-	// stop the scheduled job and remove it.
-	if err = utils_core.GetJobServiceClient().PostAction(jobs[0].UUID, common_job.JobActionStop); err != nil {
-		_, ok := err.(*common_job.StatusBehindError)
-		if !ok {
-			if e, ok := err.(*common_http.Error); !ok || e.Code != http.StatusNotFound {
-				aj.SendInternalServerError(err)
-				return
+	// stop the scheduled job and remove it, the job pending submission isn't in the job service yet.
+	if jobs[0].Status != common_models.JobPendingSubmission {
+		if err = getJobServiceClient().PostAction(jobs[0].UUID, common_job.JobActionStop); err != nil {
+			_, ok := err.(*common_job.StatusBehindError)
+			if !ok {
+				if e, ok := err.(*common_http.Error); !ok || e.Code != http.StatusNotFound {
+					aj.SendInternalServerError(err)
+					return
+				}
 			}
 		}
 	}
@@ -78,7 +119,99 @@ func (aj *AJAPI) updateSchedule(ajr models.AdminJobReq) {
 
 	// Set schedule to None means to cancel the schedule, won't add new job.
 	if ajr.Schedule.Type != models.ScheduleNone {
-		aj.submit(&ajr)
+		if !aj.submit(&ajr) {
+			return
+		}
+	}
+
+	aj.auditScheduleChange(ajr, jobs[0].Cron)
+}
+
+// patchSchedule updates the cron of the periodic admin job in place, so no trigger is missed during the update.
+// If the jobservice doesn't support it or the timezone is changed, it falls back to recreate the job.
+func (aj *AJAPI) patchSchedule(ajr models.AdminJobReq) {
+	if ajr.Schedule == nil {
+		aj.SendBadRequestError(errors.New("the schedule is required"))
+		return
+	}
+	if !ajr.IsPeriodic() {
+		aj.SendBadRequestError(fmt.Errorf("only the periodic schedule can be patched, but got schedule type: %s", ajr.Schedule.Type))
+		return
+	}
+
+	jobs, err := dao.GetAdminJobs(scheduleQuery(ajr.Name, ajr.ScheduleName))
+	if err != nil {
+		aj.SendInternalServerError(fmt.Errorf("failed to get admin jobs: %v", err))
+		return
+	}
+	if len(jobs) == 0 {
+		aj.SendNotFoundError(fmt.Errorf("no schedule %s found for admin job %s", ajr.ScheduleName, ajr.Name))
+		return
+	}
+	if len(jobs) > 1 {
+		aj.SendInternalServerError(errors.New("fail to update admin job schedule as we found more than one schedule in system, please ensure that only one schedule left for your job"))
+		return
+	}
+
+	// the timezone can't be updated in place, recreate the job
+	if schedule, err := models.ConvertSchedule(jobs[0].Cron); err != nil || schedule.Timezone != ajr.Schedule.Timezone {
+		log.Debugf("the timezone of admin job %s schedule is changed, recreate it", ajr.Name)
+		aj.updateSchedule(ajr)
+		return
+	}
+
+	// the job pending submission will be submitted with the updated cron
+	if jobs[0].Status != common_models.JobPendingSubmission {
+		if err = getJobServiceClient().UpdateJobSchedule(jobs[0].UUID, ajr.Schedule.Cron); err != nil {
+			if err == common_job.ErrScheduleUpdateUnsupported {
+				log.Warningf("the jobservice doesn't support updating the schedule of admin job %s in place, recreate it", ajr.Name)
+				aj.updateSchedule(ajr)
+				return
+			}
+			aj.ParseAndHandleError("failed to update the schedule of admin job", err)
+			return
+		}
+	}
+
+	if err = dao.UpdateAdminJobCron(jobs[0].ID, ajr.CronString()); err != nil {
+		aj.SendInternalServerError(err)
+		return
+	}
+
+	aj.auditScheduleChange(ajr, jobs[0].Cron)
+}
+
+// create submits the admin job per request, the creation of the schedule is audited as the change
+// from no schedule. It returns false if the submission fails and the error has been sent back
+func (aj *AJAPI) create(ajr *models.AdminJobReq) bool {
+	if !aj.submit(ajr) {
+		return false
+	}
+	if ajr.IsPeriodic() {
+		aj.auditScheduleChange(*ajr, "")
+	}
+	return true
+}
+
+// auditScheduleChange records who changed the schedule of the admin job, the failure is only logged
+// as the schedule has been changed
+func (aj *AJAPI) auditScheduleChange(ajr models.AdminJobReq, oldCronStr string) {
+	entry := &common_models.ScheduleAuditEntry{
+		Actor:        aj.SecurityCtx.GetUsername(),
+		JobName:      ajr.Name,
+		ScheduleName: ajr.ScheduleName,
+	}
+	if schedule, err := models.ConvertSchedule(oldCronStr); err != nil {
+		log.Warningf("failed to convert the schedule %s of admin job %s: %v", oldCronStr, ajr.Name, err)
+		entry.OldCron = oldCronStr
+	} else {
+		entry.OldCron = schedule.Cron
+	}
+	if ajr.Schedule.Type != models.ScheduleNone {
+		entry.NewCron = ajr.Schedule.Cron
+	}
+	if _, err := dao.InsertScheduleAuditLog(entry); err != nil {
+		log.Errorf("failed to record the schedule change of admin job %s: %v", ajr.Name, err)
 	}
 }
 
@@ -106,9 +239,51 @@ func (aj *AJAPI) get(id int64) {
 	aj.ServeJSON()
 }
 
-// list list all executions of admin job by name
+// list list all executions of admin job by name, they can be filtered by the statuses and the time range
+// in which they are updated
 func (aj *AJAPI) list(name string) {
-	jobs, err := dao.GetTop10AdminJobsOfName(name)
+	page, err := aj.GetInt64("page", 1)
+	if err != nil || page <= 0 {
+		aj.SendBadRequestError(errors.New("invalid page"))
+		return
+	}
+
+	pageSize, err := aj.GetInt64("page_size", defaultAdminJobPageSize)
+	if err != nil || pageSize <= 0 || pageSize > maxAdminJobPageSize {
+		aj.SendBadRequestError(fmt.Errorf("invalid page_size, it should be between 1 and %d", maxAdminJobPageSize))
+		return
+	}
+
+	statuses, err := parseAdminJobStatuses(aj.GetStrings("status"))
+	if err != nil {
+		aj.SendBadRequestError(err)
+		return
+	}
+	startTime, err := parseAdminJobTime("start_time", aj.GetString("start_time"))
+	if err != nil {
+		aj.SendBadRequestError(err)
+		return
+	}
+	endTime, err := parseAdminJobTime("end_time", aj.GetString("end_time"))
+	if err != nil {
+		aj.SendBadRequestError(err)
+		return
+	}
+	if startTime != nil && endTime != nil && startTime.After(*endTime) {
+		aj.SendBadRequestError(errors.New("the start_time can't be after the end_time"))
+		return
+	}
+
+	jobs, total, err := dao.GetAdminJobsPaged(&common_models.AdminJobQuery{
+		Name:      name,
+		Statuses:  statuses,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Pagination: common_models.Pagination{
+			Page: page,
+			Size: pageSize,
+		},
+	})
 	if err != nil {
 		aj.SendInternalServerError(fmt.Errorf("failed to get admin jobs: %v", err))
 		return
@@ -124,22 +299,99 @@ func (aj *AJAPI) list(name string) {
 		AdminJobReps = append(AdminJobReps, &AdminJobRep)
 	}
 
+	aj.SetPaginationHeader(total, page, pageSize)
 	aj.Data["json"] = AdminJobReps
 	aj.ServeJSON()
 }
 
-// getSchedule gets admin job schedule ...
-func (aj *AJAPI) getSchedule(name string) {
+// parseAdminJobStatuses parses the statuses which the admin job list is filtered by, the status can be
+// specified multiple times or separated by comma
+func parseAdminJobStatuses(values []string) ([]string, error) {
+	var statuses []string
+	for _, value := range values {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.TrimSpace(status)
+			if len(status) == 0 {
+				continue
+			}
+			if _, ok := adminJobStatuses[status]; !ok {
+				return nil, fmt.Errorf("invalid status %s", status)
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+// parseAdminJobTime parses the time in RFC3339 format, nil is returned if it's empty
+func parseAdminJobTime(name, value string) (*time.Time, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %s, it should be in RFC3339 format, e.g. 2006-01-02T15:04:05Z", name, value)
+	}
+	return &t, nil
+}
+
+// stop stops the running execution of admin job by ID, the execution which is already in the
+// final status can't be stopped.
+func (aj *AJAPI) stop(id int64) {
+	job, err := dao.GetAdminJob(id)
+	if err != nil && err != orm.ErrNoRows {
+		aj.SendInternalServerError(fmt.Errorf("failed to get admin job %d: %v", id, err))
+		return
+	}
+	if job == nil {
+		aj.SendNotFoundError(fmt.Errorf("admin job %d not found", id))
+		return
+	}
+	if job.Kind == common_job.JobKindPeriodic {
+		aj.SendBadRequestError(errors.New("the schedule of admin job can't be stopped, set the schedule to None instead"))
+		return
+	}
+	if _, ok := adminJobFinalStatuses[job.Status]; ok {
+		aj.SendConflictError(fmt.Errorf("admin job %d is already in the final status %s", id, job.Status))
+		return
+	}
+
+	// the job pending submission isn't in the job service yet.
+	if job.Status != common_models.JobPendingSubmission {
+		if err = getJobServiceClient().PostAction(job.UUID, common_job.JobActionStop); err != nil {
+			if e, ok := err.(*common_job.StatusBehindError); ok {
+				aj.SendConflictError(fmt.Errorf("admin job %d is already in the final status %s", id, e.Status()))
+				return
+			}
+			if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusNotFound {
+				aj.SendNotFoundError(fmt.Errorf("admin job %d not found in the job service", id))
+				return
+			}
+			aj.SendInternalServerError(fmt.Errorf("failed to stop admin job %d: %v", id, err))
+			return
+		}
+	}
+
+	if err = dao.UpdateAdminJobStatus(id, common_models.JobStopped); err != nil {
+		aj.SendInternalServerError(fmt.Errorf("failed to update the status of admin job %d: %v", id, err))
+		return
+	}
+}
+
+// getSchedule gets the admin job schedule with the name, the empty name means the default schedule
+func (aj *AJAPI) getSchedule(name, scheduleName string) {
 	adminJobSchedule := models.AdminJobSchedule{}
 
-	jobs, err := dao.GetAdminJobs(&common_models.AdminJobQuery{
-		Name: name,
-		Kind: common_job.JobKindPeriodic,
-	})
+	jobs, err := dao.GetAdminJobs(scheduleQuery(name, scheduleName))
 	if err != nil {
 		aj.SendInternalServerError(fmt.Errorf("failed to get admin jobs: %v", err))
 		return
 	}
+	// keep returning the empty default schedule for the compatibility
+	if len(jobs) == 0 && len(scheduleName) > 0 {
+		aj.SendNotFoundError(fmt.Errorf("schedule %s of admin job %s not found", scheduleName, name))
+		return
+	}
 	if len(jobs) > 1 {
 		aj.SendInternalServerError(errors.New("get more than one scheduled admin job, make sure there has only one"))
 		return
@@ -151,15 +403,60 @@ func (aj *AJAPI) getSchedule(name string) {
 			aj.SendInternalServerError(fmt.Errorf("failed to convert admin job response: %v", err))
 			return
 		}
-		adminJobSchedule.Schedule = adminJobRep.Schedule
+		adminJobSchedule = adminJobRep.AdminJobSchedule
 	}
 
 	aj.Data["json"] = adminJobSchedule
 	aj.ServeJSON()
 }
 
-// getLog ...
+// listSchedules lists all the schedules of admin job, the default one comes first and the named ones
+// are ordered by the name
+func (aj *AJAPI) listSchedules(name string) {
+	jobs, err := dao.GetAdminJobs(&common_models.AdminJobQuery{
+		Name: name,
+		Kind: common_job.JobKindPeriodic,
+	})
+	if err != nil {
+		aj.SendInternalServerError(fmt.Errorf("failed to get admin jobs: %v", err))
+		return
+	}
+
+	schedules := []*models.AdminJobSchedule{}
+	for _, job := range jobs {
+		adminJobRep, err := convertToAdminJobRep(job)
+		if err != nil {
+			aj.SendInternalServerError(fmt.Errorf("failed to convert admin job response: %v", err))
+			return
+		}
+		schedules = append(schedules, &adminJobRep.AdminJobSchedule)
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].ScheduleName < schedules[j].ScheduleName
+	})
+
+	aj.Data["json"] = schedules
+	aj.ServeJSON()
+}
+
+// getLog streams the log of admin job from the job service without buffering it, the Range header
+// is passed through. Only the last N lines are returned if the "tail=N" is specified in the query.
 func (aj *AJAPI) getLog(id int64) {
+	tail := 0
+	if t := aj.GetString("tail"); len(t) > 0 {
+		n, err := strconv.Atoi(t)
+		if err != nil || n <= 0 || n > maxLogTailLines {
+			aj.SendBadRequestError(fmt.Errorf("invalid tail, it should be between 1 and %d", maxLogTailLines))
+			return
+		}
+		tail = n
+	}
+	rangeHeader := aj.Ctx.Request.Header.Get("Range")
+	if tail > 0 && len(rangeHeader) > 0 {
+		aj.SendBadRequestError(errors.New("the tail and the Range header can't be specified together"))
+		return
+	}
+
 	job, err := dao.GetAdminJob(id)
 	if err != nil {
 		log.Errorf("Failed to load job data for job: %d, error: %v", id, err)
@@ -175,7 +472,7 @@ func (aj *AJAPI) getLog(id int64) {
 	var jobID string
 	// to get the latest execution job id, then to query job log.
 	if job.Kind == common_job.JobKindPeriodic {
-		exes, err := utils_core.GetJobServiceClient().GetExecutions(job.UUID)
+		exes, err := getJobServiceClient().GetExecutions(job.UUID)
 		if err != nil {
 			aj.SendInternalServerError(err)
 			return
@@ -201,7 +498,7 @@ func (aj *AJAPI) getLog(id int64) {
 		jobID = job.UUID
 	}
 
-	logBytes, err := utils_core.GetJobServiceClient().GetJobLog(jobID)
+	resp, err := getJobServiceClient().GetJobLogStream(jobID, rangeHeader)
 	if err != nil {
 		if httpErr, ok := err.(*common_http.Error); ok {
 			aj.RenderError(httpErr.Code, "")
@@ -212,62 +509,213 @@ func (aj *AJAPI) getLog(id int64) {
 		aj.SendInternalServerError(fmt.Errorf("Failed to get job logs, uuid: %s, error: %v", job.UUID, err))
 		return
 	}
-	aj.Ctx.ResponseWriter.Header().Set(http.CanonicalHeaderKey("Content-Length"), strconv.Itoa(len(logBytes)))
-	aj.Ctx.ResponseWriter.Header().Set(http.CanonicalHeaderKey("Content-Type"), "text/plain")
-	_, err = aj.Ctx.ResponseWriter.Write(logBytes)
-	if err != nil {
-		aj.SendInternalServerError(fmt.Errorf("Failed to write job logs, uuid: %s, error: %v", job.UUID, err))
+	defer resp.Body.Close()
+
+	w := aj.Ctx.ResponseWriter
+	w.Header().Set(http.CanonicalHeaderKey("Content-Type"), "text/plain")
+	if tail > 0 {
+		logBytes, err := tailLines(resp.Body, tail)
+		if err != nil {
+			aj.SendInternalServerError(fmt.Errorf("Failed to read job logs, uuid: %s, error: %v", job.UUID, err))
+			return
+		}
+		w.Header().Set(http.CanonicalHeaderKey("Content-Length"), strconv.Itoa(len(logBytes)))
+		if _, err = w.Write(logBytes); err != nil {
+			log.Errorf("failed to write log of job %d: %v", id, err)
+		}
+		return
+	}
+
+	// the Content-Length is absent for the log of unknown size, then it's sent chunked
+	for _, key := range []string{"Content-Length", "Content-Range", "Accept-Ranges"} {
+		if value := resp.Header.Get(key); len(value) > 0 {
+			w.Header().Set(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	// the status has been sent, the failure can only be logged
+	if _, err = io.Copy(w, resp.Body); err != nil {
+		log.Errorf("failed to write log of job %d: %v", id, err)
+	}
+}
+
+// tailLines returns the last n lines read from the reader, at most n lines are kept in memory
+func tailLines(r io.Reader, n int) ([]byte, error) {
+	lines := make([][]byte, n)
+	count := 0
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			lines[count%n] = line
+			count++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	start := 0
+	if count > n {
+		start = count - n
+	}
+	buf := &bytes.Buffer{}
+	for i := start; i < count; i++ {
+		buf.Write(lines[i%n])
 	}
+	return buf.Bytes(), nil
 }
 
-// submit submits a job to job service per request
-func (aj *AJAPI) submit(ajr *models.AdminJobReq) {
+// prepareParameters validates the parameters of the request against the ones accepted by the job and
+// fills the defaults, then adds the ones read from the environment. It returns false if the parameters
+// are invalid or the request violates the policy of the job and the error has been sent back
+func (aj *AJAPI) prepareParameters(ajr *models.AdminJobReq) bool {
+	if err := ajr.ValidatePolicy(); err != nil {
+		aj.SendBadRequestError(err)
+		return false
+	}
+	if err := ajr.NormalizeParameters(); err != nil {
+		aj.SendBadRequestError(err)
+		return false
+	}
+	addEnvParameters(ajr)
+	return true
+}
+
+// submit submits a job to job service per request, it returns false if the submission fails
+// and the error has been sent back
+func (aj *AJAPI) submit(ajr *models.AdminJobReq) bool {
 	// when the schedule is saved as None without any schedule, just return 200 and do nothing.
 	if ajr.Schedule.Type == models.ScheduleNone {
-		return
+		return true
 	}
 
-	// cannot post multiple schedule for admin job.
+	// cannot post multiple schedule with the same name for admin job.
 	if ajr.IsPeriodic() {
-		jobs, err := dao.GetAdminJobs(&common_models.AdminJobQuery{
-			Name: ajr.Name,
-			Kind: common_job.JobKindPeriodic,
-		})
+		jobs, err := dao.GetAdminJobs(scheduleQuery(ajr.Name, ajr.ScheduleName))
 		if err != nil {
 			aj.SendInternalServerError(fmt.Errorf("failed to get admin jobs: %v", err))
-			return
+			return false
 		}
 		if len(jobs) != 0 {
+			if len(ajr.ScheduleName) > 0 {
+				aj.SendPreconditionFailedError(fmt.Errorf("fail to set schedule %s for admin job as always had one, please delete it firstly then to re-schedule", ajr.ScheduleName))
+				return false
+			}
 			aj.SendPreconditionFailedError(errors.New("fail to set schedule for admin job as always had one, please delete it firstly then to re-schedule"))
-			return
+			return false
 		}
 	}
 
-	id, err := dao.AddAdminJob(&common_models.AdminJob{
-		Name: ajr.Name,
-		Kind: ajr.JobKind(),
-		Cron: ajr.CronString(),
-	})
+	params, err := persistedParameters(ajr)
 	if err != nil {
 		aj.SendInternalServerError(err)
-		return
+		return false
+	}
+	adminJob := &common_models.AdminJob{
+		Name:         ajr.Name,
+		Kind:         ajr.JobKind(),
+		Cron:         ajr.CronString(),
+		Parameters:   params,
+		ScheduleName: ajr.ScheduleName,
+	}
+	var id int64
+	if max := ajr.MaxConcurrent(); max > 0 && !ajr.IsPeriodic() {
+		id, err = dao.AddAdminJobWithLimit(adminJob, max)
+	} else {
+		id, err = dao.AddAdminJob(adminJob)
+	}
+	if err != nil {
+		if err == dao.ErrAdminJobLimitReached {
+			aj.SendTooManyRequestsError(fmt.Errorf("no more than %d %s jobs are allowed to run at the same time, please retry later",
+				ajr.MaxConcurrent(), ajr.Name))
+			return false
+		}
+		aj.SendInternalServerError(err)
+		return false
 	}
 	ajr.ID = id
 	job := ajr.ToJob()
 
 	// submit job to job service
 	log.Debugf("submitting admin job to job service")
-	uuid, err := utils_core.GetJobServiceClient().SubmitJob(job)
+	uuid, err := getJobServiceClient().SubmitJob(job)
 	if err != nil {
+		// keep the job and re-submit it when the job service is back
+		if jobServiceUnavailable(err) {
+			log.Warningf("the job service is unavailable, admin job %d will be re-submitted later: %v", id, err)
+			if err := dao.UpdateAdminJobStatus(id, common_models.JobPendingSubmission); err != nil {
+				aj.SendInternalServerError(err)
+				return false
+			}
+			return true
+		}
 		if err := dao.DeleteAdminJob(id); err != nil {
 			log.Debugf("Failed to delete admin job, err: %v", err)
 		}
 		aj.ParseAndHandleError("failed to submit admin job", err)
-		return
+		return false
 	}
 	if err := dao.SetAdminJobUUID(id, uuid); err != nil {
 		aj.SendInternalServerError(err)
-		return
+		return false
+	}
+	pruneAdminJobs(ajr.Name)
+	return true
+}
+
+// pruneAdminJobs deletes the executions of admin job beyond the configured retention along with their
+// logs in the job service, the schedules and the executions not in the final status are kept. The failure
+// is only logged as the pruning is retried by the next submission and the logs left are swept by the
+// log sweeper of the job service eventually.
+func pruneAdminJobs(name string) {
+	count, age := config.AdminJobRetention()
+	if count > 0 {
+		jobs, err := dao.PruneAdminJobsByCount(name, count)
+		if err != nil {
+			log.Errorf("failed to prune the executions of admin job %s by count: %v", name, err)
+		} else if len(jobs) > 0 {
+			log.Debugf("%d executions of admin job %s are pruned as only the latest %d ones are kept", len(jobs), name, count)
+			deleteAdminJobLogs(jobs)
+		}
+	}
+	if age > 0 {
+		jobs, err := dao.PruneAdminJobsByAge(name, time.Now().Add(-age))
+		if err != nil {
+			log.Errorf("failed to prune the executions of admin job %s by age: %v", name, err)
+		} else if len(jobs) > 0 {
+			log.Debugf("%d executions of admin job %s are pruned as they are older than %v", len(jobs), name, age)
+			deleteAdminJobLogs(jobs)
+		}
+	}
+}
+
+// deleteAdminJobLogs requests the job service to delete the logs of the pruned executions, the ones
+// never submitted to the job service or whose logs have been swept are skipped
+func deleteAdminJobLogs(jobs []*common_models.AdminJob) {
+	for _, job := range jobs {
+		if len(job.UUID) == 0 {
+			continue
+		}
+		if err := getJobServiceClient().DeleteJobLog(job.UUID); err != nil {
+			if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusNotFound {
+				continue
+			}
+			log.Warningf("failed to delete the log of admin job %d(%s): %v", job.ID, job.UUID, err)
+		}
+	}
+}
+
+// scheduleQuery returns the query of the schedule of admin job with the name, the empty name means
+// the default schedule
+func scheduleQuery(name, scheduleName string) *common_models.AdminJobQuery {
+	return &common_models.AdminJobQuery{
+		Name:         name,
+		Kind:         common_job.JobKindPeriodic,
+		ScheduleName: &scheduleName,
 	}
 }
 
@@ -284,6 +732,29 @@ func convertToAdminJobRep(job *common_models.AdminJob) (models.AdminJobRep, erro
 		CreationTime: job.CreationTime,
 		UpdateTime:   job.UpdateTime,
 	}
+	AdminJobRep.ScheduleName = job.ScheduleName
+
+	if len(job.Parameters) > 0 {
+		if err := json.Unmarshal([]byte(job.Parameters), &AdminJobRep.Parameters); err != nil {
+			return models.AdminJobRep{}, err
+		}
+	}
+
+	if len(job.Summary) > 0 {
+		if err := json.Unmarshal([]byte(job.Summary), &AdminJobRep.Summary); err != nil {
+			return models.AdminJobRep{}, err
+		}
+	}
+
+	if len(job.Stats) > 0 {
+		stats := &common_models.GCStats{}
+		if err := json.Unmarshal([]byte(job.Stats), stats); err != nil {
+			return models.AdminJobRep{}, err
+		}
+		// the kind only identifies the check in
+		stats.Kind = ""
+		AdminJobRep.Stats = stats
+	}
 
 	if len(job.Cron) > 0 {
 		schedule, err := models.ConvertSchedule(job.Cron)
@@ -291,6 +762,9 @@ func convertToAdminJobRep(job *common_models.AdminJob) (models.AdminJobRep, erro
 			return models.AdminJobRep{}, err
 		}
 		AdminJobRep.Schedule = &schedule
+		if job.Kind == common_job.JobKindPeriodic {
+			AdminJobRep.NextRun = schedule.NextRun(time.Now())
+		}
 	}
 	return AdminJobRep, nil
 }
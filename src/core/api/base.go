@@ -20,9 +20,11 @@ import (
 	"fmt"
 	"github.com/goharbor/harbor/src/common/models"
 	"net/http"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/goharbor/harbor/src/common/api"
+	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/rbac"
 	"github.com/goharbor/harbor/src/common/security"
 	"github.com/goharbor/harbor/src/common/utils"
@@ -68,6 +70,10 @@ type BaseController struct {
 // Prepare inits security context and project manager from request
 // context
 func (b *BaseController) Prepare() {
+	// the handlers of the paths skipped by the security filter don't read the security context
+	if filter.Skipped(b.Ctx.Request) {
+		return
+	}
 	ctx, err := filter.GetSecurityContext(b.Ctx.Request)
 	if err != nil {
 		log.Errorf("failed to get security context: %v", err)
@@ -170,10 +176,17 @@ func (b *BaseController) WriteYamlData(object interface{}) {
 	_, _ = w.Write(yData)
 }
 
-// PopulateUserSession generates a new session ID and fill the user model in parm to the session
+// PopulateUserSession generates a new session ID and fill the user model in parm, the login time and
+// a new CSRF token to the session. It's called by all the UI logins, so the last login time of the
+// user is recorded here
 func (b *BaseController) PopulateUserSession(u models.User) {
+	if err := dao.UpdateLastLoginTime(u.UserID, time.Now()); err != nil {
+		log.Warningf("failed to update the last login time of user %s: %v", u.Username, err)
+	}
 	b.SessionRegenerateID()
 	b.SetSession(userSessionKey, u)
+	b.SetSession(filter.SessionLoginTimeKey, time.Now().Unix())
+	b.SetSession(filter.SessionCSRFTokenKey, filter.NewCSRFToken())
 }
 
 // Init related objects/configurations for the API controllers
@@ -208,7 +221,7 @@ func Init() error {
 		if err := json.Unmarshal([]byte(str), param); err != nil {
 			return fmt.Errorf("failed to unmarshal the param: %v", err)
 		}
-		_, err := retentionController.TriggerRetentionExec(param.PolicyID, param.Trigger, false)
+		_, err := retentionController.TriggerRetentionExec(param.PolicyID, param.Trigger, false, "")
 		return err
 	}
 	err := scheduler.Register(retention.SchedulerCallback, callbackFun)
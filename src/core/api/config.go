@@ -26,6 +26,7 @@ import (
 	"github.com/goharbor/harbor/src/common/security/secret"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/api/models"
+	"github.com/goharbor/harbor/src/core/auth/oidc"
 	corecfg "github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/filter"
 )
@@ -47,7 +48,8 @@ func (c *ConfigAPI) Prepare() {
 
 	// Only internal container can access /api/internal/configurations
 	if strings.EqualFold(c.Ctx.Request.RequestURI, "/api/internal/configurations") {
-		if _, ok := c.Ctx.Request.Context().Value(filter.SecurCtxKey).(*secret.SecurityContext); !ok {
+		sc, _ := filter.SecurityContextFrom(c.Ctx.Request.Context())
+		if _, ok := sc.(*secret.SecurityContext); !ok {
 			c.SendUnAuthorizedError(errors.New("UnAuthorized"))
 			return
 		}
@@ -113,6 +115,12 @@ func (c *ConfigAPI) Put() {
 
 	}
 
+	if err := c.registerOIDCClient(m); err != nil {
+		log.Errorf("failed to register OIDC client: %v", err)
+		c.SendBadRequestError(fmt.Errorf("failed to register client to the OIDC provider: %v", err))
+		return
+	}
+
 	if err := c.cfgManager.UpdateConfig(m); err != nil {
 		log.Errorf("failed to upload configurations: %v", err)
 		c.SendInternalServerError(errors.New(""))
@@ -138,6 +146,59 @@ func (c *ConfigAPI) validateCfg(cfgs map[string]interface{}) (bool, error) {
 	return false, nil
 }
 
+// registerOIDCClient registers harbor as a client of the OIDC provider dynamically if the client
+// credentials are not configured manually, and registers again when the endpoint of the provider
+// is changed. The credentials returned by the provider are put into the configurations to update.
+func (c *ConfigAPI) registerOIDCClient(cfgs map[string]interface{}) error {
+	current := func(key string) string {
+		if v, ok := cfgs[key]; ok {
+			return fmt.Sprintf("%v", v)
+		}
+		return c.cfgManager.Get(key).GetString()
+	}
+	if current(common.AUTHMode) != common.OIDCAuth {
+		return nil
+	}
+	// the client credentials are configured manually
+	if id, ok := cfgs[common.OIDCCLientID]; ok && len(fmt.Sprintf("%v", id)) > 0 {
+		cfgs[common.OIDCClientDynamic] = false
+		return nil
+	}
+
+	endpoint := current(common.OIDCEndpoint)
+	if !needOIDCClientRegistration(endpoint, c.cfgManager.Get(common.OIDCEndpoint).GetString(),
+		current(common.OIDCCLientID), c.cfgManager.Get(common.OIDCClientDynamic).GetBool()) {
+		return nil
+	}
+
+	extEndpoint := strings.TrimSuffix(current(common.ExtEndpoint), "/")
+	verifyCert := c.cfgManager.Get(common.OIDCVerifyCert).GetBool()
+	if v, ok := cfgs[common.OIDCVerifyCert].(bool); ok {
+		verifyCert = v
+	}
+	creds, err := oidc.RegisterClient(endpoint, extEndpoint+common.OIDCCallbackPath, "harbor", !verifyCert)
+	if err != nil {
+		return err
+	}
+	cfgs[common.OIDCCLientID] = creds.ClientID
+	cfgs[common.OIDCClientSecret] = creds.ClientSecret
+	cfgs[common.OIDCClientDynamic] = true
+	log.Infof("registered OIDC client %s to %s dynamically", creds.ClientID, endpoint)
+	return nil
+}
+
+// needOIDCClientRegistration checks whether the client should be registered to the OIDC provider:
+// no client has been configured, or the client was registered dynamically to another provider
+func needOIDCClientRegistration(endpoint, oldEndpoint, clientID string, dynamic bool) bool {
+	if len(endpoint) == 0 {
+		return false
+	}
+	if len(clientID) == 0 {
+		return true
+	}
+	return dynamic && endpoint != oldEndpoint
+}
+
 // delete sensitive attrs and add editable field to every attr
 func convertForGet(cfg map[string]interface{}) (map[string]*value, error) {
 	result := map[string]*value{}
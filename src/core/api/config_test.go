@@ -137,3 +137,17 @@ func TestPutConfigMaxLength(t *testing.T) {
 	code500, _ := apiTest.PutConfig(*admin, cfg)
 	assert.Equal(500, code500, "the status code of modifying configurations with admin user should be 500")
 }
+
+func TestNeedOIDCClientRegistration(t *testing.T) {
+	assert := assert.New(t)
+	// no provider configured
+	assert.False(needOIDCClientRegistration("", "", "", false))
+	// first configuration
+	assert.True(needOIDCClientRegistration("https://oidc.local", "", "", false))
+	// the client is registered dynamically and the provider isn't changed
+	assert.False(needOIDCClientRegistration("https://oidc.local", "https://oidc.local", "client-id", true))
+	// the client is registered dynamically and the provider is changed
+	assert.True(needOIDCClientRegistration("https://oidc2.local", "https://oidc.local", "client-id", true))
+	// the client is configured manually
+	assert.False(needOIDCClientRegistration("https://oidc2.local", "https://oidc.local", "client-id", false))
+}
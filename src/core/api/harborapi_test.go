@@ -91,7 +91,9 @@ func init() {
 	beego.BConfig.WebConfig.Session.SessionOn = true
 	beego.TestBeegoInit(apppath)
 
-	filter.Init()
+	if err := filter.Init(); err != nil {
+		log.Fatalf("failed to initialize the security filter: %v", err)
+	}
 	beego.InsertFilter("/*", beego.BeforeRouter, filter.SecurityFilter)
 
 	beego.Router("/api/health", &HealthAPI{}, "get:CheckHealth")
@@ -104,7 +106,16 @@ func init() {
 	beego.Router("/api/users/:id([0-9]+)/password", &UserAPI{}, "put:ChangePassword")
 	beego.Router("/api/users/:id/permissions", &UserAPI{}, "get:ListUserPermissions")
 	beego.Router("/api/users/:id/sysadmin", &UserAPI{}, "put:ToggleUserAdminRole")
+	beego.Router("/api/users/:id([0-9]+)/impersonate", &UserAPI{}, "post:Impersonate")
+	beego.Router("/api/users/:id([0-9]+)/override", &UserAPI{}, "post:Override")
+	beego.Router("/api/users/:id/apikeys", &UserAPIKeyAPI{}, "get:List;post:Post")
+	beego.Router("/api/users/:id/apikeys/:kid([0-9]+)", &UserAPIKeyAPI{}, "get:Get;put:Put;delete:Delete")
 	beego.Router("/api/projects/:id([0-9]+)/logs", &ProjectAPI{}, "get:Logs")
+	beego.Router("/api/projects/:id([0-9]+)/config-history", &ProjectAPI{}, "get:ConfigHistory")
+	beego.Router("/api/projects/:id([0-9]+)/scan-metrics", &ProjectAPI{}, "get:ScanMetrics")
+	beego.Router("/api/projects/:id([0-9]+)/storage-breakdown", &ProjectAPI{}, "get:StorageBreakdown")
+	beego.Router("/api/projects/:id([0-9]+)/repositories/*/tag-suggestions", &ProjectAPI{}, "get:TagSuggestions")
+	beego.Router("/api/projects/:id([0-9]+)/risk-score", &ProjectAPI{}, "get:RiskScore")
 	beego.Router("/api/projects/:id([0-9]+)/summary", &ProjectAPI{}, "get:Summary")
 	beego.Router("/api/projects/:id([0-9]+)/_deletable", &ProjectAPI{}, "get:Deletable")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/?:name", &MetadataAPI{}, "get:Get")
@@ -122,9 +133,10 @@ func init() {
 	beego.Router("/api/repositories/*/tags/:tag/labels", &RepositoryLabelAPI{}, "get:GetOfImage;post:AddToImage")
 	beego.Router("/api/repositories/*/tags/:tag/labels/:id([0-9]+", &RepositoryLabelAPI{}, "delete:RemoveFromImage")
 	beego.Router("/api/repositories/*/tags/:tag", &RepositoryAPI{}, "delete:Delete;get:GetTag")
-	beego.Router("/api/repositories/*/tags", &RepositoryAPI{}, "get:GetTags;post:Retag")
+	beego.Router("/api/repositories/*/tags", &RepositoryAPI{}, "get:GetTags;post:Retag;delete:DeleteTags")
 	beego.Router("/api/repositories/*/tags/:tag/manifest", &RepositoryAPI{}, "get:GetManifests")
 	beego.Router("/api/repositories/*/signatures", &RepositoryAPI{}, "get:GetSignatures")
+	beego.Router("/api/repositories/*/reclaimable", &RepositoryAPI{}, "post:GetReclaimableSizes")
 	beego.Router("/api/repositories/top", &RepositoryAPI{}, "get:GetTopRepos")
 	beego.Router("/api/registries", &RegistryAPI{}, "get:List;post:Post")
 	beego.Router("/api/registries/ping", &RegistryAPI{}, "post:Ping")
@@ -143,15 +155,30 @@ func init() {
 	beego.Router("/api/labels/:id([0-9]+", &LabelAPI{}, "get:Get;put:Put;delete:Delete")
 	beego.Router("/api/labels/:id([0-9]+)/resources", &LabelAPI{}, "get:ListResources")
 	beego.Router("/api/ping", &SystemInfoAPI{}, "get:Ping")
+	beego.Router("/api/system/gc", &GCAPI{}, "get:List")
 	beego.Router("/api/system/gc/:id", &GCAPI{}, "get:GetGC")
 	beego.Router("/api/system/gc/:id([0-9]+)/log", &GCAPI{}, "get:GetLog")
-	beego.Router("/api/system/gc/schedule", &GCAPI{}, "get:Get;put:Put;post:Post")
-	beego.Router("/api/system/scanAll/schedule", &ScanAllAPI{}, "get:Get;put:Put;post:Post")
+	beego.Router("/api/system/gc/:id([0-9]+)/stop", &GCAPI{}, "put:Stop")
+	beego.Router("/api/system/gc/schedule", &GCAPI{}, "get:Get;put:Put;post:Post;patch:Patch")
+	beego.Router("/api/system/scanAll/schedule", &ScanAllAPI{}, "get:Get;put:Put;post:Post;patch:Patch")
+	beego.Router("/api/system/scanAll/schedules", &ScanAllAPI{}, "get:ListSchedules")
+	beego.Router("/api/system/scanAll/:id([0-9]+)/stop", &ScanAllAPI{}, "put:Stop")
+	beego.Router("/api/system/schedule-audit", &ScheduleAuditAPI{}, "get:List")
+	beego.Router("/api/system/users/inactive", &InactiveUserAPI{}, "get:List")
+	beego.Router("/api/system/harbor/upgrade-check", &UpgradeCheckAPI{}, "post:Check")
+	beego.Router("/api/system/keys/rotate", &SystemKeyAPI{}, "post:Rotate")
+	beego.Router("/api/system/secrets/rotate", &SystemSecretAPI{}, "post:Rotate")
 	beego.Router("/api/system/CVEWhitelist", &SysCVEWhitelistAPI{}, "get:Get;put:Put")
 	beego.Router("/api/system/oidc/ping", &OIDCAPI{}, "post:Ping")
+	beego.Router("/api/system/replication/executions", &ReplicationOperationAPI{}, "get:ListSystemExecutions")
+	beego.Router("/api/system/artifacts/layer-sharing", &LayerSharingAPI{}, "get:List")
 
 	beego.Router("/api/projects/:pid([0-9]+)/robots/", &RobotAPI{}, "post:Post;get:List")
 	beego.Router("/api/projects/:pid([0-9]+)/robots/:id([0-9]+)", &RobotAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/projects/:pid([0-9]+)/robots/:id([0-9]+)/token", &RobotAPI{}, "put:RotateToken")
+	beego.Router("/api/system/robots", &SystemRobotAPI{}, "post:Post;get:List")
+	beego.Router("/api/system/robots/:id([0-9]+)", &SystemRobotAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/system/robots/:id([0-9]+)/token", &SystemRobotAPI{}, "put:RotateToken")
 
 	beego.Router("/api/replication/adapters", &ReplicationAdapterAPI{}, "get:List")
 	beego.Router("/api/replication/executions", &ReplicationOperationAPI{}, "get:ListExecutions;post:CreateExecution")
@@ -171,10 +198,15 @@ func init() {
 	beego.Router("/api/retentions/:id/executions", &RetentionAPI{}, "get:ListRetentionExecs")
 	beego.Router("/api/retentions/:id/executions/:eid/tasks", &RetentionAPI{}, "get:ListRetentionExecTasks")
 	beego.Router("/api/retentions/:id/executions/:eid/tasks/:tid", &RetentionAPI{}, "get:GetRetentionExecTaskLog")
+	beego.Router("/api/projects/:pid([0-9]+)/retentions/:id([0-9]+)/preview", &RetentionAPI{}, "get:PreviewRetention")
+	beego.Router("/api/projects/:pid([0-9]+)/retentions/:id([0-9]+)/analyze", &RetentionAPI{}, "post:AnalyzeRetentionConflicts")
+	beego.Router("/api/projects/:id([0-9]+)/tag-retention-conflicts", &RetentionAPI{}, "get:ListTagRetentionConflicts")
 
 	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies", &NotificationPolicyAPI{}, "get:List;post:Post")
 	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies/:id([0-9]+)", &NotificationPolicyAPI{})
 	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies/test", &NotificationPolicyAPI{}, "post:Test")
+	beego.Router("/api/projects/:pid([0-9]+)/webhooks/test", &NotificationPolicyAPI{}, "post:TestDelivery")
+	beego.Router("/api/projects/:pid([0-9]+)/webhooks/:id([0-9]+)/stats", &NotificationPolicyAPI{}, "get:Stats")
 	beego.Router("/api/projects/:pid([0-9]+)/webhook/lasttrigger", &NotificationPolicyAPI{}, "get:ListGroupByEventType")
 	beego.Router("/api/projects/:pid([0-9]+)/webhook/jobs/", &NotificationJobAPI{}, "get:List")
 	beego.Router("/api/projects/:pid([0-9]+)/immutabletagrules", &ImmutableTagRuleAPI{}, "get:List;post:Post")
@@ -213,10 +245,13 @@ func init() {
 	beego.Router("/api/scanners/:uuid", scannerAPI, "get:Get;delete:Delete;put:Update;patch:SetAsDefault")
 	beego.Router("/api/scanners/:uuid/metadata", scannerAPI, "get:Metadata")
 	beego.Router("/api/scanners/ping", scannerAPI, "post:Ping")
+	beego.Router("/api/system/scanner/benchmark", scannerAPI, "post:Benchmark")
+	beego.Router("/api/system/scanner/client-pool", scannerAPI, "get:ClientPool")
 
 	// Add routes for project level scanner
 	proScannerAPI := &ProjectScannerAPI{}
 	beego.Router("/api/projects/:pid([0-9]+)/scanner", proScannerAPI, "get:GetProjectScanner;put:SetProjectScanner")
+	beego.Router("/api/projects/:pid([0-9]+)/scanner/assignments", proScannerAPI, "get:GetScannerAssignments;put:SetScannerAssignments")
 
 	// Add routes for scan
 	scanAPI := &ScanAPI{}
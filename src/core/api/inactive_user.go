@@ -0,0 +1,79 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/core/filter"
+)
+
+const (
+	defaultInactiveDays = 90
+	maxInactiveUsers    = 500
+)
+
+// InactiveUserAPI handles the request to /api/system/users/inactive
+type InactiveUserAPI struct {
+	BaseController
+}
+
+// Prepare validates the user, it needs the system admin permission.
+func (i *InactiveUserAPI) Prepare() {
+	i.BaseController.Prepare()
+	if !i.SecurityCtx.IsAuthenticated() {
+		i.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+	if !i.SecurityCtx.IsSysAdmin() {
+		i.SendForbiddenError(errors.New(i.SecurityCtx.GetUsername()))
+		return
+	}
+}
+
+// List returns the users who haven't logged in for more than the days specified by the "days"
+// parameter, the returned users are disabled if the "disable" parameter is true
+func (i *InactiveUserAPI) List() {
+	days, err := i.GetInt("days", defaultInactiveDays)
+	if err != nil || days <= 0 {
+		i.SendBadRequestError(fmt.Errorf("invalid days %s, it must be a positive integer", i.GetString("days")))
+		return
+	}
+	disable, err := i.GetBool("disable", false)
+	if err != nil {
+		i.SendBadRequestError(fmt.Errorf("invalid disable %s: %v", i.GetString("disable"), err))
+		return
+	}
+
+	users, err := dao.ListInactiveUsers(days, maxInactiveUsers)
+	if err != nil {
+		i.SendInternalServerError(fmt.Errorf("failed to list the inactive users: %v", err))
+		return
+	}
+
+	if disable {
+		if err = dao.DisableUsers(i.SecurityCtx.GetUsername(), users); err != nil {
+			i.SendInternalServerError(fmt.Errorf("failed to disable the inactive users: %v", err))
+			return
+		}
+		// the cached basic auth results of the disabled users must not be honored any more
+		for _, user := range users {
+			filter.InvalidateBasicAuthCache(user.UserID)
+		}
+	}
+	i.WriteJSONData(users)
+}
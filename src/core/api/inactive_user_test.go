@@ -0,0 +1,176 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInactiveUserAPI(t *testing.T) {
+	require.Nil(t, dao.ClearTable("user_audit_log"))
+	defer dao.ClearTable("user_audit_log")
+
+	now := time.Now()
+	ids := []int64{}
+	for _, u := range []struct {
+		name      string
+		createdAt time.Time
+		lastLogin time.Time
+	}{
+		{name: "inactive-user-never-login", createdAt: now.AddDate(0, 0, -200)},
+		{name: "inactive-user-login", createdAt: now.AddDate(0, 0, -300), lastLogin: now.AddDate(0, 0, -100)},
+		{name: "active-user", createdAt: now.AddDate(0, 0, -300), lastLogin: now},
+	} {
+		id, err := dao.Register(models.User{
+			Username: u.name,
+			Email:    fmt.Sprintf("%s@example.com", u.name),
+			Password: "Harbor12345",
+			Realname: u.name,
+		})
+		require.Nil(t, err)
+		ids = append(ids, id)
+		_, err = dao.GetOrmer().Raw(`update harbor_user set creation_time = ? where user_id = ?`,
+			u.createdAt, id).Exec()
+		require.Nil(t, err)
+		if !u.lastLogin.IsZero() {
+			require.Nil(t, dao.UpdateLastLoginTime(int(id), u.lastLogin))
+		}
+	}
+	defer func() {
+		for _, id := range ids {
+			dao.CleanUser(id)
+		}
+	}()
+
+	url := "/api/system/users/inactive"
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        url,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, invalid days
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        url + "?days=0",
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	// list
+	users := []*models.InactiveUser{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url + "?days=90",
+		credential: sysAdmin,
+	}, &users)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(users))
+	assert.Equal(t, "inactive-user-never-login", users[0].Username)
+	assert.Nil(t, users[0].LastLoginAt)
+	assert.True(t, users[0].DaysInactive >= 199)
+	assert.Equal(t, "inactive-user-login", users[1].Username)
+	assert.NotNil(t, users[1].LastLoginAt)
+	assert.True(t, users[1].DaysInactive >= 99 && users[1].DaysInactive < users[0].DaysInactive)
+
+	// the users are not disabled by listing
+	entries := []*models.UserAuditEntry{}
+	_, err = dao.GetOrmer().QueryTable(&models.UserAuditEntry{}).All(&entries)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(entries))
+
+	// the user authenticated by basic auth is cached, the last login time is reset to keep it inactive
+	disabled := &usrInfo{Name: "inactive-user-never-login", Passwd: "Harbor12345"}
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodGet,
+			url:        "/api/users/current",
+			credential: disabled,
+		},
+		code: http.StatusOK,
+	})
+	_, err = dao.GetOrmer().Raw(`update harbor_user set last_login_at = null where user_id = ?`, ids[0]).Exec()
+	require.Nil(t, err)
+
+	// disable
+	users = []*models.InactiveUser{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url + "?days=90&disable=true",
+		credential: sysAdmin,
+	}, &users)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(users))
+
+	_, err = dao.GetOrmer().QueryTable(&models.UserAuditEntry{}).OrderBy("user_id").All(&entries)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(entries))
+	for i, entry := range entries {
+		assert.Equal(t, int(ids[i]), entry.UserID)
+		assert.Equal(t, models.UserAuditActionDisable, entry.Action)
+		assert.Equal(t, adminName, entry.Actor)
+	}
+
+	for i, id := range ids {
+		user, err := dao.GetUser(models.User{UserID: int(id)})
+		require.Nil(t, err)
+		require.NotNil(t, user)
+		assert.Equal(t, i < 2, user.Disabled)
+	}
+
+	// the cached basic auth result of the disabled user is invalidated
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodGet,
+			url:        "/api/users/current",
+			credential: disabled,
+		},
+		code: http.StatusUnauthorized,
+	})
+
+	// the disabled users are not returned any more
+	users = []*models.InactiveUser{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url + "?days=90",
+		credential: sysAdmin,
+	}, &users)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(users))
+}
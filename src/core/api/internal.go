@@ -22,6 +22,7 @@ import (
 	common_quota "github.com/goharbor/harbor/src/common/quota"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/filter"
 	"github.com/goharbor/harbor/src/jobservice/logger"
 	"github.com/pkg/errors"
 	"strconv"
@@ -72,6 +73,7 @@ func (ia *InternalAPI) RenameAdmin() {
 		ia.SendInternalServerError(errors.New("failed to rename admin user"))
 		return
 	}
+	filter.InvalidateBasicAuthCache(1)
 	log.Debugf("The super user has been renamed to: %s", newName)
 	ia.DestroySession()
 }
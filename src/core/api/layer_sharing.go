@@ -0,0 +1,74 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// LayerSharingAPI handles the request to /api/system/artifacts/layer-sharing
+type LayerSharingAPI struct {
+	BaseController
+}
+
+// Prepare validates the user, it needs the system admin permission.
+func (l *LayerSharingAPI) Prepare() {
+	l.BaseController.Prepare()
+	if !l.SecurityCtx.IsAuthenticated() {
+		l.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+	if !l.SecurityCtx.IsSysAdmin() {
+		l.SendForbiddenError(errors.New(l.SecurityCtx.GetUsername()))
+		return
+	}
+}
+
+// List returns the artifacts whose manifest contains the layer specified by the "digest" parameter
+func (l *LayerSharingAPI) List() {
+	digest := l.GetString("digest")
+	if len(digest) == 0 {
+		l.SendBadRequestError(errors.New("the layer digest is required"))
+		return
+	}
+
+	page, size, err := l.GetPaginationParams()
+	if err != nil {
+		l.SendBadRequestError(err)
+		return
+	}
+
+	total, err := dao.GetTotalOfArtifactsByLayerDigest(digest)
+	if err != nil {
+		l.SendInternalServerError(fmt.Errorf("failed to get the total of artifacts containing layer %s: %v", digest, err))
+		return
+	}
+
+	refs, err := dao.GetArtifactsByLayerDigest(digest, &models.Pagination{
+		Page: page,
+		Size: size,
+	})
+	if err != nil {
+		l.SendInternalServerError(fmt.Errorf("failed to get the artifacts containing layer %s: %v", digest, err))
+		return
+	}
+
+	l.SetPaginationHeader(total, page, size)
+	l.WriteJSONData(refs)
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayerSharingAPIList(t *testing.T) {
+	digest := "sha256:api-layer-sharing"
+	id, err := dao.AddArtifact(&models.Artifact{
+		PID:    1,
+		Repo:   "library/api-layer-sharing",
+		Tag:    "latest",
+		Digest: digest,
+		Kind:   "Docker-Image",
+	})
+	require.Nil(t, err)
+	defer dao.DeleteArtifact(id)
+	require.Nil(t, dao.AddArtifactNBlobs([]*models.ArtifactAndBlob{
+		{DigestAF: digest, DigestBlob: "sha256:api-layer-sharing-base"},
+	}))
+	defer dao.DeleteArtifactAndBlobByDigest(digest)
+
+	url := "/api/system/artifacts/layer-sharing"
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        url,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, no digest
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        url,
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	refs := []*models.ArtifactReference{}
+	err = handleAndParse(&testingRequest{
+		method: http.MethodGet,
+		url:    url,
+		queryStruct: struct {
+			Digest string `url:"digest"`
+		}{
+			Digest: "sha256:api-layer-sharing-base",
+		},
+		credential: sysAdmin,
+	}, &refs)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(refs))
+	assert.Equal(t, "library", refs[0].ProjectName)
+	assert.Equal(t, "library/api-layer-sharing", refs[0].Repo)
+	assert.Equal(t, digest, refs[0].Digest)
+}
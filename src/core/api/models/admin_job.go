@@ -17,6 +17,8 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -45,6 +47,80 @@ const (
 	ScheduleNone = "None"
 )
 
+const (
+	// GCWorkersParam is the parameter of GC job controlling how many blobs are deleted concurrently
+	GCWorkersParam = "workers"
+	// DefaultGCWorkers is the count of the GC workers if it isn't specified
+	DefaultGCWorkers = 4
+	// MaxGCWorkers is the max count of the GC workers
+	MaxGCWorkers = 20
+	// GCDeleteUntaggedParam is the parameter of GC job controlling whether the untagged manifests are deleted
+	GCDeleteUntaggedParam = "delete_untagged"
+	// GCReadOnlyParam is the parameter of GC job controlling whether the registry is read only during GC
+	GCReadOnlyParam = "read_only"
+	// GCDryRunParam is the parameter of GC job controlling whether the blobs eligible for deletion are
+	// only reported without deleting them
+	GCDryRunParam = "dry_run"
+)
+
+// MaxScheduleNameLength is the max length of the name of the admin job schedule
+const MaxScheduleNameLength = 64
+
+// scheduleNameRegexp matches the valid names of the admin job schedule
+var scheduleNameRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+
+// jobParameter defines a parameter accepted by the admin job
+type jobParameter struct {
+	// validate returns an error if the value isn't valid
+	validate func(value interface{}) error
+	// the value used if the parameter isn't specified
+	defaultValue interface{}
+}
+
+// jobParameters defines the parameters accepted per admin job, the ones not defined are rejected
+var jobParameters = map[string]map[string]jobParameter{
+	job.ImageGC: {
+		GCWorkersParam:        {validate: validateGCWorkers, defaultValue: DefaultGCWorkers},
+		GCDeleteUntaggedParam: {validate: validateBool, defaultValue: true},
+		GCReadOnlyParam:       {validate: validateBool, defaultValue: true},
+		GCDryRunParam:         {validate: validateBool, defaultValue: false},
+	},
+	job.ImageScanAllJob: {},
+}
+
+// jobPolicy defines how the admin job is submitted to the job service
+type jobPolicy struct {
+	// whether the job service rejects the job if the same one is running
+	unique bool
+	// whether the uniqueness can be overridden by the request
+	uniqueOverridable bool
+	// the max number of the instances waiting to run or running, 0 means no limit
+	maxConcurrent int
+	// whether the job can have multiple named schedules besides the default one
+	namedSchedules bool
+}
+
+// defaultJobPolicy is used for the admin jobs not defined in jobPolicies
+var defaultJobPolicy = jobPolicy{unique: true}
+
+// jobPolicies defines the policy per admin job
+var jobPolicies = map[string]jobPolicy{
+	// GC must be unique as the concurrent ones delete the blobs in use by each other
+	job.ImageGC: {unique: true, maxConcurrent: 1},
+	job.ImageScanAllJob: {
+		uniqueOverridable: true,
+		maxConcurrent:     3,
+		namedSchedules:    true,
+	},
+}
+
+func policyOf(name string) jobPolicy {
+	if policy, exist := jobPolicies[name]; exist {
+		return policy
+	}
+	return defaultJobPolicy
+}
+
 // AdminJobReq holds request information for admin job
 type AdminJobReq struct {
 	AdminJobSchedule
@@ -52,11 +128,17 @@ type AdminJobReq struct {
 	Status     string                 `json:"status"`
 	ID         int64                  `json:"id"`
 	Parameters map[string]interface{} `json:"parameters"`
+	// Unique overrides the uniqueness of the job defined by its policy if it's set
+	Unique *bool `json:"unique,omitempty"`
 }
 
 // AdminJobSchedule ...
 type AdminJobSchedule struct {
-	Schedule *ScheduleParam `json:"schedule"`
+	// The name of the schedule, it's empty for the default schedule
+	ScheduleName string         `json:"schedule_name,omitempty"`
+	Schedule     *ScheduleParam `json:"schedule"`
+	// The next time the schedule is triggered, it's computed for the response only
+	NextRun *time.Time `json:"next_run,omitempty"`
 }
 
 // ScheduleParam defines the parameter of schedule trigger
@@ -65,6 +147,37 @@ type ScheduleParam struct {
 	Type string `json:"type"`
 	// The cron string of scheduled job
 	Cron string `json:"cron"`
+	// The IANA name of the timezone the cron is evaluated in, e.g. "Asia/Shanghai",
+	// the timezone of job service(UTC by default) is used if it's empty
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// NextRun returns the next time the cron of the schedule is triggered after the time, it's evaluated in
+// the timezone of the schedule or the local one if it's empty. Nil is returned if it isn't a periodic
+// schedule or the cron or timezone is invalid.
+func (s *ScheduleParam) NextRun(after time.Time) *time.Time {
+	if s == nil || len(s.Cron) == 0 {
+		return nil
+	}
+	switch s.Type {
+	case ScheduleManual, ScheduleNone:
+		return nil
+	}
+
+	schedule, err := cron.Parse(s.Cron)
+	if err != nil {
+		log.Warningf("failed to parse the cron %s of schedule: %v", s.Cron, err)
+		return nil
+	}
+	loc := time.Local
+	if len(s.Timezone) > 0 {
+		if loc, err = time.LoadLocation(s.Timezone); err != nil {
+			log.Warningf("failed to load the timezone %s of schedule: %v", s.Timezone, err)
+			return nil
+		}
+	}
+	next := schedule.Next(after.In(loc))
+	return &next
 }
 
 // AdminJobRep holds the response of query admin job
@@ -78,6 +191,12 @@ type AdminJobRep struct {
 	Deleted      bool      `json:"deleted"`
 	CreationTime time.Time `json:"creation_time"`
 	UpdateTime   time.Time `json:"update_time"`
+	// The normalized parameters the job is submitted with
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	// The summary reported by the job, e.g. the blobs found by the dry run of GC
+	Summary map[string]interface{} `json:"summary,omitempty"`
+	// The statistics of the execution reported by GC when it completes, it's null if the job doesn't report it
+	Stats *common_models.GCStats `json:"stats"`
 }
 
 // Valid validates the schedule type of a admin job request.
@@ -91,19 +210,99 @@ func (ar *AdminJobReq) Valid(v *validation.Validation) {
 		if _, err := cron.Parse(ar.Schedule.Cron); err != nil {
 			v.SetError("cron", fmt.Sprintf("Invalid schedule trigger parameter cron: %s", ar.Schedule.Cron))
 		}
-	case ScheduleManual, ScheduleNone:
+		if len(ar.Schedule.Timezone) > 0 {
+			if _, err := time.LoadLocation(ar.Schedule.Timezone); err != nil {
+				v.SetError("timezone", fmt.Sprintf("Invalid schedule trigger parameter timezone: %s", ar.Schedule.Timezone))
+			}
+		}
+	case ScheduleManual:
+		if len(ar.ScheduleName) > 0 {
+			v.SetError("schedule_name", "The manual trigger can't be named")
+		}
+	case ScheduleNone:
 	default:
 		v.SetError("kind", fmt.Sprintf("Invalid schedule kind: %s", ar.Schedule.Type))
 	}
+	if len(ar.ScheduleName) > 0 {
+		if err := ValidateScheduleName(ar.ScheduleName); err != nil {
+			v.SetError("schedule_name", err.Error())
+		}
+	}
+	if workers, exist := ar.Parameters[GCWorkersParam]; exist {
+		if err := validateGCWorkers(workers); err != nil {
+			v.SetError(GCWorkersParam, fmt.Sprintf("Invalid %s: %v", GCWorkersParam, err))
+		}
+	}
+}
+
+// NormalizeParameters validates the parameters against the ones accepted by the admin job and fills
+// the defaults of the ones not specified. The unknown parameters and the invalid ones are all reported
+// in the returned error. The job must be named before.
+func (ar *AdminJobReq) NormalizeParameters() error {
+	params, exist := jobParameters[ar.Name]
+	if !exist {
+		return fmt.Errorf("unknown admin job: %s", ar.Name)
+	}
+
+	var problems []string
+	for key, value := range ar.Parameters {
+		param, exist := params[key]
+		if !exist {
+			problems = append(problems, fmt.Sprintf("unknown parameter: %s", key))
+			continue
+		}
+		if err := param.validate(value); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid %s: %v", key, err))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid parameters of %s: %s", ar.Name, strings.Join(problems, "; "))
+	}
+
+	for key, param := range params {
+		if _, exist := ar.Parameters[key]; exist {
+			continue
+		}
+		if ar.Parameters == nil {
+			ar.Parameters = map[string]interface{}{}
+		}
+		ar.Parameters[key] = param.defaultValue
+	}
+	return nil
+}
+
+func validateGCWorkers(value interface{}) error {
+	if n, ok := value.(float64); !ok || n != float64(int(n)) || n < 1 || n > MaxGCWorkers {
+		return fmt.Errorf("%v must be an integer between 1 and %d", value, MaxGCWorkers)
+	}
+	return nil
+}
+
+func validateBool(value interface{}) error {
+	if _, ok := value.(bool); !ok {
+		return fmt.Errorf("%v must be a boolean", value)
+	}
+	return nil
+}
+
+// GCWorkers returns the count of the GC workers specified in the request, or the default
+// one if it isn't specified. The request must have been validated.
+func (ar *AdminJobReq) GCWorkers() int {
+	if n, ok := ar.Parameters[GCWorkersParam].(float64); ok {
+		return int(n)
+	}
+	return DefaultGCWorkers
 }
 
 // ToJob converts request to a job recognized by job service.
 func (ar *AdminJobReq) ToJob() *models.JobData {
 	metadata := &models.JobMetadata{
-		JobKind: ar.JobKind(),
-		Cron:    ar.Schedule.Cron,
-		// GC job must be unique ...
-		IsUnique: true,
+		JobKind:       ar.JobKind(),
+		Cron:          ar.Schedule.Cron,
+		Timezone:      ar.Schedule.Timezone,
+		IsUnique:      ar.IsUnique(),
+		MaxConcurrent: ar.MaxConcurrent(),
 	}
 
 	jobData := &models.JobData{
@@ -116,6 +315,48 @@ func (ar *AdminJobReq) ToJob() *models.JobData {
 	return jobData
 }
 
+// SupportsNamedSchedules returns whether the job can have multiple named schedules
+func (ar *AdminJobReq) SupportsNamedSchedules() bool {
+	return policyOf(ar.Name).namedSchedules
+}
+
+// IsUnique returns whether the job service rejects the job if the same one is running, it's
+// defined by the policy of the job unless it's overridden by the request
+func (ar *AdminJobReq) IsUnique() bool {
+	if ar.Unique != nil {
+		return *ar.Unique
+	}
+	return policyOf(ar.Name).unique
+}
+
+// ValidatePolicy validates the request against the policy of the job. The job must be named before.
+func (ar *AdminJobReq) ValidatePolicy() error {
+	policy := policyOf(ar.Name)
+	if len(ar.ScheduleName) > 0 && !policy.namedSchedules {
+		return fmt.Errorf("admin job %s doesn't support the named schedules", ar.Name)
+	}
+	if ar.Unique != nil && *ar.Unique != policy.unique && !policy.uniqueOverridable {
+		return fmt.Errorf("the uniqueness of admin job %s can't be overridden", ar.Name)
+	}
+	return nil
+}
+
+// ValidateScheduleName validates the name of the admin job schedule, it must consist of lower case
+// alphanumeric characters separated by '.', '_' or '-' and no longer than MaxScheduleNameLength
+func ValidateScheduleName(name string) error {
+	if len(name) > MaxScheduleNameLength || !scheduleNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid schedule name %q, it must consist of lower case alphanumeric characters "+
+			"separated by '.', '_' or '-' and be no longer than %d", name, MaxScheduleNameLength)
+	}
+	return nil
+}
+
+// MaxConcurrent returns the max number of the instances of the job waiting to run or running,
+// 0 means no limit. It's enforced by core when adding the job, the job service isn't aware of it
+func (ar *AdminJobReq) MaxConcurrent() int {
+	return policyOf(ar.Name).maxConcurrent
+}
+
 // IsPeriodic ...
 func (ar *AdminJobReq) IsPeriodic() bool {
 	return ar.JobKind() == job.JobKindPeriodic
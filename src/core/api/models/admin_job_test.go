@@ -16,7 +16,9 @@ package models
 import (
 	"testing"
 
+	"github.com/astaxie/beego/validation"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/goharbor/harbor/src/common"
 	common_job "github.com/goharbor/harbor/src/common/job"
@@ -24,6 +26,7 @@ import (
 	"github.com/goharbor/harbor/src/core/config"
 	"os"
 	"strings"
+	"time"
 )
 
 var testConfig = map[string]interface{}{
@@ -77,6 +80,337 @@ func TestToJobManual(t *testing.T) {
 	assert.Equal(t, job.Metadata.JobKind, common_job.JobKindGeneric)
 }
 
+func TestToJobUniqueness(t *testing.T) {
+	manual := AdminJobSchedule{
+		Schedule: &ScheduleParam{
+			Type: ScheduleManual,
+		},
+	}
+	unique, notUnique := true, false
+
+	cases := []struct {
+		name          string
+		unique        *bool
+		isUnique      bool
+		maxConcurrent int
+	}{
+		{name: common_job.ImageGC, isUnique: true, maxConcurrent: 1},
+		{name: common_job.ImageScanAllJob, isUnique: false, maxConcurrent: 3},
+		{name: common_job.ImageScanAllJob, unique: &unique, isUnique: true, maxConcurrent: 3},
+		{name: common_job.ImageScanAllJob, unique: &notUnique, isUnique: false, maxConcurrent: 3},
+		// the jobs without policy are unique
+		{name: "unknown", isUnique: true},
+	}
+	for _, c := range cases {
+		adminjob := &AdminJobReq{
+			Name:             c.name,
+			AdminJobSchedule: manual,
+			Unique:           c.unique,
+		}
+		metadata := adminjob.ToJob().Metadata
+		assert.Equal(t, common_job.JobKindGeneric, metadata.JobKind, c.name)
+		assert.Equal(t, c.isUnique, metadata.IsUnique, c.name)
+		assert.Equal(t, c.maxConcurrent, metadata.MaxConcurrent, c.name)
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	unique, notUnique := true, false
+	cases := []struct {
+		adminjob *AdminJobReq
+		hasErr   bool
+	}{
+		{adminjob: &AdminJobReq{Name: common_job.ImageGC}},
+		{adminjob: &AdminJobReq{Name: common_job.ImageGC, Unique: &unique}},
+		// GC can't run concurrently
+		{adminjob: &AdminJobReq{Name: common_job.ImageGC, Unique: &notUnique}, hasErr: true},
+		{adminjob: &AdminJobReq{Name: common_job.ImageScanAllJob, Unique: &unique}},
+		{adminjob: &AdminJobReq{Name: common_job.ImageScanAllJob, Unique: &notUnique}},
+		{
+			adminjob: &AdminJobReq{
+				Name:             common_job.ImageScanAllJob,
+				AdminJobSchedule: AdminJobSchedule{ScheduleName: "weekly"},
+			},
+		},
+		{
+			adminjob: &AdminJobReq{
+				Name:             common_job.ImageGC,
+				AdminJobSchedule: AdminJobSchedule{ScheduleName: "weekly"},
+			},
+			hasErr: true,
+		},
+	}
+	for i, c := range cases {
+		err := c.adminjob.ValidatePolicy()
+		assert.Equal(t, c.hasErr, err != nil, "case %d: %v", i, err)
+	}
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	adminjob := &AdminJobReq{Name: common_job.ImageGC}
+	assert.Equal(t, 1, adminjob.MaxConcurrent())
+
+	adminjob = &AdminJobReq{Name: common_job.ImageScanAllJob}
+	assert.Equal(t, 3, adminjob.MaxConcurrent())
+
+	adminjob = &AdminJobReq{Name: "unknown"}
+	assert.Equal(t, 0, adminjob.MaxConcurrent())
+}
+
+func TestValidGCWorkers(t *testing.T) {
+	cases := []struct {
+		parameters map[string]interface{}
+		hasErr     bool
+		workers    int
+	}{
+		{parameters: nil, workers: DefaultGCWorkers},
+		{parameters: map[string]interface{}{GCWorkersParam: float64(8)}, workers: 8},
+		{parameters: map[string]interface{}{GCWorkersParam: float64(0)}, hasErr: true},
+		{parameters: map[string]interface{}{GCWorkersParam: float64(21)}, hasErr: true},
+		{parameters: map[string]interface{}{GCWorkersParam: 2.5}, hasErr: true},
+		{parameters: map[string]interface{}{GCWorkersParam: "8"}, hasErr: true},
+	}
+	for _, c := range cases {
+		adminjob := &AdminJobReq{
+			AdminJobSchedule: AdminJobSchedule{
+				Schedule: &ScheduleParam{
+					Type: ScheduleManual,
+				},
+			},
+			Parameters: c.parameters,
+		}
+		v := &validation.Validation{}
+		adminjob.Valid(v)
+		assert.Equal(t, c.hasErr, v.HasErrors())
+		if !c.hasErr {
+			assert.Equal(t, c.workers, adminjob.GCWorkers())
+		}
+	}
+}
+
+func TestValidTimezone(t *testing.T) {
+	cases := []struct {
+		timezone string
+		hasErr   bool
+	}{
+		{timezone: ""},
+		{timezone: "UTC"},
+		{timezone: "America/New_York"},
+		{timezone: "Asia/Shanghai"},
+		{timezone: "Mars/Olympus_Mons", hasErr: true},
+		{timezone: "GMT+8", hasErr: true},
+	}
+	for _, c := range cases {
+		adminjob := &AdminJobReq{
+			AdminJobSchedule: AdminJobSchedule{
+				Schedule: &ScheduleParam{
+					Type:     ScheduleDaily,
+					Cron:     "0 0 2 * * *",
+					Timezone: c.timezone,
+				},
+			},
+		}
+		v := &validation.Validation{}
+		adminjob.Valid(v)
+		assert.Equal(t, c.hasErr, v.HasErrors(), c.timezone)
+	}
+}
+
+func TestValidScheduleName(t *testing.T) {
+	cases := []struct {
+		name         string
+		scheduleType string
+		hasErr       bool
+	}{
+		{name: "", scheduleType: ScheduleManual},
+		{name: "weekly", scheduleType: ScheduleWeekly},
+		{name: "daily-library", scheduleType: ScheduleDaily},
+		{name: "v1.daily_library", scheduleType: ScheduleDaily},
+		{name: "weekly", scheduleType: ScheduleNone},
+		{name: "Weekly", scheduleType: ScheduleWeekly, hasErr: true},
+		{name: "-weekly", scheduleType: ScheduleWeekly, hasErr: true},
+		{name: "weekly schedule", scheduleType: ScheduleWeekly, hasErr: true},
+		{name: strings.Repeat("a", MaxScheduleNameLength+1), scheduleType: ScheduleWeekly, hasErr: true},
+		// the manual trigger can't be named
+		{name: "weekly", scheduleType: ScheduleManual, hasErr: true},
+	}
+	for _, c := range cases {
+		adminjob := &AdminJobReq{
+			AdminJobSchedule: AdminJobSchedule{
+				ScheduleName: c.name,
+				Schedule: &ScheduleParam{
+					Type: c.scheduleType,
+					Cron: "0 0 0 * * 0",
+				},
+			},
+		}
+		v := &validation.Validation{}
+		adminjob.Valid(v)
+		assert.Equal(t, c.hasErr, v.HasErrors(), "%s: %s", c.scheduleType, c.name)
+	}
+}
+
+func TestSupportsNamedSchedules(t *testing.T) {
+	assert.True(t, (&AdminJobReq{Name: common_job.ImageScanAllJob}).SupportsNamedSchedules())
+	assert.False(t, (&AdminJobReq{Name: common_job.ImageGC}).SupportsNamedSchedules())
+}
+
+func TestToJobTimezone(t *testing.T) {
+	adminjob := &AdminJobReq{
+		Name: common_job.ImageGC,
+		AdminJobSchedule: AdminJobSchedule{
+			Schedule: &ScheduleParam{
+				Type:     ScheduleDaily,
+				Cron:     "0 0 2 * * *",
+				Timezone: "America/New_York",
+			},
+		},
+	}
+
+	job := adminjob.ToJob()
+	assert.Equal(t, "0 0 2 * * *", job.Metadata.Cron)
+	assert.Equal(t, "America/New_York", job.Metadata.Timezone)
+
+	// the timezone is persisted with the cron and echoed back
+	converted, err := ConvertSchedule(adminjob.CronString())
+	assert.Nil(t, err)
+	assert.Equal(t, *adminjob.Schedule, converted)
+}
+
+func TestNormalizeParameters(t *testing.T) {
+	cases := []struct {
+		name       string
+		parameters map[string]interface{}
+		normalized map[string]interface{}
+		problems   []string
+	}{
+		// GC with the defaults
+		{
+			name: common_job.ImageGC,
+			normalized: map[string]interface{}{
+				GCWorkersParam:        DefaultGCWorkers,
+				GCDeleteUntaggedParam: true,
+				GCReadOnlyParam:       true,
+				GCDryRunParam:         false,
+			},
+		},
+		// GC with all the parameters
+		{
+			name: common_job.ImageGC,
+			parameters: map[string]interface{}{
+				GCWorkersParam:        float64(8),
+				GCDeleteUntaggedParam: false,
+				GCReadOnlyParam:       false,
+				GCDryRunParam:         true,
+			},
+			normalized: map[string]interface{}{
+				GCWorkersParam:        float64(8),
+				GCDeleteUntaggedParam: false,
+				GCReadOnlyParam:       false,
+				GCDryRunParam:         true,
+			},
+		},
+		// GC with the unknown and invalid parameters
+		{
+			name: common_job.ImageGC,
+			parameters: map[string]interface{}{
+				"delete_untaged":      true,
+				GCDeleteUntaggedParam: "ture",
+				GCReadOnlyParam:       float64(1),
+				GCWorkersParam:        float64(0),
+				GCDryRunParam:         "yes",
+			},
+			problems: []string{"invalid dry_run: yes", "unknown parameter: delete_untaged",
+				"invalid delete_untagged: ture", "invalid read_only: 1", "invalid workers: 0"},
+		},
+		// scan all without parameters
+		{
+			name: common_job.ImageScanAllJob,
+		},
+		// scan all accepts no parameters
+		{
+			name:       common_job.ImageScanAllJob,
+			parameters: map[string]interface{}{GCWorkersParam: float64(8)},
+			problems:   []string{"unknown parameter: workers"},
+		},
+		// unknown job
+		{
+			name:     "unknown",
+			problems: []string{"unknown admin job: unknown"},
+		},
+	}
+	for _, c := range cases {
+		adminjob := &AdminJobReq{
+			Name:       c.name,
+			Parameters: c.parameters,
+		}
+		err := adminjob.NormalizeParameters()
+		if len(c.problems) > 0 {
+			require.NotNil(t, err)
+			for _, problem := range c.problems {
+				assert.Contains(t, err.Error(), problem)
+			}
+			continue
+		}
+		require.Nil(t, err)
+		assert.Equal(t, c.normalized, adminjob.Parameters)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	// Wednesday
+	now := time.Date(2019, 10, 16, 10, 20, 30, 0, time.UTC)
+	cases := []struct {
+		schedule *ScheduleParam
+		next     *time.Time
+	}{
+		{schedule: nil},
+		{schedule: &ScheduleParam{Type: ScheduleManual}},
+		{schedule: &ScheduleParam{Type: ScheduleNone}},
+		{
+			schedule: &ScheduleParam{Type: ScheduleHourly, Cron: "0 0 * * * *", Timezone: "UTC"},
+			next:     timePtr(time.Date(2019, 10, 16, 11, 0, 0, 0, time.UTC)),
+		},
+		{
+			schedule: &ScheduleParam{Type: ScheduleDaily, Cron: "0 0 0 * * *", Timezone: "UTC"},
+			next:     timePtr(time.Date(2019, 10, 17, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			schedule: &ScheduleParam{Type: ScheduleWeekly, Cron: "0 0 0 * * 0", Timezone: "UTC"},
+			next:     timePtr(time.Date(2019, 10, 20, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			schedule: &ScheduleParam{Type: ScheduleCustom, Cron: "0 30 2 * * 1-5", Timezone: "UTC"},
+			next:     timePtr(time.Date(2019, 10, 17, 2, 30, 0, 0, time.UTC)),
+		},
+		// 2am in New York is 6am in UTC during the DST
+		{
+			schedule: &ScheduleParam{Type: ScheduleDaily, Cron: "0 0 2 * * *", Timezone: "America/New_York"},
+			next:     timePtr(time.Date(2019, 10, 17, 6, 0, 0, 0, time.UTC)),
+		},
+		// the legacy schedule converted
+		{
+			schedule: &ScheduleParam{Type: "custom", Cron: "0 2 16 * * *", Timezone: "UTC"},
+			next:     timePtr(time.Date(2019, 10, 16, 16, 2, 0, 0, time.UTC)),
+		},
+		{schedule: &ScheduleParam{Type: ScheduleCustom, Cron: "invalid"}},
+		{schedule: &ScheduleParam{Type: ScheduleDaily, Cron: "0 0 0 * * *", Timezone: "Mars/Olympus_Mons"}},
+	}
+	for _, c := range cases {
+		next := c.schedule.NextRun(now)
+		if c.next == nil {
+			assert.Nil(t, next)
+			continue
+		}
+		require.NotNil(t, next)
+		assert.True(t, c.next.Equal(*next), "expected %v but got %v", c.next, next)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
 func TestIsPeriodic(t *testing.T) {
 
 	adminJobSchedule := AdminJobSchedule{
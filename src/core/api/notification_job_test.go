@@ -38,6 +38,30 @@ func (f *fakedNotificationJobMgr) ListJobsGroupByEventType(policyID int64) ([]*m
 	}, nil
 }
 
+func (f *fakedNotificationJobMgr) Get(id int64) (*models.NotificationJob, error) {
+	return nil, nil
+}
+
+func (f *fakedNotificationJobMgr) CreateDeliveryLog(log *models.WebhookDeliveryLog) (int64, error) {
+	return 1, nil
+}
+
+func (f *fakedNotificationJobMgr) GetDeliveryStats(policyID int64, days int) (*models.WebhookDeliveryStats, error) {
+	return &models.WebhookDeliveryStats{
+		PeriodDays:      days,
+		TotalDeliveries: 3,
+		Successful:      2,
+		Failed:          1,
+		AvgDurationMS:   20,
+		ErrorBreakdown: []*models.WebhookDeliveryErrorCount{
+			{
+				StatusCode: 500,
+				Count:      1,
+			},
+		},
+	}, nil
+}
+
 func TestNotificationJobAPI_List(t *testing.T) {
 	policyMgr := notification.PolicyMgr
 	jobMgr := notification.JobMgr
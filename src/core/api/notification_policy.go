@@ -7,14 +7,28 @@ import (
 	"strconv"
 	"time"
 
+	beego_cache "github.com/astaxie/beego/cache"
 	"github.com/goharbor/harbor/src/common/utils/log"
 
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/rbac"
 	"github.com/goharbor/harbor/src/common/utils"
+	notifierModel "github.com/goharbor/harbor/src/core/notifier/model"
 	"github.com/goharbor/harbor/src/pkg/notification"
+	"github.com/goharbor/harbor/src/pkg/notification/hook"
+	"github.com/goharbor/harbor/src/pkg/notification/model"
+	"github.com/goharbor/harbor/src/pkg/scan/vuln"
 )
 
+const (
+	// webhookStatsPeriodDays is the period covered by the delivery stats of webhook
+	webhookStatsPeriodDays = 7
+	// webhookStatsCacheTTL is how long the delivery stats of a policy are cached
+	webhookStatsCacheTTL = 5 * time.Minute
+)
+
+var webhookStatsCache = beego_cache.NewMemoryCache()
+
 // NotificationPolicyAPI ...
 type NotificationPolicyAPI struct {
 	BaseController
@@ -110,6 +124,10 @@ func (w *NotificationPolicyAPI) Post() {
 		return
 	}
 
+	if !w.validateScanSeverityThreshold(policy) {
+		return
+	}
+
 	if policy.ID != 0 {
 		w.SendBadRequestError(fmt.Errorf("cannot accept policy creating request with ID: %d", policy.ID))
 		return
@@ -163,6 +181,10 @@ func (w *NotificationPolicyAPI) Put() {
 		return
 	}
 
+	if !w.validateScanSeverityThreshold(policy) {
+		return
+	}
+
 	if w.project.ProjectID != oriPolicy.ProjectID {
 		w.SendBadRequestError(fmt.Errorf("notification policy %d with projectID %d not belong to project %d in URL", id, oriPolicy.ProjectID, w.project.ProjectID))
 		return
@@ -281,6 +303,90 @@ func (w *NotificationPolicyAPI) Test() {
 	}
 }
 
+// TestDelivery sends a synthetic test event to the target by the webhook job and returns the status
+// code and the latency of the delivery
+func (w *NotificationPolicyAPI) TestDelivery() {
+	if !w.validateRBAC(rbac.ActionCreate, w.project.ProjectID) {
+		return
+	}
+
+	target := &models.EventTarget{}
+	if err := w.DecodeJSONReq(target); err != nil {
+		w.SendBadRequestError(err)
+		return
+	}
+	if err := validateTarget(target); err != nil {
+		w.SendBadRequestError(err)
+		return
+	}
+
+	// the job rejects the internal address when connecting as well, this is checked here to
+	// report the error to the user directly
+	if err := utils.CheckExternalEndpoint(target.Address); err != nil {
+		w.SendBadRequestError(err)
+		return
+	}
+
+	result, err := notification.HookManager.DeliverTest(&notifierModel.HookEvent{
+		EventType: model.EventTypeTestEndpoint,
+		Target:    target,
+	})
+	if err != nil {
+		if err == hook.ErrDeliveryTimeout {
+			w.SendStatusServiceUnavailableError(fmt.Errorf("failed to deliver the test event to %s: %v", target.Address, err))
+			return
+		}
+		w.SendInternalServerError(fmt.Errorf("failed to deliver the test event to %s: %v", target.Address, err))
+		return
+	}
+
+	w.WriteJSONData(result)
+}
+
+// Stats returns the delivery success rate of the notification policy in the last days
+func (w *NotificationPolicyAPI) Stats() {
+	if !w.validateRBAC(rbac.ActionRead, w.project.ProjectID) {
+		return
+	}
+
+	id, err := w.GetIDFromURL()
+	if err != nil {
+		w.SendBadRequestError(err)
+		return
+	}
+
+	policy, err := notification.PolicyMgr.Get(id)
+	if err != nil {
+		w.SendInternalServerError(fmt.Errorf("failed to get the notification policy %d: %v", id, err))
+		return
+	}
+	if policy == nil {
+		w.SendNotFoundError(fmt.Errorf("notification policy %d not found", id))
+		return
+	}
+
+	if w.project.ProjectID != policy.ProjectID {
+		w.SendBadRequestError(fmt.Errorf("notification policy %d with projectID %d not belong to project %d in URL", id, policy.ProjectID, w.project.ProjectID))
+		return
+	}
+
+	key := strconv.FormatInt(id, 10)
+	if stats, ok := webhookStatsCache.Get(key).(*models.WebhookDeliveryStats); ok {
+		w.WriteJSONData(stats)
+		return
+	}
+
+	stats, err := notification.JobMgr.GetDeliveryStats(id, webhookStatsPeriodDays)
+	if err != nil {
+		w.SendInternalServerError(fmt.Errorf("failed to get the delivery stats of notification policy %d: %v", id, err))
+		return
+	}
+	if err = webhookStatsCache.Put(key, stats, webhookStatsCacheTTL); err != nil {
+		log.Warningf("failed to cache the delivery stats of notification policy %d: %v", id, err)
+	}
+	w.WriteJSONData(stats)
+}
+
 func (w *NotificationPolicyAPI) validateRBAC(action rbac.Action, projectID int64) bool {
 	if w.SecurityCtx.IsSysAdmin() {
 		return true
@@ -296,17 +402,8 @@ func (w *NotificationPolicyAPI) validateTargets(policy *models.NotificationPolic
 	}
 
 	for _, target := range policy.Targets {
-		url, err := utils.ParseEndpoint(target.Address)
-		if err != nil {
-			w.SendBadRequestError(err)
-			return false
-		}
-		// Prevent SSRF security issue #3755
-		target.Address = url.Scheme + "://" + url.Host + url.Path
-
-		_, ok := notification.SupportedNotifyTypes[target.Type]
-		if !ok {
-			w.SendBadRequestError(fmt.Errorf("unsupport target type %s with policy %s", target.Type, policy.Name))
+		if err := validateTarget(&target); err != nil {
+			w.SendBadRequestError(fmt.Errorf("%v with policy %s", err, policy.Name))
 			return false
 		}
 	}
@@ -314,6 +411,21 @@ func (w *NotificationPolicyAPI) validateTargets(policy *models.NotificationPolic
 	return true
 }
 
+// validateTarget validates the target and removes the unexpected parts of its address
+func validateTarget(target *models.EventTarget) error {
+	url, err := utils.ParseEndpoint(target.Address)
+	if err != nil {
+		return err
+	}
+	// Prevent SSRF security issue #3755
+	target.Address = url.Scheme + "://" + url.Host + url.Path
+
+	if _, ok := notification.SupportedNotifyTypes[target.Type]; !ok {
+		return fmt.Errorf("unsupport target type %s", target.Type)
+	}
+	return nil
+}
+
 func (w *NotificationPolicyAPI) validateEventTypes(policy *models.NotificationPolicy) bool {
 	if len(policy.EventTypes) == 0 {
 		w.SendBadRequestError(errors.New("empty event type"))
@@ -331,6 +443,20 @@ func (w *NotificationPolicyAPI) validateEventTypes(policy *models.NotificationPo
 	return true
 }
 
+func (w *NotificationPolicyAPI) validateScanSeverityThreshold(policy *models.NotificationPolicy) bool {
+	if len(policy.ScanSeverityThreshold) == 0 {
+		return true
+	}
+
+	switch vuln.Severity(policy.ScanSeverityThreshold) {
+	case vuln.None, vuln.Negligible, vuln.Low, vuln.Medium, vuln.High, vuln.Critical:
+		return true
+	default:
+		w.SendBadRequestError(fmt.Errorf("unsupport scan severity threshold %s", policy.ScanSeverityThreshold))
+		return false
+	}
+}
+
 func getLastTriggerTimeGroupByEventType(eventType string, policyID int64) (time.Time, error) {
 	jobs, err := notification.JobMgr.ListJobsGroupByEventType(policyID)
 	if err != nil {
@@ -8,8 +8,13 @@ import (
 
 	"github.com/goharbor/harbor/src/pkg/notification/model"
 
+	jobModels "github.com/goharbor/harbor/src/common/job/models"
 	"github.com/goharbor/harbor/src/common/models"
+	notifierModel "github.com/goharbor/harbor/src/core/notifier/model"
 	"github.com/goharbor/harbor/src/pkg/notification"
+	"github.com/goharbor/harbor/src/pkg/notification/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type fakedNotificationPlyMgr struct {
@@ -519,6 +524,130 @@ func TestNotificationPolicyAPI_Test(t *testing.T) {
 	runCodeCheckingCases(t, cases...)
 }
 
+type fakedHookManager struct {
+	result *hook.DeliveryResult
+	err    error
+}
+
+func (f *fakedHookManager) StartHook(event *notifierModel.HookEvent, data *jobModels.JobData) error {
+	return nil
+}
+
+func (f *fakedHookManager) DeliverTest(event *notifierModel.HookEvent) (*hook.DeliveryResult, error) {
+	return f.result, f.err
+}
+
+func TestNotificationPolicyAPI_TestDelivery(t *testing.T) {
+	hookMgr := notification.HookManager
+	defer func() {
+		notification.HookManager = hookMgr
+	}()
+	fakedMgr := &fakedHookManager{}
+	notification.HookManager = fakedMgr
+
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/projects/1/webhooks/test",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/webhooks/test",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/123/webhooks/test",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 400 unsupported target type
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/webhooks/test",
+				credential: sysAdmin,
+				bodyJSON: &models.EventTarget{
+					Type:    "email",
+					Address: "http://8.8.8.8:8080/",
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400 loopback address
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/webhooks/test",
+				credential: sysAdmin,
+				bodyJSON: &models.EventTarget{
+					Type:    "http",
+					Address: "http://127.0.0.1:8080/",
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400 link-local address
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/webhooks/test",
+				credential: sysAdmin,
+				bodyJSON: &models.EventTarget{
+					Type:    "http",
+					Address: "http://169.254.169.254/latest/meta-data/",
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	target := &models.EventTarget{
+		Type:    "http",
+		Address: "http://8.8.8.8:8080/",
+	}
+	for _, c := range []*hook.DeliveryResult{
+		{Delivered: true, StatusCode: http.StatusOK, DurationMS: 10},
+		{Delivered: false, StatusCode: http.StatusNotFound, DurationMS: 10},
+		{Delivered: false, StatusCode: 0, DurationMS: 10},
+	} {
+		fakedMgr.result, fakedMgr.err = c, nil
+		result := &hook.DeliveryResult{}
+		err := handleAndParse(&testingRequest{
+			method:     http.MethodPost,
+			url:        "/api/projects/1/webhooks/test",
+			credential: sysAdmin,
+			bodyJSON:   target,
+		}, result)
+		require.Nil(t, err)
+		assert.Equal(t, c, result)
+	}
+
+	// 503 the result isn't reported in time
+	fakedMgr.result, fakedMgr.err = nil, hook.ErrDeliveryTimeout
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPost,
+			url:        "/api/projects/1/webhooks/test",
+			credential: sysAdmin,
+			bodyJSON:   target,
+		},
+		code: http.StatusServiceUnavailable,
+	})
+}
+
 func TestNotificationPolicyAPI_ListGroupByEventType(t *testing.T) {
 	policyCtl := notification.PolicyMgr
 	jobMgr := notification.JobMgr
@@ -570,6 +699,66 @@ func TestNotificationPolicyAPI_ListGroupByEventType(t *testing.T) {
 	runCodeCheckingCases(t, cases...)
 }
 
+func TestNotificationPolicyAPI_Stats(t *testing.T) {
+	policyCtl := notification.PolicyMgr
+	jobMgr := notification.JobMgr
+	defer func() {
+		notification.PolicyMgr = policyCtl
+		notification.JobMgr = jobMgr
+	}()
+
+	notification.PolicyMgr = &fakedNotificationPlyMgr{}
+	notification.JobMgr = &fakedNotificationJobMgr{}
+
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/projects/1/webhooks/1/stats",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/webhooks/1/stats",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/webhooks/1234/stats",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 400 projectID not match with projectID in URL
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/webhooks/2/stats",
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/webhooks/1/stats",
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+}
+
 func TestNotificationPolicyAPI_Delete(t *testing.T) {
 	policyCtl := notification.PolicyMgr
 	defer func() {
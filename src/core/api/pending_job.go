@@ -0,0 +1,192 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	common_http "github.com/goharbor/harbor/src/common/http"
+	common_job "github.com/goharbor/harbor/src/common/job"
+	common_models "github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/api/models"
+	utils_core "github.com/goharbor/harbor/src/core/utils"
+)
+
+// the interval to re-submit the admin jobs pending submission
+const pendingSubmissionRetryInterval = 30 * time.Second
+
+var (
+	// the job service client, it's a variable for testing
+	getJobServiceClient = utils_core.GetJobServiceClient
+	// the parameters read from the environment when submitting the admin jobs, they are
+	// not persisted as they may contain credentials, keyed by the job name
+	envJobParameters = map[string]map[string]string{
+		common_job.ImageGC: {"redis_url_reg": "_REDIS_URL_REG"},
+	}
+)
+
+// PendingJobQueue re-submits the admin jobs which failed to be submitted as the job service
+// was unavailable, the pending jobs are kept in the admin_job table
+type PendingJobQueue struct {
+	interval time.Duration
+}
+
+// NewPendingJobQueue returns an instance of PendingJobQueue
+func NewPendingJobQueue() *PendingJobQueue {
+	return &PendingJobQueue{
+		interval: pendingSubmissionRetryInterval,
+	}
+}
+
+// Start re-submits the pending jobs periodically in background until the stop channel is closed
+func (p *PendingJobQueue) Start(stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := RetryPendingSubmissions(); err != nil {
+					log.Warningf("failed to re-submit the pending admin jobs: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// RetryPendingSubmissions re-submits the admin jobs pending submission, it stops at the first
+// job which fails again as the job service is still unavailable
+func RetryPendingSubmissions() error {
+	jobs, err := dao.GetPendingSubmissionJobs()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := resubmit(job); err != nil {
+			if jobServiceUnavailable(err) {
+				return err
+			}
+			log.Errorf("failed to re-submit admin job %d: %v", job.ID, err)
+		}
+	}
+	return nil
+}
+
+// resubmit submits the pending job, the job is claimed before the submission
+// to avoid submitting it more than once when multiple core instances are running
+func resubmit(job *common_models.AdminJob) error {
+	ajr, err := toAdminJobReq(job)
+	if err != nil {
+		// the job can never be submitted, give it up
+		if e := dao.UpdateAdminJobStatus(job.ID, common_models.JobError); e != nil {
+			log.Errorf("failed to update the status of admin job %d: %v", job.ID, e)
+		}
+		return err
+	}
+
+	claimed, err := dao.ClaimPendingSubmissionJob(job.ID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		log.Debugf("admin job %d has been claimed by others, skip", job.ID)
+		return nil
+	}
+
+	uuid, err := getJobServiceClient().SubmitJob(ajr.ToJob())
+	if err != nil {
+		status := common_models.JobError
+		if jobServiceUnavailable(err) {
+			status = common_models.JobPendingSubmission
+		}
+		if e := dao.UpdateAdminJobStatus(job.ID, status); e != nil {
+			log.Errorf("failed to update the status of admin job %d: %v", job.ID, e)
+		}
+		return err
+	}
+	log.Infof("admin job %d is re-submitted to the job service", job.ID)
+	return dao.SetAdminJobUUID(job.ID, uuid)
+}
+
+// toAdminJobReq rebuilds the request from the persisted admin job
+func toAdminJobReq(job *common_models.AdminJob) (*models.AdminJobReq, error) {
+	ajr := &models.AdminJobReq{
+		Name: job.Name,
+		ID:   job.ID,
+	}
+	ajr.ScheduleName = job.ScheduleName
+	schedule := &models.ScheduleParam{}
+	if err := json.Unmarshal([]byte(job.Cron), schedule); err != nil {
+		return nil, err
+	}
+	ajr.Schedule = schedule
+	if len(job.Parameters) > 0 {
+		if err := json.Unmarshal([]byte(job.Parameters), &ajr.Parameters); err != nil {
+			return nil, err
+		}
+	}
+	addEnvParameters(ajr)
+	return ajr, nil
+}
+
+// addEnvParameters adds the parameters read from the environment into the request
+func addEnvParameters(ajr *models.AdminJobReq) {
+	for key, env := range envJobParameters[ajr.Name] {
+		if ajr.Parameters == nil {
+			ajr.Parameters = map[string]interface{}{}
+		}
+		ajr.Parameters[key] = os.Getenv(env)
+	}
+}
+
+// persistedParameters returns the parameters of the request to be persisted, the
+// parameters read from the environment are excluded
+func persistedParameters(ajr *models.AdminJobReq) (string, error) {
+	params := map[string]interface{}{}
+	for key, value := range ajr.Parameters {
+		if _, exist := envJobParameters[ajr.Name][key]; exist {
+			continue
+		}
+		params[key] = value
+	}
+	if len(params) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// jobServiceUnavailable checks whether the error is caused by the unavailability of the job service
+func jobServiceUnavailable(err error) bool {
+	switch e := err.(type) {
+	case *common_http.Error:
+		return e.Code >= http.StatusInternalServerError
+	case *url.Error, net.Error:
+		return true
+	}
+	return false
+}
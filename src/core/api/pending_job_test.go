@@ -0,0 +1,109 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	common_http "github.com/goharbor/harbor/src/common/http"
+	common_job "github.com/goharbor/harbor/src/common/job"
+	job_models "github.com/goharbor/harbor/src/common/job/models"
+	common_models "github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/api/models"
+	"github.com/goharbor/harbor/src/testing/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unavailableJobClient fails the submissions until the job service recovers
+type unavailableJobClient struct {
+	job.MockJobClient
+	unavailable bool
+	submitted   []*job_models.JobData
+}
+
+func (u *unavailableJobClient) SubmitJob(data *job_models.JobData) (string, error) {
+	if u.unavailable {
+		return "", &url.Error{Op: "Post", URL: "http://jobservice:8080/api/v1/jobs", Err: errors.New("connection refused")}
+	}
+	u.submitted = append(u.submitted, data)
+	return u.MockJobClient.SubmitJob(data)
+}
+
+func TestRetryPendingSubmissions(t *testing.T) {
+	client := &unavailableJobClient{unavailable: true}
+	old := getJobServiceClient
+	getJobServiceClient = func() common_job.Client { return client }
+	defer func() { getJobServiceClient = old }()
+
+	ajr := &models.AdminJobReq{
+		Name: common_job.ImageGC,
+		AdminJobSchedule: models.AdminJobSchedule{
+			Schedule: &models.ScheduleParam{Type: models.ScheduleManual},
+		},
+		Parameters: map[string]interface{}{
+			"redis_url_reg":       "redis://redis:6379/1",
+			models.GCWorkersParam: 2,
+		},
+	}
+	params, err := persistedParameters(ajr)
+	require.Nil(t, err)
+	// the parameter read from the environment isn't persisted
+	assert.Equal(t, `{"workers":2}`, params)
+
+	id, err := dao.AddAdminJob(&common_models.AdminJob{
+		Name:       ajr.Name,
+		Kind:       ajr.JobKind(),
+		Cron:       ajr.CronString(),
+		Status:     common_models.JobPendingSubmission,
+		Parameters: params,
+	})
+	require.Nil(t, err)
+	defer dao.DeleteAdminJob(id)
+
+	// the job service is still unavailable
+	err = RetryPendingSubmissions()
+	require.NotNil(t, err)
+	aj, err := dao.GetAdminJob(id)
+	require.Nil(t, err)
+	assert.Equal(t, common_models.JobPendingSubmission, aj.Status)
+	assert.Empty(t, aj.UUID)
+
+	// the job is re-submitted after the job service recovers
+	client.unavailable = false
+	require.Nil(t, RetryPendingSubmissions())
+	aj, err = dao.GetAdminJob(id)
+	require.Nil(t, err)
+	assert.Equal(t, common_models.JobPending, aj.Status)
+	assert.NotEmpty(t, aj.UUID)
+	require.Equal(t, 1, len(client.submitted))
+	assert.Equal(t, common_job.JobKindGeneric, client.submitted[0].Metadata.JobKind)
+	assert.Equal(t, float64(2), client.submitted[0].Parameters[models.GCWorkersParam])
+	assert.Contains(t, client.submitted[0].Parameters, "redis_url_reg")
+
+	// nothing is submitted again
+	require.Nil(t, RetryPendingSubmissions())
+	assert.Equal(t, 1, len(client.submitted))
+}
+
+func TestJobServiceUnavailable(t *testing.T) {
+	assert.True(t, jobServiceUnavailable(&url.Error{Op: "Post", Err: errors.New("connection refused")}))
+	assert.True(t, jobServiceUnavailable(&common_http.Error{Code: 503}))
+	assert.False(t, jobServiceUnavailable(&common_http.Error{Code: 400}))
+	assert.False(t, jobServiceUnavailable(errors.New("invalid job")))
+}
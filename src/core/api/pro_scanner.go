@@ -110,3 +110,53 @@ func (sa *ProjectScannerAPI) SetProjectScanner() {
 		return
 	}
 }
+
+// GetScannerAssignments gets the tag pattern based scanner assignments of the project
+func (sa *ProjectScannerAPI) GetScannerAssignments() {
+	// Check access permissions
+	if !sa.RequireProjectAccess(sa.pid, rbac.ActionRead, rbac.ResourceConfiguration) {
+		return
+	}
+
+	assignments, err := sa.c.GetScannerAssignments(sa.pid)
+	if err != nil {
+		sa.SendInternalServerError(errors.Wrap(err, "scanner API: get scanner assignments"))
+		return
+	}
+
+	sa.Data["json"] = assignments
+	sa.ServeJSON()
+}
+
+// SetScannerAssignments sets the tag pattern based scanner assignments of the project
+func (sa *ProjectScannerAPI) SetScannerAssignments() {
+	// Check access permissions
+	if !sa.RequireProjectAccess(sa.pid, rbac.ActionUpdate, rbac.ResourceConfiguration) {
+		return
+	}
+
+	body := struct {
+		ScannerAssignments []scanner.Assignment `json:"scanner_assignments"`
+	}{}
+	if err := sa.DecodeJSONReq(&body); err != nil {
+		sa.SendBadRequestError(errors.Wrap(err, "scanner API: set scanner assignments"))
+		return
+	}
+
+	for _, a := range body.ScannerAssignments {
+		if err := a.Validate(); err != nil {
+			sa.SendBadRequestError(errors.Wrap(err, "scanner API: set scanner assignments"))
+			return
+		}
+
+		if !sa.c.RegistrationExists(a.ScannerUUID) {
+			sa.SendBadRequestError(errors.Errorf("scanner %s of scanner assignment not found", a.ScannerUUID))
+			return
+		}
+	}
+
+	if err := sa.c.SetScannerAssignments(sa.pid, body.ScannerAssignments); err != nil {
+		sa.SendInternalServerError(errors.Wrap(err, "scanner API: set scanner assignments"))
+		return
+	}
+}
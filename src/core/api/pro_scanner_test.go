@@ -93,3 +93,68 @@ func (suite *ProScannerAPITestSuite) TestScannerAPIProjectScanner() {
 	assert.Equal(suite.T(), r.Name, rr.Name)
 	assert.Equal(suite.T(), r.UUID, rr.UUID)
 }
+
+// TestScannerAPIScannerAssignments tests the API of getting/setting scanner assignments of project
+func (suite *ProScannerAPITestSuite) TestScannerAPIScannerAssignments() {
+	assignments := []sc.Assignment{{TagPattern: "*-alpine", ScannerUUID: "uuid"}}
+	suite.mockC.On("RegistrationExists", "uuid").Return(true)
+	suite.mockC.On("RegistrationExists", "missing").Return(false)
+	suite.mockC.On("SetScannerAssignments", int64(1), assignments).Return(nil)
+	suite.mockC.On("GetScannerAssignments", int64(1)).Return(assignments, nil)
+
+	runCodeCheckingCases(suite.T(),
+		// 401
+		&codeCheckingCase{
+			request: &testingRequest{
+				url:    fmt.Sprintf("/api/projects/%d/scanner/assignments", 1),
+				method: http.MethodGet,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 400 invalid pattern
+		&codeCheckingCase{
+			request: &testingRequest{
+				url:        fmt.Sprintf("/api/projects/%d/scanner/assignments", 1),
+				method:     http.MethodPut,
+				credential: projAdmin,
+				bodyJSON: map[string]interface{}{
+					"scanner_assignments": []sc.Assignment{{TagPattern: "[", ScannerUUID: "uuid"}},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400 scanner not found
+		&codeCheckingCase{
+			request: &testingRequest{
+				url:        fmt.Sprintf("/api/projects/%d/scanner/assignments", 1),
+				method:     http.MethodPut,
+				credential: projAdmin,
+				bodyJSON: map[string]interface{}{
+					"scanner_assignments": []sc.Assignment{{TagPattern: "*", ScannerUUID: "missing"}},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		&codeCheckingCase{
+			request: &testingRequest{
+				url:        fmt.Sprintf("/api/projects/%d/scanner/assignments", 1),
+				method:     http.MethodPut,
+				credential: projAdmin,
+				bodyJSON: map[string]interface{}{
+					"scanner_assignments": assignments,
+				},
+			},
+			code: http.StatusOK,
+		},
+	)
+
+	rr := make([]sc.Assignment, 0)
+	err := handleAndParse(&testingRequest{
+		url:        fmt.Sprintf("/api/projects/%d/scanner/assignments", 1),
+		method:     http.MethodGet,
+		credential: projAdmin,
+	}, &rr)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), assignments, rr)
+}
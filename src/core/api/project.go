@@ -15,6 +15,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -30,8 +31,10 @@ import (
 	"github.com/goharbor/harbor/src/common/rbac"
 	"github.com/goharbor/harbor/src/common/utils"
 	errutil "github.com/goharbor/harbor/src/common/utils/error"
+	"github.com/goharbor/harbor/src/common/utils/jsondiff"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/pkg/retention/policy"
 	"github.com/goharbor/harbor/src/pkg/types"
 	"github.com/pkg/errors"
 )
@@ -514,6 +517,96 @@ func (p *ProjectAPI) Put() {
 			p.project.ProjectID), err)
 		return
 	}
+
+	updated, err := p.ProjectMgr.Get(p.project.ProjectID)
+	if err != nil {
+		log.Errorf("failed to get project %d after updating, the configuration change isn't recorded: %v",
+			p.project.ProjectID, err)
+		return
+	}
+	if updated == nil {
+		return
+	}
+	if err = recordProjectConfigChange(p.project.ProjectID, p.SecurityCtx.GetUsername(),
+		newProjectConfig(p.project), newProjectConfig(updated)); err != nil {
+		log.Errorf("failed to record the configuration change of project %d: %v", p.project.ProjectID, err)
+	}
+}
+
+// projectConfig is the part of project recorded in the configuration history, the
+// quota and retention policy are only populated by the APIs updating them
+type projectConfig struct {
+	Metadata     map[string]string         `json:"metadata"`
+	CVEWhitelist []models.CVEWhitelistItem `json:"cve_whitelist"`
+	ExpiresAt    *int64                    `json:"cve_whitelist_expires_at"`
+	Quota        types.ResourceList        `json:"quota,omitempty"`
+	Retention    *policy.Metadata          `json:"retention,omitempty"`
+}
+
+func newProjectConfig(project *models.Project) *projectConfig {
+	return &projectConfig{
+		Metadata:     project.Metadata,
+		CVEWhitelist: project.CVEWhitelist.Items,
+		ExpiresAt:    project.CVEWhitelist.ExpiresAt,
+	}
+}
+
+// recordProjectConfigChange inserts a history record if the configuration of the project is changed
+func recordProjectConfigChange(projectID int64, changedBy string, old, updated *projectConfig) error {
+	patch, err := jsondiff.Compare(old, updated)
+	if err != nil {
+		return err
+	}
+	if len(patch) == 0 {
+		return nil
+	}
+	diff, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	return dao.InsertProjectConfigHistory(&models.ProjectConfigChange{
+		ProjectID: projectID,
+		ChangedBy: changedBy,
+		Diff:      diff,
+	})
+}
+
+// ConfigHistory returns the history of the configuration changes of the project
+func (p *ProjectAPI) ConfigHistory() {
+	if !p.requireAccess(rbac.ActionRead, rbac.ResourceConfiguration) {
+		return
+	}
+
+	page, size, err := p.GetPaginationParams()
+	if err != nil {
+		p.SendBadRequestError(err)
+		return
+	}
+	query := &models.ProjectConfigChangeQuery{
+		ProjectID: p.project.ProjectID,
+		Pagination: models.Pagination{
+			Page: page,
+			Size: size,
+		},
+	}
+
+	total, err := dao.GetTotalOfProjectConfigHistory(query)
+	if err != nil {
+		p.SendInternalServerError(fmt.Errorf(
+			"failed to get total of configuration history: %v", err))
+		return
+	}
+
+	changes, err := dao.GetProjectConfigHistory(query)
+	if err != nil {
+		p.SendInternalServerError(fmt.Errorf(
+			"failed to get configuration history: %v", err))
+		return
+	}
+
+	p.SetPaginationHeader(total, page, size)
+	p.Data["json"] = changes
+	p.ServeJSON()
 }
 
 // Logs ...
@@ -0,0 +1,37 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/pkg/risk"
+)
+
+// RiskScore returns the composite security score of the project computed from the scan coverage,
+// the density of the critical CVEs, the unsigned images and the security policies of the project
+func (p *ProjectAPI) RiskScore() {
+	if !p.requireAccess(rbac.ActionRead, rbac.ResourceScan) {
+		return
+	}
+
+	score, err := risk.ComputeScore(p.project.ProjectID)
+	if err != nil {
+		p.SendInternalServerError(fmt.Errorf("failed to compute the risk score of project %d: %v", p.project.ProjectID, err))
+		return
+	}
+	p.WriteJSONData(score)
+}
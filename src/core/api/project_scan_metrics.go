@@ -0,0 +1,55 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	beego_cache "github.com/astaxie/beego/cache"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scan"
+)
+
+// scanMetricsCacheTTL is how long the scan metrics of a project are cached as
+// computing the percentiles walks through all the scan reports of the project
+const scanMetricsCacheTTL = 10 * time.Minute
+
+var scanMetricsCache = beego_cache.NewMemoryCache()
+
+// ScanMetrics returns the distribution of the scan durations of the project grouped by the scanner
+func (p *ProjectAPI) ScanMetrics() {
+	if !p.requireAccess(rbac.ActionRead, rbac.ResourceScan) {
+		return
+	}
+
+	key := strconv.FormatInt(p.project.ProjectID, 10)
+	if stats, ok := scanMetricsCache.Get(key).([]*scan.ScanDurationStats); ok {
+		p.WriteJSONData(stats)
+		return
+	}
+
+	stats, err := scan.GetScanDurationStats(p.project.ProjectID)
+	if err != nil {
+		p.SendInternalServerError(fmt.Errorf("failed to get the scan metrics of project %d: %v", p.project.ProjectID, err))
+		return
+	}
+	if err = scanMetricsCache.Put(key, stats, scanMetricsCacheTTL); err != nil {
+		log.Warningf("failed to cache the scan metrics of project %d: %v", p.project.ProjectID, err)
+	}
+	p.WriteJSONData(stats)
+}
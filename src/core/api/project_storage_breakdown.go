@@ -0,0 +1,84 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	beego_cache "github.com/astaxie/beego/cache"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+// storageBreakdownCacheTTL is how long the storage breakdown of a project is cached
+const storageBreakdownCacheTTL = 5 * time.Minute
+
+var storageBreakdownCache = beego_cache.NewMemoryCache()
+
+// StorageBreakdown returns the storage of the project broken down by the artifact type
+func (p *ProjectAPI) StorageBreakdown() {
+	// the storage is only visible to the developer and above
+	if !p.requireAccess(rbac.ActionPush, rbac.ResourceRepository) {
+		return
+	}
+
+	key := strconv.FormatInt(p.project.ProjectID, 10)
+	if breakdown, ok := storageBreakdownCache.Get(key).(*models.StorageBreakdown); ok {
+		p.WriteJSONData(breakdown)
+		return
+	}
+
+	breakdown, err := getStorageBreakdown(p.project.ProjectID)
+	if err != nil {
+		p.SendInternalServerError(fmt.Errorf("failed to get the storage breakdown of project %d: %v", p.project.ProjectID, err))
+		return
+	}
+	if err = storageBreakdownCache.Put(key, breakdown, storageBreakdownCacheTTL); err != nil {
+		log.Warningf("failed to cache the storage breakdown of project %d: %v", p.project.ProjectID, err)
+	}
+	p.WriteJSONData(breakdown)
+}
+
+// getStorageBreakdown gets the storage by the artifact type and calculates the percentages
+// against the total usage including the untagged storage
+func getStorageBreakdown(projectID int64) (*models.StorageBreakdown, error) {
+	storages, err := dao.GetStorageByArtifactType(projectID)
+	if err != nil {
+		return nil, err
+	}
+	untagged, err := dao.GetUntaggedStorage(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	total := untagged
+	for _, storage := range storages {
+		total += storage.SizeBytes
+	}
+	if total > 0 {
+		for _, storage := range storages {
+			storage.Percentage = math.Round(float64(storage.SizeBytes)/float64(total)*100*100) / 100
+		}
+	}
+	return &models.StorageBreakdown{
+		StorageByType:   storages,
+		UntaggedStorage: untagged,
+	}, nil
+}
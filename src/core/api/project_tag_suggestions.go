@@ -0,0 +1,115 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/rbac"
+)
+
+const (
+	// the max number of the tags suggested
+	maxTagSuggestions = 10
+	// tagSuggestionsCacheTTL is how long the tag suggestions are cached, it's short
+	// as the suggestions are requested while typing
+	tagSuggestionsCacheTTL = 30 * time.Second
+)
+
+var tagSuggestions = &tagSuggestionsCache{
+	ttl:         tagSuggestionsCacheTTL,
+	lastCleanup: time.Now(),
+}
+
+type tagSuggestionsEntry struct {
+	tags      []string
+	expiresAt time.Time
+}
+
+// tagSuggestionsCache caches the tag suggestions keyed by the repository and the prefix
+type tagSuggestionsCache struct {
+	entries     sync.Map
+	ttl         time.Duration
+	lock        sync.Mutex
+	lastCleanup time.Time
+}
+
+func (c *tagSuggestionsCache) get(repository, prefix string) ([]string, bool) {
+	c.cleanup()
+	v, ok := c.entries.Load(tagSuggestionsKey(repository, prefix))
+	if !ok {
+		return nil, false
+	}
+	entry := v.(*tagSuggestionsEntry)
+	if time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.tags, true
+}
+
+func (c *tagSuggestionsCache) put(repository, prefix string, tags []string) {
+	c.entries.Store(tagSuggestionsKey(repository, prefix), &tagSuggestionsEntry{
+		tags:      tags,
+		expiresAt: time.Now().Add(c.ttl),
+	})
+}
+
+// cleanup removes the expired entries, it runs at most once in a TTL
+func (c *tagSuggestionsCache) cleanup() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	if now.Sub(c.lastCleanup) < c.ttl {
+		return
+	}
+	c.lastCleanup = now
+	c.entries.Range(func(k, v interface{}) bool {
+		if now.After(v.(*tagSuggestionsEntry).expiresAt) {
+			c.entries.Delete(k)
+		}
+		return true
+	})
+}
+
+func tagSuggestionsKey(repository, prefix string) string {
+	return repository + ":" + prefix
+}
+
+// TagSuggestions returns the latest pushed tags starting with the prefix in the repository to help autocomplete,
+// the repository is specified by the name without the project name
+func (p *ProjectAPI) TagSuggestions() {
+	if !p.requireAccess(rbac.ActionList, rbac.ResourceRepositoryTag) {
+		return
+	}
+
+	repository := fmt.Sprintf("%s/%s", p.project.Name, strings.Trim(p.GetString(":splat"), "/"))
+	prefix := p.GetString("prefix")
+	if tags, ok := tagSuggestions.get(repository, prefix); ok {
+		p.WriteJSONData(tags)
+		return
+	}
+
+	tags, err := dao.GetTagSuggestions(p.project.ProjectID, repository, prefix, maxTagSuggestions)
+	if err != nil {
+		p.SendInternalServerError(fmt.Errorf("failed to get the tag suggestions of repository %s: %v", repository, err))
+		return
+	}
+	tagSuggestions.put(repository, prefix, tags)
+	p.WriteJSONData(tags)
+}
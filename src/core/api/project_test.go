@@ -14,14 +14,21 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/quota"
+	"github.com/goharbor/harbor/src/common/utils/jsondiff"
+	"github.com/goharbor/harbor/src/pkg/risk"
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scan"
+	"github.com/goharbor/harbor/src/pkg/types"
 	"github.com/goharbor/harbor/src/testing/apitests/apilib"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -409,6 +416,335 @@ func TestPut(t *testing.T) {
 
 	fmt.Printf("\n")
 }
+func TestProjectConfigHistory(t *testing.T) {
+	apiTest := newHarborAPI()
+	projectID, err := addProjectByName(apiTest, "project-config-history")
+	require.Nil(t, err)
+	defer deleteProjectByIDs(apiTest, projectID)
+
+	code, err := apiTest.ProjectsPut(*admin, fmt.Sprintf("%d", projectID), &models.Project{
+		Metadata: map[string]string{
+			models.ProMetaPublic:   "true",
+			models.ProMetaAutoScan: "true",
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, code)
+
+	url := fmt.Sprintf("/api/projects/%d/config-history", projectID)
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 400
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    url,
+				queryStruct: struct {
+					Page int64 `url:"page"`
+				}{
+					Page: -1,
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        url,
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	changes := []*models.ProjectConfigChange{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url,
+		credential: sysAdmin,
+	}, &changes)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(changes))
+	assert.Equal(t, "admin", changes[0].ChangedBy)
+	assert.JSONEq(t, `[
+		{"op":"add","path":"/metadata/auto_scan","value":"true"},
+		{"op":"test","path":"/metadata/public","value":"false"},
+		{"op":"replace","path":"/metadata/public","value":"true"}
+	]`, string(changes[0].Diff))
+
+	// the update of the quota is recorded as well
+	ref := strconv.FormatInt(int64(projectID), 10)
+	quotaQuery := &models.QuotaQuery{Reference: "project", ReferenceID: ref}
+	quotas, err := dao.ListQuotas(quotaQuery)
+	require.Nil(t, err)
+	if len(quotas) == 0 {
+		mgr, err := quota.NewManager("project", ref)
+		require.Nil(t, err)
+		_, err = mgr.NewQuota(types.ResourceList{types.ResourceCount: -1, types.ResourceStorage: -1})
+		require.Nil(t, err)
+		quotas, err = dao.ListQuotas(quotaQuery)
+		require.Nil(t, err)
+	}
+	require.Equal(t, 1, len(quotas))
+	code, err = apiTest.QuotasPut(*admin, fmt.Sprintf("%d", quotas[0].ID), models.QuotaUpdateRequest{
+		Hard: types.ResourceList{types.ResourceCount: 100, types.ResourceStorage: 100},
+	})
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, code)
+
+	changes = []*models.ProjectConfigChange{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url,
+		credential: sysAdmin,
+	}, &changes)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(changes))
+	patch := jsondiff.Patch{}
+	require.Nil(t, json.Unmarshal(changes[0].Diff, &patch))
+	assert.Contains(t, patch, jsondiff.Operation{
+		Type:  jsondiff.OperationReplace,
+		Path:  "/quota/count",
+		Value: float64(100),
+	})
+	assert.Contains(t, patch, jsondiff.Operation{
+		Type:  jsondiff.OperationReplace,
+		Path:  "/quota/storage",
+		Value: float64(100),
+	})
+}
+
+func TestProjectScanMetrics(t *testing.T) {
+	apiTest := newHarborAPI()
+	projectID, err := addProjectByName(apiTest, "project-scan-metrics")
+	require.Nil(t, err)
+	defer deleteProjectByIDs(apiTest, projectID)
+
+	url := fmt.Sprintf("/api/projects/%d/scan-metrics", projectID)
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1000000/scan-metrics",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	stats := []*scan.ScanDurationStats{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url,
+		credential: sysAdmin,
+	}, &stats)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(stats))
+
+	// the metrics are served from the cache
+	key := fmt.Sprintf("%d", projectID)
+	defer scanMetricsCache.Delete(key)
+	require.Nil(t, scanMetricsCache.Put(key, []*scan.ScanDurationStats{
+		{
+			RegistrationUUID: "uuid",
+			ScannerName:      "scanner",
+			TotalScans:       1,
+		},
+	}, scanMetricsCacheTTL))
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url,
+		credential: sysAdmin,
+	}, &stats)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(stats))
+	assert.Equal(t, "scanner", stats[0].ScannerName)
+}
+
+func TestProjectStorageBreakdown(t *testing.T) {
+	defer storageBreakdownCache.Delete("1")
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/projects/1/storage-breakdown",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1000000/storage-breakdown",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 403, guest
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/storage-breakdown",
+				credential: projGuest,
+			},
+			code: http.StatusForbidden,
+		},
+		// 200, developer
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/storage-breakdown",
+				credential: projDeveloper,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	// the breakdown is served from the cache
+	require.Nil(t, storageBreakdownCache.Put("1", &models.StorageBreakdown{
+		StorageByType: []*models.ArtifactTypeStorage{
+			{
+				ArtifactType:  "image",
+				SizeBytes:     100,
+				ArtifactCount: 1,
+				Percentage:    100,
+			},
+		},
+	}, storageBreakdownCacheTTL))
+	breakdown := &models.StorageBreakdown{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/projects/1/storage-breakdown",
+		credential: sysAdmin,
+	}, breakdown)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(breakdown.StorageByType))
+	assert.Equal(t, "image", breakdown.StorageByType[0].ArtifactType)
+	assert.Equal(t, int64(100), breakdown.StorageByType[0].SizeBytes)
+}
+
+func TestProjectTagSuggestions(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/projects/1/repositories/hello-world/tag-suggestions",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1000000/repositories/hello-world/tag-suggestions",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	id, err := dao.AddArtifact(&models.Artifact{
+		PID:    1,
+		Repo:   "library/tag-suggestions",
+		Tag:    "v1.0",
+		Digest: "TestProjectTagSuggestions",
+		Kind:   "image",
+	})
+	require.Nil(t, err)
+	defer dao.DeleteArtifact(id)
+
+	tags := []string{}
+	err = handleAndParse(&testingRequest{
+		method: http.MethodGet,
+		url:    "/api/projects/1/repositories/tag-suggestions/tag-suggestions",
+		queryStruct: struct {
+			Prefix string `url:"prefix"`
+		}{
+			Prefix: "v1.",
+		},
+		credential: projGuest,
+	}, &tags)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"v1.0"}, tags)
+
+	// an empty array is returned when no tag matches
+	resp, err := handle(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/projects/1/repositories/tag-suggestions/tag-suggestions?prefix=v2",
+		credential: projGuest,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "[]", strings.TrimSpace(resp.Body.String()))
+}
+
+func TestProjectRiskScore(t *testing.T) {
+	apiTest := newHarborAPI()
+	projectID, err := addProjectByName(apiTest, "project-risk-score")
+	require.Nil(t, err)
+	defer deleteProjectByIDs(apiTest, projectID)
+
+	url := fmt.Sprintf("/api/projects/%d/risk-score", projectID)
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1000000/risk-score",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	// no image in the project and none of the security policies is enabled
+	score := &risk.Score{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url,
+		credential: sysAdmin,
+	}, score)
+	require.Nil(t, err)
+	assert.Equal(t, int64(projectID), score.ProjectID)
+	assert.Equal(t, 0, score.TotalImages)
+	assert.Equal(t, 100.0, score.ScanCoveragePercent)
+	assert.Equal(t, 0.0, score.PolicyComplianceScore)
+	assert.Equal(t, 25.0, score.Score)
+}
+
 func TestProjectLogsFilter(t *testing.T) {
 	fmt.Println("\nTest for search access logs filtered by operations and date time ranges..")
 	assert := assert.New(t)
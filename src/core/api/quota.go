@@ -16,10 +16,13 @@ package api
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/quota"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/pkg/types"
 	"github.com/pkg/errors"
 )
 
@@ -115,6 +118,28 @@ func (qa *QuotaAPI) Put() {
 		qa.SendInternalServerError(fmt.Errorf("failed to update hard limits of the quota, error: %v", err))
 		return
 	}
+
+	if err := qa.recordConfigChange(req.Hard); err != nil {
+		log.Errorf("failed to record the change of quota %d: %v", qa.quota.ID, err)
+	}
+}
+
+// recordConfigChange records the change of the hard limits in the configuration history
+// of the project, the quotas of other references are ignored
+func (qa *QuotaAPI) recordConfigChange(hard types.ResourceList) error {
+	if qa.quota.Reference != "project" {
+		return nil
+	}
+	projectID, err := strconv.ParseInt(qa.quota.ReferenceID, 10, 64)
+	if err != nil {
+		return err
+	}
+	old, err := types.NewResourceList(qa.quota.Hard)
+	if err != nil {
+		return err
+	}
+	return recordProjectConfigChange(projectID, qa.SecurityCtx.GetUsername(),
+		&projectConfig{Quota: old}, &projectConfig{Quota: hard})
 }
 
 // List returns quotas by query
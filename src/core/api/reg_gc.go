@@ -17,7 +17,6 @@ package api
 import (
 	"errors"
 	"net/http"
-	"os"
 	"strconv"
 
 	common_job "github.com/goharbor/harbor/src/common/job"
@@ -50,10 +49,13 @@ func (gc *GCAPI) Prepare() {
 //    "cron": "0 0 0 * * *"
 //  }
 //	}
-// create a manual trigger for GC
+// create a manual trigger for GC with 8 workers deleting the blobs concurrently
 // 	{
 //  "schedule": {
 //    "type": "Manual"
+//  },
+//  "parameters": {
+//    "workers": 8
 //  }
 //	}
 func (gc *GCAPI) Post() {
@@ -64,10 +66,12 @@ func (gc *GCAPI) Post() {
 		return
 	}
 	ajr.Name = common_job.ImageGC
-	ajr.Parameters = map[string]interface{}{
-		"redis_url_reg": os.Getenv("_REDIS_URL_REG"),
+	if !gc.prepareParameters(&ajr) {
+		return
+	}
+	if !gc.create(&ajr) {
+		return
 	}
-	gc.submit(&ajr)
 	gc.Redirect(http.StatusCreated, strconv.FormatInt(ajr.ID, 10))
 }
 
@@ -87,12 +91,34 @@ func (gc *GCAPI) Put() {
 		return
 	}
 	ajr.Name = common_job.ImageGC
-	ajr.Parameters = map[string]interface{}{
-		"redis_url_reg": os.Getenv("_REDIS_URL_REG"),
+	if !gc.prepareParameters(&ajr) {
+		return
 	}
 	gc.updateSchedule(ajr)
 }
 
+// Patch updates the cron of the GC schedule without recreating the job.
+// Request: update the cron of the GC schedule
+// 	{
+//  "schedule": {
+//    "type": "Custom",
+//    "cron": "0 0 1 * * *"
+//  }
+//	}
+func (gc *GCAPI) Patch() {
+	ajr := models.AdminJobReq{}
+	isValid, err := gc.DecodeJSONReqAndValidate(&ajr)
+	if !isValid {
+		gc.SendBadRequestError(err)
+		return
+	}
+	ajr.Name = common_job.ImageGC
+	if !gc.prepareParameters(&ajr) {
+		return
+	}
+	gc.patchSchedule(ajr)
+}
+
 // GetGC ...
 func (gc *GCAPI) GetGC() {
 	id, err := gc.GetInt64FromPath(":id")
@@ -103,14 +129,15 @@ func (gc *GCAPI) GetGC() {
 	gc.get(id)
 }
 
-// List returns the top 10 executions of GC which includes manual and cron.
+// List returns the executions of GC which includes manual and cron, the latest 10 ones are
+// returned by default and they can be paged and filtered by the status.
 func (gc *GCAPI) List() {
 	gc.list(common_job.ImageGC)
 }
 
 // Get gets GC schedule ...
 func (gc *GCAPI) Get() {
-	gc.getSchedule(common_job.ImageGC)
+	gc.getSchedule(common_job.ImageGC, "")
 }
 
 // GetLog ...
@@ -122,3 +149,13 @@ func (gc *GCAPI) GetLog() {
 	}
 	gc.getLog(id)
 }
+
+// Stop stops the running GC execution by ID.
+func (gc *GCAPI) Stop() {
+	id, err := gc.GetInt64FromPath(":id")
+	if err != nil {
+		gc.SendBadRequestError(errors.New("invalid ID"))
+		return
+	}
+	gc.stop(id)
+}
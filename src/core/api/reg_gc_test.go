@@ -1,10 +1,24 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	common_job "github.com/goharbor/harbor/src/common/job"
+	common_models "github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/api/models"
+	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/testing/apitests/apilib"
+	"github.com/goharbor/harbor/src/testing/job"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var adminJob001 apilib.AdminJobReq
@@ -24,6 +38,107 @@ func TestGCPost(t *testing.T) {
 	}
 }
 
+func TestGCPostMaxConcurrent(t *testing.T) {
+	// the GC jobs submitted by the other cases are never run by the testing job service,
+	// finish them to leave the slot to the running one below
+	jobs, err := dao.GetAdminJobs(&common_models.AdminJobQuery{
+		Name: common_job.ImageGC,
+		Kind: common_job.JobKindGeneric,
+		Statuses: []string{common_models.JobPending, common_models.JobPendingSubmission,
+			common_models.JobRunning, common_models.JobRetrying},
+	})
+	require.Nil(t, err)
+	for _, job := range jobs {
+		require.Nil(t, dao.UpdateAdminJobStatus(job.ID, common_models.JobFinished))
+	}
+
+	id, err := dao.AddAdminJob(&common_models.AdminJob{
+		Name: common_job.ImageGC,
+		Kind: common_job.JobKindGeneric,
+	})
+	require.Nil(t, err)
+	defer dao.DeleteAdminJob(id)
+	require.Nil(t, dao.UpdateAdminJobStatus(id, common_models.JobRunning))
+
+	req := &testingRequest{
+		method:     http.MethodPost,
+		url:        "/api/system/gc/schedule",
+		credential: sysAdmin,
+		bodyJSON: &models.AdminJobReq{
+			AdminJobSchedule: models.AdminJobSchedule{
+				Schedule: &models.ScheduleParam{
+					Type: models.ScheduleManual,
+				},
+			},
+		},
+	}
+	// only one GC job is allowed to run
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: req,
+		code:    http.StatusTooManyRequests,
+	})
+
+	// the slot is freed after the running job completes
+	require.Nil(t, dao.UpdateAdminJobStatus(id, common_models.JobFinished))
+	resp, err := handle(req)
+	require.Nil(t, err)
+	assert.NotEqual(t, http.StatusTooManyRequests, resp.Code)
+
+	// the submitted job takes the slot until it completes
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: req,
+		code:    http.StatusTooManyRequests,
+	})
+}
+
+func TestGCPostInvalidWorkers(t *testing.T) {
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPost,
+			url:        "/api/system/gc/schedule",
+			credential: sysAdmin,
+			bodyJSON: &models.AdminJobReq{
+				AdminJobSchedule: models.AdminJobSchedule{
+					Schedule: &models.ScheduleParam{
+						Type: models.ScheduleManual,
+					},
+				},
+				Parameters: map[string]interface{}{
+					models.GCWorkersParam: models.MaxGCWorkers + 1,
+				},
+			},
+		},
+		code: http.StatusBadRequest,
+	})
+}
+
+func TestGCPostInvalidParameters(t *testing.T) {
+	for _, params := range []map[string]interface{}{
+		{"delete_untaged": true},
+		{models.GCDeleteUntaggedParam: "ture"},
+		{models.GCReadOnlyParam: 1},
+		{models.GCDryRunParam: "true"},
+		{"redis_url_reg": "redis://evil:6379/1"},
+	} {
+		runCodeCheckingCases(t, &codeCheckingCase{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/system/gc/schedule",
+				credential: sysAdmin,
+				bodyJSON: &models.AdminJobReq{
+					AdminJobSchedule: models.AdminJobSchedule{
+						Schedule: &models.ScheduleParam{
+							Type: models.ScheduleManual,
+						},
+					},
+					Parameters: params,
+				},
+			},
+			code: http.StatusBadRequest,
+		})
+	}
+}
+
 func TestGCGet(t *testing.T) {
 	assert := assert.New(t)
 	apiTest := newHarborAPI()
@@ -36,3 +151,632 @@ func TestGCGet(t *testing.T) {
 		assert.Equal(200, code, "Get adminjob status should be 200")
 	}
 }
+
+func TestGCPatch(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPatch,
+				url:    "/api/system/gc/schedule",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPatch,
+				url:        "/api/system/gc/schedule",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, invalid cron
+		{
+			request: &testingRequest{
+				method:     http.MethodPatch,
+				url:        "/api/system/gc/schedule",
+				credential: sysAdmin,
+				bodyJSON: &models.AdminJobReq{
+					AdminJobSchedule: models.AdminJobSchedule{
+						Schedule: &models.ScheduleParam{
+							Type: models.ScheduleCustom,
+							Cron: "invalid",
+						},
+					},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, not a periodic schedule
+		{
+			request: &testingRequest{
+				method:     http.MethodPatch,
+				url:        "/api/system/gc/schedule",
+				credential: sysAdmin,
+				bodyJSON: &models.AdminJobReq{
+					AdminJobSchedule: models.AdminJobSchedule{
+						Schedule: &models.ScheduleParam{
+							Type: models.ScheduleManual,
+						},
+					},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+}
+
+func TestGCList(t *testing.T) {
+	ids := []int64{}
+	for i := 0; i < 3; i++ {
+		id, err := dao.AddAdminJob(&common_models.AdminJob{
+			Name: common_job.ImageGC,
+			Kind: common_job.JobKindGeneric,
+		})
+		require.Nil(t, err)
+		require.Nil(t, dao.UpdateAdminJobStatus(id, common_models.JobRetrying))
+		ids = append(ids, id)
+	}
+	defer func() {
+		for _, id := range ids {
+			dao.DeleteAdminJob(id)
+		}
+	}()
+
+	type listQuery struct {
+		Status   string `url:"status"`
+		Page     int64  `url:"page"`
+		PageSize int64  `url:"page_size"`
+	}
+	list := func(query interface{}) (*httptest.ResponseRecorder, []*models.AdminJobRep) {
+		resp, err := handle(&testingRequest{
+			method:      http.MethodGet,
+			url:         "/api/system/gc",
+			credential:  sysAdmin,
+			queryStruct: query,
+		})
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		jobs := []*models.AdminJobRep{}
+		require.Nil(t, json.Unmarshal(resp.Body.Bytes(), &jobs))
+		return resp, jobs
+	}
+
+	// the first page
+	resp, jobs := list(&listQuery{common_models.JobRetrying, 1, 2})
+	assert.Equal(t, "3", resp.Header().Get("X-Total-Count"))
+	assert.Contains(t, resp.Header().Get("Link"), `rel="next"`)
+	require.Equal(t, 2, len(jobs))
+	assert.Equal(t, ids[2], jobs[0].ID)
+
+	// the last page
+	resp, jobs = list(&listQuery{common_models.JobRetrying, 2, 2})
+	assert.Equal(t, "3", resp.Header().Get("X-Total-Count"))
+	assert.Contains(t, resp.Header().Get("Link"), `rel="prev"`)
+	assert.NotContains(t, resp.Header().Get("Link"), `rel="next"`)
+	assert.Equal(t, 1, len(jobs))
+
+	// the empty page
+	resp, jobs = list(&listQuery{common_models.JobRetrying, 3, 2})
+	assert.Equal(t, "3", resp.Header().Get("X-Total-Count"))
+	assert.Equal(t, 0, len(jobs))
+
+	// at most 10 jobs are returned by default
+	_, jobs = list(nil)
+	assert.True(t, len(jobs) <= 10)
+
+	// invalid parameters
+	for _, query := range []interface{}{
+		&struct {
+			Page string `url:"page"`
+		}{"0"},
+		&struct {
+			Page string `url:"page"`
+		}{"a"},
+		&struct {
+			PageSize string `url:"page_size"`
+		}{"0"},
+		&struct {
+			PageSize string `url:"page_size"`
+		}{"101"},
+		&struct {
+			Status string `url:"status"`
+		}{"unknown"},
+	} {
+		runCodeCheckingCases(t, &codeCheckingCase{
+			request: &testingRequest{
+				method:      http.MethodGet,
+				url:         "/api/system/gc",
+				credential:  sysAdmin,
+				queryStruct: query,
+			},
+			code: http.StatusBadRequest,
+		})
+	}
+}
+
+func TestGCListByStatusesAndTimeRange(t *testing.T) {
+	ids := []int64{}
+	for _, status := range []string{common_models.JobError, common_models.JobStopped, common_models.JobFinished} {
+		id, err := dao.AddAdminJob(&common_models.AdminJob{
+			Name: common_job.ImageGC,
+			Kind: common_job.JobKindGeneric,
+		})
+		require.Nil(t, err)
+		require.Nil(t, dao.UpdateAdminJobStatus(id, status))
+		ids = append(ids, id)
+	}
+	defer func() {
+		for _, id := range ids {
+			dao.DeleteAdminJob(id)
+		}
+	}()
+
+	type listQuery struct {
+		Status    []string `url:"status,omitempty"`
+		StartTime string   `url:"start_time,omitempty"`
+		EndTime   string   `url:"end_time,omitempty"`
+	}
+	list := func(query *listQuery) []int64 {
+		jobs := []*models.AdminJobRep{}
+		require.Nil(t, handleAndParse(&testingRequest{
+			method:      http.MethodGet,
+			url:         "/api/system/gc",
+			credential:  sysAdmin,
+			queryStruct: query,
+		}, &jobs))
+		res := []int64{}
+		for _, job := range jobs {
+			res = append(res, job.ID)
+		}
+		return res
+	}
+
+	start := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	// the status can be specified multiple times or separated by comma
+	res := list(&listQuery{Status: []string{common_models.JobError, common_models.JobStopped}, StartTime: start})
+	assert.Subset(t, res, ids[:2])
+	assert.NotContains(t, res, ids[2])
+	res = list(&listQuery{Status: []string{common_models.JobError + "," + common_models.JobStopped}, StartTime: start})
+	assert.Subset(t, res, ids[:2])
+	assert.NotContains(t, res, ids[2])
+
+	// the jobs updated after the end time are excluded
+	res = list(&listQuery{
+		Status:  []string{common_models.JobError},
+		EndTime: time.Now().Add(-time.Hour).Format(time.RFC3339),
+	})
+	assert.NotContains(t, res, ids[0])
+
+	// invalid parameters
+	for _, query := range []*listQuery{
+		{Status: []string{common_models.JobError + ",unknown"}},
+		{StartTime: "2019-10-01"},
+		{EndTime: "yesterday"},
+		{StartTime: "2019-10-02T00:00:00Z", EndTime: "2019-10-01T00:00:00Z"},
+	} {
+		runCodeCheckingCases(t, &codeCheckingCase{
+			request: &testingRequest{
+				method:      http.MethodGet,
+				url:         "/api/system/gc",
+				credential:  sysAdmin,
+				queryStruct: query,
+			},
+			code: http.StatusBadRequest,
+		})
+	}
+}
+
+func TestParseAdminJobStatuses(t *testing.T) {
+	statuses, err := parseAdminJobStatuses(nil)
+	require.Nil(t, err)
+	assert.Empty(t, statuses)
+
+	statuses, err = parseAdminJobStatuses([]string{"error, stopped", "finished", ""})
+	require.Nil(t, err)
+	assert.Equal(t, []string{common_models.JobError, common_models.JobStopped, common_models.JobFinished}, statuses)
+
+	_, err = parseAdminJobStatuses([]string{"error,unknown"})
+	assert.NotNil(t, err)
+}
+
+func TestParseAdminJobTime(t *testing.T) {
+	tm, err := parseAdminJobTime("start_time", "")
+	require.Nil(t, err)
+	assert.Nil(t, tm)
+
+	tm, err = parseAdminJobTime("start_time", "2019-10-01T08:00:00+08:00")
+	require.Nil(t, err)
+	require.NotNil(t, tm)
+	assert.True(t, tm.Equal(time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)))
+
+	for _, value := range []string{"2019-10-01", "2019-10-01 00:00:00", "1569888000"} {
+		_, err = parseAdminJobTime("start_time", value)
+		assert.NotNil(t, err, value)
+	}
+}
+
+func TestGCStop(t *testing.T) {
+	client := &job.MockJobClient{JobUUID: []string{"running-gc"}}
+	old := getJobServiceClient
+	getJobServiceClient = func() common_job.Client { return client }
+	defer func() { getJobServiceClient = old }()
+
+	addJob := func(uuid, status string) int64 {
+		id, err := dao.AddAdminJob(&common_models.AdminJob{
+			Name: common_job.ImageGC,
+			Kind: common_job.JobKindGeneric,
+		})
+		require.Nil(t, err)
+		require.Nil(t, dao.SetAdminJobUUID(id, uuid))
+		require.Nil(t, dao.UpdateAdminJobStatus(id, status))
+		return id
+	}
+	running := addJob("running-gc", common_models.JobRunning)
+	unknown := addJob("unknown-gc", common_models.JobRunning)
+	finished := addJob("finished-gc", common_models.JobFinished)
+	defer func() {
+		for _, id := range []int64{running, unknown, finished} {
+			dao.DeleteAdminJob(id)
+		}
+	}()
+
+	stopURL := func(id int64) string {
+		return fmt.Sprintf("/api/system/gc/%d/stop", id)
+	}
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    stopURL(running),
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        stopURL(running),
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 404, the admin job doesn't exist
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        stopURL(finished + 1000),
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 404, the job doesn't exist in the job service
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        stopURL(unknown),
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 409, the job is already in the final status
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        stopURL(finished),
+				credential: sysAdmin,
+			},
+			code: http.StatusConflict,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        stopURL(running),
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 409, the job has been stopped
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        stopURL(running),
+				credential: sysAdmin,
+			},
+			code: http.StatusConflict,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	j, err := dao.GetAdminJob(running)
+	require.Nil(t, err)
+	assert.Equal(t, common_models.JobStopped, j.Status)
+	j, err = dao.GetAdminJob(unknown)
+	require.Nil(t, err)
+	assert.Equal(t, common_models.JobRunning, j.Status)
+}
+
+func TestPruneAdminJobsDeletesLogs(t *testing.T) {
+	name := "prune-logs-job"
+	client := &job.MockJobClient{JobUUID: []string{"pruned-job", "kept-job"}}
+	old := getJobServiceClient
+	getJobServiceClient = func() common_job.Client { return client }
+	defer func() { getJobServiceClient = old }()
+
+	config.Upload(map[string]interface{}{common.AdminJobRetentionCount: 1})
+	defer config.Upload(map[string]interface{}{common.AdminJobRetentionCount: 0})
+
+	// the one never submitted to the job service and the one whose log is swept are pruned as well
+	for _, uuid := range []string{"", "swept-job", "pruned-job", "kept-job"} {
+		id, err := dao.AddAdminJob(&common_models.AdminJob{
+			Name: name,
+			Kind: common_job.JobKindGeneric,
+		})
+		require.Nil(t, err)
+		defer dao.DeleteAdminJob(id)
+		if len(uuid) > 0 {
+			require.Nil(t, dao.SetAdminJobUUID(id, uuid))
+		}
+		require.Nil(t, dao.UpdateAdminJobStatus(id, common_models.JobFinished))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	pruneAdminJobs(name)
+	assert.Equal(t, []string{"pruned-job"}, client.DeletedLogs)
+	jobs, err := dao.GetAdminJobs(&common_models.AdminJobQuery{Name: name})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(jobs))
+	assert.Equal(t, "kept-job", jobs[0].UUID)
+}
+
+func TestConvertToAdminJobRepNextRun(t *testing.T) {
+	// the next run is computed for the periodic job
+	rep, err := convertToAdminJobRep(&common_models.AdminJob{
+		Name: common_job.ImageGC,
+		Kind: common_job.JobKindPeriodic,
+		Cron: `{"type":"Hourly","cron":"0 0 * * * *"}`,
+	})
+	require.Nil(t, err)
+	require.NotNil(t, rep.NextRun)
+	assert.True(t, rep.NextRun.After(time.Now()))
+	assert.Equal(t, 0, rep.NextRun.Minute())
+
+	// the invalid cron stored doesn't fail the conversion
+	rep, err = convertToAdminJobRep(&common_models.AdminJob{
+		Name: common_job.ImageGC,
+		Kind: common_job.JobKindPeriodic,
+		Cron: `{"type":"Custom","cron":"invalid"}`,
+	})
+	require.Nil(t, err)
+	assert.Nil(t, rep.NextRun)
+
+	// no next run for the manual job
+	rep, err = convertToAdminJobRep(&common_models.AdminJob{
+		Name: common_job.ImageGC,
+		Kind: common_job.JobKindGeneric,
+		Cron: `{"type":"Manual","cron":""}`,
+	})
+	require.Nil(t, err)
+	assert.Nil(t, rep.NextRun)
+	data, err := json.Marshal(rep)
+	require.Nil(t, err)
+	assert.NotContains(t, string(data), "next_run")
+}
+
+func TestConvertToAdminJobRepSummary(t *testing.T) {
+	rep, err := convertToAdminJobRep(&common_models.AdminJob{
+		Name:    common_job.ImageGC,
+		Kind:    common_job.JobKindGeneric,
+		Summary: `{"dry_run":true,"blob_count":2,"blob_size":1024}`,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"dry_run":    true,
+		"blob_count": float64(2),
+		"blob_size":  float64(1024),
+	}, rep.Summary)
+
+	// no summary if the job doesn't report it
+	rep, err = convertToAdminJobRep(&common_models.AdminJob{
+		Name: common_job.ImageGC,
+		Kind: common_job.JobKindGeneric,
+	})
+	require.Nil(t, err)
+	data, err := json.Marshal(rep)
+	require.Nil(t, err)
+	assert.NotContains(t, string(data), "summary")
+}
+
+func TestConvertToAdminJobRepStats(t *testing.T) {
+	rep, err := convertToAdminJobRep(&common_models.AdminJob{
+		Name:  common_job.ImageGC,
+		Kind:  common_job.JobKindGeneric,
+		Stats: `{"kind":"stats","blobs_deleted":2,"manifests_deleted":1,"bytes_freed":1024,"duration":3,"errors":1}`,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, &common_models.GCStats{
+		BlobsDeleted:     2,
+		ManifestsDeleted: 1,
+		BytesFreed:       1024,
+		Duration:         3,
+		Errors:           1,
+	}, rep.Stats)
+	data, err := json.Marshal(rep)
+	require.Nil(t, err)
+	assert.Contains(t, string(data), `"stats":{"blobs_deleted":2,"manifests_deleted":1,"bytes_freed":1024,"duration":3,"errors":1}`)
+
+	// the stats of the old executions are rendered as null
+	rep, err = convertToAdminJobRep(&common_models.AdminJob{
+		Name: common_job.ImageGC,
+		Kind: common_job.JobKindGeneric,
+	})
+	require.Nil(t, err)
+	assert.Nil(t, rep.Stats)
+	data, err = json.Marshal(rep)
+	require.Nil(t, err)
+	assert.Contains(t, string(data), `"stats":null`)
+}
+
+func TestGCListSummary(t *testing.T) {
+	id, err := dao.AddAdminJob(&common_models.AdminJob{
+		Name: common_job.ImageGC,
+		Kind: common_job.JobKindGeneric,
+	})
+	require.Nil(t, err)
+	defer dao.DeleteAdminJob(id)
+	require.Nil(t, dao.UpdateAdminJobSummary(id, `{"dry_run":true,"blob_count":2,"blob_size":1024}`))
+
+	jobs := []*models.AdminJobRep{}
+	require.Nil(t, handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/system/gc",
+		credential: sysAdmin,
+	}, &jobs))
+	var summary map[string]interface{}
+	for _, job := range jobs {
+		if job.ID == id {
+			summary = job.Summary
+		}
+	}
+	assert.Equal(t, true, summary["dry_run"])
+	assert.Equal(t, float64(2), summary["blob_count"])
+	assert.Equal(t, float64(1024), summary["blob_size"])
+}
+
+func TestGCPostNotUnique(t *testing.T) {
+	notUnique := false
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPost,
+			url:        "/api/system/gc/schedule",
+			credential: sysAdmin,
+			bodyJSON: &models.AdminJobReq{
+				AdminJobSchedule: models.AdminJobSchedule{
+					Schedule: &models.ScheduleParam{
+						Type: models.ScheduleManual,
+					},
+				},
+				Unique: &notUnique,
+			},
+		},
+		code: http.StatusBadRequest,
+	})
+}
+
+func TestGCNamedScheduleUnsupported(t *testing.T) {
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPost,
+			url:        "/api/system/gc/schedule",
+			credential: sysAdmin,
+			bodyJSON: &models.AdminJobReq{
+				AdminJobSchedule: models.AdminJobSchedule{
+					ScheduleName: "weekly",
+					Schedule: &models.ScheduleParam{
+						Type: models.ScheduleWeekly,
+						Cron: "0 0 0 * * 0",
+					},
+				},
+			},
+		},
+		code: http.StatusBadRequest,
+	})
+}
+
+func TestGCGetLog(t *testing.T) {
+	client := &job.MockJobClient{
+		JobUUID: []string{"gc-with-log"},
+		JobLog:  "line 1\nline 2\nline 3\n",
+	}
+	old := getJobServiceClient
+	getJobServiceClient = func() common_job.Client { return client }
+	defer func() { getJobServiceClient = old }()
+
+	addJob := func(uuid string) int64 {
+		id, err := dao.AddAdminJob(&common_models.AdminJob{
+			Name: common_job.ImageGC,
+			Kind: common_job.JobKindGeneric,
+		})
+		require.Nil(t, err)
+		require.Nil(t, dao.SetAdminJobUUID(id, uuid))
+		return id
+	}
+	withLog := addJob("gc-with-log")
+	withoutLog := addJob("gc-without-log")
+	defer func() {
+		for _, id := range []int64{withLog, withoutLog} {
+			dao.DeleteAdminJob(id)
+		}
+	}()
+
+	logURL := func(id int64) string {
+		return fmt.Sprintf("/api/system/gc/%d/log", id)
+	}
+	getLog := func(url string, header http.Header) *httptest.ResponseRecorder {
+		resp, err := handle(&testingRequest{
+			method:     http.MethodGet,
+			url:        url,
+			header:     header,
+			credential: sysAdmin,
+		})
+		require.Nil(t, err)
+		return resp
+	}
+
+	// the full log
+	resp := getLog(logURL(withLog), nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/plain", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "21", resp.Header().Get("Content-Length"))
+	assert.Equal(t, "line 1\nline 2\nline 3\n", resp.Body.String())
+
+	// the last lines
+	resp = getLog(logURL(withLog)+"?tail=2", nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "line 2\nline 3\n", resp.Body.String())
+	resp = getLog(logURL(withLog)+"?tail=10", nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "line 1\nline 2\nline 3\n", resp.Body.String())
+
+	// the Range header is passed through
+	resp = getLog(logURL(withLog), http.Header{"Range": []string{"bytes=7-13"}})
+	require.Equal(t, http.StatusPartialContent, resp.Code)
+	assert.Equal(t, "bytes 7-13/21", resp.Header().Get("Content-Range"))
+	assert.Equal(t, "line 2\n", resp.Body.String())
+
+	// the log isn't found in the job service
+	assert.Equal(t, http.StatusNotFound, getLog(logURL(withoutLog), nil).Code)
+
+	// invalid tail
+	for _, tail := range []string{"0", "-1", "abc", "10001"} {
+		assert.Equal(t, http.StatusBadRequest, getLog(logURL(withLog)+"?tail="+tail, nil).Code, tail)
+	}
+	assert.Equal(t, http.StatusBadRequest,
+		getLog(logURL(withLog)+"?tail=1", http.Header{"Range": []string{"bytes=0-1"}}).Code)
+}
+
+func TestTailLines(t *testing.T) {
+	cases := []struct {
+		log      string
+		n        int
+		expected string
+	}{
+		{log: "", n: 1, expected: ""},
+		{log: "a\nb\nc\n", n: 1, expected: "c\n"},
+		{log: "a\nb\nc\n", n: 2, expected: "b\nc\n"},
+		{log: "a\nb\nc\n", n: 3, expected: "a\nb\nc\n"},
+		{log: "a\nb\nc\n", n: 5, expected: "a\nb\nc\n"},
+		// the last line without the line break
+		{log: "a\nb\nc", n: 2, expected: "b\nc"},
+	}
+	for _, c := range cases {
+		data, err := tailLines(strings.NewReader(c.log), c.n)
+		require.Nil(t, err)
+		assert.Equal(t, c.expected, string(data), "%q: %d", c.log, c.n)
+	}
+}
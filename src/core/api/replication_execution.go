@@ -19,9 +19,12 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	common_http "github.com/goharbor/harbor/src/common/http"
 	"github.com/goharbor/harbor/src/replication"
+	rep_dao "github.com/goharbor/harbor/src/replication/dao"
 	"github.com/goharbor/harbor/src/replication/dao/models"
 	"github.com/goharbor/harbor/src/replication/event"
 	"github.com/goharbor/harbor/src/replication/model"
@@ -109,6 +112,57 @@ func (r *ReplicationOperationAPI) ListExecutions() {
 	r.WriteJSONData(executions)
 }
 
+// ListSystemExecutions lists the executions of all the policies filtered by the status and start time,
+// it's used to monitor the replication failures of the whole system
+func (r *ReplicationOperationAPI) ListSystemExecutions() {
+	if !r.SecurityCtx.IsSysAdmin() {
+		r.SendForbiddenError(errors.New(r.SecurityCtx.GetUsername()))
+		return
+	}
+
+	status := models.ExecutionStatusFailed
+	if s := r.GetString("status"); len(s) > 0 {
+		var ok bool
+		status, ok = parseExecutionStatus(s)
+		if !ok {
+			r.SendBadRequestError(fmt.Errorf("invalid status %s", s))
+			return
+		}
+	}
+
+	var since int64
+	if len(r.GetString("since")) > 0 {
+		var err error
+		since, err = r.GetInt64("since")
+		if err != nil || since < 0 {
+			r.SendBadRequestError(fmt.Errorf("invalid since %s", r.GetString("since")))
+			return
+		}
+	}
+
+	summaries, err := rep_dao.GetReplicationExecutionsByStatus(status, time.Unix(since, 0), rep_dao.MaxExecutionSummaries)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to list executions: %v", err))
+		return
+	}
+	r.WriteJSONData(summaries)
+}
+
+// parseExecutionStatus returns the execution status matches the string case insensitively
+func parseExecutionStatus(s string) (string, bool) {
+	for _, status := range []string{
+		models.ExecutionStatusFailed,
+		models.ExecutionStatusSucceed,
+		models.ExecutionStatusStopped,
+		models.ExecutionStatusInProgress,
+	} {
+		if strings.EqualFold(s, status) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
 // CreateExecution starts a replication
 func (r *ReplicationOperationAPI) CreateExecution() {
 	execution := &models.Execution{}
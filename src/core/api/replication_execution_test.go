@@ -22,6 +22,7 @@ import (
 
 	"github.com/goharbor/harbor/src/replication/dao/models"
 	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
 )
 
 type fakedOperationController struct{}
@@ -69,6 +70,9 @@ func (f *fakedOperationController) GetTask(id int64) (*models.Task, error) {
 func (f *fakedOperationController) UpdateTaskStatus(id int64, status string, statusRevision int64, statusCondition ...string) error {
 	return nil
 }
+func (f *fakedOperationController) UpdateTaskTransferMode(id int64, mode string) error {
+	return nil
+}
 func (f *fakedOperationController) GetTaskLog(int64) ([]byte, error) {
 	return []byte("success"), nil
 }
@@ -156,6 +160,85 @@ func TestListExecutions(t *testing.T) {
 	runCodeCheckingCases(t, cases...)
 }
 
+func TestListSystemExecutions(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/system/replication/executions",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/system/replication/executions",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, invalid status
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/system/replication/executions",
+				queryStruct: struct {
+					Status string `url:"status"`
+				}{
+					Status: "unknown",
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, invalid since
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/system/replication/executions",
+				queryStruct: struct {
+					Since string `url:"since"`
+				}{
+					Since: "yesterday",
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/system/replication/executions",
+				queryStruct: struct {
+					Status string `url:"status"`
+					Since  int64  `url:"since"`
+				}{
+					Status: "failed",
+					Since:  1,
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	runCodeCheckingCases(t, cases...)
+}
+
+func TestParseExecutionStatus(t *testing.T) {
+	status, ok := parseExecutionStatus("failed")
+	assert.True(t, ok)
+	assert.Equal(t, models.ExecutionStatusFailed, status)
+	status, ok = parseExecutionStatus("InProgress")
+	assert.True(t, ok)
+	assert.Equal(t, models.ExecutionStatusInProgress, status)
+	_, ok = parseExecutionStatus("unknown")
+	assert.False(t, ok)
+}
+
 func TestCreateExecution(t *testing.T) {
 	operationCtl := replication.OperationCtl
 	policyMgr := replication.PolicyCtl
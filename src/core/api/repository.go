@@ -283,55 +283,75 @@ func (ra *RepositoryAPI) Delete() {
 	}
 
 	for _, t := range tags {
-		image := fmt.Sprintf("%s:%s", repoName, t)
-		if err = dao.DeleteLabelsOfResource(common.ResourceTypeImage, image); err != nil {
-			ra.SendInternalServerError(fmt.Errorf("failed to delete labels of image %s: %v", image, err))
-			return
-		}
-		if err = rc.DeleteTag(t); err != nil {
+		if err = ra.deleteTag(project, repoName, rc, t); err != nil {
 			if regErr, ok := err.(*commonhttp.Error); ok {
 				if regErr.Code == http.StatusNotFound {
 					continue
 				}
+				ra.ParseAndHandleError(fmt.Sprintf("failed to delete tag %s", t), err)
+				return
 			}
-			ra.ParseAndHandleError(fmt.Sprintf("failed to delete tag %s", t), err)
+			ra.SendInternalServerError(err)
 			return
 		}
-		log.Infof("delete tag: %s:%s", repoName, t)
-
-		go func(tag string) {
-			e := &event.Event{
-				Type: event.EventTypeImageDelete,
-				Resource: &model.Resource{
-					Type: model.ResourceTypeImage,
-					Metadata: &model.ResourceMetadata{
-						Repository: &model.Repository{
-							Name: repoName,
-						},
-						Vtags: []string{tag},
+	}
+
+	if err = ra.cleanupDeletedTags(project, repoName, rc, tags); err != nil {
+		ra.SendInternalServerError(err)
+		return
+	}
+}
+
+// deleteTag deletes the tag with the repository client, the labels of the image are removed and the
+// replication event and the access log are recorded. The error returned by the registry is kept as it is.
+func (ra *RepositoryAPI) deleteTag(project *models.Project, repoName string, rc *registry.Repository, tag string) error {
+	image := fmt.Sprintf("%s:%s", repoName, tag)
+	if err := dao.DeleteLabelsOfResource(common.ResourceTypeImage, image); err != nil {
+		return fmt.Errorf("failed to delete labels of image %s: %v", image, err)
+	}
+	if err := rc.DeleteTag(tag); err != nil {
+		return err
+	}
+	log.Infof("delete tag: %s:%s", repoName, tag)
+
+	go func(tag string) {
+		e := &event.Event{
+			Type: event.EventTypeImageDelete,
+			Resource: &model.Resource{
+				Type: model.ResourceTypeImage,
+				Metadata: &model.ResourceMetadata{
+					Repository: &model.Repository{
+						Name: repoName,
 					},
-					Deleted: true,
+					Vtags: []string{tag},
 				},
-			}
-			if err := replication.EventHandler.Handle(e); err != nil {
-				log.Errorf("failed to handle event: %v", err)
-			}
-		}(t)
-
-		go func(tag string) {
-			if err := dao.AddAccessLog(models.AccessLog{
-				Username:  ra.SecurityCtx.GetUsername(),
-				ProjectID: project.ProjectID,
-				RepoName:  repoName,
-				RepoTag:   tag,
-				Operation: "delete",
-				OpTime:    time.Now(),
-			}); err != nil {
-				log.Errorf("failed to add access log: %v", err)
-			}
-		}(t)
-	}
+				Deleted: true,
+			},
+		}
+		if err := replication.EventHandler.Handle(e); err != nil {
+			log.Errorf("failed to handle event: %v", err)
+		}
+	}(tag)
+
+	go func(tag string) {
+		if err := dao.AddAccessLog(models.AccessLog{
+			Username:  ra.SecurityCtx.GetUsername(),
+			ProjectID: project.ProjectID,
+			RepoName:  repoName,
+			RepoTag:   tag,
+			Operation: "delete",
+			OpTime:    time.Now(),
+		}); err != nil {
+			log.Errorf("failed to add access log: %v", err)
+		}
+	}(tag)
+
+	return nil
+}
 
+// cleanupDeletedTags publishes the image delete event of the tags and removes the repository record
+// once all the tags of the repository are deleted
+func (ra *RepositoryAPI) cleanupDeletedTags(project *models.Project, repoName string, rc *registry.Repository, tags []string) error {
 	// build and publish image delete event
 	evt := &notifierEvt.Event{}
 	imgDelMetadata := &notifierEvt.ImageDelMetaData{
@@ -354,32 +374,31 @@ func (ra *RepositoryAPI) Delete() {
 	exist, err := repositoryExist(repoName, rc)
 	if err != nil {
 		log.Errorf("failed to check the existence of repository %s: %v", repoName, err)
-		ra.SendInternalServerError(fmt.Errorf("failed to check the existence of repository %s: %v", repoName, err))
-		return
+		return fmt.Errorf("failed to check the existence of repository %s: %v", repoName, err)
+	}
+	if exist {
+		return nil
 	}
-	if !exist {
-		repository, err := dao.GetRepositoryByName(repoName)
-		if err != nil {
-			ra.SendInternalServerError(fmt.Errorf("failed to get repository %s: %v", repoName, err))
-			return
-		}
-		if repository == nil {
-			log.Warningf("the repository %s not found after deleting tags", repoName)
-			return
-		}
 
-		if err = dao.DeleteLabelsOfResource(common.ResourceTypeRepository,
-			strconv.FormatInt(repository.RepositoryID, 10)); err != nil {
-			ra.SendInternalServerError(fmt.Errorf("failed to delete labels of repository %s: %v",
-				repoName, err))
-			return
-		}
-		if err = dao.DeleteRepository(repoName); err != nil {
-			log.Errorf("failed to delete repository %s: %v", repoName, err)
-			ra.SendInternalServerError(fmt.Errorf("failed to delete repository %s: %v", repoName, err))
-			return
-		}
+	repository, err := dao.GetRepositoryByName(repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get repository %s: %v", repoName, err)
+	}
+	if repository == nil {
+		log.Warningf("the repository %s not found after deleting tags", repoName)
+		return nil
 	}
+
+	if err = dao.DeleteLabelsOfResource(common.ResourceTypeRepository,
+		strconv.FormatInt(repository.RepositoryID, 10)); err != nil {
+		return fmt.Errorf("failed to delete labels of repository %s: %v", repoName, err)
+	}
+	if err = dao.DeleteRepository(repoName); err != nil {
+		log.Errorf("failed to delete repository %s: %v", repoName, err)
+		return fmt.Errorf("failed to delete repository %s: %v", repoName, err)
+	}
+
+	return nil
 }
 
 // GetTag returns the tag of a repository
@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/filter"
 	"github.com/goharbor/harbor/src/core/promgr"
 	"github.com/goharbor/harbor/src/pkg/retention"
@@ -197,6 +198,12 @@ func (r *RetentionAPI) CreateRetention() {
 	if err := r.pm.GetMetadataManager().Add(p.Scope.Reference,
 		map[string]string{"retention_id": strconv.FormatInt(id, 10)}); err != nil {
 		r.SendInternalServerError(err)
+		return
+	}
+	p.ID = id
+	if err = recordProjectConfigChange(p.Scope.Reference, r.SecurityCtx.GetUsername(),
+		&projectConfig{}, &projectConfig{Retention: p}); err != nil {
+		log.Errorf("failed to record the creation of retention policy %d: %v", id, err)
 	}
 	r.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
 }
@@ -226,10 +233,21 @@ func (r *RetentionAPI) UpdateRetention() {
 	if !r.requireAccess(p, rbac.ActionUpdate) {
 		return
 	}
+	old, err := retentionController.GetRetention(id)
+	if err != nil {
+		r.SendInternalServerError(err)
+		return
+	}
 	if err = retentionController.UpdateRetention(p); err != nil {
 		r.SendInternalServerError(err)
 		return
 	}
+	if old != nil && old.Scope != nil && old.Scope.Level == policy.ScopeLevelProject {
+		if err = recordProjectConfigChange(old.Scope.Reference, r.SecurityCtx.GetUsername(),
+			&projectConfig{Retention: old}, &projectConfig{Retention: p}); err != nil {
+			log.Errorf("failed to record the change of retention policy %d: %v", id, err)
+		}
+	}
 }
 
 func (r *RetentionAPI) checkRuleConflict(p *policy.Metadata) error {
@@ -255,14 +273,25 @@ func (r *RetentionAPI) TriggerRetentionExec() {
 	}
 	d := &struct {
 		DryRun bool `json:"dry_run"`
+		// the mode of the dry run: "fast" or "validated"
+		DryRunMode string `json:"dry_run_mode"`
 	}{
-		DryRun: false,
+		DryRun:     false,
+		DryRunMode: retention.DryRunModeFast,
 	}
 	isValid, err := r.DecodeJSONReqAndValidate(d)
 	if !isValid {
 		r.SendBadRequestError(err)
 		return
 	}
+	if len(d.DryRunMode) == 0 {
+		d.DryRunMode = retention.DryRunModeFast
+	}
+	if d.DryRunMode != retention.DryRunModeFast && d.DryRunMode != retention.DryRunModeValidated {
+		r.SendBadRequestError(fmt.Errorf("invalid dry run mode %s, it must be %s or %s",
+			d.DryRunMode, retention.DryRunModeFast, retention.DryRunModeValidated))
+		return
+	}
 	p, err := retentionController.GetRetention(id)
 	if err != nil {
 		r.SendBadRequestError(err)
@@ -271,7 +300,7 @@ func (r *RetentionAPI) TriggerRetentionExec() {
 	if !r.requireAccess(p, rbac.ActionUpdate) {
 		return
 	}
-	eid, err := retentionController.TriggerRetentionExec(id, retention.ExecutionTriggerManual, d.DryRun)
+	eid, err := retentionController.TriggerRetentionExec(id, retention.ExecutionTriggerManual, d.DryRun, d.DryRunMode)
 	if err != nil {
 		r.SendInternalServerError(err)
 		return
@@ -425,6 +454,115 @@ func (r *RetentionAPI) GetRetentionExecTaskLog() {
 	w.Write(log)
 }
 
+// PreviewRetention previews the artifacts which would be affected by the retention policy of the project
+func (r *RetentionAPI) PreviewRetention() {
+	sampleSize, err := r.GetInt("sample_size", retention.DefaultPreviewSampleSize)
+	if err != nil || sampleSize <= 0 || sampleSize > retention.DefaultPreviewSampleSize {
+		r.SendBadRequestError(fmt.Errorf("invalid sample_size, should be between 1 and %d", retention.DefaultPreviewSampleSize))
+		return
+	}
+	p, ok := r.getProjectRetention()
+	if !ok {
+		return
+	}
+	if !r.requireAccess(p, rbac.ActionRead) {
+		return
+	}
+	preview, err := retentionController.PreviewRetention(p.ID, sampleSize)
+	if err != nil {
+		r.SendInternalServerError(err)
+		return
+	}
+	r.WriteJSONData(preview)
+}
+
+// AnalyzeRetentionConflicts starts the background analysis of the tags protected by the conflicting
+// rules of the retention policy of the project
+func (r *RetentionAPI) AnalyzeRetentionConflicts() {
+	p, ok := r.getProjectRetention()
+	if !ok {
+		return
+	}
+	if !r.requireAccess(p, rbac.ActionRead) {
+		return
+	}
+	if err := retentionController.AnalyzeRetentionConflicts(p.ID); err != nil {
+		if err == retention.ErrAnalysisInProgress {
+			r.SendConflictError(err)
+			return
+		}
+		r.SendInternalServerError(err)
+		return
+	}
+	r.Ctx.ResponseWriter.WriteHeader(http.StatusAccepted)
+}
+
+// ListTagRetentionConflicts returns the result of the last conflict analysis of the retention policy of the project
+func (r *RetentionAPI) ListTagRetentionConflicts() {
+	pid, err := r.GetIDFromURL()
+	if err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+	md, err := r.pm.GetMetadataManager().Get(pid, "retention_id")
+	if err != nil {
+		r.SendInternalServerError(err)
+		return
+	}
+	if len(md["retention_id"]) == 0 {
+		r.SendNotFoundError(fmt.Errorf("no retention policy found in project %d", pid))
+		return
+	}
+	id, err := strconv.ParseInt(md["retention_id"], 10, 64)
+	if err != nil {
+		r.SendInternalServerError(err)
+		return
+	}
+	p, err := retentionController.GetRetention(id)
+	if err != nil {
+		r.SendInternalServerError(err)
+		return
+	}
+	if !r.requireAccess(p, rbac.ActionRead) {
+		return
+	}
+	analysis, err := retentionController.GetRetentionConflicts(id)
+	if err != nil {
+		r.SendInternalServerError(err)
+		return
+	}
+	if analysis == nil {
+		r.SendNotFoundError(fmt.Errorf("the retention policy %d has not been analyzed", id))
+		return
+	}
+	r.WriteJSONData(analysis)
+}
+
+// getProjectRetention gets the retention policy specified by the path parameter ":id",
+// which must belong to the project specified by ":pid"
+func (r *RetentionAPI) getProjectRetention() (*policy.Metadata, bool) {
+	pid, err := r.GetInt64FromPath(":pid")
+	if err != nil {
+		r.SendBadRequestError(err)
+		return nil, false
+	}
+	id, err := r.GetIDFromURL()
+	if err != nil {
+		r.SendBadRequestError(err)
+		return nil, false
+	}
+	p, err := retentionController.GetRetention(id)
+	if err != nil {
+		r.SendBadRequestError(err)
+		return nil, false
+	}
+	if p.Scope == nil || p.Scope.Level != "project" || p.Scope.Reference != pid {
+		r.SendNotFoundError(fmt.Errorf("retention policy %d not found in project %d", id, pid))
+		return nil, false
+	}
+	return p, true
+}
+
 func (r *RetentionAPI) requireAccess(p *policy.Metadata, action rbac.Action, subresources ...rbac.Resource) bool {
 	var hasPermission bool
 
@@ -3,12 +3,16 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	common_dao "github.com/goharbor/harbor/src/common/dao"
+	common_models "github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/pkg/retention/dao"
 	"github.com/goharbor/harbor/src/pkg/retention/dao/models"
 	"github.com/goharbor/harbor/src/pkg/retention/policy"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/rule"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 )
@@ -211,6 +215,17 @@ func TestCreatePolicy(t *testing.T) {
 	}
 
 	runCodeCheckingCases(t, cases...)
+
+	// the creation of the policy is recorded in the configuration history of the project
+	changes, err := common_dao.GetProjectConfigHistory(&common_models.ProjectConfigChangeQuery{ProjectID: 1})
+	require.Nil(t, err)
+	created := false
+	for _, change := range changes {
+		if strings.Contains(string(change.Diff), `"op":"add","path":"/retention"`) {
+			created = true
+		}
+	}
+	assert.True(t, created)
 }
 
 func TestPolicy(t *testing.T) {
@@ -410,6 +425,22 @@ func TestPolicy(t *testing.T) {
 			},
 			code: http.StatusOK,
 		},
+		// 400, invalid dry run mode
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    fmt.Sprintf("/api/retentions/%d/executions", id),
+				bodyJSON: &struct {
+					DryRun     bool   `json:"dry_run"`
+					DryRunMode string `json:"dry_run_mode"`
+				}{
+					DryRun:     true,
+					DryRunMode: "thorough",
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
 		{
 			request: &testingRequest{
 				method:     http.MethodGet,
@@ -418,7 +449,92 @@ func TestPolicy(t *testing.T) {
 			},
 			code: http.StatusOK,
 		},
+		// 400, invalid sample size
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        fmt.Sprintf("/api/projects/1/retentions/%d/preview?sample_size=0", id),
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, sample size exceeds the limit
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        fmt.Sprintf("/api/projects/1/retentions/%d/preview?sample_size=1001", id),
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 404, the policy doesn't belong to the project
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        fmt.Sprintf("/api/projects/1000/retentions/%d/preview", id),
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    fmt.Sprintf("/api/projects/1/retentions/%d/preview", id),
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        fmt.Sprintf("/api/projects/1/retentions/%d/preview?sample_size=10", id),
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 404, the policy doesn't belong to the project
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        fmt.Sprintf("/api/projects/1000/retentions/%d/analyze", id),
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    fmt.Sprintf("/api/projects/1/retentions/%d/analyze", id),
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 202
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        fmt.Sprintf("/api/projects/1/retentions/%d/analyze", id),
+				credential: sysAdmin,
+			},
+			code: http.StatusAccepted,
+		},
+		// 404, no retention policy in the project
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1000/tag-retention-conflicts",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
 	}
 
 	runCodeCheckingCases(t, cases...)
+
+	// the update of the rules is recorded in the configuration history of the project
+	changes, err := common_dao.GetProjectConfigHistory(&common_models.ProjectConfigChangeQuery{ProjectID: 1})
+	require.Nil(t, err)
+	require.True(t, len(changes) > 0)
+	assert.Contains(t, string(changes[0].Diff), `"path":"/retention/rules"`)
 }
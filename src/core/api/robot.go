@@ -16,6 +16,7 @@ package api
 
 import (
 	"fmt"
+	"github.com/astaxie/beego"
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/rbac"
@@ -26,6 +27,7 @@ import (
 	"github.com/pkg/errors"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 // RobotAPI ...
@@ -82,7 +84,7 @@ func (r *RobotAPI) Prepare() {
 			return
 		}
 
-		if robot == nil {
+		if robot == nil || robot.ProjectID != r.project.ProjectID {
 			r.SendNotFoundError(fmt.Errorf("robot %d not found", id))
 			return
 		}
@@ -184,7 +186,7 @@ func (r *RobotAPI) Get() {
 		r.SendInternalServerError(errors.Wrap(err, "robot API: get robot"))
 		return
 	}
-	if robot == nil {
+	if robot == nil || robot.ProjectID != r.project.ProjectID {
 		r.SendNotFoundError(fmt.Errorf("robot API: robot %d not found", id))
 		return
 	}
@@ -230,6 +232,55 @@ func (r *RobotAPI) Delete() {
 	}
 }
 
+// RotateToken issues a new token to the robot without changing its name, the old token keeps
+// validating during the overlap specified in the request
+func (r *RobotAPI) RotateToken() {
+	if !r.requireAccess(rbac.ActionUpdate) {
+		return
+	}
+
+	req, err := decodeTokenRotateReq(&r.BaseController)
+	if err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+	if len(req.Access) > 0 {
+		if err := validateRobotReq(r.project, &model.RobotCreate{Access: req.Access}); err != nil {
+			r.SendBadRequestError(err)
+			return
+		}
+	}
+
+	rb, err := r.ctr.RotateRobotToken(r.robot, req.Access, time.Duration(req.OverlapSeconds)*time.Second)
+	if err != nil {
+		if err == robot.ErrNoAccess {
+			r.SendBadRequestError(err)
+			return
+		}
+		r.SendInternalServerError(errors.Wrap(err, "robot API: rotate token"))
+		return
+	}
+
+	r.Data["json"] = model.RobotRep{
+		Name:  rb.Name,
+		Token: rb.Token,
+	}
+	r.ServeJSON()
+}
+
+// decodeTokenRotateReq decodes the request of rotating the robot token, the body is optional
+func decodeTokenRotateReq(b *BaseController) (*model.RobotTokenRotate, error) {
+	req := &model.RobotTokenRotate{}
+	// the body is replaced by the bounded copy, so decoding reads no more than the max memory either
+	if len(b.Ctx.Input.CopyBody(beego.BConfig.MaxMemory)) == 0 {
+		return req, nil
+	}
+	if isValid, err := b.DecodeJSONReqAndValidate(req); !isValid {
+		return nil, err
+	}
+	return req, nil
+}
+
 func validateRobotReq(p *models.Project, robotReq *model.RobotCreate) error {
 	if len(robotReq.Access) == 0 {
 		return errors.New("access required")
@@ -20,7 +20,10 @@ import (
 	"testing"
 
 	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/pkg/robot"
 	"github.com/goharbor/harbor/src/pkg/robot/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -130,6 +133,21 @@ func TestRobotAPIPost(t *testing.T) {
 			},
 			code: http.StatusBadRequest,
 		},
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    robotPath,
+				bodyJSON: &model.RobotCreate{
+					Name:        "test",
+					Description: "all projects not allowed",
+					Access: []*rbac.Policy{
+						{Resource: model.AllProjects.Subresource(rbac.ResourceRepository), Action: rbac.ActionPull},
+					},
+				},
+				credential: projAdmin4Robot,
+			},
+			code: http.StatusBadRequest,
+		},
 		// 403 -- developer
 		{
 			request: &testingRequest{
@@ -382,3 +400,102 @@ func TestRobotAPIDelete(t *testing.T) {
 
 	runCodeCheckingCases(t, cases...)
 }
+
+func TestRobotAPIRotateToken(t *testing.T) {
+	rb, err := robot.RobotCtr.CreateRobotAccount(&model.RobotCreate{
+		Name:      "rotate-api",
+		ProjectID: 1,
+		Visible:   true,
+		Access:    []*rbac.Policy{{Resource: "/project/1/repository", Action: rbac.ActionPull}},
+	})
+	require.Nil(t, err)
+	defer robot.RobotCtr.DeleteRobotAccount(rb.ID)
+
+	url := fmt.Sprintf("%s/%d/token", robotPath, rb.ID)
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        url,
+				credential: projDeveloper,
+			},
+			code: http.StatusForbidden,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        fmt.Sprintf("%s/%d/token", robotPath, 10000),
+				credential: projAdmin4Robot,
+			},
+			code: http.StatusNotFound,
+		},
+		// 400, invalid overlap
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        url,
+				bodyJSON:   &model.RobotTokenRotate{OverlapSeconds: -1},
+				credential: projAdmin4Robot,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, the overlap overflowing the duration
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        url,
+				bodyJSON:   &model.RobotTokenRotate{OverlapSeconds: 1 << 62},
+				credential: projAdmin4Robot,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, invalid access
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    url,
+				bodyJSON: &model.RobotTokenRotate{
+					Access: []*rbac.Policy{{Resource: "/project/2/repository", Action: rbac.ActionPull}},
+				},
+				credential: projAdmin4Robot,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200, without body
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        url,
+				credential: projAdmin4Robot,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	rep := &model.RobotRep{}
+	require.Nil(t, handleAndParse(&testingRequest{
+		method:     http.MethodPut,
+		url:        url,
+		bodyJSON:   &model.RobotTokenRotate{OverlapSeconds: 60},
+		credential: projAdmin4Robot,
+	}, rep))
+	assert.Equal(t, rb.Name, rep.Name)
+	assert.NotEmpty(t, rep.Token)
+	assert.NotEqual(t, rb.Token, rep.Token)
+
+	rm, err := robot.RobotCtr.GetRobotAccount(rb.ID)
+	require.Nil(t, err)
+	assert.NotEqual(t, rb.ID, rm.TokenID)
+	assert.NotZero(t, rm.PreviousTokenID)
+}
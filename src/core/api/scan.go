@@ -15,8 +15,11 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/goharbor/harbor/src/pkg/scan/report"
 
@@ -93,7 +96,16 @@ func (sa *ScanAPI) Scan() {
 		return
 	}
 
-	if err := scan.DefaultController.Scan(sa.artifact); err != nil {
+	force, err := sa.GetBool("force", false)
+	if err != nil {
+		sa.SendBadRequestError(errors.Wrap(err, "scan API: scan"))
+		return
+	}
+
+	// The platforms to scan for the multi-arch image, all the platforms are scanned if none is specified
+	platforms := sa.GetStrings("platform")
+
+	if err := scan.DefaultController.Scan(sa.artifact, scan.WithForce(force), scan.WithPlatforms(platforms...)); err != nil {
 		sa.SendInternalServerError(errors.Wrap(err, "scan API: scan"))
 		return
 	}
@@ -102,6 +114,8 @@ func (sa *ScanAPI) Scan() {
 }
 
 // Report returns the required reports with the given mime types.
+// The mime types are negotiated with the Accept header against the mime types of the stored reports,
+// the native report is resolved to the Harbor's normalized format and the others are returned as they are.
 func (sa *ScanAPI) Report() {
 	// Check access permissions
 	if !sa.RequireProjectAccess(sa.pro.ProjectID, rbac.ActionRead, rbac.ResourceScan) {
@@ -111,7 +125,23 @@ func (sa *ScanAPI) Report() {
 	// Extract mime types
 	producesMimes := make([]string, 0)
 	if hl, ok := sa.Ctx.Request.Header[v1.HTTPAcceptHeader]; ok && len(hl) > 0 {
-		producesMimes = append(producesMimes, hl...)
+		available, err := scan.DefaultController.GetReportMimeTypes(sa.artifact)
+		if err != nil {
+			sa.SendInternalServerError(errors.Wrap(err, "scan API: get report"))
+			return
+		}
+
+		// Nothing to negotiate if the artifact isn't scanned yet
+		if len(available) == 0 {
+			producesMimes = append(producesMimes, hl...)
+		} else {
+			producesMimes = report.NewContentNegotiator(available...).Negotiate(hl...)
+			if len(producesMimes) == 0 {
+				sa.RenderFormattedError(http.StatusNotAcceptable,
+					fmt.Sprintf("no report matches the accepted mime types, available: %s", strings.Join(available, ", ")))
+				return
+			}
+		}
 	}
 
 	// Get the reports
@@ -134,6 +164,12 @@ func (sa *ScanAPI) Report() {
 			return
 		}
 
+		if vrp == nil {
+			// No resolver for the mime type, return the raw report
+			vulItems[rp.MimeType] = json.RawMessage(rp.Report)
+			continue
+		}
+
 		vulItems[rp.MimeType] = vrp
 	}
 
@@ -48,6 +48,14 @@ func (sc *ScanAllAPI) Prepare() {
 //    "type": "Manual"
 //  }
 //	}
+// create a named weekly schedule for scan all besides the default one
+// 	{
+//  "schedule_name": "weekly",
+//  "schedule": {
+//    "type": "Weekly",
+//    "cron": "0 0 0 * * 0"
+//  }
+//	}
 func (sc *ScanAllAPI) Post() {
 	ajr := models.AdminJobReq{}
 	isValid, err := sc.DecodeJSONReqAndValidate(&ajr)
@@ -56,11 +64,17 @@ func (sc *ScanAllAPI) Post() {
 		return
 	}
 	ajr.Name = common_job.ImageScanAllJob
-	sc.submit(&ajr)
+	if !sc.prepareParameters(&ajr) {
+		return
+	}
+	if !sc.create(&ajr) {
+		return
+	}
 	sc.Redirect(http.StatusCreated, strconv.FormatInt(ajr.ID, 10))
 }
 
-// Put handles scan all cron schedule update/delete.
+// Put handles scan all cron schedule update/delete, the default schedule is handled if the
+// schedule_name isn't specified.
 // Request: delete the schedule of scan all
 // 	{
 //  "schedule": {
@@ -68,6 +82,14 @@ func (sc *ScanAllAPI) Post() {
 //    "cron": ""
 //  }
 //	}
+// Request: delete the named schedule of scan all
+// 	{
+//  "schedule_name": "weekly",
+//  "schedule": {
+//    "type": "None",
+//    "cron": ""
+//  }
+//	}
 func (sc *ScanAllAPI) Put() {
 	ajr := models.AdminJobReq{}
 	isValid, err := sc.DecodeJSONReqAndValidate(&ajr)
@@ -76,15 +98,56 @@ func (sc *ScanAllAPI) Put() {
 		return
 	}
 	ajr.Name = common_job.ImageScanAllJob
+	if !sc.prepareParameters(&ajr) {
+		return
+	}
 	sc.updateSchedule(ajr)
 }
 
-// Get gets scan all schedule ...
+// Patch updates the cron of the scan all schedule without recreating the job.
+// Request: update the cron of the scan all schedule
+// 	{
+//  "schedule": {
+//    "type": "Custom",
+//    "cron": "0 0 1 * * *"
+//  }
+//	}
+func (sc *ScanAllAPI) Patch() {
+	ajr := models.AdminJobReq{}
+	isValid, err := sc.DecodeJSONReqAndValidate(&ajr)
+	if !isValid {
+		sc.SendBadRequestError(err)
+		return
+	}
+	ajr.Name = common_job.ImageScanAllJob
+	if !sc.prepareParameters(&ajr) {
+		return
+	}
+	sc.patchSchedule(ajr)
+}
+
+// Get gets scan all schedule, the named one is returned if the schedule_name is specified in the query
 func (sc *ScanAllAPI) Get() {
-	sc.getSchedule(common_job.ImageScanAllJob)
+	sc.getSchedule(common_job.ImageScanAllJob, sc.GetString("schedule_name"))
+}
+
+// ListSchedules lists the default and all the named schedules of scan all.
+func (sc *ScanAllAPI) ListSchedules() {
+	sc.listSchedules(common_job.ImageScanAllJob)
 }
 
-// List returns the top 10 executions of scan all which includes manual and cron.
+// List returns the executions of scan all which includes manual and cron, the latest 10 ones are
+// returned by default and they can be paged and filtered by the status.
 func (sc *ScanAllAPI) List() {
 	sc.list(common_job.ImageScanAllJob)
 }
+
+// Stop stops the running scan all execution by ID.
+func (sc *ScanAllAPI) Stop() {
+	id, err := sc.GetInt64FromPath(":id")
+	if err != nil {
+		sc.SendBadRequestError(errors.New("invalid ID"))
+		return
+	}
+	sc.stop(id)
+}
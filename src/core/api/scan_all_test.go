@@ -1,10 +1,16 @@
 package api
 
 import (
+	"net/http"
 	"testing"
 
+	"github.com/goharbor/harbor/src/common/dao"
+	common_job "github.com/goharbor/harbor/src/common/job"
+	"github.com/goharbor/harbor/src/core/api/models"
 	"github.com/goharbor/harbor/src/testing/apitests/apilib"
+	"github.com/goharbor/harbor/src/testing/job"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var adminJob002 apilib.AdminJobReq
@@ -36,3 +42,111 @@ func TestScanAllGet(t *testing.T) {
 		assert.Equal(200, code, "Get scan all status should be 200")
 	}
 }
+
+// TestScanAllNamedSchedules tests creating, updating and deleting two coexisting named schedules of scan all
+func TestScanAllNamedSchedules(t *testing.T) {
+	client := &job.MockJobClient{}
+	old := getJobServiceClient
+	getJobServiceClient = func() common_job.Client { return client }
+	defer func() { getJobServiceClient = old }()
+	defer dao.GetOrmer().Raw(`delete from admin_job where job_name = ? and schedule_name in (?, ?)`,
+		common_job.ImageScanAllJob, "weekly", "daily-library").Exec()
+
+	scheduleReq := func(method, scheduleName, scheduleType, cron string) *testingRequest {
+		return &testingRequest{
+			method:     method,
+			url:        "/api/system/scanAll/schedule",
+			credential: sysAdmin,
+			bodyJSON: &models.AdminJobReq{
+				AdminJobSchedule: models.AdminJobSchedule{
+					ScheduleName: scheduleName,
+					Schedule: &models.ScheduleParam{
+						Type: scheduleType,
+						Cron: cron,
+					},
+				},
+			},
+		}
+	}
+	getSchedule := func(scheduleName string) *models.AdminJobSchedule {
+		schedule := &models.AdminJobSchedule{}
+		err := handleAndParse(&testingRequest{
+			method:     http.MethodGet,
+			url:        "/api/system/scanAll/schedule",
+			credential: sysAdmin,
+			queryStruct: struct {
+				ScheduleName string `url:"schedule_name"`
+			}{scheduleName},
+		}, schedule)
+		require.Nil(t, err)
+		return schedule
+	}
+
+	runCodeCheckingCases(t,
+		// create the weekly schedule
+		&codeCheckingCase{
+			request: scheduleReq(http.MethodPost, "weekly", models.ScheduleWeekly, "0 0 0 * * 0"),
+			code:    http.StatusCreated,
+		},
+		// create the daily schedule besides the weekly one
+		&codeCheckingCase{
+			request: scheduleReq(http.MethodPost, "daily-library", models.ScheduleDaily, "0 0 1 * * *"),
+			code:    http.StatusCreated,
+		},
+		// the schedule with the same name can't be created twice
+		&codeCheckingCase{
+			request: scheduleReq(http.MethodPost, "weekly", models.ScheduleWeekly, "0 0 0 * * 1"),
+			code:    http.StatusPreconditionFailed,
+		},
+		// invalid schedule name
+		&codeCheckingCase{
+			request: scheduleReq(http.MethodPost, "Weekly", models.ScheduleWeekly, "0 0 0 * * 0"),
+			code:    http.StatusBadRequest,
+		},
+	)
+
+	assert.Equal(t, "0 0 0 * * 0", getSchedule("weekly").Schedule.Cron)
+	assert.Equal(t, "0 0 1 * * *", getSchedule("daily-library").Schedule.Cron)
+
+	// update the weekly schedule, the daily one is untouched
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: scheduleReq(http.MethodPut, "weekly", models.ScheduleWeekly, "0 0 0 * * 6"),
+		code:    http.StatusOK,
+	})
+	assert.Equal(t, "0 0 0 * * 6", getSchedule("weekly").Schedule.Cron)
+	assert.Equal(t, "0 0 1 * * *", getSchedule("daily-library").Schedule.Cron)
+
+	schedules := []*models.AdminJobSchedule{}
+	require.Nil(t, handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/system/scanAll/schedules",
+		credential: sysAdmin,
+	}, &schedules))
+	names := []string{}
+	for _, s := range schedules {
+		names = append(names, s.ScheduleName)
+	}
+	assert.Subset(t, names, []string{"daily-library", "weekly"})
+
+	// delete the daily schedule, the weekly one is untouched
+	runCodeCheckingCases(t,
+		&codeCheckingCase{
+			request: scheduleReq(http.MethodPut, "daily-library", models.ScheduleNone, ""),
+			code:    http.StatusOK,
+		},
+		// the deleted schedule can't be updated
+		&codeCheckingCase{
+			request: scheduleReq(http.MethodPut, "daily-library", models.ScheduleDaily, "0 0 2 * * *"),
+			code:    http.StatusNotFound,
+		},
+		&codeCheckingCase{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/system/scanAll/schedule?schedule_name=daily-library",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+	)
+	assert.Equal(t, "0 0 0 * * 6", getSchedule("weekly").Schedule.Cron)
+}
@@ -15,6 +15,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -22,8 +23,10 @@ import (
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/pkg/scan/api/scan"
 	dscan "github.com/goharbor/harbor/src/pkg/scan/dao/scan"
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
 	"github.com/goharbor/harbor/src/pkg/scan/report"
 	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/goharbor/harbor/src/pkg/scan/vuln"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -128,6 +131,7 @@ func (suite *ScanAPITestSuite) TestScanAPIScan() {
 
 // TestScanAPIReport ...
 func (suite *ScanAPITestSuite) TestScanAPIReport() {
+	suite.c.On("GetReportMimeTypes", suite.artifact).Return([]string{}, nil)
 	suite.c.On("GetReport", suite.artifact, []string{v1.MimeTypeNativeReport}).Return([]*dscan.Report{}, nil)
 
 	vulItems := make(map[string]interface{})
@@ -144,6 +148,55 @@ func (suite *ScanAPITestSuite) TestScanAPIReport() {
 	require.NoError(suite.T(), err)
 }
 
+// TestScanAPIReportNegotiation ...
+func (suite *ScanAPITestSuite) TestScanAPIReportNegotiation() {
+	rawMime := "application/vnd.aquasec.trivy.report.os-pkgs.json.v1"
+	nativeReport := &dscan.Report{
+		MimeType: v1.MimeTypeNativeReport,
+		Report:   `{"scanner":{"name":"Trivy","vendor":"Aqua Security","version":"0.1"},"severity":"High","vulnerabilities":[]}`,
+	}
+	rawReport := &dscan.Report{
+		MimeType: rawMime,
+		Report:   `{"Target":"library/hello-world","Vulnerabilities":null}`,
+	}
+
+	suite.c.On("GetReportMimeTypes", suite.artifact).Return([]string{v1.MimeTypeNativeReport, rawMime}, nil)
+	suite.c.On("GetReport", suite.artifact, []string{v1.MimeTypeNativeReport}).Return([]*dscan.Report{nativeReport}, nil)
+	suite.c.On("GetReport", suite.artifact, []string{rawMime}).Return([]*dscan.Report{rawReport}, nil)
+
+	request := func(accept string) *testingRequest {
+		header := make(http.Header)
+		header.Add("Accept", accept)
+		return &testingRequest{
+			url:        scanBaseURL,
+			method:     http.MethodGet,
+			credential: projDeveloper,
+			header:     header,
+		}
+	}
+
+	// the normalized format of Harbor
+	vulItems := make(map[string]*vuln.Report)
+	err := handleAndParse(request(v1.MimeTypeNativeReport), &vulItems)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(vulItems))
+	require.NotNil(suite.T(), vulItems[v1.MimeTypeNativeReport])
+	assert.Equal(suite.T(), "Trivy", vulItems[v1.MimeTypeNativeReport].Scanner.Name)
+
+	// the raw report of the scanner
+	rawItems := make(map[string]json.RawMessage)
+	err = handleAndParse(request(rawMime), &rawItems)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(rawItems))
+	assert.JSONEq(suite.T(), rawReport.Report, string(rawItems[rawMime]))
+
+	// no report matches
+	runCodeCheckingCases(suite.T(), &codeCheckingCase{
+		request: request("application/xml"),
+		code:    http.StatusNotAcceptable,
+	})
+}
+
 // TestScanAPILog ...
 func (suite *ScanAPITestSuite) TestScanAPILog() {
 	suite.c.On("GetScanLog", "the-uuid-001").Return([]byte(`{"log": "this is my log"}`), nil)
@@ -170,7 +223,7 @@ type MockScanAPIController struct {
 }
 
 // Scan ...
-func (msc *MockScanAPIController) Scan(artifact *v1.Artifact) error {
+func (msc *MockScanAPIController) Scan(artifact *v1.Artifact, options ...scan.Option) error {
 	args := msc.Called(artifact)
 
 	return args.Error(0)
@@ -186,6 +239,26 @@ func (msc *MockScanAPIController) GetReport(artifact *v1.Artifact, mimeTypes []s
 	return args.Get(0).([]*dscan.Report), args.Error(1)
 }
 
+func (msc *MockScanAPIController) BenchmarkScanner(reg *scanner.Registration, testDigest string) (*scan.BenchmarkResult, error) {
+	args := msc.Called(reg, testDigest)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*scan.BenchmarkResult), args.Error(1)
+}
+
+func (msc *MockScanAPIController) GetReportMimeTypes(artifact *v1.Artifact) ([]string, error) {
+	args := msc.Called(artifact)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (msc *MockScanAPIController) GetSummary(artifact *v1.Artifact, mimeTypes []string, options ...report.Option) (map[string]interface{}, error) {
 	args := msc.Called(artifact, mimeTypes, options)
 
@@ -17,10 +17,13 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/goharbor/harbor/src/pkg/q"
+	"github.com/goharbor/harbor/src/pkg/scan/api/scan"
 	s "github.com/goharbor/harbor/src/pkg/scan/api/scanner"
 	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
+	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
 	"github.com/pkg/errors"
 )
 
@@ -269,6 +272,59 @@ func (sa *ScannerAPI) Ping() {
 	}
 }
 
+// Benchmark scans the benchmark artifact with all the enabled scanners concurrently
+// and returns the performance of them
+func (sa *ScannerAPI) Benchmark() {
+	digest, err := digestFunc(scan.BenchmarkRepository, scan.BenchmarkTag, sa.SecurityCtx.GetUsername())
+	if err != nil {
+		sa.SendPreconditionFailedError(errors.Wrapf(err, "scanner API: benchmark: get digest of %s:%s",
+			scan.BenchmarkRepository, scan.BenchmarkTag))
+		return
+	}
+
+	all, err := sa.c.ListRegistrations(&q.Query{})
+	if err != nil {
+		sa.SendInternalServerError(errors.Wrap(err, "scanner API: benchmark"))
+		return
+	}
+
+	registrations := make([]*scanner.Registration, 0, len(all))
+	for _, r := range all {
+		if !r.Disabled {
+			registrations = append(registrations, r)
+		}
+	}
+
+	results := make([]*scan.BenchmarkResult, len(registrations))
+	wg := &sync.WaitGroup{}
+	wg.Add(len(registrations))
+	for i, r := range registrations {
+		go func(i int, r *scanner.Registration) {
+			defer wg.Done()
+
+			result, err := scan.DefaultController.BenchmarkScanner(r, digest)
+			if err != nil {
+				result = &scan.BenchmarkResult{
+					ScannerName: r.Name,
+					Status:      scan.BenchmarkStatusError,
+					Error:       err.Error(),
+				}
+			}
+			results[i] = result
+		}(i, r)
+	}
+	wg.Wait()
+
+	sa.Data["json"] = results
+	sa.ServeJSON()
+}
+
+// ClientPool returns the statistics of the scanner client pool for debugging
+func (sa *ScannerAPI) ClientPool() {
+	sa.Data["json"] = v1.DefaultClientPool.Stats()
+	sa.ServeJSON()
+}
+
 // get the specified scanner
 func (sa *ScannerAPI) get() *scanner.Registration {
 	uid := sa.GetStringFromPath(":uuid")
@@ -319,4 +375,9 @@ func getChanges(e *scanner.Registration, eChange *scanner.Registration) {
 	e.AccessCredential = eChange.AccessCredential
 	e.Disabled = eChange.Disabled
 	e.SkipCertVerify = eChange.SkipCertVerify
+	e.ReportCheckTimeout = eChange.ReportCheckTimeout
+	e.ReportCheckInterval = eChange.ReportCheckInterval
+	e.MaxConcurrentScans = eChange.MaxConcurrentScans
+	e.MaxRetries = eChange.MaxRetries
+	e.RetryDisabled = eChange.RetryDisabled
 }
@@ -22,8 +22,10 @@ import (
 	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
 
 	"github.com/goharbor/harbor/src/pkg/q"
+	"github.com/goharbor/harbor/src/pkg/scan/api/scan"
 	sc "github.com/goharbor/harbor/src/pkg/scan/api/scanner"
 	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -258,6 +260,78 @@ func (suite *ScannerAPITestSuite) TestScannerAPISetDefault() {
 	})
 }
 
+// TestScannerAPIBenchmark tests the benchmark API
+func (suite *ScannerAPITestSuite) TestScannerAPIBenchmark() {
+	originalDigestGetter := digestFunc
+	digestFunc = func(repo, tag string, username string) (string, error) {
+		return "sha256:alpine", nil
+	}
+	originalC := scan.DefaultController
+	scanC := &MockScanAPIController{}
+	scan.DefaultController = scanC
+	defer func() {
+		digestFunc = originalDigestGetter
+		scan.DefaultController = originalC
+	}()
+
+	enabled := &scanner.Registration{UUID: "enabled", Name: "enabled"}
+	failed := &scanner.Registration{UUID: "failed", Name: "failed"}
+	disabled := &scanner.Registration{UUID: "disabled", Name: "disabled", Disabled: true}
+	suite.mockC.On("ListRegistrations", &q.Query{}).Return([]*scanner.Registration{enabled, failed, disabled}, nil)
+	scanC.On("BenchmarkScanner", enabled, "sha256:alpine").Return(&scan.BenchmarkResult{
+		ScannerName: "enabled",
+		DurationMS:  100,
+		CVECount:    2,
+		Status:      scan.BenchmarkStatusSuccess,
+	}, nil)
+	scanC.On("BenchmarkScanner", failed, "sha256:alpine").Return(nil, errors.New("failed"))
+
+	runCodeCheckingCases(suite.T(), &codeCheckingCase{
+		request: &testingRequest{
+			url:        "/api/system/scanner/benchmark",
+			method:     http.MethodPost,
+			credential: nonSysAdmin,
+		},
+		code: http.StatusForbidden,
+	})
+
+	results := make([]*scan.BenchmarkResult, 0)
+	err := handleAndParse(&testingRequest{
+		url:        "/api/system/scanner/benchmark",
+		method:     http.MethodPost,
+		credential: sysAdmin,
+	}, &results)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	assert.Equal(suite.T(), "enabled", results[0].ScannerName)
+	assert.Equal(suite.T(), scan.BenchmarkStatusSuccess, results[0].Status)
+	assert.Equal(suite.T(), 2, results[0].CVECount)
+	assert.Equal(suite.T(), "failed", results[1].ScannerName)
+	assert.Equal(suite.T(), scan.BenchmarkStatusError, results[1].Status)
+	scanC.AssertNotCalled(suite.T(), "BenchmarkScanner", disabled, "sha256:alpine")
+}
+
+// TestScannerAPIClientPool tests the API of getting the client pool statistics
+func (suite *ScannerAPITestSuite) TestScannerAPIClientPool() {
+	runCodeCheckingCases(suite.T(), &codeCheckingCase{
+		request: &testingRequest{
+			url:        "/api/system/scanner/client-pool",
+			method:     http.MethodGet,
+			credential: nonSysAdmin,
+		},
+		code: http.StatusForbidden,
+	})
+
+	stats := &v1.PoolStats{}
+	err := handleAndParse(&testingRequest{
+		url:        "/api/system/scanner/client-pool",
+		method:     http.MethodGet,
+		credential: sysAdmin,
+	}, stats)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), stats.Size >= 0)
+}
+
 func (suite *ScannerAPITestSuite) mockQuery(r *scanner.Registration) {
 	kw := make(map[string]interface{}, 1)
 	kw["name"] = r.Name
@@ -378,3 +452,32 @@ func (m *MockScannerAPIController) IsScannerAvailable(projectID int64) (bool, er
 
 	return args.Bool(0), args.Error(1)
 }
+
+// SetScannerAssignments ...
+func (m *MockScannerAPIController) SetScannerAssignments(projectID int64, assignments []sc.Assignment) error {
+	args := m.Called(projectID, assignments)
+
+	return args.Error(0)
+}
+
+// GetScannerAssignments ...
+func (m *MockScannerAPIController) GetScannerAssignments(projectID int64) ([]sc.Assignment, error) {
+	args := m.Called(projectID)
+	assignments := args.Get(0)
+	if assignments == nil {
+		return nil, args.Error(1)
+	}
+
+	return assignments.([]sc.Assignment), nil
+}
+
+// SelectForArtifact ...
+func (m *MockScannerAPIController) SelectForArtifact(assignments []sc.Assignment, artifact *v1.Artifact) (*scanner.Registration, error) {
+	args := m.Called(assignments, artifact)
+	s := args.Get(0)
+	if s == nil {
+		return nil, args.Error(1)
+	}
+
+	return s.(*scanner.Registration), nil
+}
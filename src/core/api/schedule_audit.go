@@ -0,0 +1,81 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	common_job "github.com/goharbor/harbor/src/common/job"
+)
+
+const (
+	defaultScheduleAuditLimit = 50
+	maxScheduleAuditLimit     = 500
+)
+
+// the short names of the admin jobs accepted by the "job_name" parameter
+var scheduleAuditJobNames = map[string]string{
+	"gc":      common_job.ImageGC,
+	"scanall": common_job.ImageScanAllJob,
+}
+
+// ScheduleAuditAPI handles the request to /api/system/schedule-audit
+type ScheduleAuditAPI struct {
+	BaseController
+}
+
+// Prepare validates the user, it needs the system admin permission.
+func (s *ScheduleAuditAPI) Prepare() {
+	s.BaseController.Prepare()
+	if !s.SecurityCtx.IsAuthenticated() {
+		s.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+	if !s.SecurityCtx.IsSysAdmin() {
+		s.SendForbiddenError(errors.New(s.SecurityCtx.GetUsername()))
+		return
+	}
+}
+
+// List returns the latest changes of the admin job schedules, the job can be
+// specified by the "job_name" parameter, e.g. "gc" or "IMAGE_GC"
+func (s *ScheduleAuditAPI) List() {
+	jobName := s.GetString("job_name")
+	if len(jobName) > 0 {
+		if name, exist := scheduleAuditJobNames[strings.ToLower(jobName)]; exist {
+			jobName = name
+		} else if jobName != common_job.ImageGC && jobName != common_job.ImageScanAllJob {
+			s.SendBadRequestError(fmt.Errorf("unsupported job name %s", jobName))
+			return
+		}
+	}
+
+	limit, err := s.GetInt("limit", defaultScheduleAuditLimit)
+	if err != nil || limit <= 0 || limit > maxScheduleAuditLimit {
+		s.SendBadRequestError(fmt.Errorf("invalid limit %s, it must be an integer between 1 and %d",
+			s.GetString("limit"), maxScheduleAuditLimit))
+		return
+	}
+
+	entries, err := dao.GetScheduleAuditLog(jobName, limit)
+	if err != nil {
+		s.SendInternalServerError(fmt.Errorf("failed to get the schedule audit log: %v", err))
+		return
+	}
+	s.WriteJSONData(entries)
+}
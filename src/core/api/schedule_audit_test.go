@@ -0,0 +1,170 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	common_job "github.com/goharbor/harbor/src/common/job"
+	common_models "github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleAuditOfGC(t *testing.T) {
+	require.Nil(t, dao.ClearTable("schedule_audit_log"))
+	defer dao.ClearTable("schedule_audit_log")
+
+	// make sure there is only one GC schedule
+	jobs, err := dao.GetAdminJobs(&common_models.AdminJobQuery{
+		Name: common_job.ImageGC,
+		Kind: common_job.JobKindPeriodic,
+	})
+	require.Nil(t, err)
+	for _, job := range jobs {
+		require.Nil(t, dao.DeleteAdminJob(job.ID))
+	}
+	id, err := dao.AddAdminJob(&common_models.AdminJob{
+		Name: common_job.ImageGC,
+		Kind: common_job.JobKindPeriodic,
+		Cron: `{"type":"Daily","cron":"0 0 0 * * *"}`,
+	})
+	require.Nil(t, err)
+	require.Nil(t, dao.SetAdminJobUUID(id, "u-1234-5678-9012"))
+
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPut,
+			url:        "/api/system/gc/schedule",
+			credential: sysAdmin,
+			bodyJSON: &models.AdminJobReq{
+				AdminJobSchedule: models.AdminJobSchedule{
+					Schedule: &models.ScheduleParam{
+						Type: models.ScheduleNone,
+					},
+				},
+			},
+		},
+		code: http.StatusOK,
+	})
+
+	entries := []*common_models.ScheduleAuditEntry{}
+	err = handleAndParse(&testingRequest{
+		method: http.MethodGet,
+		url:    "/api/system/schedule-audit",
+		queryStruct: struct {
+			JobName string `url:"job_name"`
+			Limit   int    `url:"limit"`
+		}{
+			JobName: "gc",
+			Limit:   50,
+		},
+		credential: sysAdmin,
+	}, &entries)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, sysAdmin.Name, entries[0].Actor)
+	assert.Equal(t, common_job.ImageGC, entries[0].JobName)
+	assert.Equal(t, "0 0 0 * * *", entries[0].OldCron)
+	assert.Equal(t, "", entries[0].NewCron)
+
+	// the creation of the schedule is audited as well
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPost,
+			url:        "/api/system/gc/schedule",
+			credential: sysAdmin,
+			bodyJSON: &models.AdminJobReq{
+				AdminJobSchedule: models.AdminJobSchedule{
+					Schedule: &models.ScheduleParam{
+						Type: models.ScheduleDaily,
+						Cron: "0 0 1 * * *",
+					},
+				},
+			},
+		},
+		code: http.StatusCreated,
+	})
+	defer func() {
+		jobs, err := dao.GetAdminJobs(&common_models.AdminJobQuery{
+			Name: common_job.ImageGC,
+			Kind: common_job.JobKindPeriodic,
+		})
+		require.Nil(t, err)
+		for _, job := range jobs {
+			require.Nil(t, dao.DeleteAdminJob(job.ID))
+		}
+	}()
+
+	entries, err = dao.GetScheduleAuditLog(common_job.ImageGC, 0)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, sysAdmin.Name, entries[0].Actor)
+	assert.Equal(t, "", entries[0].OldCron)
+	assert.Equal(t, "0 0 1 * * *", entries[0].NewCron)
+}
+
+func TestScheduleAuditAPI(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/system/schedule-audit",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/system/schedule-audit",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, unsupported job name
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/system/schedule-audit?job_name=replication",
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, invalid limit
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/system/schedule-audit?limit=0",
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/system/schedule-audit?job_name=scanall",
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+}
@@ -0,0 +1,139 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/token"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/pkg/q"
+	"github.com/goharbor/harbor/src/pkg/robot"
+	"github.com/goharbor/harbor/src/pkg/robot/model"
+)
+
+const (
+	// the interval to check whether the rotation of the token signing key can be completed
+	keyRotationCheckInterval = 10 * time.Minute
+	// the page size used to list the robot accounts when checking the rotation
+	keyRotationRobotPageSize = 500
+	// keyRotationMaxOverlap bounds how long the retiring key is accepted, the robot tokens signed by it
+	// which are still valid are rejected afterwards and must be rotated
+	keyRotationMaxOverlap = 7 * 24 * time.Hour
+)
+
+// the func to list the robot accounts, it's a variable for testing
+var listRobotAccounts = robot.RobotCtr.ListRobotAccount
+
+// SystemKeyAPI handles the request to /api/system/keys
+type SystemKeyAPI struct {
+	BaseController
+}
+
+// Prepare validates the user, it needs the system admin permission.
+func (s *SystemKeyAPI) Prepare() {
+	s.BaseController.Prepare()
+	if !s.SecurityCtx.IsAuthenticated() {
+		s.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+	if !s.SecurityCtx.IsSysAdmin() {
+		s.SendForbiddenError(errors.New(s.SecurityCtx.GetUsername()))
+		return
+	}
+}
+
+// Rotate generates a new key to sign the tokens issued by Harbor. The old key is still accepted
+// to verify the tokens until all the robot tokens signed by it have expired, which is
+// checked periodically in background, or until keyRotationMaxOverlap has passed.
+func (s *SystemKeyAPI) Rotate() {
+	if err := token.DefaultKeyRotationManager.StartRotation(); err != nil {
+		if err == token.ErrRotationInProgress {
+			s.SendConflictError(err)
+			return
+		}
+		s.SendInternalServerError(err)
+		return
+	}
+	s.Ctx.ResponseWriter.WriteHeader(http.StatusAccepted)
+}
+
+// WatchKeyRotation completes the rotation of the token signing key in background once the
+// tokens signed by the retiring key have all expired, until the stop channel is closed
+func WatchKeyRotation(stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(keyRotationCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := completeKeyRotation(time.Now()); err != nil {
+					log.Warningf("failed to complete the rotation of the token signing key: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// completeKeyRotation completes the rotation in progress if none of the tokens signed by
+// the retiring key is still valid at the time or the max overlap has passed
+func completeKeyRotation(now time.Time) error {
+	mgr := token.DefaultKeyRotationManager
+	startedAt, inProgress, err := mgr.RotationStartedAt()
+	if err != nil || !inProgress {
+		return err
+	}
+	if now.Sub(startedAt) >= keyRotationMaxOverlap {
+		log.Warningf("the rotation of the token signing key started at %v exceeds the max overlap %v, the robot tokens signed by the retiring key are rejected",
+			startedAt, keyRotationMaxOverlap)
+		return mgr.CompleteRotation()
+	}
+	// the impersonation and override tokens are short-lived
+	if now.Sub(startedAt) < impersonationTTL || now.Sub(startedAt) < token.OverrideMaxTTL {
+		return nil
+	}
+	// the robot tokens aren't stored, so they can't be reissued by the new key, wait
+	// for the ones issued before the rotation to expire instead. The robots whose tokens
+	// outlive the max overlap must get new ones via the rotation of the robot token
+	for page := int64(1); ; page++ {
+		robots, err := listRobotAccounts(&q.Query{
+			PageNumber: page,
+			PageSize:   keyRotationRobotPageSize,
+		})
+		if err != nil {
+			return err
+		}
+		for _, r := range robots {
+			if signedByRetiringKey(r, startedAt) && r.ExpiresAt > now.Unix() {
+				log.Debugf("the token of robot account %d signed by the retiring key is still valid", r.ID)
+				return nil
+			}
+		}
+		if len(robots) < keyRotationRobotPageSize {
+			break
+		}
+	}
+	return mgr.CompleteRotation()
+}
+
+// signedByRetiringKey returns whether the token of the robot may be signed by the retiring key,
+// the other core instances keep signing with it until their cache of the keys expires
+func signedByRetiringKey(r *model.Robot, rotationStartedAt time.Time) bool {
+	return r.CreationTime.Before(rotationStartedAt.Add(token.KeyCacheTTL))
+}
@@ -0,0 +1,126 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/token"
+	"github.com/goharbor/harbor/src/pkg/q"
+	"github.com/goharbor/harbor/src/pkg/robot/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemKeyAPIRotate(t *testing.T) {
+	url := "/api/system/keys/rotate"
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 202
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: sysAdmin,
+			},
+			code: http.StatusAccepted,
+		},
+		// 409, the previous rotation isn't completed
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: sysAdmin,
+			},
+			code: http.StatusConflict,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	startedAt, inProgress, err := token.DefaultKeyRotationManager.RotationStartedAt()
+	require.Nil(t, err)
+	require.True(t, inProgress)
+
+	robots := []*model.Robot{
+		{
+			ID:           1,
+			CreationTime: startedAt.Add(-time.Hour),
+			ExpiresAt:    startedAt.Add(time.Hour).Unix(),
+		},
+		{
+			ID:           2,
+			CreationTime: startedAt.Add(time.Hour),
+			ExpiresAt:    startedAt.Add(48 * time.Hour).Unix(),
+		},
+	}
+	// the token of robot 3 outlives the max overlap
+	longLived := &model.Robot{
+		ID:           3,
+		CreationTime: startedAt.Add(-time.Hour),
+		ExpiresAt:    startedAt.Add(30 * 24 * time.Hour).Unix(),
+	}
+	list := listRobotAccounts
+	defer func() {
+		listRobotAccounts = list
+	}()
+	listRobotAccounts = func(query *q.Query) ([]*model.Robot, error) {
+		return robots, nil
+	}
+
+	// the impersonation and override tokens signed by the retiring key are still valid
+	require.Nil(t, completeKeyRotation(startedAt.Add(time.Minute)))
+	assert.True(t, token.DefaultKeyRotationManager.IsInGracePeriod())
+
+	// the token of the robot 1 is signed by the retiring key and still valid
+	require.Nil(t, completeKeyRotation(startedAt.Add(30*time.Minute)))
+	assert.True(t, token.DefaultKeyRotationManager.IsInGracePeriod())
+
+	// the token of the robot 3 is still valid
+	robots = append(robots, longLived)
+	require.Nil(t, completeKeyRotation(startedAt.Add(2*time.Hour)))
+	assert.True(t, token.DefaultKeyRotationManager.IsInGracePeriod())
+
+	// the retiring key is dropped once the max overlap has passed
+	require.Nil(t, completeKeyRotation(startedAt.Add(keyRotationMaxOverlap)))
+	assert.False(t, token.DefaultKeyRotationManager.IsInGracePeriod())
+
+	// the token of the robot 1 expires, the one of robot 2 is signed by the new key
+	robots = robots[:2]
+	require.Nil(t, token.DefaultKeyRotationManager.StartRotation())
+	startedAt, _, err = token.DefaultKeyRotationManager.RotationStartedAt()
+	require.Nil(t, err)
+	robots[0].ExpiresAt = startedAt.Add(time.Hour).Unix()
+	robots[1].CreationTime = startedAt.Add(time.Hour)
+	require.Nil(t, completeKeyRotation(startedAt.Add(2*time.Hour)))
+	assert.False(t, token.DefaultKeyRotationManager.IsInGracePeriod())
+}
@@ -0,0 +1,249 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/core/promgr"
+	"github.com/goharbor/harbor/src/pkg/q"
+	"github.com/goharbor/harbor/src/pkg/robot"
+	"github.com/goharbor/harbor/src/pkg/robot/model"
+	"github.com/pkg/errors"
+)
+
+// SystemRobotAPI handles the requests to /api/system/robots, the system level robots aren't
+// bound to any project and can only be managed by the system admin
+type SystemRobotAPI struct {
+	BaseController
+	ctr   robot.Controller
+	robot *model.Robot
+}
+
+// Prepare validates the user, it needs the system admin permission.
+func (r *SystemRobotAPI) Prepare() {
+	r.BaseController.Prepare()
+	if !r.SecurityCtx.IsAuthenticated() {
+		r.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+	if !r.SecurityCtx.IsSysAdmin() {
+		r.SendForbiddenError(errors.New(r.SecurityCtx.GetUsername()))
+		return
+	}
+	r.ctr = robot.RobotCtr
+
+	if len(r.GetStringFromPath(":id")) == 0 {
+		return
+	}
+	id, err := r.GetInt64FromPath(":id")
+	if err != nil || id <= 0 {
+		r.SendBadRequestError(fmt.Errorf("invalid robot ID: %s", r.GetStringFromPath(":id")))
+		return
+	}
+	robot, err := r.ctr.GetRobotAccount(id)
+	if err != nil {
+		r.SendInternalServerError(errors.Wrap(err, "system robot API: get robot"))
+		return
+	}
+	if robot == nil || !robot.IsSystemLevel() || !robot.Visible {
+		r.SendNotFoundError(fmt.Errorf("system robot %d not found", id))
+		return
+	}
+	r.robot = robot
+}
+
+// Post creates a system level robot
+func (r *SystemRobotAPI) Post() {
+	var robotReq model.RobotCreate
+	isValid, err := r.DecodeJSONReqAndValidate(&robotReq)
+	if !isValid {
+		r.SendBadRequestError(err)
+		return
+	}
+	robotReq.Visible = true
+	robotReq.ProjectID = model.SystemLevelProjectID
+
+	if err := validateSystemRobotReq(r.ProjectMgr, &robotReq); err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+
+	robot, err := r.ctr.CreateRobotAccount(&robotReq)
+	if err != nil {
+		if err == dao.ErrDupRows {
+			r.SendConflictError(errors.New("conflict robot account"))
+			return
+		}
+		r.SendInternalServerError(errors.Wrap(err, "system robot API: post"))
+		return
+	}
+
+	w := r.Ctx.ResponseWriter
+	w.Header().Set("Content-Type", "application/json")
+
+	robotRep := model.RobotRep{
+		Name:  robot.Name,
+		Token: robot.Token,
+	}
+
+	r.Redirect(http.StatusCreated, strconv.FormatInt(robot.ID, 10))
+	r.Data["json"] = robotRep
+	r.ServeJSON()
+}
+
+// List lists the system level robots
+func (r *SystemRobotAPI) List() {
+	query := &q.Query{
+		Keywords: map[string]interface{}{
+			"Visible": true,
+		},
+	}
+	robots, err := r.ctr.ListRobotAccount(query)
+	if err != nil {
+		r.SendInternalServerError(errors.Wrap(err, "system robot API: list"))
+		return
+	}
+	systemRobots := []*model.Robot{}
+	for _, robot := range robots {
+		if robot.IsSystemLevel() {
+			systemRobots = append(systemRobots, robot)
+		}
+	}
+	page, size, err := r.GetPaginationParams()
+	if err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+
+	r.SetPaginationHeader(int64(len(systemRobots)), page, size)
+	r.Data["json"] = systemRobots
+	r.ServeJSON()
+}
+
+// Get gets the system level robot by ID
+func (r *SystemRobotAPI) Get() {
+	r.Data["json"] = r.robot
+	r.ServeJSON()
+}
+
+// Put disables or enables the system level robot
+func (r *SystemRobotAPI) Put() {
+	var robotReq model.RobotCreate
+	if err := r.DecodeJSONReq(&robotReq); err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+
+	r.robot.Disabled = robotReq.Disabled
+
+	if err := r.ctr.UpdateRobotAccount(r.robot); err != nil {
+		r.SendInternalServerError(errors.Wrap(err, "system robot API: update"))
+		return
+	}
+}
+
+// Delete deletes the system level robot
+func (r *SystemRobotAPI) Delete() {
+	if err := r.ctr.DeleteRobotAccount(r.robot.ID); err != nil {
+		r.SendInternalServerError(errors.Wrap(err, "system robot API: delete"))
+		return
+	}
+}
+
+// RotateToken issues a new token to the system level robot, see RobotAPI.RotateToken
+func (r *SystemRobotAPI) RotateToken() {
+	req, err := decodeTokenRotateReq(&r.BaseController)
+	if err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+	if len(req.Access) > 0 {
+		if err := validateSystemRobotReq(r.ProjectMgr, &model.RobotCreate{Access: req.Access}); err != nil {
+			r.SendBadRequestError(err)
+			return
+		}
+	}
+
+	rb, err := r.ctr.RotateRobotToken(r.robot, req.Access, time.Duration(req.OverlapSeconds)*time.Second)
+	if err != nil {
+		if err == robot.ErrNoAccess {
+			r.SendBadRequestError(err)
+			return
+		}
+		r.SendInternalServerError(errors.Wrap(err, "system robot API: rotate token"))
+		return
+	}
+
+	r.Data["json"] = model.RobotRep{
+		Name:  rb.Name,
+		Token: rb.Token,
+	}
+	r.ServeJSON()
+}
+
+// validateSystemRobotReq checks the access of the system level robot, every policy must be either
+// scoped to an existing project, e.g. "/project/1/repository", or to all projects, e.g. "/project/*/repository"
+func validateSystemRobotReq(pm promgr.ProjectManager, robotReq *model.RobotCreate) error {
+	if len(robotReq.Access) == 0 {
+		return errors.New("access required")
+	}
+
+	for _, policy := range robotReq.Access {
+		var namespace rbac.Namespace
+		resource := policy.Resource
+		if strings.HasPrefix(resource.String(), model.AllProjects.String()+"/") {
+			// check the policy against a project to make sure the resource and action are supported
+			namespace = rbac.NewProjectNamespace(model.SystemLevelProjectID)
+			sub, _ := resource.RelativeTo(model.AllProjects)
+			resource = namespace.Resource(sub)
+		} else {
+			ns, err := resource.GetNamespace()
+			if err != nil {
+				return fmt.Errorf("invalid resource %s", policy.Resource)
+			}
+			exist, err := pm.Exists(ns.Identity())
+			if err != nil {
+				return errors.Wrap(err, "failed to check the existence of project")
+			}
+			if !exist {
+				return fmt.Errorf("project %v of resource %s not found", ns.Identity(), policy.Resource)
+			}
+			namespace = ns
+		}
+
+		if !hasProjectPolicy(namespace, &rbac.Policy{Resource: resource, Action: policy.Action, Effect: policy.Effect}) {
+			return fmt.Errorf("%s action of %s resource not exist", policy.Action, policy.Resource)
+		}
+	}
+
+	return nil
+}
+
+func hasProjectPolicy(namespace rbac.Namespace, policy *rbac.Policy) bool {
+	for _, p := range project.GetAllPolicies(namespace) {
+		if p.String() == policy.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/pkg/robot"
+	"github.com/goharbor/harbor/src/pkg/robot/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var systemRobotPath = "/api/system/robots"
+
+func TestSystemRobotAPI(t *testing.T) {
+	projectRobot, err := robot.RobotCtr.CreateRobotAccount(&model.RobotCreate{
+		Name:      "system-robot-api-project",
+		ProjectID: 1,
+		Visible:   true,
+		Access:    []*rbac.Policy{{Resource: "/project/1/repository", Action: rbac.ActionPull}},
+	})
+	require.Nil(t, err)
+	defer robot.RobotCtr.DeleteRobotAccount(projectRobot.ID)
+
+	access := []*rbac.Policy{
+		{Resource: "/project/1/repository", Action: rbac.ActionPush},
+		{Resource: "/project/*/repository", Action: rbac.ActionPull},
+	}
+	var id int64
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    systemRobotPath,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        systemRobotPath,
+				bodyJSON:   &model.RobotCreate{Name: "system", Access: access},
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 403, the project admin isn't allowed either
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        systemRobotPath,
+				credential: projAdmin4Robot,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, no access
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        systemRobotPath,
+				bodyJSON:   &model.RobotCreate{Name: "system"},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, project not found
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    systemRobotPath,
+				bodyJSON: &model.RobotCreate{
+					Name:   "system",
+					Access: []*rbac.Policy{{Resource: "/project/10000/repository", Action: rbac.ActionPull}},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, resource not exist
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    systemRobotPath,
+				bodyJSON: &model.RobotCreate{
+					Name:   "system",
+					Access: []*rbac.Policy{{Resource: "/project/*/foo", Action: rbac.ActionPull}},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, invalid resource
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    systemRobotPath,
+				bodyJSON: &model.RobotCreate{
+					Name:   "system",
+					Access: []*rbac.Policy{{Resource: "/system/repository", Action: rbac.ActionPull}},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 201
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        systemRobotPath,
+				bodyJSON:   &model.RobotCreate{Name: "system", Description: "system robot", Access: access},
+				credential: sysAdmin,
+			},
+			code: http.StatusCreated,
+			postFunc: func(resp *httptest.ResponseRecorder) error {
+				id, err = parseResourceID(resp)
+				return err
+			},
+		},
+		// 409
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        systemRobotPath,
+				bodyJSON:   &model.RobotCreate{Name: "system", Access: access},
+				credential: sysAdmin,
+			},
+			code: http.StatusConflict,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+	require.NotZero(t, id)
+	defer robot.RobotCtr.DeleteRobotAccount(id)
+
+	// only the system level robots are listed
+	robots := []*model.Robot{}
+	require.Nil(t, handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        systemRobotPath,
+		credential: sysAdmin,
+	}, &robots))
+	require.Len(t, robots, 1)
+	assert.Equal(t, id, robots[0].ID)
+	assert.Equal(t, "robot$system", robots[0].Name)
+
+	cases = []*codeCheckingCase{
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        fmt.Sprintf("%s/%d", systemRobotPath, id),
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 404, the project robot isn't managed here
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        fmt.Sprintf("%s/%d", systemRobotPath, projectRobot.ID),
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        fmt.Sprintf("%s/%d", systemRobotPath, projectRobot.ID),
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 404, the system robot isn't managed by the project robot API
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        fmt.Sprintf("%s/%d", robotPath, id),
+				credential: projAdmin4Robot,
+			},
+			code: http.StatusNotFound,
+		},
+		// 200, rotate the token
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        fmt.Sprintf("%s/%d/token", systemRobotPath, id),
+				bodyJSON:   &model.RobotTokenRotate{OverlapSeconds: 60},
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 400, the wildcard is allowed but the resource must exist
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    fmt.Sprintf("%s/%d/token", systemRobotPath, id),
+				bodyJSON: &model.RobotTokenRotate{
+					Access: []*rbac.Policy{{Resource: "/project/*/foo", Action: rbac.ActionPull}},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200, disable
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        fmt.Sprintf("%s/%d", systemRobotPath, id),
+				bodyJSON:   &model.RobotCreate{Disabled: true},
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	rb, err := robot.RobotCtr.GetRobotAccount(id)
+	require.Nil(t, err)
+	require.NotNil(t, rb)
+	assert.True(t, rb.Disabled)
+	assert.True(t, rb.IsSystemLevel())
+
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodDelete,
+			url:        fmt.Sprintf("%s/%d", systemRobotPath, id),
+			credential: sysAdmin,
+		},
+		code: http.StatusOK,
+	})
+	rb, err = robot.RobotCtr.GetRobotAccount(id)
+	require.Nil(t, err)
+	assert.Nil(t, rb)
+}
@@ -0,0 +1,72 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/secret"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/config"
+)
+
+// the min length of the internal secret
+const minSecretLength = 16
+
+// SystemSecretAPI handles the request to /api/system/secrets
+type SystemSecretAPI struct {
+	BaseController
+}
+
+type secretRotationReq struct {
+	Secret string `json:"secret"`
+}
+
+// Prepare validates the user, it needs the system admin permission.
+func (s *SystemSecretAPI) Prepare() {
+	s.BaseController.Prepare()
+	if !s.SecurityCtx.IsAuthenticated() {
+		s.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+	if !s.SecurityCtx.IsSysAdmin() {
+		s.SendForbiddenError(errors.New(s.SecurityCtx.GetUsername()))
+		return
+	}
+}
+
+// Rotate replaces the secret of jobservice with the new one in the request, the previous
+// secret is still accepted within the configured grace period so that the internal calls
+// keep working until jobservice is updated with the new secret. The secret is persisted and
+// loaded by the other core instances within config.InternalSecretSyncInterval.
+func (s *SystemSecretAPI) Rotate() {
+	req := &secretRotationReq{}
+	if err := s.DecodeJSONReq(req); err != nil {
+		s.SendBadRequestError(err)
+		return
+	}
+	if len(req.Secret) < minSecretLength {
+		s.SendBadRequestError(fmt.Errorf("the secret must contain at least %d characters", minSecretLength))
+		return
+	}
+	grace := config.InternalSecretGracePeriod()
+	if err := config.RotateJobserviceSecret(req.Secret, grace); err != nil {
+		s.SendInternalServerError(fmt.Errorf("failed to rotate the secret of %s: %v", secret.JobserviceUser, err))
+		return
+	}
+	log.Infof("the secret of %s is rotated by %s, the previous one is valid in %v",
+		secret.JobserviceUser, s.SecurityCtx.GetUsername(), grace)
+}
@@ -0,0 +1,91 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/secret"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemSecretAPIRotate(t *testing.T) {
+	previous := config.JobserviceSecret()
+	defer config.SecretStore.Rotate(secret.JobserviceUser, previous, 0)
+	defer dao.GetOrmer().Raw(`delete from internal_secret`).Exec()
+
+	url := "/api/system/secrets/rotate"
+	newSecret := "new-jobservice-secret"
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method:   http.MethodPost,
+				url:      url,
+				bodyJSON: &secretRotationReq{Secret: newSecret},
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				bodyJSON:   &secretRotationReq{Secret: newSecret},
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, too short
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				bodyJSON:   &secretRotationReq{Secret: "short"},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				bodyJSON:   &secretRotationReq{Secret: newSecret},
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	// both the new and the previous secrets are valid within the grace period
+	assert.Equal(t, secret.JobserviceUser, config.SecretStore.GetUsername(newSecret))
+	assert.Equal(t, secret.JobserviceUser, config.SecretStore.GetUsername(previous))
+
+	// the rotated secrets are loaded by the other core instances
+	secrets, err := dao.GetInternalSecrets(secret.JobserviceUser)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(secrets))
+	config.SecretStore.Rotate(secret.JobserviceUser, "secret-of-other-instance", 0)
+	require.Nil(t, config.LoadJobserviceSecrets())
+	assert.Equal(t, secret.JobserviceUser, config.SecretStore.GetUsername(newSecret))
+	assert.Equal(t, secret.JobserviceUser, config.SecretStore.GetUsername(previous))
+	assert.False(t, config.SecretStore.IsValid("secret-of-other-instance"))
+}
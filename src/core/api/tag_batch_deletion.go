@@ -0,0 +1,108 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	commonhttp "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	notarymodel "github.com/goharbor/harbor/src/common/utils/notary/model"
+	"github.com/goharbor/harbor/src/core/config"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+)
+
+// the max count of the tags deleted in one batch
+const maxTagsDeletionBatchSize = 100
+
+// deleteTagsInBatch deletes the tags of the repository with one registry client, the failure of one tag
+// doesn't stop deleting the others and the result of each tag is returned
+func (ra *RepositoryAPI) deleteTagsInBatch(project *models.Project, repoName string, tags []string) {
+	if len(tags) > maxTagsDeletionBatchSize {
+		ra.SendBadRequestError(fmt.Errorf("too many tags, at most %d tags can be deleted in one batch", maxTagsDeletionBatchSize))
+		return
+	}
+
+	rc, err := coreutils.NewRepositoryClientForLocal(ra.SecurityCtx.GetUsername(), repoName)
+	if err != nil {
+		log.Errorf("error occurred while initializing repository client for %s: %v", repoName, err)
+		ra.SendInternalServerError(errors.New("internal error"))
+		return
+	}
+
+	var signedTags map[string][]notarymodel.Target
+	if config.WithNotary() {
+		signedTags, err = getSignatures(ra.SecurityCtx.GetUsername(), repoName)
+		if err != nil {
+			ra.SendInternalServerError(fmt.Errorf(
+				"failed to get signatures for repository %s: %v", repoName, err))
+			return
+		}
+	}
+
+	result := &models.TagsDeletionResult{
+		Deleted:  []string{},
+		NotFound: []string{},
+		Failed:   map[string]string{},
+	}
+	visited := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		if visited[t] {
+			continue
+		}
+		visited[t] = true
+
+		if len(signedTags) > 0 {
+			digest, exist, err := rc.ManifestExist(t)
+			if err != nil {
+				log.Errorf("Failed to Check the digest of tag: %s, error: %v", t, err)
+				result.Failed[t] = err.Error()
+				continue
+			}
+			if !exist {
+				result.NotFound = append(result.NotFound, t)
+				continue
+			}
+			if _, ok := signedTags[digest]; ok {
+				log.Errorf("Found signed tag, repository: %s, tag: %s, deletion will be canceled", repoName, t)
+				result.Failed[t] = fmt.Sprintf("tag %s is signed", t)
+				continue
+			}
+		}
+
+		if err := ra.deleteTag(project, repoName, rc, t); err != nil {
+			if regErr, ok := err.(*commonhttp.Error); ok && regErr.Code == http.StatusNotFound {
+				result.NotFound = append(result.NotFound, t)
+				continue
+			}
+			log.Errorf("failed to delete tag %s:%s: %v", repoName, t, err)
+			result.Failed[t] = err.Error()
+			continue
+		}
+		result.Deleted = append(result.Deleted, t)
+	}
+
+	// the tags have been deleted, the failure of the cleanup doesn't fail the request
+	if len(result.Deleted) > 0 {
+		if err := ra.cleanupDeletedTags(project, repoName, rc, result.Deleted); err != nil {
+			log.Errorf("failed to clean up the deleted tags of repository %s: %v", repoName, err)
+		}
+	}
+
+	ra.WriteJSONData(result)
+}
@@ -0,0 +1,65 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/utils"
+)
+
+// GetReclaimableSizes returns the sizes which would be freed by deleting the tags in the request all together,
+// keyed by the manifest digests. The layers still referenced by the other artifacts under any repository are
+// excluded and the layers shared among the deleting manifests are counted only once. The tags without tracked
+// artifacts are absent in the result.
+func (ra *RepositoryAPI) GetReclaimableSizes() {
+	repoName := ra.GetString(":splat")
+	projectName, _ := utils.ParseRepository(repoName)
+	project, err := ra.ProjectMgr.Get(projectName)
+	if err != nil {
+		ra.ParseAndHandleError(fmt.Sprintf("failed to get the project %s", projectName), err)
+		return
+	}
+	if project == nil {
+		ra.SendNotFoundError(fmt.Errorf("project %s not found", projectName))
+		return
+	}
+
+	if !ra.RequireAuthenticated() ||
+		!ra.RequireProjectAccess(project.ProjectID, rbac.ActionRead, rbac.ResourceRepository) {
+		return
+	}
+
+	request := &models.ReclaimableSizesRequest{}
+	if err := ra.DecodeJSONReq(request); err != nil {
+		ra.SendBadRequestError(err)
+		return
+	}
+	if len(request.Tags) == 0 {
+		ra.SendBadRequestError(errors.New("no tags specified"))
+		return
+	}
+
+	sizes, err := dao.GetReclaimableSizes(project.ProjectID, repoName, request.Tags)
+	if err != nil {
+		ra.SendInternalServerError(fmt.Errorf("failed to get the reclaimable sizes of %s: %v", repoName, err))
+		return
+	}
+	ra.WriteJSONData(sizes)
+}
@@ -0,0 +1,70 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReclaimableSizesAPI(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method:   http.MethodPost,
+				url:      "/api/repositories/library/hello-world/reclaimable",
+				bodyJSON: &models.ReclaimableSizesRequest{Tags: []string{"latest"}},
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404, the project doesn't exist
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/repositories/non-existing/hello-world/reclaimable",
+				bodyJSON:   &models.ReclaimableSizesRequest{Tags: []string{"latest"}},
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 400, no tags
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/repositories/library/hello-world/reclaimable",
+				bodyJSON:   &models.ReclaimableSizesRequest{},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	// the tags not tracked are absent
+	sizes := map[string]int64{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodPost,
+		url:        "/api/repositories/library/hello-world/reclaimable",
+		bodyJSON:   &models.ReclaimableSizesRequest{Tags: []string{"non-existing"}},
+		credential: sysAdmin,
+	}, &sizes)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(sizes))
+}
@@ -0,0 +1,172 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	commonhttp "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+	"github.com/goharbor/harbor/src/pkg/art"
+	"github.com/goharbor/harbor/src/pkg/immutabletag/match/rule"
+)
+
+const (
+	defaultUntaggedDeletionBatchSize = 100
+	maxUntaggedDeletionBatchSize     = 1000
+)
+
+// untaggedDeletionResult is the result of deleting the untagged artifacts
+type untaggedDeletionResult struct {
+	Deleted int `json:"deleted"`
+	// the total size of the deleted artifacts, the layers shared with other artifacts are included
+	FreedBytes int64 `json:"freed_bytes"`
+	// the count of the artifacts skipped as they are locked by the immutable tag rules
+	Skipped int `json:"skipped"`
+	// the count of the untagged artifacts left for the next batch
+	Remaining int `json:"remaining"`
+	// the error interrupting the batch, the artifacts deleted before it are counted
+	Error string `json:"error,omitempty"`
+}
+
+// DeleteTags deletes the tags specified by the "tag" parameters of the repository in batch,
+// or all the untagged artifacts of the repository if "untagged=true" is specified
+func (ra *RepositoryAPI) DeleteTags() {
+	repoName := ra.GetString(":splat")
+	projectName, _ := utils.ParseRepository(repoName)
+	project, err := ra.ProjectMgr.Get(projectName)
+	if err != nil {
+		ra.ParseAndHandleError(fmt.Sprintf("failed to get the project %s", projectName), err)
+		return
+	}
+	if project == nil {
+		ra.SendNotFoundError(fmt.Errorf("project %s not found", projectName))
+		return
+	}
+
+	if !ra.RequireAuthenticated() ||
+		!ra.RequireProjectAccess(project.ProjectID, rbac.ActionDelete, rbac.ResourceRepository) {
+		return
+	}
+
+	if tags := ra.GetStrings("tag"); len(tags) > 0 {
+		ra.deleteTagsInBatch(project, repoName, tags)
+		return
+	}
+	ra.deleteUntagged(project, repoName)
+}
+
+// deleteUntagged deletes all the untagged artifacts of the repository
+func (ra *RepositoryAPI) deleteUntagged(project *models.Project, repoName string) {
+	untagged, err := ra.GetBool("untagged", false)
+	if err != nil || !untagged {
+		ra.SendBadRequestError(errors.New("specify the tags to delete by \"tag\" or delete the untagged artifacts by \"untagged=true\""))
+		return
+	}
+
+	batchSize, err := ra.GetInt("batch_size", defaultUntaggedDeletionBatchSize)
+	if err != nil || batchSize <= 0 || batchSize > maxUntaggedDeletionBatchSize {
+		ra.SendBadRequestError(fmt.Errorf("invalid batch_size, it should be between 1 and %d", maxUntaggedDeletionBatchSize))
+		return
+	}
+
+	// delete the manifests via the local registry client to let the quota be released
+	rc, err := coreutils.NewRepositoryClientForLocal(ra.SecurityCtx.GetUsername(), repoName)
+	if err != nil {
+		log.Errorf("error occurred while initializing repository client for %s: %v", repoName, err)
+		ra.SendInternalServerError(errors.New("internal error"))
+		return
+	}
+
+	result, err := deleteUntaggedArtifacts(project, repoName, batchSize, rc.DeleteManifest)
+	if err != nil {
+		if result == nil {
+			ra.ParseAndHandleError(fmt.Sprintf("failed to delete the untagged artifacts of %s", repoName), err)
+			return
+		}
+		// report what has been done before the failure, the client retries with the remaining ones
+		log.Errorf("failed to delete the untagged artifacts of %s: %v", repoName, err)
+		result.Error = err.Error()
+	}
+
+	ra.WriteJSONData(result)
+}
+
+// deleteUntaggedArtifacts deletes at most batchSize untagged artifacts of the repository by the deleteManifest func,
+// the artifacts locked by the immutable tag rules are skipped and the count of the ones left is returned for the
+// client to delete them in the next batch. The partial result is returned along with the error interrupting the batch
+func deleteUntaggedArtifacts(project *models.Project, repoName string, batchSize int,
+	deleteManifest func(digest string) error) (*untaggedDeletionResult, error) {
+	afs, err := dao.GetUntaggedArtifacts(project.ProjectID, repoName)
+	if err != nil {
+		return nil, err
+	}
+
+	_, repo := utils.ParseRepository(repoName)
+	matcher := rule.NewRuleMatcher(project.ProjectID)
+	result := &untaggedDeletionResult{}
+	for i, af := range afs {
+		if result.Deleted == batchSize {
+			result.Remaining = len(afs) - i
+			break
+		}
+
+		locked, err := matcher.Match(art.Candidate{
+			NamespaceID: project.ProjectID,
+			Namespace:   project.Name,
+			Repository:  repo,
+			Kind:        art.Image,
+			Digest:      af.Digest,
+		})
+		if err != nil {
+			result.Remaining = len(afs) - i
+			return result, err
+		}
+		if locked {
+			log.Infof("the untagged artifact %s@%s is locked by the immutable tag rules, skip it", repoName, af.Digest)
+			result.Skipped++
+			continue
+		}
+
+		size, err := dao.CountSizeOfArtifact(af.Digest)
+		if err != nil {
+			log.Warningf("failed to get the size of the artifact %s@%s: %v", repoName, af.Digest, err)
+			size = 0
+		}
+
+		if err = deleteManifest(af.Digest); err != nil {
+			if e, ok := err.(*commonhttp.Error); ok && e.Code == http.StatusNotFound {
+				log.Debugf("the untagged artifact %s@%s is already deleted", repoName, af.Digest)
+				continue
+			}
+			result.Remaining = len(afs) - i
+			return result, err
+		}
+		log.Infof("delete the untagged artifact: %s@%s", repoName, af.Digest)
+		result.Deleted++
+		if size > 0 {
+			result.FreedBytes += size
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/pkg/immutabletag"
+	"github.com/goharbor/harbor/src/pkg/immutabletag/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteUntaggedArtifacts(t *testing.T) {
+	project := &models.Project{
+		ProjectID: 1,
+		Name:      "library",
+	}
+	// the untagged artifacts in the repository "library/untagged-locked" are locked
+	ruleID, err := immutabletag.ImmuCtr.CreateImmutableRule(&model.Metadata{
+		ProjectID: 1,
+		Priority:  1,
+		Action:    "immutable",
+		Template:  "immutable_template",
+		TagSelectors: []*model.Selector{
+			{
+				Kind:       "doublestar",
+				Decoration: "matches",
+				Pattern:    "**",
+			},
+		},
+		ScopeSelectors: map[string][]*model.Selector{
+			"repository": {
+				{
+					Kind:       "doublestar",
+					Decoration: "repoMatches",
+					Pattern:    "untagged-locked",
+				},
+			},
+		},
+	})
+	require.Nil(t, err)
+	defer immutabletag.ImmuCtr.DeleteImmutableRule(ruleID)
+
+	for _, af := range []*models.Artifact{
+		{
+			PID:    1,
+			Repo:   "library/untagged",
+			Digest: "sha256:untagged",
+			Kind:   "image",
+		},
+		{
+			PID:    1,
+			Repo:   "library/untagged",
+			Digest: "sha256:untagged-next",
+			Kind:   "image",
+		},
+		{
+			PID:    1,
+			Repo:   "library/untagged",
+			Tag:    "latest",
+			Digest: "sha256:tagged",
+			Kind:   "image",
+		},
+		{
+			PID:    1,
+			Repo:   "library/untagged-locked",
+			Digest: "sha256:untagged-locked",
+			Kind:   "image",
+		},
+	} {
+		id, err := dao.AddArtifact(af)
+		require.Nil(t, err)
+		defer dao.DeleteArtifact(id)
+	}
+	require.Nil(t, dao.AddArtifactNBlobs([]*models.ArtifactAndBlob{
+		{DigestAF: "sha256:untagged", DigestBlob: "sha256:untagged"},
+		{DigestAF: "sha256:untagged", DigestBlob: "sha256:untagged-layer"},
+	}))
+	defer dao.DeleteArtifactAndBlobByDigest("sha256:untagged")
+	for _, blob := range []*models.Blob{
+		{Digest: "sha256:untagged", Size: 100},
+		{Digest: "sha256:untagged-layer", Size: 1000},
+	} {
+		_, err := dao.AddBlob(blob)
+		require.Nil(t, err)
+		defer dao.DeleteBlob(blob.Digest)
+	}
+
+	// deletes the manifest and the artifact as what the quota middleware does
+	deleted := []string{}
+	deleteManifest := func(digest string) error {
+		deleted = append(deleted, digest)
+		return dao.DeleteArtifactByDigest(1, "library/untagged", digest)
+	}
+
+	// the batch is interrupted by the failure, the partial result is returned
+	failing := func(digest string) error {
+		return errors.New("failed to delete")
+	}
+	result, err := deleteUntaggedArtifacts(project, "library/untagged", 1, failing)
+	require.NotNil(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, 0, result.Deleted)
+	assert.Equal(t, 2, result.Remaining)
+
+	// only one batch is deleted, the remaining one is left for the next batch
+	result, err = deleteUntaggedArtifacts(project, "library/untagged", 1, deleteManifest)
+	require.Nil(t, err)
+	assert.Equal(t, 1, result.Deleted)
+	assert.Equal(t, 0, result.Skipped)
+	assert.Equal(t, 1, result.Remaining)
+	assert.Equal(t, int64(1100), result.FreedBytes)
+	assert.Equal(t, []string{"sha256:untagged"}, deleted)
+
+	result, err = deleteUntaggedArtifacts(project, "library/untagged", 1, deleteManifest)
+	require.Nil(t, err)
+	assert.Equal(t, 1, result.Deleted)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, []string{"sha256:untagged", "sha256:untagged-next"}, deleted)
+	// the tagged one is kept
+	afs, err := dao.ListArtifacts(&models.ArtifactQuery{PID: 1, Repo: "library/untagged"})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(afs))
+	assert.Equal(t, "latest", afs[0].Tag)
+
+	// the locked one is skipped
+	deleted = []string{}
+	result, err = deleteUntaggedArtifacts(project, "library/untagged-locked", defaultUntaggedDeletionBatchSize, deleteManifest)
+	require.Nil(t, err)
+	assert.Equal(t, 0, result.Deleted)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 0, len(deleted))
+}
+
+func TestDeleteUntaggedAPI(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodDelete,
+				url:    "/api/repositories/library/hello-world/tags?untagged=true",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        "/api/repositories/library/hello-world/tags?untagged=true",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        "/api/repositories/non-existing/hello-world/tags?untagged=true",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 400, untagged isn't specified
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        "/api/repositories/library/hello-world/tags",
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, invalid batch size
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        "/api/repositories/library/hello-world/tags?untagged=true&batch_size=0",
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+}
+
+func TestDeleteTagsInBatchAPI(t *testing.T) {
+	tags := make([]string, 0, maxTagsDeletionBatchSize+1)
+	for i := 0; i <= maxTagsDeletionBatchSize; i++ {
+		tags = append(tags, fmt.Sprintf("tag=v%d", i))
+	}
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodDelete,
+				url:    "/api/repositories/library/hello-world/tags?tag=latest",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        "/api/repositories/library/hello-world/tags?tag=latest",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, too many tags
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        "/api/repositories/library/hello-world/tags?" + strings.Join(tags, "&"),
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+}
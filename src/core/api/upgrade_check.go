@@ -0,0 +1,63 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goharbor/harbor/src/pkg/preflight"
+)
+
+// UpgradeCheckAPI handles the request to /api/system/harbor/upgrade-check
+type UpgradeCheckAPI struct {
+	BaseController
+}
+
+type upgradeCheckReq struct {
+	TargetVersion string `json:"target_version"`
+}
+
+// Prepare validates the user, it needs the system admin permission.
+func (u *UpgradeCheckAPI) Prepare() {
+	u.BaseController.Prepare()
+	if !u.SecurityCtx.IsAuthenticated() {
+		u.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+	if !u.SecurityCtx.IsSysAdmin() {
+		u.SendForbiddenError(errors.New(u.SecurityCtx.GetUsername()))
+		return
+	}
+}
+
+// Check runs the upgrade checks and returns whether it's safe to upgrade Harbor,
+// the target version in the request body is optional
+func (u *UpgradeCheckAPI) Check() {
+	req := &upgradeCheckReq{}
+	if len(u.Ctx.Input.CopyBody(1<<32)) > 0 {
+		if err := u.DecodeJSONReq(req); err != nil {
+			u.SendBadRequestError(err)
+			return
+		}
+	}
+	if len(req.TargetVersion) > 0 {
+		if err := preflight.ValidateVersion(req.TargetVersion); err != nil {
+			u.SendBadRequestError(fmt.Errorf("invalid target version: %v", err))
+			return
+		}
+	}
+	u.WriteJSONData(preflight.RunChecks(req.TargetVersion))
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/pkg/preflight"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeCheckAPI(t *testing.T) {
+	url := "/api/system/harbor/upgrade-check"
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, invalid target version
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: sysAdmin,
+				bodyJSON: map[string]string{
+					"target_version": "latest",
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	report := &preflight.Report{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodPost,
+		url:        url,
+		credential: sysAdmin,
+		bodyJSON: map[string]string{
+			"target_version": "v2.0.0",
+		},
+	}, report)
+	require.Nil(t, err)
+	names := []string{}
+	for _, check := range report.Checks {
+		names = append(names, check.Name)
+	}
+	assert.Equal(t, []string{"schema_version", "admin_jobs", "registry_storage", "job_service", "upgrade_time"}, names)
+}
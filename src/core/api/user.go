@@ -20,15 +20,19 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/goharbor/harbor/src/common"
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/rbac"
 	"github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/common/security/impersonation"
+	"github.com/goharbor/harbor/src/common/token"
 	"github.com/goharbor/harbor/src/common/utils"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/filter"
 )
 
 // UserAPI handles request to /api/users/{}
@@ -56,6 +60,21 @@ type secretReq struct {
 	Secret string `json:"secret"`
 }
 
+type impersonationReq struct {
+	AllowWrite bool `json:"allow_write"`
+}
+
+type impersonationResp struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// impersonationTTL is how long the impersonation token is valid
+const impersonationTTL = 5 * time.Minute
+
+// overrideTTL is how long the override token is valid
+const overrideTTL = 5 * time.Minute
+
 // Prepare validates the URL and parms
 func (ua *UserAPI) Prepare() {
 	ua.BaseController.Prepare()
@@ -301,6 +320,7 @@ func (ua *UserAPI) Put() {
 		ua.SendInternalServerError(err)
 		return
 	}
+	filter.InvalidateBasicAuthCache(ua.userID)
 }
 
 // Post ...
@@ -383,6 +403,7 @@ func (ua *UserAPI) Delete() {
 		ua.SendInternalServerError(errors.New("failed to delete User"))
 		return
 	}
+	filter.InvalidateBasicAuthCache(ua.userID)
 }
 
 // ChangePassword handles PUT to /api/users/{}/password
@@ -440,6 +461,7 @@ func (ua *UserAPI) ChangePassword() {
 		ua.SendInternalServerError(fmt.Errorf("failed to change password of user %d: %v", ua.userID, err))
 		return
 	}
+	filter.InvalidateBasicAuthCache(ua.userID)
 }
 
 // ToggleUserAdminRole handles PUT api/users/{}/sysadmin
@@ -459,6 +481,7 @@ func (ua *UserAPI) ToggleUserAdminRole() {
 		ua.SendInternalServerError(errors.New("internal error"))
 		return
 	}
+	filter.InvalidateBasicAuthCache(userQuery.UserID)
 }
 
 // ListUserPermissions handles GET to /api/users/{}/permissions
@@ -637,3 +660,145 @@ func commonValidate(user models.User) error {
 	return nil
 
 }
+
+// Impersonate issues a short-lived token for the system admin to see Harbor as the user does,
+// the token is sent in the header "X-Harbor-Impersonation-Token" and only the read requests
+// are accepted unless the write is allowed explicitly
+func (ua *UserAPI) Impersonate() {
+	if !ua.RequireAuthenticated() {
+		return
+	}
+	if !config.AllowImpersonation() {
+		ua.SendForbiddenError(errors.New("the impersonation is disabled"))
+		return
+	}
+	if !ua.IsAdmin {
+		ua.SendForbiddenError(errors.New(ua.SecurityCtx.GetUsername()))
+		return
+	}
+	if _, ok := ua.SecurityCtx.(*impersonation.SecurityContext); ok {
+		ua.SendForbiddenError(errors.New("can not impersonate a user when impersonating"))
+		return
+	}
+	if ua.userID == ua.currentUserID {
+		ua.SendBadRequestError(errors.New("can not impersonate yourself"))
+		return
+	}
+
+	req := &impersonationReq{}
+	if len(ua.Ctx.Input.CopyBody(1<<32)) > 0 {
+		if err := ua.DecodeJSONReq(req); err != nil {
+			ua.SendBadRequestError(err)
+			return
+		}
+	}
+
+	user, err := dao.GetUser(models.User{UserID: ua.userID})
+	if err != nil {
+		ua.SendInternalServerError(fmt.Errorf("failed to get user %d: %v", ua.userID, err))
+		return
+	}
+	if user == nil {
+		ua.SendNotFoundError(fmt.Errorf("user %d not found", ua.userID))
+		return
+	}
+	if user.Disabled {
+		ua.SendBadRequestError(fmt.Errorf("user %s is disabled", user.Username))
+		return
+	}
+
+	impersonator := ua.SecurityCtx.GetUsername()
+	tk, err := token.NewImpersonation(user.UserID, user.Username, impersonator, req.AllowWrite, impersonationTTL)
+	if err != nil {
+		ua.SendInternalServerError(fmt.Errorf("failed to create the impersonation token: %v", err))
+		return
+	}
+	raw, err := tk.Raw()
+	if err != nil {
+		ua.SendInternalServerError(fmt.Errorf("failed to sign the impersonation token: %v", err))
+		return
+	}
+
+	log.Infof("%s impersonates %s, write allowed: %t", impersonator, user.Username, req.AllowWrite)
+	if err = dao.AddAccessLog(models.AccessLog{
+		Username:  impersonator,
+		RepoName:  user.Username,
+		Operation: "impersonate",
+	}); err != nil {
+		log.Errorf("failed to add the access log of %s impersonating %s: %v", impersonator, user.Username, err)
+	}
+
+	ua.WriteJSONData(&impersonationResp{
+		Token:     raw,
+		ExpiresAt: time.Unix(tk.Claims.(*token.ImpersonationClaims).ExpiresAt, 0).UTC(),
+	})
+}
+
+// Override issues a short-lived token for the system admin to make the requests in the security
+// context of the user, the token is sent in the header "X-Harbor-Override-User" and only honored
+// when Harbor core runs in debug mode. The issuing admin is recorded in the token and the access log
+func (ua *UserAPI) Override() {
+	if !ua.RequireAuthenticated() {
+		return
+	}
+	if !config.AllowAdminOverride() {
+		ua.SendForbiddenError(errors.New("the override is disabled"))
+		return
+	}
+	if !ua.IsAdmin {
+		ua.SendForbiddenError(errors.New(ua.SecurityCtx.GetUsername()))
+		return
+	}
+	if _, ok := ua.SecurityCtx.(*impersonation.SecurityContext); ok {
+		ua.SendForbiddenError(errors.New("can not issue the override token when impersonating"))
+		return
+	}
+	if len(ua.Ctx.Request.Header.Get(common.OverrideUserHeader)) > 0 {
+		ua.SendForbiddenError(errors.New("can not issue the override token when overriding"))
+		return
+	}
+	if ua.userID == ua.currentUserID {
+		ua.SendBadRequestError(errors.New("can not override yourself"))
+		return
+	}
+
+	user, err := dao.GetUser(models.User{UserID: ua.userID})
+	if err != nil {
+		ua.SendInternalServerError(fmt.Errorf("failed to get user %d: %v", ua.userID, err))
+		return
+	}
+	if user == nil {
+		ua.SendNotFoundError(fmt.Errorf("user %d not found", ua.userID))
+		return
+	}
+	if user.Disabled {
+		ua.SendBadRequestError(fmt.Errorf("user %s is disabled", user.Username))
+		return
+	}
+
+	issuer := ua.SecurityCtx.GetUsername()
+	tk, err := token.NewOverride(user.Username, issuer, overrideTTL)
+	if err != nil {
+		ua.SendInternalServerError(fmt.Errorf("failed to create the override token: %v", err))
+		return
+	}
+	raw, err := tk.Raw()
+	if err != nil {
+		ua.SendInternalServerError(fmt.Errorf("failed to sign the override token: %v", err))
+		return
+	}
+
+	log.Infof("%s issues the override token of %s", issuer, user.Username)
+	if err = dao.AddAccessLog(models.AccessLog{
+		Username:  issuer,
+		RepoName:  user.Username,
+		Operation: "override",
+	}); err != nil {
+		log.Errorf("failed to add the access log of %s overriding %s: %v", issuer, user.Username, err)
+	}
+
+	ua.WriteJSONData(&impersonationResp{
+		Token:     raw,
+		ExpiresAt: time.Unix(tk.Claims.(*token.OverrideClaims).ExpiresAt, 0).UTC(),
+	})
+}
@@ -0,0 +1,208 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+)
+
+// the max length of the name of API key
+const maxAPIKeyNameLen = 255
+
+// UserAPIKeyAPI handles the requests to /api/users/:id/apikeys
+type UserAPIKeyAPI struct {
+	BaseController
+	userID int
+	apiKey *models.APIKey
+}
+
+type apiKeyReq struct {
+	Name string `json:"name"`
+	// the unix timestamp after which the key is expired, 0 means never
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+type apiKeyCreated struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	ExpiresAt int64  `json:"expires_at"`
+	// the key is only returned when it's created
+	Key string `json:"key"`
+}
+
+// Prepare validates the user and the API key, the keys can only be managed by the owner and
+// the system admin
+func (u *UserAPIKeyAPI) Prepare() {
+	u.BaseController.Prepare()
+	if !u.SecurityCtx.IsAuthenticated() {
+		u.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+
+	current, err := dao.GetUser(models.User{Username: u.SecurityCtx.GetUsername()})
+	if err != nil {
+		u.SendInternalServerError(fmt.Errorf("failed to get user %s: %v", u.SecurityCtx.GetUsername(), err))
+		return
+	}
+	if current == nil {
+		u.SendForbiddenError(fmt.Errorf("user %s doesn't exist in DB", u.SecurityCtx.GetUsername()))
+		return
+	}
+
+	id := u.GetStringFromPath(":id")
+	if id == "current" {
+		u.userID = current.UserID
+	} else {
+		u.userID, err = strconv.Atoi(id)
+		if err != nil || u.userID <= 0 {
+			u.SendBadRequestError(fmt.Errorf("invalid user ID: %s", id))
+			return
+		}
+	}
+	if u.userID != current.UserID && !u.SecurityCtx.IsSysAdmin() {
+		u.SendForbiddenError(errors.New(u.SecurityCtx.GetUsername()))
+		return
+	}
+	user, err := dao.GetUser(models.User{UserID: u.userID})
+	if err != nil {
+		u.SendInternalServerError(fmt.Errorf("failed to get user %d: %v", u.userID, err))
+		return
+	}
+	if user == nil {
+		u.SendNotFoundError(fmt.Errorf("user %d not found", u.userID))
+		return
+	}
+
+	if len(u.GetStringFromPath(":kid")) == 0 {
+		return
+	}
+	kid, err := u.GetInt64FromPath(":kid")
+	if err != nil || kid <= 0 {
+		u.SendBadRequestError(errors.New("invalid API key ID"))
+		return
+	}
+	u.apiKey, err = dao.GetAPIKey(kid)
+	if err != nil {
+		u.SendInternalServerError(fmt.Errorf("failed to get API key %d: %v", kid, err))
+		return
+	}
+	if u.apiKey == nil || u.apiKey.UserID != u.userID {
+		u.SendNotFoundError(fmt.Errorf("API key %d not found", kid))
+		return
+	}
+}
+
+// List the API keys of the user, the keys themselves are never returned
+func (u *UserAPIKeyAPI) List() {
+	keys, err := dao.ListAPIKeys(u.userID)
+	if err != nil {
+		u.SendInternalServerError(fmt.Errorf("failed to list the API keys of user %d: %v", u.userID, err))
+		return
+	}
+	u.WriteJSONData(keys)
+}
+
+// Get the API key
+func (u *UserAPIKeyAPI) Get() {
+	u.WriteJSONData(u.apiKey)
+}
+
+// Post creates the API key, the key is only returned in the response
+func (u *UserAPIKeyAPI) Post() {
+	req := &apiKeyReq{}
+	if err := u.DecodeJSONReq(req); err != nil {
+		u.SendBadRequestError(err)
+		return
+	}
+	if err := validateAPIKeyReq(req); err != nil {
+		u.SendBadRequestError(err)
+		return
+	}
+
+	key := utils.GenerateRandomString()
+	apiKey := &models.APIKey{
+		UserID:    u.userID,
+		Name:      req.Name,
+		KeyHash:   dao.HashAPIKey(key),
+		ExpiresAt: req.ExpiresAt,
+	}
+	id, err := dao.AddAPIKey(apiKey)
+	if err != nil {
+		if err == dao.ErrDupRows {
+			u.SendConflictError(fmt.Errorf("API key %s already exists", req.Name))
+			return
+		}
+		u.SendInternalServerError(fmt.Errorf("failed to add API key: %v", err))
+		return
+	}
+
+	u.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
+	u.Data["json"] = &apiKeyCreated{
+		ID:        id,
+		Name:      apiKey.Name,
+		ExpiresAt: apiKey.ExpiresAt,
+		Key:       key,
+	}
+	u.ServeJSON()
+}
+
+// Put updates the name and the expiry of the API key
+func (u *UserAPIKeyAPI) Put() {
+	req := &apiKeyReq{}
+	if err := u.DecodeJSONReq(req); err != nil {
+		u.SendBadRequestError(err)
+		return
+	}
+	if err := validateAPIKeyReq(req); err != nil {
+		u.SendBadRequestError(err)
+		return
+	}
+	u.apiKey.Name = req.Name
+	u.apiKey.ExpiresAt = req.ExpiresAt
+	if err := dao.UpdateAPIKey(u.apiKey); err != nil {
+		if err == dao.ErrDupRows {
+			u.SendConflictError(fmt.Errorf("API key %s already exists", req.Name))
+			return
+		}
+		u.SendInternalServerError(fmt.Errorf("failed to update API key %d: %v", u.apiKey.ID, err))
+		return
+	}
+}
+
+// Delete revokes the API key
+func (u *UserAPIKeyAPI) Delete() {
+	if err := dao.DeleteAPIKey(u.apiKey.ID); err != nil {
+		u.SendInternalServerError(fmt.Errorf("failed to delete API key %d: %v", u.apiKey.ID, err))
+		return
+	}
+}
+
+func validateAPIKeyReq(req *apiKeyReq) error {
+	if len(req.Name) == 0 || len(req.Name) > maxAPIKeyNameLen {
+		return fmt.Errorf("the length of name must be between 1 and %d", maxAPIKeyNameLen)
+	}
+	if req.ExpiresAt < 0 || (req.ExpiresAt > 0 && req.ExpiresAt <= time.Now().Unix()) {
+		return errors.New("the expiry must be in the future")
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAPIKeyAPI(t *testing.T) {
+	url := fmt.Sprintf("/api/users/%d/apikeys", nonSysAdminID)
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403, the keys of the other users can't be managed
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        url,
+				credential: projAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 404, the user doesn't exist
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/users/10000/apikeys",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 400, no name
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				bodyJSON:   &apiKeyReq{},
+				credential: nonSysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, expired
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				bodyJSON:   &apiKeyReq{Name: "ci", ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+				credential: nonSysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 404, the key doesn't exist
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        url + "/10000",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	// create
+	created := &apiKeyCreated{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodPost,
+		url:        "/api/users/current/apikeys",
+		bodyJSON:   &apiKeyReq{Name: "ci"},
+		credential: nonSysAdmin,
+	}, created)
+	require.Nil(t, err)
+	defer dao.DeleteAPIKey(created.ID)
+	assert.Equal(t, "ci", created.Name)
+	assert.NotEmpty(t, created.Key)
+	keyURL := fmt.Sprintf("%s/%d", url, created.ID)
+
+	// 409, the name is used
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPost,
+			url:        url,
+			bodyJSON:   &apiKeyReq{Name: "ci"},
+			credential: nonSysAdmin,
+		},
+		code: http.StatusConflict,
+	})
+
+	// the key is stored hashed and never returned again
+	apiKey, err := dao.GetAPIKey(created.ID)
+	require.Nil(t, err)
+	require.NotNil(t, apiKey)
+	assert.Equal(t, dao.HashAPIKey(created.Key), apiKey.KeyHash)
+	keys := []*models.APIKey{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url,
+		credential: sysAdmin,
+	}, &keys)
+	require.Nil(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, created.ID, keys[0].ID)
+	resp, err := handle(&testingRequest{
+		method:     http.MethodGet,
+		url:        keyURL,
+		credential: nonSysAdmin,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), created.Key)
+	assert.NotContains(t, resp.Body.String(), apiKey.KeyHash)
+
+	// authenticate with the key
+	withKey := func(key string) http.Header {
+		return http.Header{common.APIKeyHeader: []string{key}}
+	}
+	user := &models.User{}
+	err = handleAndParse(&testingRequest{
+		method: http.MethodGet,
+		url:    "/api/users/current",
+		header: withKey(created.Key),
+	}, user)
+	require.Nil(t, err)
+	assert.Equal(t, nonSysAdmin.Name, user.Username)
+	err = handleAndParse(&testingRequest{
+		method: http.MethodGet,
+		url:    "/api/users/current",
+		header: http.Header{"Authorization": []string{"ApiKey " + created.Key}},
+	}, user)
+	require.Nil(t, err)
+	assert.Equal(t, nonSysAdmin.Name, user.Username)
+
+	// update
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPut,
+			url:        keyURL,
+			bodyJSON:   &apiKeyReq{Name: "build", ExpiresAt: time.Now().Add(time.Hour).Unix()},
+			credential: nonSysAdmin,
+		},
+		code: http.StatusOK,
+	})
+	apiKey, err = dao.GetAPIKey(created.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "build", apiKey.Name)
+
+	// revoke
+	runCodeCheckingCases(t,
+		&codeCheckingCase{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        keyURL,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		&codeCheckingCase{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/users/current",
+				header: withKey(created.Key),
+			},
+			code: http.StatusUnauthorized,
+		},
+	)
+}
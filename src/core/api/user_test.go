@@ -23,6 +23,7 @@ import (
 
 	"github.com/goharbor/harbor/src/common/api"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/token"
 	"github.com/goharbor/harbor/src/testing/apitests/apilib"
 	"github.com/stretchr/testify/assert"
 
@@ -652,3 +653,178 @@ func TestValidateSecret(t *testing.T) {
 	assert.Nil(t, validateSecret("Passw0rd"))
 	assert.Nil(t, validateSecret("Thisis1Valid_password"))
 }
+
+func TestUserImpersonate(t *testing.T) {
+	url := fmt.Sprintf("/api/users/%d/impersonate", nonSysAdminID)
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403, the impersonation is disabled by default
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: sysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	config.Upload(map[string]interface{}{common.AllowImpersonation: true})
+	defer config.Upload(map[string]interface{}{common.AllowImpersonation: false})
+
+	cases = []*codeCheckingCase{
+		// 403, not system admin
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, impersonate self
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/users/1/impersonate",
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	resp := &impersonationResp{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodPost,
+		url:        url,
+		credential: sysAdmin,
+	}, resp)
+	require.Nil(t, err)
+	require.NotEmpty(t, resp.Token)
+	header := http.Header{}
+	header.Set(common.ImpersonationTokenHeader, resp.Token)
+
+	// see Harbor as the impersonated user does
+	user := &apilib.User{}
+	err = handleAndParse(&testingRequest{
+		method: http.MethodGet,
+		url:    "/api/users/current",
+		header: header,
+	}, user)
+	require.Nil(t, err)
+	assert.Equal(t, nonSysAdmin.Name, user.Username)
+
+	// the token can't be used for the write operations by default
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method: http.MethodPost,
+			url:    "/api/projects",
+			header: header,
+			bodyJSON: &models.ProjectRequest{
+				Name: "project-created-by-impersonation",
+			},
+		},
+		code: http.StatusForbidden,
+	})
+
+	// 400, the disabled user can not be impersonated
+	id, err := dao.Register(models.User{
+		Username: "disabled-impersonated-user",
+		Email:    "disabled-impersonated-user@example.com",
+		Password: "Harbor12345",
+	})
+	require.Nil(t, err)
+	defer dao.CleanUser(id)
+	require.Nil(t, dao.DisableUsers(adminName, []*models.InactiveUser{{UserID: int(id), Username: "disabled-impersonated-user"}}))
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPost,
+			url:        fmt.Sprintf("/api/users/%d/impersonate", id),
+			credential: sysAdmin,
+		},
+		code: http.StatusBadRequest,
+	})
+}
+
+func TestUserOverride(t *testing.T) {
+	url := fmt.Sprintf("/api/users/%d/override", nonSysAdminID)
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403, the override is disabled by default
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: sysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	config.Upload(map[string]interface{}{common.AllowAdminOverride: true})
+	defer config.Upload(map[string]interface{}{common.AllowAdminOverride: false})
+
+	cases = []*codeCheckingCase{
+		// 403, not system admin
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, override self
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/users/1/override",
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	resp := &impersonationResp{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodPost,
+		url:        url,
+		credential: sysAdmin,
+	}, resp)
+	require.Nil(t, err)
+	require.NotEmpty(t, resp.Token)
+
+	// the issuing admin is recorded in the token
+	claims := &token.OverrideClaims{}
+	_, err = token.ParseWithClaims(resp.Token, claims)
+	require.Nil(t, err)
+	assert.Equal(t, nonSysAdmin.Name, claims.Username)
+	assert.Equal(t, adminName, claims.IssuedBy)
+
+	// and in the access log
+	logs, err := dao.GetAccessLogs(&models.LogQueryParam{
+		Username:   adminName,
+		Operations: []string{"override"},
+	})
+	require.Nil(t, err)
+	require.NotEmpty(t, logs)
+	assert.Equal(t, nonSysAdmin.Name, logs[0].RepoName)
+}
@@ -155,8 +155,33 @@ func Login(m models.AuthModel) (*models.User, error) {
 		}
 		return nil, err
 	}
-	err = authenticator.PostAuthenticate(user)
-	return user, err
+	if err = authenticator.PostAuthenticate(user); err != nil {
+		return user, err
+	}
+	if user != nil {
+		disabled, err := isDisabled(user)
+		if err != nil {
+			return nil, err
+		}
+		if disabled {
+			log.Debugf("%s is disabled, login failed", m.Principal)
+			return nil, nil
+		}
+	}
+	return user, nil
+}
+
+// isDisabled checks whether the user has been disabled, the user returned by the authenticators
+// other than the DB one doesn't carry the flag, so read it from DB
+func isDisabled(user *models.User) (bool, error) {
+	if user.Disabled || user.UserID == 0 {
+		return user.Disabled, nil
+	}
+	u, err := dao.GetUser(models.User{UserID: user.UserID})
+	if err != nil {
+		return false, err
+	}
+	return u != nil && u.Disabled, nil
 }
 
 func getHelper() (AuthenticateHelper, error) {
@@ -90,15 +90,17 @@ func (l *Auth) Authenticate(m models.AuthModel) (*models.User, error) {
 	// Retrieve ldap related info in login to avoid too many traffic with LDAP server.
 	// Get group admin dn
 	groupCfg, err := config.LDAPGroupConf()
-	groupAdminDN := utils.TrimLower(groupCfg.LdapGroupAdminDN)
+	if err != nil {
+		log.Warningf("failed to get the LDAP group config: %v", err)
+	} else if len(groupCfg.LdapGroupAdminDN) > 0 {
+		// Attach LDAP group admin
+		u.HasAdminRole = ldapUtils.InGroup(ldapUsers[0].GroupDNList, groupCfg.LdapGroupAdminDN)
+	}
+	u.LDAPGroupDNs = ldapUsers[0].GroupDNList
 	// Attach user group
 	for _, groupDN := range ldapUsers[0].GroupDNList {
 
 		groupDN = utils.TrimLower(groupDN)
-		// Attach LDAP group admin
-		if len(groupAdminDN) > 0 && groupAdminDN == groupDN {
-			u.HasAdminRole = true
-		}
 
 		userGroupQuery := models.UserGroup{
 			GroupType:   1,
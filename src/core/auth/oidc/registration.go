@@ -0,0 +1,67 @@
+package oidc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	commonhttp "github.com/goharbor/harbor/src/common/http"
+)
+
+const registrationPath = "/register"
+
+// ClientCredentials is the client credentials returned by the dynamic client registration of OIDC provider
+type ClientCredentials struct {
+	ClientID              string `json:"client_id"`
+	ClientSecret          string `json:"client_secret"`
+	ClientSecretExpiresAt int64  `json:"client_secret_expires_at,omitempty"`
+}
+
+type registrationRequest struct {
+	RedirectURIs []string `json:"redirect_uris"`
+	ClientName   string   `json:"client_name"`
+}
+
+// RegisterClient registers harbor as a client of the OIDC provider via the dynamic client
+// registration(RFC 7591) and returns the client credentials issued by the provider
+func RegisterClient(providerURL, redirectURI, clientName string, insecure ...bool) (*ClientCredentials, error) {
+	if len(providerURL) == 0 {
+		return nil, fmt.Errorf("empty OIDC provider URL")
+	}
+	data, err := json.Marshal(&registrationRequest{
+		RedirectURIs: []string{redirectURI},
+		ClientName:   clientName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Transport: commonhttp.GetHTTPTransport(insecure...),
+		Timeout:   30 * time.Second,
+	}
+	url := strings.TrimSuffix(providerURL, "/") + registrationPath
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to register client to %s, status code: %d, body: %s", url, resp.StatusCode, string(body))
+	}
+	creds := &ClientCredentials{}
+	if err = json.Unmarshal(body, creds); err != nil {
+		return nil, err
+	}
+	if len(creds.ClientID) == 0 {
+		return nil, fmt.Errorf("no client ID returned by %s", url)
+	}
+	return creds, nil
+}
@@ -0,0 +1,53 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterClient(t *testing.T) {
+	var req registrationRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/register" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"client_id":"id-001","client_secret":"secret-001","client_secret_expires_at":0}`))
+	}))
+	defer server.Close()
+
+	creds, err := RegisterClient(server.URL+"/", "https://harbor.local/c/oidc/callback", "harbor")
+	require.Nil(t, err)
+	assert.Equal(t, "id-001", creds.ClientID)
+	assert.Equal(t, "secret-001", creds.ClientSecret)
+	assert.Equal(t, []string{"https://harbor.local/c/oidc/callback"}, req.RedirectURIs)
+	assert.Equal(t, "harbor", req.ClientName)
+
+	// registration not supported by the provider
+	_, err = RegisterClient(server.URL+"/not-found", "https://harbor.local/c/oidc/callback", "harbor")
+	assert.NotNil(t, err)
+
+	_, err = RegisterClient("", "https://harbor.local/c/oidc/callback", "harbor")
+	assert.NotNil(t, err)
+}
+
+func TestRegisterClientNoClientID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	_, err := RegisterClient(server.URL, "https://harbor.local/c/oidc/callback", "harbor")
+	assert.NotNil(t, err)
+}
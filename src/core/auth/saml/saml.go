@@ -0,0 +1,68 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saml
+
+import (
+	"errors"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/auth"
+)
+
+// userComment is the comment of the users onboarded via the SAML IdP
+const userComment = "Onboarded via SAML provider"
+
+// Authenticator of SAML mode, the users are authenticated by the SP-initiated SSO rather than the password
+type Authenticator struct {
+	auth.DefaultAuthenticateHelper
+}
+
+// OnBoardUser isn't supported as the users of SAML mode are bound to the subjects asserted by the IdP
+// rather than matched by the username, they are onboarded by the func OnBoardUser when logging in
+func (a *Authenticator) OnBoardUser(u *models.User) error {
+	return errors.New("the user of SAML mode can only be onboarded by logging in via the IdP")
+}
+
+// OnBoardUser returns the user bound to the subject asserted by the IdP, the user is onboarded and
+// bound to the subject if it doesn't exist. It never matches the existing users by the username,
+// the dao.ErrDupUser is returned if the asserted username is taken by another user.
+func OnBoardUser(info *UserInfo) (*models.User, error) {
+	u, err := dao.GetUserBySAMLSubject(info.Issuer, info.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if u != nil {
+		return u, nil
+	}
+	u = &models.User{
+		Username: info.Username,
+		Email:    info.Email,
+		Realname: info.Realname,
+		Comment:  userComment,
+	}
+	if len(u.Realname) == 0 {
+		u.Realname = u.Username
+	}
+	if err = dao.OnBoardSAMLUser(u, info.Issuer, info.Subject); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func init() {
+	auth.Register(common.SAMLAuth, &Authenticator{})
+}
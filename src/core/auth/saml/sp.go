@@ -0,0 +1,486 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	beego_cache "github.com/astaxie/beego/cache"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SessionExpiryKey is the key of the session storing the time (unix seconds) after which
+	// the session established via the IdP must be terminated
+	SessionExpiryKey = "saml_session_not_on_or_after"
+
+	nsProtocol              = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsAssertion             = "urn:oasis:names:tc:SAML:2.0:assertion"
+	bindingHTTPPost         = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	nameIDFormatUnspecified = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	statusSuccess           = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	confirmationBearer      = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	// the clock skew allowed between Harbor and the IdP
+	clockSkew = time.Minute
+)
+
+// the keys of the attribute mapping
+const (
+	attrUsername = "username"
+	attrEmail    = "email"
+	attrRealname = "realname"
+)
+
+var getSetting = config.SAMLSetting
+
+// consumedAssertions records the IDs of the consumed assertions until they expire, so that a replayed
+// assertion is rejected even if it's posted with a pending request ID of another session
+var consumedAssertions = &assertionCache{cache: beego_cache.NewMemoryCache()}
+
+type assertionCache struct {
+	sync.Mutex
+	cache beego_cache.Cache
+}
+
+// consume marks the assertion consumed until the expiry, it returns false if the assertion has been consumed
+func (a *assertionCache) consume(id string, expiry time.Time) (bool, error) {
+	a.Lock()
+	defer a.Unlock()
+	if a.cache.IsExist(id) {
+		return false, nil
+	}
+	if err := a.cache.Put(id, true, time.Until(expiry)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UserInfo is the user asserted by the IdP, the user is identified by the issuer and the subject
+type UserInfo struct {
+	Issuer   string
+	Subject  string
+	Username string
+	Email    string
+	Realname string
+	// the session must be terminated after the time, zero means it isn't asserted
+	SessionNotOnOrAfter time.Time
+}
+
+type issuer struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	Value   string   `xml:",chardata"`
+}
+
+type nameIDPolicy struct {
+	XMLName     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol NameIDPolicy"`
+	Format      string   `xml:"Format,attr"`
+	AllowCreate bool     `xml:"AllowCreate,attr"`
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name     `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string       `xml:"ID,attr"`
+	Version                     string       `xml:"Version,attr"`
+	IssueInstant                string       `xml:"IssueInstant,attr"`
+	Destination                 string       `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string       `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string       `xml:"ProtocolBinding,attr"`
+	Issuer                      issuer       `xml:"Issuer"`
+	NameIDPolicy                nameIDPolicy `xml:"NameIDPolicy"`
+}
+
+type endpoint struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+	Index    int    `xml:"index,attr"`
+}
+
+type spSSODescriptor struct {
+	AuthnRequestsSigned        bool     `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool     `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string   `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormat               string   `xml:"NameIDFormat"`
+	AssertionConsumerService   endpoint `xml:"AssertionConsumerService"`
+}
+
+type entityDescriptor struct {
+	XMLName         xml.Name        `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID        string          `xml:"entityID,attr"`
+	SPSSODescriptor spSSODescriptor `xml:"SPSSODescriptor"`
+}
+
+type response struct {
+	XMLName      xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol Response"`
+	ID           string   `xml:"ID,attr"`
+	InResponseTo string   `xml:"InResponseTo,attr"`
+	Destination  string   `xml:"Destination,attr"`
+	Status       struct {
+		StatusCode struct {
+			Value string `xml:"Value,attr"`
+		} `xml:"StatusCode"`
+	} `xml:"Status"`
+	Assertions          []assertion `xml:"urn:oasis:names:tc:SAML:2.0:assertion Assertion"`
+	EncryptedAssertions []struct{}  `xml:"urn:oasis:names:tc:SAML:2.0:assertion EncryptedAssertion"`
+}
+
+type subjectConfirmation struct {
+	Method string `xml:"Method,attr"`
+	Data   struct {
+		Recipient    string `xml:"Recipient,attr"`
+		InResponseTo string `xml:"InResponseTo,attr"`
+		NotOnOrAfter string `xml:"NotOnOrAfter,attr"`
+	} `xml:"SubjectConfirmationData"`
+}
+
+type attribute struct {
+	Name         string   `xml:"Name,attr"`
+	FriendlyName string   `xml:"FriendlyName,attr"`
+	Values       []string `xml:"AttributeValue"`
+}
+
+type assertion struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Assertion"`
+	ID      string   `xml:"ID,attr"`
+	Issuer  string   `xml:"Issuer"`
+	Subject struct {
+		NameID        string                `xml:"NameID"`
+		Confirmations []subjectConfirmation `xml:"SubjectConfirmation"`
+	} `xml:"Subject"`
+	Conditions *struct {
+		NotBefore    string   `xml:"NotBefore,attr"`
+		NotOnOrAfter string   `xml:"NotOnOrAfter,attr"`
+		Audiences    []string `xml:"AudienceRestriction>Audience"`
+	} `xml:"Conditions"`
+	AuthnStatement *struct {
+		SessionNotOnOrAfter string `xml:"SessionNotOnOrAfter,attr"`
+	} `xml:"AuthnStatement"`
+	Attributes []attribute `xml:"AttributeStatement>Attribute"`
+}
+
+// NewRequestID generates the ID of the authentication request, the ID of XML can not start with a digit
+func NewRequestID() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "id-" + hex.EncodeToString(b), nil
+}
+
+// AuthnRequestURL returns the URL redirecting the user to the IdP with the authentication request
+// in HTTP-Redirect binding
+func AuthnRequestURL(requestID, relayState string) (string, error) {
+	setting, err := getSetting()
+	if err != nil {
+		return "", err
+	}
+	if len(setting.IdPSSOURL) == 0 {
+		return "", errors.New("the SSO URL of the SAML IdP is not configured")
+	}
+	req := &authnRequest{
+		ID:                          requestID,
+		Version:                     "2.0",
+		IssueInstant:                time.Now().UTC().Format(time.RFC3339),
+		Destination:                 setting.IdPSSOURL,
+		AssertionConsumerServiceURL: setting.ACSURL,
+		ProtocolBinding:             bindingHTTPPost,
+		Issuer:                      issuer{Value: setting.EntityID},
+		NameIDPolicy: nameIDPolicy{
+			Format:      nameIDFormatUnspecified,
+			AllowCreate: true,
+		},
+	}
+	data, err := xml.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	w, err := flate.NewWriter(buf, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err = w.Write(data); err != nil {
+		return "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(setting.IdPSSOURL)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid SSO URL of the SAML IdP")
+	}
+	q := u.Query()
+	q.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	q.Set("RelayState", relayState)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Metadata returns the metadata of Harbor as the service provider
+func Metadata() ([]byte, error) {
+	setting, err := getSetting()
+	if err != nil {
+		return nil, err
+	}
+	data, err := xml.MarshalIndent(&entityDescriptor{
+		EntityID: setting.EntityID,
+		SPSSODescriptor: spSSODescriptor{
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: nsProtocol,
+			NameIDFormat:               nameIDFormatUnspecified,
+			AssertionConsumerService: endpoint{
+				Binding:  bindingHTTPPost,
+				Location: setting.ACSURL,
+				Index:    1,
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// ParseResponse verifies the base64 encoded response posted by the IdP for the authentication request
+// and returns the user asserted in it
+func ParseResponse(encoded, requestID string) (*UserInfo, error) {
+	setting, err := getSetting()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := parseCertificate(setting.IdPCertificate)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the SAML response")
+	}
+	resp := &response{}
+	if err = xml.Unmarshal(doc, resp); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the SAML response")
+	}
+	if resp.Status.StatusCode.Value != statusSuccess {
+		return nil, fmt.Errorf("the SAML IdP returned the status: %s", resp.Status.StatusCode.Value)
+	}
+	if len(resp.EncryptedAssertions) > 0 {
+		return nil, errors.New("the encrypted assertion is not supported")
+	}
+	// only the responses for the requests sent by Harbor are accepted, the IdP-initiated SSO isn't supported
+	if len(requestID) == 0 || resp.InResponseTo != requestID {
+		return nil, fmt.Errorf("the SAML response is not for the request %s", requestID)
+	}
+	if len(resp.Destination) > 0 && resp.Destination != setting.ACSURL {
+		return nil, fmt.Errorf("mismatch destination of the SAML response: %s", resp.Destination)
+	}
+
+	signed, err := verifySignature(doc, cert)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify the signature of the SAML response")
+	}
+	a, err := signedAssertion(signed, resp.ID)
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := validateAssertion(a, setting, requestID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	consumed, err := consumedAssertions.consume(a.ID, expiry)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, fmt.Errorf("the SAML assertion %s has been consumed", a.ID)
+	}
+	return userInfo(a, setting.AttributeMapping)
+}
+
+// signedAssertion returns the assertion in the element covered by the signature
+func signedAssertion(signed []byte, responseID string) (*assertion, error) {
+	name, err := rootName(signed)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case xml.Name{Space: nsProtocol, Local: "Response"}:
+		resp := &response{}
+		if err := xml.Unmarshal(signed, resp); err != nil {
+			return nil, err
+		}
+		if resp.ID != responseID {
+			return nil, errors.New("the signed response is not the one being processed")
+		}
+		if len(resp.Assertions) != 1 {
+			return nil, fmt.Errorf("exactly one assertion is expected in the SAML response, but got %d", len(resp.Assertions))
+		}
+		return &resp.Assertions[0], nil
+	case xml.Name{Space: nsAssertion, Local: "Assertion"}:
+		a := &assertion{}
+		if err := xml.Unmarshal(signed, a); err != nil {
+			return nil, err
+		}
+		return a, nil
+	default:
+		return nil, fmt.Errorf("unexpected signed element: %s", name.Local)
+	}
+}
+
+// validateAssertion validates the assertion and returns the time after which it can't be consumed
+func validateAssertion(a *assertion, setting *models.SAMLSetting, requestID string, now time.Time) (time.Time, error) {
+	if len(a.ID) == 0 {
+		return time.Time{}, errors.New("no ID in the SAML assertion")
+	}
+	if len(strings.TrimSpace(a.Issuer)) == 0 {
+		return time.Time{}, errors.New("no issuer in the SAML assertion")
+	}
+	if a.Conditions == nil {
+		return time.Time{}, errors.New("no conditions in the SAML assertion")
+	}
+	if len(a.Conditions.NotBefore) > 0 {
+		notBefore, err := time.Parse(time.RFC3339, a.Conditions.NotBefore)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if now.Add(clockSkew).Before(notBefore) {
+			return time.Time{}, errors.New("the SAML assertion is not yet valid")
+		}
+	}
+	if len(a.Conditions.NotOnOrAfter) > 0 {
+		notOnOrAfter, err := time.Parse(time.RFC3339, a.Conditions.NotOnOrAfter)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !now.Add(-clockSkew).Before(notOnOrAfter) {
+			return time.Time{}, errors.New("the SAML assertion has expired")
+		}
+	}
+	audienceMatched := false
+	for _, audience := range a.Conditions.Audiences {
+		if strings.TrimSpace(audience) == setting.EntityID {
+			audienceMatched = true
+			break
+		}
+	}
+	if !audienceMatched {
+		return time.Time{}, fmt.Errorf("the SAML assertion is not issued for the audience %s", setting.EntityID)
+	}
+
+	for _, c := range a.Subject.Confirmations {
+		if c.Method != confirmationBearer || c.Data.Recipient != setting.ACSURL {
+			continue
+		}
+		if c.Data.InResponseTo != requestID {
+			continue
+		}
+		notOnOrAfter, err := time.Parse(time.RFC3339, c.Data.NotOnOrAfter)
+		if err != nil || !now.Add(-clockSkew).Before(notOnOrAfter) {
+			continue
+		}
+		return notOnOrAfter.Add(clockSkew), nil
+	}
+	return time.Time{}, errors.New("no valid bearer subject confirmation in the SAML assertion")
+}
+
+// userInfo maps the attributes of the assertion to the user, the mapping maps the fields of
+// the user to the names of the attributes
+func userInfo(a *assertion, mapping map[string]string) (*UserInfo, error) {
+	values := map[string]string{}
+	for _, attr := range a.Attributes {
+		if len(attr.Values) == 0 {
+			continue
+		}
+		values[attr.Name] = strings.TrimSpace(attr.Values[0])
+		if len(attr.FriendlyName) > 0 {
+			values[attr.FriendlyName] = strings.TrimSpace(attr.Values[0])
+		}
+	}
+	info := &UserInfo{
+		Issuer:   strings.TrimSpace(a.Issuer),
+		Subject:  strings.TrimSpace(a.Subject.NameID),
+		Username: values[mapping[attrUsername]],
+		Email:    values[mapping[attrEmail]],
+		Realname: values[mapping[attrRealname]],
+	}
+	if len(info.Subject) == 0 {
+		return nil, errors.New("no subject asserted in the SAML assertion")
+	}
+	if len(info.Username) == 0 {
+		info.Username = info.Subject
+	}
+	if len(info.Username) == 0 {
+		return nil, errors.New("no username asserted in the SAML assertion")
+	}
+	if a.AuthnStatement != nil && len(a.AuthnStatement.SessionNotOnOrAfter) > 0 {
+		t, err := time.Parse(time.RFC3339, a.AuthnStatement.SessionNotOnOrAfter)
+		if err != nil {
+			return nil, err
+		}
+		info.SessionNotOnOrAfter = t
+	}
+	return info, nil
+}
+
+// parseCertificate parses the certificate of the IdP, both the PEM and the base64 encoded DER
+// used in the IdP metadata are supported
+func parseCertificate(cert string) (*x509.Certificate, error) {
+	cert = strings.TrimSpace(cert)
+	if len(cert) == 0 {
+		return nil, errors.New("the certificate of the SAML IdP is not configured")
+	}
+	var der []byte
+	if block, _ := pem.Decode([]byte(cert)); block != nil {
+		der = block.Bytes
+	} else {
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(cert), ""))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid certificate of the SAML IdP")
+		}
+		der = b
+	}
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid certificate of the SAML IdP")
+	}
+	return c, nil
+}
+
+// rootName returns the name of the root element of the XML document
+func rootName(doc []byte) (xml.Name, error) {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if se, ok := t.(xml.StartElement); ok {
+			return se.Name, nil
+		}
+	}
+}
@@ -0,0 +1,432 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	beego_cache "github.com/astaxie/beego/cache"
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml/samlidp"
+	"github.com/goharbor/harbor/src/common/models"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRequestID = "id-request"
+	testACSURL    = "https://harbor.test/c/saml/callback"
+	testEntityID  = "https://harbor.test/c/saml/metadata"
+	testIdPSSOURL = "https://idp.test/sso?tenant=harbor"
+
+	testResponse = `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="id-response" InResponseTo="%s" Destination="https://harbor.test/c/saml/callback" Version="2.0">
+  <saml:Issuer>https://idp.test</saml:Issuer>
+  <samlp:Status><samlp:StatusCode Value="%s"/></samlp:Status>
+  <saml:Assertion ID="id-assertion" Version="2.0">
+    <saml:Issuer>https://idp.test</saml:Issuer>
+    <saml:Subject>
+      <saml:NameID>user01@idp.test</saml:NameID>
+      <saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">
+        <saml:SubjectConfirmationData InResponseTo="%s" Recipient="https://harbor.test/c/saml/callback" NotOnOrAfter="%s"/>
+      </saml:SubjectConfirmation>
+    </saml:Subject>
+    <saml:Conditions NotBefore="%s" NotOnOrAfter="%s">
+      <saml:AudienceRestriction><saml:Audience>%s</saml:Audience></saml:AudienceRestriction>
+    </saml:Conditions>
+    <saml:AuthnStatement AuthnInstant="%s" SessionNotOnOrAfter="%s"/>
+    <saml:AttributeStatement>
+      <saml:Attribute Name="urn:oid:0.9.2342.19200300.100.1.1" FriendlyName="uid"><saml:AttributeValue>user01</saml:AttributeValue></saml:Attribute>
+      <saml:Attribute Name="mail"><saml:AttributeValue>user01@harbor.test</saml:AttributeValue></saml:Attribute>
+      <saml:Attribute Name="cn"><saml:AttributeValue>User 01</saml:AttributeValue></saml:Attribute>
+    </saml:AttributeStatement>
+  </saml:Assertion>
+</samlp:Response>`
+)
+
+// testIdP signs the responses with the enveloped signature as the IdP does
+type testIdP struct {
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+	cert        string
+}
+
+// newTestIdP creates the IdP whose certificate expires at the time
+func newTestIdP(t *testing.T, notAfter time.Time) *testIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.test"},
+		NotBefore:    notAfter.Add(-2 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.Nil(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	return &testIdP{
+		key:         key,
+		certificate: certificate,
+		cert:        string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+// sign signs the response or only the assertion in it
+func (i *testIdP) sign(t *testing.T, doc string, assertionOnly bool) string {
+	ctx := dsig.NewDefaultSigningContext(dsig.TLSCertKeyStore(tls.Certificate{
+		Certificate: [][]byte{i.certificate.Raw},
+		PrivateKey:  i.key,
+	}))
+	ctx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	require.Nil(t, ctx.SetSignatureMethod(dsig.RSASHA256SignatureMethod))
+
+	d := etree.NewDocument()
+	require.Nil(t, d.ReadFromString(doc))
+	root := d.Root()
+	if assertionOnly {
+		el := root.SelectElement("Assertion")
+		require.NotNil(t, el)
+		nsCtx, err := etreeutils.NSBuildParentContext(el)
+		require.Nil(t, err)
+		nsCtx, err = nsCtx.SubContext(el)
+		require.Nil(t, err)
+		detached, err := etreeutils.NSDetatch(nsCtx, el)
+		require.Nil(t, err)
+		signed, err := ctx.SignEnveloped(detached)
+		require.Nil(t, err)
+		index := el.Index()
+		root.RemoveChildAt(index)
+		root.InsertChildAt(index, signed)
+	} else {
+		signed, err := ctx.SignEnveloped(root)
+		require.Nil(t, err)
+		d.SetRoot(signed)
+	}
+	signed, err := d.WriteToString()
+	require.Nil(t, err)
+	return signed
+}
+
+type responseOptions struct {
+	assertionOnly bool
+	inResponseTo  string
+	status        string
+	notBefore     time.Time
+	notOnOrAfter  time.Time
+	audience      string
+	// the response isn't for any request, as the IdP-initiated SSO does
+	unsolicited bool
+}
+
+func newResponse(t *testing.T, opts *responseOptions) string {
+	now := time.Now().UTC()
+	if opts.inResponseTo == "" {
+		opts.inResponseTo = testRequestID
+	}
+	if opts.status == "" {
+		opts.status = statusSuccess
+	}
+	if opts.notBefore.IsZero() {
+		opts.notBefore = now.Add(-time.Minute)
+	}
+	if opts.notOnOrAfter.IsZero() {
+		opts.notOnOrAfter = now.Add(5 * time.Minute)
+	}
+	if opts.audience == "" {
+		opts.audience = testEntityID
+	}
+	if opts.unsolicited {
+		opts.inResponseTo = ""
+	}
+	doc := fmt.Sprintf(testResponse, opts.inResponseTo, opts.status, opts.inResponseTo,
+		opts.notOnOrAfter.Format(time.RFC3339), opts.notBefore.Format(time.RFC3339),
+		opts.notOnOrAfter.Format(time.RFC3339), opts.audience, now.Format(time.RFC3339),
+		now.Add(8*time.Hour).Format(time.RFC3339))
+	// every assertion issued by the IdP has the unique ID
+	id, err := NewRequestID()
+	require.Nil(t, err)
+	doc = strings.Replace(doc, `ID="id-assertion"`, fmt.Sprintf(`ID="%s"`, id), 1)
+	return base64.StdEncoding.EncodeToString([]byte(idp.sign(t, doc, opts.assertionOnly)))
+}
+
+// idp is the IdP issuing the responses, it's set up by setup
+var idp *testIdP
+
+func setup(t *testing.T) func() {
+	idp = newTestIdP(t, time.Now().Add(time.Hour))
+	setting := &models.SAMLSetting{
+		IdPSSOURL:      testIdPSSOURL,
+		IdPCertificate: idp.cert,
+		EntityID:       testEntityID,
+		ACSURL:         testACSURL,
+		AttributeMapping: map[string]string{
+			attrUsername: "uid",
+			attrEmail:    "mail",
+			attrRealname: "cn",
+		},
+	}
+	origSetting := getSetting
+	getSetting = func() (*models.SAMLSetting, error) {
+		return setting, nil
+	}
+	origConsumed := consumedAssertions
+	consumedAssertions = &assertionCache{cache: beego_cache.NewMemoryCache()}
+	return func() {
+		getSetting = origSetting
+		consumedAssertions = origConsumed
+	}
+}
+
+func TestAuthnRequestURL(t *testing.T) {
+	defer setup(t)()
+
+	id, err := NewRequestID()
+	require.Nil(t, err)
+	assert.True(t, strings.HasPrefix(id, "id-"))
+
+	u, err := AuthnRequestURL(id, "state")
+	require.Nil(t, err)
+	parsed, err := url.Parse(u)
+	require.Nil(t, err)
+	assert.Equal(t, "idp.test", parsed.Host)
+	assert.Equal(t, "/sso", parsed.Path)
+	assert.Equal(t, "harbor", parsed.Query().Get("tenant"))
+	assert.Equal(t, "state", parsed.Query().Get("RelayState"))
+
+	compressed, err := base64.StdEncoding.DecodeString(parsed.Query().Get("SAMLRequest"))
+	require.Nil(t, err)
+	data, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	require.Nil(t, err)
+	req := &authnRequest{}
+	require.Nil(t, xml.Unmarshal(data, req))
+	assert.Equal(t, id, req.ID)
+	assert.Equal(t, testIdPSSOURL, req.Destination)
+	assert.Equal(t, testACSURL, req.AssertionConsumerServiceURL)
+	assert.Equal(t, testEntityID, req.Issuer.Value)
+}
+
+func TestMetadata(t *testing.T) {
+	defer setup(t)()
+
+	data, err := Metadata()
+	require.Nil(t, err)
+	ed := &entityDescriptor{}
+	require.Nil(t, xml.Unmarshal(data, ed))
+	assert.Equal(t, testEntityID, ed.EntityID)
+	assert.Equal(t, testACSURL, ed.SPSSODescriptor.AssertionConsumerService.Location)
+	assert.Equal(t, bindingHTTPPost, ed.SPSSODescriptor.AssertionConsumerService.Binding)
+}
+
+func TestParseResponse(t *testing.T) {
+	defer setup(t)()
+
+	// the response is signed
+	resp := newResponse(t, &responseOptions{})
+	info, err := ParseResponse(resp, testRequestID)
+	require.Nil(t, err)
+	assert.Equal(t, "https://idp.test", info.Issuer)
+	assert.Equal(t, "user01@idp.test", info.Subject)
+	assert.Equal(t, "user01", info.Username)
+	assert.Equal(t, "user01@harbor.test", info.Email)
+	assert.Equal(t, "User 01", info.Realname)
+	assert.False(t, info.SessionNotOnOrAfter.IsZero())
+
+	// the assertion is replayed
+	_, err = ParseResponse(resp, testRequestID)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "consumed")
+
+	// only the assertion is signed
+	info, err = ParseResponse(newResponse(t, &responseOptions{assertionOnly: true}), testRequestID)
+	require.Nil(t, err)
+	assert.Equal(t, "user01", info.Username)
+
+	// fall back to the name ID when the username attribute isn't asserted
+	setting, _ := getSetting()
+	setting.AttributeMapping[attrUsername] = "nonexistent"
+	info, err = ParseResponse(newResponse(t, &responseOptions{}), testRequestID)
+	require.Nil(t, err)
+	assert.Equal(t, "user01@idp.test", info.Username)
+	setting.AttributeMapping[attrUsername] = "uid"
+
+	cases := []struct {
+		name string
+		opts *responseOptions
+	}{
+		{"the response of other request", &responseOptions{inResponseTo: "id-other"}},
+		{"the unsolicited response", &responseOptions{unsolicited: true}},
+		{"the failed status", &responseOptions{status: "urn:oasis:names:tc:SAML:2.0:status:Requester"}},
+		{"the not yet valid assertion", &responseOptions{notBefore: time.Now().Add(time.Hour)}},
+		{"the expired assertion", &responseOptions{notOnOrAfter: time.Now().Add(-time.Hour)}},
+		{"the assertion for other audience", &responseOptions{audience: "https://other.test"}},
+	}
+	for _, c := range cases {
+		_, err = ParseResponse(newResponse(t, c.opts), testRequestID)
+		assert.NotNil(t, err, c.name)
+	}
+
+	// no pending request
+	_, err = ParseResponse(newResponse(t, &responseOptions{}), "")
+	assert.NotNil(t, err)
+
+	// the response isn't base64 encoded
+	_, err = ParseResponse("<samlp:Response/>", testRequestID)
+	assert.NotNil(t, err)
+
+	// the response is modified after being signed
+	for _, assertionOnly := range []bool{false, true} {
+		doc, err := base64.StdEncoding.DecodeString(newResponse(t, &responseOptions{assertionOnly: assertionOnly}))
+		require.Nil(t, err)
+		modified := strings.Replace(string(doc), ">user01<", ">admin<", 1)
+		_, err = ParseResponse(base64.StdEncoding.EncodeToString([]byte(modified)), testRequestID)
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "Signature could not be verified")
+	}
+
+	// the response is signed by other IdP
+	resp = newResponse(t, &responseOptions{})
+	setting.IdPCertificate = newTestIdP(t, time.Now().Add(time.Hour)).cert
+	_, err = ParseResponse(resp, testRequestID)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Could not verify certificate against trusted certs")
+
+	// the certificate of the IdP has expired
+	idp = newTestIdP(t, time.Now().Add(-time.Minute))
+	setting.IdPCertificate = idp.cert
+	_, err = ParseResponse(newResponse(t, &responseOptions{}), testRequestID)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Cert is not valid at this time")
+}
+
+func TestParseCertificate(t *testing.T) {
+	cert := newTestIdP(t, time.Now().Add(time.Hour)).cert
+	_, err := parseCertificate(cert)
+	assert.Nil(t, err)
+
+	// the base64 encoded DER used in the metadata of IdP
+	block, _ := pem.Decode([]byte(cert))
+	_, err = parseCertificate(base64.StdEncoding.EncodeToString(block.Bytes))
+	assert.Nil(t, err)
+
+	_, err = parseCertificate("")
+	assert.NotNil(t, err)
+	_, err = parseCertificate("invalid")
+	assert.NotNil(t, err)
+}
+
+// TestLoginViaIdP runs the SP-initiated SSO against the IdP of crewjam/saml
+func TestLoginViaIdP(t *testing.T) {
+	defer setup(t)()
+
+	var server *samlidp.Server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	idpURL, err := url.Parse(ts.URL)
+	require.Nil(t, err)
+	provider := newTestIdP(t, time.Now().Add(time.Hour))
+	server, err = samlidp.New(samlidp.Options{
+		URL:         *idpURL,
+		Key:         provider.key,
+		Certificate: provider.certificate,
+		Store:       &samlidp.MemoryStore{},
+	})
+	require.Nil(t, err)
+	server.IDP.SignatureMethod = dsig.RSASHA256SignatureMethod
+
+	setting, _ := getSetting()
+	setting.IdPSSOURL = ts.URL + "/sso"
+	setting.IdPCertificate = provider.cert
+	setting.AttributeMapping[attrEmail] = "eduPersonPrincipalName"
+
+	// register Harbor as the service provider and the user in the IdP
+	metadata, err := Metadata()
+	require.Nil(t, err)
+	put := func(path, body string) {
+		req, err := http.NewRequest(http.MethodPut, ts.URL+path, strings.NewReader(body))
+		require.Nil(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNoContent, resp.StatusCode, path)
+	}
+	put("/services/harbor", string(metadata))
+	put("/users/user01", `{"name":"user01","password":"Harbor12345","email":"user01@harbor.test","common_name":"User 01"}`)
+
+	requestID, err := NewRequestID()
+	require.Nil(t, err)
+	loginURL, err := AuthnRequestURL(requestID, "state")
+	require.Nil(t, err)
+	// the IdP asks the user to log in
+	resp, err := http.Get(loginURL)
+	require.Nil(t, err)
+	page, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	samlRequest := regexp.MustCompile(`name="SAMLRequest" value="([^"]+)"`).FindSubmatch(page)
+	require.NotNil(t, samlRequest, string(page))
+
+	// the IdP posts the response to Harbor via the browser after the user logs in
+	resp, err = http.PostForm(ts.URL+"/sso", url.Values{
+		"user":        {"user01"},
+		"password":    {"Harbor12345"},
+		"SAMLRequest": {html.UnescapeString(string(samlRequest[1]))},
+		"RelayState":  {"state"},
+	})
+	require.Nil(t, err)
+	page, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	samlResponse := regexp.MustCompile(`name="SAMLResponse" value="([^"]+)"`).FindSubmatch(page)
+	require.NotNil(t, samlResponse, string(page))
+	encoded := html.UnescapeString(string(samlResponse[1]))
+
+	info, err := ParseResponse(encoded, requestID)
+	require.Nil(t, err)
+	assert.Equal(t, ts.URL+"/metadata", info.Issuer)
+	assert.Equal(t, "user01@harbor.test", info.Subject)
+	assert.Equal(t, "user01", info.Username)
+	assert.Equal(t, "user01@harbor.test", info.Email)
+	assert.Equal(t, "User 01", info.Realname)
+
+	// the response can't be consumed again
+	_, err = ParseResponse(encoded, requestID)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,97 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saml
+
+import (
+	"bytes"
+	"crypto/x509"
+
+	"github.com/beevik/etree"
+	xrv "github.com/mattermost/xml-roundtrip-validator"
+	"github.com/pkg/errors"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+// clock is used to check the validity period of the certificate of the IdP, it's replaced in tests
+var clock *dsig.Clock
+
+// verifySignature verifies the enveloped signature of the SAML response, or of the assertion in it if
+// the response isn't signed, with the certificate of the IdP. The certificate must be valid at the time
+// being. It returns the signed element with the signature removed, only the returned element can be trusted.
+func verifySignature(doc []byte, cert *x509.Certificate) ([]byte, error) {
+	// reject the documents which can't survive the round trip of encoding/xml, as the response is
+	// parsed by encoding/xml after the verification
+	if err := xrv.Validate(bytes.NewReader(doc)); err != nil {
+		return nil, err
+	}
+	d := etree.NewDocument()
+	if err := d.ReadFromBytes(doc); err != nil {
+		return nil, err
+	}
+	root := d.Root()
+	if root == nil || root.Tag != "Response" || root.NamespaceURI() != nsProtocol {
+		return nil, errors.New("the root element is not a SAML response")
+	}
+
+	el := root
+	if !hasSignature(root) {
+		var assertions []*etree.Element
+		for _, child := range root.ChildElements() {
+			if child.Tag == "Assertion" && child.NamespaceURI() == nsAssertion {
+				assertions = append(assertions, child)
+			}
+		}
+		if len(assertions) != 1 {
+			return nil, errors.New("neither the SAML response nor the only assertion in it is signed")
+		}
+		el = assertions[0]
+	}
+
+	ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{
+		Roots: []*x509.Certificate{cert},
+	})
+	ctx.IdAttribute = "ID"
+	ctx.Clock = clock
+	// the element must carry the namespaces declared by its ancestors to be canonicalized alone
+	nsCtx, err := etreeutils.NSBuildParentContext(el)
+	if err != nil {
+		return nil, err
+	}
+	if nsCtx, err = nsCtx.SubContext(el); err != nil {
+		return nil, err
+	}
+	if el, err = etreeutils.NSDetatch(nsCtx, el); err != nil {
+		return nil, err
+	}
+	signed, err := ctx.Validate(el)
+	if err != nil {
+		return nil, err
+	}
+
+	result := etree.NewDocument()
+	result.SetRoot(signed)
+	return result.WriteToBytes()
+}
+
+// hasSignature returns whether the element has the enveloped signature as the child
+func hasSignature(el *etree.Element) bool {
+	for _, child := range el.ChildElements() {
+		if child.Tag == "Signature" && child.NamespaceURI() == dsig.Namespace {
+			return true
+		}
+	}
+	return false
+}
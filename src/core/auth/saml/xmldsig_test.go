@@ -0,0 +1,80 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUnsignedResponse = `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="id-response">
+  <saml:Issuer>https://idp.test</saml:Issuer>
+  %s
+</samlp:Response>`
+
+const testAssertion = `<saml:Assertion ID="%s"><saml:Issuer>https://idp.test</saml:Issuer><saml:Subject><saml:NameID>%s</saml:NameID></saml:Subject></saml:Assertion>`
+
+func TestVerifySignature(t *testing.T) {
+	p := newTestIdP(t, time.Now().Add(time.Hour))
+
+	// the response is signed
+	doc := fmt.Sprintf(testUnsignedResponse, fmt.Sprintf(testAssertion, "id-1", "user01"))
+	signed, err := verifySignature([]byte(p.sign(t, doc, false)), p.certificate)
+	require.Nil(t, err)
+	resp := &response{}
+	require.Nil(t, xml.Unmarshal(signed, resp))
+	assert.Equal(t, "id-response", resp.ID)
+	require.Equal(t, 1, len(resp.Assertions))
+	assert.Equal(t, "user01", resp.Assertions[0].Subject.NameID)
+	assert.NotContains(t, string(signed), "Signature")
+
+	// only the assertion is signed, the returned assertion carries the namespace declared by the response
+	signed, err = verifySignature([]byte(p.sign(t, doc, true)), p.certificate)
+	require.Nil(t, err)
+	a := &assertion{}
+	require.Nil(t, xml.Unmarshal(signed, a))
+	assert.Equal(t, "id-1", a.ID)
+	assert.Equal(t, "user01", a.Subject.NameID)
+
+	// nothing is signed
+	_, err = verifySignature([]byte(doc), p.certificate)
+	assert.NotNil(t, err)
+
+	// the other assertion is injected next to the signed one
+	injected := strings.Replace(p.sign(t, doc, true), "</samlp:Response>",
+		fmt.Sprintf(testAssertion, "id-2", "admin")+"</samlp:Response>", 1)
+	_, err = verifySignature([]byte(injected), p.certificate)
+	assert.NotNil(t, err)
+
+	// the root element isn't a response
+	_, err = verifySignature([]byte(fmt.Sprintf(testAssertion, "id-1", "user01")), p.certificate)
+	assert.NotNil(t, err)
+
+	// the certificate is checked at the time of the clock
+	defer func() {
+		clock = nil
+	}()
+	clock = dsig.NewFakeClockAt(time.Now().Add(2 * time.Hour))
+	_, err = verifySignature([]byte(p.sign(t, doc, false)), p.certificate)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Cert is not valid at this time")
+}
@@ -20,12 +20,14 @@ package config
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/goharbor/harbor/src/common"
 	comcfg "github.com/goharbor/harbor/src/common/config"
@@ -423,6 +425,129 @@ func ReadOnly() bool {
 	return cfgMgr.Get(common.ReadOnly).GetBool()
 }
 
+// AllowImpersonation returns a bool to indicate if the system admin can impersonate the other users.
+func AllowImpersonation() bool {
+	return cfgMgr.Get(common.AllowImpersonation).GetBool()
+}
+
+// AllowAdminOverride returns a bool to indicate if the security context can be overridden by the signed admin header.
+func AllowAdminOverride() bool {
+	return cfgMgr.Get(common.AllowAdminOverride).GetBool()
+}
+
+// BasicAuthCacheTTL returns how long the users authenticated by basic auth are cached, 0 means no cache.
+func BasicAuthCacheTTL() time.Duration {
+	return time.Duration(cfgMgr.Get(common.BasicAuthCacheTTL).GetInt()) * time.Second
+}
+
+// RobotTokenClockSkew returns the grace period allowed for the clock skew when validating the time of robot tokens.
+func RobotTokenClockSkew() time.Duration {
+	return time.Duration(cfgMgr.Get(common.RobotTokenClockSkew).GetInt()) * time.Second
+}
+
+// ClientCertAuth returns whether the requests can be authenticated by the verified client certificate.
+func ClientCertAuth() bool {
+	return cfgMgr.Get(common.ClientCertAuth).GetBool()
+}
+
+// ClientCertMapping returns the map from the CN or SAN of the client certificate to the name of user or robot account.
+func ClientCertMapping() (map[string]string, error) {
+	mapping := map[string]string{}
+	if err := json.Unmarshal([]byte(cfgMgr.Get(common.ClientCertMapping).GetString()), &mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// ReqCtxModifiers returns the names of the modifiers chained in order in the security filter,
+// empty means the default chain.
+func ReqCtxModifiers() []string {
+	return commaSeparatedList(common.ReqCtxModifiers)
+}
+
+// AuthMetricsEnabled returns whether the metrics of the authentication outcomes are collected.
+func AuthMetricsEnabled() bool {
+	return cfgMgr.Get(common.AuthMetricsEnabled).GetBool()
+}
+
+// BasicAuthFailureLimit returns the max number of failed basic auth attempts of one IP within the window,
+// and how long the IP is locked out once the limit is reached, the limit is disabled if the max isn't positive.
+func BasicAuthFailureLimit() (max int, window, lockout time.Duration) {
+	return cfgMgr.Get(common.BasicAuthMaxFailures).GetInt(),
+		time.Duration(cfgMgr.Get(common.BasicAuthFailureWindow).GetInt()) * time.Second,
+		time.Duration(cfgMgr.Get(common.BasicAuthLockout).GetInt()) * time.Second
+}
+
+// TrustedProxies returns the IPs or CIDRs of the proxies whose X-Forwarded-For header is honored.
+func TrustedProxies() []string {
+	return commaSeparatedList(common.TrustedProxies)
+}
+
+// SecurityFilterSkipPaths returns the paths which are skipped by the security filter.
+func SecurityFilterSkipPaths() []string {
+	return commaSeparatedList(common.SecurityFilterSkipPaths)
+}
+
+// ReadOnlyAllowedPaths returns the paths which can still be modified in read only mode, the path ending
+// with "*" matches the paths with the prefix.
+func ReadOnlyAllowedPaths() []string {
+	return commaSeparatedList(common.ReadOnlyAllowedPaths)
+}
+
+// ReadOnlyRetryAfter returns how long the client should wait before retrying the request rejected in read only mode.
+func ReadOnlyRetryAfter() time.Duration {
+	return time.Duration(cfgMgr.Get(common.ReadOnlyRetryAfter).GetInt()) * time.Second
+}
+
+// AnonymousAccessCIDRs returns the IPs or CIDRs from which the anonymous access is allowed, the anonymous
+// access isn't restricted if it's empty.
+func AnonymousAccessCIDRs() []string {
+	return commaSeparatedList(common.AnonymousAccessCIDRs)
+}
+
+// DisableBasicAuthAPI returns whether the basic auth is only honored for the token service and registry.
+func DisableBasicAuthAPI() bool {
+	return cfgMgr.Get(common.DisableBasicAuthAPI).GetBool()
+}
+
+// ScanReportCacheTTL returns the seconds within which the report of the digest is reused instead of scanning again,
+// the report isn't reused if it isn't positive.
+func ScanReportCacheTTL() int {
+	return cfgMgr.Get(common.ScanReportCacheTTL).GetInt()
+}
+
+// commaSeparatedList splits the value of the config item by comma, the empty elements are dropped
+func commaSeparatedList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(cfgMgr.Get(key).GetString(), ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// InternalSecretGracePeriod returns how long the previous internal secret is still valid after the rotation.
+func InternalSecretGracePeriod() time.Duration {
+	return time.Duration(cfgMgr.Get(common.InternalSecretGracePeriod).GetInt()) * time.Second
+}
+
+// RiskScoreWeights returns the weights of the factors combined into the risk score of project.
+func RiskScoreWeights() (map[string]float64, error) {
+	weights := map[string]float64{}
+	if err := json.Unmarshal([]byte(cfgMgr.Get(common.RiskScoreWeights).GetString()), &weights); err != nil {
+		return nil, err
+	}
+	return weights, nil
+}
+
+// AdminJobRetention returns how many finished executions are kept per admin job and how long they are kept,
+// the limit is disabled if it isn't positive.
+func AdminJobRetention() (count int, age time.Duration) {
+	return cfgMgr.Get(common.AdminJobRetentionCount).GetInt(),
+		time.Duration(cfgMgr.Get(common.AdminJobRetentionDays).GetInt()) * 24 * time.Hour
+}
+
 // WithChartMuseum returns a bool to indicate if chartmuseum is deployed with Harbor.
 func WithChartMuseum() bool {
 	return cfgMgr.Get(common.WithChartMuseum).GetBool()
@@ -482,6 +607,8 @@ func HTTPAuthProxySetting() (*models.HTTPAuthProxy, error) {
 		TokenReviewEndpoint: cfgMgr.Get(common.HTTPAuthProxyTokenReviewEndpoint).GetString(),
 		VerifyCert:          cfgMgr.Get(common.HTTPAuthProxyVerifyCert).GetBool(),
 		SkipSearch:          cfgMgr.Get(common.HTTPAuthProxySkipSearch).GetBool(),
+		AutoOnboard:         cfgMgr.Get(common.HTTPAuthProxyAutoOnboard).GetBool(),
+		APIAccess:           cfgMgr.Get(common.HTTPAuthProxyAPIAccess).GetBool(),
 	}, nil
 
 }
@@ -511,11 +638,50 @@ func OIDCSetting() (*models.OIDCSetting, error) {
 	}, nil
 }
 
+// SAMLSetting returns the setting of SAML auth
+func SAMLSetting() (*models.SAMLSetting, error) {
+	if err := cfgMgr.Load(); err != nil {
+		return nil, err
+	}
+	extEndpoint := strings.TrimSuffix(cfgMgr.Get(common.ExtEndpoint).GetString(), "/")
+	entityID := cfgMgr.Get(common.SAMLSPEntityID).GetString()
+	if len(entityID) == 0 {
+		entityID = extEndpoint + common.SAMLMetadataPath
+	}
+	mapping := map[string]string{}
+	if err := json.Unmarshal([]byte(cfgMgr.Get(common.SAMLAttributeMapping).GetString()), &mapping); err != nil {
+		return nil, err
+	}
+
+	return &models.SAMLSetting{
+		IdPSSOURL:        cfgMgr.Get(common.SAMLIdPSSOURL).GetString(),
+		IdPCertificate:   cfgMgr.Get(common.SAMLIdPCertificate).GetString(),
+		EntityID:         entityID,
+		ACSURL:           extEndpoint + common.SAMLCallbackPath,
+		AttributeMapping: mapping,
+	}, nil
+}
+
 // NotificationEnable returns a bool to indicates if notification enabled in harbor
 func NotificationEnable() bool {
 	return cfgMgr.Get(common.NotificationEnable).GetBool()
 }
 
+// AdminJobNotificationEnable returns whether the status changes of admin jobs are notified
+func AdminJobNotificationEnable() bool {
+	return cfgMgr.Get(common.AdminJobNotificationEnable).GetBool()
+}
+
+// AdminJobNotificationStatuses returns the statuses of admin job which trigger the notification
+func AdminJobNotificationStatuses() []string {
+	return commaSeparatedList(common.AdminJobNotificationStatuses)
+}
+
+// AdminJobNotificationAddress returns the webhook address the notification of admin jobs is sent to
+func AdminJobNotificationAddress() string {
+	return strings.TrimSpace(cfgMgr.Get(common.AdminJobNotificationAddress).GetString())
+}
+
 // QuotaPerProjectEnable returns a bool to indicates if quota per project enabled in harbor
 func QuotaPerProjectEnable() bool {
 	return cfgMgr.Get(common.QuotaPerProjectEnable).GetBool()
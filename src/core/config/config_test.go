@@ -254,3 +254,20 @@ func TestOIDCSetting(t *testing.T) {
 	assert.Equal(t, "https://harbor.test/c/oidc/callback", v.RedirectURL)
 	assert.ElementsMatch(t, []string{"openid", "profile"}, v.Scope)
 }
+
+func TestSAMLSetting(t *testing.T) {
+	m := map[string]interface{}{
+		common.SAMLIdPSSOURL:        "https://idp.test/sso",
+		common.SAMLIdPCertificate:   "cert",
+		common.SAMLAttributeMapping: `{"username":"uid","email":"mail"}`,
+		common.ExtEndpoint:          "https://harbor.test",
+	}
+	InitWithSettings(m)
+	v, e := SAMLSetting()
+	assert.Nil(t, e)
+	assert.Equal(t, "https://idp.test/sso", v.IdPSSOURL)
+	assert.Equal(t, "cert", v.IdPCertificate)
+	assert.Equal(t, "https://harbor.test/c/saml/metadata", v.EntityID)
+	assert.Equal(t, "https://harbor.test/c/saml/callback", v.ACSURL)
+	assert.Equal(t, map[string]string{"username": "uid", "email": "mail"}, v.AttributeMapping)
+}
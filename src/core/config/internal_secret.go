@@ -0,0 +1,102 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/secret"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+// InternalSecretSyncInterval is the interval to load the internal secrets from the database, so the
+// rotation done by another core instance takes effect in it at most
+const InternalSecretSyncInterval = time.Minute
+
+// RotateJobserviceSecret persists the new secret of jobservice in the database, so it's loaded by
+// all the core instances, and applies it to the SecretStore. The secret read from the environment
+// is retired by the first rotation, the previous secret is still accepted within the grace period.
+func RotateJobserviceSecret(scrt string, grace time.Duration) error {
+	key, err := SecretKey()
+	if err != nil {
+		return err
+	}
+	previous := ""
+	if len(JobserviceSecret()) > 0 {
+		if previous, err = utils.ReversibleEncrypt(JobserviceSecret(), key); err != nil {
+			return err
+		}
+	}
+	current, err := utils.ReversibleEncrypt(scrt, key)
+	if err != nil {
+		return err
+	}
+	if err = dao.RotateInternalSecret(secret.JobserviceUser, previous, current, grace); err != nil {
+		return err
+	}
+	SecretStore.Rotate(secret.JobserviceUser, scrt, grace)
+	return nil
+}
+
+// LoadJobserviceSecrets applies the secrets of jobservice rotated via the API to the SecretStore,
+// the secret read from the environment is used if it has never been rotated
+func LoadJobserviceSecrets() error {
+	secrets, err := dao.GetInternalSecrets(secret.JobserviceUser)
+	if err != nil {
+		return err
+	}
+	if len(secrets) == 0 || !secrets[0].ExpiresAt.IsZero() {
+		return nil
+	}
+	key, err := SecretKey()
+	if err != nil {
+		return err
+	}
+	current := ""
+	previous := map[string]time.Time{}
+	for _, s := range secrets {
+		plain, err := utils.ReversibleDecrypt(s.Secret, key)
+		if err != nil {
+			return err
+		}
+		if s.ExpiresAt.IsZero() {
+			current = plain
+			continue
+		}
+		previous[plain] = s.ExpiresAt
+	}
+	SecretStore.Set(secret.JobserviceUser, current, previous)
+	return nil
+}
+
+// WatchJobserviceSecrets loads the secrets of jobservice periodically until the stop channel is closed
+func WatchJobserviceSecrets(stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(InternalSecretSyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := LoadJobserviceSecrets(); err != nil {
+					log.Warningf("failed to load the secrets of jobservice: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
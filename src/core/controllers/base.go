@@ -121,6 +121,24 @@ func (cc *CommonController) LogOut() {
 	cc.DestroySession()
 }
 
+// CSRFToken returns the CSRF token of the session to UI, the token must be carried by the state-changing
+// API requests. The token is issued here for the sessions established before the CSRF token is introduced.
+func (cc *CommonController) CSRFToken() {
+	if _, ok := cc.GetSession("user").(models.User); !ok {
+		cc.CustomAbort(http.StatusUnauthorized, "")
+	}
+	token, _ := cc.GetSession(filter.SessionCSRFTokenKey).(string)
+	if len(token) == 0 {
+		token = filter.NewCSRFToken()
+		cc.SetSession(filter.SessionCSRFTokenKey, token)
+	}
+	cc.Ctx.ResponseWriter.Header().Set(filter.CSRFTokenHeader, token)
+	cc.Data["json"] = struct {
+		Token string `json:"token"`
+	}{token}
+	cc.ServeJSON()
+}
+
 // UserExists checks if user exists when user input value in sign in form.
 func (cc *CommonController) UserExists() {
 	target := cc.GetString("target")
@@ -262,6 +280,7 @@ func (cc *CommonController) ResetPassword() {
 			log.Errorf("Error occurred in ResetUserPassword: %v", err)
 			cc.CustomAbort(http.StatusInternalServerError, "Internal error.")
 		}
+		filter.InvalidateBasicAuthCache(user.UserID)
 	} else {
 		cc.CustomAbort(http.StatusBadRequest, "password_is_required")
 	}
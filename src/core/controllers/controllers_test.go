@@ -47,10 +47,14 @@ func init() {
 
 	beego.Router("/c/login", &CommonController{}, "post:Login")
 	beego.Router("/c/log_out", &CommonController{}, "get:LogOut")
+	beego.Router("/c/csrf_token", &CommonController{}, "get:CSRFToken")
 	beego.Router("/c/reset", &CommonController{}, "post:ResetPassword")
 	beego.Router("/c/userExists", &CommonController{}, "post:UserExists")
 	beego.Router("/c/sendEmail", &CommonController{}, "get:SendResetEmail")
 	beego.Router("/v2/*", &RegistryProxy{}, "*:Handle")
+	beego.Router(common.SAMLLoginPath, &SAMLController{}, "get:RedirectLogin")
+	beego.Router(common.SAMLCallbackPath, &SAMLController{}, "post:Callback")
+	beego.Router(common.SAMLMetadataPath, &SAMLController{}, "get:Metadata")
 }
 
 func TestMain(m *testing.M) {
@@ -117,6 +121,11 @@ func TestAll(t *testing.T) {
 	assert.Equal(int(200), w.Code, "'/c/log_out' httpStatusCode should be 200")
 	assert.Equal(true, strings.Contains(fmt.Sprintf("%s", w.Body), ""), "http respond should be empty")
 
+	r, _ = http.NewRequest("GET", "/c/csrf_token", nil)
+	w = httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+	assert.Equal(int(401), w.Code, "'/c/csrf_token' httpStatusCode should be 401 without session")
+
 	r, _ = http.NewRequest("POST", "/c/reset", nil)
 	w = httptest.NewRecorder()
 	beego.BeeApp.Handlers.ServeHTTP(w, r)
@@ -148,3 +157,40 @@ func TestAll(t *testing.T) {
 	assert.Equal(int(404), w.Code, "GET v2/noproject/manifests/1.0 should get a 404 response")
 
 }
+
+func TestSAMLController(t *testing.T) {
+	assert := assert.New(t)
+	cfg := utilstest.GetUnitTestConfig()
+	config.InitWithSettings(cfg)
+
+	// not SAML auth mode
+	r, _ := http.NewRequest(http.MethodGet, common.SAMLMetadataPath, nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+	assert.Equal(http.StatusPreconditionFailed, w.Code)
+
+	cfg[common.AUTHMode] = common.SAMLAuth
+	cfg[common.ExtEndpoint] = "https://harbor.test"
+	cfg[common.SAMLIdPSSOURL] = "https://idp.test/sso"
+	config.InitWithSettings(cfg)
+	defer config.InitWithSettings(utilstest.GetUnitTestConfig())
+
+	r, _ = http.NewRequest(http.MethodGet, common.SAMLMetadataPath, nil)
+	w = httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("application/samlmetadata+xml", w.Header().Get("Content-Type"))
+	assert.Contains(w.Body.String(), `entityID="https://harbor.test/c/saml/metadata"`)
+
+	r, _ = http.NewRequest(http.MethodGet, common.SAMLLoginPath, nil)
+	w = httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+	assert.Equal(http.StatusFound, w.Code)
+	assert.True(strings.HasPrefix(w.Header().Get("Location"), "https://idp.test/sso?"))
+
+	// no pending authentication request
+	r, _ = http.NewRequest(http.MethodPost, common.SAMLCallbackPath, nil)
+	w = httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+	assert.Equal(http.StatusBadRequest, w.Code)
+}
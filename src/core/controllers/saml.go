@@ -0,0 +1,125 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/api"
+	"github.com/goharbor/harbor/src/core/auth/saml"
+	"github.com/goharbor/harbor/src/core/config"
+)
+
+const samlRequestIDKey = "saml_request_id"
+const samlStateKey = "saml_state"
+
+// SAMLController handles requests for the SP-initiated SSO via the SAML IdP and the SP metadata
+type SAMLController struct {
+	api.BaseController
+}
+
+// Prepare include public code path for call request handler of SAMLController
+func (sc *SAMLController) Prepare() {
+	if mode, _ := config.AuthMode(); mode != common.SAMLAuth {
+		sc.SendPreconditionFailedError(fmt.Errorf("auth mode: %s is not SAML based", mode))
+		return
+	}
+}
+
+// RedirectLogin redirects user's browser to the SAML IdP with the authentication request
+func (sc *SAMLController) RedirectLogin() {
+	requestID, err := saml.NewRequestID()
+	if err != nil {
+		sc.SendInternalServerError(err)
+		return
+	}
+	state := utils.GenerateRandomString()
+	url, err := saml.AuthnRequestURL(requestID, state)
+	if err != nil {
+		sc.SendInternalServerError(err)
+		return
+	}
+	sc.SetSession(samlRequestIDKey, requestID)
+	sc.SetSession(samlStateKey, state)
+	log.Debugf("SAML authentication request %s dumped to session", requestID)
+	// Force to use the func 'Redirect' of beego.Controller
+	sc.Controller.Redirect(url, http.StatusFound)
+}
+
+// Callback handles the response posted by the SAML IdP, it onboards the asserted user if needed
+// and populates the session
+func (sc *SAMLController) Callback() {
+	requestID, ok := sc.GetSession(samlRequestIDKey).(string)
+	if !ok {
+		sc.SendBadRequestError(errors.New("no pending SAML authentication request"))
+		return
+	}
+	state := sc.GetSession(samlStateKey)
+	// the authentication request can only be responded once
+	sc.DelSession(samlRequestIDKey)
+	sc.DelSession(samlStateKey)
+	if sc.GetString("RelayState") != state {
+		log.Errorf("State mismatch, in session: %s, in form: %s", state, sc.GetString("RelayState"))
+		sc.SendBadRequestError(errors.New("State mismatch"))
+		return
+	}
+
+	info, err := saml.ParseResponse(sc.GetString("SAMLResponse"), requestID)
+	if err != nil {
+		log.Errorf("Failed to verify the SAML response, error: %v", err)
+		sc.SendUnAuthorizedError(errors.New("failed to verify the SAML response"))
+		return
+	}
+
+	u, err := saml.OnBoardUser(info)
+	if err != nil {
+		if strings.Contains(err.Error(), dao.ErrDupUser.Error()) {
+			sc.RenderError(http.StatusConflict, fmt.Sprintf("Conflict in username, the user %s has been onboarded.", info.Username))
+			return
+		}
+		sc.SendInternalServerError(fmt.Errorf("failed to onboard the user %s: %v", info.Username, err))
+		return
+	}
+	if u.Disabled {
+		sc.SendForbiddenError(fmt.Errorf("the user %s is disabled", u.Username))
+		return
+	}
+
+	sc.PopulateUserSession(*u)
+	if !info.SessionNotOnOrAfter.IsZero() {
+		sc.SetSession(saml.SessionExpiryKey, info.SessionNotOnOrAfter.Unix())
+	}
+	sc.Controller.Redirect("/", http.StatusFound)
+}
+
+// Metadata returns the metadata of Harbor as the SAML service provider, which is imported by the IdP
+func (sc *SAMLController) Metadata() {
+	data, err := saml.Metadata()
+	if err != nil {
+		sc.SendInternalServerError(err)
+		return
+	}
+	sc.Ctx.ResponseWriter.Header().Set(http.CanonicalHeaderKey("Content-Type"), "application/samlmetadata+xml")
+	if _, err = sc.Ctx.ResponseWriter.Write(data); err != nil {
+		log.Errorf("Failed to write the SAML metadata, error: %v", err)
+	}
+}
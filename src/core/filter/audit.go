@@ -0,0 +1,80 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+const (
+	// requestIDHeader is the header carrying the request ID, the one set by the upstream proxy is reused
+	requestIDHeader = "X-Request-ID"
+	// the max length of the request ID reused from the header
+	maxRequestIDLen = 128
+	// the principal logged when the request isn't authenticated
+	anonymousPrincipal = "-"
+)
+
+// auditLogger is the logger of the auth audit records
+var auditLogger = log.DefaultLogger()
+
+// GetRequestID returns the ID of the request assigned by the SecurityFilter, an empty string is
+// returned if there is no such ID
+func GetRequestID(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	id, _ := req.Context().Value(RequestIDKey).(string)
+	return id
+}
+
+// requestID reuses the ID in the X-Request-ID header if it's valid, otherwise a new one is generated
+func requestID(req *http.Request) string {
+	id := req.Header.Get(requestIDHeader)
+	if isValidRequestID(id) {
+		return id
+	}
+	return utils.GenerateRandomString()
+}
+
+// isValidRequestID only accepts the IDs which are safe to be logged and echoed back in the header
+func isValidRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// auditAuth logs who is authenticated by which modifier for the request, the credentials and the
+// query string which may carry them are never logged
+func auditAuth(req *http.Request, authMethod string) {
+	principal := anonymousPrincipal
+	if sc, err := GetSecurityContext(req); err == nil && sc.IsAuthenticated() {
+		principal = sc.GetUsername()
+	}
+	auditLogger.Infof("auth audit: request_id=%s auth_method=%s principal=%q client_ip=%s method=%s path=%q",
+		GetRequestID(req), authMethod, principal, sourceIP(req), req.Method, req.URL.Path)
+}
@@ -0,0 +1,117 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	commonsecret "github.com/goharbor/harbor/src/common/secret"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidRequestID(t *testing.T) {
+	cases := []struct {
+		id    string
+		valid bool
+	}{
+		{"", false},
+		{"4f1b2c3d-5e6f-7a8b-9c0d-e1f2a3b4c5d6", true},
+		{"req_1.2:3", true},
+		{"has space", false},
+		{"line\nbreak", false},
+		{"quote\"d", false},
+		{strings.Repeat("a", maxRequestIDLen), true},
+		{strings.Repeat("a", maxRequestIDLen+1), false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.valid, isValidRequestID(c.id), c.id)
+	}
+}
+
+func TestGetRequestID(t *testing.T) {
+	assert.Equal(t, "", GetRequestID(nil))
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects", nil)
+	require.Nil(t, err)
+	assert.Equal(t, "", GetRequestID(req))
+	addToReqContext(req, RequestIDKey, "id")
+	assert.Equal(t, "id", GetRequestID(req))
+}
+
+func TestSecurityFilterAudit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	origin := auditLogger
+	auditLogger = log.New(buf, log.NewTextFormatter(), log.InfoLevel)
+	defer func() {
+		auditLogger = origin
+	}()
+
+	// the request ID in the header is reused
+	req, err := http.NewRequest(http.MethodPost, "http://127.0.0.1/api/projects?token=query-secret", nil)
+	require.Nil(t, err)
+	req.RemoteAddr = "10.0.0.1:12345"
+	req.Header.Set(requestIDHeader, "req-1")
+	req.SetBasicAuth("admin", "Harbor12345")
+	ctx, err := newContext(req)
+	require.Nil(t, err)
+	SecurityFilter(ctx)
+	assert.Equal(t, "req-1", GetRequestID(ctx.Request))
+	assert.Equal(t, "req-1", ctx.ResponseWriter.Header().Get(requestIDHeader))
+
+	record := buf.String()
+	assert.Equal(t, 1, strings.Count(record, "auth audit:"))
+	assert.Contains(t, record, "request_id=req-1")
+	assert.Contains(t, record, "auth_method="+ModifierBasicAuth)
+	assert.Contains(t, record, `principal="admin"`)
+	assert.Contains(t, record, "client_ip=10.0.0.1")
+	assert.Contains(t, record, "method=POST")
+	assert.Contains(t, record, `path="/api/projects"`)
+	assert.NotContains(t, record, "Harbor12345")
+	assert.NotContains(t, record, "query-secret")
+	assert.NotContains(t, record, req.Header.Get("Authorization"))
+
+	// a new request ID is generated for the anonymous request carrying an invalid one
+	buf.Reset()
+	req, err = http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects", nil)
+	require.Nil(t, err)
+	req.Header.Set(requestIDHeader, "bad id\n")
+	ctx, err = newContext(req)
+	require.Nil(t, err)
+	SecurityFilter(ctx)
+	id := GetRequestID(ctx.Request)
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, ctx.ResponseWriter.Header().Get(requestIDHeader))
+	record = buf.String()
+	assert.Contains(t, record, "request_id="+id)
+	assert.Contains(t, record, "auth_method="+ModifierUnauthorized)
+	assert.Contains(t, record, `principal="-"`)
+	assert.NotContains(t, record, "bad id")
+
+	// the secret is never logged
+	buf.Reset()
+	req, err = http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects", nil)
+	require.Nil(t, err)
+	require.Nil(t, commonsecret.AddToRequest(req, "audit-secret-value"))
+	ctx, err = newContext(req)
+	require.Nil(t, err)
+	SecurityFilter(ctx)
+	record = buf.String()
+	assert.Equal(t, 1, strings.Count(record, "auth audit:"))
+	assert.NotContains(t, record, "audit-secret-value")
+}
@@ -0,0 +1,150 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+// the max number of the users authenticated by basic auth cached
+const basicAuthCacheSize = 1024
+
+// basicAuthResults caches the users authenticated by basic auth to avoid authenticating the
+// credentials against the backend, e.g. LDAP, for every request
+var basicAuthResults = newBasicAuthCache(basicAuthCacheSize)
+
+// InvalidateBasicAuthCache removes the cached basic auth results of the user, it should be
+// called once the user is updated or deleted. The results cached by the other core instances
+// are kept until they expire.
+func InvalidateBasicAuthCache(userID int) {
+	basicAuthResults.invalidate(userID)
+}
+
+// basicAuthCache is a LRU cache of the authenticated users keyed by the HMAC of the credential,
+// the plaintext password is never stored
+type basicAuthCache struct {
+	lock    sync.Mutex
+	size    int
+	hmacKey []byte
+	// the elements in the list are the *basicAuthEntry, the most recently used ones go first
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type basicAuthEntry struct {
+	key       string
+	user      *models.User
+	expiresAt time.Time
+}
+
+func newBasicAuthCache(size int) *basicAuthCache {
+	// the key is generated by each core instance and only lives in memory
+	hmacKey := make([]byte, sha256.Size)
+	if _, err := rand.Read(hmacKey); err != nil {
+		log.Errorf("failed to generate the key of basic auth cache: %v", err)
+	}
+	return &basicAuthCache{
+		size:    size,
+		hmacKey: hmacKey,
+		lru:     list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func (b *basicAuthCache) key(username, password string) string {
+	mac := hmac.New(sha256.New, b.hmacKey)
+	// separate the username and password to avoid the ambiguity of the concatenation
+	mac.Write([]byte(username))
+	mac.Write([]byte{0})
+	mac.Write([]byte(password))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// get returns a copy of the cached user authenticated by the credential, nil if the credential
+// isn't cached or the result expires
+func (b *basicAuthCache) get(username, password string) *models.User {
+	key := b.key(username, password)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	elem, ok := b.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := elem.Value.(*basicAuthEntry)
+	if time.Now().After(entry.expiresAt) {
+		b.remove(elem)
+		return nil
+	}
+	b.lru.MoveToFront(elem)
+	user := *entry.user
+	return &user
+}
+
+// put caches the user authenticated by the credential for the ttl, the least recently used
+// one is evicted when the cache is full
+func (b *basicAuthCache) put(username, password string, user *models.User, ttl time.Duration) {
+	if ttl <= 0 || user == nil {
+		return
+	}
+	key := b.key(username, password)
+	u := *user
+	// the password stored in the user model isn't needed to build the security context
+	u.Password = ""
+	entry := &basicAuthEntry{
+		key:       key,
+		user:      &u,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if elem, ok := b.entries[key]; ok {
+		elem.Value = entry
+		b.lru.MoveToFront(elem)
+		return
+	}
+	b.entries[key] = b.lru.PushFront(entry)
+	for b.lru.Len() > b.size {
+		b.remove(b.lru.Back())
+	}
+}
+
+// invalidate removes all the cached results of the user
+func (b *basicAuthCache) invalidate(userID int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for elem := b.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if elem.Value.(*basicAuthEntry).user.UserID == userID {
+			b.remove(elem)
+		}
+		elem = next
+	}
+}
+
+// remove removes the element, the caller must hold the lock
+func (b *basicAuthCache) remove(elem *list.Element) {
+	b.lru.Remove(elem)
+	delete(b.entries, elem.Value.(*basicAuthEntry).key)
+}
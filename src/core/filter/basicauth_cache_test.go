@@ -0,0 +1,120 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasicAuthCache(t *testing.T) {
+	cache := newBasicAuthCache(2)
+	user := &models.User{UserID: 1, Username: "user1", Password: "hashed"}
+
+	assert.Nil(t, cache.get("user1", "Harbor12345"))
+	// not cached when the TTL is 0
+	cache.put("user1", "Harbor12345", user, 0)
+	assert.Nil(t, cache.get("user1", "Harbor12345"))
+
+	cache.put("user1", "Harbor12345", user, time.Minute)
+	u := cache.get("user1", "Harbor12345")
+	require.NotNil(t, u)
+	assert.Equal(t, 1, u.UserID)
+	assert.Empty(t, u.Password)
+	// the wrong password doesn't hit the cache
+	assert.Nil(t, cache.get("user1", "wrong"))
+	// the username and password are separated in the key
+	assert.Nil(t, cache.get("user1H", "arbor12345"))
+
+	// the copy returned can't change the cached one
+	u.Username = "changed"
+	assert.Equal(t, "user1", cache.get("user1", "Harbor12345").Username)
+
+	// the plaintext password isn't stored
+	for key := range cache.entries {
+		assert.False(t, strings.Contains(key, "Harbor12345"))
+	}
+	for elem := cache.lru.Front(); elem != nil; elem = elem.Next() {
+		assert.NotEqual(t, "Harbor12345", elem.Value.(*basicAuthEntry).user.Password)
+	}
+}
+
+func TestBasicAuthCacheExpiry(t *testing.T) {
+	cache := newBasicAuthCache(2)
+	cache.put("user1", "Harbor12345", &models.User{UserID: 1}, 10*time.Millisecond)
+	require.NotNil(t, cache.get("user1", "Harbor12345"))
+	time.Sleep(20 * time.Millisecond)
+	assert.Nil(t, cache.get("user1", "Harbor12345"))
+	// the expired entry is removed
+	assert.Equal(t, 0, cache.lru.Len())
+	assert.Equal(t, 0, len(cache.entries))
+}
+
+func TestBasicAuthCacheEviction(t *testing.T) {
+	cache := newBasicAuthCache(2)
+	cache.put("user1", "p1", &models.User{UserID: 1}, time.Minute)
+	cache.put("user2", "p2", &models.User{UserID: 2}, time.Minute)
+	// user1 becomes the most recently used one
+	require.NotNil(t, cache.get("user1", "p1"))
+	// user2 is evicted
+	cache.put("user3", "p3", &models.User{UserID: 3}, time.Minute)
+	assert.NotNil(t, cache.get("user1", "p1"))
+	assert.Nil(t, cache.get("user2", "p2"))
+	assert.NotNil(t, cache.get("user3", "p3"))
+	assert.Equal(t, 2, cache.lru.Len())
+	assert.Equal(t, 2, len(cache.entries))
+}
+
+func TestBasicAuthCacheInvalidate(t *testing.T) {
+	cache := newBasicAuthCache(10)
+	cache.put("user1", "p1", &models.User{UserID: 1}, time.Minute)
+	cache.put("user1", "p2", &models.User{UserID: 1}, time.Minute)
+	cache.put("user2", "p2", &models.User{UserID: 2}, time.Minute)
+	cache.invalidate(1)
+	assert.Nil(t, cache.get("user1", "p1"))
+	assert.Nil(t, cache.get("user1", "p2"))
+	assert.NotNil(t, cache.get("user2", "p2"))
+}
+
+func TestBasicAuthCacheConcurrency(t *testing.T) {
+	cache := newBasicAuthCache(16)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				username := fmt.Sprintf("user%d", (i+j)%24)
+				cache.put(username, "password", &models.User{UserID: (i + j) % 24, Username: username}, time.Minute)
+				if u := cache.get(username, "password"); u != nil {
+					assert.Equal(t, username, u.Username)
+				}
+				if j%10 == 0 {
+					cache.invalidate(i % 24)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.True(t, cache.lru.Len() <= 16)
+	assert.Equal(t, cache.lru.Len(), len(cache.entries))
+}
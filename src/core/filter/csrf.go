@@ -0,0 +1,70 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	beegoctx "github.com/astaxie/beego/context"
+	commonhttp "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+const (
+	// CSRFTokenHeader is the header carrying the CSRF token of the session, it's required by the
+	// state-changing API requests authenticated by the session
+	CSRFTokenHeader = "X-Harbor-CSRF-Token"
+	// SessionCSRFTokenKey is the key of the session value which stores the CSRF token, the token is
+	// issued once the user logs in
+	SessionCSRFTokenKey = "csrf_token"
+)
+
+// NewCSRFToken generates a new CSRF token for the session
+func NewCSRFToken() string {
+	return utils.GenerateRandomString()
+}
+
+// csrfProtected checks whether the request changes the state via the API, the session of such
+// request must be proved by the CSRF token
+func csrfProtected(req *http.Request) bool {
+	return !isReadRequest(req) && strings.HasPrefix(req.URL.Path, "/api/")
+}
+
+// validCSRFToken checks whether the request carries the CSRF token stored in the session
+func validCSRFToken(ctx *beegoctx.Context) bool {
+	expected, _ := ctx.Input.Session(SessionCSRFTokenKey).(string)
+	token := ctx.Request.Header.Get(CSRFTokenHeader)
+	if len(expected) == 0 || len(token) == 0 {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1
+}
+
+// rejectCSRF rejects the request whose CSRF token is missing or mismatched
+func rejectCSRF(ctx *beegoctx.Context, username string) bool {
+	req := ctx.Request
+	log.Warningf("the request %s %s of user %s is rejected as the CSRF token is missing or invalid", req.Method, req.URL.Path, username)
+	e := &commonhttp.Error{
+		Code:    http.StatusForbidden,
+		Message: "the CSRF token is missing or invalid",
+	}
+	ctx.ResponseWriter.Header().Set("Content-Type", "application/json")
+	ctx.ResponseWriter.WriteHeader(e.Code)
+	ctx.ResponseWriter.Write([]byte(e.String()))
+	return true
+}
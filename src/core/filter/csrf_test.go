@@ -0,0 +1,157 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/astaxie/beego"
+	beegoctx "github.com/astaxie/beego/context"
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	commonsecret "github.com/goharbor/harbor/src/common/secret"
+	"github.com/goharbor/harbor/src/common/security/local"
+	robotCtx "github.com/goharbor/harbor/src/common/security/robot"
+	"github.com/goharbor/harbor/src/common/security/secret"
+	"github.com/goharbor/harbor/src/pkg/robot"
+	robotModel "github.com/goharbor/harbor/src/pkg/robot/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSRFProtected(t *testing.T) {
+	cases := []struct {
+		method    string
+		path      string
+		protected bool
+	}{
+		{http.MethodGet, "/api/projects", false},
+		{http.MethodHead, "/api/projects", false},
+		{http.MethodOptions, "/api/projects", false},
+		{http.MethodPost, "/api/projects", true},
+		{http.MethodPut, "/api/users/1", true},
+		{http.MethodDelete, "/api/repositories/library/hello-world", true},
+		{http.MethodPatch, "/api/labels/1", true},
+		{http.MethodPost, "/c/login", false},
+		{http.MethodPut, "/v2/library/hello-world/manifests/latest", false},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest(c.method, "http://127.0.0.1"+c.path, nil)
+		require.Nil(t, err)
+		assert.Equal(t, c.protected, csrfProtected(req), "%s %s", c.method, c.path)
+	}
+}
+
+func TestSessionReqCtxModifierCSRF(t *testing.T) {
+	user := models.User{
+		Username: "admin",
+		UserID:   1,
+	}
+	newCtx := func(method, token string) (*beegoctx.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest(method, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		store, err := beego.GlobalSessions.SessionStart(httptest.NewRecorder(), req)
+		require.Nil(t, err)
+		require.Nil(t, store.Set("user", user))
+		require.Nil(t, store.Set(SessionCSRFTokenKey, "the-csrf-token"))
+		addSessionIDToCookie(req, store.SessionID())
+		addToReqContext(req, AuthModeKey, common.DBAuth)
+		if len(token) > 0 {
+			req.Header.Set(CSRFTokenHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		ctx := beegoctx.NewContext()
+		ctx.Reset(rec, req)
+		ctx.Input.CruSession, err = beego.GlobalSessions.SessionStart(ctx.ResponseWriter, req)
+		require.Nil(t, err)
+		return ctx, rec
+	}
+	modifier := &sessionReqCtxModifier{}
+
+	// the read request needs no token
+	ctx, rec := newCtx(http.MethodGet, "")
+	assert.True(t, modifier.Modify(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.IsType(t, &local.SecurityContext{}, securityContext(ctx))
+
+	// missing token
+	ctx, rec = newCtx(http.MethodPost, "")
+	assert.True(t, modifier.Modify(ctx))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Nil(t, securityContext(ctx))
+
+	// wrong token
+	ctx, rec = newCtx(http.MethodDelete, "another-csrf-token")
+	assert.True(t, modifier.Modify(ctx))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Nil(t, securityContext(ctx))
+
+	// the token stored in the session
+	ctx, rec = newCtx(http.MethodPost, "the-csrf-token")
+	assert.True(t, modifier.Modify(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.IsType(t, &local.SecurityContext{}, securityContext(ctx))
+}
+
+// TestCSRFExempted tests the requests not authenticated by the session need no token
+func TestCSRFExempted(t *testing.T) {
+	newCtx := func(setAuth func(req *http.Request)) (*beegoctx.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest(http.MethodPost, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		setAuth(req)
+		addToReqContext(req, AuthModeKey, common.DBAuth)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		rec := httptest.NewRecorder()
+		ctx.Reset(rec, req)
+		return ctx, rec
+	}
+
+	// basic auth
+	ctx, rec := newCtx(func(req *http.Request) {
+		req.SetBasicAuth("admin", "Harbor12345")
+	})
+	assert.True(t, (&basicAuthReqCtxModifier{}).Modify(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.IsType(t, &local.SecurityContext{}, securityContext(ctx))
+
+	// secret
+	ctx, rec = newCtx(func(req *http.Request) {
+		commonsecret.AddToRequest(req, "secret")
+	})
+	assert.True(t, (&secretReqCtxModifier{}).Modify(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.IsType(t, &secret.SecurityContext{}, securityContext(ctx))
+
+	// robot
+	rb, err := robot.RobotCtr.CreateRobotAccount(&robotModel.RobotCreate{
+		Name:      "csrf",
+		ProjectID: 1,
+		Access: []*rbac.Policy{
+			{Resource: "/project/1/repository", Action: "push"},
+		},
+	})
+	require.Nil(t, err)
+	defer robot.RobotCtr.DeleteRobotAccount(rb.ID)
+	ctx, rec = newCtx(func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+rb.Token)
+	})
+	assert.True(t, (&robotAuthReqCtxModifier{}).Modify(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.IsType(t, &robotCtx.SecurityContext{}, securityContext(ctx))
+}
@@ -0,0 +1,159 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics collects the outcomes of the authentication in the security filter and exposes
+// them in the Prometheus text format
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// ResultSuccess is the result label of the authenticated requests
+	ResultSuccess = "success"
+	// ResultFailure is the result label of the requests not authenticated
+	ResultFailure = "failure"
+
+	requestsName = "harbor_core_auth_requests_total"
+	durationName = "harbor_core_auth_modifier_duration_seconds"
+)
+
+// the upper bounds of the buckets of the modifier latency histogram, in seconds
+var buckets = []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+var (
+	enabled int32
+	// counters of the authenticated requests keyed by requestKey, the values are *uint64
+	requests sync.Map
+	// latency histograms keyed by the name of modifier, the values are *histogram
+	durations sync.Map
+)
+
+type requestKey struct {
+	modifier string
+	result   string
+}
+
+type histogram struct {
+	lock   sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Enable enables or disables the collection, nothing is recorded when it's disabled
+func Enable(enable bool) {
+	var v int32
+	if enable {
+		v = 1
+	}
+	atomic.StoreInt32(&enabled, v)
+}
+
+// Enabled returns whether the collection is enabled, the callers should check it before
+// measuring to keep the cost near zero when disabled
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+// ObserveRequest counts the request handled by the modifier
+func ObserveRequest(modifier string, success bool) {
+	if !Enabled() {
+		return
+	}
+	result := ResultFailure
+	if success {
+		result = ResultSuccess
+	}
+	v, _ := requests.LoadOrStore(requestKey{modifier: modifier, result: result}, new(uint64))
+	atomic.AddUint64(v.(*uint64), 1)
+}
+
+// ObserveDuration records the time spent by the modifier
+func ObserveDuration(modifier string, d time.Duration) {
+	if !Enabled() {
+		return
+	}
+	v, _ := durations.LoadOrStore(modifier, &histogram{counts: make([]uint64, len(buckets))})
+	h := v.(*histogram)
+	seconds := d.Seconds()
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for i, bound := range buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// Requests returns the number of the requests handled by the modifier with the result
+func Requests(modifier, result string) uint64 {
+	v, ok := requests.Load(requestKey{modifier: modifier, result: result})
+	if !ok {
+		return 0
+	}
+	return atomic.LoadUint64(v.(*uint64))
+}
+
+// Handler returns the handler exposing the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprintf(w, "# HELP %s The number of the requests handled by the request context modifiers.\n", requestsName)
+		fmt.Fprintf(w, "# TYPE %s counter\n", requestsName)
+		var keys []requestKey
+		requests.Range(func(k, v interface{}) bool {
+			keys = append(keys, k.(requestKey))
+			return true
+		})
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].modifier != keys[j].modifier {
+				return keys[i].modifier < keys[j].modifier
+			}
+			return keys[i].result < keys[j].result
+		})
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s{modifier=%q,result=%q} %d\n", requestsName, k.modifier, k.result, Requests(k.modifier, k.result))
+		}
+
+		fmt.Fprintf(w, "# HELP %s The time spent by the request context modifiers.\n", durationName)
+		fmt.Fprintf(w, "# TYPE %s histogram\n", durationName)
+		var modifiers []string
+		durations.Range(func(k, v interface{}) bool {
+			modifiers = append(modifiers, k.(string))
+			return true
+		})
+		sort.Strings(modifiers)
+		for _, m := range modifiers {
+			v, _ := durations.Load(m)
+			h := v.(*histogram)
+			h.lock.Lock()
+			for i, bound := range buckets {
+				fmt.Fprintf(w, "%s_bucket{modifier=%q,le=\"%g\"} %d\n", durationName, m, bound, h.counts[i])
+			}
+			fmt.Fprintf(w, "%s_bucket{modifier=%q,le=\"+Inf\"} %d\n", durationName, m, h.count)
+			fmt.Fprintf(w, "%s_sum{modifier=%q} %g\n", durationName, m, h.sum)
+			fmt.Fprintf(w, "%s_count{modifier=%q} %d\n", durationName, m, h.count)
+			h.lock.Unlock()
+		}
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reset() {
+	requests = sync.Map{}
+	durations = sync.Map{}
+}
+
+func TestDisabled(t *testing.T) {
+	reset()
+	Enable(false)
+	ObserveRequest("basic_auth", true)
+	ObserveDuration("basic_auth", time.Millisecond)
+	assert.Equal(t, uint64(0), Requests("basic_auth", ResultSuccess))
+	_, ok := durations.Load("basic_auth")
+	assert.False(t, ok)
+}
+
+func TestObserve(t *testing.T) {
+	reset()
+	Enable(true)
+	defer Enable(false)
+
+	ObserveRequest("basic_auth", true)
+	ObserveRequest("basic_auth", true)
+	ObserveRequest("basic_auth", false)
+	ObserveRequest("unauthorized", false)
+	assert.Equal(t, uint64(2), Requests("basic_auth", ResultSuccess))
+	assert.Equal(t, uint64(1), Requests("basic_auth", ResultFailure))
+	assert.Equal(t, uint64(1), Requests("unauthorized", ResultFailure))
+	assert.Equal(t, uint64(0), Requests("session", ResultSuccess))
+
+	ObserveDuration("basic_auth", 2*time.Millisecond)
+	ObserveDuration("basic_auth", 2*time.Second)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, err := ioutil.ReadAll(rec.Body)
+	require.Nil(t, err)
+	text := string(body)
+	assert.Contains(t, text, `harbor_core_auth_requests_total{modifier="basic_auth",result="success"} 2`)
+	assert.Contains(t, text, `harbor_core_auth_requests_total{modifier="unauthorized",result="failure"} 1`)
+	assert.Contains(t, text, `harbor_core_auth_modifier_duration_seconds_bucket{modifier="basic_auth",le="0.001"} 0`)
+	assert.Contains(t, text, `harbor_core_auth_modifier_duration_seconds_bucket{modifier="basic_auth",le="0.005"} 1`)
+	assert.Contains(t, text, `harbor_core_auth_modifier_duration_seconds_bucket{modifier="basic_auth",le="5"} 2`)
+	assert.Contains(t, text, `harbor_core_auth_modifier_duration_seconds_bucket{modifier="basic_auth",le="+Inf"} 2`)
+	assert.Contains(t, text, `harbor_core_auth_modifier_duration_seconds_count{modifier="basic_auth"} 2`)
+}
+
+func TestObserveConcurrently(t *testing.T) {
+	reset()
+	Enable(true)
+	defer Enable(false)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ObserveRequest("robot", true)
+			ObserveDuration("robot", time.Millisecond)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(50), Requests("robot", ResultSuccess))
+}
+
+func BenchmarkObserveDisabled(b *testing.B) {
+	Enable(false)
+	for i := 0; i < b.N; i++ {
+		ObserveRequest("basic_auth", true)
+	}
+}
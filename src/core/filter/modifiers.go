@@ -0,0 +1,107 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+// the names of the built-in modifiers which can be configured in the chain
+const (
+	ModifierOverride      = "override"
+	ModifierSecret        = "secret"
+	ModifierCert          = "cert"
+	ModifierOIDCCli       = "oidc_cli"
+	ModifierIDToken       = "id_token"
+	ModifierAuthProxy     = "auth_proxy"
+	ModifierImpersonation = "impersonation"
+	ModifierAPIKey        = "api_key"
+	ModifierRobot         = "robot"
+	ModifierBasicAuth     = "basic_auth"
+	ModifierSAML          = "saml"
+	ModifierSession       = "session"
+	ModifierToken         = "token"
+	// ModifierUnauthorized is always the last one of the chain
+	ModifierUnauthorized = "unauthorized"
+	// ModifierConfig populates the configuration values, it isn't configurable
+	ModifierConfig = "config"
+)
+
+var (
+	defaultStandaloneModifiers = []string{
+		// the override runs before all the other authenticating modifiers
+		ModifierOverride,
+		ModifierSecret,
+		// the client certificate takes precedence over the credentials in the headers
+		ModifierCert,
+		ModifierOIDCCli,
+		ModifierIDToken,
+		ModifierAuthProxy,
+		ModifierImpersonation,
+		ModifierAPIKey,
+		ModifierRobot,
+		ModifierBasicAuth,
+		ModifierSAML,
+		ModifierSession,
+	}
+	defaultAdmiralModifiers = []string{
+		ModifierSecret,
+		ModifierToken,
+		ModifierBasicAuth,
+	}
+
+	// the modifiers registered by RegisterModifier
+	registry = map[string]ReqCtxModifier{}
+)
+
+// RegisterModifier registers the modifier with the name, it can be chained by configuring
+// the name, and it should be called before Init
+func RegisterModifier(name string, m ReqCtxModifier) {
+	if _, dup := registry[name]; dup {
+		log.Infof("request context modifier: %s has been registered, skip", name)
+		return
+	}
+	registry[name] = m
+	log.Debugf("Registered request context modifier: %s", name)
+}
+
+// chainModifiers looks up the modifiers by the names in order, the unauthorized modifier is
+// always appended as the last one, the names of the chained modifiers are returned as well
+func chainModifiers(names []string, builtin map[string]ReqCtxModifier) ([]ReqCtxModifier, []string, error) {
+	var modifiers []ReqCtxModifier
+	var chainedNames []string
+	chained := map[string]bool{}
+	for _, name := range names {
+		if name == ModifierUnauthorized {
+			continue
+		}
+		if chained[name] {
+			return nil, nil, fmt.Errorf("the request context modifier %s is configured more than once", name)
+		}
+		m, ok := builtin[name]
+		if !ok {
+			m, ok = registry[name]
+		}
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown request context modifier: %s", name)
+		}
+		chained[name] = true
+		modifiers = append(modifiers, m)
+		chainedNames = append(chainedNames, name)
+	}
+	return append(modifiers, &unauthorizedReqCtxModifier{}), append(chainedNames, ModifierUnauthorized), nil
+}
@@ -0,0 +1,100 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"testing"
+
+	beegoctx "github.com/astaxie/beego/context"
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeModifier struct {
+	name string
+}
+
+func (f *fakeModifier) Modify(ctx *beegoctx.Context) bool {
+	return false
+}
+
+func TestChainModifiers(t *testing.T) {
+	a, b := &fakeModifier{name: "a"}, &fakeModifier{name: "b"}
+	builtin := map[string]ReqCtxModifier{"a": a, "b": b}
+
+	// the order configured is respected
+	modifiers, names, err := chainModifiers([]string{"b", "a"}, builtin)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"b", "a", ModifierUnauthorized}, names)
+	require.Len(t, modifiers, 3)
+	assert.Equal(t, b, modifiers[0])
+	assert.Equal(t, a, modifiers[1])
+	assert.IsType(t, &unauthorizedReqCtxModifier{}, modifiers[2])
+
+	// the unauthorized modifier is always the last one
+	modifiers, _, err = chainModifiers([]string{ModifierUnauthorized, "a"}, builtin)
+	require.Nil(t, err)
+	require.Len(t, modifiers, 2)
+	assert.Equal(t, a, modifiers[0])
+	assert.IsType(t, &unauthorizedReqCtxModifier{}, modifiers[1])
+
+	modifiers, _, err = chainModifiers(nil, builtin)
+	require.Nil(t, err)
+	require.Len(t, modifiers, 1)
+	assert.IsType(t, &unauthorizedReqCtxModifier{}, modifiers[0])
+
+	// unknown
+	_, _, err = chainModifiers([]string{"a", "unknown"}, builtin)
+	assert.NotNil(t, err)
+
+	// duplicated
+	_, _, err = chainModifiers([]string{"a", "a"}, builtin)
+	assert.NotNil(t, err)
+}
+
+func TestRegisterModifier(t *testing.T) {
+	custom := &fakeModifier{name: "custom"}
+	RegisterModifier("custom", custom)
+	defer delete(registry, "custom")
+	// the duplicated registration is skipped
+	RegisterModifier("custom", &fakeModifier{name: "other"})
+
+	modifiers, _, err := chainModifiers([]string{"custom"}, map[string]ReqCtxModifier{})
+	require.Nil(t, err)
+	require.Len(t, modifiers, 2)
+	assert.Equal(t, custom, modifiers[0])
+}
+
+func TestInitWithConfiguredModifiers(t *testing.T) {
+	defer func() {
+		config.Upload(map[string]interface{}{common.ReqCtxModifiers: ""})
+		require.Nil(t, Init())
+	}()
+
+	config.Upload(map[string]interface{}{common.ReqCtxModifiers: "basic_auth, robot,auth_proxy"})
+	require.Nil(t, Init())
+	require.Len(t, reqCtxModifiers, 5)
+	assert.IsType(t, &configCtxModifier{}, reqCtxModifiers[0])
+	assert.IsType(t, &basicAuthReqCtxModifier{}, reqCtxModifiers[1])
+	assert.IsType(t, &robotAuthReqCtxModifier{}, reqCtxModifiers[2])
+	assert.IsType(t, &authProxyReqCtxModifier{}, reqCtxModifiers[3])
+	assert.IsType(t, &unauthorizedReqCtxModifier{}, reqCtxModifiers[4])
+	assert.Equal(t, []string{ModifierConfig, ModifierBasicAuth, ModifierRobot, ModifierAuthProxy, ModifierUnauthorized}, reqCtxModifierNames)
+
+	config.Upload(map[string]interface{}{common.ReqCtxModifiers: "basic_auth,unknown"})
+	assert.NotNil(t, Init())
+}
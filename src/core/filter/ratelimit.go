@@ -0,0 +1,305 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/config"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// the max number of failed robot token lookups allowed in one window
+	robotMaxFailedLookups = 10
+	// the window of the failed robot token lookups
+	robotFailedLookupWindow = time.Minute
+	// the length of the token ID prefix used to group the lookups
+	tokenIDPrefixLen = 3
+	// the key part used when the token can not be decoded at all
+	undecodableTokenID = "-"
+	// the max number of IPs tracked by the failed login limiter
+	failedLoginMaxEntries = 10000
+)
+
+var (
+	// robotTokenLimiter is the limiter guarding the robot token lookups
+	robotTokenLimiter = NewTokenIDRateLimiter(robotMaxFailedLookups, robotFailedLookupWindow)
+	// basicAuthLimiter is the limiter guarding the basic auth attempts, it's created by Init
+	// with the configured limits
+	basicAuthLimiter = NewFailedLoginLimiter(0, 0, 0)
+)
+
+// TokenIDRateLimiter limits the failed robot token lookups keyed by (source IP, token ID prefix)
+type TokenIDRateLimiter struct {
+	max    int
+	window time.Duration
+	lock   sync.Mutex
+	// the limiters are indexed by the source IP and then by the token ID prefix,
+	// so that all the records of an IP can be reset at once
+	limiters    map[string]map[string]*limiterEntry
+	lastCleanup time.Time
+}
+
+type limiterEntry struct {
+	limiter *rate.Limiter
+	// the time of the last failure
+	lastSeen time.Time
+}
+
+// NewTokenIDRateLimiter creates a limiter which allows at most max failed lookups in the window
+func NewTokenIDRateLimiter(max int, window time.Duration) *TokenIDRateLimiter {
+	return &TokenIDRateLimiter{
+		max:         max,
+		window:      window,
+		limiters:    map[string]map[string]*limiterEntry{},
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allowed checks whether the lookup is still allowed for the IP and token ID prefix,
+// it doesn't consume any quota
+func (t *TokenIDRateLimiter) Allowed(ip, tokenIDPrefix string) bool {
+	t.lock.Lock()
+	t.cleanup()
+	e := t.limiters[ip][tokenIDPrefix]
+	t.lock.Unlock()
+	if e == nil {
+		return true
+	}
+	now := time.Now()
+	r := e.limiter.ReserveN(now, 1)
+	allowed := r.OK() && r.DelayFrom(now) == 0
+	r.CancelAt(now)
+	return allowed
+}
+
+// Fail records a failed lookup for the IP and token ID prefix
+func (t *TokenIDRateLimiter) Fail(ip, tokenIDPrefix string) {
+	t.lock.Lock()
+	entries, ok := t.limiters[ip]
+	if !ok {
+		entries = map[string]*limiterEntry{}
+		t.limiters[ip] = entries
+	}
+	e, ok := entries[tokenIDPrefix]
+	if !ok {
+		e = &limiterEntry{
+			limiter: rate.NewLimiter(rate.Every(t.window/time.Duration(t.max)), t.max),
+		}
+		entries[tokenIDPrefix] = e
+	}
+	e.lastSeen = time.Now()
+	t.lock.Unlock()
+	e.limiter.Allow()
+}
+
+// Reset removes all the records of the IP
+func (t *TokenIDRateLimiter) Reset(ip string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.limiters, ip)
+}
+
+// cleanup removes the records which haven't been touched for a window,
+// the caller must hold the lock
+func (t *TokenIDRateLimiter) cleanup() {
+	now := time.Now()
+	if now.Sub(t.lastCleanup) < t.window {
+		return
+	}
+	t.lastCleanup = now
+	for ip, entries := range t.limiters {
+		for prefix, e := range entries {
+			if now.Sub(e.lastSeen) > t.window {
+				delete(entries, prefix)
+			}
+		}
+		if len(entries) == 0 {
+			delete(t.limiters, ip)
+		}
+	}
+}
+
+// tokenIDPrefix returns the prefix of the token ID which is used to group the lookups
+func tokenIDPrefix(tokenID int64) string {
+	s := strconv.FormatInt(tokenID, 10)
+	if len(s) > tokenIDPrefixLen {
+		return s[:tokenIDPrefixLen]
+	}
+	return s
+}
+
+// FailedLoginLimiter locks out the IP for the cooldown period once the max number of failed
+// logins happen within the window, the number of IPs tracked is bounded
+type FailedLoginLimiter struct {
+	max        int
+	window     time.Duration
+	cooldown   time.Duration
+	maxEntries int
+	lock       sync.Mutex
+	entries    map[string]*failedLogins
+}
+
+type failedLogins struct {
+	count       int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// NewFailedLoginLimiter creates a limiter which locks out the IP for the cooldown period after max
+// failed logins within the window, the limiter is disabled if max isn't positive
+func NewFailedLoginLimiter(max int, window, cooldown time.Duration) *FailedLoginLimiter {
+	return &FailedLoginLimiter{
+		max:        max,
+		window:     window,
+		cooldown:   cooldown,
+		maxEntries: failedLoginMaxEntries,
+		entries:    map[string]*failedLogins{},
+	}
+}
+
+// Allowed checks whether the IP isn't locked out
+func (f *FailedLoginLimiter) Allowed(ip string) bool {
+	if f.max <= 0 {
+		return true
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	e, ok := f.entries[ip]
+	return !ok || !time.Now().Before(e.lockedUntil)
+}
+
+// Fail records a failed login of the IP, the IP is locked out once the limit is reached
+func (f *FailedLoginLimiter) Fail(ip string) {
+	if f.max <= 0 {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	now := time.Now()
+	e, ok := f.entries[ip]
+	if !ok {
+		f.evict(now)
+		e = &failedLogins{windowStart: now}
+		f.entries[ip] = e
+	}
+	if now.Sub(e.windowStart) > f.window {
+		e.count = 0
+		e.windowStart = now
+	}
+	e.count++
+	if e.count >= f.max {
+		e.lockedUntil = now.Add(f.cooldown)
+		e.count = 0
+		e.windowStart = now
+	}
+}
+
+// Reset removes the record of the IP, it's called once the IP logins successfully
+func (f *FailedLoginLimiter) Reset(ip string) {
+	if f.max <= 0 {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.entries, ip)
+}
+
+// evict makes room for the new entry when the limiter is full, the stale entries are removed
+// first and then the one whose window started earliest, must be called with the lock held
+func (f *FailedLoginLimiter) evict(now time.Time) {
+	if len(f.entries) < f.maxEntries {
+		return
+	}
+	var oldest string
+	for ip, e := range f.entries {
+		if now.Sub(e.windowStart) > f.window && !now.Before(e.lockedUntil) {
+			delete(f.entries, ip)
+			continue
+		}
+		if len(oldest) == 0 || e.windowStart.Before(f.entries[oldest].windowStart) {
+			oldest = ip
+		}
+	}
+	if len(f.entries) >= f.maxEntries {
+		delete(f.entries, oldest)
+	}
+}
+
+// sourceIP returns the IP of the client which sends the request, the X-Forwarded-For header is
+// only honored when the request comes from the trusted proxies
+func sourceIP(req *http.Request) string {
+	return clientIP(req, config.TrustedProxies())
+}
+
+// clientIP walks through the X-Forwarded-For header from right to left when the peer is a trusted
+// proxy, the first IP which isn't a trusted proxy is the client
+func clientIP(req *http.Request, trustedProxies []string) string {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	if len(trustedProxies) == 0 || !ipInRanges(ip, trustedProxies) {
+		return ip
+	}
+	var hops []string
+	for _, v := range req.Header[http.CanonicalHeaderKey("X-Forwarded-For")] {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			// the header is malformed, stop at the last trusted hop
+			break
+		}
+		ip = hop
+		if !ipInRanges(hop, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+// ipInRanges checks whether the IP matches any of the ranges, which can be IPs or CIDRs
+func ipInRanges(ip string, ranges []string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, r := range ranges {
+		if strings.Contains(r, "/") {
+			_, cidr, err := net.ParseCIDR(r)
+			if err != nil {
+				log.Warningf("invalid IP range %s: %v", r, err)
+				continue
+			}
+			if cidr.Contains(parsed) {
+				return true
+			}
+			continue
+		}
+		if p := net.ParseIP(r); p != nil && p.Equal(parsed) {
+			return true
+		}
+	}
+	return false
+}
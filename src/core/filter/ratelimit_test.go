@@ -0,0 +1,189 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenIDRateLimiter(t *testing.T) {
+	limiter := NewTokenIDRateLimiter(3, time.Minute)
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Allowed("10.0.0.1", "123"))
+		limiter.Fail("10.0.0.1", "123")
+	}
+	assert.False(t, limiter.Allowed("10.0.0.1", "123"))
+	// other prefix and other IP are not affected
+	assert.True(t, limiter.Allowed("10.0.0.1", "456"))
+	assert.True(t, limiter.Allowed("10.0.0.2", "123"))
+
+	limiter.Reset("10.0.0.1")
+	assert.True(t, limiter.Allowed("10.0.0.1", "123"))
+}
+
+func TestTokenIDRateLimiterCleanup(t *testing.T) {
+	limiter := NewTokenIDRateLimiter(1, 10*time.Millisecond)
+	limiter.Fail("10.0.0.1", "123")
+	_, ok := limiter.limiters["10.0.0.1"]["123"]
+	assert.True(t, ok)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, limiter.Allowed("10.0.0.1", "123"))
+	_, ok = limiter.limiters["10.0.0.1"]
+	assert.False(t, ok)
+}
+
+func TestTokenIDRateLimiterReset(t *testing.T) {
+	limiter := NewTokenIDRateLimiter(1, time.Minute)
+	limiter.Fail("10.0.0.1", "123")
+	limiter.Fail("10.0.0.1", "456")
+	limiter.Fail("10.0.0.2", "123")
+
+	limiter.Reset("10.0.0.1")
+	_, ok := limiter.limiters["10.0.0.1"]
+	assert.False(t, ok)
+	assert.True(t, limiter.Allowed("10.0.0.1", "123"))
+	assert.True(t, limiter.Allowed("10.0.0.1", "456"))
+	// the records of the other IP are kept
+	assert.False(t, limiter.Allowed("10.0.0.2", "123"))
+}
+
+func TestTokenIDPrefix(t *testing.T) {
+	assert.Equal(t, "12", tokenIDPrefix(12))
+	assert.Equal(t, "123", tokenIDPrefix(123456))
+}
+
+func TestSourceIP(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+	req.RemoteAddr = "10.0.0.1:8080"
+	assert.Equal(t, "10.0.0.1", sourceIP(req))
+	req.RemoteAddr = "[::1]:8080"
+	assert.Equal(t, "::1", sourceIP(req))
+}
+
+func TestFailedLoginLimiter(t *testing.T) {
+	limiter := NewFailedLoginLimiter(3, time.Minute, 50*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Allowed("10.0.0.1"))
+		limiter.Fail("10.0.0.1")
+	}
+	// locked out
+	assert.False(t, limiter.Allowed("10.0.0.1"))
+	// other IP is not affected
+	assert.True(t, limiter.Allowed("10.0.0.2"))
+
+	// released after the cooldown
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, limiter.Allowed("10.0.0.1"))
+}
+
+func TestFailedLoginLimiterReset(t *testing.T) {
+	limiter := NewFailedLoginLimiter(3, time.Minute, time.Minute)
+	limiter.Fail("10.0.0.1")
+	limiter.Fail("10.0.0.1")
+	// the successful login resets the counter
+	limiter.Reset("10.0.0.1")
+	limiter.Fail("10.0.0.1")
+	limiter.Fail("10.0.0.1")
+	assert.True(t, limiter.Allowed("10.0.0.1"))
+	limiter.Fail("10.0.0.1")
+	assert.False(t, limiter.Allowed("10.0.0.1"))
+}
+
+func TestFailedLoginLimiterWindow(t *testing.T) {
+	limiter := NewFailedLoginLimiter(2, 20*time.Millisecond, time.Minute)
+	limiter.Fail("10.0.0.1")
+	time.Sleep(30 * time.Millisecond)
+	// the failure out of the window isn't counted
+	limiter.Fail("10.0.0.1")
+	assert.True(t, limiter.Allowed("10.0.0.1"))
+}
+
+func TestFailedLoginLimiterBounded(t *testing.T) {
+	limiter := NewFailedLoginLimiter(3, time.Minute, time.Minute)
+	limiter.maxEntries = 2
+	limiter.Fail("10.0.0.1")
+	time.Sleep(time.Millisecond)
+	limiter.Fail("10.0.0.2")
+	time.Sleep(time.Millisecond)
+	limiter.Fail("10.0.0.3")
+	assert.Len(t, limiter.entries, 2)
+	// the oldest one is evicted
+	_, ok := limiter.entries["10.0.0.1"]
+	assert.False(t, ok)
+}
+
+func TestFailedLoginLimiterDisabled(t *testing.T) {
+	limiter := NewFailedLoginLimiter(0, time.Minute, time.Minute)
+	for i := 0; i < 10; i++ {
+		limiter.Fail("10.0.0.1")
+	}
+	assert.True(t, limiter.Allowed("10.0.0.1"))
+	assert.Len(t, limiter.entries, 0)
+}
+
+func TestClientIP(t *testing.T) {
+	trusted := []string{"10.0.0.1", "192.168.0.0/16"}
+	newReq := func(remoteAddr string, xff ...string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		req.RemoteAddr = remoteAddr
+		for _, v := range xff {
+			req.Header.Add("X-Forwarded-For", v)
+		}
+		return req
+	}
+	// no trusted proxy configured
+	assert.Equal(t, "10.0.0.1", clientIP(newReq("10.0.0.1:8080", "1.1.1.1"), nil))
+	// the peer isn't trusted
+	assert.Equal(t, "10.0.0.2", clientIP(newReq("10.0.0.2:8080", "1.1.1.1"), trusted))
+	// the peer is trusted
+	assert.Equal(t, "1.1.1.1", clientIP(newReq("10.0.0.1:8080", "1.1.1.1"), trusted))
+	// the trusted hops are skipped, the spoofed ones on the left are ignored
+	assert.Equal(t, "2.2.2.2", clientIP(newReq("10.0.0.1:8080", "3.3.3.3, 2.2.2.2, 192.168.1.1"), trusted))
+	assert.Equal(t, "2.2.2.2", clientIP(newReq("10.0.0.1:8080", "3.3.3.3", "2.2.2.2,192.168.1.1"), trusted))
+	// all the hops are trusted
+	assert.Equal(t, "192.168.1.2", clientIP(newReq("10.0.0.1:8080", "192.168.1.2,192.168.1.1"), trusted))
+	// malformed
+	assert.Equal(t, "192.168.1.1", clientIP(newReq("10.0.0.1:8080", "invalid, 192.168.1.1"), trusted))
+	// no header
+	assert.Equal(t, "10.0.0.1", clientIP(newReq("10.0.0.1:8080"), trusted))
+}
+
+func TestIPInRanges(t *testing.T) {
+	ranges := []string{"10.0.0.0/8", "192.168.1.1", "2001:db8::/32", "::1", "invalid/cidr"}
+	cases := []struct {
+		ip      string
+		inRange bool
+	}{
+		{"10.1.2.3", true},
+		{"11.0.0.1", false},
+		{"192.168.1.1", true},
+		{"192.168.1.2", false},
+		{"2001:db8::1", true},
+		{"2001:db9::1", false},
+		{"::1", true},
+		{"::ffff:10.1.2.3", true},
+		{"invalid", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.inRange, ipInRanges(c.ip, ranges), c.ip)
+	}
+	assert.False(t, ipInRanges("10.1.2.3", nil))
+}
@@ -16,80 +16,67 @@ package filter
 
 import (
 	"net/http"
-	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/astaxie/beego/context"
+	commonhttp "github.com/goharbor/harbor/src/common/http"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
 )
 
-const (
-	repoURL  = `^/api/repositories/((?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)+)(?:[a-z0-9]+(?:[._-][a-z0-9]+)*)$`
-	tagsURL  = `^/api/repositories/((?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)+)tags$`
-	tagURL   = `^/api/repositories/((?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)+)tags/([\w][\w.-]{0,127})$`
-	labelURL = `^/api/repositories/((?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)+)tags/([\w][\w.-]{0,127})/labels/[0-9]+$`
-)
-
-// ReadonlyFilter filters the deletion or creation (e.g. retag) of repo/tag requests and returns 503.
+// ReadonlyFilter rejects the requests modifying the system, e.g. the API requests other than GET and HEAD and
+// the pushes to the registry, with 503 in read only mode. The requests authenticated by the internal secret,
+// e.g. the ones of GC, and the ones to the configured allowed paths are always served. It must be inserted after
+// the security filter as the security context of the request is checked.
 func ReadonlyFilter(ctx *context.Context) {
 	filter(ctx.Request, ctx.ResponseWriter)
 }
 
 func filter(req *http.Request, resp http.ResponseWriter) {
-	if !config.ReadOnly() {
+	if !config.ReadOnly() || !readonlyBlocked(req) {
 		return
 	}
 
-	if matchRepoTagDelete(req) || matchRetag(req) {
-		resp.WriteHeader(http.StatusServiceUnavailable)
-		_, err := resp.Write([]byte("The system is in read only mode. Any modification is prohibited."))
-		if err != nil {
-			log.Errorf("failed to write response body: %v", err)
-		}
+	log.Warningf("the request %s %s is rejected in read only mode", req.Method, req.URL.Path)
+	e := &commonhttp.Error{
+		Code:    http.StatusServiceUnavailable,
+		Message: "The system is in read only mode. Any modification is prohibited.",
 	}
-}
-
-// matchRepoTagDelete checks whether a request is a repository or tag deletion request,
-// it should be blocked in read-only mode.
-func matchRepoTagDelete(req *http.Request) bool {
-	if req.Method != http.MethodDelete {
-		return false
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Retry-After", strconv.FormatInt(int64(config.ReadOnlyRetryAfter()/time.Second), 10))
+	resp.WriteHeader(e.Code)
+	if _, err := resp.Write([]byte(e.String())); err != nil {
+		log.Errorf("failed to write response body: %v", err)
 	}
+}
 
-	if inWhiteList(req) {
+// readonlyBlocked checks whether the request should be blocked in read only mode
+func readonlyBlocked(req *http.Request) bool {
+	if isReadRequest(req) || pathAllowed(req.URL.Path, config.ReadOnlyAllowedPaths()) {
 		return false
 	}
-
-	re := regexp.MustCompile(tagURL)
-	s := re.FindStringSubmatch(req.URL.Path)
-	if len(s) == 3 {
-		return true
-	}
-
-	re = regexp.MustCompile(repoURL)
-	s = re.FindStringSubmatch(req.URL.Path)
-	if len(s) == 2 {
-		return true
-	}
-
-	return false
-}
-
-// matchRetag checks whether a request is a retag request, it should be blocked in read-only mode.
-func matchRetag(req *http.Request) bool {
-	if req.Method != http.MethodPost {
+	// only the internal components, e.g. the job service running GC, authenticate by the secret
+	if sc, err := GetSecurityContext(req); err == nil && sc.IsSolutionUser() {
 		return false
 	}
-
-	re := regexp.MustCompile(tagsURL)
-	return re.MatchString(req.URL.Path)
+	return true
 }
 
-func inWhiteList(req *http.Request) bool {
-	re := regexp.MustCompile(labelURL)
-	s := re.FindStringSubmatch(req.URL.Path)
-	if len(s) == 3 {
-		return true
+// pathAllowed checks whether the path matches any of the allowed paths, the allowed path ending
+// with "*" matches the paths with the prefix, others are compared exactly
+func pathAllowed(path string, allowed []string) bool {
+	for _, p := range allowed {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+			continue
+		}
+		if path == p {
+			return true
+		}
 	}
 	return false
 }
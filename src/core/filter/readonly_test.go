@@ -20,8 +20,13 @@ import (
 	"testing"
 
 	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/config/metadata"
+	commonsecret "github.com/goharbor/harbor/src/common/secret"
+	"github.com/goharbor/harbor/src/common/security/local"
+	"github.com/goharbor/harbor/src/common/security/secret"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestReadonlyFilter(t *testing.T) {
@@ -30,6 +35,7 @@ func TestReadonlyFilter(t *testing.T) {
 		common.ReadOnly: true,
 	}
 	config.Upload(defaultConfig)
+	defer config.Upload(map[string]interface{}{common.ReadOnly: false})
 
 	assert := assert.New(t)
 	req1, _ := http.NewRequest("DELETE", "http://127.0.0.1:5000/api/repositories/library/ubuntu", nil)
@@ -63,16 +69,123 @@ func TestReadonlyFilter(t *testing.T) {
 	assert.Equal(http.StatusServiceUnavailable, rec.Code)
 }
 
-func TestMatchRetag(t *testing.T) {
-	req1, _ := http.NewRequest("POST", "http://127.0.0.1:5000/api/repositories/library/hello-world/tags", nil)
-	assert.True(t, matchRetag(req1))
+func TestReadonlyFilterWrites(t *testing.T) {
+	config.Upload(map[string]interface{}{
+		common.ReadOnly:           true,
+		common.ReadOnlyRetryAfter: 60,
+	})
+	defer config.Upload(map[string]interface{}{common.ReadOnly: false})
 
-	req2, _ := http.NewRequest("POST", "http://127.0.0.1:5000/api/repositories/library/hello-world/tags/v1.0", nil)
-	assert.False(t, matchRetag(req2))
+	cases := []struct {
+		method string
+		url    string
+	}{
+		{http.MethodPost, "http://127.0.0.1/api/projects"},
+		{http.MethodPut, "http://127.0.0.1/api/projects/1"},
+		{http.MethodDelete, "http://127.0.0.1/api/projects/1/robots/1"},
+		{http.MethodPatch, "http://127.0.0.1/v2/library/hello-world/blobs/uploads/uuid"},
+		{http.MethodPut, "http://127.0.0.1/v2/library/hello-world/manifests/latest"},
+		{http.MethodDelete, "http://127.0.0.1/api/repositories/library/hello-world/tags/latest/labels/1"},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, c.url, nil)
+		// the local user isn't allowed even if it's the system admin
+		setSecurCtxAndPM(req, local.NewSecurityContext(nil, nil), nil)
+		rec := httptest.NewRecorder()
+		filter(req, rec)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "%s %s", c.method, c.url)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"code":503,"message":"The system is in read only mode. Any modification is prohibited."}`, rec.Body.String())
+	}
+}
+
+func TestReadonlyFilterAllowed(t *testing.T) {
+	config.Upload(map[string]interface{}{
+		common.ReadOnly:             true,
+		common.ReadOnlyAllowedPaths: "/api/configurations, /service/notifications*",
+	})
+	defer config.Upload(map[string]interface{}{common.ReadOnly: false})
+
+	// the reads
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodOptions} {
+		req, _ := http.NewRequest(method, "http://127.0.0.1/v2/library/hello-world/manifests/latest", nil)
+		rec := httptest.NewRecorder()
+		filter(req, rec)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	// the allowed paths
+	for _, url := range []string{
+		"http://127.0.0.1/api/configurations",
+		"http://127.0.0.1/service/notifications",
+		"http://127.0.0.1/service/notifications/jobs/adminjob/1",
+	} {
+		req, _ := http.NewRequest(http.MethodPut, url, nil)
+		rec := httptest.NewRecorder()
+		filter(req, rec)
+		assert.Equal(t, http.StatusOK, rec.Code, url)
+	}
+	req, _ := http.NewRequest(http.MethodPut, "http://127.0.0.1/api/configurations/foo", nil)
+	rec := httptest.NewRecorder()
+	filter(req, rec)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// the internal requests authenticated by the secret
+	store := commonsecret.NewStore(map[string]string{"secret": "jobservice"})
+	req, _ = http.NewRequest(http.MethodDelete, "http://127.0.0.1/api/repositories/library/hello-world", nil)
+	setSecurCtxAndPM(req, secret.NewSecurityContext("secret", store), nil)
+	rec = httptest.NewRecorder()
+	filter(req, rec)
+	assert.Equal(t, http.StatusOK, rec.Code)
 
-	req3, _ := http.NewRequest("GET", "http://127.0.0.1:5000/api/repositories/library/hello-world/tags", nil)
-	assert.False(t, matchRetag(req3))
+	// the invalid secret
+	req, _ = http.NewRequest(http.MethodDelete, "http://127.0.0.1/api/repositories/library/hello-world", nil)
+	setSecurCtxAndPM(req, secret.NewSecurityContext("invalid", store), nil)
+	rec = httptest.NewRecorder()
+	filter(req, rec)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// nothing is blocked out of read only mode
+	config.Upload(map[string]interface{}{common.ReadOnly: false})
+	req, _ = http.NewRequest(http.MethodPost, "http://127.0.0.1/api/projects", nil)
+	rec = httptest.NewRecorder()
+	filter(req, rec)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestReadonlyFilterDefaultAllowed(t *testing.T) {
+	item, ok := metadata.Instance().GetByName(common.ReadOnlyAllowedPaths)
+	require.True(t, ok)
+	config.Upload(map[string]interface{}{
+		common.ReadOnly:             true,
+		common.ReadOnlyAllowedPaths: item.DefaultValue,
+	})
+	defer config.Upload(map[string]interface{}{common.ReadOnly: false})
+
+	// the users can still log in via the database, OIDC or SAML
+	for _, path := range []string{
+		"/c/login",
+		common.OIDCCallbackPath,
+		common.SAMLCallbackPath,
+	} {
+		req, _ := http.NewRequest(http.MethodPost, "http://127.0.0.1"+path, nil)
+		rec := httptest.NewRecorder()
+		filter(req, rec)
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://127.0.0.1/c/saml/metadata", nil)
+	rec := httptest.NewRecorder()
+	filter(req, rec)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
 
-	req4, _ := http.NewRequest("POST", "http://127.0.0.1:5000/api/repositories/library/hello-world", nil)
-	assert.False(t, matchRetag(req4))
+func TestPathAllowed(t *testing.T) {
+	allowed := []string{"/c/login", "/c/oidc/*"}
+	assert.True(t, pathAllowed("/c/login", allowed))
+	assert.False(t, pathAllowed("/c/login/foo", allowed))
+	assert.True(t, pathAllowed("/c/oidc/onboard", allowed))
+	assert.False(t, pathAllowed("/c/oidc", allowed))
+	assert.False(t, pathAllowed("/api/projects", nil))
 }
@@ -0,0 +1,72 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/pkg/robot"
+)
+
+// the min interval between two writes of the last access time of one robot account
+const robotLastAccessInterval = time.Minute
+
+// robotAccessRecorder records the last access time of the robot accounts
+var robotAccessRecorder = newLastAccessRecorder(robotLastAccessInterval, func(id int64, t time.Time) error {
+	return robot.RobotCtr.UpdateRobotLastAccess(id, t)
+})
+
+// lastAccessRecorder writes the last access time through the update function,
+// the writes of the same ID are throttled to at most once per interval
+type lastAccessRecorder struct {
+	interval time.Duration
+	update   func(id int64, t time.Time) error
+	lock     sync.Mutex
+	written  map[int64]time.Time
+}
+
+func newLastAccessRecorder(interval time.Duration, update func(id int64, t time.Time) error) *lastAccessRecorder {
+	return &lastAccessRecorder{
+		interval: interval,
+		update:   update,
+		written:  map[int64]time.Time{},
+	}
+}
+
+// Record records the access at time t, it returns false if the write is skipped
+// as the previous one happened within the interval
+func (l *lastAccessRecorder) Record(id int64, t time.Time) bool {
+	l.lock.Lock()
+	if last, ok := l.written[id]; ok && t.Sub(last) < l.interval {
+		l.lock.Unlock()
+		return false
+	}
+	l.written[id] = t
+	l.lock.Unlock()
+
+	if err := l.update(id, t); err != nil {
+		// the failure isn't fatal for the request, let the next access retry
+		log.Errorf("failed to update the last access time of %d: %v", id, err)
+		l.lock.Lock()
+		if l.written[id].Equal(t) {
+			delete(l.written, id)
+		}
+		l.lock.Unlock()
+		return false
+	}
+	return true
+}
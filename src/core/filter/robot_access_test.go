@@ -0,0 +1,124 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLastAccessStore struct {
+	lock    sync.Mutex
+	err     error
+	updates map[int64][]time.Time
+}
+
+func (f *fakeLastAccessStore) update(id int64, t time.Time) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	if f.updates == nil {
+		f.updates = map[int64][]time.Time{}
+	}
+	f.updates[id] = append(f.updates[id], t)
+	return nil
+}
+
+func (f *fakeLastAccessStore) count(id int64) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.updates[id])
+}
+
+func TestLastAccessRecorderThrottle(t *testing.T) {
+	store := &fakeLastAccessStore{}
+	recorder := newLastAccessRecorder(time.Minute, store.update)
+	now := time.Now()
+
+	assert.True(t, recorder.Record(1, now))
+	// throttled within the interval
+	assert.False(t, recorder.Record(1, now.Add(30*time.Second)))
+	assert.Equal(t, 1, store.count(1))
+	// the other robots are not affected
+	assert.True(t, recorder.Record(2, now.Add(30*time.Second)))
+	assert.Equal(t, 1, store.count(2))
+	// written again once the interval passes
+	assert.True(t, recorder.Record(1, now.Add(time.Minute)))
+	assert.Equal(t, 2, store.count(1))
+}
+
+func TestLastAccessRecorderRetryOnFailure(t *testing.T) {
+	store := &fakeLastAccessStore{err: errors.New("error")}
+	recorder := newLastAccessRecorder(time.Minute, store.update)
+	now := time.Now()
+
+	assert.False(t, recorder.Record(1, now))
+	store.err = nil
+	// the failed write doesn't throttle the next one
+	assert.True(t, recorder.Record(1, now.Add(time.Second)))
+	assert.Equal(t, 1, store.count(1))
+}
+
+func TestLastAccessRecorderConcurrent(t *testing.T) {
+	store := &fakeLastAccessStore{}
+	recorder := newLastAccessRecorder(time.Minute, store.update)
+	now := time.Now()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder.Record(1, now)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, store.count(1))
+}
+
+func TestRobotLastAccessNotBumpedOnFailedAuth(t *testing.T) {
+	store := &fakeLastAccessStore{}
+	origin := robotAccessRecorder
+	robotAccessRecorder = newLastAccessRecorder(time.Minute, store.update)
+	defer func() {
+		robotAccessRecorder = origin
+	}()
+
+	modifier := &robotAuthReqCtxModifier{}
+	for _, auth := range []func(req *http.Request){
+		func(req *http.Request) { req.SetBasicAuth("robot$test1", "invalid-token") },
+		func(req *http.Request) { req.Header.Set("Authorization", "Bearer invalid-token") },
+	} {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.RemoteAddr = "10.10.10.12:12345"
+		auth(req)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		ctx.Reset(httptest.NewRecorder(), req)
+		assert.False(t, modifier.Modify(ctx))
+	}
+	assert.Empty(t, store.updates)
+	robotTokenLimiter.Reset("10.10.10.12")
+}
@@ -16,44 +16,69 @@ package filter
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/goharbor/harbor/src/common/utils/oidc"
 	"net/http"
+	"os"
 	"regexp"
+	"time"
 
 	beegoctx "github.com/astaxie/beego/context"
 	"github.com/docker/distribution/reference"
 	"github.com/goharbor/harbor/src/common"
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/dao/group"
+	commonhttp "github.com/goharbor/harbor/src/common/http"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
 	secstore "github.com/goharbor/harbor/src/common/secret"
 	"github.com/goharbor/harbor/src/common/security"
 	admr "github.com/goharbor/harbor/src/common/security/admiral"
 	"github.com/goharbor/harbor/src/common/security/admiral/authcontext"
+	"github.com/goharbor/harbor/src/common/security/impersonation"
 	"github.com/goharbor/harbor/src/common/security/local"
 	robotCtx "github.com/goharbor/harbor/src/common/security/robot"
 	"github.com/goharbor/harbor/src/common/security/secret"
 	"github.com/goharbor/harbor/src/common/token"
+	ldapUtils "github.com/goharbor/harbor/src/common/utils/ldap"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/auth"
+	"github.com/goharbor/harbor/src/core/auth/saml"
 	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/filter/metrics"
 	"github.com/goharbor/harbor/src/core/promgr"
 	"github.com/goharbor/harbor/src/core/promgr/pmsdriver/admiral"
 	"strings"
 
-	"github.com/goharbor/harbor/src/pkg/authproxy"
+	"github.com/goharbor/harbor/src/pkg/q"
 	"github.com/goharbor/harbor/src/pkg/robot"
+	robotModel "github.com/goharbor/harbor/src/pkg/robot/model"
 )
 
 // ContextValueKey for content value
 type ContextValueKey string
 
 type pathMethod struct {
-	path   string
+	path   *regexp.Regexp
 	method string
 }
 
+// newPathMethod compiles the path pattern which is anchored to match the whole path
+func newPathMethod(pattern, method string) *pathMethod {
+	return &pathMethod{
+		path:   regexp.MustCompile("^(?:" + pattern + ")$"),
+		method: method,
+	}
+}
+
+// match checks whether both the method and the path of the request match
+func (p *pathMethod) match(req *http.Request) bool {
+	return req.Method == p.method && p.path.MatchString(req.URL.Path)
+}
+
 const (
 	// SecurCtxKey is context value key for security context
 	SecurCtxKey ContextValueKey = "harbor_security_context"
@@ -62,59 +87,80 @@ const (
 	PmKey ContextValueKey = "harbor_project_manager"
 	// AuthModeKey is context key for auth mode
 	AuthModeKey ContextValueKey = "harbor_auth_mode"
+	// RequestIDKey is context value key for the request ID
+	RequestIDKey ContextValueKey = "harbor_request_id"
 )
 
 var (
 	reqCtxModifiers []ReqCtxModifier
+	// the names of the modifiers in reqCtxModifiers, used as the label of metrics
+	reqCtxModifierNames []string
 	// basic auth request context modifier only takes effect on the patterns
 	// in the slice
 	basicAuthReqPatterns = []*pathMethod{
 		// create project
-		{
-			path:   "/api/projects",
-			method: http.MethodPost,
-		},
+		newPathMethod("/api/projects", http.MethodPost),
 		// token service
-		{
-			path:   "/service/token",
-			method: http.MethodGet,
-		},
+		newPathMethod("/service/token", http.MethodGet),
 		// delete repository
-		{
-			path:   "/api/repositories/" + reference.NameRegexp.String(),
-			method: http.MethodDelete,
-		},
+		newPathMethod("/api/repositories/"+reference.NameRegexp.String(), http.MethodDelete),
 		// delete tag
-		{
-			path:   "/api/repositories/" + reference.NameRegexp.String() + "/tags/" + reference.TagRegexp.String(),
-			method: http.MethodDelete,
-		},
+		newPathMethod("/api/repositories/"+reference.NameRegexp.String()+"/tags/"+reference.TagRegexp.String(),
+			http.MethodDelete),
+	}
+	// the prefixes of the paths which are accessible anonymously even if the anonymous access
+	// is restricted, they're the login flows and the notifications from the internal components
+	anonymousAccessExemptedPrefixes = []string{
+		"/c/",
+		"/service/notifications",
 	}
 )
 
-// Init ReqCtxMofiers list
-func Init() {
-	// integration with admiral
-	if config.WithAdmiral() {
-		reqCtxModifiers = []ReqCtxModifier{
-			&secretReqCtxModifier{config.SecretStore},
-			&tokenReqCtxModifier{},
-			&basicAuthReqCtxModifier{},
-			&unauthorizedReqCtxModifier{}}
-		return
+// Init ReqCtxMofiers list, the modifiers configured are chained in order, the default ones
+// of the deployment mode are used if none is configured
+func Init() error {
+	builtin := map[string]ReqCtxModifier{
+		ModifierOverride:      &overrideReqCtxModifier{},
+		ModifierSecret:        &secretReqCtxModifier{config.SecretStore},
+		ModifierCert:          &certReqCtxModifier{},
+		ModifierOIDCCli:       &oidcCliReqCtxModifier{},
+		ModifierIDToken:       &idTokenReqCtxModifier{},
+		ModifierAuthProxy:     &authProxyReqCtxModifier{},
+		ModifierImpersonation: &impersonationReqCtxModifier{},
+		ModifierAPIKey:        &apiKeyReqCtxModifier{},
+		ModifierRobot:         &robotAuthReqCtxModifier{},
+		ModifierBasicAuth:     &basicAuthReqCtxModifier{},
+		ModifierSAML:          &samlReqCtxModifier{},
+		ModifierSession:       &sessionReqCtxModifier{},
+		ModifierToken:         &tokenReqCtxModifier{},
 	}
 
-	// standalone
-	reqCtxModifiers = []ReqCtxModifier{
-		&configCtxModifier{},
-		&secretReqCtxModifier{config.SecretStore},
-		&oidcCliReqCtxModifier{},
-		&idTokenReqCtxModifier{},
-		&authProxyReqCtxModifier{},
-		&robotAuthReqCtxModifier{},
-		&basicAuthReqCtxModifier{},
-		&sessionReqCtxModifier{},
-		&unauthorizedReqCtxModifier{}}
+	names := config.ReqCtxModifiers()
+	if len(names) == 0 {
+		names = defaultStandaloneModifiers
+		// integration with admiral
+		if config.WithAdmiral() {
+			names = defaultAdmiralModifiers
+		}
+	}
+	modifiers, names, err := chainModifiers(names, builtin)
+	if err != nil {
+		return err
+	}
+	// the configuration values are populated before all the other modifiers in standalone mode
+	if !config.WithAdmiral() {
+		modifiers = append([]ReqCtxModifier{&configCtxModifier{}}, modifiers...)
+		names = append([]string{ModifierConfig}, names...)
+	}
+	if err := validateChain(modifiers); err != nil {
+		return err
+	}
+	skipPaths = skipPathsOf(config.SecurityFilterSkipPaths())
+	reqCtxModifiers = modifiers
+	reqCtxModifierNames = names
+	basicAuthLimiter = NewFailedLoginLimiter(config.BasicAuthFailureLimit())
+	metrics.Enable(config.AuthMetricsEnabled())
+	return nil
 }
 
 // SecurityFilter authenticates the request and passes a security context
@@ -128,13 +174,63 @@ func SecurityFilter(ctx *beegoctx.Context) {
 	if req == nil {
 		return
 	}
+	// the health checks are too frequent to walk through the modifiers
+	if Skipped(req) {
+		return
+	}
+
+	id := requestID(req)
+	addToReqContext(req, RequestIDKey, id)
+	ctx.ResponseWriter.Header().Set(requestIDHeader, id)
 
 	// add security context and project manager to request context
-	for _, modifier := range reqCtxModifiers {
-		if modifier.Modify(ctx) {
+	metricsEnabled := metrics.Enabled()
+	authMethod := ""
+	for i, modifier := range reqCtxModifiers {
+		var modified bool
+		if metricsEnabled {
+			start := time.Now()
+			modified = modifier.Modify(ctx)
+			metrics.ObserveDuration(reqCtxModifierNames[i], time.Since(start))
+		} else {
+			modified = modifier.Modify(ctx)
+		}
+		if modified {
+			authMethod = reqCtxModifierNames[i]
+			if metricsEnabled {
+				// the requests rejected directly, e.g. by the rate limiting, carry no security context
+				sc, err := GetSecurityContext(req)
+				metrics.ObserveRequest(authMethod, err == nil && sc.IsAuthenticated())
+			}
 			break
 		}
 	}
+	// the requests rejected by the modifiers directly carry no security context
+	if !ctx.ResponseWriter.Started {
+		if _, ok := SecurityContextFrom(req.Context()); !ok {
+			log.Errorf("no security context is built for the request %s %s", req.Method, req.URL.Path)
+			e := &commonhttp.Error{
+				Code:    http.StatusInternalServerError,
+				Message: "failed to build the security context",
+			}
+			ctx.ResponseWriter.Header().Set("Content-Type", "application/json")
+			ctx.ResponseWriter.WriteHeader(e.Code)
+			ctx.ResponseWriter.Write([]byte(e.String()))
+		}
+	}
+	auditAuth(req, authMethod)
+}
+
+// validateChain makes sure the chain ends with the unauthorized modifier, so that the security
+// context is always built
+func validateChain(modifiers []ReqCtxModifier) error {
+	if len(modifiers) == 0 {
+		return errors.New("no request context modifier is chained")
+	}
+	if _, ok := modifiers[len(modifiers)-1].(*unauthorizedReqCtxModifier); !ok {
+		return errors.New("the last request context modifier must be the unauthorized one")
+	}
+	return nil
 }
 
 // ReqCtxModifier modifies the context of request
@@ -178,48 +274,393 @@ func (s *secretReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	return true
 }
 
+// certReqCtxModifier handles the requests presenting the verified client certificate when it's enabled,
+// the CN or SAN of the certificate is mapped to the name of user or robot account by the configured
+// mapping, the CN is used as the username if none of them is in the mapping
+type certReqCtxModifier struct{}
+
+func (c *certReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
+	if !config.ClientCertAuth() {
+		return false
+	}
+	cert := verifiedClientCert(ctx.Request)
+	if cert == nil {
+		return false
+	}
+	mapping, err := config.ClientCertMapping()
+	if err != nil {
+		log.Errorf("failed to get the mapping of client certificates: %v", err)
+		return false
+	}
+	name := certSubjectName(cert, mapping)
+	if len(name) == 0 {
+		log.Debug("no name in the client certificate, skip")
+		return false
+	}
+	if strings.HasPrefix(name, common.RobotPrefix) {
+		return c.robot(ctx, name)
+	}
+
+	user, err := dao.GetUser(models.User{
+		Username: name,
+	})
+	if err != nil {
+		log.Errorf("failed to get user %s: %v", name, err)
+		return false
+	}
+	if user == nil {
+		log.Warningf("the user %s mapped from the client certificate %q doesn't exist", name, cert.Subject.CommonName)
+		return false
+	}
+	if !activeUser(user) {
+		return false
+	}
+	updateLastLoginTime(user)
+	pm := config.GlobalProjectMgr
+	log.Debug("creating local database security context for client certificate...")
+	securCtx := local.NewSecurityContext(user, pm)
+	setSecurCtxAndPM(ctx.Request, securCtx, pm)
+	return true
+}
+
+// robot builds the security context for the robot account, as the access of robot account is only
+// carried by its tokens, the robot authenticated by certificate can only pull from its project
+func (c *certReqCtxModifier) robot(ctx *beegoctx.Context, name string) bool {
+	robots, err := robot.RobotCtr.ListRobotAccount(&q.Query{
+		Keywords: map[string]interface{}{
+			"Name": name,
+		},
+	})
+	if err != nil {
+		log.Errorf("failed to list robot %s: %v", name, err)
+		return false
+	}
+	// the keywords are matched fuzzily
+	var matched []*robotModel.Robot
+	for _, r := range robots {
+		if r.Name == name {
+			matched = append(matched, r)
+		}
+	}
+	if len(matched) != 1 {
+		log.Warningf("%d robot accounts named %s mapped from the client certificate", len(matched), name)
+		return false
+	}
+	rb := matched[0]
+	if rb.Disabled {
+		log.Errorf("the robot account %s is disabled", rb.Name)
+		return false
+	}
+	robotAccessRecorder.Record(rb.ID, time.Now())
+	pm := config.GlobalProjectMgr
+	log.Debug("creating robot account security context for client certificate...")
+	access := []*rbac.Policy{
+		{
+			Resource: rbac.NewProjectNamespace(rb.ProjectID).Resource(rbac.ResourceRepository),
+			Action:   rbac.ActionPull,
+		},
+	}
+	securCtx := robotCtx.NewSecurityContext(rb, pm, access)
+	setSecurCtxAndPM(ctx.Request, securCtx, pm)
+	return true
+}
+
+// verifiedClientCert returns the leaf of the verified client certificate chain, nil is returned
+// if the client doesn't present a certificate or it isn't verified
+func verifiedClientCert(req *http.Request) *x509.Certificate {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return req.TLS.VerifiedChains[0][0]
+}
+
+// certSubjectName maps the CN or SAN of the certificate to the name by the mapping, the CN is
+// looked up first, and it's returned directly if none of the identities is in the mapping
+func certSubjectName(cert *x509.Certificate, mapping map[string]string) string {
+	identities := []string{cert.Subject.CommonName}
+	identities = append(identities, cert.DNSNames...)
+	identities = append(identities, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	for _, id := range identities {
+		if len(id) == 0 {
+			continue
+		}
+		if name, ok := mapping[id]; ok {
+			return name
+		}
+	}
+	return cert.Subject.CommonName
+}
+
+// robotAuthReqCtxModifier handles the requests carrying the robot token, either as the password of
+// basic auth with the robot name as the username, or as the bearer token
 type robotAuthReqCtxModifier struct{}
 
 func (r *robotAuthReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	robotName, robotTk, ok := ctx.Request.BasicAuth()
+	if ok {
+		if !strings.HasPrefix(robotName, common.RobotPrefix) {
+			return false
+		}
+		ip := sourceIP(ctx.Request)
+		claims := &token.RobotClaims{Leeway: config.RobotTokenClockSkew()}
+		htk, err := token.ParseWithClaims(robotTk, claims)
+		if err != nil {
+			if r.rejectByTime(ctx, claims, robotName, err) {
+				return false
+			}
+			log.Errorf("failed to decrypt robot token, %v", err)
+			return r.fail(ctx, ip, undecodableTokenID)
+		}
+		return r.authenticate(ctx, ip, htk, robotName)
+	}
+
+	rawToken, ok := bearerToken(ctx.Request)
 	if !ok {
 		return false
 	}
-	if !strings.HasPrefix(robotName, common.RobotPrefix) {
+	claims := &token.RobotClaims{Leeway: config.RobotTokenClockSkew()}
+	htk, err := token.ParseWithClaims(rawToken, claims)
+	if err != nil {
+		if claims.TokenID > 0 && r.rejectByTime(ctx, claims, "", err) {
+			return false
+		}
+		// the bearer token may be issued for the other purposes, e.g. by the OIDC provider
+		log.Debugf("the bearer token isn't a robot token: %v", err)
 		return false
 	}
-	rClaims := &token.RobotClaims{}
-	htk, err := token.ParseWithClaims(robotTk, rClaims)
-	if err != nil {
-		log.Errorf("failed to decrypt robot token, %v", err)
+	// the registry tokens signed by the same key carry no robot token ID
+	if htk.Claims.(*token.RobotClaims).TokenID <= 0 {
 		return false
 	}
+	return r.authenticate(ctx, sourceIP(ctx.Request), htk, "")
+}
+
+// authenticate looks up the robot by the ID in the token, the name of the robot is checked
+// if the "robotName" isn't empty
+func (r *robotAuthReqCtxModifier) authenticate(ctx *beegoctx.Context, ip string, htk *token.HToken, robotName string) bool {
+	claims := htk.Claims.(*token.RobotClaims)
+	tokenID := claims.TokenID
+	prefix := tokenIDPrefix(tokenID)
+	if !robotTokenLimiter.Allowed(ip, prefix) {
+		return r.tooManyRequests(ctx, ip)
+	}
 	// Do authn for robot account, as Harbor only stores the token ID, just validate the ID and disable.
+	// The ID of the rotated token doesn't match any robot once the overlap window elapses
 	ctr := robot.RobotCtr
-	robot, err := ctr.GetRobotAccount(htk.Claims.(*token.RobotClaims).TokenID)
+	robot, err := ctr.GetRobotAccountByTokenID(tokenID)
 	if err != nil {
-		log.Errorf("failed to get robot %s: %v", robotName, err)
+		log.Errorf("failed to get robot %d: %v", tokenID, err)
 		return false
 	}
 	if robot == nil {
 		log.Error("the token provided doesn't exist.")
-		return false
+		return r.fail(ctx, ip, prefix)
 	}
-	if robotName != robot.Name {
+	if len(robotName) > 0 && robotName != robot.Name {
 		log.Errorf("failed to authenticate : %v", robotName)
-		return false
+		return r.fail(ctx, ip, prefix)
+	}
+	// the token of the project robot can't be used by the system level robot and vice versa
+	if claims.ProjectID != robot.ProjectID {
+		log.Errorf("the project %d in the token doesn't match the robot account %s", claims.ProjectID, robot.Name)
+		return r.fail(ctx, ip, prefix)
 	}
 	if robot.Disabled {
 		log.Errorf("the robot account %s is disabled", robot.Name)
 		return false
 	}
+	robotTokenLimiter.Reset(ip)
+	robotAccessRecorder.Record(robot.ID, time.Now())
 	log.Debug("creating robot account security context...")
 	pm := config.GlobalProjectMgr
-	securCtx := robotCtx.NewSecurityContext(robot, pm, htk.Claims.(*token.RobotClaims).Access)
+	securCtx := robotCtx.NewSecurityContext(robot, pm, claims.Access)
 	setSecurCtxAndPM(ctx.Request, securCtx, pm)
 	return true
 }
 
+// rejectByTime checks whether the token signed by Harbor is rejected only because of its time claims,
+// the reason is returned in the header to tell the client why the request is unauthorized
+func (r *robotAuthReqCtxModifier) rejectByTime(ctx *beegoctx.Context, claims *token.RobotClaims, robotName string, err error) bool {
+	var reason string
+	switch {
+	case token.IsExpired(err):
+		reason = "robot token expired"
+	case token.IsNotValidYet(err):
+		reason = "robot token not valid yet"
+	default:
+		return false
+	}
+	log.Warningf("%s: robot=%q token_id=%d expires_at=%s not_before=%s issued_at=%s clock_skew=%s",
+		reason, robotName, claims.TokenID, unixTime(claims.ExpiresAt), unixTime(claims.NotBefore),
+		unixTime(claims.IssuedAt), claims.Leeway)
+	ctx.ResponseWriter.Header().Set(common.HarborErrorHeader, reason)
+	return true
+}
+
+func unixTime(sec int64) string {
+	if sec == 0 {
+		return "-"
+	}
+	return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+}
+
+// fail records the failed lookup, the request is rejected directly if the limit is exceeded
+func (r *robotAuthReqCtxModifier) fail(ctx *beegoctx.Context, ip, tokenIDPrefix string) bool {
+	if !robotTokenLimiter.Allowed(ip, tokenIDPrefix) {
+		return r.tooManyRequests(ctx, ip)
+	}
+	robotTokenLimiter.Fail(ip, tokenIDPrefix)
+	return false
+}
+
+func (r *robotAuthReqCtxModifier) tooManyRequests(ctx *beegoctx.Context, ip string) bool {
+	log.Warningf("too many failed robot token lookups from %s", ip)
+	ctx.ResponseWriter.WriteHeader(http.StatusTooManyRequests)
+	return true
+}
+
+// impersonationReqCtxModifier handles the requests carrying the impersonation token issued to the
+// system admin, only the read requests are accepted unless the write is allowed by the token
+type impersonationReqCtxModifier struct{}
+
+func (i *impersonationReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
+	raw := ctx.Request.Header.Get(common.ImpersonationTokenHeader)
+	if len(raw) == 0 {
+		return false
+	}
+	if !config.AllowImpersonation() {
+		log.Warning("the impersonation token is ignored as the impersonation is disabled")
+		return false
+	}
+	claims := &token.ImpersonationClaims{}
+	if _, err := token.ParseWithClaims(raw, claims); err != nil {
+		log.Warningf("failed to parse the impersonation token: %v", err)
+		return false
+	}
+	user, err := dao.GetUser(models.User{UserID: claims.UserID})
+	if err != nil {
+		log.Errorf("failed to get the impersonated user %d: %v", claims.UserID, err)
+		return false
+	}
+	if user == nil || user.Username != claims.Subject {
+		log.Warningf("the impersonated user %s doesn't exist", claims.Subject)
+		return false
+	}
+	if !activeUser(user) {
+		return false
+	}
+
+	req := ctx.Request
+	log.Infof("%s %s is requested by %s impersonating %s", req.Method, req.URL.Path, claims.ImpersonatedBy, user.Username)
+	if !claims.AllowWrite && !isReadRequest(req) {
+		log.Warningf("the write request %s %s by %s impersonating %s is rejected", req.Method, req.URL.Path,
+			claims.ImpersonatedBy, user.Username)
+		ctx.ResponseWriter.WriteHeader(http.StatusForbidden)
+		return true
+	}
+
+	pm := config.GlobalProjectMgr
+	securCtx := impersonation.NewSecurityContext(user, claims.ImpersonatedBy, claims.AllowWrite, pm)
+	setSecurCtxAndPM(req, securCtx, pm)
+	return true
+}
+
+// debugMode returns whether Harbor core is running in debug mode
+var debugMode = func() bool {
+	return strings.EqualFold(os.Getenv("LOG_LEVEL"), "debug")
+}
+
+// overrideAuditEntry is the audit record of the request whose security context is overridden
+type overrideAuditEntry struct {
+	IsOverride bool   `json:"is_override"`
+	Username   string `json:"username"`
+	IssuedBy   string `json:"issued_by"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	SourceIP   string `json:"source_ip"`
+}
+
+// overrideReqCtxModifier handles the requests carrying the override token signed by the
+// key of Harbor, it only takes effect in debug mode and when the override is allowed
+type overrideReqCtxModifier struct{}
+
+func (o *overrideReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
+	raw := ctx.Request.Header.Get(common.OverrideUserHeader)
+	if len(raw) == 0 {
+		return false
+	}
+	if !config.AllowAdminOverride() || !debugMode() {
+		log.Warning("the override token is ignored as the override is disabled or not in debug mode")
+		return false
+	}
+	claims := &token.OverrideClaims{}
+	if _, err := token.ParseWithClaims(raw, claims); err != nil {
+		log.Warningf("failed to parse the override token: %v", err)
+		return false
+	}
+	user, err := dao.GetUser(models.User{Username: claims.Username})
+	if err != nil {
+		log.Errorf("failed to get the user %s: %v", claims.Username, err)
+		return false
+	}
+	if user == nil || user.Disabled {
+		log.Warningf("the user %s doesn't exist or is disabled", claims.Username)
+		return false
+	}
+
+	req := ctx.Request
+	auditOverride(&overrideAuditEntry{
+		IsOverride: true,
+		Username:   user.Username,
+		IssuedBy:   claims.IssuedBy,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		SourceIP:   sourceIP(req),
+	})
+	pm := config.GlobalProjectMgr
+	setSecurCtxAndPM(req, local.NewSecurityContext(user, pm), pm)
+	return true
+}
+
+func auditOverride(entry *overrideAuditEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Errorf("failed to marshal the override audit entry: %v", err)
+		return
+	}
+	log.Infof("audit: %s", string(data))
+}
+
+func isReadRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// activeUser returns false if the user resolved from the request is disabled, every modifier
+// building the security context for a user checks it, as the disabled user is only rejected by
+// the login otherwise
+func activeUser(user *models.User) bool {
+	if user.Disabled {
+		log.Warningf("the user %s is disabled, reject the request", user.Username)
+		return false
+	}
+	return true
+}
+
+// updateLastLoginTime records the request authenticated as the user as its login, so the users
+// only accessing via the CLI or the API aren't treated as inactive. The write is throttled by dao
+func updateLastLoginTime(user *models.User) {
+	if err := dao.UpdateLastLoginTime(user.UserID, time.Now()); err != nil {
+		log.Warningf("failed to update the last login time of user %s: %v", user.Username, err)
+	}
+}
+
 type oidcCliReqCtxModifier struct{}
 
 func (oc *oidcCliReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
@@ -245,19 +686,24 @@ func (oc *oidcCliReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 		log.Errorf("Failed to get user: %v", err)
 		return false
 	}
-	if user == nil {
+	if user == nil || !activeUser(user) {
 		return false
 	}
 	if err := oidc.VerifySecret(ctx.Request.Context(), user.UserID, secret); err != nil {
 		log.Errorf("Failed to verify secret: %v", err)
 		return false
 	}
+	updateLastLoginTime(user)
 	pm := config.GlobalProjectMgr
 	sc := local.NewSecurityContext(user, pm)
 	setSecurCtxAndPM(ctx.Request, sc, pm)
 	return true
 }
 
+// idTokenReqCtxModifier handles the API requests carrying the token issued by the OIDC provider in
+// the "Authorization: Bearer" header, the issuer, audience and expiry of the token are verified and
+// its subject is mapped to the onboarded user. It skips when the auth mode isn't OIDC or the token
+// is invalid, so the subsequent modifiers get the chance to handle the request
 type idTokenReqCtxModifier struct{}
 
 func (it *idTokenReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
@@ -268,12 +714,11 @@ func (it *idTokenReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	if !strings.HasPrefix(ctx.Request.URL.Path, "/api") {
 		return false
 	}
-	h := req.Header.Get("Authorization")
-	token := strings.Split(h, "Bearer")
-	if len(token) < 2 {
+	token, ok := bearerToken(req)
+	if !ok {
 		return false
 	}
-	claims, err := oidc.VerifyToken(req.Context(), strings.TrimSpace(token[1]))
+	claims, err := oidc.VerifyToken(req.Context(), token)
 	if err != nil {
 		log.Warningf("Failed to verify token, error: %v", err)
 		return false
@@ -287,6 +732,10 @@ func (it *idTokenReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 		log.Warning("User matches token's claims is not onboarded.")
 		return false
 	}
+	if !activeUser(u) {
+		return false
+	}
+	updateLastLoginTime(u)
 	u.GroupIDs, err = group.GetGroupIDByGroupName(oidc.GroupsFromToken(claims), common.OIDCGroupType)
 	if err != nil {
 		log.Errorf("Failed to get group ID list for OIDC user: %s, error: %v", u.Username, err)
@@ -297,16 +746,72 @@ func (it *idTokenReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	return true
 }
 
-type authProxyReqCtxModifier struct{}
+// bearerToken returns the token carried by the "Authorization" header in the "Bearer" scheme,
+// the scheme is case insensitive
+func bearerToken(req *http.Request) (string, bool) {
+	return authorizationCredential(req, "Bearer")
+}
 
-func (ap *authProxyReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
-	if ctx.Request.Context().Value(AuthModeKey).(string) != common.HTTPAuth {
+// authorizationCredential returns the credential carried by the "Authorization" header in the
+// scheme, the scheme is case insensitive
+func authorizationCredential(req *http.Request, scheme string) (string, bool) {
+	parts := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], scheme) {
+		return "", false
+	}
+	credential := strings.TrimSpace(parts[1])
+	return credential, len(credential) > 0
+}
+
+// apiKeyReqCtxModifier authenticates the requests carrying the API key of user in the
+// X-Harbor-API-Key header or the "Authorization" header in the "ApiKey" scheme
+type apiKeyReqCtxModifier struct{}
+
+func (a *apiKeyReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
+	key := ctx.Request.Header.Get(common.APIKeyHeader)
+	if len(key) == 0 {
+		var ok bool
+		if key, ok = authorizationCredential(ctx.Request, "ApiKey"); !ok {
+			return false
+		}
+	}
+	apiKey, err := dao.GetAPIKeyByHash(dao.HashAPIKey(key))
+	if err != nil {
+		log.Errorf("failed to get the API key: %v", err)
+		return false
+	}
+	if apiKey == nil {
+		log.Warningf("invalid API key from %s", sourceIP(ctx.Request))
+		return false
+	}
+	if apiKey.IsExpired(time.Now()) {
+		log.Warningf("the API key %d of user %d is expired at %s", apiKey.ID, apiKey.UserID, unixTime(apiKey.ExpiresAt))
+		ctx.ResponseWriter.Header().Set(common.HarborErrorHeader, "api key expired")
 		return false
 	}
+	user, err := dao.GetUser(models.User{UserID: apiKey.UserID})
+	if err != nil {
+		log.Errorf("failed to get the user %d of the API key %d: %v", apiKey.UserID, apiKey.ID, err)
+		return false
+	}
+	if user == nil {
+		log.Warningf("the user %d of the API key %d doesn't exist", apiKey.UserID, apiKey.ID)
+		return false
+	}
+	if !activeUser(user) {
+		return false
+	}
+	log.Debugf("got user %s via API key %d", user.Username, apiKey.ID)
+	updateLastLoginTime(user)
+	pm := config.GlobalProjectMgr
+	setSecurCtxAndPM(ctx.Request, local.NewSecurityContext(user, pm), pm)
+	return true
+}
 
-	// only support docker login
-	if ctx.Request.URL.Path != "/service/token" {
-		log.Debug("Auth proxy modifier only handles docker login request.")
+type authProxyReqCtxModifier struct{}
+
+func (ap *authProxyReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
+	if ctx.Request.Context().Value(AuthModeKey).(string) != common.HTTPAuth {
 		return false
 	}
 
@@ -315,9 +820,13 @@ func (ap *authProxyReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 		return false
 	}
 
+	dockerLogin := ctx.Request.URL.Path == "/service/token"
 	rawUserName, match := ap.matchAuthProxyUserName(proxyUserName)
 	if !match {
-		log.Errorf("User name %s doesn't meet the auth proxy name pattern", proxyUserName)
+		// the other requests carrying the basic auth are handled by the following modifiers
+		if dockerLogin {
+			log.Errorf("User name %s doesn't meet the auth proxy name pattern", proxyUserName)
+		}
 		return false
 	}
 	httpAuthProxyConf, err := config.HTTPAuthProxySetting()
@@ -325,7 +834,12 @@ func (ap *authProxyReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 		log.Errorf("fail to get auth proxy settings, %v", err)
 		return false
 	}
-	tokenReviewResponse, err := authproxy.TokenReview(proxyPwd, httpAuthProxyConf)
+	// only support docker login unless the API access is enabled
+	if !dockerLogin && !httpAuthProxyConf.APIAccess {
+		log.Debug("Auth proxy modifier only handles docker login request.")
+		return false
+	}
+	tokenReviewResponse, err := tokenReviewer.Review(proxyPwd, httpAuthProxyConf)
 	if err != nil {
 		log.Errorf("fail to review token, %v", err)
 		return false
@@ -335,6 +849,10 @@ func (ap *authProxyReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 		log.Errorf("fail to auth user: %s", rawUserName)
 		return false
 	}
+	if rawUserName != tokenReviewResponse.Status.User.Username {
+		log.Errorf("user name doesn't match with token: %s", rawUserName)
+		return false
+	}
 	user, err := dao.GetUser(models.User{
 		Username: rawUserName,
 	})
@@ -343,13 +861,25 @@ func (ap *authProxyReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 		return false
 	}
 	if user == nil {
-		log.Errorf("User: %s has not been on boarded yet.", rawUserName)
-		return false
+		if !httpAuthProxyConf.AutoOnboard {
+			log.Errorf("User: %s has not been on boarded yet.", rawUserName)
+			return false
+		}
+		user, err = ap.onboardUser(rawUserName)
+		if err != nil {
+			log.Errorf("failed to onboard user %s: %v", rawUserName, err)
+			return false
+		}
 	}
-	if rawUserName != tokenReviewResponse.Status.User.Username {
-		log.Errorf("user name doesn't match with token: %s", rawUserName)
+	if !activeUser(user) {
 		return false
 	}
+	updateLastLoginTime(user)
+	// the groups are onboarded so that the project roles granted to them take effect
+	user.GroupIDs, err = group.PopulateGroup(tokenReviewResponse.Status.User.Groups, common.HTTPGroupType)
+	if err != nil {
+		log.Errorf("failed to populate the groups of user %s: %v", rawUserName, err)
+	}
 
 	pm := config.GlobalProjectMgr
 	log.Debug("creating local database security context for auth proxy...")
@@ -358,6 +888,23 @@ func (ap *authProxyReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	return true
 }
 
+// onboardUser creates the user authenticated by the auth proxy without password, the user name
+// is used as the email only if it looks like one
+func (ap *authProxyReqCtxModifier) onboardUser(username string) (*models.User, error) {
+	user := &models.User{
+		Username: username,
+		Realname: username,
+		Comment:  "By Authproxy",
+	}
+	if strings.Contains(username, "@") {
+		user.Email = username
+	}
+	if err := dao.OnBoardUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 func (ap *authProxyReqCtxModifier) matchAuthProxyUserName(name string) (string, bool) {
 	if !strings.HasPrefix(name, common.AuthProxyUserNamePrefix) {
 		return "", false
@@ -367,6 +914,23 @@ func (ap *authProxyReqCtxModifier) matchAuthProxyUserName(name string) (string,
 
 type basicAuthReqCtxModifier struct{}
 
+// matchBasicAuthReqPatterns checks whether the request is one of those supporting basic auth in
+// Admiral mode
+func matchBasicAuthReqPatterns(req *http.Request) bool {
+	for _, pattern := range basicAuthReqPatterns {
+		if pattern.match(req) {
+			return true
+		}
+	}
+	return false
+}
+
+// basicAuthAllowedPath checks whether the basic auth is honored for the path when it's disabled
+// for the API, the docker client logins via the token service and the registry
+func basicAuthAllowedPath(path string) bool {
+	return path == "/service/token" || strings.HasPrefix(path, "/v2/")
+}
+
 func (b *basicAuthReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	username, password, ok := ctx.Request.BasicAuth()
 	if !ok {
@@ -380,20 +944,7 @@ func (b *basicAuthReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 		// create a project manager with the token of the solution user.
 		// That way may cause some wrong permission promotion in some API
 		// calls, so we just handle the requests which are necessary
-		match := false
-		var err error
-		path := ctx.Request.URL.Path
-		for _, pattern := range basicAuthReqPatterns {
-			match, err = regexp.MatchString(pattern.path, path)
-			if err != nil {
-				log.Errorf("failed to match %s with pattern %s", path, pattern)
-				continue
-			}
-			if match {
-				break
-			}
-		}
-		if !match {
+		if !matchBasicAuthReqPatterns(ctx.Request) {
 			log.Debugf("basic auth is not supported for request %s %s, skip",
 				ctx.Request.Method, ctx.Request.URL.Path)
 			return false
@@ -421,18 +972,53 @@ func (b *basicAuthReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	}
 
 	// standalone
-	user, err := auth.Login(models.AuthModel{
-		Principal: username,
-		Password:  password,
-	})
-	if err != nil {
-		log.Errorf("failed to authenticate %s: %v", username, err)
-		return false
+	if config.DisableBasicAuthAPI() && !basicAuthAllowedPath(ctx.Request.URL.Path) {
+		log.Debugf("basic auth is disabled for request %s %s", ctx.Request.Method, ctx.Request.URL.Path)
+		e := &commonhttp.Error{
+			Code:    http.StatusUnauthorized,
+			Message: "basic auth is disabled for the API, use the robot accounts instead",
+		}
+		ctx.ResponseWriter.Header().Set("WWW-Authenticate", `Basic realm="harbor"`)
+		ctx.ResponseWriter.Header().Set("Content-Type", "application/json")
+		ctx.ResponseWriter.WriteHeader(e.Code)
+		ctx.ResponseWriter.Write([]byte(e.String()))
+		return true
+	}
+	ip := sourceIP(ctx.Request)
+	if !basicAuthLimiter.Allowed(ip) {
+		log.Warningf("too many failed basic auth attempts from %s", ip)
+		ctx.ResponseWriter.WriteHeader(http.StatusTooManyRequests)
+		return true
 	}
+	user := basicAuthResults.get(username, password)
 	if user == nil {
-		log.Debug("basic auth user is nil")
+		var err error
+		user, err = auth.Login(models.AuthModel{
+			Principal: username,
+			Password:  password,
+		})
+		if err != nil {
+			log.Errorf("failed to authenticate %s: %v", username, err)
+			// the failures of the auth backend are not counted
+			if _, ok := err.(auth.ErrAuth); ok {
+				basicAuthLimiter.Fail(ip)
+			}
+			return false
+		}
+		if user == nil {
+			log.Debug("basic auth user is nil")
+			basicAuthLimiter.Fail(ip)
+			return false
+		}
+		updateLastLoginTime(user)
+		markLDAPGroupAdmin(user)
+		basicAuthResults.put(username, password, user, config.BasicAuthCacheTTL())
+	}
+	// the cached user is invalidated when it's disabled, this covers the one disabled by other instances
+	if !activeUser(user) {
 		return false
 	}
+	basicAuthLimiter.Reset(ip)
 	log.Debug("using local database project manager")
 	pm := config.GlobalProjectMgr
 	log.Debug("creating local database security context...")
@@ -441,6 +1027,53 @@ func (b *basicAuthReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	return true
 }
 
+// markLDAPGroupAdmin grants the system admin to the user who is a member of the LDAP admin group,
+// it only applies to the security context of the request and isn't persisted, so the changes of
+// the membership take effect on the next login
+func markLDAPGroupAdmin(user *models.User) {
+	if user.HasAdminRole || len(user.LDAPGroupDNs) == 0 {
+		return
+	}
+	groupCfg, err := config.LDAPGroupConf()
+	if err != nil {
+		log.Errorf("failed to get the LDAP group config: %v", err)
+		return
+	}
+	if len(groupCfg.LdapGroupAdminDN) == 0 {
+		return
+	}
+	if ldapUtils.InGroup(user.LDAPGroupDNs, groupCfg.LdapGroupAdminDN) {
+		log.Debugf("%s is a member of the LDAP admin group %s", user.Username, groupCfg.LdapGroupAdminDN)
+		user.HasAdminRole = true
+	}
+}
+
+// samlReqCtxModifier validates the session established via the SAML IdP, the session is
+// invalidated once the time asserted by the IdP is passed
+type samlReqCtxModifier struct{}
+
+func (s *samlReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
+	if ctx.Request.Context().Value(AuthModeKey).(string) != common.SAMLAuth {
+		return false
+	}
+	expiry, ok := ctx.Input.Session(saml.SessionExpiryKey).(int64)
+	if !ok {
+		return false
+	}
+	if time.Now().Unix() < expiry {
+		// let the session modifier populate the security context
+		return false
+	}
+	log.Debug("the SAML session has expired, invalidate it")
+	if err := ctx.Input.CruSession.Delete("user"); err != nil {
+		log.Errorf("failed to delete the user from the expired SAML session: %v", err)
+	}
+	if err := ctx.Input.CruSession.Delete(saml.SessionExpiryKey); err != nil {
+		log.Errorf("failed to delete the expiry from the expired SAML session: %v", err)
+	}
+	return false
+}
+
 type sessionReqCtxModifier struct{}
 
 func (s *sessionReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
@@ -455,6 +1088,27 @@ func (s *sessionReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 		log.Info("can not get user information from session")
 		return false
 	}
+	u, err := dao.GetUser(models.User{UserID: user.UserID})
+	if err != nil {
+		log.Errorf("failed to get user %d: %v", user.UserID, err)
+		return false
+	}
+	if u == nil {
+		log.Infof("the user %d in session doesn't exist", user.UserID)
+		return false
+	}
+	if !activeUser(u) {
+		return false
+	}
+	// rotate the session ID to prevent the session fixation
+	loginTime, _ := ctx.Input.Session(SessionLoginTimeKey).(int64)
+	if isStaleSession(loginTime, u) {
+		log.Debugf("the session of user %s is stale, regenerate its ID", user.Username)
+		if err := refreshSession(ctx); err != nil {
+			log.Errorf("failed to refresh the session of user %s: %v", user.Username, err)
+			return false
+		}
+	}
 	if ctx.Request.Context().Value(AuthModeKey).(string) == common.OIDCAuth {
 		ou, err := dao.GetOIDCUserByUserID(user.UserID)
 		if err != nil {
@@ -469,10 +1123,19 @@ func (s *sessionReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 			}
 		}
 	}
+	// the session is carried by the browser automatically, the state-changing API requests must prove
+	// they're sent by the UI with the token of the session
+	if csrfProtected(ctx.Request) && !validCSRFToken(ctx) {
+		return rejectCSRF(ctx, user.Username)
+	}
 	log.Debug("using local database project manager")
 	pm := config.GlobalProjectMgr
 	log.Debug("creating local database security context...")
-	securCtx := local.NewSecurityContext(&user, pm)
+	// the user in session may be stale, the groups only populated when logging in are kept
+	u.GroupIDs = user.GroupIDs
+	u.LDAPGroupDNs = user.LDAPGroupDNs
+	markLDAPGroupAdmin(u)
+	securCtx := local.NewSecurityContext(u, pm)
 
 	setSecurCtxAndPM(ctx.Request, securCtx, pm)
 
@@ -517,6 +1180,12 @@ type unauthorizedReqCtxModifier struct{}
 func (u *unauthorizedReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	log.Debug("user information is nil")
 
+	if ranges := config.AnonymousAccessCIDRs(); len(ranges) > 0 && !anonymousAccessExempted(ctx.Request.URL.Path) {
+		if ip := sourceIP(ctx.Request); !ipInRanges(ip, ranges) {
+			return u.unauthorized(ctx, ip)
+		}
+	}
+
 	var securCtx security.Context
 	var pm promgr.ProjectManager
 	if config.WithAdmiral() {
@@ -538,6 +1207,30 @@ func (u *unauthorizedReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	return true
 }
 
+// unauthorized rejects the anonymous request with the challenge, the one of the token service is
+// returned for the registry API so that the docker client can login
+func (u *unauthorizedReqCtxModifier) unauthorized(ctx *beegoctx.Context, ip string) bool {
+	log.Debugf("anonymous access from %s isn't allowed", ip)
+	challenge := `Basic realm="harbor"`
+	if strings.HasPrefix(ctx.Request.URL.Path, "/v2/") {
+		endpoint, _ := config.ExtEndpoint()
+		challenge = fmt.Sprintf(`Bearer realm="%s/service/token",service="harbor-registry"`, strings.TrimSuffix(endpoint, "/"))
+	}
+	ctx.ResponseWriter.Header().Set("WWW-Authenticate", challenge)
+	ctx.ResponseWriter.WriteHeader(http.StatusUnauthorized)
+	return true
+}
+
+// anonymousAccessExempted checks whether the path is accessible anonymously from anywhere
+func anonymousAccessExempted(path string) bool {
+	for _, prefix := range anonymousAccessExemptedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return path == "/api/systeminfo" || path == "/api/ping"
+}
+
 func setSecurCtxAndPM(req *http.Request, ctx security.Context, pm promgr.ProjectManager) {
 	addToReqContext(req, SecurCtxKey, ctx)
 	addToReqContext(req, PmKey, pm)
@@ -552,17 +1245,10 @@ func GetSecurityContext(req *http.Request) (security.Context, error) {
 	if req == nil {
 		return nil, fmt.Errorf("request is nil")
 	}
-
-	ctx := req.Context().Value(SecurCtxKey)
-	if ctx == nil {
-		return nil, fmt.Errorf("the security context got from request is nil")
-	}
-
-	c, ok := ctx.(security.Context)
+	c, ok := SecurityContextFrom(req.Context())
 	if !ok {
-		return nil, fmt.Errorf("the variable got from request is not security context type")
+		return nil, fmt.Errorf("no security context found in the request")
 	}
-
 	return c, nil
 }
 
@@ -571,16 +1257,39 @@ func GetProjectManager(req *http.Request) (promgr.ProjectManager, error) {
 	if req == nil {
 		return nil, fmt.Errorf("request is nil")
 	}
+	pm, ok := ProjectManagerFrom(req.Context())
+	if !ok {
+		return nil, fmt.Errorf("no project manager found in the request")
+	}
+	return pm, nil
+}
+
+// SecurityContextFrom returns the security context in the context, false is returned if there is
+// no security context or the value isn't a security context
+func SecurityContextFrom(ctx context.Context) (security.Context, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	c, ok := ctx.Value(SecurCtxKey).(security.Context)
+	return c, ok && c != nil
+}
 
-	pm := req.Context().Value(PmKey)
-	if pm == nil {
-		return nil, fmt.Errorf("the project manager got from request is nil")
+// ProjectManagerFrom returns the project manager in the context, false is returned if there is
+// no project manager or the value isn't a project manager
+func ProjectManagerFrom(ctx context.Context) (promgr.ProjectManager, bool) {
+	if ctx == nil {
+		return nil, false
 	}
+	pm, ok := ctx.Value(PmKey).(promgr.ProjectManager)
+	return pm, ok && pm != nil
+}
 
-	p, ok := pm.(promgr.ProjectManager)
+// MustSecurityContext returns the security context in the context, it panics if there is no
+// security context, so it's only for the internal callers running after the security filter
+func MustSecurityContext(ctx context.Context) security.Context {
+	c, ok := SecurityContextFrom(ctx)
 	if !ok {
-		return nil, fmt.Errorf("the variable got from request is not project manager type")
+		panic("no security context found in the context, the request isn't handled by the security filter")
 	}
-
-	return p, nil
+	return c
 }
@@ -15,13 +15,20 @@
 package filter
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -31,20 +38,34 @@ import (
 	"github.com/astaxie/beego"
 	beegoctx "github.com/astaxie/beego/context"
 	"github.com/astaxie/beego/session"
+	"github.com/dgrijalva/jwt-go"
 	config2 "github.com/goharbor/harbor/src/common/config"
 	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/dao/group"
+	"github.com/goharbor/harbor/src/common/dao/project"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
 	commonsecret "github.com/goharbor/harbor/src/common/secret"
 	"github.com/goharbor/harbor/src/common/security"
+	"github.com/goharbor/harbor/src/common/security/impersonation"
 	"github.com/goharbor/harbor/src/common/security/local"
+	robotCtx "github.com/goharbor/harbor/src/common/security/robot"
 	"github.com/goharbor/harbor/src/common/security/secret"
+	"github.com/goharbor/harbor/src/common/token"
+	hlog "github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/common/utils/test"
 	_ "github.com/goharbor/harbor/src/core/auth/db"
 	_ "github.com/goharbor/harbor/src/core/auth/ldap"
+	"github.com/goharbor/harbor/src/core/auth/saml"
 	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/filter/metrics"
 	"github.com/goharbor/harbor/src/core/promgr"
 	driver_local "github.com/goharbor/harbor/src/core/promgr/pmsdriver/local"
+	"github.com/goharbor/harbor/src/pkg/authproxy"
+	"github.com/goharbor/harbor/src/pkg/robot"
+	robotModel "github.com/goharbor/harbor/src/pkg/robot/model"
 	"github.com/stretchr/testify/assert"
+	k8s_api_v1beta1 "k8s.io/api/authentication/v1beta1"
 
 	"github.com/goharbor/harbor/src/common"
 	fiter_test "github.com/goharbor/harbor/src/core/filter/test"
@@ -71,7 +92,9 @@ func TestMain(m *testing.M) {
 	test.InitDatabaseFromEnv()
 
 	config.Upload(test.GetUnitTestConfig())
-	Init()
+	if err := Init(); err != nil {
+		log.Fatalf("failed to initialize the request context modifiers: %v", err)
+	}
 
 	os.Exit(m.Run())
 }
@@ -145,6 +168,31 @@ func TestSecretReqCtxModifier(t *testing.T) {
 	assert.NotNil(t, projectManager(ctx))
 }
 
+func TestSecretReqCtxModifierRotation(t *testing.T) {
+	store := commonsecret.NewStore(map[string]string{"secret1": commonsecret.JobserviceUser})
+	store.Rotate(commonsecret.JobserviceUser, "secret2", 50*time.Millisecond)
+	modifier := &secretReqCtxModifier{store}
+
+	authenticated := func(scrt string) bool {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		commonsecret.AddToRequest(req, scrt)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		require.True(t, modifier.Modify(ctx))
+		sc, err := GetSecurityContext(ctx.Request)
+		require.Nil(t, err)
+		return sc.IsAuthenticated()
+	}
+	// both secrets are valid within the grace period
+	assert.True(t, authenticated("secret1"))
+	assert.True(t, authenticated("secret2"))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.False(t, authenticated("secret1"))
+	assert.True(t, authenticated("secret2"))
+}
+
 func TestOIDCCliReqCtxModifier(t *testing.T) {
 	conf := map[string]interface{}{
 		common.AUTHMode:         common.OIDCAuth,
@@ -219,6 +267,29 @@ func TestIdTokenReqCtxModifier(t *testing.T) {
 	assert.False(t, it.Modify(ctx3))
 }
 
+func TestBearerToken(t *testing.T) {
+	cases := []struct {
+		header string
+		token  string
+		ok     bool
+	}{
+		{header: "", ok: false},
+		{header: "Bearer", ok: false},
+		{header: "Bearer  ", ok: false},
+		{header: "Basic dXNlcjpCZWFyZXI=", ok: false},
+		{header: "Bearer abc.def.ghi", token: "abc.def.ghi", ok: true},
+		{header: "bearer abc.def.ghi", token: "abc.def.ghi", ok: true},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.Header.Set("Authorization", c.header)
+		token, ok := bearerToken(req)
+		assert.Equal(t, c.ok, ok, c.header)
+		assert.Equal(t, c.token, token, c.header)
+	}
+}
+
 func TestRobotReqCtxModifier(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet,
 		"http://127.0.0.1/api/projects/", nil)
@@ -236,6 +307,587 @@ func TestRobotReqCtxModifier(t *testing.T) {
 	assert.False(t, modified)
 }
 
+func TestRobotReqCtxModifierRateLimit(t *testing.T) {
+	modifier := &robotAuthReqCtxModifier{}
+	for i := 1; i <= robotMaxFailedLookups+1; i++ {
+		req, err := http.NewRequest(http.MethodGet,
+			"http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.RemoteAddr = "10.10.10.10:12345"
+		req.SetBasicAuth("robot$test1", "invalid-token-id")
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		rec := httptest.NewRecorder()
+		ctx.Reset(rec, req)
+
+		modified := modifier.Modify(ctx)
+		if i <= robotMaxFailedLookups {
+			assert.False(t, modified)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			continue
+		}
+		assert.True(t, modified)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	}
+	robotTokenLimiter.Reset("10.10.10.10")
+}
+
+func TestRobotReqCtxModifierBearer(t *testing.T) {
+	rb, err := robot.RobotCtr.CreateRobotAccount(&robotModel.RobotCreate{
+		Name:      "bearer",
+		ProjectID: 1,
+		Access: []*rbac.Policy{
+			{Resource: "/project/1/repository", Action: "pull"},
+		},
+	})
+	require.Nil(t, err)
+	defer robot.RobotCtr.DeleteRobotAccount(rb.ID)
+
+	newCtx := func(authorization string) (*beegoctx.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.RemoteAddr = "10.10.10.11:12345"
+		req.Header.Set("Authorization", authorization)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		rec := httptest.NewRecorder()
+		ctx.Reset(rec, req)
+		return ctx, rec
+	}
+	modifier := &robotAuthReqCtxModifier{}
+
+	ctx, _ := newCtx("Bearer " + rb.Token)
+	assert.True(t, modifier.Modify(ctx))
+	sc := securityContext(ctx)
+	require.IsType(t, &robotCtx.SecurityContext{}, sc)
+	assert.Equal(t, rb.Name, sc.(*robotCtx.SecurityContext).GetUsername())
+	// the last access time is recorded on success
+	rm, err := robot.RobotCtr.GetRobotAccount(rb.ID)
+	require.Nil(t, err)
+	assert.False(t, rm.LastAccessTime.IsZero())
+
+	// the malformed bearer tokens fall through without being counted as the failed lookups
+	for i := 0; i <= robotMaxFailedLookups; i++ {
+		ctx, rec := newCtx("Bearer invalid-token")
+		assert.False(t, modifier.Modify(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	assert.True(t, robotTokenLimiter.Allowed("10.10.10.11", undecodableTokenID))
+
+	// the token not issued to a robot falls through
+	tk, err := token.NewImpersonation(1, "admin", "impersonator", false, 5*time.Minute)
+	require.Nil(t, err)
+	raw, err := tk.Raw()
+	require.Nil(t, err)
+	ctx, _ = newCtx("Bearer " + raw)
+	assert.False(t, modifier.Modify(ctx))
+}
+
+func TestRobotReqCtxModifierSystemRobot(t *testing.T) {
+	rb, err := robot.RobotCtr.CreateRobotAccount(&robotModel.RobotCreate{
+		Name: "system",
+		Access: []*rbac.Policy{
+			{Resource: "/project/*/repository", Action: "pull"},
+		},
+	})
+	require.Nil(t, err)
+	defer robot.RobotCtr.DeleteRobotAccount(rb.ID)
+
+	modifier := &robotAuthReqCtxModifier{}
+	newCtx := func(raw string) *beegoctx.Context {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.RemoteAddr = "10.10.10.14:12345"
+		req.SetBasicAuth(rb.Name, raw)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		ctx.Reset(httptest.NewRecorder(), req)
+		return ctx
+	}
+
+	ctx := newCtx(rb.Token)
+	assert.True(t, modifier.Modify(ctx))
+	sc := securityContext(ctx)
+	require.IsType(t, &robotCtx.SecurityContext{}, sc)
+	assert.True(t, sc.(*robotCtx.SecurityContext).Can(rbac.ActionPull, rbac.NewProjectNamespace(1).Resource(rbac.ResourceRepository)))
+
+	// the token carrying a project doesn't work for the system level robot
+	htk := &token.HToken{
+		Token: *jwt.NewWithClaims(token.DefaultOptions().SignMethod, &token.RobotClaims{
+			StandardClaims: jwt.StandardClaims{Issuer: token.DefaultOptions().Issuer},
+			TokenID:        rb.ID,
+			ProjectID:      1,
+			Access:         []*rbac.Policy{{Resource: "/project/1/repository", Action: "pull"}},
+		}),
+	}
+	raw, err := htk.Raw()
+	require.Nil(t, err)
+	assert.False(t, modifier.Modify(newCtx(raw)))
+	robotTokenLimiter.Reset("10.10.10.14")
+}
+
+func TestRobotReqCtxModifierRotation(t *testing.T) {
+	rb, err := robot.RobotCtr.CreateRobotAccount(&robotModel.RobotCreate{
+		Name:      "rotation",
+		ProjectID: 1,
+		Access: []*rbac.Policy{
+			{Resource: "/project/1/repository", Action: "pull"},
+		},
+	})
+	require.Nil(t, err)
+	defer robot.RobotCtr.DeleteRobotAccount(rb.ID)
+
+	modifier := &robotAuthReqCtxModifier{}
+	authenticated := func(raw string) bool {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.RemoteAddr = "10.10.10.15:12345"
+		req.SetBasicAuth(rb.Name, raw)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		ctx.Reset(httptest.NewRecorder(), req)
+		return modifier.Modify(ctx) && securityContext(ctx) != nil
+	}
+	defer robotTokenLimiter.Reset("10.10.10.15")
+
+	// both tokens are accepted during the overlap window
+	old := rb.Token
+	rm, err := robot.RobotCtr.GetRobotAccount(rb.ID)
+	require.Nil(t, err)
+	rotated, err := robot.RobotCtr.RotateRobotToken(rm, nil, time.Minute)
+	require.Nil(t, err)
+	assert.Equal(t, rb.Name, rotated.Name)
+	assert.True(t, authenticated(old))
+	assert.True(t, authenticated(rotated.Token))
+
+	// the old token is rejected right after the rotation without overlap
+	old = rotated.Token
+	rotated, err = robot.RobotCtr.RotateRobotToken(rotated, nil, 0)
+	require.Nil(t, err)
+	assert.False(t, authenticated(old))
+	assert.False(t, authenticated(rb.Token))
+	assert.True(t, authenticated(rotated.Token))
+}
+
+func TestRobotReqCtxModifierTime(t *testing.T) {
+	rb, err := robot.RobotCtr.CreateRobotAccount(&robotModel.RobotCreate{
+		Name:      "time",
+		ProjectID: 1,
+		Access: []*rbac.Policy{
+			{Resource: "/project/1/repository", Action: "pull"},
+		},
+	})
+	require.Nil(t, err)
+	defer robot.RobotCtr.DeleteRobotAccount(rb.ID)
+
+	now := time.Now().Unix()
+	sign := func(claims jwt.StandardClaims) string {
+		claims.Issuer = token.DefaultOptions().Issuer
+		htk := &token.HToken{
+			Token: *jwt.NewWithClaims(token.DefaultOptions().SignMethod, &token.RobotClaims{
+				StandardClaims: claims,
+				TokenID:        rb.ID,
+				ProjectID:      1,
+				Access:         []*rbac.Policy{{Resource: "/project/1/repository", Action: "pull"}},
+			}),
+		}
+		raw, err := htk.Raw()
+		require.Nil(t, err)
+		return raw
+	}
+	newCtx := func(basic bool, raw string) (*beegoctx.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.RemoteAddr = "10.10.10.13:12345"
+		if basic {
+			req.SetBasicAuth(rb.Name, raw)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+raw)
+		}
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		rec := httptest.NewRecorder()
+		ctx.Reset(rec, req)
+		return ctx, rec
+	}
+	modifier := &robotAuthReqCtxModifier{}
+	expired := sign(jwt.StandardClaims{IssuedAt: now - 120, ExpiresAt: now - 60})
+	notValidYet := sign(jwt.StandardClaims{IssuedAt: now + 60, ExpiresAt: now + 120})
+
+	// expired
+	for _, basic := range []bool{true, false} {
+		ctx, rec := newCtx(basic, expired)
+		assert.False(t, modifier.Modify(ctx))
+		assert.Equal(t, "robot token expired", rec.Header().Get(common.HarborErrorHeader))
+	}
+	// the expired tokens are not counted as the failed lookups
+	for i := 0; i <= robotMaxFailedLookups; i++ {
+		ctx, rec := newCtx(true, expired)
+		assert.False(t, modifier.Modify(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	assert.True(t, robotTokenLimiter.Allowed("10.10.10.13", undecodableTokenID))
+
+	// not valid yet
+	for _, basic := range []bool{true, false} {
+		ctx, rec := newCtx(basic, notValidYet)
+		assert.False(t, modifier.Modify(ctx))
+		assert.Equal(t, "robot token not valid yet", rec.Header().Get(common.HarborErrorHeader))
+	}
+
+	// skewed clock within the grace period
+	config.Upload(map[string]interface{}{common.RobotTokenClockSkew: 120})
+	defer config.Upload(map[string]interface{}{common.RobotTokenClockSkew: 0})
+	for _, raw := range []string{expired, notValidYet} {
+		ctx, rec := newCtx(true, raw)
+		assert.True(t, modifier.Modify(ctx))
+		assert.Empty(t, rec.Header().Get(common.HarborErrorHeader))
+	}
+	robotTokenLimiter.Reset("10.10.10.13")
+}
+
+func TestSecurityFilterMetrics(t *testing.T) {
+	config.Upload(map[string]interface{}{common.AuthMetricsEnabled: true})
+	require.Nil(t, Init())
+	defer func() {
+		config.Upload(map[string]interface{}{common.AuthMetricsEnabled: false})
+		require.Nil(t, Init())
+	}()
+
+	newCtx := func(username, password string) *beegoctx.Context {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		if len(username) > 0 {
+			req.SetBasicAuth(username, password)
+		}
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		return ctx
+	}
+
+	unauthorized := metrics.Requests(ModifierUnauthorized, metrics.ResultFailure)
+	SecurityFilter(newCtx("", ""))
+	assert.Equal(t, unauthorized+1, metrics.Requests(ModifierUnauthorized, metrics.ResultFailure))
+
+	basicAuth := metrics.Requests(ModifierBasicAuth, metrics.ResultSuccess)
+	SecurityFilter(newCtx("admin", "Harbor12345"))
+	assert.Equal(t, basicAuth+1, metrics.Requests(ModifierBasicAuth, metrics.ResultSuccess))
+
+	// nothing is counted when disabled
+	config.Upload(map[string]interface{}{common.AuthMetricsEnabled: false})
+	require.Nil(t, Init())
+	SecurityFilter(newCtx("", ""))
+	assert.Equal(t, unauthorized+1, metrics.Requests(ModifierUnauthorized, metrics.ResultFailure))
+}
+
+type rejectingReqCtxModifier struct{}
+
+func (r *rejectingReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
+	ctx.ResponseWriter.WriteHeader(http.StatusTooManyRequests)
+	return true
+}
+
+type noopReqCtxModifier struct{}
+
+func (n *noopReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
+	return false
+}
+
+func TestSecurityFilterNoSecurityContext(t *testing.T) {
+	modifiers, names := reqCtxModifiers, reqCtxModifierNames
+	defer func() {
+		reqCtxModifiers, reqCtxModifierNames = modifiers, names
+	}()
+
+	newCtx := func() *beegoctx.Context {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		return ctx
+	}
+
+	// no modifier builds the security context
+	for _, chain := range [][]ReqCtxModifier{nil, {&noopReqCtxModifier{}}} {
+		reqCtxModifiers = chain
+		reqCtxModifierNames = make([]string, len(chain))
+		ctx := newCtx()
+		SecurityFilter(ctx)
+		rec := ctx.ResponseWriter.ResponseWriter.(*httptest.ResponseRecorder)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"code":500,"message":"failed to build the security context"}`, rec.Body.String())
+	}
+
+	// the request rejected by the modifier is left as it is
+	reqCtxModifiers = []ReqCtxModifier{&rejectingReqCtxModifier{}}
+	reqCtxModifierNames = []string{"rejecting"}
+	ctx := newCtx()
+	SecurityFilter(ctx)
+	rec := ctx.ResponseWriter.ResponseWriter.(*httptest.ResponseRecorder)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestValidateChain(t *testing.T) {
+	assert.NotNil(t, validateChain(nil))
+	assert.NotNil(t, validateChain([]ReqCtxModifier{&unauthorizedReqCtxModifier{}, &basicAuthReqCtxModifier{}}))
+	assert.Nil(t, validateChain([]ReqCtxModifier{&unauthorizedReqCtxModifier{}}))
+	assert.Nil(t, validateChain([]ReqCtxModifier{&configCtxModifier{}, &basicAuthReqCtxModifier{}, &unauthorizedReqCtxModifier{}}))
+}
+
+func TestBasicAuthReqCtxModifierLockout(t *testing.T) {
+	origin := basicAuthLimiter
+	basicAuthLimiter = NewFailedLoginLimiter(2, time.Minute, time.Minute)
+	defer func() {
+		basicAuthLimiter = origin
+	}()
+
+	newCtx := func(username, password string) (*beegoctx.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.RemoteAddr = "10.10.10.14:12345"
+		req.SetBasicAuth(username, password)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		rec := httptest.NewRecorder()
+		ctx.Reset(rec, req)
+		return ctx, rec
+	}
+	modifier := &basicAuthReqCtxModifier{}
+	// the failed attempts are made by the other user as the user is frozen for a while by auth.Login
+
+	ctx, _ := newCtx("non-existing-user", "invalid-password")
+	assert.False(t, modifier.Modify(ctx))
+	// the successful login resets the counter
+	ctx, _ = newCtx("admin", "Harbor12345")
+	assert.True(t, modifier.Modify(ctx))
+	assert.True(t, basicAuthLimiter.Allowed("10.10.10.14"))
+
+	for i := 0; i < 2; i++ {
+		ctx, _ = newCtx("non-existing-user", "invalid-password")
+		assert.False(t, modifier.Modify(ctx))
+	}
+	// locked out even with the valid credential
+	ctx, rec := newCtx("admin", "Harbor12345")
+	assert.True(t, modifier.Modify(ctx))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Nil(t, securityContext(ctx))
+}
+
+func TestCertSubjectName(t *testing.T) {
+	u, err := url.Parse("spiffe://example.com/ci")
+	require.Nil(t, err)
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "ci"},
+		DNSNames:       []string{"ci.example.com"},
+		EmailAddresses: []string{"ci@example.com"},
+		URIs:           []*url.URL{u},
+	}
+	// CN is the username by default
+	assert.Equal(t, "ci", certSubjectName(cert, map[string]string{}))
+	// CN takes precedence over SAN
+	assert.Equal(t, "user1", certSubjectName(cert, map[string]string{
+		"ci":             "user1",
+		"ci.example.com": "user2",
+	}))
+	assert.Equal(t, "user2", certSubjectName(cert, map[string]string{"ci.example.com": "user2"}))
+	assert.Equal(t, "user3", certSubjectName(cert, map[string]string{"ci@example.com": "user3"}))
+	assert.Equal(t, "robot$ci", certSubjectName(cert, map[string]string{"spiffe://example.com/ci": "robot$ci"}))
+}
+
+func TestCertReqCtxModifier(t *testing.T) {
+	rb, err := robot.RobotCtr.CreateRobotAccount(&robotModel.RobotCreate{
+		Name:      "cert",
+		ProjectID: 1,
+		Access:    []*rbac.Policy{},
+	})
+	require.Nil(t, err)
+	defer robot.RobotCtr.DeleteRobotAccount(rb.ID)
+
+	newCtx := func(state *tls.ConnectionState) *beegoctx.Context {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.TLS = state
+		// the invalid basic auth is ignored as the certificate takes precedence
+		req.SetBasicAuth("admin", "invalid-password")
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		return ctx
+	}
+	verified := func(cn string, dnsNames ...string) *tls.ConnectionState {
+		cert := &x509.Certificate{
+			Subject:  pkix.Name{CommonName: cn},
+			DNSNames: dnsNames,
+		}
+		return &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}
+	}
+	modifier := &certReqCtxModifier{}
+
+	// disabled
+	assert.False(t, modifier.Modify(newCtx(verified("admin"))))
+
+	config.Upload(map[string]interface{}{
+		common.ClientCertAuth:    true,
+		common.ClientCertMapping: `{"ci.example.com":"robot$cert"}`,
+	})
+	defer config.Upload(map[string]interface{}{
+		common.ClientCertAuth:    false,
+		common.ClientCertMapping: "{}",
+	})
+
+	// no TLS
+	assert.False(t, modifier.Modify(newCtx(nil)))
+	// the certificate isn't verified
+	state := verified("admin")
+	state.VerifiedChains = nil
+	assert.False(t, modifier.Modify(newCtx(state)))
+	// the user doesn't exist
+	assert.False(t, modifier.Modify(newCtx(verified("non-existing-user"))))
+
+	// CN is the username
+	ctx := newCtx(verified("admin"))
+	SecurityFilter(ctx)
+	sc := securityContext(ctx)
+	require.IsType(t, &local.SecurityContext{}, sc)
+	assert.Equal(t, "admin", sc.(security.Context).GetUsername())
+
+	// SAN mapped to the robot account
+	ctx = newCtx(verified("ci", "ci.example.com"))
+	assert.True(t, modifier.Modify(ctx))
+	sc = securityContext(ctx)
+	require.IsType(t, &robotCtx.SecurityContext{}, sc)
+	assert.Equal(t, "robot$cert", sc.(security.Context).GetUsername())
+	assert.True(t, sc.(security.Context).Can(rbac.ActionPull, rbac.NewProjectNamespace(1).Resource(rbac.ResourceRepository)))
+	assert.False(t, sc.(security.Context).Can(rbac.ActionPush, rbac.NewProjectNamespace(1).Resource(rbac.ResourceRepository)))
+}
+
+func TestImpersonationReqCtxModifier(t *testing.T) {
+	tk, err := token.NewImpersonation(1, "admin", "impersonator", false, 5*time.Minute)
+	require.Nil(t, err)
+	raw, err := tk.Raw()
+	require.Nil(t, err)
+
+	newCtx := func(method, rawToken string) (*beegoctx.Context, *httptest.ResponseRecorder) {
+		req, err := http.NewRequest(method, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.Header.Set(common.ImpersonationTokenHeader, rawToken)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		rec := httptest.NewRecorder()
+		ctx.Reset(rec, req)
+		return ctx, rec
+	}
+	modifier := &impersonationReqCtxModifier{}
+
+	// the impersonation is disabled by default
+	ctx, _ := newCtx(http.MethodGet, raw)
+	assert.False(t, modifier.Modify(ctx))
+
+	config.Upload(map[string]interface{}{common.AllowImpersonation: true})
+	defer config.Upload(map[string]interface{}{common.AllowImpersonation: false})
+
+	// invalid token
+	ctx, _ = newCtx(http.MethodGet, "invalid-token")
+	assert.False(t, modifier.Modify(ctx))
+
+	// read request
+	ctx, _ = newCtx(http.MethodGet, raw)
+	assert.True(t, modifier.Modify(ctx))
+	sc := securityContext(ctx)
+	require.IsType(t, &impersonation.SecurityContext{}, sc)
+	assert.Equal(t, "admin", sc.(*impersonation.SecurityContext).GetUsername())
+	assert.Equal(t, "impersonator", sc.(*impersonation.SecurityContext).GetImpersonator())
+
+	// the write request is rejected by default
+	ctx, rec := newCtx(http.MethodPost, raw)
+	assert.True(t, modifier.Modify(ctx))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Nil(t, securityContext(ctx))
+
+	// the write request is accepted if the token allows
+	tk, err = token.NewImpersonation(1, "admin", "impersonator", true, 5*time.Minute)
+	require.Nil(t, err)
+	raw, err = tk.Raw()
+	require.Nil(t, err)
+	ctx, rec = newCtx(http.MethodPost, raw)
+	assert.True(t, modifier.Modify(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.IsType(t, &impersonation.SecurityContext{}, securityContext(ctx))
+
+	// the disabled user can not be impersonated
+	id, err := dao.Register(models.User{
+		Username: "impersonatedTester",
+		Email:    "impersonated@test.org",
+		Password: "12345678",
+	})
+	require.Nil(t, err)
+	defer dao.CleanUser(id)
+	require.Nil(t, dao.DisableUsers("admin", []*models.InactiveUser{{UserID: int(id), Username: "impersonatedTester"}}))
+	tk, err = token.NewImpersonation(int(id), "impersonatedTester", "impersonator", false, 5*time.Minute)
+	require.Nil(t, err)
+	raw, err = tk.Raw()
+	require.Nil(t, err)
+	ctx, _ = newCtx(http.MethodGet, raw)
+	assert.False(t, modifier.Modify(ctx))
+	assert.Nil(t, securityContext(ctx))
+}
+
+func TestOverrideReqCtxModifier(t *testing.T) {
+	tk, err := token.NewOverride("admin", "admin", 5*time.Minute)
+	require.Nil(t, err)
+	raw, err := tk.Raw()
+	require.Nil(t, err)
+
+	newCtx := func(rawToken string) *beegoctx.Context {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.Header.Set(common.OverrideUserHeader, rawToken)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		return ctx
+	}
+	buf := &bytes.Buffer{}
+	hlog.SetOutput(buf)
+	defer hlog.SetOutput(os.Stdout)
+	modifier := &overrideReqCtxModifier{}
+
+	// the override is disabled by default
+	assert.False(t, modifier.Modify(newCtx(raw)))
+
+	config.Upload(map[string]interface{}{common.AllowAdminOverride: true})
+	defer config.Upload(map[string]interface{}{common.AllowAdminOverride: false})
+
+	// not in debug mode
+	assert.False(t, modifier.Modify(newCtx(raw)))
+
+	origin := debugMode
+	debugMode = func() bool { return true }
+	defer func() { debugMode = origin }()
+
+	// invalid token
+	assert.False(t, modifier.Modify(newCtx("invalid-token")))
+	assert.NotContains(t, buf.String(), `"is_override":true`)
+
+	// the override token of the impersonation is rejected
+	imp, err := token.NewImpersonation(1, "admin", "impersonator", true, 5*time.Minute)
+	require.Nil(t, err)
+	impRaw, err := imp.Raw()
+	require.Nil(t, err)
+	assert.False(t, modifier.Modify(newCtx(impRaw)))
+
+	// valid token
+	ctx := newCtx(raw)
+	assert.True(t, modifier.Modify(ctx))
+	sc := securityContext(ctx)
+	require.IsType(t, &local.SecurityContext{}, sc)
+	assert.Equal(t, "admin", sc.(*local.SecurityContext).GetUsername())
+	assert.Contains(t, buf.String(), `"is_override":true,"username":"admin","issued_by":"admin","method":"GET","path":"/api/projects/"`)
+}
+
 func TestAuthProxyReqCtxModifier(t *testing.T) {
 
 	server, err := fiter_test.NewAuthProxyTestServer()
@@ -299,6 +951,409 @@ func TestAuthProxyReqCtxModifier(t *testing.T) {
 	assert.True(t, modified)
 }
 
+func TestAuthProxyReqCtxModifierGroups(t *testing.T) {
+	err := dao.OnBoardUser(&models.User{
+		Username: "administrator@vsphere.local",
+	})
+	require.Nil(t, err)
+
+	cases := []struct {
+		name   string
+		groups []string
+	}{
+		{name: "zero", groups: []string{}},
+		{name: "one", groups: []string{"vsphere.local\\admins"}},
+		{name: "many", groups: []string{"vsphere.local\\users", "O'Reilly", "dev, ops", "50% off", "\"quoted\""}},
+	}
+	for _, c := range cases {
+		server, err := fiter_test.NewAuthProxyTestServerWithGroups(c.groups)
+		require.Nil(t, err)
+		config.Upload(map[string]interface{}{
+			common.HTTPAuthProxySkipSearch:          "true",
+			common.HTTPAuthProxyVerifyCert:          "false",
+			common.HTTPAuthProxyEndpoint:            "https://auth.proxy/suffix",
+			common.HTTPAuthProxyTokenReviewEndpoint: server.URL,
+			common.AUTHMode:                         common.HTTPAuth,
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/service/token", nil)
+		require.Nil(t, err)
+		req.SetBasicAuth("tokenreview$administrator@vsphere.local", "reviEwt0k3n")
+		addToReqContext(req, AuthModeKey, common.HTTPAuth)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+
+		modifier := &authProxyReqCtxModifier{}
+		assert.True(t, modifier.Modify(ctx), c.name)
+		server.Close()
+
+		// the groups are onboarded and attached to the user of the security context
+		groupIDs, err := group.GetGroupIDByGroupName(c.groups, common.HTTPGroupType)
+		require.Nil(t, err, c.name)
+		assert.Len(t, groupIDs, len(c.groups), c.name)
+		for _, id := range groupIDs {
+			_, err = project.AddProjectMember(models.Member{
+				ProjectID:  1,
+				EntityID:   id,
+				EntityType: common.GroupMember,
+				Role:       common.RoleDeveloper,
+			})
+			require.Nil(t, err, c.name)
+		}
+		sc, err := GetSecurityContext(ctx.Request)
+		require.Nil(t, err, c.name)
+		require.IsType(t, &local.SecurityContext{}, sc, c.name)
+		roles := sc.(*local.SecurityContext).GetRolesByGroup(int64(1))
+		if len(c.groups) == 0 {
+			assert.Empty(t, roles, c.name)
+		} else {
+			assert.Equal(t, []int{common.RoleDeveloper}, roles, c.name)
+		}
+		for _, id := range groupIDs {
+			group.DeleteUserGroup(id)
+		}
+	}
+	config.Upload(map[string]interface{}{common.AUTHMode: common.DBAuth})
+}
+
+func TestAuthProxyReqCtxModifierAutoOnboard(t *testing.T) {
+	username := "administrator@vsphere.local"
+	u, err := dao.GetUser(models.User{Username: username})
+	require.Nil(t, err)
+	if u != nil {
+		require.Nil(t, dao.CleanUser(int64(u.UserID)))
+	}
+
+	server, err := fiter_test.NewAuthProxyTestServer()
+	require.Nil(t, err)
+	defer server.Close()
+	config.Upload(map[string]interface{}{
+		common.HTTPAuthProxySkipSearch:          "true",
+		common.HTTPAuthProxyVerifyCert:          "false",
+		common.HTTPAuthProxyAutoOnboard:         "true",
+		common.HTTPAuthProxyEndpoint:            "https://auth.proxy/suffix",
+		common.HTTPAuthProxyTokenReviewEndpoint: server.URL,
+		common.AUTHMode:                         common.HTTPAuth,
+	})
+	defer config.Upload(map[string]interface{}{
+		common.HTTPAuthProxyAutoOnboard: "false",
+		common.AUTHMode:                 common.DBAuth,
+	})
+
+	// the concurrent first logins of the same user onboard only one user
+	n := 5
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/service/token", nil)
+			require.Nil(t, err)
+			req.SetBasicAuth("tokenreview$"+username, "reviEwt0k3n")
+			addToReqContext(req, AuthModeKey, common.HTTPAuth)
+			ctx, err := newContext(req)
+			require.Nil(t, err)
+			modifier := &authProxyReqCtxModifier{}
+			assert.True(t, modifier.Modify(ctx))
+			sc, err := GetSecurityContext(ctx.Request)
+			require.Nil(t, err)
+			require.IsType(t, &local.SecurityContext{}, sc)
+			assert.Equal(t, username, sc.GetUsername())
+		}()
+	}
+	wg.Wait()
+
+	u, err = dao.GetUser(models.User{Username: username})
+	require.Nil(t, err)
+	require.NotNil(t, u)
+	assert.Equal(t, username, u.Email)
+	assert.Equal(t, username, u.Realname)
+}
+
+func TestAuthProxyReqCtxModifierAPIAccess(t *testing.T) {
+	require.Nil(t, dao.OnBoardUser(&models.User{Username: "administrator@vsphere.local"}))
+	server, err := fiter_test.NewAuthProxyTestServer()
+	require.Nil(t, err)
+	defer server.Close()
+
+	var calls int32
+	origin := tokenReviewer
+	tokenReviewer = newTokenReviewCache(func(token string, setting *models.HTTPAuthProxy) (*k8s_api_v1beta1.TokenReview, error) {
+		atomic.AddInt32(&calls, 1)
+		return authproxy.TokenReview(token, setting)
+	}, time.Minute, 10)
+	defer func() {
+		tokenReviewer = origin
+	}()
+
+	upload := func(apiAccess bool) {
+		config.Upload(map[string]interface{}{
+			common.HTTPAuthProxySkipSearch:          "true",
+			common.HTTPAuthProxyVerifyCert:          "false",
+			common.HTTPAuthProxyAPIAccess:           apiAccess,
+			common.HTTPAuthProxyEndpoint:            "https://auth.proxy/suffix",
+			common.HTTPAuthProxyTokenReviewEndpoint: server.URL,
+			common.AUTHMode:                         common.HTTPAuth,
+		})
+	}
+	defer config.Upload(map[string]interface{}{
+		common.HTTPAuthProxyAPIAccess: false,
+		common.AUTHMode:               common.DBAuth,
+	})
+	modify := func(method, path, username string) bool {
+		req, err := http.NewRequest(method, "http://127.0.0.1"+path, nil)
+		require.Nil(t, err)
+		req.SetBasicAuth(username, "reviEwt0k3n")
+		addToReqContext(req, AuthModeKey, common.HTTPAuth)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		modifier := &authProxyReqCtxModifier{}
+		return modifier.Modify(ctx)
+	}
+
+	// only the docker login is handled when the API access is disabled
+	upload(false)
+	assert.False(t, modify(http.MethodGet, "/api/projects", "tokenreview$administrator@vsphere.local"))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	assert.True(t, modify(http.MethodGet, "/service/token", "tokenreview$administrator@vsphere.local"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// the result of the docker login is reused by the API calls
+	upload(true)
+	assert.True(t, modify(http.MethodGet, "/api/projects", "tokenreview$administrator@vsphere.local"))
+	assert.True(t, modify(http.MethodPost, "/api/projects", "tokenreview$administrator@vsphere.local"))
+	assert.True(t, modify(http.MethodGet, "/api/repositories", "tokenreview$administrator@vsphere.local"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// the requests of the other users are left to the basic auth modifier
+	assert.False(t, modify(http.MethodPost, "/api/projects", "admin"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// the failed reviews fall through without being cached
+	tokenReviewer = newTokenReviewCache(func(token string, setting *models.HTTPAuthProxy) (*k8s_api_v1beta1.TokenReview, error) {
+		atomic.AddInt32(&calls, 1)
+		return &k8s_api_v1beta1.TokenReview{}, nil
+	}, time.Minute, 10)
+	assert.False(t, modify(http.MethodGet, "/api/projects", "tokenreview$administrator@vsphere.local"))
+	assert.False(t, modify(http.MethodGet, "/api/projects", "tokenreview$administrator@vsphere.local"))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestUnauthorizedReqCtxModifierAnonymousAccess(t *testing.T) {
+	config.Upload(map[string]interface{}{
+		common.AnonymousAccessCIDRs: "10.0.0.0/8, 2001:db8::/32",
+		common.TrustedProxies:       "192.168.0.1",
+	})
+	defer config.Upload(map[string]interface{}{
+		common.AnonymousAccessCIDRs: "",
+		common.TrustedProxies:       "",
+	})
+
+	cases := []struct {
+		name       string
+		path       string
+		remoteAddr string
+		xff        string
+		allowed    bool
+		challenge  string
+	}{
+		{name: "ipv4 in range", path: "/api/projects", remoteAddr: "10.1.2.3:1234", allowed: true},
+		{name: "ipv4 out of range", path: "/api/projects", remoteAddr: "11.1.2.3:1234", challenge: `Basic realm="harbor"`},
+		{name: "ipv6 in range", path: "/api/projects", remoteAddr: "[2001:db8::1]:1234", allowed: true},
+		{name: "ipv6 out of range", path: "/api/projects", remoteAddr: "[2001:db9::1]:1234", challenge: `Basic realm="harbor"`},
+		{name: "registry API", path: "/v2/library/hello-world/manifests/latest", remoteAddr: "11.1.2.3:1234",
+			challenge: `Bearer realm="` + strings.TrimSuffix(extEndpoint(t), "/") + `/service/token",service="harbor-registry"`},
+		{name: "trusted proxy", path: "/api/projects", remoteAddr: "192.168.0.1:1234", xff: "10.1.2.3", allowed: true},
+		{name: "trusted proxy chain", path: "/api/projects", remoteAddr: "192.168.0.1:1234", xff: "10.1.2.3, 11.1.2.3", challenge: `Basic realm="harbor"`},
+		{name: "spoofed by untrusted peer", path: "/api/projects", remoteAddr: "11.1.2.3:1234", xff: "10.1.2.3", challenge: `Basic realm="harbor"`},
+		{name: "login", path: "/c/login", remoteAddr: "11.1.2.3:1234", allowed: true},
+		{name: "system info", path: "/api/systeminfo", remoteAddr: "11.1.2.3:1234", allowed: true},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1"+c.path, nil)
+		require.Nil(t, err)
+		req.RemoteAddr = c.remoteAddr
+		if len(c.xff) > 0 {
+			req.Header.Set("X-Forwarded-For", c.xff)
+		}
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		modifier := &unauthorizedReqCtxModifier{}
+		assert.True(t, modifier.Modify(ctx), c.name)
+		if c.allowed {
+			assert.IsType(t, &local.SecurityContext{}, securityContext(ctx), c.name)
+			assert.Equal(t, 0, ctx.ResponseWriter.Status, c.name)
+			continue
+		}
+		assert.Nil(t, securityContext(ctx), c.name)
+		assert.Equal(t, http.StatusUnauthorized, ctx.ResponseWriter.Status, c.name)
+		assert.Equal(t, c.challenge, ctx.ResponseWriter.Header().Get("WWW-Authenticate"), c.name)
+	}
+}
+
+func extEndpoint(t *testing.T) string {
+	endpoint, err := config.ExtEndpoint()
+	require.Nil(t, err)
+	return endpoint
+}
+
+func TestAPIKeyReqCtxModifier(t *testing.T) {
+	valid, err := dao.AddAPIKey(&models.APIKey{UserID: 1, Name: "valid", KeyHash: dao.HashAPIKey("valid-api-key")})
+	require.Nil(t, err)
+	defer dao.DeleteAPIKey(valid)
+	expired, err := dao.AddAPIKey(&models.APIKey{UserID: 1, Name: "expired", KeyHash: dao.HashAPIKey("expired-api-key"),
+		ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	require.Nil(t, err)
+	defer dao.DeleteAPIKey(expired)
+	revoked, err := dao.AddAPIKey(&models.APIKey{UserID: 1, Name: "revoked", KeyHash: dao.HashAPIKey("revoked-api-key")})
+	require.Nil(t, err)
+	require.Nil(t, dao.DeleteAPIKey(revoked))
+
+	cases := []struct {
+		name     string
+		header   http.Header
+		modified bool
+		errMsg   string
+	}{
+		{name: "no key", header: http.Header{}},
+		{name: "other scheme", header: http.Header{"Authorization": []string{"Bearer valid-api-key"}}},
+		{name: "header", header: http.Header{common.APIKeyHeader: []string{"valid-api-key"}}, modified: true},
+		{name: "authorization", header: http.Header{"Authorization": []string{"apikey valid-api-key"}}, modified: true},
+		{name: "invalid", header: http.Header{common.APIKeyHeader: []string{"invalid-api-key"}}},
+		{name: "expired", header: http.Header{common.APIKeyHeader: []string{"expired-api-key"}}, errMsg: "api key expired"},
+		{name: "revoked", header: http.Header{common.APIKeyHeader: []string{"revoked-api-key"}}},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.Header = c.header
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		modifier := &apiKeyReqCtxModifier{}
+		assert.Equal(t, c.modified, modifier.Modify(ctx), c.name)
+		assert.Equal(t, c.errMsg, ctx.ResponseWriter.Header().Get(common.HarborErrorHeader), c.name)
+		if !c.modified {
+			assert.Nil(t, securityContext(ctx), c.name)
+			continue
+		}
+		sc := securityContext(ctx)
+		require.IsType(t, &local.SecurityContext{}, sc, c.name)
+		assert.Equal(t, "admin", sc.(security.Context).GetUsername(), c.name)
+		assert.NotNil(t, projectManager(ctx), c.name)
+	}
+}
+
+func TestActiveUser(t *testing.T) {
+	assert.True(t, activeUser(&models.User{Username: "active"}))
+	assert.False(t, activeUser(&models.User{Username: "disabled", Disabled: true}))
+}
+
+func TestDisabledUserRejected(t *testing.T) {
+	id, err := dao.Register(models.User{
+		Username: "disabledUserTester",
+		Email:    "disabled@test.org",
+		Password: "12345678",
+	})
+	require.Nil(t, err)
+	defer dao.CleanUser(id)
+	keyID, err := dao.AddAPIKey(&models.APIKey{UserID: int(id), Name: "disabled", KeyHash: dao.HashAPIKey("disabled-api-key")})
+	require.Nil(t, err)
+	defer dao.DeleteAPIKey(keyID)
+
+	sessionCtx := func() *beegoctx.Context {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		store, err := beego.GlobalSessions.SessionStart(httptest.NewRecorder(), req)
+		require.Nil(t, err)
+		require.Nil(t, store.Set("user", models.User{UserID: int(id), Username: "disabledUserTester"}))
+		addSessionIDToCookie(req, store.SessionID())
+		addToReqContext(req, AuthModeKey, common.DBAuth)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		return ctx
+	}
+	apiKeyCtx := func() *beegoctx.Context {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		req.Header.Set(common.APIKeyHeader, "disabled-api-key")
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		return ctx
+	}
+
+	assert.True(t, (&sessionReqCtxModifier{}).Modify(sessionCtx()))
+	assert.True(t, (&apiKeyReqCtxModifier{}).Modify(apiKeyCtx()))
+
+	require.Nil(t, dao.DisableUsers("admin", []*models.InactiveUser{{UserID: int(id), Username: "disabledUserTester"}}))
+	ctx := sessionCtx()
+	assert.False(t, (&sessionReqCtxModifier{}).Modify(ctx))
+	assert.Nil(t, securityContext(ctx))
+	ctx = apiKeyCtx()
+	assert.False(t, (&apiKeyReqCtxModifier{}).Modify(ctx))
+	assert.Nil(t, securityContext(ctx))
+}
+
+func TestAPIKeyReqCtxModifierLastLogin(t *testing.T) {
+	id, err := dao.Register(models.User{
+		Username: "apiKeyLoginTester",
+		Email:    "apikeylogin@test.org",
+		Password: "12345678",
+	})
+	require.Nil(t, err)
+	defer dao.CleanUser(id)
+	keyID, err := dao.AddAPIKey(&models.APIKey{UserID: int(id), Name: "login", KeyHash: dao.HashAPIKey("login-api-key")})
+	require.Nil(t, err)
+	defer dao.DeleteAPIKey(keyID)
+
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+	require.Nil(t, err)
+	req.Header.Set(common.APIKeyHeader, "login-api-key")
+	ctx, err := newContext(req)
+	require.Nil(t, err)
+	require.True(t, (&apiKeyReqCtxModifier{}).Modify(ctx))
+
+	// the user accessing via the API key isn't inactive
+	var lastLogin time.Time
+	require.Nil(t, dao.GetOrmer().Raw(`select last_login_at from harbor_user where user_id = ?`, id).QueryRow(&lastLogin))
+	assert.WithinDuration(t, time.Now(), lastLogin, time.Minute)
+}
+
+func TestMatchBasicAuthReqPatterns(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		match  bool
+	}{
+		{http.MethodPost, "/api/projects", true},
+		{http.MethodGet, "/api/projects", false},
+		{http.MethodPost, "/api/projectsfoo", false},
+		{http.MethodPost, "/api/projects/1", false},
+		{http.MethodPost, "/prefix/api/projects", false},
+		{http.MethodGet, "/service/token", true},
+		{http.MethodPost, "/service/token", false},
+		{http.MethodGet, "/service/tokens", false},
+		{http.MethodDelete, "/api/repositories/library/hello-world", true},
+		{http.MethodDelete, "/api/repositories/library/sub/hello-world", true},
+		{http.MethodDelete, "/api/repositories/localhost:5000/library/hello-world", true},
+		{http.MethodDelete, "/api/repositories/hello-world", true},
+		{http.MethodGet, "/api/repositories/library/hello-world", false},
+		{http.MethodDelete, "/api/repositories/library/Hello-World", false},
+		{http.MethodDelete, "/api/repositories/library//hello-world", false},
+		{http.MethodDelete, "/api/repositories/", false},
+		{http.MethodDelete, "/api/repositories/library/hello-world/", false},
+		{http.MethodDelete, "/api/repositories/library/hello-world/tags/latest", true},
+		{http.MethodDelete, "/api/repositories/localhost:5000/library/hello-world/tags/v1.0_rc-1", true},
+		{http.MethodGet, "/api/repositories/library/hello-world/tags/latest", false},
+		{http.MethodDelete, "/api/repositories/library/hello-world/tags/-latest", false},
+		{http.MethodDelete, "/api/repositories/library/hello-world/tags/", false},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest(c.method, "http://127.0.0.1"+c.path, nil)
+		require.Nil(t, err)
+		assert.Equal(t, c.match, matchBasicAuthReqPatterns(req), "%s %s", c.method, c.path)
+	}
+}
+
 func TestBasicAuthReqCtxModifier(t *testing.T) {
 	req, err := http.NewRequest(http.MethodGet,
 		"http://127.0.0.1/api/projects/", nil)
@@ -323,6 +1378,84 @@ func TestBasicAuthReqCtxModifier(t *testing.T) {
 	assert.NotNil(t, projectManager(ctx))
 }
 
+func TestBasicAuthReqCtxModifierAPIDisabled(t *testing.T) {
+	config.Upload(map[string]interface{}{
+		common.DisableBasicAuthAPI: true,
+	})
+	defer config.Upload(map[string]interface{}{
+		common.DisableBasicAuthAPI: false,
+	})
+
+	modifier := &basicAuthReqCtxModifier{}
+	newCtx := func(path string) *beegoctx.Context {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1"+path, nil)
+		require.Nil(t, err)
+		req.SetBasicAuth("admin", "Harbor12345")
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		return ctx
+	}
+
+	// the token service and registry still honor the basic auth
+	for _, path := range []string{"/service/token", "/v2/library/hello-world/manifests/latest"} {
+		ctx := newCtx(path)
+		assert.True(t, modifier.Modify(ctx), path)
+		sc := securityContext(ctx)
+		require.NotNil(t, sc, path)
+		assert.Equal(t, "admin", sc.(security.Context).GetUsername(), path)
+	}
+
+	// the API refuses the basic auth
+	ctx := newCtx("/api/projects")
+	assert.True(t, modifier.Modify(ctx))
+	assert.Nil(t, securityContext(ctx))
+	rec := ctx.ResponseWriter.ResponseWriter.(*httptest.ResponseRecorder)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `Basic realm="harbor"`, rec.Header().Get("WWW-Authenticate"))
+	assert.Contains(t, rec.Body.String(), "robot accounts")
+}
+
+func TestMarkLDAPGroupAdmin(t *testing.T) {
+	config.Upload(map[string]interface{}{
+		common.LDAPGroupAdminDn: "cn=harbor_admins,ou=groups,dc=example,dc=com",
+	})
+	defer config.Upload(map[string]interface{}{
+		common.LDAPGroupAdminDn: "",
+	})
+
+	cases := []struct {
+		name     string
+		groupDNs []string
+		admin    bool
+		expected bool
+	}{
+		{name: "no group", expected: false},
+		{name: "not a member", groupDNs: []string{"cn=harbor_users,ou=groups,dc=example,dc=com"}, expected: false},
+		{name: "direct member", groupDNs: []string{"cn=harbor_admins,ou=groups,dc=example,dc=com"}, expected: true},
+		{name: "nested member", groupDNs: []string{
+			"cn=ops,ou=groups,dc=example,dc=com",
+			"CN=Harbor_Admins, OU=Groups, DC=example, DC=com",
+		}, expected: true},
+		{name: "member of the child group only", groupDNs: []string{
+			"cn=ops,cn=harbor_admins,ou=groups,dc=example,dc=com",
+		}, expected: false},
+		{name: "admin already", admin: true, expected: true},
+	}
+	for _, c := range cases {
+		user := &models.User{Username: "user01", HasAdminRole: c.admin, LDAPGroupDNs: c.groupDNs}
+		markLDAPGroupAdmin(user)
+		assert.Equal(t, c.expected, user.HasAdminRole, c.name)
+	}
+
+	// no admin group configured
+	config.Upload(map[string]interface{}{
+		common.LDAPGroupAdminDn: "",
+	})
+	user := &models.User{Username: "user01", LDAPGroupDNs: []string{"cn=harbor_admins,ou=groups,dc=example,dc=com"}}
+	markLDAPGroupAdmin(user)
+	assert.False(t, user.HasAdminRole)
+}
+
 func TestSessionReqCtxModifier(t *testing.T) {
 	user := models.User{
 		Username:     "admin",
@@ -396,6 +1529,45 @@ func TestSessionReqCtxModifierFailed(t *testing.T) {
 
 }
 
+func TestSAMLReqCtxModifier(t *testing.T) {
+	user := models.User{
+		Username: "user01",
+		UserID:   2,
+	}
+	newCtx := func(authMode string, expiry int64) (*beegoctx.Context, session.Store) {
+		req, err := http.NewRequest(http.MethodGet,
+			"http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		store, err := beego.GlobalSessions.SessionStart(httptest.NewRecorder(), req)
+		require.Nil(t, err)
+		require.Nil(t, store.Set("user", user))
+		require.Nil(t, store.Set(saml.SessionExpiryKey, expiry))
+		addSessionIDToCookie(req, store.SessionID())
+		addToReqContext(req, AuthModeKey, authMode)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		return ctx, store
+	}
+	modifier := &samlReqCtxModifier{}
+
+	// not SAML auth mode
+	ctx, store := newCtx(common.DBAuth, time.Now().Add(-time.Hour).Unix())
+	assert.False(t, modifier.Modify(ctx))
+	assert.NotNil(t, store.Get("user"))
+
+	// the session is still valid
+	ctx, store = newCtx(common.SAMLAuth, time.Now().Add(time.Hour).Unix())
+	assert.False(t, modifier.Modify(ctx))
+	assert.NotNil(t, store.Get("user"))
+
+	// the session has expired
+	ctx, store = newCtx(common.SAMLAuth, time.Now().Add(-time.Hour).Unix())
+	assert.False(t, modifier.Modify(ctx))
+	assert.Nil(t, store.Get("user"))
+	assert.Nil(t, store.Get(saml.SessionExpiryKey))
+	assert.False(t, (&sessionReqCtxModifier{}).Modify(ctx))
+}
+
 // TODO add test case
 func TestTokenReqCtxModifier(t *testing.T) {
 
@@ -525,3 +1697,50 @@ func TestGetProjectManager(t *testing.T) {
 	_, ok := pm.(promgr.ProjectManager)
 	assert.True(t, ok)
 }
+
+func TestSecurityContextFrom(t *testing.T) {
+	// nil context
+	_, ok := SecurityContextFrom(nil)
+	assert.False(t, ok)
+
+	// missing key
+	_, ok = SecurityContextFrom(context.Background())
+	assert.False(t, ok)
+
+	// wrong type
+	_, ok = SecurityContextFrom(context.WithValue(context.Background(), SecurCtxKey, "test"))
+	assert.False(t, ok)
+
+	sc := local.NewSecurityContext(nil, nil)
+	c, ok := SecurityContextFrom(context.WithValue(context.Background(), SecurCtxKey, sc))
+	assert.True(t, ok)
+	assert.Equal(t, sc, c)
+}
+
+func TestProjectManagerFrom(t *testing.T) {
+	// nil context
+	_, ok := ProjectManagerFrom(nil)
+	assert.False(t, ok)
+
+	// missing key
+	_, ok = ProjectManagerFrom(context.Background())
+	assert.False(t, ok)
+
+	// wrong type
+	_, ok = ProjectManagerFrom(context.WithValue(context.Background(), PmKey, "test"))
+	assert.False(t, ok)
+
+	pm := promgr.NewDefaultProjectManager(driver_local.NewDriver(), true)
+	p, ok := ProjectManagerFrom(context.WithValue(context.Background(), PmKey, pm))
+	assert.True(t, ok)
+	assert.Equal(t, pm, p)
+}
+
+func TestMustSecurityContext(t *testing.T) {
+	assert.PanicsWithValue(t, "no security context found in the context, the request isn't handled by the security filter",
+		func() { MustSecurityContext(context.Background()) })
+	assert.Panics(t, func() { MustSecurityContext(context.WithValue(context.Background(), SecurCtxKey, "test")) })
+
+	sc := local.NewSecurityContext(nil, nil)
+	assert.Equal(t, sc, MustSecurityContext(context.WithValue(context.Background(), SecurCtxKey, sc)))
+}
@@ -0,0 +1,49 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"time"
+
+	"github.com/astaxie/beego"
+	beegoctx "github.com/astaxie/beego/context"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// SessionLoginTimeKey is the key of the session value which records when the user logged in
+// with the session, it's set along with the user once the session is promoted to an authenticated one
+const SessionLoginTimeKey = "login_time"
+
+// RegenerateSession generates a new ID for the session of the request and invalidates the old one,
+// the data stored in the session is kept
+func RegenerateSession(ctx *beegoctx.Context) {
+	if ctx.Input.CruSession != nil {
+		ctx.Input.CruSession.SessionRelease(ctx.ResponseWriter)
+	}
+	ctx.Input.CruSession = beego.GlobalSessions.SessionRegenerateID(ctx.ResponseWriter, ctx.Request)
+}
+
+// isStaleSession checks whether the session wasn't populated after the last change of the user,
+// the update time of the user is bumped by the password changes. The sessions populated before
+// the login time is recorded are stale too
+func isStaleSession(loginTime int64, user *models.User) bool {
+	return loginTime <= user.UpdateTime.Unix()
+}
+
+// refreshSession regenerates the ID of the stale session and records the new login time
+func refreshSession(ctx *beegoctx.Context) error {
+	RegenerateSession(ctx)
+	return ctx.Input.CruSession.Set(SessionLoginTimeKey, time.Now().Unix())
+}
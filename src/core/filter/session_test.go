@@ -0,0 +1,90 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsStaleSession(t *testing.T) {
+	now := time.Now()
+	user := &models.User{UpdateTime: now}
+	assert.True(t, isStaleSession(0, user))
+	assert.True(t, isStaleSession(now.Add(-time.Minute).Unix(), user))
+	assert.False(t, isStaleSession(now.Add(time.Minute).Unix(), user))
+}
+
+func TestSessionReqCtxModifierFixation(t *testing.T) {
+	user := models.User{
+		Username: "admin",
+		UserID:   1,
+	}
+	// the attacker plants the session, which is promoted by the victim afterwards
+	newCtx := func(loginTime interface{}) (*httptest.ResponseRecorder, string) {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/", nil)
+		require.Nil(t, err)
+		store, err := beego.GlobalSessions.SessionStart(httptest.NewRecorder(), req)
+		require.Nil(t, err)
+		require.Nil(t, store.Set("user", user))
+		if loginTime != nil {
+			require.Nil(t, store.Set(SessionLoginTimeKey, loginTime))
+		}
+		sid := store.SessionID()
+		addSessionIDToCookie(req, sid)
+		addToReqContext(req, AuthModeKey, common.DBAuth)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+
+		assert.True(t, (&sessionReqCtxModifier{}).Modify(ctx))
+		assert.Equal(t, "admin", securityContext(ctx).(security.Context).GetUsername())
+		ctx.Input.CruSession.SessionRelease(ctx.ResponseWriter)
+		return ctx.ResponseWriter.ResponseWriter.(*httptest.ResponseRecorder), sid
+	}
+	assertRegenerated := func(rec *httptest.ResponseRecorder, sid string) {
+		cookie := rec.Header().Get("Set-Cookie")
+		require.NotEmpty(t, cookie)
+		assert.False(t, strings.Contains(cookie, sid))
+		// the old session is invalidated
+		store, err := beego.GlobalSessions.GetSessionStore(sid)
+		require.Nil(t, err)
+		assert.Nil(t, store.Get("user"))
+	}
+
+	// the session populated before the login time is recorded
+	rec, sid := newCtx(nil)
+	assertRegenerated(rec, sid)
+
+	// the session populated before the last password change
+	rec, sid = newCtx(time.Now().Add(-24 * time.Hour).Unix())
+	assertRegenerated(rec, sid)
+
+	// the session populated after the last password change
+	rec, sid = newCtx(time.Now().Add(time.Hour).Unix())
+	assert.Empty(t, rec.Header().Get("Set-Cookie"))
+	store, err := beego.GlobalSessions.GetSessionStore(sid)
+	require.Nil(t, err)
+	assert.NotNil(t, store.Get("user"))
+}
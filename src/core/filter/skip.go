@@ -0,0 +1,52 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+var (
+	// skippablePaths are the paths which require no authorization and whose handlers never read the
+	// security context, only they can be skipped by the security filter
+	skippablePaths = map[string]bool{
+		"/api/ping":   true,
+		"/api/health": true,
+		"/metrics":    true,
+	}
+	// the paths skipped by the security filter, it's set by Init
+	skipPaths = map[string]bool{}
+)
+
+// Skipped checks whether the request is skipped by the security filter, so there is neither
+// security context nor project manager in the context of the request
+func Skipped(req *http.Request) bool {
+	return req != nil && skipPaths[req.URL.Path]
+}
+
+// skipPathsOf returns the configured paths which can be skipped, the paths are compared exactly
+func skipPathsOf(configured []string) map[string]bool {
+	paths := map[string]bool{}
+	for _, path := range configured {
+		if !skippablePaths[path] {
+			log.Warningf("the path %s can't be skipped by the security filter, ignored", path)
+			continue
+		}
+		paths[path] = true
+	}
+	return paths
+}
@@ -0,0 +1,119 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	beegoctx "github.com/astaxie/beego/context"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipPathsOf(t *testing.T) {
+	paths := skipPathsOf([]string{"/api/ping", "/api/health", "/metrics"})
+	assert.Equal(t, map[string]bool{"/api/ping": true, "/api/health": true, "/metrics": true}, paths)
+
+	// the paths requiring authorization or not matched exactly are ignored
+	paths = skipPathsOf([]string{"/api/ping", "/api/projects", "/api/ping/", "/API/PING", "/api/.*"})
+	assert.Equal(t, map[string]bool{"/api/ping": true}, paths)
+
+	assert.Empty(t, skipPathsOf(nil))
+}
+
+func TestSkipped(t *testing.T) {
+	origin := skipPaths
+	skipPaths = skipPathsOf([]string{"/api/ping"})
+	defer func() {
+		skipPaths = origin
+	}()
+
+	assert.False(t, Skipped(nil))
+	for path, skipped := range map[string]bool{
+		"/api/ping":        true,
+		"/api/ping/":       false,
+		"/api/ping2":       false,
+		"/api/health":      false,
+		"/api/projects":    false,
+		"/api/ping?x=/abc": true,
+	} {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1"+path, nil)
+		require.Nil(t, err)
+		assert.Equal(t, skipped, Skipped(req), path)
+	}
+}
+
+func TestSecurityFilterSkipped(t *testing.T) {
+	origin := skipPaths
+	skipPaths = skipPathsOf([]string{"/api/ping", "/api/health"})
+	defer func() {
+		skipPaths = origin
+	}()
+
+	for path, skipped := range map[string]bool{
+		"/api/ping":     true,
+		"/api/health":   true,
+		"/api/ping/":    false,
+		"/api/projects": false,
+	} {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1"+path, nil)
+		require.Nil(t, err)
+		req.SetBasicAuth("admin", "Harbor12345")
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		SecurityFilter(ctx)
+		if skipped {
+			assert.Nil(t, securityContext(ctx), path)
+			assert.Nil(t, projectManager(ctx), path)
+			assert.Empty(t, GetRequestID(ctx.Request), path)
+			continue
+		}
+		assert.NotNil(t, securityContext(ctx), path)
+		assert.NotNil(t, projectManager(ctx), path)
+	}
+}
+
+func BenchmarkSecurityFilterPing(b *testing.B) {
+	origin := skipPaths
+	defer func() {
+		skipPaths = origin
+	}()
+	originLogger := auditLogger
+	auditLogger = log.New(ioutil.Discard, log.NewTextFormatter(), log.InfoLevel)
+	defer func() {
+		auditLogger = originLogger
+	}()
+	run := func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/ping", nil)
+			ctx := beegoctx.NewContext()
+			ctx.Reset(httptest.NewRecorder(), req)
+			SecurityFilter(ctx)
+		}
+	}
+	b.Run("skipped", func(b *testing.B) {
+		skipPaths = skipPathsOf([]string{"/api/ping"})
+		run(b)
+	})
+	b.Run("not skipped", func(b *testing.B) {
+		skipPaths = map[string]bool{}
+		run(b)
+	})
+}
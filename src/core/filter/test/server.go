@@ -12,6 +12,12 @@ import (
 
 // NewAuthProxyTestServer mocks a https server for auth proxy.
 func NewAuthProxyTestServer() (*httptest.Server, error) {
+	return NewAuthProxyTestServerWithGroups(nil)
+}
+
+// NewAuthProxyTestServerWithGroups mocks a https server for auth proxy which returns the groups
+// in the TokenReview response, the groups in the request are returned if it's nil.
+func NewAuthProxyTestServerWithGroups(groups []string) (*httptest.Server, error) {
 	const webhookPath = "/authproxy/tokenreview"
 
 	serveHTTP := func(w http.ResponseWriter, r *http.Request) {
@@ -74,6 +80,9 @@ func NewAuthProxyTestServer() (*httptest.Server, error) {
 				review.Status.Audiences,
 			},
 		}
+		if groups != nil {
+			resp.Status.User.Groups = groups
+		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(resp)
 	}
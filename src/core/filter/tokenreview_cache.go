@@ -0,0 +1,124 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/pkg/authproxy"
+	k8s_api_v1beta1 "k8s.io/api/authentication/v1beta1"
+)
+
+const (
+	// the TTL of the cached TokenReview results
+	tokenReviewCacheTTL = 30 * time.Second
+	// the max number of TokenReview results cached
+	tokenReviewCacheMaxEntries = 10000
+)
+
+// tokenReviewer reviews the tokens of the auth proxy users, the results are cached so that the
+// proxy isn't hit by every API call
+var tokenReviewer = newTokenReviewCache(authproxy.TokenReview, tokenReviewCacheTTL, tokenReviewCacheMaxEntries)
+
+type reviewFunc func(token string, setting *models.HTTPAuthProxy) (*k8s_api_v1beta1.TokenReview, error)
+
+// tokenReviewCache caches the successful TokenReview results keyed by the hash of the token and
+// the TokenReview endpoint, the failed ones are not cached
+type tokenReviewCache struct {
+	review     reviewFunc
+	ttl        time.Duration
+	maxEntries int
+	lock       sync.Mutex
+	entries    map[string]*tokenReviewEntry
+}
+
+type tokenReviewEntry struct {
+	result    *k8s_api_v1beta1.TokenReview
+	expiresAt time.Time
+}
+
+func newTokenReviewCache(review reviewFunc, ttl time.Duration, maxEntries int) *tokenReviewCache {
+	return &tokenReviewCache{
+		review:     review,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[string]*tokenReviewEntry{},
+	}
+}
+
+// Review returns the cached result of the token if it isn't expired, otherwise the token is
+// reviewed by the auth proxy
+func (t *tokenReviewCache) Review(token string, setting *models.HTTPAuthProxy) (*k8s_api_v1beta1.TokenReview, error) {
+	key := tokenReviewKey(token, setting.TokenReviewEndpoint)
+	if result := t.get(key); result != nil {
+		return result, nil
+	}
+	result, err := t.review(token, setting)
+	if err != nil {
+		return nil, err
+	}
+	if result.Status.Authenticated {
+		t.put(key, result)
+	}
+	return result, nil
+}
+
+func (t *tokenReviewCache) get(key string) *k8s_api_v1beta1.TokenReview {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	e, ok := t.entries[key]
+	if !ok {
+		return nil
+	}
+	if !time.Now().Before(e.expiresAt) {
+		delete(t.entries, key)
+		return nil
+	}
+	return e.result
+}
+
+func (t *tokenReviewCache) put(key string, result *k8s_api_v1beta1.TokenReview) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := time.Now()
+	if _, ok := t.entries[key]; !ok && len(t.entries) >= t.maxEntries {
+		for k, e := range t.entries {
+			if !now.Before(e.expiresAt) {
+				delete(t.entries, k)
+			}
+		}
+		// all the entries are still valid, drop an arbitrary one to make room
+		for k := range t.entries {
+			if len(t.entries) < t.maxEntries {
+				break
+			}
+			delete(t.entries, k)
+		}
+	}
+	t.entries[key] = &tokenReviewEntry{
+		result:    result,
+		expiresAt: now.Add(t.ttl),
+	}
+}
+
+// tokenReviewKey hashes the token so that the raw token isn't kept in the memory
+func tokenReviewKey(token, endpoint string) string {
+	sum := sha256.Sum256([]byte(endpoint + "\n" + token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8s_api_v1beta1 "k8s.io/api/authentication/v1beta1"
+)
+
+type fakeReviewer struct {
+	calls         int32
+	authenticated bool
+	err           error
+}
+
+func (f *fakeReviewer) review(token string, setting *models.HTTPAuthProxy) (*k8s_api_v1beta1.TokenReview, error) {
+	atomic.AddInt32(&f.calls, 1)
+	if f.err != nil {
+		return nil, f.err
+	}
+	result := &k8s_api_v1beta1.TokenReview{}
+	result.Status.Authenticated = f.authenticated
+	result.Status.User.Username = token
+	return result, nil
+}
+
+func TestTokenReviewCacheCached(t *testing.T) {
+	f := &fakeReviewer{authenticated: true}
+	c := newTokenReviewCache(f.review, time.Minute, 10)
+	setting := &models.HTTPAuthProxy{TokenReviewEndpoint: "https://auth.proxy/review"}
+
+	for i := 0; i < 3; i++ {
+		result, err := c.Review("token1", setting)
+		require.Nil(t, err)
+		assert.True(t, result.Status.Authenticated)
+		assert.Equal(t, "token1", result.Status.User.Username)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&f.calls))
+
+	// the tokens and the endpoints are cached separately
+	_, err := c.Review("token2", setting)
+	require.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&f.calls))
+	_, err = c.Review("token1", &models.HTTPAuthProxy{TokenReviewEndpoint: "https://another.proxy/review"})
+	require.Nil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&f.calls))
+}
+
+func TestTokenReviewCacheUncached(t *testing.T) {
+	setting := &models.HTTPAuthProxy{TokenReviewEndpoint: "https://auth.proxy/review"}
+
+	// the unauthenticated results are not cached
+	f := &fakeReviewer{authenticated: false}
+	c := newTokenReviewCache(f.review, time.Minute, 10)
+	for i := 0; i < 2; i++ {
+		result, err := c.Review("token", setting)
+		require.Nil(t, err)
+		assert.False(t, result.Status.Authenticated)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&f.calls))
+
+	// neither are the errors
+	f = &fakeReviewer{err: errors.New("unreachable")}
+	c = newTokenReviewCache(f.review, time.Minute, 10)
+	for i := 0; i < 2; i++ {
+		_, err := c.Review("token", setting)
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&f.calls))
+}
+
+func TestTokenReviewCacheExpiry(t *testing.T) {
+	f := &fakeReviewer{authenticated: true}
+	c := newTokenReviewCache(f.review, 50*time.Millisecond, 10)
+	setting := &models.HTTPAuthProxy{TokenReviewEndpoint: "https://auth.proxy/review"}
+
+	_, err := c.Review("token", setting)
+	require.Nil(t, err)
+	_, err = c.Review("token", setting)
+	require.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&f.calls))
+
+	time.Sleep(100 * time.Millisecond)
+	_, err = c.Review("token", setting)
+	require.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&f.calls))
+}
+
+func TestTokenReviewCacheMaxEntries(t *testing.T) {
+	f := &fakeReviewer{authenticated: true}
+	c := newTokenReviewCache(f.review, time.Minute, 3)
+	setting := &models.HTTPAuthProxy{TokenReviewEndpoint: "https://auth.proxy/review"}
+
+	for i := 0; i < 10; i++ {
+		_, err := c.Review(fmt.Sprintf("token%d", i), setting)
+		require.Nil(t, err)
+		assert.True(t, len(c.entries) <= 3)
+	}
+	// the most recent one is always kept
+	_, err := c.Review("token9", setting)
+	require.Nil(t, err)
+	assert.Equal(t, int32(10), atomic.LoadInt32(&f.calls))
+}
+
+func TestTokenReviewKey(t *testing.T) {
+	key := tokenReviewKey("secret-token", "https://auth.proxy/review")
+	assert.NotContains(t, key, "secret-token")
+	assert.Equal(t, key, tokenReviewKey("secret-token", "https://auth.proxy/review"))
+	assert.NotEqual(t, key, tokenReviewKey("secret-token", "https://another.proxy/review"))
+}
@@ -36,6 +36,7 @@ import (
 	_ "github.com/goharbor/harbor/src/core/auth/db"
 	_ "github.com/goharbor/harbor/src/core/auth/ldap"
 	_ "github.com/goharbor/harbor/src/core/auth/oidc"
+	_ "github.com/goharbor/harbor/src/core/auth/saml"
 	_ "github.com/goharbor/harbor/src/core/auth/uaa"
 
 	quota "github.com/goharbor/harbor/src/core/api/quota"
@@ -44,6 +45,7 @@ import (
 
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/filter"
+	"github.com/goharbor/harbor/src/core/filter/metrics"
 	"github.com/goharbor/harbor/src/core/middlewares"
 	_ "github.com/goharbor/harbor/src/core/notifier/topic"
 	"github.com/goharbor/harbor/src/core/service/token"
@@ -190,6 +192,9 @@ func main() {
 	if err := config.Load(); err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	if err := config.LoadJobserviceSecrets(); err != nil {
+		log.Fatalf("failed to load the secrets of jobservice: %v", err)
+	}
 
 	// init the jobservice client
 	job.Init()
@@ -238,11 +243,19 @@ func main() {
 	if err := replication.Init(closing, done); err != nil {
 		log.Fatalf("failed to init for replication: %v", err)
 	}
+	api.NewPendingJobQueue().Start(closing)
+	api.WatchKeyRotation(closing)
+	config.WatchJobserviceSecrets(closing)
 
 	log.Info("initializing notification...")
 	notification.Init()
 
-	filter.Init()
+	if err := filter.Init(); err != nil {
+		log.Fatalf("failed to initialize the security filter: %v", err)
+	}
+	if config.AuthMetricsEnabled() {
+		beego.Handler("/metrics", metrics.Handler())
+	}
 	beego.InsertFilter("/*", beego.BeforeRouter, filter.SecurityFilter)
 	beego.InsertFilter("/*", beego.BeforeRouter, filter.ReadonlyFilter)
 	beego.InsertFilter("/api/*", beego.BeforeRouter, filter.MediaTypeFilter("application/json", "multipart/form-data", "application/octet-stream"))
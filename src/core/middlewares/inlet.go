@@ -37,9 +37,9 @@ func Init() error {
 
 // Handle handles the request.
 func Handle(rw http.ResponseWriter, req *http.Request) {
-	securityCtx, err := filter.GetSecurityContext(req)
-	if err != nil {
-		log.Errorf("failed to get security context in middlerware: %v", err)
+	securityCtx, ok := filter.SecurityContextFrom(req.Context())
+	if !ok {
+		log.Error("failed to get security context in middlerware")
 		// error to get security context, use the default chain.
 		head = New(Middlewares).Create().Then(proxy)
 	} else {
@@ -54,6 +54,38 @@ func (i *ImageDelMetaData) Resolve(evt *Event) error {
 	return nil
 }
 
+// ArtifactDeletedMetaData defines the artifact deleted by retention related event data
+type ArtifactDeletedMetaData struct {
+	Project     *models.Project
+	RepoName    string
+	Tag         string
+	Digest      string
+	ExecutionID int64
+	OccurAt     time.Time
+}
+
+// Resolve artifact deleted metadata into common image event
+func (a *ArtifactDeletedMetaData) Resolve(evt *Event) error {
+	data := &model.ImageEvent{
+		EventType: notifyModel.EventTypeArtifactDeleted,
+		Project:   a.Project,
+		OccurAt:   a.OccurAt,
+		Operator:  autoTriggeredOperator,
+		RepoName:  a.RepoName,
+		Resource: []*model.ImgResource{
+			{
+				Tag:    a.Tag,
+				Digest: a.Digest,
+			},
+		},
+		RetentionExecutionID: a.ExecutionID,
+	}
+
+	evt.Topic = model.ArtifactDeletedTopic
+	evt.Data = data
+	return nil
+}
+
 // ImagePushMetaData defines images pushing related event data
 type ImagePushMetaData struct {
 	Project  *models.Project
@@ -215,6 +247,27 @@ func (si *ScanImageMetaData) Resolve(evt *Event) error {
 	return nil
 }
 
+// AdminJobMetaData defines meta data of the status change of admin job
+type AdminJobMetaData struct {
+	JobID  int64
+	Status string
+}
+
+// Resolve admin job status metadata into admin job event
+func (a *AdminJobMetaData) Resolve(evt *Event) error {
+	data := &model.AdminJobEvent{
+		EventType: notifyModel.EventTypeAdminJobStatus,
+		JobID:     a.JobID,
+		Status:    a.Status,
+		OccurAt:   time.Now(),
+		Operator:  autoTriggeredOperator,
+	}
+
+	evt.Topic = model.AdminJobStatusTopic
+	evt.Data = data
+	return nil
+}
+
 // HookMetaData defines hook notification related event data
 type HookMetaData struct {
 	PolicyID  int64
@@ -136,6 +136,27 @@ func TestImageDelEvent_Build(t *testing.T) {
 	}
 }
 
+func TestArtifactDeletedEvent_Build(t *testing.T) {
+	event := &Event{}
+	err := event.Build(&ArtifactDeletedMetaData{
+		Project:     &models.Project{ProjectID: 1, Name: "library"},
+		RepoName:    "library/alpine",
+		Tag:         "v1.0",
+		Digest:      "sha256:digest",
+		ExecutionID: 2,
+		OccurAt:     time.Now(),
+	})
+	require.Nil(t, err)
+	assert.Equal(t, notifierModel.ArtifactDeletedTopic, event.Topic)
+
+	data, ok := event.Data.(*notifierModel.ImageEvent)
+	require.True(t, ok)
+	assert.Equal(t, int64(2), data.RetentionExecutionID)
+	require.Equal(t, 1, len(data.Resource))
+	assert.Equal(t, "v1.0", data.Resource[0].Tag)
+	assert.Equal(t, "sha256:digest", data.Resource[0].Digest)
+}
+
 func TestHookEvent_Build(t *testing.T) {
 	type args struct {
 		hookMetadata *HookMetaData
@@ -0,0 +1,112 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/job"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/notifier/event"
+	"github.com/goharbor/harbor/src/core/notifier/model"
+	notificationModel "github.com/goharbor/harbor/src/pkg/notification/model"
+	"github.com/pkg/errors"
+)
+
+// AdminJobPreprocessHandler preprocess the status change event of admin job
+type AdminJobPreprocessHandler struct {
+}
+
+// Handle preprocess the status change event of admin job and then publish hook event
+func (a *AdminJobPreprocessHandler) Handle(value interface{}) error {
+	// if global notification configured disabled, return directly
+	if !config.NotificationEnable() || !config.AdminJobNotificationEnable() {
+		log.Debug("notification of admin job is not enabled")
+		return nil
+	}
+
+	if value == nil {
+		return errors.New("empty admin job event")
+	}
+
+	e, ok := value.(*model.AdminJobEvent)
+	if !ok {
+		return errors.New("invalid admin job event type")
+	}
+
+	if !containsStatus(config.AdminJobNotificationStatuses(), e.Status) {
+		log.Debugf("status %s of admin job %d isn't notified", e.Status, e.JobID)
+		return nil
+	}
+
+	address := config.AdminJobNotificationAddress()
+	if len(address) == 0 {
+		log.Debug("notification address of admin job is not configured")
+		return nil
+	}
+
+	aj, err := dao.GetAdminJob(e.JobID)
+	if err != nil || aj == nil {
+		return errors.Errorf("admin job preprocess handler: failed to get admin job %d: %v", e.JobID, err)
+	}
+
+	extURL, err := config.ExtURL()
+	if err != nil {
+		return errors.Wrap(err, "admin job preprocess handler")
+	}
+
+	evt := &event.Event{}
+	hookMetadata := &event.HookMetaData{
+		EventType: e.EventType,
+		Payload:   constructAdminJobPayload(e, aj, extURL),
+		Target: &models.EventTarget{
+			Type:    notificationModel.NotifyTypeHTTP,
+			Address: address,
+		},
+	}
+	if err := evt.Build(hookMetadata); err != nil {
+		return errors.Wrap(err, "admin job preprocess handler")
+	}
+
+	return evt.Publish()
+}
+
+// IsStateful ...
+func (a *AdminJobPreprocessHandler) IsStateful() bool {
+	return false
+}
+
+func constructAdminJobPayload(event *model.AdminJobEvent, aj *models.AdminJob, extURL string) *model.Payload {
+	adminJob := &model.AdminJob{
+		ID:     aj.ID,
+		Name:   aj.Name,
+		Kind:   aj.Kind,
+		Status: event.Status,
+	}
+	// only the GC job exposes the API to get the log
+	if aj.Name == job.ImageGC {
+		adminJob.LogURL = fmt.Sprintf("%s/api/system/gc/%d/log", extURL, aj.ID)
+	}
+
+	return &model.Payload{
+		Type:    event.EventType,
+		OccurAt: event.OccurAt.Unix(),
+		EventData: &model.EventData{
+			AdminJob: adminJob,
+		},
+		Operator: event.Operator,
+	}
+}
+
+// containsStatus checks whether the status is one of the statuses, the comparison is case insensitive
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if strings.EqualFold(s, status) {
+			return true
+		}
+	}
+
+	return false
+}
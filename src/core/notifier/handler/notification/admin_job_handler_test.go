@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/job"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/notifier/model"
+	nm "github.com/goharbor/harbor/src/pkg/notification/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConstructAdminJobPayload tests the payload of the status change event of admin job
+func TestConstructAdminJobPayload(t *testing.T) {
+	occurAt := time.Now()
+	e := &model.AdminJobEvent{
+		EventType: nm.EventTypeAdminJobStatus,
+		JobID:     1,
+		Status:    models.JobError,
+		OccurAt:   occurAt,
+		Operator:  "auto",
+	}
+
+	aj := &models.AdminJob{
+		ID:     1,
+		Name:   job.ImageGC,
+		Kind:   job.JobKindPeriodic,
+		Status: models.JobRunning,
+	}
+	payload := constructAdminJobPayload(e, aj, "https://harbor.example.com")
+	assert.Equal(t, nm.EventTypeAdminJobStatus, payload.Type)
+	assert.Equal(t, occurAt.Unix(), payload.OccurAt)
+	assert.Equal(t, "auto", payload.Operator)
+	require.NotNil(t, payload.EventData)
+	assert.Equal(t, &model.AdminJob{
+		ID:     1,
+		Name:   job.ImageGC,
+		Kind:   job.JobKindPeriodic,
+		Status: models.JobError,
+		LogURL: "https://harbor.example.com/api/system/gc/1/log",
+	}, payload.EventData.AdminJob)
+
+	// the scan all job has no log API
+	aj.Name = job.ImageScanAllJob
+	payload = constructAdminJobPayload(e, aj, "https://harbor.example.com")
+	require.NotNil(t, payload.EventData.AdminJob)
+	assert.Empty(t, payload.EventData.AdminJob.LogURL)
+}
+
+// TestContainsStatus tests matching the status of admin job with the configured statuses
+func TestContainsStatus(t *testing.T) {
+	statuses := []string{models.JobError, "Stopped"}
+	assert.True(t, containsStatus(statuses, models.JobError))
+	assert.True(t, containsStatus(statuses, models.JobStopped))
+	assert.False(t, containsStatus(statuses, models.JobFinished))
+	assert.False(t, containsStatus(nil, models.JobError))
+}
@@ -1,14 +1,11 @@
 package notification
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
 
-	"github.com/goharbor/harbor/src/common/job/models"
 	"github.com/goharbor/harbor/src/core/notifier/model"
-	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/pkg/notification"
+	"github.com/goharbor/harbor/src/pkg/notification/hook"
 )
 
 // HTTPHandler preprocess http event data and start the hook processing
@@ -35,25 +32,9 @@ func (h *HTTPHandler) IsStateful() bool {
 }
 
 func (h *HTTPHandler) process(event *model.HookEvent) error {
-	j := &models.JobData{
-		Metadata: &models.JobMetadata{
-			JobKind: job.KindGeneric,
-		},
-	}
-	j.Name = job.WebhookJob
-
-	payload, err := json.Marshal(event.Payload)
+	j, err := hook.NewWebhookJobData(event)
 	if err != nil {
-		return fmt.Errorf("marshal from payload %v failed: %v", event.Payload, err)
-	}
-
-	j.Parameters = map[string]interface{}{
-		"payload": string(payload),
-		"address": event.Target.Address,
-		// Users can define a auth header in http statement in notification(webhook) policy.
-		// So it will be sent in header in http request.
-		"auth_header":      event.Target.AuthHeader,
-		"skip_cert_verify": event.Target.SkipCertVerify,
+		return err
 	}
 	return notification.HookManager.StartHook(event, j)
 }
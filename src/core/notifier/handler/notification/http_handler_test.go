@@ -11,6 +11,7 @@ import (
 	"github.com/goharbor/harbor/src/core/notifier/event"
 	"github.com/goharbor/harbor/src/core/notifier/model"
 	"github.com/goharbor/harbor/src/pkg/notification"
+	"github.com/goharbor/harbor/src/pkg/notification/hook"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,6 +22,10 @@ func (f *fakedHookManager) StartHook(event *model.HookEvent, job *models.JobData
 	return nil
 }
 
+func (f *fakedHookManager) DeliverTest(event *model.HookEvent) (*hook.DeliveryResult, error) {
+	return &hook.DeliveryResult{}, nil
+}
+
 func TestHTTPHandler_Handle(t *testing.T) {
 	hookMgr := notification.HookManager
 	defer func() {
@@ -51,6 +51,11 @@ func constructImagePayload(event *notifyModel.ImageEvent) (*notifyModel.Payload,
 		},
 		Operator: event.Operator,
 	}
+	if event.RetentionExecutionID > 0 {
+		payload.EventData.Retention = &notifyModel.Retention{
+			ExecutionID: event.RetentionExecutionID,
+		}
+	}
 
 	repoRecord, err := dao.GetRepositoryByName(repoName)
 	if err != nil {
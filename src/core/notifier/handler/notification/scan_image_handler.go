@@ -1,13 +1,17 @@
 package notification
 
 import (
+	"fmt"
+
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/notifier/model"
 	"github.com/goharbor/harbor/src/pkg/notification"
+	notificationModel "github.com/goharbor/harbor/src/pkg/notification/model"
 	"github.com/goharbor/harbor/src/pkg/scan/api/scan"
 	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/goharbor/harbor/src/pkg/scan/vuln"
 	"github.com/pkg/errors"
 )
 
@@ -54,6 +58,15 @@ func (si *ScanImagePreprocessHandler) Handle(value interface{}) error {
 		return errors.Wrap(err, "scan preprocess handler")
 	}
 
+	// Only notify the policies whose severity threshold is reached by the report
+	if e.EventType == notificationModel.EventTypeScanningCompleted {
+		policies = filterPoliciesBySeverity(policies, reportSeverity(payload))
+		if len(policies) == 0 {
+			log.Debugf("Severity threshold of the policies isn't reached for %s event: %v", e.EventType, e)
+			return nil
+		}
+	}
+
 	err = sendHookWithPolicies(policies, payload, e.EventType)
 	if err != nil {
 		return errors.Wrap(err, "scan preprocess handler")
@@ -106,12 +119,44 @@ func constructScanImagePayload(event *model.ScanImageEvent, project *models.Proj
 	}
 
 	resource := &model.Resource{
-		Tag:          event.Artifact.Tag,
-		Digest:       event.Artifact.Digest,
-		ResourceURL:  resURL,
-		ScanOverview: summaries,
+		Tag:           event.Artifact.Tag,
+		Digest:        event.Artifact.Digest,
+		ResourceURL:   resURL,
+		ScanOverview:  summaries,
+		ScanReportURL: buildScanReportURL(extURL, event.Artifact.Repository, event.Artifact.Tag),
 	}
 	payload.EventData.Resources = append(payload.EventData.Resources, resource)
 
 	return payload, nil
 }
+
+// buildScanReportURL returns the URL of the API to get the scan report of the image
+func buildScanReportURL(extURL, repoName, tag string) string {
+	return fmt.Sprintf("%s/api/repositories/%s/tags/%s/scan", extURL, repoName, tag)
+}
+
+// reportSeverity returns the overall severity of the native report in the payload,
+// the unknown severity is returned if there is no such report
+func reportSeverity(payload *model.Payload) vuln.Severity {
+	for _, res := range payload.EventData.Resources {
+		if sum, ok := res.ScanOverview[v1.MimeTypeNativeReport].(*vuln.NativeReportSummary); ok {
+			return sum.Severity
+		}
+	}
+
+	return vuln.Unknown
+}
+
+// filterPoliciesBySeverity filters out the policies whose severity threshold is higher than the severity
+func filterPoliciesBySeverity(policies []*models.NotificationPolicy, severity vuln.Severity) []*models.NotificationPolicy {
+	var res []*models.NotificationPolicy
+	for _, ply := range policies {
+		if len(ply.ScanSeverityThreshold) > 0 &&
+			vuln.Severity(ply.ScanSeverityThreshold).Code() > severity.Code() {
+			continue
+		}
+		res = append(res, ply)
+	}
+
+	return res
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/notifier"
 	"github.com/goharbor/harbor/src/core/notifier/model"
@@ -14,8 +15,11 @@ import (
 	"github.com/goharbor/harbor/src/pkg/notification/policy"
 	sc "github.com/goharbor/harbor/src/pkg/scan/api/scan"
 	"github.com/goharbor/harbor/src/pkg/scan/dao/scan"
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
 	"github.com/goharbor/harbor/src/pkg/scan/report"
 	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/goharbor/harbor/src/pkg/scan/vuln"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -90,6 +94,55 @@ func (suite *ScanImagePreprocessHandlerSuite) TestHandle() {
 	suite.NoError(err)
 }
 
+// TestFilterPoliciesBySeverity tests filtering the policies by the severity threshold
+func TestFilterPoliciesBySeverity(t *testing.T) {
+	policies := []*models.NotificationPolicy{
+		{ID: 1},
+		{ID: 2, ScanSeverityThreshold: string(vuln.Low)},
+		{ID: 3, ScanSeverityThreshold: string(vuln.High)},
+		{ID: 4, ScanSeverityThreshold: string(vuln.Critical)},
+	}
+
+	cases := []struct {
+		severity vuln.Severity
+		expected []int64
+	}{
+		{severity: vuln.None, expected: []int64{1}},
+		{severity: vuln.Low, expected: []int64{1, 2}},
+		{severity: vuln.Medium, expected: []int64{1, 2}},
+		{severity: vuln.High, expected: []int64{1, 2, 3}},
+		{severity: vuln.Critical, expected: []int64{1, 2, 3, 4}},
+		{severity: vuln.Unknown, expected: []int64{1, 2, 3, 4}},
+	}
+
+	for _, c := range cases {
+		var ids []int64
+		for _, ply := range filterPoliciesBySeverity(policies, c.severity) {
+			ids = append(ids, ply.ID)
+		}
+		assert.Equal(t, c.expected, ids, "severity %s", c.severity)
+	}
+}
+
+// TestReportSeverity tests getting the severity of the native report from the payload
+func TestReportSeverity(t *testing.T) {
+	payload := &model.Payload{
+		EventData: &model.EventData{
+			Resources: []*model.Resource{
+				{
+					ScanOverview: map[string]interface{}{
+						v1.MimeTypeNativeReport: &vuln.NativeReportSummary{Severity: vuln.Medium},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, vuln.Medium, reportSeverity(payload))
+
+	payload.EventData.Resources[0].ScanOverview = map[string]interface{}{}
+	assert.Equal(t, vuln.Unknown, reportSeverity(payload))
+}
+
 // Mock things
 
 // MockScanAPIController ...
@@ -98,7 +151,7 @@ type MockScanAPIController struct {
 }
 
 // Scan ...
-func (msc *MockScanAPIController) Scan(artifact *v1.Artifact) error {
+func (msc *MockScanAPIController) Scan(artifact *v1.Artifact, options ...sc.Option) error {
 	args := msc.Called(artifact)
 
 	return args.Error(0)
@@ -114,6 +167,26 @@ func (msc *MockScanAPIController) GetReport(artifact *v1.Artifact, mimeTypes []s
 	return args.Get(0).([]*scan.Report), args.Error(1)
 }
 
+func (msc *MockScanAPIController) BenchmarkScanner(reg *scanner.Registration, testDigest string) (*sc.BenchmarkResult, error) {
+	args := msc.Called(reg, testDigest)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*sc.BenchmarkResult), args.Error(1)
+}
+
+func (msc *MockScanAPIController) GetReportMimeTypes(artifact *v1.Artifact) ([]string, error) {
+	args := msc.Called(artifact)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (msc *MockScanAPIController) GetSummary(artifact *v1.Artifact, mimeTypes []string, options ...report.Option) (map[string]interface{}, error) {
 	args := msc.Called(artifact, mimeTypes, options)
 
@@ -15,6 +15,8 @@ type ImageEvent struct {
 	OccurAt   time.Time
 	Operator  string
 	RepoName  string
+	// the ID of the retention execution which deletes the image
+	RetentionExecutionID int64
 }
 
 // ImgResource include image digest and tag
@@ -41,6 +43,15 @@ type ScanImageEvent struct {
 	Operator  string
 }
 
+// AdminJobEvent is the status change of admin job to publish
+type AdminJobEvent struct {
+	EventType string
+	JobID     int64
+	Status    string
+	OccurAt   time.Time
+	Operator  string
+}
+
 // HookEvent is hook related event data to publish
 type HookEvent struct {
 	PolicyID  int64
@@ -61,14 +72,17 @@ type Payload struct {
 type EventData struct {
 	Resources  []*Resource `json:"resources"`
 	Repository *Repository `json:"repository"`
+	AdminJob   *AdminJob   `json:"admin_job,omitempty"`
+	Retention  *Retention  `json:"retention,omitempty"`
 }
 
 // Resource describe infos of resource triggered notification
 type Resource struct {
-	Digest       string                 `json:"digest,omitempty"`
-	Tag          string                 `json:"tag"`
-	ResourceURL  string                 `json:"resource_url,omitempty"`
-	ScanOverview map[string]interface{} `json:"scan_overview,omitempty"`
+	Digest        string                 `json:"digest,omitempty"`
+	Tag           string                 `json:"tag"`
+	ResourceURL   string                 `json:"resource_url,omitempty"`
+	ScanOverview  map[string]interface{} `json:"scan_overview,omitempty"`
+	ScanReportURL string                 `json:"scan_report_url,omitempty"`
 }
 
 // Repository info of notification event
@@ -79,3 +93,17 @@ type Repository struct {
 	RepoFullName string `json:"repo_full_name"`
 	RepoType     string `json:"repo_type"`
 }
+
+// AdminJob info of the admin job notification event
+type AdminJob struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	LogURL string `json:"log_url,omitempty"`
+}
+
+// Retention info of the artifact deleted notification event
+type Retention struct {
+	ExecutionID int64 `json:"execution_id"`
+}
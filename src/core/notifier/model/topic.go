@@ -18,6 +18,10 @@ const (
 	ScanningFailedTopic = "OnScanningFailed"
 	// ScanningCompletedTopic is topic for scanning completed event
 	ScanningCompletedTopic = "OnScanningCompleted"
+	// AdminJobStatusTopic is topic for the status change of admin job
+	AdminJobStatusTopic = "OnAdminJobStatus"
+	// ArtifactDeletedTopic is topic for the artifact deleted by retention
+	ArtifactDeletedTopic = "OnArtifactDeleted"
 
 	// WebhookTopic is topic for sending webhook payload
 	WebhookTopic = "http"
@@ -19,6 +19,8 @@ func init() {
 		model.DeleteChartTopic:       {&notification.ChartPreprocessHandler{}},
 		model.ScanningCompletedTopic: {&notification.ScanImagePreprocessHandler{}},
 		model.ScanningFailedTopic:    {&notification.ScanImagePreprocessHandler{}},
+		model.AdminJobStatusTopic:    {&notification.AdminJobPreprocessHandler{}},
+		model.ArtifactDeletedTopic:   {&notification.ImagePreprocessHandler{}},
 	}
 
 	for t, handlers := range handlersMap {
@@ -34,12 +34,16 @@ func initRouters() {
 		// Controller API:
 		beego.Router("/c/login", &controllers.CommonController{}, "post:Login")
 		beego.Router("/c/log_out", &controllers.CommonController{}, "get:LogOut")
+		beego.Router("/c/csrf_token", &controllers.CommonController{}, "get:CSRFToken")
 		beego.Router("/c/reset", &controllers.CommonController{}, "post:ResetPassword")
 		beego.Router("/c/userExists", &controllers.CommonController{}, "post:UserExists")
 		beego.Router("/c/sendEmail", &controllers.CommonController{}, "get:SendResetEmail")
 		beego.Router(common.OIDCLoginPath, &controllers.OIDCController{}, "get:RedirectLogin")
 		beego.Router("/c/oidc/onboard", &controllers.OIDCController{}, "post:Onboard")
 		beego.Router(common.OIDCCallbackPath, &controllers.OIDCController{}, "get:Callback")
+		beego.Router(common.SAMLLoginPath, &controllers.SAMLController{}, "get:RedirectLogin")
+		beego.Router(common.SAMLCallbackPath, &controllers.SAMLController{}, "post:Callback")
+		beego.Router(common.SAMLMetadataPath, &controllers.SAMLController{}, "get:Metadata")
 
 		// API:
 		beego.Router("/api/projects/:pid([0-9]+)/members/?:pmid([0-9]+)", &api.ProjectMemberAPI{})
@@ -53,6 +57,10 @@ func initRouters() {
 		beego.Router("/api/users/:id/permissions", &api.UserAPI{}, "get:ListUserPermissions")
 		beego.Router("/api/users/:id/sysadmin", &api.UserAPI{}, "put:ToggleUserAdminRole")
 		beego.Router("/api/users/:id/cli_secret", &api.UserAPI{}, "put:SetCLISecret")
+		beego.Router("/api/users/:id([0-9]+)/impersonate", &api.UserAPI{}, "post:Impersonate")
+		beego.Router("/api/users/:id([0-9]+)/override", &api.UserAPI{}, "post:Override")
+		beego.Router("/api/users/:id/apikeys", &api.UserAPIKeyAPI{}, "get:List;post:Post")
+		beego.Router("/api/users/:id/apikeys/:kid([0-9]+)", &api.UserAPIKeyAPI{}, "get:Get;put:Put;delete:Delete")
 		beego.Router("/api/usergroups/?:ugid([0-9]+)", &api.UserGroupAPI{})
 		beego.Router("/api/ldap/ping", &api.LdapAPI{}, "post:Ping")
 		beego.Router("/api/ldap/users/search", &api.LdapAPI{}, "get:Search")
@@ -68,6 +76,11 @@ func initRouters() {
 	beego.Router("/api/projects/", &api.ProjectAPI{}, "get:List;post:Post")
 	beego.Router("/api/projects/:id([0-9]+)/summary", &api.ProjectAPI{}, "get:Summary")
 	beego.Router("/api/projects/:id([0-9]+)/logs", &api.ProjectAPI{}, "get:Logs")
+	beego.Router("/api/projects/:id([0-9]+)/config-history", &api.ProjectAPI{}, "get:ConfigHistory")
+	beego.Router("/api/projects/:id([0-9]+)/scan-metrics", &api.ProjectAPI{}, "get:ScanMetrics")
+	beego.Router("/api/projects/:id([0-9]+)/storage-breakdown", &api.ProjectAPI{}, "get:StorageBreakdown")
+	beego.Router("/api/projects/:id([0-9]+)/repositories/*/tag-suggestions", &api.ProjectAPI{}, "get:TagSuggestions")
+	beego.Router("/api/projects/:id([0-9]+)/risk-score", &api.ProjectAPI{}, "get:RiskScore")
 	beego.Router("/api/projects/:id([0-9]+)/_deletable", &api.ProjectAPI{}, "get:Deletable")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/?:name", &api.MetadataAPI{}, "get:Get")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/", &api.MetadataAPI{}, "post:Post")
@@ -75,6 +88,10 @@ func initRouters() {
 
 	beego.Router("/api/projects/:pid([0-9]+)/robots", &api.RobotAPI{}, "post:Post;get:List")
 	beego.Router("/api/projects/:pid([0-9]+)/robots/:id([0-9]+)", &api.RobotAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/projects/:pid([0-9]+)/robots/:id([0-9]+)/token", &api.RobotAPI{}, "put:RotateToken")
+	beego.Router("/api/system/robots", &api.SystemRobotAPI{}, "post:Post;get:List")
+	beego.Router("/api/system/robots/:id([0-9]+)", &api.SystemRobotAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/system/robots/:id([0-9]+)/token", &api.SystemRobotAPI{}, "put:RotateToken")
 
 	beego.Router("/api/quotas", &api.QuotaAPI{}, "get:List")
 	beego.Router("/api/quotas/:id([0-9]+)", &api.QuotaAPI{}, "get:Get;put:Put")
@@ -86,18 +103,29 @@ func initRouters() {
 	beego.Router("/api/repositories/*/tags/:tag", &api.RepositoryAPI{}, "delete:Delete;get:GetTag")
 	beego.Router("/api/repositories/*/tags/:tag/labels", &api.RepositoryLabelAPI{}, "get:GetOfImage;post:AddToImage")
 	beego.Router("/api/repositories/*/tags/:tag/labels/:id([0-9]+)", &api.RepositoryLabelAPI{}, "delete:RemoveFromImage")
-	beego.Router("/api/repositories/*/tags", &api.RepositoryAPI{}, "get:GetTags;post:Retag")
+	beego.Router("/api/repositories/*/tags", &api.RepositoryAPI{}, "get:GetTags;post:Retag;delete:DeleteTags")
 	beego.Router("/api/repositories/*/tags/:tag/manifest", &api.RepositoryAPI{}, "get:GetManifests")
 	beego.Router("/api/repositories/*/signatures", &api.RepositoryAPI{}, "get:GetSignatures")
+	beego.Router("/api/repositories/*/reclaimable", &api.RepositoryAPI{}, "post:GetReclaimableSizes")
 	beego.Router("/api/repositories/top", &api.RepositoryAPI{}, "get:GetTopRepos")
 
 	beego.Router("/api/system/gc", &api.GCAPI{}, "get:List")
 	beego.Router("/api/system/gc/:id", &api.GCAPI{}, "get:GetGC")
 	beego.Router("/api/system/gc/:id([0-9]+)/log", &api.GCAPI{}, "get:GetLog")
-	beego.Router("/api/system/gc/schedule", &api.GCAPI{}, "get:Get;put:Put;post:Post")
-	beego.Router("/api/system/scanAll/schedule", &api.ScanAllAPI{}, "get:Get;put:Put;post:Post")
+	beego.Router("/api/system/gc/:id([0-9]+)/stop", &api.GCAPI{}, "put:Stop")
+	beego.Router("/api/system/gc/schedule", &api.GCAPI{}, "get:Get;put:Put;post:Post;patch:Patch")
+	beego.Router("/api/system/scanAll/schedule", &api.ScanAllAPI{}, "get:Get;put:Put;post:Post;patch:Patch")
+	beego.Router("/api/system/scanAll/schedules", &api.ScanAllAPI{}, "get:ListSchedules")
+	beego.Router("/api/system/scanAll/:id([0-9]+)/stop", &api.ScanAllAPI{}, "put:Stop")
+	beego.Router("/api/system/schedule-audit", &api.ScheduleAuditAPI{}, "get:List")
+	beego.Router("/api/system/users/inactive", &api.InactiveUserAPI{}, "get:List")
+	beego.Router("/api/system/harbor/upgrade-check", &api.UpgradeCheckAPI{}, "post:Check")
+	beego.Router("/api/system/keys/rotate", &api.SystemKeyAPI{}, "post:Rotate")
+	beego.Router("/api/system/secrets/rotate", &api.SystemSecretAPI{}, "post:Rotate")
 	beego.Router("/api/system/CVEWhitelist", &api.SysCVEWhitelistAPI{}, "get:Get;put:Put")
 	beego.Router("/api/system/oidc/ping", &api.OIDCAPI{}, "post:Ping")
+	beego.Router("/api/system/replication/executions", &api.ReplicationOperationAPI{}, "get:ListSystemExecutions")
+	beego.Router("/api/system/artifacts/layer-sharing", &api.LayerSharingAPI{}, "get:List")
 
 	beego.Router("/api/logs", &api.LogAPI{})
 
@@ -113,6 +141,8 @@ func initRouters() {
 	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies", &api.NotificationPolicyAPI{}, "get:List;post:Post")
 	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies/:id([0-9]+)", &api.NotificationPolicyAPI{})
 	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies/test", &api.NotificationPolicyAPI{}, "post:Test")
+	beego.Router("/api/projects/:pid([0-9]+)/webhooks/test", &api.NotificationPolicyAPI{}, "post:TestDelivery")
+	beego.Router("/api/projects/:pid([0-9]+)/webhooks/:id([0-9]+)/stats", &api.NotificationPolicyAPI{}, "get:Stats")
 
 	beego.Router("/api/projects/:pid([0-9]+)/webhook/lasttrigger", &api.NotificationPolicyAPI{}, "get:ListGroupByEventType")
 
@@ -163,6 +193,9 @@ func initRouters() {
 	beego.Router("/api/retentions/:id/executions", &api.RetentionAPI{}, "get:ListRetentionExecs")
 	beego.Router("/api/retentions/:id/executions/:eid/tasks", &api.RetentionAPI{}, "get:ListRetentionExecTasks")
 	beego.Router("/api/retentions/:id/executions/:eid/tasks/:tid", &api.RetentionAPI{}, "get:GetRetentionExecTaskLog")
+	beego.Router("/api/projects/:pid([0-9]+)/retentions/:id([0-9]+)/preview", &api.RetentionAPI{}, "get:PreviewRetention")
+	beego.Router("/api/projects/:pid([0-9]+)/retentions/:id([0-9]+)/analyze", &api.RetentionAPI{}, "post:AnalyzeRetentionConflicts")
+	beego.Router("/api/projects/:id([0-9]+)/tag-retention-conflicts", &api.RetentionAPI{}, "get:ListTagRetentionConflicts")
 	beego.Router("/api/projects/:pid([0-9]+)/immutabletagrules", &api.ImmutableTagRuleAPI{}, "get:List;post:Post")
 	beego.Router("/api/projects/:pid([0-9]+)/immutabletagrules/:id([0-9]+)", &api.ImmutableTagRuleAPI{})
 
@@ -199,10 +232,13 @@ func initRouters() {
 	beego.Router("/api/scanners/:uuid", scannerAPI, "get:Get;delete:Delete;put:Update;patch:SetAsDefault")
 	beego.Router("/api/scanners/:uuid/metadata", scannerAPI, "get:Metadata")
 	beego.Router("/api/scanners/ping", scannerAPI, "post:Ping")
+	beego.Router("/api/system/scanner/benchmark", scannerAPI, "post:Benchmark")
+	beego.Router("/api/system/scanner/client-pool", scannerAPI, "get:ClientPool")
 
 	// Add routes for project level scanner
 	proScannerAPI := &api.ProjectScannerAPI{}
 	beego.Router("/api/projects/:pid([0-9]+)/scanner", proScannerAPI, "get:GetProjectScanner;put:SetProjectScanner")
+	beego.Router("/api/projects/:pid([0-9]+)/scanner/assignments", proScannerAPI, "get:GetScannerAssignments;put:SetScannerAssignments")
 
 	// Add routes for scan
 	scanAPI := &api.ScanAPI{}
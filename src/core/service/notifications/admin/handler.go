@@ -23,6 +23,18 @@ import (
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/api"
+	"github.com/goharbor/harbor/src/core/notifier/event"
+)
+
+// publishEvent is a variable to make it replaceable in the tests
+var publishEvent = func(e *event.Event) error {
+	return e.Publish()
+}
+
+// the persistence of the check-in of the admin job, they're variables to make them replaceable in the tests
+var (
+	updateSummary = dao.UpdateAdminJobSummary
+	updateStats   = dao.UpdateAdminJobStats
 )
 
 var statusMap = map[string]string{
@@ -41,6 +53,7 @@ type Handler struct {
 	id            int64
 	UUID          string
 	status        string
+	checkIn       string
 	UpstreamJobID string
 }
 
@@ -75,6 +88,7 @@ func (h *Handler) Prepare() {
 		return
 	}
 	h.status = status
+	h.checkIn = data.CheckIn
 }
 
 // HandleAdminJob handles the webhook of admin jobs
@@ -90,4 +104,48 @@ func (h *Handler) HandleAdminJob() {
 		h.SendInternalServerError(err)
 		return
 	}
+	if len(h.checkIn) > 0 {
+		if err := handleCheckIn(h.id, h.checkIn); err != nil {
+			log.Errorf("Failed to update job check in, id: %d", h.id)
+			h.SendInternalServerError(err)
+			return
+		}
+	}
+	publishAdminJobEvent(h.id, h.status)
+}
+
+// handleCheckIn persists the check in of the admin job, it's either the statistics of the execution,
+// e.g. the blobs deleted by GC, or the summary of the execution, e.g. the blobs found by the dry run of GC.
+// The invalid check in is dropped.
+func handleCheckIn(id int64, checkIn string) error {
+	var data struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal([]byte(checkIn), &data); err != nil {
+		log.Warningf("drop the invalid check in of admin job %d: %s", id, checkIn)
+		return nil
+	}
+	if data.Kind != models.AdminJobCheckInKindStats {
+		return updateSummary(id, checkIn)
+	}
+
+	stats := &models.GCStats{}
+	if err := json.Unmarshal([]byte(checkIn), stats); err != nil {
+		log.Warningf("drop the invalid stats of admin job %d: %s", id, checkIn)
+		return nil
+	}
+	return updateStats(id, checkIn)
+}
+
+// publishAdminJobEvent publishes the status change of the admin job, the failure is only logged
+// as it shouldn't block the status update
+func publishAdminJobEvent(id int64, status string) {
+	evt := &event.Event{}
+	if err := evt.Build(&event.AdminJobMetaData{JobID: id, Status: status}); err != nil {
+		log.Errorf("failed to build the event of admin job %d: %v", id, err)
+		return
+	}
+	if err := publishEvent(evt); err != nil {
+		log.Errorf("failed to publish the event of admin job %d: %v", id, err)
+	}
 }
@@ -0,0 +1,88 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/job"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/notifier/event"
+	"github.com/goharbor/harbor/src/core/notifier/model"
+	nm "github.com/goharbor/harbor/src/pkg/notification/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPublishAdminJobEvent tests the event published for the status change of admin job
+func TestPublishAdminJobEvent(t *testing.T) {
+	var published *event.Event
+	p := publishEvent
+	publishEvent = func(e *event.Event) error {
+		published = e
+		return errors.New("webhook is down")
+	}
+	defer func() {
+		publishEvent = p
+	}()
+
+	// the failure of publishing is only logged
+	publishAdminJobEvent(1, statusMap[job.JobServiceStatusError])
+
+	require.NotNil(t, published)
+	assert.Equal(t, model.AdminJobStatusTopic, published.Topic)
+	data, ok := published.Data.(*model.AdminJobEvent)
+	require.True(t, ok)
+	assert.Equal(t, nm.EventTypeAdminJobStatus, data.EventType)
+	assert.Equal(t, int64(1), data.JobID)
+	assert.Equal(t, models.JobError, data.Status)
+	assert.Equal(t, "auto", data.Operator)
+}
+
+// TestHandleCheckIn tests the check in of admin job is persisted as the stats or the summary by the kind
+func TestHandleCheckIn(t *testing.T) {
+	summaries, stats := map[int64]string{}, map[int64]string{}
+	us, ut := updateSummary, updateStats
+	updateSummary = func(id int64, summary string) error {
+		summaries[id] = summary
+		return nil
+	}
+	updateStats = func(id int64, s string) error {
+		stats[id] = s
+		return nil
+	}
+	defer func() {
+		updateSummary, updateStats = us, ut
+	}()
+
+	gcStats := `{"kind":"stats","blobs_deleted":2,"manifests_deleted":1,"bytes_freed":1024,"duration":3,"errors":0}`
+	require.Nil(t, handleCheckIn(1, gcStats))
+	dryRun := `{"dry_run":true,"blob_count":2,"blob_size":1024}`
+	require.Nil(t, handleCheckIn(2, dryRun))
+	// the invalid ones are dropped
+	require.Nil(t, handleCheckIn(3, "not json"))
+	require.Nil(t, handleCheckIn(4, `["not an object"]`))
+	require.Nil(t, handleCheckIn(5, `{"kind":"stats","blobs_deleted":"two"}`))
+
+	assert.Equal(t, map[int64]string{1: gcStats}, stats)
+	assert.Equal(t, map[int64]string{2: dryRun}, summaries)
+
+	// the failure of the persistence is returned
+	updateStats = func(id int64, s string) error {
+		return errors.New("db is down")
+	}
+	assert.NotNil(t, handleCheckIn(1, gcStats))
+}
@@ -16,18 +16,22 @@ package jobs
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/goharbor/harbor/src/common/job"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/api"
+	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/notifier/event"
 	jjob "github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/pkg/notification"
+	notifyModel "github.com/goharbor/harbor/src/pkg/notification/model"
 	"github.com/goharbor/harbor/src/pkg/retention"
 	sc "github.com/goharbor/harbor/src/pkg/scan"
 	"github.com/goharbor/harbor/src/pkg/scan/api/scan"
+	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
 	"github.com/goharbor/harbor/src/replication"
 	"github.com/goharbor/harbor/src/replication/operation/hook"
 	"github.com/goharbor/harbor/src/replication/policy/scheduler"
@@ -95,29 +99,6 @@ func (h *Handler) Prepare() {
 func (h *Handler) HandleScan() {
 	log.Debugf("received san job status update event: job UUID: %s, status-%s, track id-%s", h.change.JobID, h.status, h.trackID)
 
-	// Trigger image scan webhook event only for JobFinished and JobError status
-	if h.status == models.JobFinished || h.status == models.JobError {
-		// Get the required info from the job parameters
-		req, err := sc.ExtractScanReq(h.change.Metadata.Parameters)
-		if err != nil {
-			log.Error(errors.Wrap(err, "scan job hook handler: event publish"))
-		} else {
-			e := &event.Event{}
-			metaData := &event.ScanImageMetaData{
-				Artifact: req.Artifact,
-				Status:   h.status,
-			}
-
-			if err := e.Build(metaData); err == nil {
-				if err := e.Publish(); err != nil {
-					log.Error(errors.Wrap(err, "scan job hook handler: event publish"))
-				}
-			} else {
-				log.Error(errors.Wrap(err, "scan job hook handler: event publish"))
-			}
-		}
-	}
-
 	if err := scan.DefaultController.HandleJobHooks(h.trackID, h.change); err != nil {
 		err = errors.Wrap(err, "scan job hook handler")
 		log.Error(err)
@@ -125,6 +106,71 @@ func (h *Handler) HandleScan() {
 
 		return
 	}
+
+	// Trigger image scan webhook event after the report is persisted, the failure of
+	// the event publishing is only logged to avoid failing the check in of the report
+	if status, ok := scanEventStatus(h.status, h.checkIn); ok {
+		if err := publishScanEvent(h.change.Metadata, status); err != nil {
+			log.Error(errors.Wrap(err, "scan job hook handler: event publish"))
+		}
+	}
+}
+
+// scanEventStatus returns the status of the image scan webhook event triggered by the hook.
+// The completed event is triggered once the native report is checked in and the failed
+// event is triggered once the job fails.
+func scanEventStatus(status, checkIn string) (string, bool) {
+	if status == models.JobError {
+		return models.JobError, true
+	}
+
+	if len(checkIn) == 0 {
+		return "", false
+	}
+
+	kind, err := sc.CheckInKindOf(checkIn)
+	if err != nil || kind != sc.CheckInKindReport {
+		return "", false
+	}
+
+	cir := &sc.CheckInReport{}
+	if err := cir.FromJSON(checkIn); err != nil {
+		return "", false
+	}
+
+	if cir.MimeType != v1.MimeTypeNativeReport {
+		return "", false
+	}
+
+	return models.JobFinished, true
+}
+
+// publishEvent publishes the event, it's replaced in the tests
+var publishEvent = func(e *event.Event) error {
+	return e.Publish()
+}
+
+// publishScanEvent publishes the image scan webhook event of the artifact in the job parameters
+func publishScanEvent(stats *jjob.StatsInfo, status string) error {
+	if stats == nil {
+		return errors.New("missing job metadata")
+	}
+
+	req, err := sc.ExtractScanReq(stats.Parameters)
+	if err != nil {
+		return err
+	}
+
+	e := &event.Event{}
+	metaData := &event.ScanImageMetaData{
+		Artifact: req.Artifact,
+		Status:   status,
+	}
+	if err := e.Build(metaData); err != nil {
+		return err
+	}
+
+	return publishEvent(e)
 }
 
 // HandleReplicationScheduleJob handles the webhook of replication schedule job
@@ -139,6 +185,14 @@ func (h *Handler) HandleReplicationScheduleJob() {
 
 // HandleReplicationTask handles the webhook of replication task
 func (h *Handler) HandleReplicationTask() {
+	// handle the checkin of the transfer stats
+	if h.checkIn != "" {
+		if err := hook.UpdateTaskTransferMode(replication.OperationCtl, h.id, h.checkIn); err != nil {
+			log.Errorf("failed to update the transfer mode of the replication task %d: %v", h.id, err)
+		}
+		return
+	}
+
 	log.Debugf("received replication task status update event: task-%d, status-%s", h.id, h.status)
 	if err := hook.UpdateTask(replication.OperationCtl, h.id, h.rawStatus, h.revision); err != nil {
 		log.Errorf("failed to update the status of the replication task %d: %v", h.id, err)
@@ -155,11 +209,17 @@ func (h *Handler) HandleRetentionTask() {
 	mgr := &retention.DefaultManager{}
 	// handle checkin
 	if h.checkIn != "" {
-		var retainObj struct {
-			Total    int `json:"total"`
-			Retained int `json:"retained"`
+		// the artifact deleted by the task, the failure of the publishing doesn't fail the hook
+		deletion := &retention.DeletionCheckIn{}
+		if err := json.Unmarshal([]byte(h.checkIn), deletion); err == nil && deletion.Deleted != nil {
+			if err := publishRetentionDeletion(mgr, taskID, deletion.Deleted); err != nil {
+				log.Errorf("failed to publish the artifact deleted event of retention task %d: %v", taskID, err)
+			}
+			return
 		}
-		if err := json.Unmarshal([]byte(h.checkIn), &retainObj); err != nil {
+
+		retainObj := &retention.SummaryCheckIn{}
+		if err := json.Unmarshal([]byte(h.checkIn), retainObj); err != nil {
 			log.Errorf("failed to resolve checkin of retention task %d: %v", taskID, err)
 			return
 		}
@@ -167,8 +227,14 @@ func (h *Handler) HandleRetentionTask() {
 			ID:       taskID,
 			Total:    retainObj.Total,
 			Retained: retainObj.Retained,
+			Summary:  retainObj.Summary,
+		}
+		cols := []string{"Total", "Retained"}
+		// the summary isn't checked in by the jobs of the legacy version
+		if retainObj.Summary != nil {
+			cols = append(cols, "Summary")
 		}
-		if err := mgr.UpdateTask(task, "Total", "Retained"); err != nil {
+		if err := mgr.UpdateTask(task, cols...); err != nil {
 			log.Errorf("failed to update of retention task %d: %v", taskID, err)
 			h.SendInternalServerError(err)
 			return
@@ -184,8 +250,52 @@ func (h *Handler) HandleRetentionTask() {
 	}
 }
 
+// publishRetentionDeletion publishes the artifact deleted event of the retention task
+func publishRetentionDeletion(mgr retention.Manager, taskID int64, deleted *retention.DeletedArtifact) error {
+	task, err := mgr.GetTask(taskID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return errors.Errorf("retention task %d not found", taskID)
+	}
+
+	// the artifacts deleted by the tasks submitted by the old launchers don't carry the project ID
+	var projectIDOrName interface{} = deleted.ProjectID
+	if deleted.ProjectID <= 0 {
+		projectIDOrName = deleted.Namespace
+	}
+	project, err := config.GlobalProjectMgr.Get(projectIDOrName)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return errors.Errorf("project %v not found", projectIDOrName)
+	}
+
+	e := &event.Event{}
+	if err := e.Build(&event.ArtifactDeletedMetaData{
+		Project:     project,
+		RepoName:    fmt.Sprintf("%s/%s", deleted.Namespace, deleted.Repository),
+		Tag:         deleted.Tag,
+		Digest:      deleted.Digest,
+		ExecutionID: task.ExecutionID,
+		OccurAt:     time.Unix(deleted.DeletedAt, 0),
+	}); err != nil {
+		return err
+	}
+
+	return publishEvent(e)
+}
+
 // HandleNotificationJob handles the hook of notification job
 func (h *Handler) HandleNotificationJob() {
+	// handle the checkin of the delivery result
+	if h.checkIn != "" {
+		h.recordWebhookDelivery()
+		return
+	}
+
 	log.Debugf("received notification job status update event: job-%d, status-%s", h.id, h.status)
 	if err := notification.JobMgr.Update(&models.NotificationJob{
 		ID:         h.id,
@@ -197,3 +307,49 @@ func (h *Handler) HandleNotificationJob() {
 		return
 	}
 }
+
+// recordWebhookDelivery records the delivery result checked in by the webhook job as the delivery log,
+// or as the detail of the job if it is a test delivery
+func (h *Handler) recordWebhookDelivery() {
+	var delivery struct {
+		StatusCode int   `json:"status_code"`
+		DurationMS int64 `json:"duration_ms"`
+		Success    bool  `json:"success"`
+	}
+	if err := json.Unmarshal([]byte(h.checkIn), &delivery); err != nil {
+		log.Errorf("failed to resolve checkin of notification job %d: %v", h.id, err)
+		return
+	}
+	job, err := notification.JobMgr.Get(h.id)
+	if err != nil {
+		log.Errorf("failed to get notification job %d: %v", h.id, err)
+		h.SendInternalServerError(err)
+		return
+	}
+	if job == nil {
+		log.Warningf("notification job %d not found, skip recording the delivery", h.id)
+		return
+	}
+	// the result of the test delivery is kept in the job record and read by the API waiting for it
+	if job.EventType == notifyModel.EventTypeTestEndpoint {
+		if err := notification.JobMgr.Update(&models.NotificationJob{
+			ID:        h.id,
+			JobDetail: h.checkIn,
+		}, "JobDetail"); err != nil {
+			log.Errorf("failed to record the test delivery of notification job %d: %v", h.id, err)
+			h.SendInternalServerError(err)
+		}
+		return
+	}
+	if _, err := notification.JobMgr.CreateDeliveryLog(&models.WebhookDeliveryLog{
+		PolicyID:   job.PolicyID,
+		JobID:      h.id,
+		StatusCode: delivery.StatusCode,
+		DurationMS: delivery.DurationMS,
+		Success:    delivery.Success,
+	}); err != nil {
+		log.Errorf("failed to record the delivery of notification job %d: %v", h.id, err)
+		h.SendInternalServerError(err)
+		return
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/astaxie/beego/context"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/notifier/event"
+	"github.com/goharbor/harbor/src/core/notifier/model"
+	"github.com/goharbor/harbor/src/core/promgr"
+	jjob "github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/pkg/retention"
+	sc "github.com/goharbor/harbor/src/pkg/scan"
+	"github.com/goharbor/harbor/src/pkg/scan/api/scan"
+	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakedScanController only handles the job hooks, the report is persisted once the hook is handled
+type fakedScanController struct {
+	scan.Controller
+	persisted bool
+	err       error
+}
+
+func (f *fakedScanController) HandleJobHooks(trackID string, change *jjob.StatusChange) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.persisted = true
+	return nil
+}
+
+// TestScanEventStatus tests the status of the scan webhook event triggered by the job hooks
+func TestScanEventStatus(t *testing.T) {
+	native := checkInJSON(t, v1.MimeTypeNativeReport)
+	sbom := checkInJSON(t, v1.MimeTypeSBOMCycloneDX)
+
+	cases := []struct {
+		status   string
+		checkIn  string
+		expected string
+		ok       bool
+	}{
+		{status: models.JobRunning, checkIn: native, expected: models.JobFinished, ok: true},
+		{status: models.JobRunning, checkIn: sbom},
+		{status: models.JobRunning, checkIn: `{"kind":"progress"}`},
+		{status: models.JobRunning, checkIn: "{"},
+		{status: models.JobRunning},
+		{status: models.JobFinished},
+		{status: models.JobError, expected: models.JobError, ok: true},
+	}
+
+	for _, c := range cases {
+		status, ok := scanEventStatus(c.status, c.checkIn)
+		assert.Equal(t, c.ok, ok, "status %s with check in %s", c.status, c.checkIn)
+		assert.Equal(t, c.expected, status, "status %s with check in %s", c.status, c.checkIn)
+	}
+}
+
+// TestHandleScanPublishFailure tests the failure of the event publishing doesn't fail the check in
+func TestHandleScanPublishFailure(t *testing.T) {
+	fc := &fakedScanController{}
+	c := scan.DefaultController
+	scan.DefaultController = fc
+	defer func() {
+		scan.DefaultController = c
+	}()
+
+	var published *event.Event
+	p := publishEvent
+	publishEvent = func(e *event.Event) error {
+		require.True(t, fc.persisted, "event is published before the report is persisted")
+		published = e
+		return errors.New("webhook is down")
+	}
+	defer func() {
+		publishEvent = p
+	}()
+
+	w := newScanHookHandler(t, models.JobRunning, checkInJSON(t, v1.MimeTypeNativeReport)).handle()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, published)
+	assert.Equal(t, model.ScanningCompletedTopic, published.Topic)
+}
+
+// TestHandleScanPersistFailure tests no event is published if the report isn't persisted
+func TestHandleScanPersistFailure(t *testing.T) {
+	c := scan.DefaultController
+	scan.DefaultController = &fakedScanController{err: errors.New("db is down")}
+	defer func() {
+		scan.DefaultController = c
+	}()
+
+	p := publishEvent
+	publishEvent = func(e *event.Event) error {
+		t.Fatal("event is published for the report which isn't persisted")
+		return nil
+	}
+	defer func() {
+		publishEvent = p
+	}()
+
+	w := newScanHookHandler(t, models.JobRunning, checkInJSON(t, v1.MimeTypeNativeReport)).handle()
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+type scanHookHandler struct {
+	*Handler
+}
+
+func newScanHookHandler(t *testing.T, status, checkIn string) *scanHookHandler {
+	params, err := json.Marshal(&v1.ScanRequest{
+		Registry: &v1.Registry{
+			URL:           "http://core:8080",
+			Authorization: "Bearer token",
+		},
+		Artifact: &v1.Artifact{
+			NamespaceID: 1,
+			Repository:  "library/redis",
+			Tag:         "latest",
+			Digest:      "digest-code",
+			MimeType:    v1.MimeTypeDockerArtifact,
+		},
+	})
+	require.NoError(t, err)
+
+	h := &Handler{
+		trackID: "the-uuid-123",
+		status:  status,
+		checkIn: checkIn,
+		change: &jjob.StatusChange{
+			JobID:   "the-job-id",
+			CheckIn: checkIn,
+			Metadata: &jjob.StatsInfo{
+				Parameters: jjob.Parameters{
+					sc.JobParameterRequest: string(params),
+				},
+			},
+		},
+	}
+
+	return &scanHookHandler{h}
+}
+
+func (s *scanHookHandler) handle() *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.Ctx = context.NewContext()
+	s.Ctx.Reset(w, httptest.NewRequest(http.MethodPost, "/service/notifications/jobs/scan/the-uuid-123", nil))
+	s.HandleScan()
+
+	return w
+}
+
+func checkInJSON(t *testing.T, mimeType string) string {
+	cir := &sc.CheckInReport{
+		Digest:           "digest-code",
+		RegistrationUUID: "reg-uuid",
+		MimeType:         mimeType,
+		RawReport:        "{}",
+	}
+	jsonData, err := cir.ToJSON()
+	require.NoError(t, err)
+
+	return jsonData
+}
+
+// fakedRetentionManager only returns the task
+type fakedRetentionManager struct {
+	retention.Manager
+	task *retention.Task
+}
+
+func (f *fakedRetentionManager) GetTask(taskID int64) (*retention.Task, error) {
+	return f.task, nil
+}
+
+// fakedProjectManager only returns the project
+type fakedProjectManager struct {
+	promgr.ProjectManager
+	project *models.Project
+}
+
+func (f *fakedProjectManager) Get(projectIDOrName interface{}) (*models.Project, error) {
+	return f.project, nil
+}
+
+// TestPublishRetentionDeletion tests the artifact deleted event carries the retention execution
+func TestPublishRetentionDeletion(t *testing.T) {
+	pm := config.GlobalProjectMgr
+	config.GlobalProjectMgr = &fakedProjectManager{project: &models.Project{ProjectID: 1, Name: "library"}}
+	defer func() {
+		config.GlobalProjectMgr = pm
+	}()
+
+	var published *event.Event
+	p := publishEvent
+	publishEvent = func(e *event.Event) error {
+		published = e
+		return nil
+	}
+	defer func() {
+		publishEvent = p
+	}()
+
+	deleted := &retention.DeletedArtifact{
+		ProjectID:  1,
+		Namespace:  "library",
+		Repository: "redis",
+		Tag:        "latest",
+		Digest:     "digest-code",
+	}
+	err := publishRetentionDeletion(&fakedRetentionManager{}, 1, deleted)
+	require.Error(t, err)
+	require.Nil(t, published)
+
+	mgr := &fakedRetentionManager{task: &retention.Task{ID: 1, ExecutionID: 2}}
+	require.NoError(t, publishRetentionDeletion(mgr, 1, deleted))
+	require.NotNil(t, published)
+	assert.Equal(t, model.ArtifactDeletedTopic, published.Topic)
+	data, ok := published.Data.(*model.ImageEvent)
+	require.True(t, ok)
+	assert.Equal(t, int64(2), data.RetentionExecutionID)
+	assert.Equal(t, "library/redis", data.RepoName)
+	assert.Equal(t, int64(1), data.Project.ProjectID)
+	require.Equal(t, 1, len(data.Resource))
+	assert.Equal(t, "latest", data.Resource[0].Tag)
+	assert.Equal(t, "digest-code", data.Resource[0].Digest)
+}
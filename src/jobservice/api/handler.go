@@ -15,11 +15,13 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -50,12 +52,18 @@ type Handler interface {
 	// HandleJobActionReq is used to handle the job action requests (stop/retry).
 	HandleJobActionReq(w http.ResponseWriter, req *http.Request)
 
+	// HandleJobScheduleUpdateReq is used to handle the request of updating the cron spec of the periodic job.
+	HandleJobScheduleUpdateReq(w http.ResponseWriter, req *http.Request)
+
 	// HandleCheckStatusReq is used to handle the job service healthy status checking request.
 	HandleCheckStatusReq(w http.ResponseWriter, req *http.Request)
 
 	// HandleJobLogReq is used to handle the request of getting job logs
 	HandleJobLogReq(w http.ResponseWriter, req *http.Request)
 
+	// HandleJobLogDeleteReq is used to handle the request of deleting job logs
+	HandleJobLogDeleteReq(w http.ResponseWriter, req *http.Request)
+
 	// HandleJobLogReq is used to handle the request of getting periodic executions
 	HandlePeriodicExecutions(w http.ResponseWriter, req *http.Request)
 
@@ -178,6 +186,43 @@ func (dh *DefaultHandler) HandleJobActionReq(w http.ResponseWriter, req *http.Re
 	w.WriteHeader(http.StatusNoContent) // only header, no content returned
 }
 
+// HandleJobScheduleUpdateReq is implementation of method defined in interface 'Handler'
+func (dh *DefaultHandler) HandleJobScheduleUpdateReq(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	jobID := vars["job_id"]
+
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.ReadRequestBodyError(err))
+		return
+	}
+
+	// unmarshal data
+	updateReq := &job.ScheduleUpdateRequest{}
+	if err = json.Unmarshal(data, updateReq); err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.HandleJSONDataError(err))
+		return
+	}
+
+	// Update the schedule
+	if err := dh.controller.UpdateJobSchedule(jobID, updateReq.CronSpec); err != nil {
+		code := http.StatusInternalServerError
+		if errs.IsObjectNotFoundError(err) {
+			code = http.StatusNotFound
+		} else if errs.IsBadRequestError(err) {
+			code = http.StatusBadRequest
+		} else {
+			err = errs.UpdateScheduleError(err)
+		}
+		dh.handleError(w, req, code, err)
+		return
+	}
+
+	dh.log(req, http.StatusNoContent, string(data))
+
+	w.WriteHeader(http.StatusNoContent) // only header, no content returned
+}
+
 // HandleCheckStatusReq is implementation of method defined in interface 'Handler'
 func (dh *DefaultHandler) HandleCheckStatusReq(w http.ResponseWriter, req *http.Request) {
 	stats, err := dh.controller.CheckStatus()
@@ -215,8 +260,37 @@ func (dh *DefaultHandler) HandleJobLogReq(w http.ResponseWriter, req *http.Reque
 
 	dh.log(req, http.StatusOK, "")
 
-	w.WriteHeader(http.StatusOK)
-	writeDate(w, logData)
+	// the Range header is honored so the clients can read the long log in parts
+	w.Header().Set("Content-Type", "text/plain")
+	http.ServeContent(w, req, "", time.Time{}, bytes.NewReader(logData))
+}
+
+// HandleJobLogDeleteReq is implementation of method defined in interface 'Handler'
+func (dh *DefaultHandler) HandleJobLogDeleteReq(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	jobID := vars["job_id"]
+
+	if strings.Contains(jobID, "..") || strings.ContainsRune(jobID, os.PathSeparator) {
+		dh.handleError(w, req, http.StatusBadRequest, errors.Errorf("invalid Job ID: %s", jobID))
+		return
+	}
+
+	if err := dh.controller.DeleteJobLog(jobID); err != nil {
+		code := http.StatusInternalServerError
+		if errs.IsObjectNotFoundError(err) {
+			code = http.StatusNotFound
+		} else if errs.IsBadRequestError(err) {
+			code = http.StatusBadRequest
+		} else {
+			err = errs.DeleteJobLogError(err)
+		}
+		dh.handleError(w, req, code, err)
+		return
+	}
+
+	dh.log(req, http.StatusNoContent, "")
+
+	w.WriteHeader(http.StatusNoContent) // only header, no content returned
 }
 
 // HandlePeriodicExecutions is implementation of method defined in interface 'Handler'
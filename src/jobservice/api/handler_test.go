@@ -194,6 +194,35 @@ func (suite *APIHandlerTestSuite) TestJobActionSucceed() {
 	assert.Equal(suite.T(), 204, code, "expected 204 no content but got %d", code)
 }
 
+// TestJobScheduleUpdate ...
+func (suite *APIHandlerTestSuite) TestJobScheduleUpdate() {
+	data, _ := json.Marshal(&job.ScheduleUpdateRequest{CronSpec: "0 0 * * * *"})
+
+	fc := &fakeController{}
+	fc.On("UpdateJobSchedule", "fake_job_ID", "0 0 * * * *").Return(nil)
+	suite.controller = fc
+	_, code := suite.patchReq(fmt.Sprintf("%s/%s", suite.APIAddr, "jobs/fake_job_ID"), data)
+	assert.Equal(suite.T(), 204, code, "expected 204 no content but got %d", code)
+
+	fc1 := &fakeController{}
+	fc1.On("UpdateJobSchedule", "fake_job_ID_not", "0 0 * * * *").Return(errs.NoObjectFoundError("fake_job_ID_not"))
+	suite.controller = fc1
+	_, code = suite.patchReq(fmt.Sprintf("%s/%s", suite.APIAddr, "jobs/fake_job_ID_not"), data)
+	assert.Equal(suite.T(), 404, code, "expected 404 not found but got %d", code)
+
+	fc2 := &fakeController{}
+	fc2.On("UpdateJobSchedule", "fake_job_ID", "0 0 * * * *").Return(errs.BadRequestError("invalid cron spec"))
+	suite.controller = fc2
+	_, code = suite.patchReq(fmt.Sprintf("%s/%s", suite.APIAddr, "jobs/fake_job_ID"), data)
+	assert.Equal(suite.T(), 400, code, "expected 400 bad request but got %d", code)
+
+	fc3 := &fakeController{}
+	fc3.On("UpdateJobSchedule", "fake_job_ID", "0 0 * * * *").Return(errors.New("testing error"))
+	suite.controller = fc3
+	_, code = suite.patchReq(fmt.Sprintf("%s/%s", suite.APIAddr, "jobs/fake_job_ID"), data)
+	assert.Equal(suite.T(), 500, code, "expected 500 internal server but got %d", code)
+}
+
 // TestCheckStatus ...
 func (suite *APIHandlerTestSuite) TestCheckStatus() {
 	statsRes := &worker.Stats{
@@ -240,6 +269,51 @@ func (suite *APIHandlerTestSuite) TestGetJobLog() {
 	assert.Equal(suite.T(), "hello log", string(resData))
 }
 
+// TestGetJobLogRange ...
+func (suite *APIHandlerTestSuite) TestGetJobLogRange() {
+	fc := &fakeController{}
+	fc.On("GetJobLogData", "fake_job_ID").Return([]byte("hello log"), nil)
+	suite.controller = fc
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s", suite.APIAddr, "jobs/fake_job_ID/log"), nil)
+	require.NoError(suite.T(), err)
+	req.Header.Set(authHeader, fmt.Sprintf("%s %s", secretPrefix, fakeSecret))
+	req.Header.Set("Range", "bytes=6-")
+
+	res, err := suite.client.Do(req)
+	require.NoError(suite.T(), err)
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	data, err := ioutil.ReadAll(res.Body)
+	require.NoError(suite.T(), err)
+
+	require.Equal(suite.T(), http.StatusPartialContent, res.StatusCode)
+	assert.Equal(suite.T(), "bytes 6-8/9", res.Header.Get("Content-Range"))
+	assert.Equal(suite.T(), "log", string(data))
+}
+
+// TestDeleteJobLog ...
+func (suite *APIHandlerTestSuite) TestDeleteJobLog() {
+	fc := &fakeController{}
+	fc.On("DeleteJobLog", "fake_job_ID").Return(nil)
+	suite.controller = fc
+	_, code := suite.deleteReq(fmt.Sprintf("%s/%s", suite.APIAddr, "jobs/fake_job_ID/log"))
+	assert.Equal(suite.T(), 204, code, "expected 204 no content but got %d", code)
+
+	fc1 := &fakeController{}
+	fc1.On("DeleteJobLog", "fake_job_ID_not").Return(errs.NoObjectFoundError("fake_job_ID_not"))
+	suite.controller = fc1
+	_, code = suite.deleteReq(fmt.Sprintf("%s/%s", suite.APIAddr, "jobs/fake_job_ID_not/log"))
+	assert.Equal(suite.T(), 404, code, "expected 404 not found but got %d", code)
+
+	fc2 := &fakeController{}
+	fc2.On("DeleteJobLog", "fake_job_ID").Return(errors.New("testing error"))
+	suite.controller = fc2
+	_, code = suite.deleteReq(fmt.Sprintf("%s/%s", suite.APIAddr, "jobs/fake_job_ID/log"))
+	assert.Equal(suite.T(), 500, code, "expected 500 internal server but got %d", code)
+}
+
 // TestGetPeriodicExecutionsWithoutQuery ...
 func (suite *APIHandlerTestSuite) TestGetPeriodicExecutionsWithoutQuery() {
 	q := &query.Parameter{
@@ -323,7 +397,22 @@ func (suite *APIHandlerTestSuite) createServer() {
 
 // postReq ...
 func (suite *APIHandlerTestSuite) postReq(url string, data []byte) ([]byte, int) {
-	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(string(data)))
+	return suite.sendReq(http.MethodPost, url, data)
+}
+
+// deleteReq ...
+func (suite *APIHandlerTestSuite) deleteReq(url string) ([]byte, int) {
+	return suite.sendReq(http.MethodDelete, url, nil)
+}
+
+// patchReq ...
+func (suite *APIHandlerTestSuite) patchReq(url string, data []byte) ([]byte, int) {
+	return suite.sendReq(http.MethodPatch, url, data)
+}
+
+// sendReq ...
+func (suite *APIHandlerTestSuite) sendReq(method string, url string, data []byte) ([]byte, int) {
+	req, err := http.NewRequest(method, url, strings.NewReader(string(data)))
 	if err != nil {
 		return nil, 0
 	}
@@ -394,6 +483,10 @@ func (suite *APIHandlerTestSuite) RetryJob(jobID string) error {
 	return suite.controller.RetryJob(jobID)
 }
 
+func (suite *APIHandlerTestSuite) UpdateJobSchedule(jobID string, cronSpec string) error {
+	return suite.controller.UpdateJobSchedule(jobID, cronSpec)
+}
+
 func (suite *APIHandlerTestSuite) CheckStatus() (*worker.Stats, error) {
 	return suite.controller.CheckStatus()
 }
@@ -402,6 +495,10 @@ func (suite *APIHandlerTestSuite) GetJobLogData(jobID string) ([]byte, error) {
 	return suite.controller.GetJobLogData(jobID)
 }
 
+func (suite *APIHandlerTestSuite) DeleteJobLog(jobID string) error {
+	return suite.controller.DeleteJobLog(jobID)
+}
+
 func (suite *APIHandlerTestSuite) GetPeriodicExecutions(periodicJobID string, query *query.Parameter) ([]*job.Stats, int64, error) {
 	return suite.controller.GetPeriodicExecutions(periodicJobID, query)
 }
@@ -442,6 +539,11 @@ func (fc *fakeController) RetryJob(jobID string) error {
 	return args.Error(0)
 }
 
+func (fc *fakeController) UpdateJobSchedule(jobID string, cronSpec string) error {
+	args := fc.Called(jobID, cronSpec)
+	return args.Error(0)
+}
+
 func (fc *fakeController) CheckStatus() (*worker.Stats, error) {
 	args := fc.Called()
 	if args.Error(1) != nil {
@@ -460,6 +562,11 @@ func (fc *fakeController) GetJobLogData(jobID string) ([]byte, error) {
 	return args.Get(0).([]byte), nil
 }
 
+func (fc *fakeController) DeleteJobLog(jobID string) error {
+	args := fc.Called(jobID)
+	return args.Error(0)
+}
+
 func (fc *fakeController) GetPeriodicExecutions(periodicJobID string, query *query.Parameter) ([]*job.Stats, int64, error) {
 	args := fc.Called(periodicJobID, query)
 	if args.Error(2) != nil {
@@ -91,7 +91,9 @@ func (br *BaseRouter) registerRoutes() {
 	subRouter.HandleFunc("/jobs", br.handler.HandleGetJobsReq).Methods(http.MethodGet)
 	subRouter.HandleFunc("/jobs/{job_id}", br.handler.HandleGetJobReq).Methods(http.MethodGet)
 	subRouter.HandleFunc("/jobs/{job_id}", br.handler.HandleJobActionReq).Methods(http.MethodPost)
+	subRouter.HandleFunc("/jobs/{job_id}", br.handler.HandleJobScheduleUpdateReq).Methods(http.MethodPatch)
 	subRouter.HandleFunc("/jobs/{job_id}/log", br.handler.HandleJobLogReq).Methods(http.MethodGet)
+	subRouter.HandleFunc("/jobs/{job_id}/log", br.handler.HandleJobLogDeleteReq).Methods(http.MethodDelete)
 	subRouter.HandleFunc("/stats", br.handler.HandleCheckStatusReq).Methods(http.MethodGet)
 	subRouter.HandleFunc("/jobs/{job_id}/executions", br.handler.HandlePeriodicExecutions).Methods(http.MethodGet)
 }
@@ -16,6 +16,7 @@ package core
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/robfig/cron"
@@ -63,6 +64,16 @@ func (bc *basicController) LaunchJob(req *job.Request) (res *job.Stats, err erro
 		return nil, errs.BadRequestError(err)
 	}
 
+	// The max fails of the request is passed to the runner along with the parameters
+	if req.Job.Metadata.MaxFails > 0 {
+		params := make(job.Parameters, len(req.Job.Parameters)+1)
+		for k, v := range req.Job.Parameters {
+			params[k] = v
+		}
+		params[job.ParamMaxFails] = req.Job.Metadata.MaxFails
+		req.Job.Parameters = params
+	}
+
 	// Enqueue job regarding of the kind
 	switch req.Job.Metadata.JobKind {
 	case job.KindScheduled:
@@ -78,6 +89,7 @@ func (bc *basicController) LaunchJob(req *job.Request) (res *job.Stats, err erro
 			req.Job.Name,
 			req.Job.Parameters,
 			req.Job.Metadata.Cron,
+			req.Job.Metadata.Timezone,
 			req.Job.Metadata.IsUnique,
 			req.Job.StatusHook,
 		)
@@ -127,6 +139,23 @@ func (bc *basicController) RetryJob(jobID string) error {
 	return bc.backendWorker.RetryJob(jobID)
 }
 
+// UpdateJobSchedule is implementation of same method in core interface.
+func (bc *basicController) UpdateJobSchedule(jobID string, cronSpec string) error {
+	if utils.IsEmptyStr(jobID) {
+		return errs.BadRequestError(errors.New("empty job ID"))
+	}
+
+	if utils.IsEmptyStr(cronSpec) {
+		return errs.BadRequestError(errors.New("empty cron spec"))
+	}
+
+	if _, err := cron.Parse(cronSpec); err != nil {
+		return errs.BadRequestError(errors.Errorf("'cron_spec' is not correctly set: %s: %s", cronSpec, err))
+	}
+
+	return bc.backendWorker.UpdateSchedule(jobID, cronSpec)
+}
+
 // GetJobLogData is used to return the log text data for the specified job if exists
 func (bc *basicController) GetJobLogData(jobID string) ([]byte, error) {
 	if utils.IsEmptyStr(jobID) {
@@ -141,6 +170,15 @@ func (bc *basicController) GetJobLogData(jobID string) ([]byte, error) {
 	return logData, nil
 }
 
+// DeleteJobLog is used to delete the log data of the specified job if exists
+func (bc *basicController) DeleteJobLog(jobID string) error {
+	if utils.IsEmptyStr(jobID) {
+		return errs.BadRequestError(errors.New("empty job ID"))
+	}
+
+	return logger.Delete(jobID)
+}
+
 // CheckStatus is implementation of same method in core interface.
 func (bc *basicController) CheckStatus() (*worker.Stats, error) {
 	return bc.backendWorker.Stats()
@@ -210,6 +248,16 @@ func validJobReq(req *job.Request) error {
 		if _, err := cron.Parse(req.Job.Metadata.Cron); err != nil {
 			return fmt.Errorf("'cron_spec' is not correctly set: %s: %s", req.Job.Metadata.Cron, err)
 		}
+
+		if !utils.IsEmptyStr(req.Job.Metadata.Timezone) {
+			if _, err := time.LoadLocation(req.Job.Metadata.Timezone); err != nil {
+				return fmt.Errorf("'timezone' is not correctly set: %s: %s", req.Job.Metadata.Timezone, err)
+			}
+		}
+	}
+
+	if req.Job.Metadata.MaxFails > job.MaxFailsLimit {
+		return errors.Errorf("'max_fails' can not be greater than %d", job.MaxFailsLimit)
 	}
 
 	return nil
@@ -16,6 +16,7 @@ package core
 import (
 	"github.com/goharbor/harbor/src/jobservice/common/query"
 	"github.com/goharbor/harbor/src/jobservice/common/utils"
+	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/jobservice/job/impl/sample"
 	"github.com/goharbor/harbor/src/jobservice/worker"
@@ -101,13 +102,35 @@ func (suite *ControllerTestSuite) TestLaunchScheduledJob() {
 func (suite *ControllerTestSuite) TestLaunchPeriodicJob() {
 	req := createJobReq("Periodic")
 
-	suite.worker.On("PeriodicallyEnqueue", job.SampleJob, suite.params, "5 * * * * *", true, req.Job.StatusHook).Return(suite.res, nil)
+	suite.worker.On("PeriodicallyEnqueue", job.SampleJob, suite.params, "5 * * * * *", "", true, req.Job.StatusHook).Return(suite.res, nil)
 
 	res, err := suite.ctl.LaunchJob(req)
 	require.Nil(suite.T(), err, "launch periodic job: nil error expected but got %s", err)
 	assert.Equal(suite.T(), suite.jobID, res.Info.JobID, "mismatch job ID")
 }
 
+// TestLaunchJobMaxFails tests the max fails of the request is passed along with the parameters
+func (suite *ControllerTestSuite) TestLaunchJobMaxFails() {
+	req := createJobReq("Generic")
+	req.Job.Metadata.MaxFails = 2
+
+	params := make(job.Parameters)
+	params["name"] = "testing:v1"
+	params[job.ParamMaxFails] = uint(2)
+	suite.worker.On("Enqueue", job.SampleJob, params, true, req.Job.StatusHook).Return(suite.res, nil)
+
+	res, err := suite.ctl.LaunchJob(req)
+	require.Nil(suite.T(), err, "launch job: nil error expected but got %s", err)
+	assert.Equal(suite.T(), suite.jobID, res.Info.JobID, "mismatch job ID")
+	// the parameters of the request are left untouched
+	assert.Equal(suite.T(), suite.params, createJobReq("Generic").Job.Parameters)
+
+	req = createJobReq("Generic")
+	req.Job.Metadata.MaxFails = job.MaxFailsLimit + 1
+	_, err = suite.ctl.LaunchJob(req)
+	assert.NotNil(suite.T(), err, "too many max fails: error expected but got nil")
+}
+
 // TestGetJobStats ...
 func (suite *ControllerTestSuite) TestGetJobStats() {
 	res, err := suite.ctl.GetJob(suite.jobID)
@@ -126,6 +149,21 @@ func (suite *ControllerTestSuite) TestJobActions() {
 	assert.Nil(suite.T(), err, "job action: nil error expected but got %s", err)
 }
 
+// TestUpdateJobSchedule ...
+func (suite *ControllerTestSuite) TestUpdateJobSchedule() {
+	suite.worker.On("UpdateSchedule", suite.jobID, "0 0 * * * *").Return(nil)
+
+	err := suite.ctl.UpdateJobSchedule(suite.jobID, "0 0 * * * *")
+	assert.Nil(suite.T(), err, "update job schedule: nil error expected but got %s", err)
+
+	err = suite.ctl.UpdateJobSchedule(suite.jobID, "invalid")
+	assert.Error(suite.T(), err, "update job schedule: error expected for invalid cron spec")
+	assert.True(suite.T(), errs.IsBadRequestError(err), "update job schedule: bad request error expected")
+
+	err = suite.ctl.UpdateJobSchedule("", "0 0 * * * *")
+	assert.Error(suite.T(), err, "update job schedule: error expected for empty job ID")
+}
+
 // TestCheckStatus ...
 func (suite *ControllerTestSuite) TestCheckStatus() {
 	suite.worker.On("Stats").Return(&worker.Stats{
@@ -241,8 +279,8 @@ func (suite *ControllerTestSuite) Schedule(jobName string, params job.Parameters
 	return suite.worker.Schedule(jobName, params, runAfterSeconds, isUnique, webHook)
 }
 
-func (suite *ControllerTestSuite) PeriodicallyEnqueue(jobName string, params job.Parameters, cronSetting string, isUnique bool, webHook string) (*job.Stats, error) {
-	return suite.worker.PeriodicallyEnqueue(jobName, params, cronSetting, isUnique, webHook)
+func (suite *ControllerTestSuite) PeriodicallyEnqueue(jobName string, params job.Parameters, cronSetting string, timezone string, isUnique bool, webHook string) (*job.Stats, error) {
+	return suite.worker.PeriodicallyEnqueue(jobName, params, cronSetting, timezone, isUnique, webHook)
 }
 
 func (suite *ControllerTestSuite) Stats() (*worker.Stats, error) {
@@ -265,6 +303,10 @@ func (suite *ControllerTestSuite) RetryJob(jobID string) error {
 	return suite.worker.RetryJob(jobID)
 }
 
+func (suite *ControllerTestSuite) UpdateSchedule(jobID string, cronSpec string) error {
+	return suite.worker.UpdateSchedule(jobID, cronSpec)
+}
+
 // Implement manager interface
 func (suite *ControllerTestSuite) GetJobs(q *query.Parameter) ([]*job.Stats, int64, error) {
 	return suite.manager.GetJobs(q)
@@ -317,8 +359,8 @@ func (f *fakeWorker) Schedule(jobName string, params job.Parameters, runAfterSec
 	return args.Get(0).(*job.Stats), nil
 }
 
-func (f *fakeWorker) PeriodicallyEnqueue(jobName string, params job.Parameters, cronSetting string, isUnique bool, webHook string) (*job.Stats, error) {
-	args := f.Called(jobName, params, cronSetting, isUnique, webHook)
+func (f *fakeWorker) PeriodicallyEnqueue(jobName string, params job.Parameters, cronSetting string, timezone string, isUnique bool, webHook string) (*job.Stats, error) {
+	args := f.Called(jobName, params, cronSetting, timezone, isUnique, webHook)
 	if args.Error(1) != nil {
 		return nil, args.Error(1)
 	}
@@ -356,6 +398,10 @@ func (f *fakeWorker) RetryJob(jobID string) error {
 	return f.Called(jobID).Error(0)
 }
 
+func (f *fakeWorker) UpdateSchedule(jobID string, cronSpec string) error {
+	return f.Called(jobID, cronSpec).Error(0)
+}
+
 // fake manager
 type fakeManager struct {
 	mock.Mock
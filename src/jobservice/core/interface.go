@@ -57,12 +57,24 @@ type Interface interface {
 	//  error   : Error returned if failed to retry the specified job.
 	RetryJob(jobID string) error
 
+	// UpdateJobSchedule is used to update the cron spec of the periodic job without recreating it.
+	//
+	// jobID	string    : ID of the periodic job.
+	// cronSpec	string    : the new cron spec.
+	//
+	// Return:
+	//  error   : Error returned if failed to update the schedule of the specified job.
+	UpdateJobSchedule(jobID string, cronSpec string) error
+
 	// CheckStatus is used to handle the job service healthy status checking request.
 	CheckStatus() (*worker.Stats, error)
 
 	// GetJobLogData is used to return the log text data for the specified job if exists
 	GetJobLogData(jobID string) ([]byte, error)
 
+	// DeleteJobLog is used to delete the log data of the specified job if exists
+	DeleteJobLog(jobID string) error
+
 	// Get the periodic executions for the specified periodic job.
 	// Pagination by query is supported.
 	// The total number is also returned.
@@ -56,6 +56,10 @@ const (
 	GetPeriodicExecutionErrorCode
 	// StatusMismatchErrorCode is code for the error of mismatching status
 	StatusMismatchErrorCode
+	// UpdateScheduleErrorCode is code for the error of updating the schedule of periodic job
+	UpdateScheduleErrorCode
+	// DeleteJobLogErrorCode is code for the error of deleting job log
+	DeleteJobLogErrorCode
 )
 
 // baseError ...
@@ -118,6 +122,11 @@ func StopJobError(err error) error {
 	return New(StopJobErrorCode, "stop job failed with error", err.Error())
 }
 
+// UpdateScheduleError is error for the case of updating the schedule of periodic job failed
+func UpdateScheduleError(err error) error {
+	return New(UpdateScheduleErrorCode, "update schedule of job failed with error", err.Error())
+}
+
 // RetryJobError is error for the case of retrying job failed
 func RetryJobError(err error) error {
 	return New(RetryJobErrorCode, "retry job failed with error", err.Error())
@@ -133,6 +142,11 @@ func GetJobLogError(err error) error {
 	return New(GetJobLogErrorCode, "failed to get the job log", err.Error())
 }
 
+// DeleteJobLogError is error for the case of deleting job log failed
+func DeleteJobLogError(err error) error {
+	return New(DeleteJobLogErrorCode, "failed to delete the job log", err.Error())
+}
+
 // UnauthorizedError is error for the case of unauthorized accessing
 func UnauthorizedError(err error) error {
 	return New(UnAuthorizedErrorCode, "unauthorized", err.Error())
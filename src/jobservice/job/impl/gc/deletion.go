@@ -0,0 +1,130 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/goharbor/harbor/src/jobservice/logger"
+)
+
+const (
+	// workersParam is the parameter controlling how many blobs are deleted concurrently
+	workersParam = "workers"
+	// defaultWorkers is the count of the workers if it isn't specified
+	defaultWorkers = 4
+	// maxWorkers is the max count of the workers
+	maxWorkers = 20
+	// deleteUntaggedParam is the parameter controlling whether the untagged manifests are deleted
+	deleteUntaggedParam = "delete_untagged"
+	// readOnlyParam is the parameter controlling whether the registry is read only during GC
+	readOnlyParam = "read_only"
+)
+
+// blobDeleter deletes the blob specified by the digest
+type blobDeleter func(digest string) error
+
+// deleteBlobs deletes the blobs with a pool of workers receiving the digests from a channel,
+// a failure doesn't stop the deletion of the other blobs, all of them are aggregated into
+// the returned error
+func deleteBlobs(log logger.Interface, digests []string, workers int, del blobDeleter) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		failed   int
+		firstErr error
+	)
+	ch := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			succeeded, fails := 0, 0
+			for digest := range ch {
+				if err := del(digest); err != nil {
+					fails++
+					lock.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("failed to delete %s: %v", digest, err)
+					}
+					lock.Unlock()
+					continue
+				}
+				succeeded++
+			}
+			log.Infof("GC worker %d: %d blobs deleted, %d failed.", worker, succeeded, fails)
+
+			lock.Lock()
+			failed += fails
+			lock.Unlock()
+		}(i)
+	}
+
+	for _, digest := range digests {
+		ch <- digest
+	}
+	close(ch)
+	wg.Wait()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d blobs failed to be deleted, the first error: %v", failed, len(digests), firstErr)
+	}
+	return nil
+}
+
+// parseWorkers parses the count of the workers from the job parameters, the JSON numbers
+// are decoded as float64
+func parseWorkers(params map[string]interface{}) (int, error) {
+	v, exist := params[workersParam]
+	if !exist {
+		return defaultWorkers, nil
+	}
+	var workers int
+	switch n := v.(type) {
+	case float64:
+		if n != float64(int(n)) {
+			return 0, fmt.Errorf("invalid %s: %v, it must be an integer", workersParam, v)
+		}
+		workers = int(n)
+	case int:
+		workers = n
+	case int64:
+		workers = int(n)
+	default:
+		return 0, fmt.Errorf("invalid %s: %v, it must be an integer", workersParam, v)
+	}
+	if workers < 1 || workers > maxWorkers {
+		return 0, fmt.Errorf("invalid %s: %d, it must be between 1 and %d", workersParam, workers, maxWorkers)
+	}
+	return workers, nil
+}
+
+// parseBool returns the bool parameter, or the default value if it isn't specified
+func parseBool(params map[string]interface{}, key string, defaultValue bool) (bool, error) {
+	v, exist := params[key]
+	if !exist {
+		return defaultValue, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("invalid %s: %v, it must be a boolean", key, v)
+	}
+	return b, nil
+}
@@ -0,0 +1,153 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/logger/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry mocks the registry deleting the blobs, each deletion takes the latency
+type fakeRegistry struct {
+	lock    sync.Mutex
+	latency time.Duration
+	deleted map[string]bool
+	broken  map[string]bool
+}
+
+func newFakeRegistry(latency time.Duration) *fakeRegistry {
+	return &fakeRegistry{
+		latency: latency,
+		deleted: map[string]bool{},
+		broken:  map[string]bool{},
+	}
+}
+
+func (f *fakeRegistry) deleteBlob(digest string) error {
+	time.Sleep(f.latency)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.broken[digest] {
+		return errors.New("internal error")
+	}
+	f.deleted[digest] = true
+	return nil
+}
+
+func digests(n int) []string {
+	result := make([]string, n)
+	for i := 0; i < n; i++ {
+		result[i] = fmt.Sprintf("sha256:%064d", i)
+	}
+	return result
+}
+
+func TestDeleteBlobs(t *testing.T) {
+	log := backend.NewStdOutputLogger("ERROR", backend.StdErr, 4)
+	blobs := digests(100)
+
+	registry := newFakeRegistry(0)
+	require.Nil(t, deleteBlobs(log, blobs, 4, registry.deleteBlob))
+	assert.Equal(t, 100, len(registry.deleted))
+
+	// the failures don't stop the deletion of the other blobs
+	registry = newFakeRegistry(0)
+	registry.broken[blobs[10]] = true
+	registry.broken[blobs[20]] = true
+	err := deleteBlobs(log, blobs, 4, registry.deleteBlob)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "2 of 100 blobs failed to be deleted")
+	assert.Equal(t, 98, len(registry.deleted))
+
+	// at least one worker runs
+	registry = newFakeRegistry(0)
+	require.Nil(t, deleteBlobs(log, blobs, 0, registry.deleteBlob))
+	assert.Equal(t, 100, len(registry.deleted))
+}
+
+func TestParseWorkers(t *testing.T) {
+	cases := []struct {
+		params  map[string]interface{}
+		workers int
+		isErr   bool
+	}{
+		{params: map[string]interface{}{}, workers: defaultWorkers},
+		{params: map[string]interface{}{workersParam: float64(8)}, workers: 8},
+		{params: map[string]interface{}{workersParam: 20}, workers: 20},
+		{params: map[string]interface{}{workersParam: float64(0)}, isErr: true},
+		{params: map[string]interface{}{workersParam: float64(21)}, isErr: true},
+		{params: map[string]interface{}{workersParam: 1.5}, isErr: true},
+		{params: map[string]interface{}{workersParam: "4"}, isErr: true},
+	}
+	for _, c := range cases {
+		workers, err := parseWorkers(c.params)
+		if c.isErr {
+			assert.NotNil(t, err)
+			continue
+		}
+		require.Nil(t, err)
+		assert.Equal(t, c.workers, workers)
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	cases := []struct {
+		params map[string]interface{}
+		value  bool
+		isErr  bool
+	}{
+		{params: map[string]interface{}{}, value: true},
+		{params: map[string]interface{}{readOnlyParam: false}, value: false},
+		{params: map[string]interface{}{readOnlyParam: true}, value: true},
+		{params: map[string]interface{}{readOnlyParam: "ture"}, isErr: true},
+		{params: map[string]interface{}{readOnlyParam: float64(1)}, isErr: true},
+	}
+	for _, c := range cases {
+		value, err := parseBool(c.params, readOnlyParam, true)
+		if c.isErr {
+			assert.NotNil(t, err)
+			continue
+		}
+		require.Nil(t, err)
+		assert.Equal(t, c.value, value)
+	}
+}
+
+func benchmarkDeleteBlobs(b *testing.B, workers int) {
+	log := backend.NewStdOutputLogger("ERROR", backend.StdErr, 4)
+	blobs := digests(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		registry := newFakeRegistry(100 * time.Microsecond)
+		if err := deleteBlobs(log, blobs, workers, registry.deleteBlob); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDeleteBlobsSequential(b *testing.B) {
+	benchmarkDeleteBlobs(b, 1)
+}
+
+func BenchmarkDeleteBlobs4Workers(b *testing.B) {
+	benchmarkDeleteBlobs(b, 4)
+}
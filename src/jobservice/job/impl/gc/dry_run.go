@@ -0,0 +1,112 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/jobservice/job"
+)
+
+const (
+	// dryRunParam is the parameter controlling whether the blobs eligible for deletion are only reported
+	dryRunParam = "dry_run"
+	// the prefix of the lines listing the blobs eligible for deletion in the output of registry GC
+	eligibleBlobPrefix = "blob eligible for deletion: "
+	// the prefix of the lines listing the manifests eligible for deletion in the output of registry GC
+	eligibleManifestPrefix = "manifest eligible for deletion: "
+)
+
+// dryRunSummary is the summary of the dry run, it's checked in to be persisted on the admin job
+type dryRunSummary struct {
+	DryRun    bool  `json:"dry_run"`
+	BlobCount int   `json:"blob_count"`
+	BlobSize  int64 `json:"blob_size"`
+}
+
+// blobSizer returns the size of the blob specified by the digest
+type blobSizer func(digest string) (int64, error)
+
+func blobSizeInDB(digest string) (int64, error) {
+	blob, err := dao.GetBlob(digest)
+	if err != nil {
+		return 0, err
+	}
+	return blob.Size, nil
+}
+
+// dryRunGC enumerates the blobs eligible for deletion without deleting anything, the registry isn't
+// set to read only and the cache isn't cleaned as nothing changes
+func (gc *GarbageCollector) dryRunGC(ctx job.Context) error {
+	gc.logger.Infof("start to run gc in dry run mode, nothing will be deleted.")
+	gcr, err := gc.registryCtlClient.DryRunGC(gc.deleteUntagged)
+	if err != nil {
+		gc.logger.Errorf("failed to get gc result: %v", err)
+		return err
+	}
+
+	summary := &dryRunSummary{DryRun: true}
+	for _, digest := range eligibleBlobs(gcr.Msg) {
+		size, err := gc.blobSize(digest)
+		if err != nil {
+			// the size is unknown, the blob is still counted
+			gc.logger.Warningf("failed to get the size of blob %s: %v", digest, err)
+		}
+		gc.logger.Infof("blob eligible for deletion: %s, size: %d", digest, size)
+		summary.BlobCount++
+		summary.BlobSize += size
+	}
+	gc.logger.Infof("GC dry run results: %d blobs eligible for deletion, total size: %d bytes, start: %s, end: %s.",
+		summary.BlobCount, summary.BlobSize, gcr.StartTime, gcr.EndTime)
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Checkin(string(data)); err != nil {
+		gc.logger.Errorf("failed to check in the summary of gc dry run: %v", err)
+		return err
+	}
+	gc.logger.Infof("success to run gc in dry run mode.")
+	return nil
+}
+
+// eligibleBlobs parses the digests of the blobs eligible for deletion from the output of registry GC
+func eligibleBlobs(output string) []string {
+	return eligible(output, eligibleBlobPrefix)
+}
+
+// eligible parses the digests listed with the prefix from the output of registry GC
+func eligible(output, prefix string) []string {
+	digests := []string{}
+	found := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		digest := strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		if len(digest) == 0 || found[digest] {
+			continue
+		}
+		found[digest] = true
+		digests = append(digests, digest)
+	}
+	return digests
+}
@@ -0,0 +1,125 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/job/impl"
+	"github.com/goharbor/harbor/src/jobservice/logger/backend"
+	"github.com/goharbor/harbor/src/registryctl/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistryCtlClient mocks the registry controller, the candidates are deleted from the registry
+// by the GC and only listed in the output by the dry run
+type fakeRegistryCtlClient struct {
+	registry   *fakeRegistry
+	candidates []string
+}
+
+func (f *fakeRegistryCtlClient) Health() error {
+	return nil
+}
+
+func (f *fakeRegistryCtlClient) StartGC(deleteUntagged bool) (*api.GCResult, error) {
+	for _, digest := range f.candidates {
+		if err := f.registry.deleteBlob(digest); err != nil {
+			return nil, err
+		}
+	}
+	return &api.GCResult{Status: true, Msg: f.output(), StartTime: time.Now(), EndTime: time.Now()}, nil
+}
+
+func (f *fakeRegistryCtlClient) DryRunGC(deleteUntagged bool) (*api.GCResult, error) {
+	return &api.GCResult{Status: true, Msg: f.output(), StartTime: time.Now(), EndTime: time.Now()}, nil
+}
+
+// output mimics the output of registry GC
+func (f *fakeRegistryCtlClient) output() string {
+	lines := []string{
+		"library/hello-world: marking manifest sha256:92c7f9c92844bbbb5d0a101b22f7c2a7949e40f8ea90c8b3bc396879d95e899a",
+		"library/hello-world: marking blob sha256:1b930d010525941c1d56ec53b97bd057a67ae1865eebf042686d2a2d18271ced",
+		"",
+		fmt.Sprintf("2 blobs marked, %d blobs and 0 manifests eligible for deletion", len(f.candidates)),
+	}
+	for _, digest := range f.candidates {
+		lines = append(lines, eligibleBlobPrefix+digest)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type fakedJobContext struct {
+	impl.Context
+	checkIns []string
+}
+
+func (f *fakedJobContext) Checkin(status string) error {
+	f.checkIns = append(f.checkIns, status)
+	return nil
+}
+
+func TestDryRunGC(t *testing.T) {
+	blobs := digests(3)
+	registry := newFakeRegistry(0)
+	gc := &GarbageCollector{
+		registryCtlClient: &fakeRegistryCtlClient{
+			registry:   registry,
+			candidates: blobs,
+		},
+		logger: backend.NewStdOutputLogger("ERROR", backend.StdErr, 4),
+		blobSize: func(digest string) (int64, error) {
+			if digest == blobs[2] {
+				return 0, errors.New("internal error")
+			}
+			return 1024, nil
+		},
+	}
+	ctx := &fakedJobContext{}
+	require.Nil(t, gc.dryRunGC(ctx))
+
+	// nothing is deleted
+	assert.Empty(t, registry.deleted)
+
+	// the summary is checked in to be stored, the blob whose size is unknown is still counted
+	require.Equal(t, 1, len(ctx.checkIns))
+	summary := &dryRunSummary{}
+	require.Nil(t, json.Unmarshal([]byte(ctx.checkIns[0]), summary))
+	assert.Equal(t, &dryRunSummary{DryRun: true, BlobCount: 3, BlobSize: 2048}, summary)
+}
+
+func TestEligibleBlobs(t *testing.T) {
+	blobs := digests(2)
+	client := &fakeRegistryCtlClient{candidates: blobs}
+	assert.Equal(t, blobs, eligibleBlobs(client.output()))
+
+	// the duplicated ones are counted once
+	output := client.output() + "\n" + eligibleBlobPrefix + blobs[0] + "\n"
+	assert.Equal(t, blobs, eligibleBlobs(output))
+
+	assert.Empty(t, eligibleBlobs("0 blobs marked, 0 blobs and 0 manifests eligible for deletion"))
+}
+
+func TestValidateDryRun(t *testing.T) {
+	gc := &GarbageCollector{}
+	assert.Nil(t, gc.Validate(map[string]interface{}{dryRunParam: true}))
+	assert.NotNil(t, gc.Validate(map[string]interface{}{dryRunParam: "true"}))
+}
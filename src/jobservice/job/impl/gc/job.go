@@ -47,6 +47,11 @@ type GarbageCollector struct {
 	cfgMgr            *config.CfgManager
 	CoreURL           string
 	redisURL          string
+	workers           int
+	deleteUntagged    bool
+	readOnly          bool
+	dryRun            bool
+	blobSize          blobSizer
 }
 
 // MaxFails implements the interface in job/Interface
@@ -61,6 +66,14 @@ func (gc *GarbageCollector) ShouldRetry() bool {
 
 // Validate implements the interface in job/Interface
 func (gc *GarbageCollector) Validate(params job.Parameters) error {
+	if _, err := parseWorkers(params); err != nil {
+		return err
+	}
+	for _, key := range []string{deleteUntaggedParam, readOnlyParam, dryRunParam} {
+		if _, err := parseBool(params, key, true); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -69,33 +82,48 @@ func (gc *GarbageCollector) Run(ctx job.Context, params job.Parameters) error {
 	if err := gc.init(ctx, params); err != nil {
 		return err
 	}
-	readOnlyCur, err := gc.getReadOnly()
-	if err != nil {
-		return err
+	if gc.dryRun {
+		if err := gc.registryCtlClient.Health(); err != nil {
+			gc.logger.Errorf("failed to start gc as registry controller is unreachable: %v", err)
+			return err
+		}
+		return gc.dryRunGC(ctx)
 	}
-	if readOnlyCur != true {
-		if err := gc.setReadOnly(true); err != nil {
+	if gc.readOnly {
+		readOnlyCur, err := gc.getReadOnly()
+		if err != nil {
 			return err
 		}
-		defer gc.setReadOnly(readOnlyCur)
+		if readOnlyCur != true {
+			if err := gc.setReadOnly(true); err != nil {
+				return err
+			}
+			defer gc.setReadOnly(readOnlyCur)
+		}
+	} else {
+		gc.logger.Warningf("the registry isn't set to read only during gc, the images pushed meanwhile may be corrupted.")
 	}
 	if err := gc.registryCtlClient.Health(); err != nil {
 		gc.logger.Errorf("failed to start gc as registry controller is unreachable: %v", err)
 		return err
 	}
 	gc.logger.Infof("start to run gc in job.")
-	gcr, err := gc.registryCtlClient.StartGC()
+	gcr, err := gc.registryCtlClient.StartGC(gc.deleteUntagged)
 	if err != nil {
 		gc.logger.Errorf("failed to get gc result: %v", err)
 		return err
 	}
+	// the sizes of the deleted blobs are got before the quota alignment removes them from the database
+	stats := gc.collectStats(gcr)
 	if err := gc.cleanCache(); err != nil {
 		return err
 	}
 	if err := gc.ensureQuota(); err != nil {
 		gc.logger.Warningf("failed to align quota data in gc job, with error: %v", err)
+		stats.Errors++
 	}
 	gc.logger.Infof("GC results: status: %t, message: %s, start: %s, end: %s.", gcr.Status, gcr.Msg, gcr.StartTime, gcr.EndTime)
+	gc.checkInStats(ctx, stats)
 	gc.logger.Infof("success to run gc in job.")
 	return nil
 }
@@ -103,6 +131,7 @@ func (gc *GarbageCollector) Run(ctx job.Context, params job.Parameters) error {
 func (gc *GarbageCollector) init(ctx job.Context, params job.Parameters) error {
 	registryctl.Init()
 	gc.registryCtlClient = registryctl.RegistryCtlClient
+	gc.blobSize = blobSizeInDB
 	gc.logger = ctx.GetLogger()
 
 	errTpl := "failed to get required property: %s"
@@ -115,6 +144,20 @@ func (gc *GarbageCollector) init(ctx job.Context, params job.Parameters) error {
 	configURL := gc.CoreURL + common.CoreConfigPath
 	gc.cfgMgr = config.NewRESTCfgManager(configURL, secret)
 	gc.redisURL = params["redis_url_reg"].(string)
+	workers, err := parseWorkers(params)
+	if err != nil {
+		return err
+	}
+	gc.workers = workers
+	if gc.deleteUntagged, err = parseBool(params, deleteUntaggedParam, true); err != nil {
+		return err
+	}
+	if gc.readOnly, err = parseBool(params, readOnlyParam, true); err != nil {
+		return err
+	}
+	if gc.dryRun, err = parseBool(params, dryRunParam, false); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -136,55 +179,81 @@ func (gc *GarbageCollector) setReadOnly(switcher bool) error {
 
 // cleanCache is to clean the registry cache for GC.
 // To do this is because the issue https://github.com/docker/distribution/issues/2094
+// The cached blobs are deleted by the workers concurrently.
 func (gc *GarbageCollector) cleanCache() error {
+	pool := &redis.Pool{
+		MaxIdle:   gc.workers,
+		MaxActive: gc.workers,
+		Wait:      true,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(
+				gc.redisURL,
+				redis.DialConnectTimeout(dialConnectionTimeout),
+				redis.DialReadTimeout(dialReadTimeout),
+				redis.DialWriteTimeout(dialWriteTimeout),
+			)
+		},
+	}
+	defer pool.Close()
 
-	con, err := redis.DialURL(
-		gc.redisURL,
-		redis.DialConnectTimeout(dialConnectionTimeout),
-		redis.DialReadTimeout(dialReadTimeout),
-		redis.DialWriteTimeout(dialWriteTimeout),
-	)
-
-	if err != nil {
+	con := pool.Get()
+	if err := con.Err(); err != nil {
+		con.Close()
 		gc.logger.Errorf("failed to connect to redis %v", err)
 		return err
 	}
-	defer con.Close()
 
 	// clean all keys in registry redis DB.
 
 	// sample of keys in registry redis:
 	// 1) "blobs::sha256:1a6fd470b9ce10849be79e99529a88371dff60c60aab424c077007f6979b4812"
 	// 2) "repository::library/hello-world::blobs::sha256:4ab4c602aa5eed5528a6620ff18a1dc4faef0e1ab3a5eddeddb410714478c67f"
-	err = delKeys(con, blobPrefix)
+	blobKeys, err := scanKeys(con, blobPrefix)
 	if err != nil {
+		con.Close()
 		gc.logger.Errorf("failed to clean registry cache %v, pattern blobs::*", err)
 		return err
 	}
-	err = delKeys(con, repoPrefix)
+	repoKeys, err := scanKeys(con, repoPrefix)
+	con.Close()
 	if err != nil {
 		gc.logger.Errorf("failed to clean registry cache %v, pattern repository::*", err)
 		return err
 	}
 
+	del := func(key string) error {
+		c := pool.Get()
+		defer c.Close()
+		_, err := c.Do("DEL", key)
+		return err
+	}
+	if err = deleteBlobs(gc.logger, blobKeys, gc.workers, del); err != nil {
+		gc.logger.Errorf("failed to clean registry cache %v, pattern blobs::*", err)
+		return err
+	}
+	if err = deleteBlobs(gc.logger, repoKeys, gc.workers, del); err != nil {
+		gc.logger.Errorf("failed to clean registry cache %v, pattern repository::*", err)
+		return err
+	}
+
 	return nil
 }
 
-func delKeys(con redis.Conn, pattern string) error {
+func scanKeys(con redis.Conn, pattern string) ([]string, error) {
 	iter := 0
 	keys := make([]string, 0)
 	for {
 		arr, err := redis.Values(con.Do("SCAN", iter, "MATCH", pattern))
 		if err != nil {
-			return fmt.Errorf("error retrieving '%s' keys", pattern)
+			return nil, fmt.Errorf("error retrieving '%s' keys", pattern)
 		}
 		iter, err = redis.Int(arr[0], nil)
 		if err != nil {
-			return fmt.Errorf("unexpected type for Int, got type %T", err)
+			return nil, fmt.Errorf("unexpected type for Int, got type %T", err)
 		}
 		k, err := redis.Strings(arr[1], nil)
 		if err != nil {
-			return fmt.Errorf("converts an array command reply to a []string %v", err)
+			return nil, fmt.Errorf("converts an array command reply to a []string %v", err)
 		}
 		keys = append(keys, k...)
 
@@ -192,13 +261,7 @@ func delKeys(con redis.Conn, pattern string) error {
 			break
 		}
 	}
-	for _, key := range keys {
-		_, err := con.Do("DEL", key)
-		if err != nil {
-			return fmt.Errorf("failed to clean registry cache %v", err)
-		}
-	}
-	return nil
+	return keys, nil
 }
 
 func (gc *GarbageCollector) ensureQuota() error {
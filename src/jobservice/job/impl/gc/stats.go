@@ -0,0 +1,59 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"encoding/json"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/registryctl/api"
+)
+
+// collectStats collects the statistics of GC from the output of registry GC, the blobs and the manifests
+// eligible for deletion are deleted when the GC completes
+func (gc *GarbageCollector) collectStats(gcr *api.GCResult) *models.GCStats {
+	stats := &models.GCStats{
+		Kind:             models.AdminJobCheckInKindStats,
+		ManifestsDeleted: len(eligible(gcr.Msg, eligibleManifestPrefix)),
+		Duration:         int64(gcr.EndTime.Sub(gcr.StartTime).Seconds()),
+	}
+	for _, digest := range eligibleBlobs(gcr.Msg) {
+		size, err := gc.blobSize(digest)
+		if err != nil {
+			// the size is unknown, the blob is still counted
+			gc.logger.Warningf("failed to get the size of blob %s: %v", digest, err)
+			stats.Errors++
+		}
+		stats.BlobsDeleted++
+		stats.BytesFreed += size
+	}
+	return stats
+}
+
+// checkInStats checks in the statistics to be persisted on the admin job, the failure is only logged
+// as the GC has completed
+func (gc *GarbageCollector) checkInStats(ctx job.Context, stats *models.GCStats) {
+	gc.logger.Infof("GC stats: %d blobs and %d manifests deleted, %d bytes freed, duration: %ds, errors: %d.",
+		stats.BlobsDeleted, stats.ManifestsDeleted, stats.BytesFreed, stats.Duration, stats.Errors)
+	data, err := json.Marshal(stats)
+	if err != nil {
+		gc.logger.Errorf("failed to marshal the stats of gc: %v", err)
+		return
+	}
+	if err := ctx.Checkin(string(data)); err != nil {
+		gc.logger.Errorf("failed to check in the stats of gc: %v", err)
+	}
+}
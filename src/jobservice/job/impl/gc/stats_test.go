@@ -0,0 +1,79 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/jobservice/logger/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectStats(t *testing.T) {
+	blobs := digests(3)
+	client := &fakeRegistryCtlClient{registry: newFakeRegistry(0), candidates: blobs}
+	gcr, err := client.StartGC(true)
+	require.Nil(t, err)
+	gcr.Msg += "\n" + eligibleManifestPrefix + "sha256:manifest\n"
+	gcr.EndTime = gcr.StartTime.Add(90 * time.Second)
+
+	gc := &GarbageCollector{
+		logger: backend.NewStdOutputLogger("ERROR", backend.StdErr, 4),
+		blobSize: func(digest string) (int64, error) {
+			if digest == blobs[2] {
+				return 0, errors.New("internal error")
+			}
+			return 1024, nil
+		},
+	}
+	stats := gc.collectStats(gcr)
+
+	// the blob whose size is unknown is still counted
+	assert.Equal(t, &models.GCStats{
+		Kind:             models.AdminJobCheckInKindStats,
+		BlobsDeleted:     3,
+		ManifestsDeleted: 1,
+		BytesFreed:       2048,
+		Duration:         90,
+		Errors:           1,
+	}, stats)
+
+	// the stats are checked in to be stored
+	ctx := &fakedJobContext{}
+	gc.checkInStats(ctx, stats)
+	require.Equal(t, 1, len(ctx.checkIns))
+	checkedIn := &models.GCStats{}
+	require.Nil(t, json.Unmarshal([]byte(ctx.checkIns[0]), checkedIn))
+	assert.Equal(t, stats, checkedIn)
+}
+
+func TestCollectStatsNothingDeleted(t *testing.T) {
+	client := &fakeRegistryCtlClient{registry: newFakeRegistry(0)}
+	gcr, err := client.StartGC(true)
+	require.Nil(t, err)
+
+	gc := &GarbageCollector{
+		logger: backend.NewStdOutputLogger("ERROR", backend.StdErr, 4),
+		blobSize: func(digest string) (int64, error) {
+			return 1024, nil
+		},
+	}
+	assert.Equal(t, &models.GCStats{Kind: models.AdminJobCheckInKindStats}, gc.collectStats(gcr))
+}
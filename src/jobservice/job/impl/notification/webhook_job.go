@@ -2,13 +2,20 @@ package notification
 
 import (
 	"bytes"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
-	commonhttp "github.com/goharbor/harbor/src/common/http"
-	"github.com/goharbor/harbor/src/jobservice/job"
-	"github.com/goharbor/harbor/src/jobservice/logger"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"syscall"
+	"time"
+
+	commonhttp "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/jobservice/logger"
 )
 
 // Max retry has the same meaning as max fails.
@@ -68,10 +75,40 @@ func (wj *WebhookJob) init(ctx job.Context, params map[string]interface{}) error
 	wj.client = &http.Client{
 		Transport: commonhttp.GetHTTPTransport(insecureSkipVerify),
 	}
+	// the internal address is rejected when connecting, which covers the redirects and the
+	// DNS answers changed after the address is validated
+	if v, ok := params["block_internal_address"]; ok && v.(bool) {
+		wj.client = &http.Client{
+			Transport: externalTransport(insecureSkipVerify),
+		}
+	}
 
 	return nil
 }
 
+// externalTransport returns the transport which only connects to the external IPs directly
+func externalTransport(insecureSkipVerify bool) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || utils.IsInternalIP(ip) {
+				return fmt.Errorf("connecting to the internal address %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+	return &http.Transport{
+		DialContext: dialer.DialContext,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: insecureSkipVerify,
+		},
+	}
+}
+
 // execute webhook job
 func (wj *WebhookJob) execute(ctx job.Context, params map[string]interface{}) error {
 	payload := params["payload"].(string)
@@ -86,14 +123,36 @@ func (wj *WebhookJob) execute(ctx job.Context, params map[string]interface{}) er
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	start := time.Now()
 	resp, err := wj.client.Do(req)
 	if err != nil {
+		wj.checkInDelivery(0, time.Since(start))
 		return err
 	}
 	defer resp.Body.Close()
+	wj.checkInDelivery(resp.StatusCode, time.Since(start))
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return fmt.Errorf("webhook job(target: %s) response code is %d", address, resp.StatusCode)
 	}
 
 	return nil
 }
+
+// checkInDelivery reports the result of the delivery which is recorded as the delivery log by core,
+// the status code is 0 if no response is received
+func (wj *WebhookJob) checkInDelivery(statusCode int, duration time.Duration) {
+	delivery := struct {
+		StatusCode int   `json:"status_code"`
+		DurationMS int64 `json:"duration_ms"`
+		Success    bool  `json:"success"`
+	}{
+		StatusCode: statusCode,
+		DurationMS: int64(duration / time.Millisecond),
+		Success:    statusCode >= 200 && statusCode < 300,
+	}
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return
+	}
+	_ = wj.ctx.Checkin(string(data))
+}
@@ -1,13 +1,18 @@
 package notification
 
 import (
-	"github.com/goharbor/harbor/src/jobservice/job/impl"
-	"github.com/stretchr/testify/assert"
+	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMaxFails(t *testing.T) {
@@ -57,7 +62,13 @@ func TestRun(t *testing.T) {
 		"auth_header":      "auth_test",
 	}
 	// test correct webhook response
-	assert.Nil(t, rep.Run(&impl.Context{}, params))
+	ctx := &fakeJobContext{}
+	assert.Nil(t, rep.Run(ctx, params))
+	require.Equal(t, 1, len(ctx.checkIns))
+	delivery := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal([]byte(ctx.checkIns[0]), &delivery))
+	assert.Equal(t, float64(http.StatusOK), delivery["status_code"])
+	assert.Equal(t, true, delivery["success"])
 
 	tsWrong := httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -71,5 +82,71 @@ func TestRun(t *testing.T) {
 		"auth_header":      "auth_test",
 	}
 	// test incorrect webhook response
-	assert.NotNil(t, rep.Run(&impl.Context{}, paramsWrong))
+	ctx = &fakeJobContext{}
+	assert.NotNil(t, rep.Run(ctx, paramsWrong))
+	require.Equal(t, 1, len(ctx.checkIns))
+	delivery = map[string]interface{}{}
+	require.Nil(t, json.Unmarshal([]byte(ctx.checkIns[0]), &delivery))
+	assert.Equal(t, float64(http.StatusUnauthorized), delivery["status_code"])
+	assert.Equal(t, false, delivery["success"])
+}
+
+func TestRunBlockInternalAddress(t *testing.T) {
+	rep := &WebhookJob{}
+
+	received := false
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = true
+		}))
+	defer ts.Close()
+	params := map[string]interface{}{
+		"skip_cert_verify":       true,
+		"payload":                `{"key": "value"}`,
+		"address":                ts.URL,
+		"auth_header":            "auth_test",
+		"block_internal_address": true,
+	}
+	// the test server listens on the loopback address
+	ctx := &fakeJobContext{}
+	assert.NotNil(t, rep.Run(ctx, params))
+	assert.False(t, received)
+	require.Equal(t, 1, len(ctx.checkIns))
+	delivery := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal([]byte(ctx.checkIns[0]), &delivery))
+	assert.Equal(t, float64(0), delivery["status_code"])
+	assert.Equal(t, false, delivery["success"])
+}
+
+type fakeJobContext struct {
+	checkIns []string
+}
+
+func (c *fakeJobContext) Build(tracker job.Tracker) (job.Context, error) {
+	return nil, nil
+}
+
+func (c *fakeJobContext) Get(prop string) (interface{}, bool) {
+	return nil, false
+}
+
+func (c *fakeJobContext) SystemContext() context.Context {
+	return context.TODO()
+}
+
+func (c *fakeJobContext) Checkin(status string) error {
+	c.checkIns = append(c.checkIns, status)
+	return nil
+}
+
+func (c *fakeJobContext) OPCommand() (job.OPCommand, bool) {
+	return "", false
+}
+
+func (c *fakeJobContext) GetLogger() logger.Interface {
+	return nil
+}
+
+func (c *fakeJobContext) Tracker() job.Tracker {
+	return nil
 }
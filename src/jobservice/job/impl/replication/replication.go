@@ -100,7 +100,21 @@ func (r *Replication) Run(ctx job.Context, params job.Parameters) error {
 		return err
 	}
 
-	return trans.Transfer(src, dst)
+	if err = trans.Transfer(src, dst); err != nil {
+		return err
+	}
+	// report the transfer mode to be recorded in the task
+	if reporter, ok := trans.(transfer.StatsReporter); ok {
+		data, err := json.Marshal(reporter.Stats())
+		if err != nil {
+			logger.Errorf("failed to marshal the transfer stats: %v", err)
+			return nil
+		}
+		if err = ctx.Checkin(string(data)); err != nil {
+			logger.Warningf("failed to check in the transfer stats: %v", err)
+		}
+	}
+	return nil
 }
 
 func parseParams(params map[string]interface{}) (*model.Resource, *model.Resource, error) {
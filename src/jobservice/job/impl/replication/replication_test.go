@@ -15,6 +15,7 @@
 package replication
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/goharbor/harbor/src/jobservice/job/impl"
@@ -98,3 +99,43 @@ func TestRun(t *testing.T) {
 	require.Nil(t, rep.Run(&impl.Context{}, params))
 	assert.True(t, transferred)
 }
+
+type fakedReportingTransfer struct {
+	fakedTransfer
+}
+
+func (f *fakedReportingTransfer) Stats() *transfer.Stats {
+	return &transfer.Stats{
+		TransferMode: transfer.TransferModeMount,
+		MountedBytes: 1024,
+	}
+}
+
+type fakedJobContext struct {
+	impl.Context
+	checkIns []string
+}
+
+func (f *fakedJobContext) Checkin(status string) error {
+	f.checkIns = append(f.checkIns, status)
+	return nil
+}
+
+func TestRunWithStats(t *testing.T) {
+	err := transfer.RegisterFactory("image-mount", func(transfer.Logger, transfer.StopFunc) (transfer.Transfer, error) {
+		return &fakedReportingTransfer{}, nil
+	})
+	require.Nil(t, err)
+	params := map[string]interface{}{
+		"src_resource": `{"type":"image-mount"}`,
+		"dst_resource": `{}`,
+	}
+	ctx := &fakedJobContext{}
+	rep := &Replication{}
+	require.Nil(t, rep.Run(ctx, params))
+	require.Equal(t, 1, len(ctx.checkIns))
+	stats := &transfer.Stats{}
+	require.Nil(t, json.Unmarshal([]byte(ctx.checkIns[0]), stats))
+	assert.Equal(t, transfer.TransferModeMount, stats.TransferMode)
+	assert.Equal(t, int64(1024), stats.MountedBytes)
+}
@@ -29,6 +29,11 @@ import (
 	"github.com/goharbor/harbor/src/jobservice/job/impl/utils"
 )
 
+const (
+	// ParamForce is the optional bool parameter which bypasses the fresh reports of the same digest
+	ParamForce = "force"
+)
+
 // All query the DB and Registry for all image and tags,
 // then call Harbor's API to scan each of them.
 type All struct {
@@ -51,8 +56,13 @@ func (sa *All) ShouldRetry() bool {
 
 // Validate implements the interface in job/Interface
 func (sa *All) Validate(params job.Parameters) error {
-	if len(params) > 0 {
-		return fmt.Errorf("the parms should be empty for scan all job")
+	for k, v := range params {
+		if k != ParamForce {
+			return fmt.Errorf("unsupported parameter %s for scan all job", k)
+		}
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("the parameter %s should be a bool", ParamForce)
+		}
 	}
 	return nil
 }
@@ -67,6 +77,11 @@ func (sa *All) Run(ctx job.Context, params job.Parameters) error {
 		return err
 	}
 
+	query := ""
+	if force, ok := params[ParamForce].(bool); ok && force {
+		query = "?force=true"
+	}
+
 	repos, err := dao.GetRepositories()
 	if err != nil {
 		logger.Errorf("Failed to get the list of repositories, error: %v", err)
@@ -86,7 +101,7 @@ func (sa *All) Run(ctx job.Context, params job.Parameters) error {
 		}
 		for _, t := range tags {
 			logger.Infof("Calling harbor-core API to scan image, %s:%s", r.Name, t)
-			resp, err := sa.coreClient.Post(fmt.Sprintf("%s/repositories/%s/tags/%s/scan", sa.harborAPIEndpoint, r.Name, t),
+			resp, err := sa.coreClient.Post(fmt.Sprintf("%s/repositories/%s/tags/%s/scan%s", sa.harborAPIEndpoint, r.Name, t, query),
 				"application/json",
 				bytes.NewReader([]byte("{}")))
 			if err != nil {
@@ -19,6 +19,16 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	// ParamMaxFails is the reserved parameter carrying the max fails of the job request, it's set by
+	// job service and overrides the max fails declared by the job
+	ParamMaxFails = "_max_fails_"
+	// MaxFailsLimit is the upper limit of the max fails of the job request
+	MaxFailsLimit uint = 10
+	// DefaultMaxFails is used if the job declares no max fails
+	DefaultMaxFails uint = 4
+)
+
 // Parameters for job execution.
 type Parameters map[string]interface{}
 
@@ -40,7 +50,10 @@ type Metadata struct {
 	JobKind       string `json:"kind"`
 	ScheduleDelay uint64 `json:"schedule_delay,omitempty"`
 	Cron          string `json:"cron_spec,omitempty"`
+	Timezone      string `json:"timezone,omitempty"`
 	IsUnique      bool   `json:"unique"`
+	// MaxFails overrides the max fails declared by the job if it's set, 1 means no retry
+	MaxFails uint `json:"max_fails,omitempty"`
 }
 
 // Stats keeps the result of job launching.
@@ -75,6 +88,11 @@ type ActionRequest struct {
 	Action string `json:"action"`
 }
 
+// ScheduleUpdateRequest defines for updating the cron spec of the periodic job.
+type ScheduleUpdateRequest struct {
+	CronSpec string `json:"cron_spec"`
+}
+
 // StatusChange is designed for reporting the status change via hook.
 type StatusChange struct {
 	JobID    string     `json:"job_id"`
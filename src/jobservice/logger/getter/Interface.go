@@ -9,4 +9,11 @@ type Interface interface {
 	// If succeed, log data bytes will be returned
 	// otherwise, a non nil error is returned
 	Retrieve(logID string) ([]byte, error)
+
+	// Delete the log data of the specified log entry
+	//
+	// logID string : the id of the log entry. e.g: file name a.log for file log
+	//
+	// If the log entry doesn't exist, a not found error is returned
+	Delete(logID string) error
 }
@@ -3,6 +3,7 @@ package getter
 import (
 	"errors"
 	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/jobservice/errs"
 )
 
 // DBGetter is responsible for retrieving DB log data
@@ -27,3 +28,20 @@ func (dbg *DBGetter) Retrieve(logID string) ([]byte, error) {
 
 	return []byte(jobLog.Content), nil
 }
+
+// Delete implements @Interface.Delete
+func (dbg *DBGetter) Delete(logID string) error {
+	if len(logID) == 0 {
+		return errors.New("empty log identify")
+	}
+
+	count, err := dao.DeleteJobLog(logID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errs.NoObjectFoundError(logID)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package getter
 import (
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/jobservice/logger/backend"
 	"github.com/goharbor/harbor/src/jobservice/logger/sweeper"
 	"github.com/stretchr/testify/require"
@@ -55,6 +56,26 @@ func TestDBGetter(t *testing.T) {
 	require.Equal(t, 1, count)
 }
 
+// TestDBGetterDelete
+func TestDBGetterDelete(t *testing.T) {
+	uuid := "uuid_for_unit_test_getter_delete"
+	l, err := backend.NewDBLogger(uuid, "DEBUG", 4)
+	require.Nil(t, err)
+
+	l.Debug("JobLog Debug: TestDBGetterDelete")
+	err = l.Close()
+	require.NoError(t, err)
+
+	dbGetter := NewDBGetter()
+	require.Nil(t, dbGetter.Delete(uuid))
+	_, err = dbGetter.Retrieve(uuid)
+	require.NotNil(t, err)
+
+	err = dbGetter.Delete(uuid)
+	require.True(t, errs.IsObjectNotFoundError(err))
+	require.NotNil(t, dbGetter.Delete(""))
+}
+
 // TestDBGetterError
 func TestDBGetterError(t *testing.T) {
 	uuid := "uuid_for_unit_test_getter_error"
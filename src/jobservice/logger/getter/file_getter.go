@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path"
 
 	"github.com/goharbor/harbor/src/jobservice/errs"
@@ -24,19 +25,39 @@ func NewFileGetter(baseDir string) *FileGetter {
 
 // Retrieve implements @Interface.Retrieve
 func (fg *FileGetter) Retrieve(logID string) ([]byte, error) {
+	fPath, err := fg.logPath(logID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadFile(fPath)
+}
+
+// Delete implements @Interface.Delete
+func (fg *FileGetter) Delete(logID string) error {
+	fPath, err := fg.logPath(logID)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(fPath)
+}
+
+// logPath returns the path of the existing log file of the log entry
+func (fg *FileGetter) logPath(logID string) (string, error) {
 	if len(logID) != 24 {
-		return nil, errors.New("invalid length of log identify")
+		return "", errors.New("invalid length of log identify")
 	}
 
 	if _, err := hex.DecodeString(logID); err != nil {
-		return nil, errors.New("invalid log identify")
+		return "", errors.New("invalid log identify")
 	}
 
 	fPath := path.Join(fg.baseDir, fmt.Sprintf("%s.log", logID))
 
 	if !utils.FileExists(fPath) {
-		return nil, errs.NoObjectFoundError(logID)
+		return "", errs.NoObjectFoundError(logID)
 	}
 
-	return ioutil.ReadFile(fPath)
+	return fPath, nil
 }
@@ -45,3 +45,23 @@ func TestLogDataGetter(t *testing.T) {
 		t.Errorf("expect reading 5 bytes but got %d bytes", len(data))
 	}
 }
+
+// Test deleting the log data
+func TestLogDataDelete(t *testing.T) {
+	fakeLog := path.Join(os.TempDir(), newLogFileName)
+	if err := ioutil.WriteFile(fakeLog, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fg := NewFileGetter(os.TempDir())
+	if err := fg.Delete(newLogFileID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(fakeLog); !os.IsNotExist(err) {
+		t.Errorf("expect the log file %s to be deleted", fakeLog)
+	}
+
+	if err := fg.Delete(newLogFileID); err == nil || !errs.IsObjectNotFoundError(err) {
+		t.Errorf("expect object not found error but got %v", err)
+	}
+}
@@ -15,3 +15,13 @@ func Retrieve(logID string) ([]byte, error) {
 
 	return val.(getter.Interface).Retrieve(logID)
 }
+
+// Delete is wrapper func for getter.Delete
+func Delete(logID string) error {
+	val, ok := singletons.Load(systemKeyLogDataGetter)
+	if !ok {
+		return errors.New("no log data getter is configured")
+	}
+
+	return val.(getter.Interface).Delete(logID)
+}
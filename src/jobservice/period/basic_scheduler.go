@@ -27,6 +27,7 @@ import (
 	"github.com/goharbor/harbor/src/jobservice/logger"
 	"github.com/gomodule/redigo/redis"
 	"github.com/pkg/errors"
+	"github.com/robfig/cron"
 )
 
 // basicScheduler manages the periodic scheduling policies.
@@ -147,25 +148,11 @@ func (bs *basicScheduler) UnSchedule(policyID string) error {
 	}()
 
 	// Get the un-scheduling policy object
-	bytes, err := redis.Values(conn.Do("ZRANGEBYSCORE", rds.KeyPeriodicPolicy(bs.namespace), numericID, numericID))
+	p, err := bs.loadPolicy(conn, policyID, numericID)
 	if err != nil {
 		return err
 	}
 
-	p := &Policy{}
-	if len(bytes) > 0 {
-		if rawPolicy, ok := bytes[0].([]byte); ok {
-			if err := p.DeSerialize(rawPolicy); err != nil {
-				return err
-			}
-		}
-	}
-
-	if utils.IsEmptyStr(p.ID) {
-		// Deserialize failed
-		return errors.Errorf("no valid periodic job policy found: %s:%d", policyID, numericID)
-	}
-
 	notification := &message{
 		Event: changeEventUnSchedule,
 		Data:  p,
@@ -240,6 +227,137 @@ func (bs *basicScheduler) UnSchedule(policyID string) error {
 	return err
 }
 
+// UpdateSchedule is implementation of the same method in period.Interface
+func (bs *basicScheduler) UpdateSchedule(policyID string, cronSpec string) error {
+	if utils.IsEmptyStr(policyID) {
+		return errors.New("bad periodic job ID: nil")
+	}
+
+	tracker, err := bs.ctl.Track(policyID)
+	if err != nil {
+		return err
+	}
+
+	numericID, err := tracker.NumericID()
+	if err != nil {
+		return err
+	}
+
+	conn := bs.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	p, err := bs.loadPolicy(conn, policyID, numericID)
+	if err != nil {
+		return err
+	}
+
+	p.CronSpec = cronSpec
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	rawJSON, err := p.Serialize()
+	if err != nil {
+		return err
+	}
+
+	msgJSON, err := json.Marshal(&message{
+		Event: changeEventUpdateSchedule,
+		Data:  p,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Replace the policy with the same numeric ID and publish notification via redis transaction,
+	// the policy is never absent from the store
+	err = conn.Send("MULTI")
+	err = conn.Send("ZREMRANGEBYSCORE", rds.KeyPeriodicPolicy(bs.namespace), numericID, numericID)
+	err = conn.Send("ZADD", rds.KeyPeriodicPolicy(bs.namespace), numericID, rawJSON)
+	err = conn.Send("PUBLISH", rds.KeyPeriodicNotification(bs.namespace), msgJSON)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Do("EXEC"); err != nil {
+		return err
+	}
+
+	if err := tracker.Update("cron_spec", cronSpec); err != nil {
+		logger.Errorf("Update cron spec of periodic job %s error: %s", policyID, err)
+	}
+
+	// Schedule the executions of the new cron spec before clearing the ones of the old cron spec,
+	// so there is no gap in scheduling
+	bs.enqueuer.scheduleNextJobs(p, conn)
+
+	schedule, err := cron.Parse(cronSpec)
+	if err != nil {
+		return err
+	}
+
+	eKey := rds.KeyUpstreamJobAndExecutions(bs.namespace, policyID)
+	eIDs, err := getPeriodicExecutions(conn, eKey)
+	if err != nil {
+		// The outdated executions will be run, only logged
+		logger.Errorf("Get executions for periodic job %s error: %s", policyID, err)
+		return nil
+	}
+
+	for _, eID := range eIDs {
+		eTracker, err := bs.ctl.Track(eID)
+		if err != nil {
+			logger.Errorf("Track execution %s error: %s", eID, err)
+			continue
+		}
+
+		e := eTracker.Job()
+		if job.ScheduledStatus != job.Status(e.Info.Status) {
+			continue
+		}
+
+		// Keep the executions still matching the new cron spec
+		runAt := time.Unix(e.Info.RunAt, 0).In(p.Location())
+		if schedule.Next(runAt.Add(-time.Second)).Equal(runAt) {
+			continue
+		}
+
+		if err := bs.client.DeleteScheduledJob(e.Info.RunAt, policyID); err != nil {
+			logger.Errorf("Delete scheduled job %s error: %s", eID, err)
+		}
+		if err := eTracker.Stop(); err != nil {
+			logger.Errorf("Stop execution %s error: %s", eID, err)
+		}
+	}
+
+	return nil
+}
+
+// loadPolicy loads the policy with the numeric ID
+func (bs *basicScheduler) loadPolicy(conn redis.Conn, policyID string, numericID int64) (*Policy, error) {
+	bytes, err := redis.Values(conn.Do("ZRANGEBYSCORE", rds.KeyPeriodicPolicy(bs.namespace), numericID, numericID))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Policy{}
+	if len(bytes) > 0 {
+		if rawPolicy, ok := bytes[0].([]byte); ok {
+			if err := p.DeSerialize(rawPolicy); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if utils.IsEmptyStr(p.ID) {
+		// Deserialize failed
+		return nil, errors.Errorf("no valid periodic job policy found: %s:%d", policyID, numericID)
+	}
+
+	return p, nil
+}
+
 // Clear all the dirty jobs
 // A scheduled job will be marked as dirty job only if the enqueued timestamp has expired a horizon.
 // This is a try best action
@@ -16,6 +16,7 @@ package period
 import (
 	"context"
 	"fmt"
+	"github.com/goharbor/harbor/src/jobservice/common/rds"
 	"github.com/goharbor/harbor/src/jobservice/common/utils"
 	"github.com/goharbor/harbor/src/jobservice/env"
 	"github.com/goharbor/harbor/src/jobservice/job"
@@ -133,3 +134,80 @@ func (suite *BasicSchedulerTestSuite) TestScheduler() {
 	err = suite.scheduler.UnSchedule(p.ID)
 	require.NoError(suite.T(), err, "unschedule: nil error expected but got %s", err)
 }
+
+// TestUpdateSchedule tests updating the cron spec of the scheduled policy without any gap in scheduling
+func (suite *BasicSchedulerTestSuite) TestUpdateSchedule() {
+	p := &Policy{
+		ID:       "fake_policy_update",
+		JobName:  job.SampleJob,
+		CronSpec: "0 * * * * *",
+	}
+	pid, err := suite.scheduler.Schedule(p)
+	require.NoError(suite.T(), err, "schedule: nil error expected but got %s", err)
+
+	_, err = suite.lcmCtl.New(&job.Stats{
+		Info: &job.StatsInfo{
+			JobID:      p.ID,
+			Status:     job.ScheduledStatus.String(),
+			JobName:    job.SampleJob,
+			JobKind:    job.KindPeriodic,
+			NumericPID: pid,
+			CronSpec:   p.CronSpec,
+		},
+	})
+	require.NoError(suite.T(), err, "lcm new: nil error expected but got %s", err)
+	defer func() {
+		_ = suite.scheduler.UnSchedule(p.ID)
+	}()
+
+	conn := suite.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// the run times of the executions of the policy in the scheduled job queue
+	scheduled := func() []int64 {
+		jobScores, err := rds.GetZsetByScore(conn, rds.RedisKeyScheduled(suite.namespace), []int64{0, time.Now().Add(time.Hour).Unix()})
+		require.NoError(suite.T(), err)
+		var runAts []int64
+		for _, js := range jobScores {
+			j, err := utils.DeSerializeJob(js.JobBytes)
+			require.NoError(suite.T(), err)
+			if j.ID == p.ID {
+				runAts = append(runAts, js.Score)
+			}
+		}
+		return runAts
+	}
+	require.NotEmpty(suite.T(), scheduled())
+
+	err = suite.scheduler.UpdateSchedule(p.ID, "30 * * * * *")
+	require.NoError(suite.T(), err, "update schedule: nil error expected but got %s", err)
+
+	// the policy is replaced with the same numeric ID
+	bs := suite.scheduler.(*basicScheduler)
+	updated, err := bs.loadPolicy(conn, p.ID, pid)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "30 * * * * *", updated.CronSpec)
+
+	// the next run of the new cron spec has been scheduled and the ones of the old cron spec are removed
+	runAts := scheduled()
+	require.NotEmpty(suite.T(), runAts)
+	next := time.Now().Truncate(time.Minute).Add(30 * time.Second)
+	if !next.After(time.Now()) {
+		next = next.Add(time.Minute)
+	}
+	assert.Contains(suite.T(), runAts, next.Unix())
+	for _, runAt := range runAts {
+		assert.Equal(suite.T(), 30, time.Unix(runAt, 0).Second())
+	}
+
+	// the stats of the periodic job is updated
+	tracker, err := suite.lcmCtl.Track(p.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "30 * * * * *", tracker.Job().Info.CronSpec)
+
+	// invalid cron spec
+	err = suite.scheduler.UpdateSchedule(p.ID, "invalid")
+	assert.Error(suite.T(), err)
+}
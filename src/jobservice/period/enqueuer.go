@@ -165,7 +165,8 @@ func (e *enqueuer) enqueue() {
 
 // scheduleNextJobs schedules job for next time slots based on the policy
 func (e *enqueuer) scheduleNextJobs(p *Policy, conn redis.Conn) {
-	nowTime := time.Unix(time.Now().Unix(), 0)
+	// Evaluate the cron spec at the wall clock of the policy timezone
+	nowTime := time.Unix(time.Now().Unix(), 0).In(p.Location())
 	horizon := nowTime.Add(enqueuerHorizon)
 
 	schedule, err := cron.Parse(p.CronSpec)
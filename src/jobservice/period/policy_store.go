@@ -35,6 +35,8 @@ const (
 	changeEventSchedule = "Schedule"
 	// changeEventUnSchedule : UnSchedule periodic job policy event
 	changeEventUnSchedule = "UnSchedule"
+	// changeEventUpdateSchedule : Update the cron spec of periodic job policy event
+	changeEventUpdateSchedule = "UpdateSchedule"
 )
 
 // Policy ...
@@ -44,6 +46,7 @@ type Policy struct {
 	ID            string                 `json:"id"`
 	JobName       string                 `json:"job_name"`
 	CronSpec      string                 `json:"cron_spec"`
+	Timezone      string                 `json:"timezone,omitempty"`
 	JobParameters map[string]interface{} `json:"job_params,omitempty"`
 	WebHookURL    string                 `json:"web_hook_url,omitempty"`
 }
@@ -78,9 +81,31 @@ func (p *Policy) Validate() error {
 		return err
 	}
 
+	if !utils.IsEmptyStr(p.Timezone) {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			return fmt.Errorf("bad timezone: %s", p.Timezone)
+		}
+	}
+
 	return nil
 }
 
+// Location returns the location the cron spec is evaluated in, the local one is returned
+// if the timezone isn't set or can't be loaded
+func (p *Policy) Location() *time.Location {
+	if utils.IsEmptyStr(p.Timezone) {
+		return time.Local
+	}
+
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		logger.Errorf("Invalid timezone in periodic policy %s %s: %s", p.JobName, p.ID, err)
+		return time.Local
+	}
+
+	return loc
+}
+
 // policyStore is in-memory cache for the periodic job policies.
 type policyStore struct {
 	// k-v pair and key is the policy ID
@@ -239,6 +264,10 @@ func (ps *policyStore) sync(m *message) error {
 		if removed == nil {
 			return fmt.Errorf("failed to sync unscheduled policy %s", m.Data.ID)
 		}
+	case changeEventUpdateSchedule:
+		if err := ps.update(m.Data); err != nil {
+			return fmt.Errorf("failed to sync updated policy %s: %s", m.Data.ID, err)
+		}
 	default:
 		return fmt.Errorf("message %s is not supported", m.Event)
 	}
@@ -305,6 +334,21 @@ func (ps *policyStore) add(item *Policy) error {
 	return nil
 }
 
+// Update the policy or add it if it doesn't exist
+func (ps *policyStore) update(item *Policy) error {
+	if item == nil {
+		return errors.New("nil policy to update")
+	}
+
+	if utils.IsEmptyStr(item.ID) {
+		return errors.New("malform policy to update")
+	}
+
+	ps.hash.Store(item.ID, item)
+
+	return nil
+}
+
 // Iterate all the policies in the store
 func (ps *policyStore) Iterate(f func(id string, p *Policy) bool) {
 	ps.hash.Range(func(k, v interface{}) bool {
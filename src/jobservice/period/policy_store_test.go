@@ -19,7 +19,9 @@ import (
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/jobservice/tests"
 	"github.com/gomodule/redigo/redis"
+	"github.com/robfig/cron"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"testing"
 	"time"
@@ -151,3 +153,65 @@ func (suite *PolicyStoreTestSuite) TestPolicy() {
 	err = p2.Validate()
 	assert.Nil(suite.T(), err, "policy validate: nil error expected but got %s", err)
 }
+
+// TestSyncUpdateSchedule tests syncing the policy with the updated cron spec
+func (suite *PolicyStoreTestSuite) TestSyncUpdateSchedule() {
+	p := &Policy{
+		ID:       "fake_policy_2",
+		JobName:  job.SampleJob,
+		CronSpec: "5 * * * * *",
+	}
+	err := suite.store.sync(&message{Event: changeEventSchedule, Data: p})
+	require.Nil(suite.T(), err, "sync schedule: nil error expected but got %s", err)
+	defer suite.store.remove(p.ID)
+
+	updated := &Policy{
+		ID:       p.ID,
+		JobName:  p.JobName,
+		CronSpec: "10 * * * * *",
+	}
+	err = suite.store.sync(&message{Event: changeEventUpdateSchedule, Data: updated})
+	require.Nil(suite.T(), err, "sync update schedule: nil error expected but got %s", err)
+
+	var cronSpec string
+	suite.store.Iterate(func(id string, p *Policy) bool {
+		if id == updated.ID {
+			cronSpec = p.CronSpec
+		}
+		return true
+	})
+	assert.Equal(suite.T(), "10 * * * * *", cronSpec)
+}
+
+// TestPolicyLocation tests the cron spec is evaluated in the timezone of policy
+func TestPolicyLocation(t *testing.T) {
+	p := &Policy{
+		ID:       "fake_policy",
+		JobName:  job.SampleJob,
+		CronSpec: "0 0 3 * * *",
+		Timezone: "America/New_York",
+	}
+	require.NoError(t, p.Validate())
+	require.Equal(t, "America/New_York", p.Location().String())
+
+	schedule, err := cron.Parse(p.CronSpec)
+	require.NoError(t, err)
+
+	// 3am EST before the DST transition
+	next := schedule.Next(time.Date(2019, 3, 9, 0, 0, 0, 0, time.UTC).In(p.Location()))
+	assert.Equal(t, time.Date(2019, 3, 9, 8, 0, 0, 0, time.UTC), next.UTC())
+	// 3am EDT on the day of the DST transition, the 2am is skipped
+	next = schedule.Next(next)
+	assert.Equal(t, time.Date(2019, 3, 10, 7, 0, 0, 0, time.UTC), next.UTC())
+	// 3am EST again after the DST ends
+	next = schedule.Next(time.Date(2019, 11, 3, 0, 0, 0, 0, time.UTC).In(p.Location()))
+	assert.Equal(t, time.Date(2019, 11, 3, 8, 0, 0, 0, time.UTC), next.UTC())
+
+	p.Timezone = "Mars/Olympus_Mons"
+	assert.Error(t, p.Validate())
+	assert.Equal(t, time.Local, p.Location())
+
+	p.Timezone = ""
+	require.NoError(t, p.Validate())
+	assert.Equal(t, time.Local, p.Location())
+}
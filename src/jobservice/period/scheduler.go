@@ -44,4 +44,14 @@ type Scheduler interface {
 	// Return:
 	//  error if failed to unschedule
 	UnSchedule(policyID string) error
+
+	// UpdateSchedule updates the cron spec of the specified cron job policy without
+	// un-scheduling it, the executions are scheduled by the new cron spec at once.
+	//
+	// policyID string: The ID of cron job policy.
+	// cronSpec string: The new cron spec.
+	//
+	// Return:
+	//  error if failed to update
+	UpdateSchedule(policyID string, cronSpec string) error
 }
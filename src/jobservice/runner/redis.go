@@ -88,6 +88,9 @@ func (rj *RedisJob) Run(j *work.Job) (err error) {
 		return
 	}
 
+	// Defer to handle retry, the failures happened before running the job are counted as well
+	defer rj.retry(Wrap(rj.job), j)
+
 	// Do operation based on the job status
 	jStatus := job.Status(tracker.Job().Info.Status)
 	switch jStatus {
@@ -189,8 +192,6 @@ func (rj *RedisJob) Run(j *work.Job) (err error) {
 	}
 	// Run the job
 	err = runningJob.Run(execContext, j.Args)
-	// Handle retry
-	rj.retry(runningJob, j)
 	// Handle periodic job execution
 	if _, yes := isPeriodicJobExecution(j); yes {
 		if er := tracker.PeriodicExecutionDone(); er != nil {
@@ -202,8 +203,11 @@ func (rj *RedisJob) Run(j *work.Job) (err error) {
 	return
 }
 
+// retry cancels the retry of the job if it's not allowed or the max fails is reached. The job is registered
+// with the max fails raised to the limit, so the max fails of the job or the job request is enforced here.
 func (rj *RedisJob) retry(j job.Interface, wj *work.Job) {
-	if !j.ShouldRetry() {
+	// The fails is increased by the worker once the job returns
+	if !j.ShouldRetry() || wj.Fails+1 >= int64(maxFails(j, wj.Args)) {
 		// Cancel retry immediately
 		// Make it big enough to avoid retrying
 		wj.Fails = 10000000000
@@ -211,6 +215,39 @@ func (rj *RedisJob) retry(j job.Interface, wj *work.Job) {
 	}
 }
 
+// maxFails returns the max fails of the job request if it's set, otherwise the one declared by the job
+func maxFails(j job.Interface, params job.Parameters) uint {
+	if v, ok := params[job.ParamMaxFails]; ok {
+		// the parameters are decoded from JSON
+		switch n := v.(type) {
+		case float64:
+			if n > 0 {
+				return uint(n)
+			}
+		case uint:
+			if n > 0 {
+				return n
+			}
+		}
+	}
+
+	if n := j.MaxFails(); n > 0 {
+		return n
+	}
+
+	return job.DefaultMaxFails
+}
+
+// RegisteredMaxFails returns the max fails the job is registered with, it's raised to the limit so
+// that the job request can override the max fails declared by the job
+func RegisteredMaxFails(j job.Interface) uint {
+	if n := j.MaxFails(); n > job.MaxFailsLimit {
+		return n
+	}
+
+	return job.MaxFailsLimit
+}
+
 func isPeriodicJobExecution(j *work.Job) (string, bool) {
 	epoch, ok := j.Args[period.PeriodicExecutionMark]
 	return fmt.Sprintf("%s@%s", j.ID, epoch), ok
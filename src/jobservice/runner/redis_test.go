@@ -227,3 +227,54 @@ func (j *fakePanicJob) Validate(params job.Parameters) error {
 func (j *fakePanicJob) Run(ctx job.Context, params job.Parameters) error {
 	panic("for testing")
 }
+
+type fakeRetryJob struct {
+	fakeParentJob
+}
+
+func (j *fakeRetryJob) MaxFails() uint {
+	return 3
+}
+
+func (j *fakeRetryJob) ShouldRetry() bool {
+	return true
+}
+
+// TestRetry tests the retry is capped by the max fails of the job or the job request
+func TestRetry(t *testing.T) {
+	rj := &RedisJob{}
+	cases := []struct {
+		name    string
+		j       job.Interface
+		fails   int64
+		args    map[string]interface{}
+		retried bool
+	}{
+		{"no retry", &fakeParentJob{}, 0, nil, false},
+		{"first fail of job", &fakeRetryJob{}, 0, nil, true},
+		{"last fail of job", &fakeRetryJob{}, 2, nil, false},
+		{"raised by request", &fakeRetryJob{}, 2, map[string]interface{}{job.ParamMaxFails: float64(7)}, true},
+		{"last fail of request", &fakeRetryJob{}, 6, map[string]interface{}{job.ParamMaxFails: float64(7)}, false},
+		{"disabled by request", &fakeRetryJob{}, 0, map[string]interface{}{job.ParamMaxFails: float64(1)}, false},
+		{"not retryable job", &fakeParentJob{}, 0, map[string]interface{}{job.ParamMaxFails: float64(7)}, false},
+	}
+	for _, c := range cases {
+		wj := &work.Job{Fails: c.fails, Args: c.args}
+		rj.retry(c.j, wj)
+		assert.Equal(t, c.retried, wj.Fails == c.fails, c.name)
+	}
+}
+
+// TestRegisteredMaxFails tests the job is registered with the max fails raised to the limit
+func TestRegisteredMaxFails(t *testing.T) {
+	assert.Equal(t, job.MaxFailsLimit, RegisteredMaxFails(&fakeRetryJob{}))
+	assert.Equal(t, job.DefaultMaxFails, maxFails(&fakeZeroJob{}, nil))
+}
+
+type fakeZeroJob struct {
+	fakeParentJob
+}
+
+func (j *fakeZeroJob) MaxFails() uint {
+	return 0
+}
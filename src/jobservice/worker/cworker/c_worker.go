@@ -237,11 +237,12 @@ func (w *basicWorker) Schedule(jobName string, params job.Parameters, runAfterSe
 }
 
 // PeriodicallyEnqueue job
-func (w *basicWorker) PeriodicallyEnqueue(jobName string, params job.Parameters, cronSetting string, isUnique bool, webHook string) (*job.Stats, error) {
+func (w *basicWorker) PeriodicallyEnqueue(jobName string, params job.Parameters, cronSetting string, timezone string, isUnique bool, webHook string) (*job.Stats, error) {
 	p := &period.Policy{
 		ID:            utils.MakeIdentifier(),
 		JobName:       jobName,
 		CronSpec:      cronSetting,
+		Timezone:      timezone,
 		JobParameters: params,
 		WebHookURL:    webHook,
 	}
@@ -344,6 +345,24 @@ func (w *basicWorker) StopJob(jobID string) error {
 	}
 }
 
+// UpdateSchedule updates the cron spec of the periodic job
+func (w *basicWorker) UpdateSchedule(jobID string, cronSpec string) error {
+	if utils.IsEmptyStr(jobID) {
+		return errors.New("empty job ID to update schedule")
+	}
+
+	t, err := w.ctl.Track(jobID)
+	if err != nil {
+		return err
+	}
+
+	if t.Job().Info.JobKind != job.KindPeriodic {
+		return errors.Errorf("only the schedule of %s job can be updated but job %s is %s", job.KindPeriodic, jobID, t.Job().Info.JobKind)
+	}
+
+	return w.scheduler.UpdateSchedule(jobID, cronSpec)
+}
+
 // RetryJob retry the job
 func (w *basicWorker) RetryJob(jobID string) error {
 	return errors.New("not implemented")
@@ -405,7 +424,7 @@ func (w *basicWorker) registerJob(name string, j interface{}) (err error) {
 	w.pool.JobWithOptions(
 		name,
 		work.JobOptions{
-			MaxFails: theJ.MaxFails(),
+			MaxFails: runner.RegisteredMaxFails(theJ),
 			SkipDead: true,
 		},
 		// Use generic handler to handle as we do not accept context with this way.
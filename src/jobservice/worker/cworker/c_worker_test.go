@@ -163,6 +163,7 @@ func (suite *CWorkerTestSuite) TestEnqueuePeriodicJob() {
 		"fake_job",
 		params,
 		fmt.Sprintf("10 %d * * * *", m+2),
+		"",
 		false,
 		"http://fake-hook.com:8080",
 	)
@@ -62,13 +62,14 @@ type Interface interface {
 	// jobName string        : the name of enqueuing job
 	// params job.Parameters : parameters of enqueuing job
 	// cronSetting string    : the periodic duration with cron style like '0 * * * * *'
+	// timezone string       : the IANA timezone the cron setting is evaluated in, the local one if empty
 	// isUnique bool         : specify if duplicated job will be discarded
 	// webHook string        : the server URL to receive hook events
 	//
 	// Returns:
 	//  models.JobStats: the stats of enqueuing job if succeed
 	//  error          : if failed to enqueue
-	PeriodicallyEnqueue(jobName string, params job.Parameters, cronSetting string, isUnique bool, webHook string) (*job.Stats, error)
+	PeriodicallyEnqueue(jobName string, params job.Parameters, cronSetting string, timezone string, isUnique bool, webHook string) (*job.Stats, error)
 
 	// Return the status info of the worker.
 	//
@@ -111,4 +112,13 @@ type Interface interface {
 	// Return:
 	//  error           : error returned if meet any problems
 	RetryJob(jobID string) error
+
+	// Update the cron spec of the periodic job without recreating it
+	//
+	// jobID string    : ID of the periodic job
+	// cronSpec string : the new cron spec
+	//
+	// Return:
+	//  error           : error returned if meet any problems
+	UpdateSchedule(jobID string, cronSpec string) error
 }
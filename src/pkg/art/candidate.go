@@ -39,6 +39,8 @@ type Repository struct {
 	// So far we need the kind of repository and retrieve candidates with different APIs
 	// TODO: REMOVE IT IN THE FUTURE IF WE SUPPORT UNIFIED ARTIFACT MODEL
 	Kind string `json:"kind"`
+	// The ID of the namespace, it's used to check the immutable tag rules of the project
+	NamespaceID int64 `json:"namespace_id,omitempty"`
 }
 
 // ToJSON marshals repository to JSON string
@@ -83,6 +85,8 @@ type Candidate struct {
 	CreationTime int64
 	// Labels attached with the candidate
 	Labels []string
+	// Size in bytes of the candidate, 0 means the size is unknown
+	Size int64
 }
 
 // Hash code based on the candidate info for differentiation
@@ -14,9 +14,61 @@
 
 package art
 
+import "time"
+
+const (
+	// ActionRetain means the target is retained
+	ActionRetain = "retain"
+	// ActionDelete means the target is deleted, or would be deleted in dry run
+	ActionDelete = "delete"
+	// ActionSkip means the action isn't taken on the target
+	ActionSkip = "skip"
+)
+
+const (
+	// ReasonRetained : the target is retained by a rule
+	ReasonRetained = "retained"
+	// ReasonNotRetained : the target isn't retained by any rule
+	ReasonNotRetained = "not_retained"
+	// ReasonMatched : the target is matched by the rule deleting the targets
+	ReasonMatched = "matched"
+	// ReasonAlreadyDeleted : the target had been deleted by others before the action was taken
+	ReasonAlreadyDeleted = "already_deleted"
+	// ReasonDeletionFailed : the target failed to be deleted
+	ReasonDeletionFailed = "deletion_failed"
+	// ReasonStopped : the action isn't taken as the job is stopped
+	ReasonStopped = "stopped"
+	// ReasonImmutable : the target is retained as it's protected by the immutable tag rules
+	ReasonImmutable = "immutable"
+	// ReasonUndeletable : the target would fail to be deleted, only reported by the validated dry run
+	ReasonUndeletable = "undeletable"
+)
+
+// Reason explains why the action is taken on the target
+type Reason struct {
+	// The machine readable code
+	Code string `json:"code"`
+	// The human readable message
+	Message string `json:"message"`
+}
+
 // Result keeps the action result
 type Result struct {
 	Target *Candidate `json:"target"`
 	// nil error means success
 	Error error `json:"error"`
+	// The target had been deleted by others before the action was taken
+	AlreadyDeleted bool `json:"already_deleted"`
+	// The size in bytes which would be freed by deleting the target along with the other deleting ones, the
+	// layers shared among them are counted under only one of them. Only populated in dry run
+	SizeBytes int64 `json:"size_bytes"`
+	// The action taken on the target: retain, delete or skip
+	Action string `json:"action,omitempty"`
+	// Why the action is taken on the target
+	Reason *Reason `json:"reason,omitempty"`
+	// The ID of the rule retaining the target, only populated for the retained ones
+	RuleID int `json:"rule_id,omitempty"`
+	// The time the action started and ended
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
 }
@@ -0,0 +1,63 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package art
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResultJSON tests the consumers of the legacy result still parse the new one
+func TestResultJSON(t *testing.T) {
+	now := time.Now().UTC()
+	data, err := json.Marshal(&Result{
+		Target:         &Candidate{Namespace: "library", Repository: "hello-world", Tag: "latest"},
+		AlreadyDeleted: true,
+		SizeBytes:      1024,
+		Action:         ActionSkip,
+		Reason:         &Reason{Code: ReasonAlreadyDeleted, Message: "deleted by others"},
+		StartTime:      now,
+		EndTime:        now,
+	})
+	require.Nil(t, err)
+
+	legacy := &struct {
+		Target         *Candidate `json:"target"`
+		AlreadyDeleted bool       `json:"already_deleted"`
+		SizeBytes      int64      `json:"size_bytes"`
+	}{}
+	require.Nil(t, json.Unmarshal(data, legacy))
+	require.NotNil(t, legacy.Target)
+	assert.Equal(t, "latest", legacy.Target.Tag)
+	assert.True(t, legacy.AlreadyDeleted)
+	assert.Equal(t, int64(1024), legacy.SizeBytes)
+
+	result := &Result{}
+	require.Nil(t, json.Unmarshal(data, result))
+	assert.Equal(t, ActionSkip, result.Action)
+	require.NotNil(t, result.Reason)
+	assert.Equal(t, ReasonAlreadyDeleted, result.Reason.Code)
+	assert.True(t, now.Equal(result.StartTime))
+
+	// the action and the reason are omitted if they're empty
+	data, err = json.Marshal(&Result{})
+	require.Nil(t, err)
+	assert.NotContains(t, string(data), "action")
+	assert.NotContains(t, string(data), "reason")
+}
@@ -0,0 +1,111 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package art
+
+// Summary aggregates the results of the retention run, e.g: "deleted 1,234 tags, retained 5,678,
+// reclaimed ~82GB"
+type Summary struct {
+	// The number of all the candidates
+	Total int `json:"total"`
+	// The number of the candidates left after the run
+	Retained int `json:"retained"`
+	// The number of the candidates deleted
+	Deleted int `json:"deleted"`
+	// The number of the candidates skipped, e.g: immutable, deleted by others or stopped
+	Skipped int `json:"skipped"`
+	// The number of the candidates failed to be deleted
+	Failed int `json:"failed"`
+	// The size in bytes reclaimed by the deleted candidates, the candidates with the same
+	// digest are counted only once
+	ReclaimedBytes int64 `json:"reclaimed_bytes"`
+	// The number of the deleted candidates whose sizes are unknown and not counted in ReclaimedBytes
+	UnknownSize int `json:"unknown_size"`
+	// The summary is an estimate as it comes from a dry run
+	Estimated bool `json:"estimated"`
+}
+
+// Summarize aggregates the results of the candidates, the candidates without result are retained.
+// The size of the deleted candidate is read from the candidate itself and falls back to the size
+// of the result.
+func Summarize(candidates []*Candidate, results []*Result, estimated bool) *Summary {
+	s := &Summary{
+		Total:     len(candidates),
+		Estimated: estimated,
+	}
+
+	var gone int
+	counted := make(map[string]bool)
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		switch {
+		case r.AlreadyDeleted:
+			s.Skipped++
+			gone++
+		case r.Action == ActionSkip:
+			s.Skipped++
+		case r.Error != nil:
+			s.Failed++
+		case r.Action == ActionDelete:
+			s.Deleted++
+			s.reclaim(r, counted)
+		}
+	}
+	s.Retained = s.Total - s.Deleted - gone
+	if s.Retained < 0 {
+		s.Retained = 0
+	}
+
+	return s
+}
+
+// reclaim adds the size of the deleted candidate
+func (s *Summary) reclaim(r *Result, counted map[string]bool) {
+	if r.Target != nil && len(r.Target.Digest) > 0 {
+		if counted[r.Target.Digest] {
+			return
+		}
+		counted[r.Target.Digest] = true
+	}
+
+	var size int64
+	if r.Target != nil {
+		size = r.Target.Size
+	}
+	if size <= 0 {
+		size = r.SizeBytes
+	}
+	if size <= 0 {
+		s.UnknownSize++
+		return
+	}
+	s.ReclaimedBytes += size
+}
+
+// Merge adds up the other summary, the merged summary is an estimate if either one is
+func (s *Summary) Merge(other *Summary) {
+	if other == nil {
+		return
+	}
+	s.Total += other.Total
+	s.Retained += other.Retained
+	s.Deleted += other.Deleted
+	s.Skipped += other.Skipped
+	s.Failed += other.Failed
+	s.ReclaimedBytes += other.ReclaimedBytes
+	s.UnknownSize += other.UnknownSize
+	s.Estimated = s.Estimated || other.Estimated
+}
@@ -0,0 +1,108 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package art
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSummarize tests the counts of the actions and the size reclaimed by the deleted candidates
+func TestSummarize(t *testing.T) {
+	candidates := []*Candidate{
+		{Tag: "1.0", Digest: "sha256:a", Size: 100},
+		{Tag: "stable", Digest: "sha256:a", Size: 100},
+		{Tag: "2.0", Digest: "sha256:b"},
+		{Tag: "3.0", Digest: "sha256:c"},
+		{Tag: "4.0", Digest: "sha256:d", Size: 400},
+		{Tag: "5.0", Digest: "sha256:e", Size: 500},
+		{Tag: "6.0", Digest: "sha256:f", Size: 600},
+		{Tag: "7.0", Digest: "sha256:g", Size: 700},
+		{Tag: "8.0", Digest: "sha256:h", Size: 800},
+		{Tag: "latest", Digest: "sha256:i", Size: 900},
+	}
+	results := []*Result{
+		// the same manifest is reclaimed only once
+		{Target: candidates[0], Action: ActionDelete},
+		{Target: candidates[1], Action: ActionDelete},
+		// the size falls back to the one of the result
+		{Target: candidates[2], Action: ActionDelete, SizeBytes: 20},
+		// the size is unknown
+		{Target: candidates[3], Action: ActionDelete},
+		{Target: candidates[4], Action: ActionDelete, Error: errors.New("failed")},
+		{Target: candidates[5], Action: ActionDelete, Error: errors.New("failed")},
+		{Target: candidates[6], Action: ActionSkip, Reason: &Reason{Code: ReasonImmutable}},
+		{Target: candidates[7], Action: ActionSkip, AlreadyDeleted: true, Reason: &Reason{Code: ReasonAlreadyDeleted}},
+		{Target: candidates[8], Action: ActionSkip, Error: errors.New("stopped"), Reason: &Reason{Code: ReasonStopped}},
+	}
+
+	s := Summarize(candidates, results, false)
+	assert.Equal(t, 10, s.Total)
+	assert.Equal(t, 4, s.Deleted)
+	assert.Equal(t, 2, s.Failed)
+	assert.Equal(t, 3, s.Skipped)
+	// 10 - 4 deleted - 1 deleted by others
+	assert.Equal(t, 5, s.Retained)
+	assert.Equal(t, int64(120), s.ReclaimedBytes)
+	assert.Equal(t, 1, s.UnknownSize)
+	assert.False(t, s.Estimated)
+
+	s = Summarize(candidates, nil, true)
+	assert.Equal(t, 10, s.Total)
+	assert.Equal(t, 10, s.Retained)
+	assert.Equal(t, int64(0), s.ReclaimedBytes)
+	assert.True(t, s.Estimated)
+}
+
+// TestSummarizeUnknownSize tests the deleted candidates without size are counted but not reclaimed
+func TestSummarizeUnknownSize(t *testing.T) {
+	candidates := []*Candidate{
+		{Tag: "1.0", Digest: "sha256:a"},
+		{Tag: "2.0", Digest: "sha256:b", Size: -1},
+		{Tag: "3.0"},
+	}
+	results := make([]*Result, 0, len(candidates))
+	for _, c := range candidates {
+		results = append(results, &Result{Target: c, Action: ActionDelete})
+	}
+
+	s := Summarize(candidates, results, true)
+	assert.Equal(t, 3, s.Deleted)
+	assert.Equal(t, 0, s.Retained)
+	assert.Equal(t, int64(0), s.ReclaimedBytes)
+	assert.Equal(t, 3, s.UnknownSize)
+	assert.True(t, s.Estimated)
+}
+
+// TestSummaryMerge tests the summaries of the tasks are added up
+func TestSummaryMerge(t *testing.T) {
+	s := &Summary{}
+	s.Merge(&Summary{Total: 3, Retained: 1, Deleted: 2, ReclaimedBytes: 100, UnknownSize: 1})
+	s.Merge(&Summary{Total: 2, Retained: 1, Skipped: 1, Failed: 1, Estimated: true})
+	s.Merge(nil)
+
+	assert.Equal(t, &Summary{
+		Total:          5,
+		Retained:       2,
+		Deleted:        2,
+		Skipped:        1,
+		Failed:         1,
+		ReclaimedBytes: 100,
+		UnknownSize:    1,
+		Estimated:      true,
+	}, s)
+}
@@ -15,6 +15,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -31,13 +32,18 @@ import (
 type Client interface {
 	ImageClient
 	ChartClient
+	// WithContext returns a client whose requests are bound to the context
+	WithContext(ctx context.Context) Client
 }
 
 // ImageClient defines the methods that an image client should implement
 type ImageClient interface {
 	ListAllImages(project, repository string) ([]*models.TagResp, error)
+	GetImage(project, repository, tag string) (*models.TagResp, error)
 	DeleteImage(project, repository, tag string) error
+	DeleteImages(project, repository string, tags []string) (*models.TagsDeletionResult, error)
 	DeleteImageRepository(project, repository string) error
+	GetReclaimableSizes(project, repository string, tags []string) (map[string]int64, error)
 }
 
 // ChartClient defines the methods that a chart client should implement
@@ -60,6 +66,13 @@ type client struct {
 	httpclient *chttp.Client
 }
 
+func (c *client) WithContext(ctx context.Context) Client {
+	return &client{
+		url:        c.url,
+		httpclient: c.httpclient.WithContext(ctx),
+	}
+}
+
 func (c *client) buildURL(path string) string {
 	return fmt.Sprintf("%s/%s", c.url, path)
 }
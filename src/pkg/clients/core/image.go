@@ -16,6 +16,7 @@ package core
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/goharbor/harbor/src/common/models"
 )
@@ -29,11 +30,45 @@ func (c *client) ListAllImages(project, repository string) ([]*models.TagResp, e
 	return images, nil
 }
 
+func (c *client) GetImage(project, repository, tag string) (*models.TagResp, error) {
+	url := c.buildURL(fmt.Sprintf("/api/repositories/%s/%s/tags/%s", project, repository, tag))
+	image := &models.TagResp{}
+	if err := c.httpclient.Get(url, image); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
 func (c *client) DeleteImage(project, repository, tag string) error {
 	url := c.buildURL(fmt.Sprintf("/api/repositories/%s/%s/tags/%s", project, repository, tag))
 	return c.httpclient.Delete(url)
 }
 
+// DeleteImages deletes the tags of the repository in one request
+func (c *client) DeleteImages(project, repository string, tags []string) (*models.TagsDeletionResult, error) {
+	query := url.Values{}
+	for _, tag := range tags {
+		query.Add("tag", tag)
+	}
+	url := c.buildURL(fmt.Sprintf("/api/repositories/%s/%s/tags?%s", project, repository, query.Encode()))
+	result := &models.TagsDeletionResult{}
+	if err := c.httpclient.Delete(url, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetReclaimableSizes returns the sizes freed by deleting the tags all together keyed by the manifest digests,
+// the tags without tracked artifacts are absent
+func (c *client) GetReclaimableSizes(project, repository string, tags []string) (map[string]int64, error) {
+	url := c.buildURL(fmt.Sprintf("/api/repositories/%s/%s/reclaimable", project, repository))
+	sizes := map[string]int64{}
+	if err := c.httpclient.Post(url, &models.ReclaimableSizesRequest{Tags: tags}, &sizes); err != nil {
+		return nil, err
+	}
+	return sizes, nil
+}
+
 func (c *client) DeleteImageRepository(project, repository string) error {
 	url := c.buildURL(fmt.Sprintf("/api/repositories/%s/%s", project, repository))
 	return c.httpclient.Delete(url)
@@ -2,6 +2,7 @@ package hook
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,13 +13,72 @@ import (
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/notifier/model"
 	"github.com/goharbor/harbor/src/core/utils"
+	jsJob "github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/pkg/notification/job"
 	"github.com/goharbor/harbor/src/pkg/notification/job/manager"
 )
 
+var (
+	// DeliveryTimeout is the timeout of waiting for the result of the test delivery
+	DeliveryTimeout = 10 * time.Second
+	// ErrDeliveryTimeout is returned if the result of the test delivery isn't reported in time
+	ErrDeliveryTimeout = errors.New("timeout waiting for the result of the delivery")
+	// the interval of polling the result of the test delivery
+	deliveryPollInterval = 500 * time.Millisecond
+)
+
 // Manager send hook
 type Manager interface {
 	StartHook(*model.HookEvent, *models.JobData) error
+	// DeliverTest sends a test payload to the target of the event by the webhook job and
+	// waits for the result, the internal address of the target is rejected by the job
+	DeliverTest(event *model.HookEvent) (*DeliveryResult, error)
+}
+
+// TestPayload is the synthetic payload sent by the test delivery
+type TestPayload struct {
+	EventType string `json:"event_type"`
+	Payload   string `json:"payload"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// NewTestPayload returns the payload for the test delivery
+func NewTestPayload() *TestPayload {
+	return &TestPayload{
+		EventType: "test",
+		Payload:   "harbor-webhook-test",
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// DeliveryResult is the result of the test delivery checked in by the webhook job, the status
+// code is 0 if no response is received
+type DeliveryResult struct {
+	Delivered  bool  `json:"delivered"`
+	StatusCode int   `json:"status_code"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// NewWebhookJobData returns the data of the webhook job which sends the payload of the event to its target
+func NewWebhookJobData(event *model.HookEvent) (*models.JobData, error) {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal from payload %v failed: %v", event.Payload, err)
+	}
+	return &models.JobData{
+		Name: jsJob.WebhookJob,
+		Metadata: &models.JobMetadata{
+			JobKind: jsJob.KindGeneric,
+		},
+		Parameters: map[string]interface{}{
+			"payload": string(payload),
+			"address": event.Target.Address,
+			// Users can define a auth header in http statement in notification(webhook) policy.
+			// So it will be sent in header in http request.
+			"auth_header":      event.Target.AuthHeader,
+			"skip_cert_verify": event.Target.SkipCertVerify,
+		},
+	}, nil
 }
 
 // DefaultManager ...
@@ -41,7 +101,67 @@ func (hm *DefaultManager) StartHook(event *model.HookEvent, data *models.JobData
 	if err != nil {
 		return err
 	}
+	_, err = hm.submit(event, data, string(payload))
+	return err
+}
+
+// DeliverTest submits the webhook job with the test payload, the job isn't retried and the
+// result is checked in by the job, which is kept in the detail of the job record by core
+func (hm *DefaultManager) DeliverTest(event *model.HookEvent) (*DeliveryResult, error) {
+	if event == nil || event.Target == nil {
+		return nil, errors.New("empty target of the event")
+	}
+	data, err := NewWebhookJobData(event)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(NewTestPayload())
+	if err != nil {
+		return nil, err
+	}
+	data.Parameters["payload"] = string(payload)
+	data.Metadata.MaxFails = 1
+	data.Parameters["block_internal_address"] = true
 
+	// the detail of the test job is left empty until the result is checked in
+	id, err := hm.submit(event, data, "")
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(DeliveryTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(deliveryPollInterval)
+		j, err := hm.jobMgr.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if j == nil {
+			return nil, fmt.Errorf("notification job %d not found", id)
+		}
+		if len(j.JobDetail) == 0 {
+			continue
+		}
+		// the detail is the check-in of the webhook job
+		var delivery struct {
+			StatusCode int   `json:"status_code"`
+			DurationMS int64 `json:"duration_ms"`
+			Success    bool  `json:"success"`
+		}
+		if err = json.Unmarshal([]byte(j.JobDetail), &delivery); err != nil {
+			return nil, fmt.Errorf("failed to resolve the result of notification job %d: %v", id, err)
+		}
+		return &DeliveryResult{
+			Delivered:  delivery.Success,
+			StatusCode: delivery.StatusCode,
+			DurationMS: delivery.DurationMS,
+		}, nil
+	}
+	return nil, ErrDeliveryTimeout
+}
+
+// submit creates the job record with the detail and submits the job to jobservice
+func (hm *DefaultManager) submit(event *model.HookEvent, data *models.JobData, detail string) (int64, error) {
 	t := time.Now()
 	id, err := hm.jobMgr.Create(&cModels.NotificationJob{
 		PolicyID:     event.PolicyID,
@@ -50,10 +170,10 @@ func (hm *DefaultManager) StartHook(event *model.HookEvent, data *models.JobData
 		Status:       cModels.JobPending,
 		CreationTime: t,
 		UpdateTime:   t,
-		JobDetail:    string(payload),
+		JobDetail:    detail,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create the job record for notification based on policy %d: %v", event.PolicyID, err)
+		return 0, fmt.Errorf("failed to create the job record for notification based on policy %d: %v", event.PolicyID, err)
 	}
 	statusHookURL := fmt.Sprintf("%s/service/notifications/jobs/webhook/%d", config.InternalCoreURL(), id)
 	data.StatusHook = statusHookURL
@@ -71,7 +191,7 @@ func (hm *DefaultManager) StartHook(event *model.HookEvent, data *models.JobData
 		if e != nil {
 			log.Errorf("failed to update the notification job status %d: %v", id, e)
 		}
-		return err
+		return 0, err
 	}
 
 	if err = hm.jobMgr.Update(&cModels.NotificationJob{
@@ -79,7 +199,7 @@ func (hm *DefaultManager) StartHook(event *model.HookEvent, data *models.JobData
 		UUID: jobUUID,
 	}, "UUID"); err != nil {
 		log.Errorf("failed to update the notification job %d: %v", id, err)
-		return err
+		return 0, err
 	}
-	return nil
+	return id, nil
 }
@@ -0,0 +1,177 @@
+package hook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common"
+	cJob "github.com/goharbor/harbor/src/common/job"
+	"github.com/goharbor/harbor/src/common/job/models"
+	cModels "github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/notifier/model"
+	jsJob "github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakedJobMgr struct {
+	jobs []*cModels.NotificationJob
+}
+
+func (f *fakedJobMgr) Create(job *cModels.NotificationJob) (int64, error) {
+	f.jobs = append(f.jobs, job)
+	job.ID = int64(len(f.jobs))
+	return job.ID, nil
+}
+
+func (f *fakedJobMgr) List(...*cModels.NotificationJobQuery) (int64, []*cModels.NotificationJob, error) {
+	return int64(len(f.jobs)), f.jobs, nil
+}
+
+func (f *fakedJobMgr) Update(job *cModels.NotificationJob, props ...string) error {
+	j := f.jobs[job.ID-1]
+	for _, prop := range props {
+		switch prop {
+		case "UUID":
+			j.UUID = job.UUID
+		case "Status":
+			j.Status = job.Status
+		case "JobDetail":
+			j.JobDetail = job.JobDetail
+		}
+	}
+	return nil
+}
+
+func (f *fakedJobMgr) ListJobsGroupByEventType(policyID int64) ([]*cModels.NotificationJob, error) {
+	return nil, nil
+}
+
+func (f *fakedJobMgr) Get(id int64) (*cModels.NotificationJob, error) {
+	if id < 1 || int(id) > len(f.jobs) {
+		return nil, nil
+	}
+	return f.jobs[id-1], nil
+}
+
+func (f *fakedJobMgr) CreateDeliveryLog(log *cModels.WebhookDeliveryLog) (int64, error) {
+	return 1, nil
+}
+
+func (f *fakedJobMgr) GetDeliveryStats(policyID int64, days int) (*cModels.WebhookDeliveryStats, error) {
+	return nil, nil
+}
+
+// fakedJobClient checks in the result of the webhook job as core does when the job is submitted
+type fakedJobClient struct {
+	cJob.Client
+	jobMgr    *fakedJobMgr
+	checkIn   string
+	err       error
+	submitted []*models.JobData
+}
+
+func (f *fakedJobClient) SubmitJob(data *models.JobData) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.submitted = append(f.submitted, data)
+	if len(f.checkIn) > 0 {
+		f.jobMgr.jobs[len(f.jobMgr.jobs)-1].JobDetail = f.checkIn
+	}
+	return "uuid", nil
+}
+
+func newTestEvent() *model.HookEvent {
+	return &model.HookEvent{
+		EventType: "testEndpoint",
+		Target: &cModels.EventTarget{
+			Type:       "http",
+			Address:    "https://example.com/webhook",
+			AuthHeader: "Bearer token",
+		},
+	}
+}
+
+func TestMain(m *testing.M) {
+	config.InitWithSettings(map[string]interface{}{
+		common.CoreURL: "http://core:8080",
+	})
+	deliveryPollInterval = time.Millisecond
+	os.Exit(m.Run())
+}
+
+func TestDeliverTest(t *testing.T) {
+	cases := []struct {
+		checkIn string
+		result  *DeliveryResult
+	}{
+		{
+			checkIn: `{"status_code":200,"duration_ms":15,"success":true}`,
+			result:  &DeliveryResult{Delivered: true, StatusCode: http.StatusOK, DurationMS: 15},
+		},
+		{
+			checkIn: `{"status_code":404,"duration_ms":5,"success":false}`,
+			result:  &DeliveryResult{Delivered: false, StatusCode: http.StatusNotFound, DurationMS: 5},
+		},
+		{
+			checkIn: `{"status_code":0,"duration_ms":1,"success":false}`,
+			result:  &DeliveryResult{Delivered: false, StatusCode: 0, DurationMS: 1},
+		},
+	}
+
+	for _, c := range cases {
+		jobMgr := &fakedJobMgr{}
+		client := &fakedJobClient{jobMgr: jobMgr, checkIn: c.checkIn}
+		hm := &DefaultManager{jobMgr: jobMgr, client: client}
+
+		result, err := hm.DeliverTest(newTestEvent())
+		require.Nil(t, err)
+		assert.Equal(t, c.result, result)
+
+		// the test delivery is sent by the webhook job without retry
+		require.Equal(t, 1, len(jobMgr.jobs))
+		assert.Equal(t, "uuid", jobMgr.jobs[0].UUID)
+		require.Equal(t, 1, len(client.submitted))
+		data := client.submitted[0]
+		assert.Equal(t, jsJob.WebhookJob, data.Name)
+		assert.Equal(t, uint(1), data.Metadata.MaxFails)
+		assert.Equal(t, "http://core:8080/service/notifications/jobs/webhook/1", data.StatusHook)
+		assert.Equal(t, true, data.Parameters["block_internal_address"])
+		assert.Equal(t, "https://example.com/webhook", data.Parameters["address"])
+		assert.Equal(t, "Bearer token", data.Parameters["auth_header"])
+		payload := &TestPayload{}
+		require.Nil(t, json.Unmarshal([]byte(data.Parameters["payload"].(string)), payload))
+		assert.Equal(t, "test", payload.EventType)
+		assert.Equal(t, "harbor-webhook-test", payload.Payload)
+	}
+}
+
+func TestDeliverTestFailed(t *testing.T) {
+	_, err := (&DefaultManager{}).DeliverTest(&model.HookEvent{})
+	assert.NotNil(t, err)
+
+	// failed to submit the job
+	jobMgr := &fakedJobMgr{}
+	hm := &DefaultManager{jobMgr: jobMgr, client: &fakedJobClient{jobMgr: jobMgr, err: errors.New("unavailable")}}
+	_, err = hm.DeliverTest(newTestEvent())
+	assert.NotNil(t, err)
+	require.Equal(t, 1, len(jobMgr.jobs))
+	assert.Equal(t, cModels.JobError, jobMgr.jobs[0].Status)
+
+	// the result isn't checked in
+	timeout := DeliveryTimeout
+	defer func() {
+		DeliveryTimeout = timeout
+	}()
+	DeliveryTimeout = 10 * time.Millisecond
+	jobMgr = &fakedJobMgr{}
+	hm = &DefaultManager{jobMgr: jobMgr, client: &fakedJobClient{jobMgr: jobMgr}}
+	_, err = hm.DeliverTest(newTestEvent())
+	assert.Equal(t, ErrDeliveryTimeout, err)
+}
@@ -17,4 +17,13 @@ type Manager interface {
 
 	// ListJobsGroupByEventType lists last triggered jobs group by event type
 	ListJobsGroupByEventType(policyID int64) ([]*models.NotificationJob, error)
+
+	// Get get the notification job specified by ID
+	Get(id int64) (*models.NotificationJob, error)
+
+	// CreateDeliveryLog records the result of one delivery of webhook
+	CreateDeliveryLog(log *models.WebhookDeliveryLog) (int64, error)
+
+	// GetDeliveryStats gets the delivery statistics of the policy in the last days
+	GetDeliveryStats(policyID int64, days int) (*models.WebhookDeliveryStats, error)
 }
@@ -53,3 +53,18 @@ func (d *DefaultManager) Update(job *models.NotificationJob, props ...string) er
 func (d *DefaultManager) ListJobsGroupByEventType(policyID int64) ([]*models.NotificationJob, error) {
 	return notification.GetLastTriggerJobsGroupByEventType(policyID)
 }
+
+// Get ...
+func (d *DefaultManager) Get(id int64) (*models.NotificationJob, error) {
+	return notification.GetNotificationJob(id)
+}
+
+// CreateDeliveryLog ...
+func (d *DefaultManager) CreateDeliveryLog(log *models.WebhookDeliveryLog) (int64, error) {
+	return notification.AddWebhookDeliveryLog(log)
+}
+
+// GetDeliveryStats ...
+func (d *DefaultManager) GetDeliveryStats(policyID int64, days int) (*models.WebhookDeliveryStats, error) {
+	return notification.GetWebhookDeliveryStats(policyID, days)
+}
@@ -11,6 +11,8 @@ const (
 	EventTypeScanningCompleted = "scanningCompleted"
 	EventTypeScanningFailed    = "scanningFailed"
 	EventTypeTestEndpoint      = "testEndpoint"
+	EventTypeAdminJobStatus    = "adminJobStatus"
+	EventTypeArtifactDeleted   = "artifactDeleted"
 
 	NotifyTypeHTTP = "http"
 )
@@ -0,0 +1,97 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"sync"
+)
+
+// the status of the check result
+const (
+	StatusPassed  = "passed"
+	StatusWarning = "warning"
+	StatusFailed  = "failed"
+)
+
+// CheckResult is the result of an upgrade check
+type CheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Report is the result of all the upgrade checks, it's not safe to upgrade if any check fails
+type Report struct {
+	SafeToUpgrade bool           `json:"safe_to_upgrade"`
+	Checks        []*CheckResult `json:"checks"`
+}
+
+// UpgradeCheck checks one prerequisite of upgrading Harbor
+type UpgradeCheck interface {
+	Run() CheckResult
+}
+
+var (
+	lock         sync.RWMutex
+	customChecks []UpgradeCheck
+)
+
+// RegisterUpgradeCheck registers a custom check which is run after the built-in ones
+func RegisterUpgradeCheck(check UpgradeCheck) {
+	lock.Lock()
+	defer lock.Unlock()
+	customChecks = append(customChecks, check)
+}
+
+// RunChecks runs the built-in checks against the target version and the custom checks
+func RunChecks(targetVersion string) *Report {
+	checks := []UpgradeCheck{
+		NewSchemaVersionCheck(targetVersion),
+		NewAdminJobCheck(),
+		NewRegistryStorageCheck(),
+		NewJobServiceCheck(),
+		NewUpgradeTimeCheck(),
+	}
+	lock.RLock()
+	checks = append(checks, customChecks...)
+	lock.RUnlock()
+	return run(checks)
+}
+
+// run runs the checks concurrently and keeps the order of them in the report
+func run(checks []UpgradeCheck) *Report {
+	results := make([]*CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check UpgradeCheck) {
+			defer wg.Done()
+			result := check.Run()
+			results[i] = &result
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := &Report{
+		SafeToUpgrade: true,
+		Checks:        results,
+	}
+	for _, result := range results {
+		if result.Status == StatusFailed {
+			report.SafeToUpgrade = false
+		}
+	}
+	return report
+}
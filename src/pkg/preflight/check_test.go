@@ -0,0 +1,201 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCheck struct {
+	result CheckResult
+}
+
+func (f *fakeCheck) Run() CheckResult {
+	return f.result
+}
+
+func TestSchemaVersionCheck(t *testing.T) {
+	newCheck := func(target string, version *models.MigrationVersion, err error) *SchemaVersionCheck {
+		return &SchemaVersionCheck{
+			targetVersion: target,
+			getVersion: func() (*models.MigrationVersion, error) {
+				return version, err
+			},
+		}
+	}
+
+	result := newCheck("", nil, errors.New("error")).Run()
+	assert.Equal(t, StatusFailed, result.Status)
+
+	// dirty
+	result = newCheck("", &models.MigrationVersion{Version: 15, Dirty: true}, nil).Run()
+	assert.Equal(t, StatusFailed, result.Status)
+
+	// not fully migrated
+	result = newCheck("", &models.MigrationVersion{Version: 11}, nil).Run()
+	assert.Equal(t, StatusFailed, result.Status)
+
+	// downgrade
+	result = newCheck("v1.9.3", &models.MigrationVersion{Version: 15}, nil).Run()
+	assert.Equal(t, StatusFailed, result.Status)
+
+	// invalid target version
+	result = newCheck("latest", &models.MigrationVersion{Version: 15}, nil).Run()
+	assert.Equal(t, StatusFailed, result.Status)
+
+	// passed
+	result = newCheck("", &models.MigrationVersion{Version: 15}, nil).Run()
+	assert.Equal(t, StatusPassed, result.Status)
+	result = newCheck("v2.0.0-rc1", &models.MigrationVersion{Version: 15}, nil).Run()
+	assert.Equal(t, "schema_version", result.Name)
+	assert.Equal(t, StatusPassed, result.Status)
+	assert.Equal(t, "the schema version is 15 (Harbor 1.10.0)", result.Detail)
+}
+
+func TestAdminJobCheck(t *testing.T) {
+	var jobs []*models.AdminJob
+	check := &AdminJobCheck{
+		listRunning: func() ([]*models.AdminJob, error) {
+			return jobs, nil
+		},
+	}
+	result := check.Run()
+	assert.Equal(t, "admin_jobs", result.Name)
+	assert.Equal(t, StatusPassed, result.Status)
+
+	jobs = []*models.AdminJob{{ID: 1, Name: "IMAGE_GC"}}
+	result = check.Run()
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Contains(t, result.Detail, "IMAGE_GC(1)")
+}
+
+func TestRegistryStorageCheck(t *testing.T) {
+	code := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/_catalog", r.URL.Path)
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+
+	check := &RegistryStorageCheck{
+		url:    server.URL + "/v2/_catalog?n=1",
+		client: server.Client(),
+	}
+	result := check.Run()
+	assert.Equal(t, "registry_storage", result.Name)
+	assert.Equal(t, StatusPassed, result.Status)
+
+	code = http.StatusServiceUnavailable
+	result = check.Run()
+	assert.Equal(t, StatusFailed, result.Status)
+}
+
+func TestJobServiceCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	check := &JobServiceCheck{
+		url:    server.URL + "/api/v1/stats",
+		client: server.Client(),
+	}
+	result := check.Run()
+	assert.Equal(t, "job_service", result.Name)
+	assert.Equal(t, StatusPassed, result.Status)
+
+	// unreachable
+	server.Close()
+	result = check.Run()
+	assert.Equal(t, StatusFailed, result.Status)
+}
+
+func TestUpgradeTimeCheck(t *testing.T) {
+	var count int64 = 1000
+	check := &UpgradeTimeCheck{
+		countArtifacts: func() (int64, error) {
+			return count, nil
+		},
+	}
+	result := check.Run()
+	assert.Equal(t, "upgrade_time", result.Name)
+	assert.Equal(t, StatusPassed, result.Status)
+	assert.Equal(t, "the upgrade of 1000 artifacts is estimated to take about 1m0s", result.Detail)
+
+	count = 1000000
+	result = check.Run()
+	assert.Equal(t, StatusWarning, result.Status)
+
+	check.countArtifacts = func() (int64, error) {
+		return 0, errors.New("error")
+	}
+	result = check.Run()
+	assert.Equal(t, StatusFailed, result.Status)
+}
+
+func TestRun(t *testing.T) {
+	report := run([]UpgradeCheck{
+		&fakeCheck{CheckResult{Name: "a", Status: StatusPassed}},
+		&fakeCheck{CheckResult{Name: "b", Status: StatusWarning}},
+	})
+	assert.True(t, report.SafeToUpgrade)
+	require.Equal(t, 2, len(report.Checks))
+	assert.Equal(t, "a", report.Checks[0].Name)
+	assert.Equal(t, "b", report.Checks[1].Name)
+
+	report = run([]UpgradeCheck{
+		&fakeCheck{CheckResult{Name: "a", Status: StatusPassed}},
+		&fakeCheck{CheckResult{Name: "b", Status: StatusFailed}},
+	})
+	assert.False(t, report.SafeToUpgrade)
+}
+
+func TestRegisterUpgradeCheck(t *testing.T) {
+	defer func() {
+		customChecks = nil
+	}()
+	check := &fakeCheck{CheckResult{Name: "custom", Status: StatusPassed}}
+	RegisterUpgradeCheck(check)
+	require.Equal(t, 1, len(customChecks))
+	assert.Equal(t, check, customChecks[0])
+}
+
+func TestVersionOlder(t *testing.T) {
+	cases := []struct {
+		a, b  string
+		older bool
+	}{
+		{"1.9.0", "1.10.0", true},
+		{"v1.10.0", "1.10.0", false},
+		{"v1.10", "1.10.0", false},
+		{"2.0.0-rc1", "1.10.0", false},
+		{"1.10.0", "1.10.1", true},
+	}
+	for _, c := range cases {
+		older, err := versionOlder(c.a, c.b)
+		require.Nil(t, err)
+		assert.Equal(t, c.older, older, "%s < %s", c.a, c.b)
+	}
+
+	_, err := versionOlder("1.a.0", "1.10.0")
+	assert.NotNil(t, err)
+	_, err = versionOlder("1.10.0.1", "1.10.0")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,297 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/registry"
+	"github.com/goharbor/harbor/src/common/utils/registry/auth"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/service/token"
+	"github.com/pkg/errors"
+)
+
+const (
+	// the timeout of the requests sent to the other components
+	requestTimeout = 10 * time.Second
+	// the estimated time to migrate the data of one artifact and the fixed part
+	upgradeTimePerArtifact = 10 * time.Millisecond
+	upgradeTimeBase        = time.Minute
+	// the estimated upgrade time longer than this is reported as a warning
+	upgradeTimeWarning = time.Hour
+)
+
+// schemaRelease maps the version of the schema migration to the Harbor release introducing it
+type schemaRelease struct {
+	version int64
+	release string
+}
+
+// schemaReleases are ordered by the version, the last one is expected by the running Harbor
+var schemaReleases = []schemaRelease{
+	{1, "1.6.0"},
+	{2, "1.7.0"},
+	{4, "1.8.0"},
+	{5, "1.8.2"},
+	{10, "1.9.0"},
+	{11, "1.9.1"},
+	{15, "1.10.0"},
+}
+
+// SchemaVersionCheck checks the database schema is fully migrated and not dirty, and the
+// target version isn't older than the release of the schema
+type SchemaVersionCheck struct {
+	targetVersion string
+	getVersion    func() (*models.MigrationVersion, error)
+}
+
+// NewSchemaVersionCheck ...
+func NewSchemaVersionCheck(targetVersion string) *SchemaVersionCheck {
+	return &SchemaVersionCheck{
+		targetVersion: targetVersion,
+		getVersion:    dao.GetMigrationVersion,
+	}
+}
+
+// Run ...
+func (s *SchemaVersionCheck) Run() CheckResult {
+	result := CheckResult{Name: "schema_version"}
+	version, err := s.getVersion()
+	if err != nil {
+		return failed(result, "failed to get the schema version: %v", err)
+	}
+	if version.Dirty {
+		return failed(result, "the migration of schema version %d is dirty", version.Version)
+	}
+	latest := schemaReleases[len(schemaReleases)-1]
+	if version.Version < latest.version {
+		return failed(result, "the schema version %d is behind the version %d expected by Harbor %s",
+			version.Version, latest.version, latest.release)
+	}
+	release := releaseOfSchema(version.Version)
+	if len(s.targetVersion) > 0 {
+		older, err := versionOlder(s.targetVersion, release)
+		if err != nil {
+			return failed(result, "invalid target version %s: %v", s.targetVersion, err)
+		}
+		if older {
+			return failed(result, "downgrading from Harbor %s to %s isn't supported", release, s.targetVersion)
+		}
+	}
+	result.Status = StatusPassed
+	result.Detail = fmt.Sprintf("the schema version is %d (Harbor %s)", version.Version, release)
+	return result
+}
+
+// AdminJobCheck checks there is no running admin job, e.g. GC and scanning all
+type AdminJobCheck struct {
+	listRunning func() ([]*models.AdminJob, error)
+}
+
+// NewAdminJobCheck ...
+func NewAdminJobCheck() *AdminJobCheck {
+	return &AdminJobCheck{
+		listRunning: func() ([]*models.AdminJob, error) {
+			return dao.GetAdminJobs(&models.AdminJobQuery{Status: models.JobRunning})
+		},
+	}
+}
+
+// Run ...
+func (a *AdminJobCheck) Run() CheckResult {
+	result := CheckResult{Name: "admin_jobs"}
+	jobs, err := a.listRunning()
+	if err != nil {
+		return failed(result, "failed to list the running admin jobs: %v", err)
+	}
+	if len(jobs) > 0 {
+		var names []string
+		for _, job := range jobs {
+			names = append(names, fmt.Sprintf("%s(%d)", job.Name, job.ID))
+		}
+		return failed(result, "the admin jobs are running: %s", strings.Join(names, ", "))
+	}
+	result.Status = StatusPassed
+	result.Detail = "no admin job is running"
+	return result
+}
+
+// RegistryStorageCheck checks the storage of registry is reachable by listing the repositories
+type RegistryStorageCheck struct {
+	url    string
+	client *http.Client
+}
+
+// NewRegistryStorageCheck ...
+func NewRegistryStorageCheck() *RegistryStorageCheck {
+	endpoint, err := config.RegistryURL()
+	if err != nil {
+		endpoint = ""
+	}
+	authorizer := auth.NewRawTokenAuthorizer("harbor-core", token.Registry)
+	return &RegistryStorageCheck{
+		url: strings.TrimSuffix(endpoint, "/") + "/v2/_catalog?n=1",
+		client: &http.Client{
+			Transport: registry.NewTransport(registry.GetHTTPTransport(), authorizer),
+			Timeout:   requestTimeout,
+		},
+	}
+}
+
+// Run ...
+func (r *RegistryStorageCheck) Run() CheckResult {
+	result := CheckResult{Name: "registry_storage"}
+	if err := get(r.client, r.url); err != nil {
+		return failed(result, "failed to list the repositories from registry: %v", err)
+	}
+	result.Status = StatusPassed
+	result.Detail = "the storage of registry is reachable"
+	return result
+}
+
+// JobServiceCheck checks the job service is reachable
+type JobServiceCheck struct {
+	url    string
+	client *http.Client
+}
+
+// NewJobServiceCheck ...
+func NewJobServiceCheck() *JobServiceCheck {
+	return &JobServiceCheck{
+		url:    config.InternalJobServiceURL() + "/api/v1/stats",
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Run ...
+func (j *JobServiceCheck) Run() CheckResult {
+	result := CheckResult{Name: "job_service"}
+	if err := get(j.client, j.url); err != nil {
+		return failed(result, "the job service isn't reachable: %v", err)
+	}
+	result.Status = StatusPassed
+	result.Detail = "the job service is reachable"
+	return result
+}
+
+// UpgradeTimeCheck estimates the upgrade time based on the count of the artifacts,
+// a warning is reported if it takes longer than an hour
+type UpgradeTimeCheck struct {
+	countArtifacts func() (int64, error)
+}
+
+// NewUpgradeTimeCheck ...
+func NewUpgradeTimeCheck() *UpgradeTimeCheck {
+	return &UpgradeTimeCheck{
+		countArtifacts: func() (int64, error) {
+			return dao.GetTotalOfArtifacts()
+		},
+	}
+}
+
+// Run ...
+func (u *UpgradeTimeCheck) Run() CheckResult {
+	result := CheckResult{Name: "upgrade_time"}
+	count, err := u.countArtifacts()
+	if err != nil {
+		return failed(result, "failed to count the artifacts: %v", err)
+	}
+	estimate := upgradeTimeBase + time.Duration(count)*upgradeTimePerArtifact
+	result.Status = StatusPassed
+	if estimate > upgradeTimeWarning {
+		result.Status = StatusWarning
+	}
+	result.Detail = fmt.Sprintf("the upgrade of %d artifacts is estimated to take about %v", count, estimate.Round(time.Minute))
+	return result
+}
+
+func failed(result CheckResult, format string, args ...interface{}) CheckResult {
+	result.Status = StatusFailed
+	result.Detail = fmt.Sprintf(format, args...)
+	return result
+}
+
+func get(client *http.Client, url string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// releaseOfSchema returns the Harbor release introducing the schema version
+func releaseOfSchema(version int64) string {
+	release := schemaReleases[0].release
+	for _, sr := range schemaReleases {
+		if sr.version > version {
+			break
+		}
+		release = sr.release
+	}
+	return release
+}
+
+// versionOlder returns whether the version a is older than b, both are in the format "v1.2.3" or "1.2.3"
+func versionOlder(a, b string) (bool, error) {
+	va, err := parseVersion(a)
+	if err != nil {
+		return false, err
+	}
+	vb, err := parseVersion(b)
+	if err != nil {
+		return false, err
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			return va[i] < vb[i], nil
+		}
+	}
+	return false, nil
+}
+
+// ValidateVersion validates the version is in the format "v1.2.3" or "1.2.3"
+func ValidateVersion(version string) error {
+	_, err := parseVersion(version)
+	return err
+}
+
+func parseVersion(version string) ([3]int, error) {
+	var v [3]int
+	// drop the prefix "v" and the suffix, e.g. "-rc1"
+	version = strings.SplitN(strings.TrimPrefix(version, "v"), "-", 2)[0]
+	parts := strings.Split(version, ".")
+	if len(parts) > len(v) {
+		return v, errors.Errorf("too many parts in version %s", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, errors.Errorf("invalid part %s in version %s", part, version)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
@@ -0,0 +1,315 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/pkg/art"
+	selectors "github.com/goharbor/harbor/src/pkg/art/selectors/index"
+	"github.com/goharbor/harbor/src/pkg/retention/dao"
+	"github.com/goharbor/harbor/src/pkg/retention/dao/models"
+	"github.com/goharbor/harbor/src/pkg/retention/policy"
+	"github.com/goharbor/harbor/src/pkg/retention/policy/rule"
+	rules "github.com/goharbor/harbor/src/pkg/retention/policy/rule/index"
+	"github.com/pkg/errors"
+)
+
+// TagConflict is the tag which is retained by some rules but deleted by others in the same policy
+type TagConflict struct {
+	Repository  string `json:"repository"`
+	Tag         string `json:"tag"`
+	RetainRules []int  `json:"retain_rules"`
+	DeleteRules []int  `json:"delete_rules"`
+}
+
+// ConflictDetector evaluates the rules of a policy independently against the candidates,
+// the candidates retained by some rules but deleted by others are reported as conflicts.
+// A candidate is deleted by a rule when it is selected by the tag selectors of the rule
+// but not retained by the rule.
+type ConflictDetector struct {
+	rules []*rule.Metadata
+}
+
+// NewConflictDetector returns a detector for the rules
+func NewConflictDetector(rules []*rule.Metadata) *ConflictDetector {
+	return &ConflictDetector{
+		rules: rules,
+	}
+}
+
+// Detect the conflicts among the candidates of one repository
+func (d *ConflictDetector) Detect(candidates []*art.Candidate) ([]*TagConflict, error) {
+	retained := map[string][]int{}
+	deleted := map[string][]int{}
+	for _, r := range d.rules {
+		if r.Disabled {
+			continue
+		}
+		selected, kept, err := evaluateRule(r, candidates)
+		if err != nil {
+			return nil, errors.Wrapf(err, "evaluate rule %d", r.ID)
+		}
+		for _, c := range selected {
+			if _, ok := kept[c.Hash()]; ok {
+				retained[c.Hash()] = append(retained[c.Hash()], r.ID)
+			} else {
+				deleted[c.Hash()] = append(deleted[c.Hash()], r.ID)
+			}
+		}
+	}
+
+	conflicts := []*TagConflict{}
+	for _, c := range candidates {
+		h := c.Hash()
+		if len(retained[h]) == 0 || len(deleted[h]) == 0 {
+			continue
+		}
+		conflicts = append(conflicts, &TagConflict{
+			Repository:  fmt.Sprintf("%s/%s", c.Namespace, c.Repository),
+			Tag:         c.Tag,
+			RetainRules: retained[h],
+			DeleteRules: deleted[h],
+		})
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Tag < conflicts[j].Tag
+	})
+
+	return conflicts, nil
+}
+
+// evaluateRule returns the candidates selected by the tag selectors of the rule and
+// the hashes of the ones retained by the rule
+func evaluateRule(r *rule.Metadata, candidates []*art.Candidate) ([]*art.Candidate, map[string]struct{}, error) {
+	evaluator, err := rules.Get(r.Template, r.Parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	selected := append([]*art.Candidate{}, candidates...)
+	for _, s := range r.TagSelectors {
+		selector, err := selectors.Get(s.Kind, s.Decoration, s.Pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		if selected, err = selector.Select(selected); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// pass a copy as the evaluators may sort the candidates in place
+	processed, err := evaluator.Process(append([]*art.Candidate{}, selected...))
+	if err != nil {
+		return nil, nil, err
+	}
+	kept := make(map[string]struct{}, len(processed))
+	for _, c := range processed {
+		kept[c.Hash()] = struct{}{}
+	}
+
+	return selected, kept, nil
+}
+
+const (
+	// the deadline of the conflict analysis, the running analysis started before it is treated as failed
+	analysisTimeout = 30 * time.Minute
+	// the finished conflict analyses are kept for the period
+	analysisTTL = 24 * time.Hour
+)
+
+// the max number of the candidates analyzed per repository, the repositories with more
+// candidates are reported as truncated
+var maxAnalysisCandidates = 10000
+
+// ErrAnalysisInProgress is returned when analyzing a policy whose analysis is still running
+var ErrAnalysisInProgress = errors.New("the conflict analysis of the retention policy is in progress")
+
+// analysisStore persists the last conflict analysis of the policies
+type analysisStore interface {
+	// Start records the running analysis, it returns false if the last analysis of the policy
+	// is still running and started after the staleBefore
+	Start(a *models.RetentionConflictAnalysis, staleBefore time.Time) (bool, error)
+	// Finish records the result of the run of the analysis
+	Finish(a *models.RetentionConflictAnalysis) error
+	// Get returns the last analysis of the policy, nil is returned if there is none
+	Get(policyID int64) (*models.RetentionConflictAnalysis, error)
+	// DeleteBefore deletes the analyses which end before the time
+	DeleteBefore(t time.Time) error
+}
+
+type dbAnalysisStore struct{}
+
+func (s *dbAnalysisStore) Start(a *models.RetentionConflictAnalysis, staleBefore time.Time) (bool, error) {
+	return dao.StartConflictAnalysis(a, staleBefore)
+}
+
+func (s *dbAnalysisStore) Finish(a *models.RetentionConflictAnalysis) error {
+	return dao.FinishConflictAnalysis(a)
+}
+
+func (s *dbAnalysisStore) Get(policyID int64) (*models.RetentionConflictAnalysis, error) {
+	return dao.GetConflictAnalysis(policyID)
+}
+
+func (s *dbAnalysisStore) DeleteBefore(t time.Time) error {
+	return dao.DeleteConflictAnalysesBefore(t)
+}
+
+// conflictAnalysisResult is the result of the conflict analysis persisted in JSON
+type conflictAnalysisResult struct {
+	Conflicts             []*TagConflict `json:"conflicts"`
+	TruncatedRepositories []string       `json:"truncated_repositories,omitempty"`
+}
+
+// AnalyzeRetentionConflicts starts the conflict analysis of the policy in background
+func (r *DefaultAPIController) AnalyzeRetentionConflicts(policyID int64) error {
+	p, err := r.manager.GetPolicy(policyID)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("retention policy %d not found", policyID)
+	}
+
+	now := time.Now()
+	a := &models.RetentionConflictAnalysis{
+		PolicyID:  policyID,
+		RunID:     utils.GenerateRandomString(),
+		Status:    AnalysisStatusRunning,
+		StartTime: now,
+	}
+	started, err := r.analyses.Start(a, now.Add(-analysisTimeout))
+	if err != nil {
+		return err
+	}
+	if !started {
+		return ErrAnalysisInProgress
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), analysisTimeout)
+		defer cancel()
+
+		result, err := r.detectConflicts(ctx, p)
+		a.EndTime = time.Now()
+		if err == nil {
+			var data []byte
+			if data, err = json.Marshal(result); err == nil {
+				a.Result = string(data)
+			}
+		}
+		if err != nil {
+			log.Errorf("failed to analyze the conflicts of retention policy %d: %v", policyID, err)
+			a.Status = AnalysisStatusFailed
+			a.Error = err.Error()
+		} else {
+			a.Status = AnalysisStatusSucceed
+		}
+		if err := r.analyses.Finish(a); err != nil {
+			log.Errorf("failed to record the conflict analysis of retention policy %d: %v", policyID, err)
+		}
+	}()
+
+	return nil
+}
+
+// GetRetentionConflicts returns the result of the last conflict analysis of the policy,
+// nil is returned if the policy has never been analyzed or the analysis has expired
+func (r *DefaultAPIController) GetRetentionConflicts(policyID int64) (*ConflictAnalysis, error) {
+	now := time.Now()
+	if err := r.analyses.DeleteBefore(now.Add(-analysisTTL)); err != nil {
+		log.Warningf("failed to delete the expired conflict analyses: %v", err)
+	}
+	a, err := r.analyses.Get(policyID)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, nil
+	}
+
+	analysis := &ConflictAnalysis{
+		PolicyID:  a.PolicyID,
+		Status:    a.Status,
+		StartTime: a.StartTime,
+		EndTime:   a.EndTime,
+		Error:     a.Error,
+		Conflicts: []*TagConflict{},
+	}
+	// the core instance running the analysis may have exited
+	if a.Status == AnalysisStatusRunning && a.StartTime.Add(analysisTimeout).Before(now) {
+		analysis.Status = AnalysisStatusFailed
+		analysis.Error = fmt.Sprintf("the analysis didn't finish in %v", analysisTimeout)
+		return analysis, nil
+	}
+	if len(a.Result) > 0 {
+		result := &conflictAnalysisResult{}
+		if err := json.Unmarshal([]byte(a.Result), result); err != nil {
+			return nil, err
+		}
+		analysis.Conflicts = result.Conflicts
+		analysis.TruncatedRepositories = result.TruncatedRepositories
+	}
+	return analysis, nil
+}
+
+func (r *DefaultAPIController) detectConflicts(ctx context.Context, p *policy.Metadata) (*conflictAnalysisResult, error) {
+	result := &conflictAnalysisResult{
+		Conflicts: []*TagConflict{},
+	}
+	if len(p.Rules) == 0 {
+		return result, nil
+	}
+
+	repositoryRules, err := getRepositoryRules(p, r.projectManager, r.repositoryMgr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for repo, meta := range repositoryRules {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "analyze the conflicts")
+		}
+		repo := repo
+		// load one more candidate to know whether the repository is truncated
+		candidates, err := r.loadCandidates(&repo, maxAnalysisCandidates+1, withLabelSelectors(meta))
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) > maxAnalysisCandidates {
+			candidates = candidates[:maxAnalysisCandidates]
+			result.TruncatedRepositories = append(result.TruncatedRepositories,
+				fmt.Sprintf("%s/%s", repo.Namespace, repo.Name))
+		}
+		cs, err := NewConflictDetector(meta.Rules).Detect(candidates)
+		if err != nil {
+			return nil, err
+		}
+		result.Conflicts = append(result.Conflicts, cs...)
+	}
+	sort.SliceStable(result.Conflicts, func(i, j int) bool {
+		return result.Conflicts[i].Repository < result.Conflicts[j].Repository
+	})
+	sort.Strings(result.TruncatedRepositories)
+
+	return result, nil
+}
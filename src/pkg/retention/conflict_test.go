@@ -0,0 +1,248 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/pkg/art"
+	retmodels "github.com/goharbor/harbor/src/pkg/retention/dao/models"
+	"github.com/goharbor/harbor/src/pkg/retention/policy"
+	"github.com/goharbor/harbor/src/pkg/retention/policy/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func conflictTestRule(id int, k int, tagPattern string) rule.Metadata {
+	return rule.Metadata{
+		ID:       id,
+		Priority: 1,
+		Template: "latestPushedK",
+		Action:   "retain",
+		Parameters: rule.Parameters{
+			"latestPushedK": k,
+		},
+		TagSelectors: []*rule.Selector{
+			{
+				Kind:       "doublestar",
+				Decoration: "matches",
+				Pattern:    tagPattern,
+			},
+		},
+		ScopeSelectors: map[string][]*rule.Selector{
+			"repository": {
+				{
+					Kind:       "doublestar",
+					Decoration: "repoMatches",
+					Pattern:    "**",
+				},
+			},
+		},
+	}
+}
+
+func conflictTestCandidates(repo *art.Repository) []*art.Candidate {
+	var candidates []*art.Candidate
+	for i, tag := range []string{"release-1", "release-2", "release-3", "release-4", "dev-1", "dev-2"} {
+		candidates = append(candidates, &art.Candidate{
+			Namespace:  repo.Namespace,
+			Repository: repo.Name,
+			Kind:       art.Image,
+			Tag:        tag,
+			Digest:     fmt.Sprintf("sha256:%d", i),
+			PushedTime: int64(i + 1),
+		})
+	}
+	return candidates
+}
+
+func TestConflictDetector(t *testing.T) {
+	candidates := conflictTestCandidates(&art.Repository{Namespace: "library", Name: "hello-world"})
+
+	// "latest 2 of all" deletes the release tags retained by "latest 5 of release-*"
+	r1 := conflictTestRule(1, 2, "**")
+	r2 := conflictTestRule(2, 5, "release-*")
+	conflicts, err := NewConflictDetector([]*rule.Metadata{&r1, &r2}).Detect(candidates)
+	require.Nil(t, err)
+	require.Equal(t, 4, len(conflicts))
+	for i, c := range conflicts {
+		assert.Equal(t, "library/hello-world", c.Repository)
+		assert.Equal(t, fmt.Sprintf("release-%d", i+1), c.Tag)
+		assert.Equal(t, []int{2}, c.RetainRules)
+		assert.Equal(t, []int{1}, c.DeleteRules)
+	}
+
+	// "latest 1 of release-*" deletes release-1,2 which are retained by "latest 3 of release-*"
+	r3 := conflictTestRule(3, 1, "release-*")
+	r4 := conflictTestRule(4, 3, "release-*")
+	conflicts, err = NewConflictDetector([]*rule.Metadata{&r3, &r4}).Detect(candidates)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(conflicts))
+	assert.Equal(t, "release-2", conflicts[0].Tag)
+	assert.Equal(t, "release-3", conflicts[1].Tag)
+	assert.Equal(t, []int{4}, conflicts[0].RetainRules)
+	assert.Equal(t, []int{3}, conflicts[0].DeleteRules)
+
+	// the rules select different tags, no conflicts
+	r5 := conflictTestRule(5, 1, "dev-*")
+	conflicts, err = NewConflictDetector([]*rule.Metadata{&r2, &r5}).Detect(candidates)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(conflicts))
+
+	// the disabled rules are ignored
+	r1.Disabled = true
+	conflicts, err = NewConflictDetector([]*rule.Metadata{&r1, &r2}).Detect(candidates)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(conflicts))
+}
+
+func TestAnalyzeRetentionConflicts(t *testing.T) {
+	r1 := conflictTestRule(1, 2, "**")
+	r2 := conflictTestRule(2, 5, "release-*")
+	retentionMgr := &previewRetentionManager{
+		policy: &policy.Metadata{
+			ID:        1,
+			Algorithm: "or",
+			Rules:     []rule.Metadata{r1, r2},
+			Scope: &policy.Scope{
+				Level:     "project",
+				Reference: 1,
+			},
+		},
+	}
+	repositoryMgr := &fakeRepositoryManager{
+		imageRepositories: []*models.RepoRecord{
+			{
+				Name: "library/hello-world",
+			},
+		},
+	}
+	c := NewAPIController(retentionMgr, &fakeProjectManager{}, repositoryMgr,
+		&fakeRetentionScheduler{}, &fakeLauncher{}).(*DefaultAPIController)
+	c.loadCandidates = func(repo *art.Repository, limit int, withLabels bool) ([]*art.Candidate, error) {
+		candidates := conflictTestCandidates(repo)
+		if len(candidates) > limit {
+			candidates = candidates[:limit]
+		}
+		return candidates, nil
+	}
+	store := &fakeAnalysisStore{
+		analyses: map[int64]*retmodels.RetentionConflictAnalysis{},
+	}
+	c.analyses = store
+
+	a, err := c.GetRetentionConflicts(1)
+	require.Nil(t, err)
+	assert.Nil(t, a)
+
+	require.Nil(t, c.AnalyzeRetentionConflicts(1))
+	for i := 0; i < 50; i++ {
+		a, err = c.GetRetentionConflicts(1)
+		require.Nil(t, err)
+		require.NotNil(t, a)
+		if a.Status != AnalysisStatusRunning {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.Equal(t, AnalysisStatusSucceed, a.Status)
+	assert.Equal(t, 4, len(a.Conflicts))
+	assert.Equal(t, 0, len(a.TruncatedRepositories))
+
+	// the repositories with more candidates than the max are reported as truncated
+	defer func(max int) {
+		maxAnalysisCandidates = max
+	}(maxAnalysisCandidates)
+	maxAnalysisCandidates = 3
+	require.Nil(t, c.AnalyzeRetentionConflicts(1))
+	for i := 0; i < 50; i++ {
+		a, err = c.GetRetentionConflicts(1)
+		require.Nil(t, err)
+		require.NotNil(t, a)
+		if a.Status != AnalysisStatusRunning {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.Equal(t, AnalysisStatusSucceed, a.Status)
+	assert.Equal(t, []string{"library/hello-world"}, a.TruncatedRepositories)
+
+	// the running analysis exceeding the deadline is reported as failed
+	store.analyses[1].Status = AnalysisStatusRunning
+	store.analyses[1].StartTime = time.Now().Add(-2 * analysisTimeout)
+	a, err = c.GetRetentionConflicts(1)
+	require.Nil(t, err)
+	require.NotNil(t, a)
+	assert.Equal(t, AnalysisStatusFailed, a.Status)
+
+	// the expired analysis is dropped
+	store.analyses[1].Status = AnalysisStatusSucceed
+	store.analyses[1].EndTime = time.Now().Add(-2 * analysisTTL)
+	a, err = c.GetRetentionConflicts(1)
+	require.Nil(t, err)
+	assert.Nil(t, a)
+}
+
+type fakeAnalysisStore struct {
+	lock     sync.Mutex
+	analyses map[int64]*retmodels.RetentionConflictAnalysis
+}
+
+func (f *fakeAnalysisStore) Start(a *retmodels.RetentionConflictAnalysis, staleBefore time.Time) (bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if last, ok := f.analyses[a.PolicyID]; ok && last.Status == AnalysisStatusRunning && !last.StartTime.Before(staleBefore) {
+		return false, nil
+	}
+	cp := *a
+	f.analyses[a.PolicyID] = &cp
+	return true, nil
+}
+
+func (f *fakeAnalysisStore) Finish(a *retmodels.RetentionConflictAnalysis) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if last, ok := f.analyses[a.PolicyID]; ok && last.RunID == a.RunID {
+		cp := *a
+		f.analyses[a.PolicyID] = &cp
+	}
+	return nil
+}
+
+func (f *fakeAnalysisStore) Get(policyID int64) (*retmodels.RetentionConflictAnalysis, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	a, ok := f.analyses[policyID]
+	if !ok {
+		return nil, nil
+	}
+	cp := *a
+	return &cp, nil
+}
+
+func (f *fakeAnalysisStore) DeleteBefore(t time.Time) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for id, a := range f.analyses {
+		if !a.EndTime.IsZero() && a.EndTime.Before(t) {
+			delete(f.analyses, id)
+		}
+	}
+	return nil
+}
@@ -35,7 +35,7 @@ type APIController interface {
 
 	DeleteRetention(id int64) error
 
-	TriggerRetentionExec(policyID int64, trigger string, dryRun bool) (int64, error)
+	TriggerRetentionExec(policyID int64, trigger string, dryRun bool, dryRunMode string) (int64, error)
 
 	OperateRetentionExec(eid int64, action string) error
 
@@ -50,6 +50,12 @@ type APIController interface {
 	GetTotalOfRetentionExecTasks(executionID int64) (int64, error)
 
 	GetRetentionExecTaskLog(taskID int64) ([]byte, error)
+
+	PreviewRetention(policyID int64, sampleSize int) (*Preview, error)
+
+	AnalyzeRetentionConflicts(policyID int64) error
+
+	GetRetentionConflicts(policyID int64) (*ConflictAnalysis, error)
 }
 
 // DefaultAPIController ...
@@ -59,6 +65,8 @@ type DefaultAPIController struct {
 	projectManager project.Manager
 	repositoryMgr  repository.Manager
 	scheduler      scheduler.Scheduler
+	loadCandidates candidatesLoader
+	analyses       analysisStore
 }
 
 const (
@@ -175,8 +183,8 @@ func (r *DefaultAPIController) DeleteRetention(id int64) error {
 	return r.manager.DeletePolicyAndExec(id)
 }
 
-// TriggerRetentionExec Trigger Retention Execution
-func (r *DefaultAPIController) TriggerRetentionExec(policyID int64, trigger string, dryRun bool) (int64, error) {
+// TriggerRetentionExec Trigger Retention Execution, the dry run mode is ignored if it isn't a dry run
+func (r *DefaultAPIController) TriggerRetentionExec(policyID int64, trigger string, dryRun bool, dryRunMode string) (int64, error) {
 	p, err := r.manager.GetPolicy(policyID)
 	if err != nil {
 		return 0, err
@@ -189,7 +197,7 @@ func (r *DefaultAPIController) TriggerRetentionExec(policyID int64, trigger stri
 		DryRun:    dryRun,
 	}
 	id, err := r.manager.CreateExecution(exec)
-	if _, err = r.launcher.Launch(p, id, dryRun); err != nil {
+	if _, err = r.launcher.Launch(p, id, dryRun, dryRunMode); err != nil {
 		// clean execution if launch failed
 		_ = r.manager.DeleteExecution(id)
 		return 0, err
@@ -260,5 +268,7 @@ func NewAPIController(retentionMgr Manager, projectManager project.Manager, repo
 		projectManager: projectManager,
 		repositoryMgr:  repositoryMgr,
 		scheduler:      scheduler,
+		loadCandidates: newDBCandidatesLoader(projectManager),
+		analyses:       &dbAnalysisStore{},
 	}
 }
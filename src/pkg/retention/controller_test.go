@@ -176,7 +176,7 @@ func (s *ControllerTestSuite) TestExecution() {
 	s.Require().Nil(err)
 	s.Require().True(policyID > 0)
 
-	id, err := m.TriggerRetentionExec(policyID, ExecutionTriggerManual, false)
+	id, err := m.TriggerRetentionExec(policyID, ExecutionTriggerManual, false, "")
 	s.Require().Nil(err)
 	s.Require().True(id > 0)
 
@@ -216,6 +216,6 @@ func (f *fakeLauncher) Stop(executionID int64) error {
 	return nil
 }
 
-func (f *fakeLauncher) Launch(policy *policy.Metadata, executionID int64, isDryRun bool) (int64, error) {
+func (f *fakeLauncher) Launch(policy *policy.Metadata, executionID int64, isDryRun bool, dryRunMode string) (int64, error) {
 	return 0, nil
 }
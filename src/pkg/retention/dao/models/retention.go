@@ -19,6 +19,7 @@ func init() {
 		new(RetentionPolicy),
 		new(RetentionExecution),
 		new(RetentionTask),
+		new(RetentionConflictAnalysis),
 	)
 }
 
@@ -60,4 +61,19 @@ type RetentionTask struct {
 	EndTime        time.Time `orm:"column(end_time)"`
 	Total          int       `orm:"column(total)"`
 	Retained       int       `orm:"column(retained)"`
+	Summary        string    `orm:"column(summary)"` // The summary of the results in JSON, empty if it isn't reported
+}
+
+// RetentionConflictAnalysis is the last conflict analysis of the retention policy
+type RetentionConflictAnalysis struct {
+	ID       int64 `orm:"pk;auto;column(id)"`
+	PolicyID int64 `orm:"column(policy_id)"`
+	// identifies the run of the analysis, the result of the replaced run is dropped
+	RunID     string    `orm:"column(run_id)"`
+	Status    string    `orm:"column(status)"`
+	StartTime time.Time `orm:"column(start_time)"`
+	EndTime   time.Time `orm:"column(end_time);null"`
+	Error     string    `orm:"column(error)"`
+	// json format, include the conflicts and the truncated repositories
+	Result string `orm:"column(result)"`
 }
@@ -37,6 +37,9 @@ func DeletePolicyAndExec(id int64) error {
 	}); err != nil {
 		return err
 	}
+	if _, err := o.Raw("delete from retention_conflict_analysis where policy_id = ?", id).Exec(); err != nil {
+		return err
+	}
 	_, err := o.Delete(&models.RetentionPolicy{
 		ID: id,
 	})
@@ -301,6 +304,23 @@ func GetTotalOfTasks(executionID int64) (int64, error) {
 	return qs.Count()
 }
 
+// ListTaskSummaries lists the summaries of the tasks under the execution, the tasks without summary are excluded
+func ListTaskSummaries(executionID int64) ([]string, error) {
+	qs := dao.GetOrmer().QueryTable(&models.RetentionTask{})
+	qs = qs.Filter("ExecutionID", executionID).Exclude("Summary", "")
+	var summaries orm.ParamsList
+	if _, err := qs.ValuesFlat(&summaries, "Summary"); err != nil {
+		return nil, err
+	}
+	results := make([]string, 0, len(summaries))
+	for _, s := range summaries {
+		if str, ok := s.(string); ok {
+			results = append(results, str)
+		}
+	}
+	return results, nil
+}
+
 // IsFinalStatus checks whether the status is a final status
 func IsFinalStatus(status string) bool {
 	if status == job.StoppedStatus.String() || status == job.SuccessStatus.String() ||
@@ -309,3 +329,53 @@ func IsFinalStatus(status string) bool {
 	}
 	return false
 }
+
+// StartConflictAnalysis records the running conflict analysis of the policy, it replaces the last
+// analysis unless that one is still running and started after the staleBefore. Whether the analysis
+// is started is returned
+func StartConflictAnalysis(a *models.RetentionConflictAnalysis, staleBefore time.Time) (bool, error) {
+	result, err := dao.GetOrmer().Raw(`insert into retention_conflict_analysis
+		(policy_id, run_id, status, start_time, error, result) values (?, ?, ?, ?, '', '')
+		on conflict (policy_id) do update
+		set run_id = excluded.run_id, status = excluded.status, start_time = excluded.start_time,
+		end_time = null, error = '', result = ''
+		where retention_conflict_analysis.status <> ? or retention_conflict_analysis.start_time < ?`,
+		a.PolicyID, a.RunID, a.Status, a.StartTime, a.Status, staleBefore).Exec()
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// FinishConflictAnalysis records the result of the run of the conflict analysis, the result is
+// dropped if the run has been replaced by a new one
+func FinishConflictAnalysis(a *models.RetentionConflictAnalysis) error {
+	_, err := dao.GetOrmer().Raw(`update retention_conflict_analysis
+		set status = ?, end_time = ?, error = ?, result = ?
+		where policy_id = ? and run_id = ?`,
+		a.Status, a.EndTime, a.Error, a.Result, a.PolicyID, a.RunID).Exec()
+	return err
+}
+
+// GetConflictAnalysis returns the last conflict analysis of the policy, nil is returned if there is none
+func GetConflictAnalysis(policyID int64) (*models.RetentionConflictAnalysis, error) {
+	analyses := []*models.RetentionConflictAnalysis{}
+	if _, err := dao.GetOrmer().Raw(`select * from retention_conflict_analysis where policy_id = ?`,
+		policyID).QueryRows(&analyses); err != nil {
+		return nil, err
+	}
+	if len(analyses) == 0 {
+		return nil, nil
+	}
+	return analyses[0], nil
+}
+
+// DeleteConflictAnalysesBefore deletes the conflict analyses which end before the time
+func DeleteConflictAnalysesBefore(t time.Time) error {
+	_, err := dao.GetOrmer().Raw(`delete from retention_conflict_analysis where end_time < ?`, t).Exec()
+	return err
+}
@@ -208,6 +208,18 @@ func TestTask(t *testing.T) {
 	assert.Equal(t, 1, tasks[0].StatusCode)
 	assert.Equal(t, int64(1), tasks[0].StatusRevision)
 
+	// list summaries, the task without summary is excluded
+	summaries, err := ListTaskSummaries(1)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(summaries))
+	task.Summary = `{"total":1,"retained":1}`
+	err = UpdateTask(task, "Summary")
+	require.Nil(t, err)
+	summaries, err = ListTaskSummaries(1)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(summaries))
+	assert.Equal(t, task.Summary, summaries[0])
+
 	// update status
 	err = UpdateTaskStatus(id, "Stopped", 1, 2)
 	require.Nil(t, err)
@@ -216,3 +228,93 @@ func TestTask(t *testing.T) {
 	err = DeleteTask(id)
 	require.Nil(t, err)
 }
+
+func TestConflictAnalysis(t *testing.T) {
+	defer dao.GetOrmer().Raw(`delete from retention_conflict_analysis`).Exec()
+
+	a, err := GetConflictAnalysis(1)
+	require.Nil(t, err)
+	assert.Nil(t, a)
+
+	now := time.Now()
+	started, err := StartConflictAnalysis(&models.RetentionConflictAnalysis{
+		PolicyID:  1,
+		RunID:     "run1",
+		Status:    "Running",
+		StartTime: now,
+	}, now.Add(-time.Hour))
+	require.Nil(t, err)
+	assert.True(t, started)
+
+	// the analysis is running
+	started, err = StartConflictAnalysis(&models.RetentionConflictAnalysis{
+		PolicyID:  1,
+		RunID:     "run2",
+		Status:    "Running",
+		StartTime: now,
+	}, now.Add(-time.Hour))
+	require.Nil(t, err)
+	assert.False(t, started)
+
+	// the result of the other run is dropped
+	require.Nil(t, FinishConflictAnalysis(&models.RetentionConflictAnalysis{
+		PolicyID: 1,
+		RunID:    "run2",
+		Status:   "Succeed",
+		EndTime:  now,
+	}))
+	a, err = GetConflictAnalysis(1)
+	require.Nil(t, err)
+	require.NotNil(t, a)
+	assert.Equal(t, "run1", a.RunID)
+	assert.Equal(t, "Running", a.Status)
+
+	require.Nil(t, FinishConflictAnalysis(&models.RetentionConflictAnalysis{
+		PolicyID: 1,
+		RunID:    "run1",
+		Status:   "Succeed",
+		EndTime:  now,
+		Result:   `{"conflicts":[]}`,
+	}))
+	a, err = GetConflictAnalysis(1)
+	require.Nil(t, err)
+	require.NotNil(t, a)
+	assert.Equal(t, "Succeed", a.Status)
+	assert.Equal(t, `{"conflicts":[]}`, a.Result)
+
+	// the finished analysis is replaced by the new run
+	started, err = StartConflictAnalysis(&models.RetentionConflictAnalysis{
+		PolicyID:  1,
+		RunID:     "run3",
+		Status:    "Running",
+		StartTime: now,
+	}, now.Add(-time.Hour))
+	require.Nil(t, err)
+	assert.True(t, started)
+	// the running analysis started before the stale time is replaced as well
+	started, err = StartConflictAnalysis(&models.RetentionConflictAnalysis{
+		PolicyID:  1,
+		RunID:     "run4",
+		Status:    "Running",
+		StartTime: now,
+	}, now.Add(time.Hour))
+	require.Nil(t, err)
+	assert.True(t, started)
+	a, err = GetConflictAnalysis(1)
+	require.Nil(t, err)
+	require.NotNil(t, a)
+	assert.Equal(t, "run4", a.RunID)
+	assert.Equal(t, "", a.Result)
+
+	// the analyses end before the time are deleted
+	require.Nil(t, FinishConflictAnalysis(&models.RetentionConflictAnalysis{
+		PolicyID: 1,
+		RunID:    "run4",
+		Status:   "Failed",
+		EndTime:  now,
+	}))
+	require.Nil(t, DeleteConflictAnalysesBefore(now.Add(time.Minute)))
+	a, err = GetConflictAnalysis(1)
+	require.Nil(t, err)
+	assert.Nil(t, a)
+}
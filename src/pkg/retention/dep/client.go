@@ -15,11 +15,15 @@
 package dep
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
+	common_http "github.com/goharbor/harbor/src/common/http"
 	"github.com/goharbor/harbor/src/common/http/modifier/auth"
+	"github.com/goharbor/harbor/src/common/utils"
 	"github.com/goharbor/harbor/src/jobservice/config"
 	"github.com/goharbor/harbor/src/pkg/art"
 	"github.com/goharbor/harbor/src/pkg/clients/core"
@@ -28,35 +32,95 @@ import (
 // DefaultClient for the retention
 var DefaultClient = NewClient()
 
+var (
+	// ErrCandidateNotFound is returned by DeleteBatch and CheckDeletable for the candidates which don't exist anymore
+	ErrCandidateNotFound = errors.New("candidate not found")
+	// ErrCandidateSigned is returned by CheckDeletable for the candidates signed by notary
+	ErrCandidateSigned = errors.New("the artifact is signed")
+)
+
 // Client is designed to access core service to get required infos
 type Client interface {
 	// Get the tag candidates under the repository
 	//
 	//  Arguments:
+	//    ctx context.Context  : the context for the cancellation and deadline
 	//    repo *art.Repository : repository info
 	//
 	//  Returns:
 	//    []*art.Candidate : candidates returned
 	//    error            : common error if any errors occurred
-	GetCandidates(repo *art.Repository) ([]*art.Candidate, error)
+	GetCandidates(ctx context.Context, repo *art.Repository) ([]*art.Candidate, error)
 
 	// Delete the given repository
 	//
 	//  Arguments:
+	//    ctx context.Context  : the context for the cancellation and deadline
 	//    repo *art.Repository : repository info
 	//
 	//  Returns:
 	//    error            : common error if any errors occurred
-	DeleteRepository(repo *art.Repository) error
+	DeleteRepository(ctx context.Context, repo *art.Repository) error
 
 	// Delete the specified candidate
 	//
 	//  Arguments:
+	//    ctx context.Context      : the context for the cancellation and deadline
 	//    candidate *art.Candidate : the deleting candidate
 	//
 	//  Returns:
+	//    error : ErrCandidateNotFound if it doesn't exist anymore or common error if any errors occurred
+	Delete(ctx context.Context, candidate *art.Candidate) error
+
+	// Delete the specified candidates, the candidates under the same repository are deleted
+	// in one request and it falls back to deleting them one by one if the batch deletion
+	// isn't supported by the core service
+	//
+	//  Arguments:
+	//    ctx context.Context         : the context for the cancellation and deadline
+	//    candidates []*art.Candidate : the deleting candidates
+	//
+	//  Returns:
+	//    []error : the errors aligned with the candidates, nil for the deleted ones and
+	//              ErrCandidateNotFound for the ones which don't exist anymore
+	DeleteBatch(ctx context.Context, candidates []*art.Candidate) []error
+
+	// Check whether the specified candidate can be deleted without deleting anything, it's
+	// used by the validated dry run to surface the failures the real run would hit
+	//
+	//  Arguments:
+	//    ctx context.Context      : the context for the cancellation and deadline
+	//    candidate *art.Candidate : the checking candidate
+	//
+	//  Returns:
+	//    error : nil if the candidate can be deleted, ErrCandidateNotFound if it doesn't exist
+	//            anymore, ErrCandidateSigned if it's signed or the error explaining why it can't
+	CheckDeletable(ctx context.Context, candidate *art.Candidate) error
+
+	// Check whether the specified candidate still exists
+	//
+	//  Arguments:
+	//    ctx context.Context      : the context for the cancellation and deadline
+	//    candidate *art.Candidate : the checking candidate
+	//
+	//  Returns:
+	//    bool  : true if the candidate still exists
 	//    error : common error if any errors occurred
-	Delete(candidate *art.Candidate) error
+	Exists(ctx context.Context, candidate *art.Candidate) (bool, error)
+
+	// Get the sizes which would be freed by deleting the specified candidates all together. The layers
+	// still referenced by the other artifacts under any repository are excluded, and the layers shared
+	// among the deleting candidates under the same repository are counted only once
+	//
+	//  Arguments:
+	//    ctx context.Context         : the context for the cancellation and deadline
+	//    candidates []*art.Candidate : the deleting candidates
+	//
+	//  Returns:
+	//    map[string]int64 : the sizes in bytes keyed by the digests of the candidates, the sum of them
+	//                       is the size freed, the candidates whose sizes are unknown are absent
+	//    error            : common error if any errors occurred
+	GetReclaimableSizes(ctx context.Context, candidates []*art.Candidate) (map[string]int64, error)
 }
 
 // NewClient new a basic client
@@ -88,7 +152,102 @@ type basicClient struct {
 }
 
 // GetCandidates gets the tag candidates under the repository
-func (bc *basicClient) GetCandidates(repository *art.Repository) ([]*art.Candidate, error) {
+func (bc *basicClient) GetCandidates(ctx context.Context, repository *art.Repository) ([]*art.Candidate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	candidates, err := bc.withContext(ctx).getCandidates(repository)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	return candidates, nil
+}
+
+// DeleteRepository deletes the specified repository
+func (bc *basicClient) DeleteRepository(ctx context.Context, repo *art.Repository) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return contextError(ctx, bc.withContext(ctx).deleteRepository(repo))
+}
+
+// Delete deletes the specified candidate
+func (bc *basicClient) Delete(ctx context.Context, candidate *art.Candidate) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return contextError(ctx, bc.withContext(ctx).delete(candidate))
+}
+
+// DeleteBatch deletes the specified candidates grouped by the repository, the candidates failed
+// to be deleted get the error of the context if it's done before the deletion completes
+func (bc *basicClient) DeleteBatch(ctx context.Context, candidates []*art.Candidate) []error {
+	errs := make([]error, len(candidates))
+	if err := ctx.Err(); err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	errs = bc.withContext(ctx).deleteBatch(candidates)
+	for i := range errs {
+		errs[i] = contextError(ctx, errs[i])
+	}
+	return errs
+}
+
+// CheckDeletable checks whether the specified candidate can be deleted
+func (bc *basicClient) CheckDeletable(ctx context.Context, candidate *art.Candidate) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return contextError(ctx, bc.withContext(ctx).checkDeletable(candidate))
+}
+
+// Exists checks whether the specified candidate still exists
+func (bc *basicClient) Exists(ctx context.Context, candidate *art.Candidate) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	exist, err := bc.withContext(ctx).exists(candidate)
+	if err != nil {
+		return false, contextError(ctx, err)
+	}
+	return exist, nil
+}
+
+// GetReclaimableSizes gets the sizes which would be freed by deleting the candidates all together
+func (bc *basicClient) GetReclaimableSizes(ctx context.Context, candidates []*art.Candidate) (map[string]int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sizes, err := bc.withContext(ctx).getReclaimableSizes(candidates)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	return sizes, nil
+}
+
+// withContext returns a copy of the client whose requests to the core service are bound to the context,
+// so the requests in flight are aborted once the context is done
+func (bc *basicClient) withContext(ctx context.Context) *basicClient {
+	return &basicClient{
+		internalCoreURL: bc.internalCoreURL,
+		coreClient:      bc.coreClient.WithContext(ctx),
+	}
+}
+
+// contextError returns the error of the context if the call fails with the context done,
+// the error of the aborted request only wraps it
+func contextError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// getCandidates gets the tag candidates under the repository
+func (bc *basicClient) getCandidates(repository *art.Repository) ([]*art.Candidate, error) {
 	if repository == nil {
 		return nil, errors.New("repository is nil")
 	}
@@ -106,10 +265,12 @@ func (bc *basicClient) GetCandidates(repository *art.Repository) ([]*art.Candida
 			}
 			candidate := &art.Candidate{
 				Kind:         art.Image,
+				NamespaceID:  repository.NamespaceID,
 				Namespace:    repository.Namespace,
 				Repository:   repository.Name,
 				Tag:          image.Name,
 				Digest:       image.Digest,
+				Size:         image.Size,
 				Labels:       labels,
 				CreationTime: image.Created.Unix(),
 				PulledTime:   image.PullTime.Unix(),
@@ -147,8 +308,8 @@ func (bc *basicClient) GetCandidates(repository *art.Repository) ([]*art.Candida
 	return candidates, nil
 }
 
-// DeleteRepository deletes the specified repository
-func (bc *basicClient) DeleteRepository(repo *art.Repository) error {
+// deleteRepository deletes the specified repository
+func (bc *basicClient) deleteRepository(repo *art.Repository) error {
 	if repo == nil {
 		return errors.New("repository is nil")
 	}
@@ -164,14 +325,19 @@ func (bc *basicClient) DeleteRepository(repo *art.Repository) error {
 	}
 }
 
-// Deletes the specified candidate
-func (bc *basicClient) Delete(candidate *art.Candidate) error {
+// delete deletes the specified candidate, ErrCandidateNotFound is returned if it has been
+// deleted by others since it was listed
+func (bc *basicClient) delete(candidate *art.Candidate) error {
 	if candidate == nil {
 		return errors.New("candidate is nil")
 	}
 	switch candidate.Kind {
 	case art.Image:
-		return bc.coreClient.DeleteImage(candidate.Namespace, candidate.Repository, candidate.Tag)
+		err := bc.coreClient.DeleteImage(candidate.Namespace, candidate.Repository, candidate.Tag)
+		if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusNotFound {
+			return ErrCandidateNotFound
+		}
+		return err
 	/*
 		case art.Chart:
 			return bc.coreClient.DeleteChart(candidate.Namespace, candidate.Repository, candidate.Tag)
@@ -180,3 +346,191 @@ func (bc *basicClient) Delete(candidate *art.Candidate) error {
 		return fmt.Errorf("unsupported candidate kind: %s", candidate.Kind)
 	}
 }
+
+// deleteBatch deletes the specified candidates grouped by the repository
+func (bc *basicClient) deleteBatch(candidates []*art.Candidate) []error {
+	errs := make([]error, len(candidates))
+	// the indexes of the candidates keyed by the repository
+	groups := make(map[string][]int)
+	// keep the order of the repositories stable
+	var keys []string
+	for i, candidate := range candidates {
+		if candidate == nil {
+			errs[i] = errors.New("candidate is nil")
+			continue
+		}
+		if candidate.Kind != art.Image {
+			errs[i] = bc.deleteOne(candidate)
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", candidate.Namespace, candidate.Repository)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	for _, key := range keys {
+		bc.deleteImages(candidates, groups[key], errs)
+	}
+	return errs
+}
+
+// deleteImages deletes the images specified by the indexes which are under the same repository
+func (bc *basicClient) deleteImages(candidates []*art.Candidate, indexes []int, errs []error) {
+	first := candidates[indexes[0]]
+	tags := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		tags = append(tags, candidates[i].Tag)
+	}
+
+	result, err := bc.coreClient.DeleteImages(first.Namespace, first.Repository, tags)
+	if err != nil {
+		if !batchUnsupported(err) {
+			for _, i := range indexes {
+				errs[i] = err
+			}
+			return
+		}
+		for _, i := range indexes {
+			errs[i] = bc.deleteOne(candidates[i])
+		}
+		return
+	}
+
+	deleted := make(map[string]bool, len(result.Deleted))
+	for _, tag := range result.Deleted {
+		deleted[tag] = true
+	}
+	notFound := make(map[string]bool, len(result.NotFound))
+	for _, tag := range result.NotFound {
+		notFound[tag] = true
+	}
+	for _, i := range indexes {
+		tag := candidates[i].Tag
+		switch {
+		case deleted[tag]:
+			errs[i] = nil
+		case notFound[tag]:
+			errs[i] = ErrCandidateNotFound
+		case len(result.Failed[tag]) > 0:
+			errs[i] = errors.New(result.Failed[tag])
+		default:
+			errs[i] = fmt.Errorf("no deletion result returned for tag %s", tag)
+		}
+	}
+}
+
+// deleteOne deletes the candidate if it still exists
+func (bc *basicClient) deleteOne(candidate *art.Candidate) error {
+	exist, err := bc.exists(candidate)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return ErrCandidateNotFound
+	}
+	return bc.delete(candidate)
+}
+
+// batchUnsupported checks whether the error is returned because the core service
+// doesn't support deleting the tags in batch
+func batchUnsupported(err error) bool {
+	e, ok := err.(*common_http.Error)
+	if !ok {
+		return false
+	}
+	return e.Code == http.StatusBadRequest ||
+		e.Code == http.StatusNotFound ||
+		e.Code == http.StatusMethodNotAllowed
+}
+
+// exists checks whether the specified candidate still exists
+func (bc *basicClient) exists(candidate *art.Candidate) (bool, error) {
+	if candidate == nil {
+		return false, errors.New("candidate is nil")
+	}
+	switch candidate.Kind {
+	case art.Image:
+		if _, err := bc.coreClient.GetImage(candidate.Namespace, candidate.Repository, candidate.Tag); err != nil {
+			if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusNotFound {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported candidate kind: %s", candidate.Kind)
+	}
+}
+
+// checkDeletable checks whether the specified candidate can be deleted. The manifest is deleted
+// by digest, so the candidate whose manifest is referenced by other tags can't be deleted alone.
+func (bc *basicClient) checkDeletable(candidate *art.Candidate) error {
+	if candidate == nil {
+		return errors.New("candidate is nil")
+	}
+	if candidate.Kind != art.Image {
+		return fmt.Errorf("unsupported candidate kind: %s", candidate.Kind)
+	}
+
+	image, err := bc.coreClient.GetImage(candidate.Namespace, candidate.Repository, candidate.Tag)
+	if err != nil {
+		if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusNotFound {
+			return ErrCandidateNotFound
+		}
+		return err
+	}
+	if image.Signature != nil {
+		return ErrCandidateSigned
+	}
+
+	images, err := bc.coreClient.ListAllImages(candidate.Namespace, candidate.Repository)
+	if err != nil {
+		return err
+	}
+	var tags []string
+	for _, i := range images {
+		if i.Name != candidate.Tag && i.Digest == image.Digest {
+			tags = append(tags, i.Name)
+		}
+	}
+	if len(tags) > 0 {
+		return fmt.Errorf("the manifest is referenced by other tags: %s", strings.Join(tags, ", "))
+	}
+
+	return nil
+}
+
+// getReclaimableSizes gets the sizes which would be freed by deleting the candidates all together, the
+// candidates are grouped by the repository and the sizes of each group are calculated by the core service
+func (bc *basicClient) getReclaimableSizes(candidates []*art.Candidate) (map[string]int64, error) {
+	repositories := []string{}
+	tags := map[string][]string{}
+	for _, c := range candidates {
+		if c == nil {
+			return nil, errors.New("candidate is nil")
+		}
+		if c.Kind != art.Image {
+			return nil, fmt.Errorf("unsupported candidate kind: %s", c.Kind)
+		}
+		repo := fmt.Sprintf("%s/%s", c.Namespace, c.Repository)
+		if _, ok := tags[repo]; !ok {
+			repositories = append(repositories, repo)
+		}
+		tags[repo] = append(tags[repo], c.Tag)
+	}
+
+	sizes := make(map[string]int64)
+	for _, repo := range repositories {
+		namespace, repository := utils.ParseRepository(repo)
+		ss, err := bc.coreClient.GetReclaimableSizes(namespace, repository, tags[repo])
+		if err != nil {
+			return nil, err
+		}
+		for digest, size := range ss {
+			sizes[digest] = size
+		}
+	}
+	return sizes, nil
+}
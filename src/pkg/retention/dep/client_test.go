@@ -15,13 +15,22 @@
 package dep
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/goharbor/harbor/src/chartserver"
+	common_http "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/http/modifier/auth"
 	jmodels "github.com/goharbor/harbor/src/common/job/models"
 	"github.com/goharbor/harbor/src/common/models"
+	notarymodel "github.com/goharbor/harbor/src/common/utils/notary/model"
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/pkg/art"
+	"github.com/goharbor/harbor/src/pkg/clients/core"
 	"github.com/goharbor/harbor/src/testing/clients"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -34,9 +43,14 @@ type fakeCoreClient struct {
 	clients.DumbCoreClient
 }
 
+func (f *fakeCoreClient) WithContext(ctx context.Context) core.Client {
+	return f
+}
+
 func (f *fakeCoreClient) ListAllImages(project, repository string) ([]*models.TagResp, error) {
 	image := &models.TagResp{}
 	image.Name = "latest"
+	image.Size = 1024
 	return []*models.TagResp{image}, nil
 }
 
@@ -51,6 +65,118 @@ func (f *fakeCoreClient) ListAllCharts(project, repository string) ([]*chartserv
 	return []*chartserver.ChartVersion{chart}, nil
 }
 
+// fakeReclaimCoreClient records the tags of each repository whose reclaimable sizes are got,
+// the tags are assumed to point to the manifests of the same digests
+type fakeReclaimCoreClient struct {
+	clients.DumbCoreClient
+	requested map[string][]string
+}
+
+func (f *fakeReclaimCoreClient) WithContext(ctx context.Context) core.Client {
+	return f
+}
+
+func (f *fakeReclaimCoreClient) GetReclaimableSizes(project, repository string, tags []string) (map[string]int64, error) {
+	f.requested[project+"/"+repository] = tags
+	sizes := map[string]int64{}
+	for i, tag := range tags {
+		sizes[tag] = int64(i + 1)
+	}
+	return sizes, nil
+}
+
+// fakeBatchCoreClient counts the deletion requests, the tags in "missing" don't exist, the tags
+// in "racing" are deleted by others once they are got and the batch deletion is rejected if
+// "unsupported" is true
+type fakeBatchCoreClient struct {
+	clients.DumbCoreClient
+	missing      map[string]bool
+	racing       map[string]bool
+	unsupported  bool
+	batchCalls   int
+	deleteCalls  int
+	getCalls     int
+	batchedRepos []string
+}
+
+func (f *fakeBatchCoreClient) WithContext(ctx context.Context) core.Client {
+	return f
+}
+
+func (f *fakeBatchCoreClient) DeleteImages(project, repository string, tags []string) (*models.TagsDeletionResult, error) {
+	f.batchCalls++
+	if f.unsupported {
+		return nil, &common_http.Error{Code: http.StatusMethodNotAllowed}
+	}
+	f.batchedRepos = append(f.batchedRepos, project+"/"+repository)
+	result := &models.TagsDeletionResult{Failed: map[string]string{}}
+	for _, tag := range tags {
+		switch {
+		case f.missing[tag]:
+			result.NotFound = append(result.NotFound, tag)
+		case tag == "signed":
+			result.Failed[tag] = "tag signed is signed"
+		default:
+			result.Deleted = append(result.Deleted, tag)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeBatchCoreClient) GetImage(project, repository, tag string) (*models.TagResp, error) {
+	f.getCalls++
+	if f.missing[tag] {
+		return nil, &common_http.Error{Code: http.StatusNotFound}
+	}
+	return &models.TagResp{}, nil
+}
+
+func (f *fakeBatchCoreClient) DeleteImage(project, repository, tag string) error {
+	f.deleteCalls++
+	if f.missing[tag] || f.racing[tag] {
+		return &common_http.Error{Code: http.StatusNotFound}
+	}
+	return nil
+}
+
+// fakeSignedCoreClient serves the tags "signed", "1.0" and "stable", the latter two point to the same manifest
+type fakeSignedCoreClient struct {
+	clients.DumbCoreClient
+}
+
+func (f *fakeSignedCoreClient) WithContext(ctx context.Context) core.Client {
+	return f
+}
+
+func (f *fakeSignedCoreClient) GetImage(project, repository, tag string) (*models.TagResp, error) {
+	for _, image := range f.images() {
+		if image.Name == tag {
+			return image, nil
+		}
+	}
+	return nil, &common_http.Error{Code: http.StatusNotFound}
+}
+
+func (f *fakeSignedCoreClient) ListAllImages(project, repository string) ([]*models.TagResp, error) {
+	return f.images(), nil
+}
+
+func (f *fakeSignedCoreClient) images() []*models.TagResp {
+	signed := &models.TagResp{Signature: &notarymodel.Target{Tag: "signed"}}
+	signed.Name = "signed"
+	signed.Digest = "sha256:signed"
+	v1 := &models.TagResp{}
+	v1.Name = "1.0"
+	v1.Digest = "sha256:a"
+	stable := &models.TagResp{}
+	stable.Name = "stable"
+	stable.Digest = "sha256:a"
+	v2 := &models.TagResp{}
+	v2.Name = "2.0"
+	v2.Digest = "sha256:b"
+	return []*models.TagResp{signed, v1, stable, v2}
+}
+
 type fakeJobserviceClient struct{}
 
 func (f *fakeJobserviceClient) SubmitJob(*jmodels.JobData) (string, error) {
@@ -65,6 +191,15 @@ func (f *fakeJobserviceClient) PostAction(uuid, action string) error {
 func (f *fakeJobserviceClient) GetExecutions(uuid string) ([]job.Stats, error) {
 	return nil, nil
 }
+func (f *fakeJobserviceClient) UpdateJobSchedule(uuid, cron string) error {
+	return nil
+}
+func (f *fakeJobserviceClient) DeleteJobLog(uuid string) error {
+	return nil
+}
+func (f *fakeJobserviceClient) GetJobLogStream(uuid, rangeHeader string) (*http.Response, error) {
+	return nil, nil
+}
 
 type clientTestSuite struct {
 	suite.Suite
@@ -75,7 +210,7 @@ func (c *clientTestSuite) TestGetCandidates() {
 	client.coreClient = &fakeCoreClient{}
 	var repository *art.Repository
 	// nil repository
-	candidates, err := client.GetCandidates(repository)
+	candidates, err := client.GetCandidates(context.Background(), repository)
 	require.NotNil(c.T(), err)
 
 	// image repository
@@ -83,20 +218,21 @@ func (c *clientTestSuite) TestGetCandidates() {
 	repository.Kind = art.Image
 	repository.Namespace = "library"
 	repository.Name = "hello-world"
-	candidates, err = client.GetCandidates(repository)
+	candidates, err = client.GetCandidates(context.Background(), repository)
 	require.Nil(c.T(), err)
 	assert.Equal(c.T(), 1, len(candidates))
 	assert.Equal(c.T(), art.Image, candidates[0].Kind)
 	assert.Equal(c.T(), "library", candidates[0].Namespace)
 	assert.Equal(c.T(), "hello-world", candidates[0].Repository)
 	assert.Equal(c.T(), "latest", candidates[0].Tag)
+	assert.Equal(c.T(), int64(1024), candidates[0].Size)
 
 	/*
 		// chart repository
 		repository.Kind = art.Chart
 		repository.Namespace = "goharbor"
 		repository.Name = "harbor"
-		candidates, err = client.GetCandidates(repository)
+		candidates, err = client.GetCandidates(context.Background(), repository)
 		require.Nil(c.T(), err)
 		assert.Equal(c.T(), 1, len(candidates))
 		assert.Equal(c.T(), art.Chart, candidates[0].Kind)
@@ -111,26 +247,184 @@ func (c *clientTestSuite) TestDelete() {
 
 	var candidate *art.Candidate
 	// nil candidate
-	err := client.Delete(candidate)
+	err := client.Delete(context.Background(), candidate)
 	require.NotNil(c.T(), err)
 
 	// image
 	candidate = &art.Candidate{}
 	candidate.Kind = art.Image
-	err = client.Delete(candidate)
+	err = client.Delete(context.Background(), candidate)
 	require.Nil(c.T(), err)
 
 	/*
 		// chart
 		candidate.Kind = art.Chart
-		err = client.Delete(candidate)
+		err = client.Delete(context.Background(), candidate)
 		require.Nil(c.T(), err)
 	*/
 
 	// unsupported type
 	candidate.Kind = "unsupported"
-	err = client.Delete(candidate)
+	err = client.Delete(context.Background(), candidate)
 	require.NotNil(c.T(), err)
+
+	// deleted by others before the deletion
+	client.coreClient = &fakeBatchCoreClient{racing: map[string]bool{"racing": true}}
+	err = client.Delete(context.Background(), &art.Candidate{Kind: art.Image, Tag: "racing"})
+	assert.Equal(c.T(), ErrCandidateNotFound, err)
+}
+
+func (c *clientTestSuite) TestDeleteBatch() {
+	coreClient := &fakeBatchCoreClient{missing: map[string]bool{"gone": true}}
+	client := &basicClient{}
+	client.coreClient = coreClient
+
+	candidates := []*art.Candidate{
+		{Kind: art.Image, Namespace: "library", Repository: "hello-world", Tag: "1.0"},
+		{Kind: art.Image, Namespace: "library", Repository: "busybox", Tag: "1.0"},
+		{Kind: art.Image, Namespace: "library", Repository: "hello-world", Tag: "gone"},
+		{Kind: art.Image, Namespace: "library", Repository: "hello-world", Tag: "signed"},
+		{Kind: art.Image, Namespace: "library", Repository: "busybox", Tag: "2.0"},
+		nil,
+	}
+	errs := client.DeleteBatch(context.Background(), candidates)
+	require.Equal(c.T(), len(candidates), len(errs))
+	assert.Nil(c.T(), errs[0])
+	assert.Nil(c.T(), errs[1])
+	assert.Equal(c.T(), ErrCandidateNotFound, errs[2])
+	assert.NotNil(c.T(), errs[3])
+	assert.Nil(c.T(), errs[4])
+	assert.NotNil(c.T(), errs[5])
+
+	// one request per repository and no deletion of the single tag
+	assert.Equal(c.T(), 2, coreClient.batchCalls)
+	assert.Equal(c.T(), []string{"library/hello-world", "library/busybox"}, coreClient.batchedRepos)
+	assert.Equal(c.T(), 0, coreClient.deleteCalls)
+	assert.Equal(c.T(), 0, coreClient.getCalls)
+}
+
+func (c *clientTestSuite) TestDeleteBatchFallback() {
+	coreClient := &fakeBatchCoreClient{
+		missing:     map[string]bool{"gone": true},
+		unsupported: true,
+	}
+	client := &basicClient{}
+	client.coreClient = coreClient
+
+	candidates := []*art.Candidate{
+		{Kind: art.Image, Namespace: "library", Repository: "hello-world", Tag: "1.0"},
+		{Kind: art.Image, Namespace: "library", Repository: "hello-world", Tag: "gone"},
+		{Kind: art.Image, Namespace: "library", Repository: "hello-world", Tag: "2.0"},
+		{Kind: art.Image, Namespace: "library", Repository: "hello-world", Tag: "racing"},
+	}
+	coreClient.racing = map[string]bool{"racing": true}
+	errs := client.DeleteBatch(context.Background(), candidates)
+	require.Equal(c.T(), len(candidates), len(errs))
+	assert.Nil(c.T(), errs[0])
+	assert.Equal(c.T(), ErrCandidateNotFound, errs[1])
+	assert.Nil(c.T(), errs[2])
+	assert.Equal(c.T(), ErrCandidateNotFound, errs[3])
+
+	// the candidates are deleted one by one once the batch deletion is rejected
+	assert.Equal(c.T(), 1, coreClient.batchCalls)
+	assert.Equal(c.T(), 4, coreClient.getCalls)
+	assert.Equal(c.T(), 3, coreClient.deleteCalls)
+}
+
+func (c *clientTestSuite) TestCheckDeletable() {
+	client := &basicClient{}
+	client.coreClient = &fakeSignedCoreClient{}
+
+	// nil candidate
+	require.NotNil(c.T(), client.CheckDeletable(context.Background(), nil))
+	// unsupported type
+	require.NotNil(c.T(), client.CheckDeletable(context.Background(), &art.Candidate{Kind: "unsupported"}))
+
+	candidate := func(tag string) *art.Candidate {
+		return &art.Candidate{Kind: art.Image, Namespace: "library", Repository: "hello-world", Tag: tag}
+	}
+	assert.Nil(c.T(), client.CheckDeletable(context.Background(), candidate("2.0")))
+	assert.Equal(c.T(), ErrCandidateSigned, client.CheckDeletable(context.Background(), candidate("signed")))
+	assert.Equal(c.T(), ErrCandidateNotFound, client.CheckDeletable(context.Background(), candidate("gone")))
+	// deleting the manifest removes the other tag as well
+	err := client.CheckDeletable(context.Background(), candidate("1.0"))
+	require.NotNil(c.T(), err)
+	assert.Contains(c.T(), err.Error(), "stable")
+}
+
+func (c *clientTestSuite) TestContext() {
+	// the core service never responds until the request is aborted
+	var aborted int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		atomic.AddInt32(&aborted, 1)
+	}))
+	defer server.Close()
+	client := &basicClient{}
+	client.coreClient = core.New(server.URL, nil, auth.NewSecretAuthorizer("secret"))
+	candidate := &art.Candidate{Kind: art.Image, Namespace: "library", Repository: "hello-world", Tag: "1.0"}
+
+	// the stuck request is aborted once the deadline is exceeded
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.Exists(ctx, candidate)
+	assert.Equal(c.T(), context.DeadlineExceeded, err)
+	assert.True(c.T(), time.Since(start) < time.Second)
+
+	errs := client.DeleteBatch(ctx, []*art.Candidate{candidate, candidate})
+	assert.Equal(c.T(), []error{context.DeadlineExceeded, context.DeadlineExceeded}, errs)
+	// nothing is left running in background
+	assert.Eventually(c.T(), func() bool {
+		return atomic.LoadInt32(&aborted) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// nothing is called with the context cancelled already
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	client = &basicClient{}
+	client.coreClient = &fakeCoreClient{}
+	_, err = client.GetCandidates(ctx, &art.Repository{Kind: art.Image})
+	assert.Equal(c.T(), context.Canceled, err)
+	assert.Equal(c.T(), context.Canceled, client.Delete(ctx, candidate))
+}
+
+func (c *clientTestSuite) TestGetReclaimableSizes() {
+	coreClient := &fakeReclaimCoreClient{
+		requested: map[string][]string{},
+	}
+	client := &basicClient{}
+	client.coreClient = coreClient
+
+	// nil candidate
+	_, err := client.GetReclaimableSizes(context.Background(), []*art.Candidate{nil})
+	require.NotNil(c.T(), err)
+
+	// unsupported type
+	_, err = client.GetReclaimableSizes(context.Background(), []*art.Candidate{{Kind: "unsupported"}})
+	require.NotNil(c.T(), err)
+
+	// the candidates of the same repository are sized all together
+	candidate := func(repository, tag string) *art.Candidate {
+		return &art.Candidate{
+			Kind:       art.Image,
+			Namespace:  "library",
+			Repository: repository,
+			Tag:        tag,
+			Digest:     tag,
+		}
+	}
+	sizes, err := client.GetReclaimableSizes(context.Background(), []*art.Candidate{
+		candidate("hello-world", "1.0"),
+		candidate("busybox", "a"),
+		candidate("hello-world", "2.0"),
+	})
+	require.Nil(c.T(), err)
+	assert.Equal(c.T(), map[string][]string{
+		"library/hello-world": {"1.0", "2.0"},
+		"library/busybox":     {"a"},
+	}, coreClient.requested)
+	assert.Equal(c.T(), map[string]int64{"1.0": 1, "2.0": 2, "a": 1}, sizes)
 }
 
 func TestClientTestSuite(t *testing.T) {
@@ -16,16 +16,20 @@ package retention
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/jobservice/logger"
 	"github.com/goharbor/harbor/src/pkg/art"
+	"github.com/goharbor/harbor/src/pkg/immutabletag/match/rule"
 	"github.com/goharbor/harbor/src/pkg/retention/dep"
 	"github.com/goharbor/harbor/src/pkg/retention/policy"
+	"github.com/goharbor/harbor/src/pkg/retention/policy/action"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/lwp"
 	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
@@ -35,6 +39,8 @@ const (
 	actionMarkRetain   = "RETAIN"
 	actionMarkDeletion = "DEL"
 	actionMarkError    = "ERR"
+	actionMarkGone     = "GONE"
+	actionMarkSkip     = "SKIP"
 )
 
 // Job of running retention process
@@ -54,7 +60,11 @@ func (pj *Job) ShouldRetry() bool {
 func (pj *Job) Validate(params job.Parameters) (err error) {
 	if _, err = getParamRepo(params); err == nil {
 		if _, err = getParamMeta(params); err == nil {
-			_, err = getParamDryRun(params)
+			if _, err = getParamDryRun(params); err == nil {
+				if _, err = getParamWorkers(params); err == nil {
+					_, err = getParamDryRunMode(params)
+				}
+			}
 		}
 	}
 
@@ -70,10 +80,15 @@ func (pj *Job) Run(ctx job.Context, params job.Parameters) error {
 	repo, _ := getParamRepo(params)
 	liteMeta, _ := getParamMeta(params)
 	isDryRun, _ := getParamDryRun(params)
+	workers, _ := getParamWorkers(params)
+	dryRunMode, _ := getParamDryRunMode(params)
 
 	// Log stage: start
 	repoPath := fmt.Sprintf("%s/%s", repo.Namespace, repo.Name)
 	myLogger.Infof("Run retention process.\n Repository: %s \n Rule Algorithm: %s \n Dry Run: %v", repoPath, liteMeta.Algorithm, isDryRun)
+	if isDryRun {
+		myLogger.Infof("Dry Run Mode: %s", dryRunMode)
+	}
 
 	// Stop check point 1:
 	if isStopped(ctx) {
@@ -81,8 +96,11 @@ func (pj *Job) Run(ctx job.Context, params job.Parameters) error {
 		return nil
 	}
 
+	// the calls to the core service are interrupted once the system context is done
+	runCtx := systemContext(ctx)
+
 	// Retrieve all the candidates under the specified repository
-	allCandidates, err := dep.DefaultClient.GetCandidates(repo)
+	allCandidates, err := dep.DefaultClient.GetCandidates(runCtx, repo)
 	if err != nil {
 		return logError(myLogger, err)
 	}
@@ -91,7 +109,31 @@ func (pj *Job) Run(ctx job.Context, params job.Parameters) error {
 	myLogger.Infof("Load %d candidates from repository %s", len(allCandidates), repoPath)
 
 	// Build the processor
-	builder := policy.NewBuilder(allCandidates)
+	actionParams := &action.Params{
+		All:     allCandidates,
+		Workers: workers,
+		// the candidates under the repository are deleted in batches to reduce the requests
+		BatchSize: action.DefaultBatchSize,
+		// the outstanding deletions are skipped once the job is stopped
+		IsStopped: func() bool {
+			return isStopped(ctx)
+		},
+		// the would-be failures are reported by the validated dry run
+		Validate: isDryRun && dryRunMode == DryRunModeValidated,
+	}
+	// the candidates protected by the immutable tag rules of the project are skipped,
+	// the repositories submitted by the old launchers don't carry the project ID
+	if repo.NamespaceID > 0 {
+		actionParams.ImmutableMatcher = rule.NewRuleMatcher(repo.NamespaceID)
+	}
+	// the deleted artifacts are published as the events, nothing is deleted in dry run
+	var publisher *deletionPublisher
+	if !isDryRun {
+		publisher = newDeletionPublisher(ctx, len(allCandidates))
+		defer publisher.close()
+		actionParams.OnDeleted = publisher.publish
+	}
+	builder := policy.NewBuilderWithParams(actionParams)
 	processor, err := builder.Build(liteMeta, isDryRun)
 	if err != nil {
 		return logError(myLogger, err)
@@ -104,33 +146,44 @@ func (pj *Job) Run(ctx job.Context, params job.Parameters) error {
 	}
 
 	// Run the flow
-	results, err := processor.Process(allCandidates)
+	results, err := processor.Process(runCtx, allCandidates)
+	// the deletion events are published before the final check-in
+	if publisher != nil {
+		publisher.close()
+	}
 	if err != nil {
+		// the results collected before the interruption are still logged
+		if runCtx.Err() != nil {
+			logResults(myLogger, allCandidates, results)
+		}
 		return logError(myLogger, err)
 	}
 
 	// Log stage: results with table view
 	logResults(myLogger, allCandidates, results)
 
-	// Save retain and total num in DB
-	return saveRetainNum(ctx, results, allCandidates)
-}
-
-func saveRetainNum(ctx job.Context, retained []*art.Result, allCandidates []*art.Candidate) error {
-	var delNum int
-	for _, r := range retained {
-		if r.Error == nil {
-			delNum++
-		}
+	// Stop check point 3: the deletions not started yet are skipped
+	if isStopped(ctx) {
+		logStop(myLogger)
 	}
-	retainObj := struct {
-		Total    int `json:"total"`
-		Retained int `json:"retained"`
-	}{
-		Total:    len(allCandidates),
-		Retained: len(allCandidates) - delNum,
+
+	// Log stage: the storage impact of the dry run
+	if isDryRun {
+		myLogger.Infof("Total size would be freed: %d bytes", freedSize(results))
 	}
-	c, err := json.Marshal(retainObj)
+
+	// Save the summary with the retain and total num in DB
+	summary := art.Summarize(allCandidates, results, isDryRun)
+	logSummary(myLogger, summary)
+	return saveSummary(ctx, summary)
+}
+
+func saveSummary(ctx job.Context, summary *art.Summary) error {
+	c, err := json.Marshal(&SummaryCheckIn{
+		Total:    summary.Total,
+		Retained: summary.Retained,
+		Summary:  summary,
+	})
 	if err != nil {
 		return err
 	}
@@ -138,19 +191,39 @@ func saveRetainNum(ctx job.Context, retained []*art.Result, allCandidates []*art
 	return nil
 }
 
+func logSummary(logger logger.Interface, summary *art.Summary) {
+	prefix := "Summary"
+	if summary.Estimated {
+		prefix = "Summary (estimated by dry run)"
+	}
+	logger.Infof("%s: total %d, retained %d, deleted %d, skipped %d, failed %d, reclaimed %d bytes (%d deleted with unknown size)",
+		prefix, summary.Total, summary.Retained, summary.Deleted, summary.Skipped, summary.Failed,
+		summary.ReclaimedBytes, summary.UnknownSize)
+}
+
 func logResults(logger logger.Interface, all []*art.Candidate, results []*art.Result) {
-	hash := make(map[string]error, len(results))
+	hash := make(map[string]*art.Result, len(results))
 	for _, r := range results {
 		if r.Target != nil {
-			hash[r.Target.Hash()] = r.Error
+			hash[r.Target.Hash()] = r
 		}
 	}
 
-	op := func(art *art.Candidate) string {
-		if e, exists := hash[art.Hash()]; exists {
-			if e != nil {
+	op := func(c *art.Candidate) string {
+		if r, exists := hash[c.Hash()]; exists {
+			if r.Error != nil {
 				return actionMarkError
 			}
+			// deleted by others before this execution
+			if r.AlreadyDeleted {
+				return actionMarkGone
+			}
+			switch r.Action {
+			case art.ActionSkip:
+				return actionMarkSkip
+			case art.ActionRetain:
+				return actionMarkRetain
+			}
 
 			return actionMarkDeletion
 		}
@@ -158,6 +231,22 @@ func logResults(logger logger.Interface, all []*art.Candidate, results []*art.Re
 		return actionMarkRetain
 	}
 
+	size := func(art *art.Candidate) string {
+		if r, exists := hash[art.Hash()]; exists && r.SizeBytes > 0 {
+			return strconv.FormatInt(r.SizeBytes, 10)
+		}
+
+		return ""
+	}
+
+	reason := func(c *art.Candidate) string {
+		if r, exists := hash[c.Hash()]; exists && r.Reason != nil {
+			return r.Reason.Code
+		}
+
+		return ""
+	}
+
 	var buf bytes.Buffer
 
 	data := make([][]string, len(all))
@@ -172,13 +261,15 @@ func logResults(logger logger.Interface, all []*art.Candidate, results []*art.Re
 			t(c.PulledTime),
 			t(c.CreationTime),
 			op(c),
+			reason(c),
+			size(c),
 		}
 		data = append(data, row)
 	}
 
 	table := tablewriter.NewWriter(&buf)
 	table.SetAutoFormatHeaders(false)
-	table.SetHeader([]string{"Digest", "Tag", "Kind", "Labels", "PushedTime", "PulledTime", "CreatedTime", "Retention"})
+	table.SetHeader([]string{"Digest", "Tag", "Kind", "Labels", "PushedTime", "PulledTime", "CreatedTime", "Retention", "Reason", "Size"})
 	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
 	table.SetCenterSeparator("|")
 	table.AppendBulk(data)
@@ -194,6 +285,26 @@ func logResults(logger logger.Interface, all []*art.Candidate, results []*art.Re
 	}
 }
 
+// freedSize aggregates the sizes of the results except the retained ones, the tags
+// pointing to the same manifest are counted only once
+func freedSize(results []*art.Result) int64 {
+	var total int64
+	counted := make(map[string]bool)
+	for _, r := range results {
+		if r.Error != nil || r.Target == nil || r.Action == art.ActionRetain {
+			continue
+		}
+		if len(r.Target.Digest) > 0 {
+			if counted[r.Target.Digest] {
+				continue
+			}
+			counted[r.Target.Digest] = true
+		}
+		total += r.SizeBytes
+	}
+	return total
+}
+
 func arn(art *art.Candidate) string {
 	return fmt.Sprintf("%s/%s:%s", art.Namespace, art.Repository, art.Tag)
 }
@@ -205,6 +316,14 @@ func t(tm int64) string {
 	return time.Unix(tm, 0).Format("2006/01/02 15:04:05")
 }
 
+// systemContext returns the system context of the job, the background context is returned if it isn't set
+func systemContext(ctx job.Context) context.Context {
+	if sysCtx := ctx.SystemContext(); sysCtx != nil {
+		return sysCtx
+	}
+	return context.Background()
+}
+
 func isStopped(ctx job.Context) (stopped bool) {
 	cmd, ok := ctx.OPCommand()
 	stopped = ok && cmd == job.StopCommand
@@ -237,6 +356,48 @@ func getParamDryRun(params job.Parameters) (bool, error) {
 	return dryRun, nil
 }
 
+// getParamDryRunMode returns the mode of the dry run, the fast mode is returned if it isn't specified
+func getParamDryRunMode(params job.Parameters) (string, error) {
+	v, ok := params[ParamDryRunMode]
+	if !ok {
+		return DryRunModeFast, nil
+	}
+
+	mode, ok := v.(string)
+	if !ok || (mode != DryRunModeFast && mode != DryRunModeValidated) {
+		return "", errors.Errorf("invalid parameter: %s", ParamDryRunMode)
+	}
+
+	return mode, nil
+}
+
+// getParamWorkers returns the count of the workers, the default one is returned if it isn't specified
+func getParamWorkers(params job.Parameters) (int, error) {
+	v, ok := params[ParamWorkers]
+	if !ok {
+		return action.DefaultWorkers, nil
+	}
+
+	var workers int
+	switch n := v.(type) {
+	case float64:
+		if n != float64(int(n)) {
+			return 0, errors.Errorf("invalid parameter: %s", ParamWorkers)
+		}
+		workers = int(n)
+	case int:
+		workers = n
+	default:
+		return 0, errors.Errorf("invalid parameter: %s", ParamWorkers)
+	}
+
+	if workers < 1 {
+		return 0, errors.Errorf("invalid parameter: %s, it must be positive", ParamWorkers)
+	}
+
+	return workers, nil
+}
+
 func getParamRepo(params job.Parameters) (*art.Repository, error) {
 	v, ok := params[ParamRepo]
 	if !ok {
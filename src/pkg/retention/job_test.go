@@ -16,7 +16,9 @@ package retention
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -30,6 +32,7 @@ import (
 	"github.com/goharbor/harbor/src/pkg/retention/policy/lwp"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/rule"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/rule/latestps"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -109,10 +112,177 @@ func (suite *JobTestSuite) TestRunSuccess() {
 	require.NoError(suite.T(), err)
 }
 
+// TestRunPublishDeletions tests one event is published per deleted candidate and none in dry run
+func (suite *JobTestSuite) TestRunPublishDeletions() {
+	ruleParams := make(rule.Parameters)
+	ruleParams[latestps.ParameterK] = 1
+	meta := &lwp.Metadata{
+		Algorithm: policy.AlgorithmOR,
+		Rules: []*rule.Metadata{
+			{
+				ID:         1,
+				Priority:   999,
+				Action:     action.Retain,
+				Template:   latestps.TemplateID,
+				Parameters: ruleParams,
+				TagSelectors: []*rule.Selector{{
+					Kind:       doublestar.Kind,
+					Decoration: doublestar.Matches,
+					Pattern:    "**",
+				}},
+			},
+		},
+	}
+	metaJSON, err := meta.ToJSON()
+	require.NoError(suite.T(), err)
+	repository := &art.Repository{
+		Namespace: "library",
+		Name:      "harbor",
+		Kind:      art.Image,
+	}
+	repoJSON, err := repository.ToJSON()
+	require.NoError(suite.T(), err)
+
+	deletions := func(isDryRun bool) []*DeletedArtifact {
+		ctx := &fakeJobContext{}
+		err := (&Job{}).Run(ctx, job.Parameters{
+			ParamDryRun: isDryRun,
+			ParamRepo:   repoJSON,
+			ParamMeta:   metaJSON,
+		})
+		require.NoError(suite.T(), err)
+
+		var deleted []*DeletedArtifact
+		for _, checkIn := range ctx.checkIns {
+			deletion := &DeletionCheckIn{}
+			require.NoError(suite.T(), json.Unmarshal([]byte(checkIn), deletion))
+			if deletion.Deleted != nil {
+				deleted = append(deleted, deletion.Deleted)
+			}
+		}
+		// the final check-in is the retained number with the summary
+		require.NotEmpty(suite.T(), ctx.checkIns)
+		suite.Contains(ctx.checkIns[len(ctx.checkIns)-1], `"retained"`)
+		summary := &SummaryCheckIn{}
+		require.NoError(suite.T(), json.Unmarshal([]byte(ctx.checkIns[len(ctx.checkIns)-1]), summary))
+		require.NotNil(suite.T(), summary.Summary)
+		suite.Equal(summary.Total, summary.Summary.Total)
+		suite.Equal(summary.Retained, summary.Summary.Retained)
+		suite.Equal(1, summary.Summary.Deleted)
+		suite.Equal(isDryRun, summary.Summary.Estimated)
+
+		return deleted
+	}
+
+	deleted := deletions(false)
+	require.Equal(suite.T(), 1, len(deleted))
+	suite.Equal("library", deleted[0].Namespace)
+	suite.Equal("harbor", deleted[0].Repository)
+	suite.Equal("latest", deleted[0].Tag)
+	suite.Equal("latest", deleted[0].Digest)
+
+	suite.Empty(deletions(true))
+}
+
+// TestRunCancelled tests the job fails without checking in the retained number once the system context is done
+func (suite *JobTestSuite) TestRunCancelled() {
+	meta := &lwp.Metadata{
+		Algorithm: policy.AlgorithmOR,
+		Rules: []*rule.Metadata{
+			{
+				ID:       1,
+				Priority: 999,
+				Action:   action.Retain,
+				Template: latestps.TemplateID,
+				Parameters: rule.Parameters{
+					latestps.ParameterK: 1,
+				},
+				TagSelectors: []*rule.Selector{{
+					Kind:       doublestar.Kind,
+					Decoration: doublestar.Matches,
+					Pattern:    "**",
+				}},
+			},
+		},
+	}
+	metaJSON, err := meta.ToJSON()
+	require.NoError(suite.T(), err)
+	repoJSON, err := (&art.Repository{Namespace: "library", Name: "harbor", Kind: art.Image}).ToJSON()
+	require.NoError(suite.T(), err)
+
+	sysCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx := &fakeJobContext{sysCtx: sysCtx}
+	err = (&Job{}).Run(ctx, job.Parameters{
+		ParamDryRun: false,
+		ParamRepo:   repoJSON,
+		ParamMeta:   metaJSON,
+	})
+	require.Error(suite.T(), err)
+	suite.Equal(context.Canceled, errors.Cause(err))
+	suite.Empty(ctx.checkIns)
+}
+
+// TestFreedSize tests the tags pointing to the same manifest are counted once
+func (suite *JobTestSuite) TestFreedSize() {
+	results := []*art.Result{
+		{
+			Target:    &art.Candidate{Tag: "1.0", Digest: "sha256:a"},
+			SizeBytes: 10,
+		},
+		{
+			Target:    &art.Candidate{Tag: "stable", Digest: "sha256:a"},
+			SizeBytes: 10,
+		},
+		{
+			Target:    &art.Candidate{Tag: "2.0", Digest: "sha256:b"},
+			SizeBytes: 20,
+		},
+		{
+			Target:    &art.Candidate{Tag: "3.0", Digest: "sha256:c"},
+			SizeBytes: 30,
+			Error:     errors.New("failed"),
+		},
+	}
+	suite.Equal(int64(30), freedSize(results))
+}
+
+// TestParamWorkers tests the count of the workers is optional and must be positive
+func (suite *JobTestSuite) TestParamWorkers() {
+	workers, err := getParamWorkers(job.Parameters{})
+	suite.NoError(err)
+	suite.Equal(action.DefaultWorkers, workers)
+
+	workers, err = getParamWorkers(job.Parameters{ParamWorkers: float64(10)})
+	suite.NoError(err)
+	suite.Equal(10, workers)
+
+	for _, v := range []interface{}{float64(0), float64(-1), 1.5, "5"} {
+		_, err = getParamWorkers(job.Parameters{ParamWorkers: v})
+		suite.Error(err)
+	}
+}
+
+// TestParamDryRunMode tests the dry run mode is optional and must be fast or validated
+func (suite *JobTestSuite) TestParamDryRunMode() {
+	mode, err := getParamDryRunMode(job.Parameters{})
+	suite.NoError(err)
+	suite.Equal(DryRunModeFast, mode)
+
+	mode, err = getParamDryRunMode(job.Parameters{ParamDryRunMode: DryRunModeValidated})
+	suite.NoError(err)
+	suite.Equal(DryRunModeValidated, mode)
+
+	for _, v := range []interface{}{"", "thorough", true} {
+		_, err = getParamDryRunMode(job.Parameters{ParamDryRunMode: v})
+		suite.Error(err)
+	}
+}
+
 type fakeRetentionClient struct{}
 
 // GetCandidates ...
-func (frc *fakeRetentionClient) GetCandidates(repo *art.Repository) ([]*art.Candidate, error) {
+func (frc *fakeRetentionClient) GetCandidates(ctx context.Context, repo *art.Repository) ([]*art.Candidate, error) {
 	return []*art.Candidate{
 		{
 			Namespace:    "library",
@@ -140,15 +310,35 @@ func (frc *fakeRetentionClient) GetCandidates(repo *art.Repository) ([]*art.Cand
 }
 
 // Delete ...
-func (frc *fakeRetentionClient) Delete(candidate *art.Candidate) error {
+func (frc *fakeRetentionClient) Delete(ctx context.Context, candidate *art.Candidate) error {
+	return nil
+}
+
+// DeleteBatch ...
+func (frc *fakeRetentionClient) DeleteBatch(ctx context.Context, candidates []*art.Candidate) []error {
+	return make([]error, len(candidates))
+}
+
+// CheckDeletable ...
+func (frc *fakeRetentionClient) CheckDeletable(ctx context.Context, candidate *art.Candidate) error {
 	return nil
 }
 
 // SubmitTask ...
-func (frc *fakeRetentionClient) DeleteRepository(repo *art.Repository) error {
+func (frc *fakeRetentionClient) DeleteRepository(ctx context.Context, repo *art.Repository) error {
 	return nil
 }
 
+// Exists ...
+func (frc *fakeRetentionClient) Exists(ctx context.Context, candidate *art.Candidate) (bool, error) {
+	return true, nil
+}
+
+// GetReclaimableSizes ...
+func (frc *fakeRetentionClient) GetReclaimableSizes(ctx context.Context, candidates []*art.Candidate) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
+
 type fakeLogger struct{}
 
 // For debuging
@@ -188,7 +378,12 @@ func (l *fakeLogger) Fatal(v ...interface{}) {}
 // For fatal error with error
 func (l *fakeLogger) Fatalf(format string, v ...interface{}) {}
 
-type fakeJobContext struct{}
+type fakeJobContext struct {
+	lock     sync.Mutex
+	checkIns []string
+	// the system context, context.TODO() is used if it's nil
+	sysCtx context.Context
+}
 
 func (c *fakeJobContext) Build(tracker job.Tracker) (job.Context, error) {
 	return nil, nil
@@ -199,11 +394,17 @@ func (c *fakeJobContext) Get(prop string) (interface{}, bool) {
 }
 
 func (c *fakeJobContext) SystemContext() context.Context {
+	if c.sysCtx != nil {
+		return c.sysCtx
+	}
 	return context.TODO()
 }
 
 func (c *fakeJobContext) Checkin(status string) error {
 	fmt.Printf("Check in: %s\n", status)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.checkIns = append(c.checkIns, status)
 
 	return nil
 }
@@ -43,6 +43,17 @@ const (
 	ParamMeta = "liteMeta"
 	// ParamDryRun ...
 	ParamDryRun = "dryRun"
+	// ParamWorkers is the optional parameter of the count of the workers deleting the candidates concurrently
+	ParamWorkers = "workers"
+	// ParamDryRunMode is the optional parameter of the dry run mode, DryRunModeFast is used if it isn't specified
+	ParamDryRunMode = "dryRunMode"
+)
+
+const (
+	// DryRunModeFast only lists the candidates which would be deleted
+	DryRunModeFast = "fast"
+	// DryRunModeValidated also checks whether the candidates can be deleted and reports the would-be failures
+	DryRunModeValidated = "validated"
 )
 
 // Launcher provides function to launch the async jobs to run retentions based on the provided policy.
@@ -54,11 +65,12 @@ type Launcher interface {
 	//   policy *policy.Metadata: the policy info
 	//   executionID int64      : the execution ID
 	//   isDryRun bool          : indicate if it is a dry run
+	//   dryRunMode string      : the mode of the dry run, ignored if it isn't a dry run
 	//
 	//  Returns:
 	//   int64               : the count of tasks
 	//   error               : common error if any errors occurred
-	Launch(policy *policy.Metadata, executionID int64, isDryRun bool) (int64, error)
+	Launch(policy *policy.Metadata, executionID int64, isDryRun bool, dryRunMode string) (int64, error)
 	// Stop the jobs for one execution
 	//
 	//  Arguments:
@@ -98,7 +110,7 @@ type launcher struct {
 	chartServerEnabled bool
 }
 
-func (l *launcher) Launch(ply *policy.Metadata, executionID int64, isDryRun bool) (int64, error) {
+func (l *launcher) Launch(ply *policy.Metadata, executionID int64, isDryRun bool, dryRunMode string) (int64, error) {
 	if ply == nil {
 		return 0, launcherError(fmt.Errorf("the policy is nil"))
 	}
@@ -107,9 +119,42 @@ func (l *launcher) Launch(ply *policy.Metadata, executionID int64, isDryRun bool
 		log.Debugf("no rules for policy %d, skip", ply.ID)
 		return 0, nil
 	}
+	repositoryRules, err := getRepositoryRules(ply, l.projectMgr, l.repositoryMgr, l.chartServerEnabled)
+	if err != nil {
+		return 0, launcherError(err)
+	}
+
+	// create job data list
+	jobDatas, err := createJobs(repositoryRules, isDryRun, dryRunMode)
+	if err != nil {
+		return 0, launcherError(err)
+	}
+
+	// no jobs, return directly
+	if len(jobDatas) == 0 {
+		log.Debugf("no candidates for policy %d, skip", ply.ID)
+		return 0, nil
+	}
+
+	// create task records in database
+	if err = l.createTasks(executionID, jobDatas); err != nil {
+		return 0, launcherError(err)
+	}
+
+	// submit jobs to jobservice
+	if err = l.submitJobs(jobDatas); err != nil {
+		return 0, launcherError(err)
+	}
+
+	return int64(len(jobDatas)), nil
+}
+
+// getRepositoryRules resolves the repositories in the scope of the policy and the rules applied to each of them
+func getRepositoryRules(ply *policy.Metadata, projectMgr project.Manager, repositoryMgr repository.Manager,
+	chartServerEnabled bool) (map[art.Repository]*lwp.Metadata, error) {
 	scope := ply.Scope
 	if scope == nil {
-		return 0, launcherError(fmt.Errorf("the scope of policy is nil"))
+		return nil, fmt.Errorf("the scope of policy is nil")
 	}
 	repositoryRules := make(map[art.Repository]*lwp.Metadata, 0)
 	level := scope.Level
@@ -117,9 +162,9 @@ func (l *launcher) Launch(ply *policy.Metadata, executionID int64, isDryRun bool
 	var err error
 	if level == "system" {
 		// get projects
-		allProjects, err = getProjects(l.projectMgr)
+		allProjects, err = getProjects(projectMgr)
 		if err != nil {
-			return 0, launcherError(err)
+			return nil, err
 		}
 	}
 
@@ -136,11 +181,11 @@ func (l *launcher) Launch(ply *policy.Metadata, executionID int64, isDryRun bool
 				selector, err := index.Get(projectSelector.Kind, projectSelector.Decoration,
 					projectSelector.Pattern)
 				if err != nil {
-					return 0, launcherError(err)
+					return nil, err
 				}
 				projectCandidates, err = selector.Select(projectCandidates)
 				if err != nil {
-					return 0, launcherError(err)
+					return nil, err
 				}
 			}
 		case "project":
@@ -152,9 +197,9 @@ func (l *launcher) Launch(ply *policy.Metadata, executionID int64, isDryRun bool
 		var repositoryCandidates []*art.Candidate
 		// get repositories of projects
 		for _, projectCandidate := range projectCandidates {
-			repositories, err := getRepositories(l.projectMgr, l.repositoryMgr, projectCandidate.NamespaceID, l.chartServerEnabled)
+			repositories, err := getRepositories(projectMgr, repositoryMgr, projectCandidate.NamespaceID, chartServerEnabled)
 			if err != nil {
-				return 0, launcherError(err)
+				return nil, err
 			}
 			for _, repository := range repositories {
 				repositoryCandidates = append(repositoryCandidates, repository)
@@ -165,19 +210,20 @@ func (l *launcher) Launch(ply *policy.Metadata, executionID int64, isDryRun bool
 			selector, err := index.Get(repositorySelector.Kind, repositorySelector.Decoration,
 				repositorySelector.Pattern)
 			if err != nil {
-				return 0, launcherError(err)
+				return nil, err
 			}
 			repositoryCandidates, err = selector.Select(repositoryCandidates)
 			if err != nil {
-				return 0, launcherError(err)
+				return nil, err
 			}
 		}
 
 		for _, repositoryCandidate := range repositoryCandidates {
 			reposit := art.Repository{
-				Namespace: repositoryCandidate.Namespace,
-				Name:      repositoryCandidate.Repository,
-				Kind:      repositoryCandidate.Kind,
+				Namespace:   repositoryCandidate.Namespace,
+				Name:        repositoryCandidate.Repository,
+				Kind:        repositoryCandidate.Kind,
+				NamespaceID: repositoryCandidate.NamespaceID,
 			}
 			if repositoryRules[reposit] == nil {
 				repositoryRules[reposit] = &lwp.Metadata{
@@ -189,41 +235,22 @@ func (l *launcher) Launch(ply *policy.Metadata, executionID int64, isDryRun bool
 		}
 	}
 
-	// create job data list
-	jobDatas, err := createJobs(repositoryRules, isDryRun)
-	if err != nil {
-		return 0, launcherError(err)
-	}
-
-	// no jobs, return directly
-	if len(jobDatas) == 0 {
-		log.Debugf("no candidates for policy %d, skip", ply.ID)
-		return 0, nil
-	}
-
-	// create task records in database
-	if err = l.createTasks(executionID, jobDatas); err != nil {
-		return 0, launcherError(err)
-	}
-
-	// submit jobs to jobservice
-	if err = l.submitJobs(jobDatas); err != nil {
-		return 0, launcherError(err)
-	}
-
-	return int64(len(jobDatas)), nil
+	return repositoryRules, nil
 }
 
-func createJobs(repositoryRules map[art.Repository]*lwp.Metadata, isDryRun bool) ([]*jobData, error) {
+func createJobs(repositoryRules map[art.Repository]*lwp.Metadata, isDryRun bool, dryRunMode string) ([]*jobData, error) {
 	jobDatas := []*jobData{}
 	for repository, policy := range repositoryRules {
 		jobData := &jobData{
 			Repository: repository,
 			JobName:    job.Retention,
-			JobParams:  make(map[string]interface{}, 3),
+			JobParams:  make(map[string]interface{}, 4),
 		}
 		// set dry run
 		jobData.JobParams[ParamDryRun] = isDryRun
+		if isDryRun && len(dryRunMode) > 0 {
+			jobData.JobParams[ParamDryRunMode] = dryRunMode
+		}
 		// set repository
 		repoJSON, err := repository.ToJSON()
 		if err != nil {
@@ -352,9 +379,10 @@ func getRepositories(projectMgr project.Manager, repositoryMgr repository.Manage
 	for _, r := range imageRepositories {
 		namespace, repo := utils.ParseRepository(r.Name)
 		candidates = append(candidates, &art.Candidate{
-			Namespace:  namespace,
-			Repository: repo,
-			Kind:       "image",
+			NamespaceID: projectID,
+			Namespace:   namespace,
+			Repository:  repo,
+			Kind:        "image",
 		})
 	}
 	// currently, doesn't support retention for chart
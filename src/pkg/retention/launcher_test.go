@@ -199,6 +199,7 @@ func (l *launchTestSuite) TestGetRepositories() {
 	assert.Equal(l.T(), "library", repositories[0].Namespace)
 	assert.Equal(l.T(), "image", repositories[0].Repository)
 	assert.Equal(l.T(), "image", repositories[0].Kind)
+	assert.Equal(l.T(), int64(1), repositories[0].NamespaceID)
 }
 
 func (l *launchTestSuite) TestLaunch() {
@@ -212,12 +213,12 @@ func (l *launchTestSuite) TestLaunch() {
 
 	var ply *policy.Metadata
 	// nil policy
-	n, err := launcher.Launch(ply, 1, false)
+	n, err := launcher.Launch(ply, 1, false, "")
 	require.NotNil(l.T(), err)
 
 	// nil rules
 	ply = &policy.Metadata{}
-	n, err = launcher.Launch(ply, 1, false)
+	n, err = launcher.Launch(ply, 1, false, "")
 	require.Nil(l.T(), err)
 	assert.Equal(l.T(), int64(0), n)
 
@@ -227,7 +228,7 @@ func (l *launchTestSuite) TestLaunch() {
 			{},
 		},
 	}
-	_, err = launcher.Launch(ply, 1, false)
+	_, err = launcher.Launch(ply, 1, false, "")
 	require.NotNil(l.T(), err)
 
 	// system scope
@@ -275,7 +276,7 @@ func (l *launchTestSuite) TestLaunch() {
 			},
 		},
 	}
-	n, err = launcher.Launch(ply, 1, false)
+	n, err = launcher.Launch(ply, 1, false, "")
 	require.Nil(l.T(), err)
 	assert.Equal(l.T(), int64(2), n)
 }
@@ -22,7 +22,9 @@ import (
 
 	"github.com/astaxie/beego/orm"
 	cjob "github.com/goharbor/harbor/src/common/job"
+	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/pkg/art"
 	"github.com/goharbor/harbor/src/pkg/retention/dao"
 	"github.com/goharbor/harbor/src/pkg/retention/dao/models"
 	"github.com/goharbor/harbor/src/pkg/retention/policy"
@@ -162,6 +164,9 @@ func (d *DefaultManager) ListExecutions(policyID int64, query *q.Query) ([]*Exec
 		e1.EndTime = e.EndTime
 		e1.Trigger = e.Trigger
 		e1.DryRun = e.DryRun
+		if e1.Summary, err = executionSummary(e.ID); err != nil {
+			return nil, err
+		}
 		execs1 = append(execs1, e1)
 	}
 	return execs1, nil
@@ -186,6 +191,9 @@ func (d *DefaultManager) GetExecution(eid int64) (*Execution, error) {
 	e1.EndTime = e.EndTime
 	e1.Trigger = e.Trigger
 	e1.DryRun = e.DryRun
+	if e1.Summary, err = executionSummary(e.ID); err != nil {
+		return nil, err
+	}
 	return e1, nil
 }
 
@@ -205,6 +213,7 @@ func (d *DefaultManager) CreateTask(task *Task) (int64, error) {
 		EndTime:        task.EndTime,
 		Total:          task.Total,
 		Retained:       task.Retained,
+		Summary:        fromSummary(task.Summary),
 	}
 	return dao.CreateTask(t)
 }
@@ -232,6 +241,7 @@ func (d *DefaultManager) ListTasks(query ...*q.TaskQuery) ([]*Task, error) {
 			EndTime:        t.EndTime,
 			Total:          t.Total,
 			Retained:       t.Retained,
+			Summary:        toSummary(t.Summary),
 		})
 	}
 	return tasks, nil
@@ -262,6 +272,7 @@ func (d *DefaultManager) UpdateTask(task *Task, cols ...string) error {
 		EndTime:        task.EndTime,
 		Total:          task.Total,
 		Retained:       task.Retained,
+		Summary:        fromSummary(task.Summary),
 	}, cols...)
 }
 
@@ -295,6 +306,7 @@ func (d *DefaultManager) GetTask(taskID int64) (*Task, error) {
 		EndTime:        task.EndTime,
 		Total:          task.Total,
 		Retained:       task.Retained,
+		Summary:        toSummary(task.Summary),
 	}, nil
 }
 
@@ -310,6 +322,53 @@ func (d *DefaultManager) GetTaskLog(taskID int64) ([]byte, error) {
 	return cjob.GlobalClient.GetJobLog(task.JobID)
 }
 
+// executionSummary adds up the summaries of the tasks under the execution, nil is returned if none of
+// the tasks reports the summary
+func executionSummary(executionID int64) (*art.Summary, error) {
+	summaries, err := dao.ListTaskSummaries(executionID)
+	if err != nil {
+		return nil, err
+	}
+	var summary *art.Summary
+	for _, s := range summaries {
+		ts := toSummary(s)
+		if ts == nil {
+			continue
+		}
+		if summary == nil {
+			summary = &art.Summary{}
+		}
+		summary.Merge(ts)
+	}
+	return summary, nil
+}
+
+// toSummary parses the summary stored in database, the malformed one is ignored
+func toSummary(data string) *art.Summary {
+	if len(data) == 0 {
+		return nil
+	}
+	summary := &art.Summary{}
+	if err := json.Unmarshal([]byte(data), summary); err != nil {
+		log.Warningf("failed to parse the summary of retention task: %v", err)
+		return nil
+	}
+	return summary
+}
+
+// fromSummary converts the summary to the JSON stored in database
+func fromSummary(summary *art.Summary) string {
+	if summary == nil {
+		return ""
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		log.Warningf("failed to marshal the summary of retention task: %v", err)
+		return ""
+	}
+	return string(data)
+}
+
 // NewManager ...
 func NewManager() Manager {
 	return &DefaultManager{}
@@ -9,6 +9,7 @@ import (
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/job"
 	jjob "github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/pkg/art"
 	"github.com/goharbor/harbor/src/pkg/retention/policy"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/rule"
 	"github.com/goharbor/harbor/src/pkg/retention/q"
@@ -183,7 +184,8 @@ func TestTask(t *testing.T) {
 	// update
 	task.ID = id
 	task.Total = 1
-	err = m.UpdateTask(task, "Total")
+	task.Summary = &art.Summary{Total: 1, Retained: 1}
+	err = m.UpdateTask(task, "Total", "Summary")
 	require.Nil(t, err)
 
 	// update status to success which is a final status
@@ -208,6 +210,8 @@ func TestTask(t *testing.T) {
 	require.Equal(t, 1, len(tasks))
 	assert.Equal(t, int64(1000), tasks[0].ExecutionID)
 	assert.Equal(t, 1, tasks[0].Total)
+	require.NotNil(t, tasks[0].Summary)
+	assert.Equal(t, 1, tasks[0].Summary.Retained)
 	assert.Equal(t, jjob.RunningStatus.String(), tasks[0].Status)
 	assert.Equal(t, jjob.RunningStatus.Code(), tasks[0].StatusCode)
 	assert.Equal(t, int64(2), tasks[0].StatusRevision)
@@ -14,7 +14,11 @@
 
 package retention
 
-import "time"
+import (
+	"time"
+
+	"github.com/goharbor/harbor/src/pkg/art"
+)
 
 // const definitions
 const (
@@ -39,6 +43,8 @@ type Execution struct {
 	Status    string    `json:"status"`
 	Trigger   string    `json:"trigger"`
 	DryRun    bool      `json:"dry_run"`
+	// Summary adds up the summaries of the tasks, nil if none of the tasks reports it
+	Summary *art.Summary `json:"summary,omitempty"`
 }
 
 // Task of retention
@@ -54,6 +60,8 @@ type Task struct {
 	EndTime        time.Time `json:"end_time"`
 	Total          int       `json:"total"`
 	Retained       int       `json:"retained"`
+	// Summary of the results of the repository, nil if it isn't reported
+	Summary *art.Summary `json:"summary,omitempty"`
 }
 
 // History of retention
@@ -68,3 +76,66 @@ type History struct {
 	Artifact  string    `json:"tag"`
 	Timestamp time.Time `json:"timestamp"`
 }
+
+// Preview of the retention policy
+type Preview struct {
+	WouldRetain   int                 `json:"would_retain"`
+	WouldDelete   int                 `json:"would_delete"`
+	SampleDeletes []*PreviewCandidate `json:"sample_deletes"`
+	// Truncated is true if the preview isn't completed in time and only covers part of the repositories
+	Truncated bool `json:"truncated"`
+}
+
+// PreviewCandidate is the artifact which would be deleted by the retention policy
+type PreviewCandidate struct {
+	Repository string    `json:"repository"`
+	Tag        string    `json:"tag"`
+	Digest     string    `json:"digest"`
+	PushedAt   time.Time `json:"pushed_at"`
+	// The action would be taken and the reason, e.g. not retained by any rule
+	Action string      `json:"action,omitempty"`
+	Reason *art.Reason `json:"reason,omitempty"`
+}
+
+// const definitions for the status of conflict analysis
+const (
+	AnalysisStatusRunning string = "Running"
+	AnalysisStatusSucceed string = "Succeed"
+	AnalysisStatusFailed  string = "Failed"
+)
+
+// ConflictAnalysis is the result of the background conflict analysis of a retention policy
+type ConflictAnalysis struct {
+	PolicyID  int64          `json:"policy_id"`
+	Status    string         `json:"status"`
+	StartTime time.Time      `json:"start_time"`
+	EndTime   time.Time      `json:"end_time,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Conflicts []*TagConflict `json:"conflicts"`
+	// the repositories whose candidates are more than the analyzed ones
+	TruncatedRepositories []string `json:"truncated_repositories,omitempty"`
+}
+
+// DeletionCheckIn is checked in by the retention job once an artifact is deleted,
+// it's turned into the artifact deleted event by core
+type DeletionCheckIn struct {
+	Deleted *DeletedArtifact `json:"deleted"`
+}
+
+// SummaryCheckIn is checked in by the retention job at the end of the run, the total and retained
+// numbers are kept for the consumers of the legacy check-in
+type SummaryCheckIn struct {
+	Total    int          `json:"total"`
+	Retained int          `json:"retained"`
+	Summary  *art.Summary `json:"summary,omitempty"`
+}
+
+// DeletedArtifact is the artifact deleted by the retention job
+type DeletedArtifact struct {
+	ProjectID  int64  `json:"project_id"`
+	Namespace  string `json:"namespace"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	Digest     string `json:"digest"`
+	DeletedAt  int64  `json:"deleted_at"`
+}
@@ -27,6 +27,8 @@ var index sync.Map
 func init() {
 	// Register retain action
 	Register(action.Retain, action.NewRetainAction)
+	// Register delete action
+	Register(action.Delete, action.NewDeleteAction)
 }
 
 // Register the performer with the corresponding action
@@ -41,6 +43,22 @@ func Register(action string, factory action.PerformerFactory) {
 
 // Get performer with the provided action
 func Get(act string, params interface{}, isDryRun bool) (action.Performer, error) {
+	factory, err := factoryOf(act)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory(params, isDryRun), nil
+}
+
+// Valid checks whether the performer of the action is registered
+func Valid(act string) error {
+	_, err := factoryOf(act)
+	return err
+}
+
+// factoryOf returns the performer factory registered with the action
+func factoryOf(act string) (action.PerformerFactory, error) {
 	if len(act) == 0 {
 		return nil, errors.New("empty action")
 	}
@@ -55,5 +73,5 @@ func Get(act string, params interface{}, isDryRun bool) (action.Performer, error
 		return nil, errors.Errorf("invalid action performer registered for action %s", act)
 	}
 
-	return factory(params, isDryRun), nil
+	return factory, nil
 }
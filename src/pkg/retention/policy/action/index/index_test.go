@@ -15,6 +15,7 @@
 package index
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -57,7 +58,7 @@ func (suite *IndexTestSuite) TestGet() {
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), p)
 
-	results, err := p.Perform(suite.candidates)
+	results, err := p.Perform(context.Background(), suite.candidates)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), 1, len(results))
 	assert.Condition(suite.T(), func() (success bool) {
@@ -71,13 +72,22 @@ func (suite *IndexTestSuite) TestGet() {
 	})
 }
 
+// TestValid tests the action is valid only if its performer is registered
+func (suite *IndexTestSuite) TestValid() {
+	assert.NoError(suite.T(), Valid("fakeAction"))
+	assert.NoError(suite.T(), Valid(action.Retain))
+	assert.NoError(suite.T(), Valid(action.Delete))
+	assert.Error(suite.T(), Valid("archive"))
+	assert.Error(suite.T(), Valid(""))
+}
+
 type fakePerformer struct {
 	parameters interface{}
 	isDryRun   bool
 }
 
 // Perform the artifacts
-func (p *fakePerformer) Perform(candidates []*art.Candidate) (results []*art.Result, err error) {
+func (p *fakePerformer) Perform(ctx context.Context, candidates []*art.Candidate) (results []*art.Result, err error) {
 	for _, c := range candidates {
 		results = append(results, &art.Result{
 			Target: c,
@@ -15,80 +15,502 @@
 package action
 
 import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/pkg/art"
+	"github.com/goharbor/harbor/src/pkg/immutabletag/match"
 	"github.com/goharbor/harbor/src/pkg/retention/dep"
+	"github.com/pkg/errors"
 )
 
 const (
 	// Retain artifacts
 	Retain = "retain"
+	// Delete artifacts
+	Delete = "delete"
+
+	// DefaultWorkers is the default count of the workers deleting the candidates concurrently
+	DefaultWorkers = 5
+	// DefaultBatchSize is the default count of the candidates under the same repository deleted in one request
+	DefaultBatchSize = 50
 )
 
 // Performer performs the related actions targeting the candidates
 type Performer interface {
-	// Perform the action
+	// Perform the action, the results collected so far are returned with the error of the context
+	// once the context is cancelled or its deadline is exceeded
 	//
 	//  Arguments:
+	//    ctx context.Context         : the context for the cancellation and deadline
 	//    candidates []*art.Candidate : the targets to perform
 	//
 	//  Returns:
 	//    []*art.Result : result infos
 	//    error     : common error if any errors occurred
-	Perform(candidates []*art.Candidate) ([]*art.Result, error)
+	Perform(ctx context.Context, candidates []*art.Candidate) ([]*art.Result, error)
 }
 
 // PerformerFactory is factory method for creating Performer
 type PerformerFactory func(params interface{}, isDryRun bool) Performer
 
+// Params of the performers, it's passed to the PerformerFactory
+type Params struct {
+	// All the candidates under the repository
+	All []*art.Candidate
+	// The count of the workers deleting the candidates concurrently, DefaultWorkers is used if it isn't positive
+	Workers int
+	// The count of the candidates deleted in one request, the candidates are deleted one by one if it's less than 2
+	BatchSize int
+	// The hook checking whether the job is stopped, the outstanding deletions are skipped once it returns true
+	IsStopped func() bool
+	// The matcher of the immutable tag rules, the candidates matched are never deleted
+	ImmutableMatcher match.ImmutableTagMatcher
+	// The hook invoked once the candidate is deleted, it's never invoked in dry run
+	OnDeleted func(c *art.Candidate)
+	// Whether to check the deletability of the candidates in dry run, the candidates which would
+	// fail to be deleted are reported without deleting anything
+	Validate bool
+}
+
+// ErrStopped is set as the error of the results whose deletion is skipped as the job is stopped
+var ErrStopped = errors.New("retention job is stopped")
+
+// paramsOf extracts the params passed to the PerformerFactory, the candidates slice is
+// accepted for compatibility
+func paramsOf(params interface{}) *Params {
+	switch p := params.(type) {
+	case *Params:
+		if p != nil {
+			return p
+		}
+	case []*art.Candidate:
+		return &Params{All: p}
+	}
+
+	return &Params{}
+}
+
+// deletion holds the settings of deleting the candidates shared by the actions
+type deletion struct {
+	// Indicate if it is a dry run
+	isDryRun bool
+	// The count of the workers deleting the candidates concurrently
+	workers int
+	// The count of the candidates deleted in one request
+	batchSize int
+	// The hook checking whether the job is stopped
+	isStopped func() bool
+	// The matcher of the immutable tag rules
+	immutableMatcher match.ImmutableTagMatcher
+	// The hook invoked once the candidate is deleted
+	onDeleted func(c *art.Candidate)
+	// Whether to check the deletability of the candidates in dry run
+	validate bool
+}
+
+// deletionOf extracts the settings of deleting the candidates from the params
+func deletionOf(p *Params, isDryRun bool) deletion {
+	return deletion{
+		isDryRun:         isDryRun,
+		workers:          p.Workers,
+		batchSize:        p.BatchSize,
+		isStopped:        p.IsStopped,
+		immutableMatcher: p.ImmutableMatcher,
+		onDeleted:        p.OnDeleted,
+		validate:         p.Validate,
+	}
+}
+
 // retainAction make sure all the candidates will be retained and others will be cleared
 type retainAction struct {
+	deletion
+
 	all []*art.Candidate
-	// Indicate if it is a dry run
-	isDryRun bool
 }
 
-// Perform the action
-func (ra *retainAction) Perform(candidates []*art.Candidate) (results []*art.Result, err error) {
+// Perform the action, the results of the retained candidates come first and are followed
+// by the ones of the candidates deleted as they aren't retained
+func (ra *retainAction) Perform(ctx context.Context, candidates []*art.Candidate) (results []*art.Result, err error) {
+	if err := ctx.Err(); err != nil {
+		return []*art.Result{}, err
+	}
+
 	retained := make(map[string]bool)
+	results = make([]*art.Result, 0, len(ra.all))
+	now := time.Now()
 	for _, c := range candidates {
+		if retained[c.Hash()] {
+			continue
+		}
 		retained[c.Hash()] = true
+		results = append(results, &art.Result{
+			Target: c,
+			Action: art.ActionRetain,
+			Reason: &art.Reason{
+				Code:    art.ReasonRetained,
+				Message: "retained by the rule",
+			},
+			StartTime: now,
+			EndTime:   now,
+		})
 	}
 
 	// start to delete
 	if len(ra.all) > 0 {
+		deletions := make([]*art.Candidate, 0)
 		for _, c := range ra.all {
 			if _, ok := retained[c.Hash()]; !ok {
-				result := &art.Result{
-					Target: c,
+				deletions = append(deletions, c)
+			}
+		}
+
+		reason := &art.Reason{
+			Code:    art.ReasonNotRetained,
+			Message: "not retained by any rule",
+		}
+		deleted, e := ra.perform(ctx, deletions, reason)
+		results = append(results, deleted...)
+		err = e
+	}
+
+	return
+}
+
+// deleteAction deletes exactly the candidates, the others are left untouched
+type deleteAction struct {
+	deletion
+}
+
+// Perform the action
+func (da *deleteAction) Perform(ctx context.Context, candidates []*art.Candidate) (results []*art.Result, err error) {
+	reason := &art.Reason{
+		Code:    art.ReasonMatched,
+		Message: "matched by the rule deleting the artifacts",
+	}
+	return da.perform(ctx, candidates, reason)
+}
+
+// perform deletes the candidates with a pool of workers, or only records their sizes in dry run.
+// The results are in the order of the candidates and each one carries its own error, the reason is the
+// one of the deletion unless the deletion is skipped or fails. The candidates protected by the immutable
+// tag rules are skipped before any deletion and the deletions not started yet are skipped with ErrStopped
+// once the job is stopped. The candidates are deleted in batches if the batch size is greater than 1.
+// Once the context is done, nothing is started anymore and only the results of the candidates handled
+// so far are returned with the error of the context.
+func (d *deletion) perform(ctx context.Context, candidates []*art.Candidate, reason *art.Reason) ([]*art.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return []*art.Result{}, err
+	}
+
+	results := make([]*art.Result, len(candidates))
+	// whether the candidate is handled, each one is only touched by the goroutine handling it
+	handled := make([]bool, len(candidates))
+	deletions := make([]int, 0, len(candidates))
+	for i, c := range candidates {
+		results[i] = &art.Result{
+			Target: c,
+			Action: art.ActionDelete,
+			Reason: reason,
+		}
+		if skipImmutable(c, results[i], d.immutableMatcher, d.isDryRun) {
+			handled[i] = true
+			continue
+		}
+		deletions = append(deletions, i)
+	}
+
+	// the dry run only reads the sizes and checks the deletability if required, no pool is needed
+	if d.isDryRun {
+		deletable := make([]int, 0, len(deletions))
+		for _, i := range deletions {
+			if ctx.Err() != nil {
+				break
+			}
+			results[i].StartTime = time.Now()
+			if !d.validate || checkCandidate(ctx, candidates[i], results[i]) {
+				deletable = append(deletable, i)
+			}
+			results[i].EndTime = time.Now()
+			// the result of the check interrupted by the context is incomplete
+			handled[i] = ctx.Err() == nil
+		}
+		// the sizes of the partial results are left unknown
+		if ctx.Err() == nil {
+			sizeCandidates(ctx, candidates, results, deletable)
+		}
+
+		return handledResults(ctx, results, handled)
+	}
+
+	if len(deletions) == 0 {
+		return results, nil
+	}
+
+	// every worker takes one batch of the indexes each time
+	size := d.batchSize
+	if size < 2 {
+		size = 1
+	}
+	batches := make([][]int, 0, (len(deletions)+size-1)/size)
+	for start := 0; start < len(deletions); start += size {
+		end := start + size
+		if end > len(deletions) {
+			end = len(deletions)
+		}
+		batches = append(batches, deletions[start:end])
+	}
+
+	workers := d.workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if workers > len(batches) {
+		workers = len(batches)
+	}
+
+	queue := make(chan []int)
+	wg := new(sync.WaitGroup)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for batch := range queue {
+				// drain the outstanding batches once the context is done
+				if ctx.Err() != nil {
+					continue
+				}
+				for _, i := range batch {
+					handled[i] = true
 				}
 
-				if !ra.isDryRun {
-					if err := dep.DefaultClient.Delete(c); err != nil {
-						result.Error = err
+				if d.isStopped != nil && d.isStopped() {
+					for _, i := range batch {
+						stopDeletion(results[i])
 					}
+					continue
 				}
 
-				results = append(results, result)
+				if size == 1 {
+					i := batch[0]
+					results[i].StartTime = time.Now()
+					deleteCandidate(ctx, candidates[i], results[i], d.onDeleted)
+					results[i].EndTime = time.Now()
+					continue
+				}
+				deleteCandidates(ctx, candidates, results, batch, d.onDeleted)
 			}
+		}()
+	}
+
+	for _, batch := range batches {
+		queue <- batch
+	}
+	close(queue)
+	wg.Wait()
+
+	return handledResults(ctx, results, handled)
+}
+
+// handledResults returns all the results if the context isn't done, otherwise only the results of the
+// handled candidates are returned with the error of the context
+func handledResults(ctx context.Context, results []*art.Result, handled []bool) ([]*art.Result, error) {
+	err := ctx.Err()
+	if err == nil {
+		return results, nil
+	}
+
+	partial := make([]*art.Result, 0, len(results))
+	for i, r := range results {
+		if handled[i] {
+			partial = append(partial, r)
 		}
 	}
+	return partial, err
+}
 
-	return
+// stopDeletion marks the result as skipped as the job is stopped
+func stopDeletion(result *art.Result) {
+	result.Error = ErrStopped
+	result.Action = art.ActionSkip
+	result.Reason = &art.Reason{
+		Code:    art.ReasonStopped,
+		Message: ErrStopped.Error(),
+	}
 }
 
-// NewRetainAction is factory method for RetainAction
-func NewRetainAction(params interface{}, isDryRun bool) Performer {
-	if params != nil {
-		if all, ok := params.([]*art.Candidate); ok {
-			return &retainAction{
-				all:      all,
-				isDryRun: isDryRun,
+// skipImmutable checks the candidate against the immutable tag rules and marks the result as skipped
+// if it's protected, the candidate isn't deleted either if the rules fail to be checked
+func skipImmutable(c *art.Candidate, result *art.Result, immutableMatcher match.ImmutableTagMatcher, isDryRun bool) bool {
+	if immutableMatcher == nil {
+		return false
+	}
+
+	immutable, err := immutableMatcher.Match(*c)
+	if err != nil {
+		failDeletion(result, errors.Wrap(err, "check the immutable tag rules"))
+		return true
+	}
+	if !immutable {
+		return false
+	}
+
+	result.Action = art.ActionSkip
+	result.Reason = &art.Reason{
+		Code:    art.ReasonImmutable,
+		Message: "retained (immutable)",
+	}
+	if isDryRun {
+		result.Reason.Message = "would be retained (immutable)"
+	}
+
+	return true
+}
+
+// deleteCandidate deletes the candidate and records the result, the deletion is skipped
+// if the candidate has been deleted by others, e.g: another retention execution, either
+// before the existence is checked or between the check and the deletion. The hook is only
+// invoked once the candidate is deleted successfully.
+func deleteCandidate(ctx context.Context, c *art.Candidate, result *art.Result, onDeleted func(c *art.Candidate)) {
+	exists, err := dep.DefaultClient.Exists(ctx, c)
+	if err != nil {
+		failDeletion(result, err)
+		return
+	}
+	if !exists {
+		markAlreadyDeleted(result)
+		return
+	}
+	if err := dep.DefaultClient.Delete(ctx, c); err != nil {
+		if err == dep.ErrCandidateNotFound {
+			markAlreadyDeleted(result)
+			return
+		}
+		failDeletion(result, err)
+		return
+	}
+	if onDeleted != nil {
+		onDeleted(c)
+	}
+}
+
+// deleteCandidates deletes the candidates specified by the indexes in one batch and records the results,
+// the candidates deleted by others are skipped like deleteCandidate does
+func deleteCandidates(ctx context.Context, candidates []*art.Candidate, results []*art.Result, indexes []int, onDeleted func(c *art.Candidate)) {
+	batch := make([]*art.Candidate, 0, len(indexes))
+	for _, i := range indexes {
+		batch = append(batch, candidates[i])
+	}
+
+	start := time.Now()
+	errs := dep.DefaultClient.DeleteBatch(ctx, batch)
+	end := time.Now()
+	for j, i := range indexes {
+		results[i].StartTime = start
+		results[i].EndTime = end
+
+		var err error
+		if j < len(errs) {
+			err = errs[j]
+		} else {
+			err = errors.New("no deletion result returned")
+		}
+		switch {
+		case err == nil:
+			if onDeleted != nil {
+				onDeleted(candidates[i])
 			}
+		case err == dep.ErrCandidateNotFound:
+			markAlreadyDeleted(results[i])
+		default:
+			failDeletion(results[i], err)
 		}
 	}
+}
+
+// markAlreadyDeleted marks the result as skipped as the candidate has been deleted by others
+func markAlreadyDeleted(result *art.Result) {
+	result.AlreadyDeleted = true
+	result.Action = art.ActionSkip
+	result.Reason = &art.Reason{
+		Code:    art.ReasonAlreadyDeleted,
+		Message: "deleted by others before the action was taken",
+	}
+}
+
+// checkCandidate checks whether the candidate can be deleted and records the failure the deletion would hit,
+// false is returned if the candidate wouldn't be deleted
+func checkCandidate(ctx context.Context, c *art.Candidate, result *art.Result) bool {
+	err := dep.DefaultClient.CheckDeletable(ctx, c)
+	switch {
+	case err == nil:
+		return true
+	case err == dep.ErrCandidateNotFound:
+		markAlreadyDeleted(result)
+	default:
+		result.Error = err
+		result.Reason = &art.Reason{
+			Code:    art.ReasonUndeletable,
+			Message: fmt.Sprintf("would fail to be deleted: %v", err),
+		}
+	}
+	return false
+}
+
+// failDeletion records the error of the deletion
+func failDeletion(result *art.Result, err error) {
+	result.Error = err
+	result.Reason = &art.Reason{
+		Code:    art.ReasonDeletionFailed,
+		Message: err.Error(),
+	}
+}
+
+// sizeCandidates records the sizes which would be freed by deleting the candidates of the indexes all together,
+// the layers shared among them are counted only once. Failing to get the sizes doesn't fail the dry run
+func sizeCandidates(ctx context.Context, candidates []*art.Candidate, results []*art.Result, indexes []int) {
+	if len(indexes) == 0 {
+		return
+	}
+	cs := make([]*art.Candidate, 0, len(indexes))
+	for _, i := range indexes {
+		cs = append(cs, candidates[i])
+	}
+	sizes, err := dep.DefaultClient.GetReclaimableSizes(ctx, cs)
+	if err != nil {
+		log.Warningf("failed to get the reclaimable sizes of %d artifacts: %v", len(cs), err)
+		return
+	}
+	for _, i := range indexes {
+		results[i].SizeBytes = sizes[candidates[i].Digest]
+	}
+}
+
+// NewRetainAction is factory method for RetainAction, the params can be the *Params
+// or all the candidates
+func NewRetainAction(params interface{}, isDryRun bool) Performer {
+	p := paramsOf(params)
+	all := p.All
+	if all == nil {
+		all = make([]*art.Candidate, 0)
+	}
 
 	return &retainAction{
-		all:      make([]*art.Candidate, 0),
-		isDryRun: isDryRun,
+		deletion: deletionOf(p, isDryRun),
+		all:      all,
+	}
+}
+
+// NewDeleteAction is factory method for DeleteAction, only the candidates passed to Perform
+// are deleted so the candidates in the params are ignored
+func NewDeleteAction(params interface{}, isDryRun bool) Performer {
+	p := paramsOf(params)
+
+	return &deleteAction{
+		deletion: deletionOf(p, isDryRun),
 	}
 }
@@ -15,6 +15,10 @@
 package action
 
 import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
 	"testing"
 	"time"
 
@@ -63,6 +67,10 @@ func (suite *TestPerformerSuite) SetupSuite() {
 	}
 
 	suite.oldClient = dep.DefaultClient
+}
+
+// SetupTest ...
+func (suite *TestPerformerSuite) SetupTest() {
 	dep.DefaultClient = &fakeRetentionClient{}
 }
 
@@ -89,27 +97,799 @@ func (suite *TestPerformerSuite) TestPerform() {
 		},
 	}
 
-	results, err := p.Perform(candidates)
+	results, err := p.Perform(context.Background(), candidates)
 	require.NoError(suite.T(), err)
-	require.Equal(suite.T(), 1, len(results))
+	require.Equal(suite.T(), 2, len(results))
+	// the retained one comes first
 	require.NotNil(suite.T(), results[0].Target)
 	assert.NoError(suite.T(), results[0].Error)
+	assert.Equal(suite.T(), "latest", results[0].Target.Tag)
+	assert.Equal(suite.T(), art.ActionRetain, results[0].Action)
+	require.NotNil(suite.T(), results[0].Reason)
+	assert.Equal(suite.T(), art.ReasonRetained, results[0].Reason.Code)
+	require.NotNil(suite.T(), results[1].Target)
+	assert.NoError(suite.T(), results[1].Error)
+	assert.Equal(suite.T(), "dev", results[1].Target.Tag)
+	assert.Equal(suite.T(), art.ActionDelete, results[1].Action)
+
+	// the candidate retained by several rules is reported once
+	results, err = NewRetainAction(suite.all, true).Perform(context.Background(), []*art.Candidate{suite.all[0], suite.all[0]})
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	assert.Equal(suite.T(), art.ActionRetain, results[0].Action)
+}
+
+// TestPerformAlreadyDeleted tests the candidate deleted by another execution is skipped
+func (suite *TestPerformerSuite) TestPerformAlreadyDeleted() {
+	retained := []*art.Candidate{suite.all[0]}
+
+	// two executions of different policies target the same candidate
+	first := &retainAction{all: suite.all}
+	second := &retainAction{all: suite.all}
+
+	results, err := deletionsOf(first.Perform(context.Background(), retained))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(results))
+	assert.NoError(suite.T(), results[0].Error)
+	assert.False(suite.T(), results[0].AlreadyDeleted)
+
+	results, err = deletionsOf(second.Perform(context.Background(), retained))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(results))
+	assert.NoError(suite.T(), results[0].Error)
+	assert.True(suite.T(), results[0].AlreadyDeleted)
+	assert.Equal(suite.T(), "dev", results[0].Target.Tag)
+
+	client := dep.DefaultClient.(*fakeRetentionClient)
+	assert.Equal(suite.T(), 1, client.deleteCalls)
+}
+
+// TestPerformAlreadyDeletedConcurrently tests the executions running at the same time both find the
+// candidate existing, the one losing the race gets the 404 of the deletion and skips the candidate
+func (suite *TestPerformerSuite) TestPerformAlreadyDeletedConcurrently() {
+	client := &fakeRetentionClient{}
+	racing := &racingExistsClient{fakeRetentionClient: client}
+	racing.checked.Add(2)
+	dep.DefaultClient = racing
+
+	retained := []*art.Candidate{suite.all[0]}
+	results := make([][]*art.Result, 2)
+	errs := make([]error, 2)
+	wg := new(sync.WaitGroup)
+	wg.Add(2)
+	for i := 0; i < 2; i++ {
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = deletionsOf(NewRetainAction(suite.all, false).Perform(context.Background(), retained))
+		}(i)
+	}
+	wg.Wait()
+
+	alreadyDeleted := 0
+	for i := 0; i < 2; i++ {
+		require.NoError(suite.T(), errs[i])
+		require.Equal(suite.T(), 1, len(results[i]))
+		assert.NoError(suite.T(), results[i][0].Error)
+		assert.Equal(suite.T(), "dev", results[i][0].Target.Tag)
+		if results[i][0].AlreadyDeleted {
+			alreadyDeleted++
+			assert.Equal(suite.T(), art.ActionSkip, results[i][0].Action)
+			assert.Equal(suite.T(), art.ReasonAlreadyDeleted, results[i][0].Reason.Code)
+		}
+	}
+	assert.Equal(suite.T(), 1, alreadyDeleted)
+	assert.Equal(suite.T(), 1, client.deleteCalls)
+}
+
+// TestPerformDryRun tests the sizes are recorded but nothing is deleted in dry run
+func (suite *TestPerformerSuite) TestPerformDryRun() {
+	p := NewRetainAction(suite.all, true)
+
+	results, err := deletionsOf(p.Perform(context.Background(), []*art.Candidate{suite.all[0]}))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(results))
+	assert.NoError(suite.T(), results[0].Error)
+	assert.Equal(suite.T(), "dev", results[0].Target.Tag)
+	assert.Equal(suite.T(), int64(1024), results[0].SizeBytes)
+
+	client := dep.DefaultClient.(*fakeRetentionClient)
+	assert.Equal(suite.T(), 0, client.deleteCalls)
+}
+
+// TestDeletePerform tests exactly the candidates are deleted by the delete action
+func (suite *TestPerformerSuite) TestDeletePerform() {
+	p := NewDeleteAction(nil, false)
+
+	results, err := p.Perform(context.Background(), []*art.Candidate{suite.all[1]})
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(results))
+	assert.NoError(suite.T(), results[0].Error)
 	assert.Equal(suite.T(), "dev", results[0].Target.Tag)
+
+	client := dep.DefaultClient.(*fakeRetentionClient)
+	assert.Equal(suite.T(), 1, client.deleteCalls)
+	assert.True(suite.T(), client.deleted[suite.all[1].Hash()])
+	assert.False(suite.T(), client.deleted[suite.all[0].Hash()])
 }
 
-type fakeRetentionClient struct{}
+// TestDeletePerformDryRun tests the sizes are recorded but nothing is deleted in dry run
+func (suite *TestPerformerSuite) TestDeletePerformDryRun() {
+	p := NewDeleteAction(nil, true)
+
+	results, err := p.Perform(context.Background(), suite.all)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	for _, result := range results {
+		assert.NoError(suite.T(), result.Error)
+		assert.Equal(suite.T(), int64(1024), result.SizeBytes)
+	}
+
+	client := dep.DefaultClient.(*fakeRetentionClient)
+	assert.Equal(suite.T(), 0, client.deleteCalls)
+}
+
+// TestDeletePerformPartialFailure tests the failure of one candidate doesn't stop the deletion of the others
+func (suite *TestPerformerSuite) TestDeletePerformPartialFailure() {
+	client := dep.DefaultClient.(*fakeRetentionClient)
+	client.failures = map[string]error{
+		suite.all[0].Hash(): errors.New("internal error"),
+	}
+	p := NewDeleteAction(nil, false)
+
+	results, err := p.Perform(context.Background(), suite.all)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	assert.Error(suite.T(), results[0].Error)
+	assert.Equal(suite.T(), "latest", results[0].Target.Tag)
+	assert.NoError(suite.T(), results[1].Error)
+	assert.Equal(suite.T(), "dev", results[1].Target.Tag)
+	assert.True(suite.T(), client.deleted[suite.all[1].Hash()])
+}
+
+// TestDeletePerformEmpty tests nothing is deleted without candidates
+func (suite *TestPerformerSuite) TestDeletePerformEmpty() {
+	p := NewDeleteAction(suite.all, false)
+
+	results, err := p.Perform(context.Background(), nil)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), results)
+
+	client := dep.DefaultClient.(*fakeRetentionClient)
+	assert.Equal(suite.T(), 0, client.deleteCalls)
+}
+
+// TestRetainPerformConcurrently tests the candidates are deleted concurrently and the results keep the order
+func (suite *TestPerformerSuite) TestRetainPerformConcurrently() {
+	all := candidates(20)
+	perform := func(workers int) ([]*art.Result, time.Duration) {
+		dep.DefaultClient = &fakeRetentionClient{latency: 20 * time.Millisecond}
+		p := NewRetainAction(&Params{All: all, Workers: workers}, false)
+		start := time.Now()
+		results, err := deletionsOf(p.Perform(context.Background(), all[:2]))
+		require.NoError(suite.T(), err)
+		return results, time.Since(start)
+	}
+
+	_, sequential := perform(1)
+	results, concurrent := perform(DefaultWorkers)
+	assert.True(suite.T(), concurrent < sequential/2, "sequential: %v, concurrent: %v", sequential, concurrent)
+
+	require.Equal(suite.T(), 18, len(results))
+	for i, result := range results {
+		assert.NoError(suite.T(), result.Error)
+		assert.Equal(suite.T(), all[i+2], result.Target)
+	}
+	client := dep.DefaultClient.(*fakeRetentionClient)
+	assert.Equal(suite.T(), 18, client.deleteCalls)
+}
+
+// TestRetainPerformStopped tests the outstanding deletions are skipped once the job is stopped
+func (suite *TestPerformerSuite) TestRetainPerformStopped() {
+	all := candidates(20)
+	client := &fakeRetentionClient{latency: 10 * time.Millisecond}
+	dep.DefaultClient = client
+	// the job is stopped after the first 4 deletions
+	isStopped := func() bool {
+		client.lock.Lock()
+		defer client.lock.Unlock()
+		return client.deleteCalls >= 4
+	}
+	p := NewRetainAction(&Params{All: all, Workers: 2, IsStopped: isStopped}, false)
+
+	results, err := p.Perform(context.Background(), nil)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 20, len(results))
+	stopped := 0
+	for i, result := range results {
+		assert.Equal(suite.T(), all[i], result.Target)
+		if result.Error == ErrStopped {
+			stopped++
+			assert.False(suite.T(), client.deleted[result.Target.Hash()])
+			continue
+		}
+		assert.NoError(suite.T(), result.Error)
+		assert.True(suite.T(), client.deleted[result.Target.Hash()])
+	}
+	// the deletions in progress when the job is stopped are completed
+	assert.True(suite.T(), client.deleteCalls >= 4 && client.deleteCalls < 20, "deleted: %d", client.deleteCalls)
+	assert.Equal(suite.T(), 20-client.deleteCalls, stopped)
+}
+
+// TestRetainPerformDryRunSkipsPool tests the dry run neither deletes nor checks the stop hook
+func (suite *TestPerformerSuite) TestRetainPerformDryRunSkipsPool() {
+	isStopped := func() bool {
+		suite.Fail("the stop hook shouldn't be checked in dry run")
+		return true
+	}
+	p := NewRetainAction(&Params{All: suite.all, IsStopped: isStopped}, true)
+
+	results, err := p.Perform(context.Background(), nil)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	for _, result := range results {
+		assert.NoError(suite.T(), result.Error)
+		assert.Equal(suite.T(), int64(1024), result.SizeBytes)
+	}
+
+	client := dep.DefaultClient.(*fakeRetentionClient)
+	assert.Equal(suite.T(), 0, client.deleteCalls)
+}
+
+// TestResultActionAndReason tests the action and the reason recorded for each branch of the deletion
+func (suite *TestPerformerSuite) TestResultActionAndReason() {
+	assertResult := func(result *art.Result, action, code string) {
+		assert.Equal(suite.T(), action, result.Action)
+		require.NotNil(suite.T(), result.Reason)
+		assert.Equal(suite.T(), code, result.Reason.Code)
+		assert.NotEmpty(suite.T(), result.Reason.Message)
+	}
+
+	// deleted as it isn't retained
+	before := time.Now()
+	results, err := deletionsOf(NewRetainAction(suite.all, false).Perform(context.Background(), []*art.Candidate{suite.all[0]}))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(results))
+	assert.NoError(suite.T(), results[0].Error)
+	assertResult(results[0], art.ActionDelete, art.ReasonNotRetained)
+	assert.False(suite.T(), results[0].StartTime.Before(before))
+	assert.False(suite.T(), results[0].EndTime.Before(results[0].StartTime))
+
+	// skipped as it has been deleted
+	results, err = deletionsOf(NewRetainAction(suite.all, false).Perform(context.Background(), []*art.Candidate{suite.all[0]}))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(results))
+	assert.True(suite.T(), results[0].AlreadyDeleted)
+	assertResult(results[0], art.ActionSkip, art.ReasonAlreadyDeleted)
+
+	// deleted as it's matched by the delete rule
+	results, err = NewDeleteAction(nil, false).Perform(context.Background(), []*art.Candidate{suite.all[0]})
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(results))
+	assert.NoError(suite.T(), results[0].Error)
+	assertResult(results[0], art.ActionDelete, art.ReasonMatched)
+
+	// the failure of the deletion
+	dep.DefaultClient = &fakeRetentionClient{failures: map[string]error{
+		suite.all[1].Hash(): errors.New("internal error"),
+	}}
+	results, err = NewDeleteAction(nil, false).Perform(context.Background(), []*art.Candidate{suite.all[1]})
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(results))
+	assert.Error(suite.T(), results[0].Error)
+	assertResult(results[0], art.ActionDelete, art.ReasonDeletionFailed)
+	assert.Equal(suite.T(), "internal error", results[0].Reason.Message)
+
+	// skipped as the job is stopped
+	isStopped := func() bool {
+		return true
+	}
+	results, err = NewRetainAction(&Params{All: suite.all, IsStopped: isStopped}, false).Perform(context.Background(), nil)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	for _, result := range results {
+		assert.Equal(suite.T(), ErrStopped, result.Error)
+		assertResult(result, art.ActionSkip, art.ReasonStopped)
+		assert.True(suite.T(), result.StartTime.IsZero())
+	}
+
+	// would be deleted in dry run
+	results, err = NewRetainAction(suite.all, true).Perform(context.Background(), nil)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	for _, result := range results {
+		assert.NoError(suite.T(), result.Error)
+		assertResult(result, art.ActionDelete, art.ReasonNotRetained)
+	}
+	// retained by the rule
+	results, err = NewRetainAction(suite.all, true).Perform(context.Background(), []*art.Candidate{suite.all[0]})
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	assert.Equal(suite.T(), suite.all[0], results[0].Target)
+	assert.NoError(suite.T(), results[0].Error)
+	assertResult(results[0], art.ActionRetain, art.ReasonRetained)
+	assertResult(results[1], art.ActionDelete, art.ReasonNotRetained)
+}
+
+// TestPerformImmutable tests the candidates protected by the immutable tag rules are skipped
+func (suite *TestPerformerSuite) TestPerformImmutable() {
+	all := []*art.Candidate{
+		{Namespace: "library", Repository: "harbor", Kind: "image", Tag: "dev-1", Digest: "d1"},
+		{Namespace: "library", Repository: "harbor", Kind: "image", Tag: "dev-stable", Digest: "d2"},
+		{Namespace: "library", Repository: "harbor", Kind: "image", Tag: "release-1", Digest: "r1"},
+		{Namespace: "library", Repository: "harbor", Kind: "image", Tag: "release-stable", Digest: "r2"},
+	}
+	// "*-stable" overlaps with both "dev-*" deleted and "release-*" not retained
+	matcher := &fakeImmutableMatcher{pattern: "*-stable"}
+	assertResult := func(result *art.Result, action, code string) {
+		assert.Equal(suite.T(), action, result.Action)
+		require.NotNil(suite.T(), result.Reason)
+		assert.Equal(suite.T(), code, result.Reason.Code)
+	}
+
+	// the delete rule matches "dev-*"
+	results, err := NewDeleteAction(&Params{ImmutableMatcher: matcher}, true).Perform(context.Background(), all[:2])
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	assertResult(results[0], art.ActionDelete, art.ReasonMatched)
+	assert.Equal(suite.T(), int64(1024), results[0].SizeBytes)
+	assertResult(results[1], art.ActionSkip, art.ReasonImmutable)
+	assert.Equal(suite.T(), "would be retained (immutable)", results[1].Reason.Message)
+	assert.NoError(suite.T(), results[1].Error)
+	assert.Equal(suite.T(), int64(0), results[1].SizeBytes)
+
+	results, err = NewDeleteAction(&Params{ImmutableMatcher: matcher}, false).Perform(context.Background(), all[:2])
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	assertResult(results[0], art.ActionDelete, art.ReasonMatched)
+	assertResult(results[1], art.ActionSkip, art.ReasonImmutable)
+	assert.Equal(suite.T(), "retained (immutable)", results[1].Reason.Message)
+	assert.NoError(suite.T(), results[1].Error)
+
+	// the retain rule retains "dev-*" only
+	dep.DefaultClient = &fakeRetentionClient{}
+	results, err = deletionsOf(NewRetainAction(&Params{All: all, ImmutableMatcher: matcher}, false).Perform(context.Background(), all[:2]))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	assert.Equal(suite.T(), "release-1", results[0].Target.Tag)
+	assertResult(results[0], art.ActionDelete, art.ReasonNotRetained)
+	assert.Equal(suite.T(), "release-stable", results[1].Target.Tag)
+	assertResult(results[1], art.ActionSkip, art.ReasonImmutable)
+
+	client := dep.DefaultClient.(*fakeRetentionClient)
+	assert.Equal(suite.T(), 1, client.deleteCalls)
+	assert.False(suite.T(), client.deleted[all[3].Hash()])
+
+	// nothing is deleted if the immutable tag rules fail to be checked
+	matcher = &fakeImmutableMatcher{err: errors.New("internal error")}
+	results, err = NewDeleteAction(&Params{ImmutableMatcher: matcher}, false).Perform(context.Background(), all[2:])
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(results))
+	for _, result := range results {
+		assert.Error(suite.T(), result.Error)
+		assertResult(result, art.ActionDelete, art.ReasonDeletionFailed)
+	}
+	assert.Equal(suite.T(), 1, client.deleteCalls)
+}
+
+// TestPerformOnDeleted tests the hook is invoked once per deleted candidate and never in dry run
+func (suite *TestPerformerSuite) TestPerformOnDeleted() {
+	lock := new(sync.Mutex)
+	var deleted []string
+	onDeleted := func(c *art.Candidate) {
+		lock.Lock()
+		defer lock.Unlock()
+		deleted = append(deleted, c.Tag)
+	}
+
+	all := candidates(10)
+	results, err := deletionsOf(NewRetainAction(&Params{All: all, OnDeleted: onDeleted}, true).Perform(context.Background(), all[:2]))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 8, len(results))
+	assert.Empty(suite.T(), deleted)
+
+	// the failed and already deleted ones are excluded
+	dep.DefaultClient = &fakeRetentionClient{failures: map[string]error{
+		all[2].Hash(): errors.New("internal error"),
+	}}
+	_, err = NewDeleteAction(nil, false).Perform(context.Background(), all[9:])
+	require.NoError(suite.T(), err)
+	results, err = deletionsOf(NewRetainAction(&Params{All: all, OnDeleted: onDeleted}, false).Perform(context.Background(), all[:2]))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 8, len(results))
+	assert.Error(suite.T(), results[0].Error)
+	assert.True(suite.T(), results[7].AlreadyDeleted)
+	assert.ElementsMatch(suite.T(), []string{"v3", "v4", "v5", "v6", "v7", "v8"}, deleted)
+}
+
+// TestRetainPerformValidatedDryRun tests the validated dry run reports the candidates which would fail
+// to be deleted while the fast one doesn't, nothing is deleted in both modes
+func (suite *TestPerformerSuite) TestRetainPerformValidatedDryRun() {
+	all := candidates(6)
+	client := &fakeRetentionClient{
+		signed: map[string]bool{all[2].Hash(): true},
+		failures: map[string]error{
+			all[3].Hash(): errors.New("unauthorized"),
+		},
+	}
+	dep.DefaultClient = client
+	// the last one is deleted by others
+	_, err := NewDeleteAction(nil, false).Perform(context.Background(), all[5:])
+	require.NoError(suite.T(), err)
+	client.deleteCalls = 0
+
+	// fast
+	results, err := deletionsOf(NewRetainAction(&Params{All: all}, true).Perform(context.Background(), all[:2]))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 4, len(results))
+	for _, result := range results {
+		assert.NoError(suite.T(), result.Error)
+		assert.Equal(suite.T(), art.ReasonNotRetained, result.Reason.Code)
+		assert.Equal(suite.T(), int64(1024), result.SizeBytes)
+	}
+	assert.Equal(suite.T(), 0, client.checkCalls)
+	// the sizes of the deleting candidates are got all together
+	require.Equal(suite.T(), 1, len(client.sized))
+	assert.Equal(suite.T(), 4, len(client.sized[0]))
+
+	// validated
+	results, err = deletionsOf(NewRetainAction(&Params{All: all, Validate: true}, true).Perform(context.Background(), all[:2]))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 4, len(results))
+	assert.Equal(suite.T(), dep.ErrCandidateSigned, results[0].Error)
+	assert.Equal(suite.T(), art.ActionDelete, results[0].Action)
+	assert.Equal(suite.T(), art.ReasonUndeletable, results[0].Reason.Code)
+	assert.Contains(suite.T(), results[0].Reason.Message, "signed")
+	assert.Equal(suite.T(), int64(0), results[0].SizeBytes)
+	assert.Error(suite.T(), results[1].Error)
+	assert.Equal(suite.T(), art.ReasonUndeletable, results[1].Reason.Code)
+	assert.NoError(suite.T(), results[2].Error)
+	assert.Equal(suite.T(), art.ReasonNotRetained, results[2].Reason.Code)
+	assert.Equal(suite.T(), int64(1024), results[2].SizeBytes)
+	assert.True(suite.T(), results[3].AlreadyDeleted)
+	assert.Equal(suite.T(), 4, client.checkCalls)
+	// only the deletable candidates are sized
+	require.Equal(suite.T(), 2, len(client.sized))
+	assert.Equal(suite.T(), []*art.Candidate{results[2].Target}, client.sized[1])
+
+	// nothing is deleted in both modes
+	assert.Equal(suite.T(), 0, client.deleteCalls)
+}
+
+// TestRetainPerformCancelled tests only the results of the candidates handled before the cancellation
+// are returned with the error of the context
+func (suite *TestPerformerSuite) TestRetainPerformCancelled() {
+	all := candidates(10)
+	client := &fakeRetentionClient{}
+	dep.DefaultClient = client
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	deleted := 0
+	// cancelled after the third deletion, the only worker doesn't take any more candidates
+	onDeleted := func(c *art.Candidate) {
+		deleted++
+		if deleted == 3 {
+			cancel()
+		}
+	}
+	p := NewRetainAction(&Params{All: all, Workers: 1, OnDeleted: onDeleted}, false)
+	results, err := p.Perform(ctx, nil)
+	assert.Equal(suite.T(), context.Canceled, err)
+	require.Equal(suite.T(), 3, len(results))
+	for i, result := range results {
+		assert.Equal(suite.T(), all[i], result.Target)
+		assert.NoError(suite.T(), result.Error)
+	}
+	assert.Equal(suite.T(), 3, client.deleteCalls)
+
+	// nothing is started with the context cancelled already
+	results, err = p.Perform(ctx, nil)
+	assert.Equal(suite.T(), context.Canceled, err)
+	assert.Empty(suite.T(), results)
+	assert.Equal(suite.T(), 3, client.deleteCalls)
+}
+
+// TestRetainPerformTimeout tests the deletions not started before the deadline are dropped
+func (suite *TestPerformerSuite) TestRetainPerformTimeout() {
+	all := candidates(20)
+	client := &fakeRetentionClient{latency: 20 * time.Millisecond}
+	dep.DefaultClient = client
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	results, err := NewRetainAction(&Params{All: all, Workers: 1}, false).Perform(ctx, nil)
+	assert.Equal(suite.T(), context.DeadlineExceeded, err)
+	assert.True(suite.T(), len(results) > 0 && len(results) < 20, "results: %d", len(results))
+	for i, result := range results {
+		assert.Equal(suite.T(), all[i], result.Target)
+	}
+
+	// the dry run stops as well
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client = &fakeRetentionClient{}
+	dep.DefaultClient = &slowCheckClient{fakeRetentionClient: client, latency: 20 * time.Millisecond}
+	results, err = NewRetainAction(&Params{All: all, Validate: true}, true).Perform(ctx, nil)
+	assert.Equal(suite.T(), context.DeadlineExceeded, err)
+	assert.True(suite.T(), len(results) > 0 && len(results) < 20, "results: %d", len(results))
+	// the sizes of the partial results are unknown
+	for _, result := range results {
+		assert.Equal(suite.T(), int64(0), result.SizeBytes)
+	}
+	assert.Equal(suite.T(), 0, len(client.sized))
+}
+
+// TestRetainPerformBatch tests the candidates are deleted in batches and the results are mapped back
+func (suite *TestPerformerSuite) TestRetainPerformBatch() {
+	lock := new(sync.Mutex)
+	var deleted []string
+	onDeleted := func(c *art.Candidate) {
+		lock.Lock()
+		defer lock.Unlock()
+		deleted = append(deleted, c.Tag)
+	}
+
+	all := candidates(14)
+	client := &fakeRetentionClient{failures: map[string]error{
+		all[3].Hash(): errors.New("internal error"),
+	}}
+	dep.DefaultClient = client
+	// the last one is deleted by others
+	_, err := NewDeleteAction(nil, false).Perform(context.Background(), all[13:])
+	require.NoError(suite.T(), err)
+
+	p := NewRetainAction(&Params{All: all, Workers: 2, BatchSize: 5, OnDeleted: onDeleted}, false)
+	results, err := deletionsOf(p.Perform(context.Background(), all[:2]))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 12, len(results))
+	for i, result := range results {
+		assert.Equal(suite.T(), all[i+2], result.Target)
+		assert.False(suite.T(), result.StartTime.IsZero())
+		assert.False(suite.T(), result.EndTime.IsZero())
+	}
+	assertResult := func(result *art.Result, action, code string) {
+		assert.Equal(suite.T(), action, result.Action, result.Target.Tag)
+		require.NotNil(suite.T(), result.Reason, result.Target.Tag)
+		assert.Equal(suite.T(), code, result.Reason.Code, result.Target.Tag)
+	}
+	assertResult(results[1], art.ActionDelete, art.ReasonDeletionFailed)
+	assert.Error(suite.T(), results[1].Error)
+	assertResult(results[11], art.ActionSkip, art.ReasonAlreadyDeleted)
+	assert.True(suite.T(), results[11].AlreadyDeleted)
+	for _, i := range []int{0, 2, 3, 4, 5, 6, 7, 8, 9, 10} {
+		assertResult(results[i], art.ActionDelete, art.ReasonNotRetained)
+		assert.NoError(suite.T(), results[i].Error)
+	}
+	assert.Equal(suite.T(), 10, len(deleted))
+
+	// 12 candidates in 3 batches, nothing is deleted one by one
+	assert.Equal(suite.T(), 3, client.batchCalls)
+	assert.Equal(suite.T(), 1, client.deleteCalls)
+}
+
+// TestRetainPerformBatchStopped tests the outstanding batches are skipped once the job is stopped
+func (suite *TestPerformerSuite) TestRetainPerformBatchStopped() {
+	all := candidates(20)
+	client := &fakeRetentionClient{}
+	dep.DefaultClient = client
+	// the job is stopped after the first batch
+	isStopped := func() bool {
+		client.lock.Lock()
+		defer client.lock.Unlock()
+		return client.batchCalls >= 1
+	}
+	p := NewRetainAction(&Params{All: all, Workers: 1, BatchSize: 5, IsStopped: isStopped}, false)
+
+	results, err := p.Perform(context.Background(), nil)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 20, len(results))
+	for i, result := range results {
+		if i < 5 {
+			assert.NoError(suite.T(), result.Error)
+			assert.True(suite.T(), client.deleted[result.Target.Hash()])
+			continue
+		}
+		assert.Equal(suite.T(), ErrStopped, result.Error)
+		assert.Equal(suite.T(), art.ActionSkip, result.Action)
+		assert.False(suite.T(), client.deleted[result.Target.Hash()])
+	}
+	assert.Equal(suite.T(), 1, client.batchCalls)
+}
+
+func candidates(n int) []*art.Candidate {
+	all := make([]*art.Candidate, n)
+	for i := 0; i < n; i++ {
+		all[i] = &art.Candidate{
+			Namespace:  "library",
+			Repository: "harbor",
+			Kind:       "image",
+			Tag:        fmt.Sprintf("v%d", i),
+			Digest:     fmt.Sprintf("sha256:%064d", i),
+			PushedTime: time.Now().Unix(),
+		}
+	}
+
+	return all
+}
+
+func benchmarkRetainPerform(b *testing.B, workers int) {
+	oldClient := dep.DefaultClient
+	defer func() {
+		dep.DefaultClient = oldClient
+	}()
+
+	all := candidates(100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dep.DefaultClient = &fakeRetentionClient{latency: time.Millisecond}
+		if _, err := NewRetainAction(&Params{All: all, Workers: workers}, false).Perform(context.Background(), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRetainPerformSequential(b *testing.B) {
+	benchmarkRetainPerform(b, 1)
+}
+
+func BenchmarkRetainPerformDefaultWorkers(b *testing.B) {
+	benchmarkRetainPerform(b, DefaultWorkers)
+}
+
+type fakeRetentionClient struct {
+	lock        sync.Mutex
+	deleted     map[string]bool
+	deleteCalls int
+	batchCalls  int
+	// the errors returned when deleting the candidates
+	failures map[string]error
+	// the latency of each deletion
+	latency time.Duration
+	// the candidates signed by notary, which can't be deleted
+	signed     map[string]bool
+	checkCalls int
+	// the candidates of each call to get the reclaimable sizes
+	sized [][]*art.Candidate
+}
 
 // GetCandidates ...
-func (frc *fakeRetentionClient) GetCandidates(repo *art.Repository) ([]*art.Candidate, error) {
+func (frc *fakeRetentionClient) GetCandidates(ctx context.Context, repo *art.Repository) ([]*art.Candidate, error) {
 	return nil, errors.New("not implemented")
 }
 
 // Delete ...
-func (frc *fakeRetentionClient) Delete(candidate *art.Candidate) error {
+func (frc *fakeRetentionClient) Delete(ctx context.Context, candidate *art.Candidate) error {
+	time.Sleep(frc.latency)
+	frc.lock.Lock()
+	defer frc.lock.Unlock()
+	if err := frc.failures[candidate.Hash()]; err != nil {
+		return err
+	}
+	if frc.deleted[candidate.Hash()] {
+		return dep.ErrCandidateNotFound
+	}
+	if frc.deleted == nil {
+		frc.deleted = make(map[string]bool)
+	}
+	frc.deleted[candidate.Hash()] = true
+	frc.deleteCalls++
 	return nil
 }
 
+// DeleteBatch ...
+func (frc *fakeRetentionClient) DeleteBatch(ctx context.Context, candidates []*art.Candidate) []error {
+	time.Sleep(frc.latency)
+	frc.lock.Lock()
+	defer frc.lock.Unlock()
+	frc.batchCalls++
+	if frc.deleted == nil {
+		frc.deleted = make(map[string]bool)
+	}
+	errs := make([]error, len(candidates))
+	for i, candidate := range candidates {
+		if frc.deleted[candidate.Hash()] {
+			errs[i] = dep.ErrCandidateNotFound
+			continue
+		}
+		if err := frc.failures[candidate.Hash()]; err != nil {
+			errs[i] = err
+			continue
+		}
+		frc.deleted[candidate.Hash()] = true
+	}
+	return errs
+}
+
+// CheckDeletable ...
+func (frc *fakeRetentionClient) CheckDeletable(ctx context.Context, candidate *art.Candidate) error {
+	frc.lock.Lock()
+	defer frc.lock.Unlock()
+	frc.checkCalls++
+	if frc.deleted[candidate.Hash()] {
+		return dep.ErrCandidateNotFound
+	}
+	if frc.signed[candidate.Hash()] {
+		return dep.ErrCandidateSigned
+	}
+	return frc.failures[candidate.Hash()]
+}
+
+// Exists ...
+func (frc *fakeRetentionClient) Exists(ctx context.Context, candidate *art.Candidate) (bool, error) {
+	frc.lock.Lock()
+	defer frc.lock.Unlock()
+	return !frc.deleted[candidate.Hash()], nil
+}
+
+// GetReclaimableSizes ...
+func (frc *fakeRetentionClient) GetReclaimableSizes(ctx context.Context, candidates []*art.Candidate) (map[string]int64, error) {
+	frc.lock.Lock()
+	defer frc.lock.Unlock()
+	frc.sized = append(frc.sized, candidates)
+	sizes := map[string]int64{}
+	for _, c := range candidates {
+		sizes[c.Digest] = 1024
+	}
+	return sizes, nil
+}
+
 // DeleteRepository ...
-func (frc *fakeRetentionClient) DeleteRepository(repo *art.Repository) error {
+func (frc *fakeRetentionClient) DeleteRepository(ctx context.Context, repo *art.Repository) error {
 	panic("implement me")
 }
+
+// deletionsOf drops the results of the retained candidates to focus on the deletions
+func deletionsOf(results []*art.Result, err error) ([]*art.Result, error) {
+	deletions := make([]*art.Result, 0, len(results))
+	for _, r := range results {
+		if r.Action != art.ActionRetain {
+			deletions = append(deletions, r)
+		}
+	}
+	return deletions, err
+}
+
+// racingExistsClient reports the candidates existing only once all the expected callers have
+// checked them, so the concurrent executions race on the deletion
+type racingExistsClient struct {
+	*fakeRetentionClient
+	checked sync.WaitGroup
+}
+
+// Exists ...
+func (r *racingExistsClient) Exists(ctx context.Context, candidate *art.Candidate) (bool, error) {
+	exists, err := r.fakeRetentionClient.Exists(ctx, candidate)
+	r.checked.Done()
+	r.checked.Wait()
+	return exists, err
+}
+
+// slowCheckClient takes the latency to check the deletability of the candidates
+type slowCheckClient struct {
+	*fakeRetentionClient
+	latency time.Duration
+}
+
+// CheckDeletable ...
+func (s *slowCheckClient) CheckDeletable(ctx context.Context, candidate *art.Candidate) error {
+	select {
+	case <-time.After(s.latency):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fakeImmutableMatcher matches the tags with the pattern
+type fakeImmutableMatcher struct {
+	pattern string
+	err     error
+}
+
+// Match ...
+func (fim *fakeImmutableMatcher) Match(c art.Candidate) (bool, error) {
+	if fim.err != nil {
+		return false, fim.err
+	}
+	return path.Match(fim.pattern, c.Tag)
+}
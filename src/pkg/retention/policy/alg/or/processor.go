@@ -15,6 +15,8 @@
 package or
 
 import (
+	"context"
+	"fmt"
 	"sync"
 
 	"github.com/goharbor/harbor/src/common/utils/log"
@@ -30,22 +32,32 @@ type processor struct {
 	// keep evaluator and its related selector if existing
 	// attentions here, the selectors can be empty/nil, that means match all "**"
 	evaluators map[*rule.Evaluator][]art.Selector
+	// the rule of each evaluator and its index in the policy
+	rules map[*rule.Evaluator]*ruleRef
 	// action performer
 	performers map[string]action.Performer
 }
 
+// ruleRef refers to the rule of the policy
+type ruleRef struct {
+	index    int
+	metadata *rule.Metadata
+}
+
 // New processor
 func New(parameters []*alg.Parameter) alg.Processor {
 	p := &processor{
 		evaluators: make(map[*rule.Evaluator][]art.Selector),
+		rules:      make(map[*rule.Evaluator]*ruleRef),
 		performers: make(map[string]action.Performer),
 	}
 
 	if len(parameters) > 0 {
-		for _, param := range parameters {
+		for i, param := range parameters {
 			if param.Evaluator != nil {
 				if len(param.Selectors) > 0 {
 					p.evaluators[&param.Evaluator] = param.Selectors
+					p.rules[&param.Evaluator] = &ruleRef{index: i, metadata: param.Rule}
 				}
 
 				if param.Performer != nil {
@@ -59,7 +71,7 @@ func New(parameters []*alg.Parameter) alg.Processor {
 }
 
 // Process the candidates with the rules
-func (p *processor) Process(artifacts []*art.Candidate) ([]*art.Result, error) {
+func (p *processor) Process(ctx context.Context, artifacts []*art.Candidate) ([]*art.Result, error) {
 	if len(artifacts) == 0 {
 		log.Debug("no artifacts to retention")
 		return make([]*art.Result, 0), nil
@@ -70,12 +82,15 @@ func (p *processor) Process(artifacts []*art.Candidate) ([]*art.Result, error) {
 		err error
 		// collect processed candidates
 		processedCandidates = make(map[string]cHash)
+		// the first rule of the policy retaining each candidate
+		retainedBy = make(map[string]*ruleRef)
 	)
 
 	// for sync
 	type chanItem struct {
 		action    string
 		processed []*art.Candidate
+		rule      *ruleRef
 	}
 
 	resChan := make(chan *chanItem, 1)
@@ -107,6 +122,12 @@ func (p *processor) Process(artifacts []*art.Candidate) ([]*art.Result, error) {
 				for _, rp := range result.processed {
 					// remove duplicated ones
 					listByAction[rp.Hash()] = rp
+					if result.action != action.Retain || result.rule == nil {
+						continue
+					}
+					if r, ok := retainedBy[rp.Hash()]; !ok || result.rule.index < r.index {
+						retainedBy[rp.Hash()] = result.rule
+					}
 				}
 			case e := <-errChan:
 				if err == nil {
@@ -124,7 +145,7 @@ func (p *processor) Process(artifacts []*art.Candidate) ([]*art.Result, error) {
 	for eva, selectors := range p.evaluators {
 		var evaluator = *eva
 
-		go func(evaluator rule.Evaluator, selectors []art.Selector) {
+		go func(evaluator rule.Evaluator, selectors []art.Selector, ref *ruleRef) {
 			var (
 				processed []*art.Candidate
 				err       error
@@ -158,8 +179,9 @@ func (p *processor) Process(artifacts []*art.Candidate) ([]*art.Result, error) {
 			resChan <- &chanItem{
 				action:    evaluator.Action(),
 				processed: processed,
+				rule:      ref,
 			}
-		}(evaluator, selectors)
+		}(evaluator, selectors, p.rules[eva])
 	}
 
 	// waiting for all the rules are evaluated
@@ -181,7 +203,13 @@ func (p *processor) Process(artifacts []*art.Candidate) ([]*art.Result, error) {
 		cl := hash.toList()
 
 		if pf, ok := p.performers[act]; ok {
-			if theRes, err := pf.Perform(cl); err != nil {
+			theRes, err := pf.Perform(ctx, cl)
+			tagRetained(theRes, retainedBy)
+			// the other actions aren't performed once the context is done
+			if err != nil && ctx.Err() != nil {
+				return append(results, theRes...), err
+			}
+			if err != nil {
 				attachedErr = err
 			} else {
 				results = append(results, theRes...)
@@ -203,6 +231,24 @@ func (p *processor) Process(artifacts []*art.Candidate) ([]*art.Result, error) {
 	return results, nil
 }
 
+// tagRetained tags the results of the retained candidates with the rules retaining them
+func tagRetained(results []*art.Result, retainedBy map[string]*ruleRef) {
+	for _, r := range results {
+		if r == nil || r.Target == nil || r.Action != art.ActionRetain {
+			continue
+		}
+		ref, ok := retainedBy[r.Target.Hash()]
+		if !ok || ref.metadata == nil {
+			continue
+		}
+		r.RuleID = ref.metadata.ID
+		r.Reason = &art.Reason{
+			Code:    art.ReasonRetained,
+			Message: fmt.Sprintf("retained by rule %d (%s)", ref.metadata.ID, ref.metadata.Template),
+		}
+	}
+}
+
 type cHash map[string]*art.Candidate
 
 func (ch cHash) toList() []*art.Candidate {
@@ -15,6 +15,7 @@
 package or
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -95,6 +96,7 @@ func (suite *ProcessorTestSuite) TestProcess() {
 			label.New(label.With, "L1,L2"),
 		},
 		Performer: perf,
+		Rule:      &rule.Metadata{ID: 1, Template: lastx.TemplateID},
 	})
 
 	latestKParams := make(map[string]rule.Parameter)
@@ -105,13 +107,24 @@ func (suite *ProcessorTestSuite) TestProcess() {
 			label.New(label.With, "L3"),
 		},
 		Performer: perf,
+		Rule:      &rule.Metadata{ID: 2, Template: latestps.TemplateID},
+	})
+
+	// the candidate retained by both rules is tagged with the former one
+	params = append(params, &alg.Parameter{
+		Evaluator: always.New(make(map[string]rule.Parameter)),
+		Selectors: []art.Selector{
+			label.New(label.With, "L3"),
+		},
+		Performer: perf,
+		Rule:      &rule.Metadata{ID: 3, Template: always.TemplateID},
 	})
 
 	p := New(params)
 
-	results, err := p.Process(suite.all)
+	results, err := p.Process(context.Background(), suite.all)
 	require.NoError(suite.T(), err)
-	assert.Equal(suite.T(), 1, len(results))
+	assert.Equal(suite.T(), 2, len(results))
 	assert.Condition(suite.T(), func() bool {
 		for _, r := range results {
 			if r.Error != nil {
@@ -121,6 +134,20 @@ func (suite *ProcessorTestSuite) TestProcess() {
 
 		return true
 	}, "no errors in the returned result list")
+
+	for _, r := range results {
+		switch r.Target.Tag {
+		case "dev":
+			assert.Equal(suite.T(), art.ActionRetain, r.Action)
+			assert.Equal(suite.T(), 2, r.RuleID)
+			require.NotNil(suite.T(), r.Reason)
+			assert.Equal(suite.T(), art.ReasonRetained, r.Reason.Code)
+			assert.Equal(suite.T(), "retained by rule 2 (latestPushedK)", r.Reason.Message)
+		case "latest":
+			assert.Equal(suite.T(), art.ActionDelete, r.Action)
+			assert.Equal(suite.T(), 0, r.RuleID)
+		}
+	}
 }
 
 // TestProcess2 ...
@@ -140,9 +167,9 @@ func (suite *ProcessorTestSuite) TestProcess2() {
 
 	p := New(params)
 
-	results, err := p.Process(suite.all)
+	results, err := p.Process(context.Background(), suite.all)
 	require.NoError(suite.T(), err)
-	assert.Equal(suite.T(), 1, len(results))
+	assert.Equal(suite.T(), 2, len(results))
 	assert.Condition(suite.T(), func() bool {
 		found := false
 		for _, r := range results {
@@ -160,19 +187,59 @@ func (suite *ProcessorTestSuite) TestProcess2() {
 
 }
 
+// TestProcessCancelled tests nothing is performed once the context is cancelled
+func (suite *ProcessorTestSuite) TestProcessCancelled() {
+	perf := action.NewRetainAction(suite.all, false)
+
+	params := make([]*alg.Parameter, 0)
+	params = append(params, &alg.Parameter{
+		Evaluator: always.New(make(map[string]rule.Parameter)),
+		Selectors: []art.Selector{
+			doublestar.New(doublestar.Matches, "latest"),
+		},
+		Performer: perf,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := New(params).Process(ctx, suite.all)
+	assert.Equal(suite.T(), context.Canceled, err)
+	assert.Empty(suite.T(), results)
+}
+
 type fakeRetentionClient struct{}
 
 // GetCandidates ...
-func (frc *fakeRetentionClient) GetCandidates(repo *art.Repository) ([]*art.Candidate, error) {
+func (frc *fakeRetentionClient) GetCandidates(ctx context.Context, repo *art.Repository) ([]*art.Candidate, error) {
 	return nil, errors.New("not implemented")
 }
 
 // Delete ...
-func (frc *fakeRetentionClient) Delete(candidate *art.Candidate) error {
+func (frc *fakeRetentionClient) Delete(ctx context.Context, candidate *art.Candidate) error {
+	return nil
+}
+
+// DeleteBatch ...
+func (frc *fakeRetentionClient) DeleteBatch(ctx context.Context, candidates []*art.Candidate) []error {
+	return make([]error, len(candidates))
+}
+
+// CheckDeletable ...
+func (frc *fakeRetentionClient) CheckDeletable(ctx context.Context, candidate *art.Candidate) error {
 	return nil
 }
 
 // DeleteRepository ...
-func (frc *fakeRetentionClient) DeleteRepository(repo *art.Repository) error {
+func (frc *fakeRetentionClient) DeleteRepository(ctx context.Context, repo *art.Repository) error {
 	panic("implement me")
 }
+
+// Exists ...
+func (frc *fakeRetentionClient) Exists(ctx context.Context, candidate *art.Candidate) (bool, error) {
+	return true, nil
+}
+
+// GetReclaimableSizes ...
+func (frc *fakeRetentionClient) GetReclaimableSizes(ctx context.Context, candidates []*art.Candidate) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
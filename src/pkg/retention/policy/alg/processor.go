@@ -15,6 +15,8 @@
 package alg
 
 import (
+	"context"
+
 	"github.com/goharbor/harbor/src/pkg/art"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/action"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/rule"
@@ -24,15 +26,17 @@ import (
 // Methods are defined to reflect the standard structure of the policy:
 // list of rules with corresponding selectors plus an action performer.
 type Processor interface {
-	// Process the artifact candidates
+	// Process the artifact candidates, the results collected so far are returned with the error
+	// of the context once the context is cancelled or its deadline is exceeded
 	//
 	//  Arguments:
+	//    ctx context.Context        : the context for the cancellation and deadline
 	//    artifacts []*art.Candidate : process the retention candidates
 	//
 	//  Returns:
 	//    []*art.Result : the processed results
 	//    error         : common error object if any errors occurred
-	Process(artifacts []*art.Candidate) ([]*art.Result, error)
+	Process(ctx context.Context, artifacts []*art.Candidate) ([]*art.Result, error)
 }
 
 // Parameter for constructing a processor
@@ -46,6 +50,9 @@ type Parameter struct {
 
 	// Performer for the rule evaluator
 	Performer action.Performer
+
+	// Metadata of the rule, the results of the retained candidates are tagged with it
+	Rule *rule.Metadata
 }
 
 // Factory for creating processor
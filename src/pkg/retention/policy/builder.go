@@ -17,6 +17,7 @@ package policy
 import (
 	"fmt"
 
+	"github.com/goharbor/harbor/src/pkg/retention/policy/action"
 	index4 "github.com/goharbor/harbor/src/pkg/retention/policy/action/index"
 
 	index3 "github.com/goharbor/harbor/src/pkg/retention/policy/alg/index"
@@ -26,8 +27,10 @@ import (
 	"github.com/goharbor/harbor/src/pkg/retention/policy/rule/index"
 
 	"github.com/goharbor/harbor/src/pkg/art"
+	"github.com/goharbor/harbor/src/pkg/immutabletag/match"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/alg"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/lwp"
+	"github.com/goharbor/harbor/src/pkg/retention/policy/rule"
 	"github.com/pkg/errors"
 )
 
@@ -52,9 +55,32 @@ func NewBuilder(all []*art.Candidate) Builder {
 	}
 }
 
+// NewBuilderWithParams news a basic builder whose action performers are created with the params,
+// e.g. the count of the workers deleting the candidates and the hook checking whether the job is stopped
+func NewBuilderWithParams(params *action.Params) Builder {
+	return &basicBuilder{
+		allCandidates:    params.All,
+		workers:          params.Workers,
+		batchSize:        params.BatchSize,
+		isStopped:        params.IsStopped,
+		immutableMatcher: params.ImmutableMatcher,
+		onDeleted:        params.OnDeleted,
+		validate:         params.Validate,
+	}
+}
+
 // basicBuilder is default implementation of Builder interface
 type basicBuilder struct {
 	allCandidates []*art.Candidate
+	workers       int
+	batchSize     int
+	isStopped     func() bool
+	// the matcher of the immutable tag rules, the candidates matched are never deleted
+	immutableMatcher match.ImmutableTagMatcher
+	// the hook invoked once the candidate is deleted
+	onDeleted func(c *art.Candidate)
+	// whether to check the deletability of the candidates in dry run
+	validate bool
 }
 
 // Build policy processor from the raw policy
@@ -70,8 +96,20 @@ func (bb *basicBuilder) Build(policy *lwp.Metadata, isDryRun bool) (alg.Processo
 		if err != nil {
 			return nil, err
 		}
+		// the evaluators of the templates perform the retain action by default
+		if len(r.Action) > 0 && r.Action != evaluator.Action() {
+			evaluator = &actionEvaluator{Evaluator: evaluator, action: r.Action}
+		}
 
-		perf, err := index4.Get(r.Action, bb.allCandidates, isDryRun)
+		perf, err := index4.Get(r.Action, &action.Params{
+			All:              bb.allCandidates,
+			Workers:          bb.workers,
+			BatchSize:        bb.batchSize,
+			IsStopped:        bb.isStopped,
+			ImmutableMatcher: bb.immutableMatcher,
+			OnDeleted:        bb.onDeleted,
+			Validate:         bb.validate,
+		}, isDryRun)
 		if err != nil {
 			return nil, errors.Wrap(err, "get action performer by metadata")
 		}
@@ -90,6 +128,7 @@ func (bb *basicBuilder) Build(policy *lwp.Metadata, isDryRun bool) (alg.Processo
 			Evaluator: evaluator,
 			Selectors: sl,
 			Performer: perf,
+			Rule:      r,
 		})
 	}
 
@@ -100,3 +139,14 @@ func (bb *basicBuilder) Build(policy *lwp.Metadata, isDryRun bool) (alg.Processo
 
 	return p, nil
 }
+
+// actionEvaluator overrides the action of the evaluator with the one specified by the rule
+type actionEvaluator struct {
+	rule.Evaluator
+	action string
+}
+
+// Action returns the action specified by the rule
+func (ae *actionEvaluator) Action() string {
+	return ae.action
+}
@@ -15,6 +15,7 @@
 package policy
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -34,6 +35,8 @@ import (
 
 	"github.com/goharbor/harbor/src/pkg/art/selectors/doublestar"
 
+	"github.com/goharbor/harbor/src/pkg/retention/policy/rule/always"
+
 	"github.com/goharbor/harbor/src/pkg/retention/policy/rule/latestps"
 
 	"github.com/goharbor/harbor/src/pkg/retention/policy/action"
@@ -100,6 +103,7 @@ func (suite *TestBuilderSuite) SetupSuite() {
 	}, doublestar.New)
 	index.Register(label.Kind, []string{label.With, label.Without}, label.New)
 	index3.Register(action.Retain, action.NewRetainAction)
+	index3.Register(action.Delete, action.NewDeleteAction)
 
 	suite.oldClient = dep.DefaultClient
 	dep.DefaultClient = &fakeRetentionClient{}
@@ -112,7 +116,7 @@ func (suite *TestBuilderSuite) TearDownSuite() {
 
 // TestBuild tests the Build function
 func (suite *TestBuilderSuite) TestBuild() {
-	b := &basicBuilder{suite.all}
+	b := &basicBuilder{allCandidates: suite.all}
 
 	params := make(rule.Parameters)
 	params[latestps.ParameterK] = 10
@@ -147,11 +151,14 @@ func (suite *TestBuilderSuite) TestBuild() {
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), p)
 
-	results, err := p.Process(suite.all)
+	results, err := p.Process(context.Background(), suite.all)
 	require.NoError(suite.T(), err)
-	assert.Equal(suite.T(), 1, len(results))
+	require.Equal(suite.T(), 2, len(results))
+	// the retained one is tagged with the rule
+	assert.Equal(suite.T(), "latest", results[0].Target.Tag)
+	assert.Equal(suite.T(), 1, results[0].RuleID)
 	assert.Condition(suite.T(), func() (success bool) {
-		art := results[0]
+		art := results[1]
 		success = art.Error == nil &&
 			art.Target != nil &&
 			art.Target.Repository == "harbor" &&
@@ -161,19 +168,147 @@ func (suite *TestBuilderSuite) TestBuild() {
 	})
 }
 
+// TestBuildDeleteAction tests the rule with the delete action deletes the matched candidates only
+func (suite *TestBuilderSuite) TestBuildDeleteAction() {
+	b := &basicBuilder{allCandidates: suite.all}
+
+	scopeSelectors := make(map[string][]*rule.Selector, 1)
+	scopeSelectors["repository"] = []*rule.Selector{{
+		Kind:       doublestar.Kind,
+		Decoration: doublestar.RepoMatches,
+		Pattern:    "**",
+	}}
+
+	lm := &lwp.Metadata{
+		Algorithm: AlgorithmOR,
+		Rules: []*rule.Metadata{{
+			ID:             1,
+			Priority:       999,
+			Action:         action.Delete,
+			Template:       always.TemplateID,
+			Parameters:     rule.Parameters{},
+			ScopeSelectors: scopeSelectors,
+			TagSelectors: []*rule.Selector{
+				{
+					Kind:       doublestar.Kind,
+					Decoration: doublestar.Matches,
+					Pattern:    "dev*",
+				},
+			},
+		}},
+	}
+
+	p, err := b.Build(lm, false)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), p)
+
+	results, err := p.Process(context.Background(), suite.all)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(results))
+	assert.NoError(suite.T(), results[0].Error)
+	require.NotNil(suite.T(), results[0].Target)
+	assert.Equal(suite.T(), "dev", results[0].Target.Tag)
+
+	// the unknown action is rejected
+	lm.Rules[0].Action = "archive"
+	_, err = b.Build(lm, false)
+	assert.Error(suite.T(), err)
+}
+
+// TestBuildRegisteredAction tests the performer is resolved by the action name through the index
+func (suite *TestBuilderSuite) TestBuildRegisteredAction() {
+	var invoked *fakePerformer
+	index3.Register("fakeAction", func(params interface{}, isDryRun bool) action.Performer {
+		invoked = &fakePerformer{params: params, isDryRun: isDryRun}
+		return invoked
+	})
+
+	b := NewBuilderWithParams(&action.Params{All: suite.all, Workers: 2})
+	lm := &lwp.Metadata{
+		Algorithm: AlgorithmOR,
+		Rules: []*rule.Metadata{{
+			ID:         1,
+			Priority:   999,
+			Action:     "fakeAction",
+			Template:   always.TemplateID,
+			Parameters: rule.Parameters{},
+			TagSelectors: []*rule.Selector{
+				{
+					Kind:       doublestar.Kind,
+					Decoration: doublestar.Matches,
+					Pattern:    "dev*",
+				},
+			},
+		}},
+	}
+
+	p, err := b.Build(lm, true)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), invoked)
+	assert.True(suite.T(), invoked.isDryRun)
+	params, ok := invoked.params.(*action.Params)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), 2, params.Workers)
+
+	results, err := p.Process(context.Background(), suite.all)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(results))
+	assert.Equal(suite.T(), "dev", results[0].Target.Tag)
+	require.Equal(suite.T(), 1, len(invoked.performed))
+	assert.Equal(suite.T(), "dev", invoked.performed[0].Tag)
+}
+
+// fakePerformer records the candidates performed
+type fakePerformer struct {
+	params    interface{}
+	isDryRun  bool
+	performed []*art.Candidate
+}
+
+// Perform ...
+func (f *fakePerformer) Perform(ctx context.Context, candidates []*art.Candidate) ([]*art.Result, error) {
+	results := make([]*art.Result, 0, len(candidates))
+	for _, c := range candidates {
+		f.performed = append(f.performed, c)
+		results = append(results, &art.Result{Target: c})
+	}
+
+	return results, nil
+}
+
 type fakeRetentionClient struct{}
 
-func (frc *fakeRetentionClient) DeleteRepository(repo *art.Repository) error {
+func (frc *fakeRetentionClient) DeleteRepository(ctx context.Context, repo *art.Repository) error {
 	panic("implement me")
 }
 
+// Exists ...
+func (frc *fakeRetentionClient) Exists(ctx context.Context, candidate *art.Candidate) (bool, error) {
+	return true, nil
+}
+
+// GetReclaimableSizes ...
+func (frc *fakeRetentionClient) GetReclaimableSizes(ctx context.Context, candidates []*art.Candidate) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
+
 // GetCandidates ...
-func (frc *fakeRetentionClient) GetCandidates(repo *art.Repository) ([]*art.Candidate, error) {
+func (frc *fakeRetentionClient) GetCandidates(ctx context.Context, repo *art.Repository) ([]*art.Candidate, error) {
 	return nil, errors.New("not implemented")
 }
 
 // Delete ...
-func (frc *fakeRetentionClient) Delete(candidate *art.Candidate) error {
+func (frc *fakeRetentionClient) Delete(ctx context.Context, candidate *art.Candidate) error {
+	return nil
+}
+
+// DeleteBatch ...
+func (frc *fakeRetentionClient) DeleteBatch(ctx context.Context, candidates []*art.Candidate) []error {
+	return make([]error, len(candidates))
+}
+
+// CheckDeletable ...
+func (frc *fakeRetentionClient) CheckDeletable(ctx context.Context, candidate *art.Candidate) error {
 	return nil
 }
 
@@ -16,6 +16,7 @@ package policy
 
 import (
 	"github.com/astaxie/beego/validation"
+	index4 "github.com/goharbor/harbor/src/pkg/retention/policy/action/index"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/rule"
 	"github.com/goharbor/harbor/src/pkg/retention/policy/rule/index"
 )
@@ -80,6 +81,11 @@ func (m *Metadata) Valid(v *validation.Validation) {
 				_ = v.SetError("Parameters", err.Error())
 				return
 			}
+			// the unknown action is rejected when saving the policy rather than executing it
+			if err := index4.Valid(r.Action); err != nil {
+				_ = v.SetError("Action", err.Error())
+				return
+			}
 			if ok, _ := v.Valid(&r); !ok {
 				return
 			}
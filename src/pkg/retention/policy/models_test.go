@@ -181,3 +181,60 @@ func TestParamValid(t *testing.T) {
 	require.True(t, v.HasErrors())
 	require.EqualValues(t, "Parameters", v.Errors[0].Field)
 }
+
+func TestActionValid(t *testing.T) {
+	p := &Metadata{
+		Algorithm: "or",
+		Rules: []rule.Metadata{
+			{
+				ID:       1,
+				Priority: 1,
+				Action:   "archive",
+				Template: "latestPushedK",
+				Parameters: rule.Parameters{
+					"latestPushedK": 10,
+				},
+				TagSelectors: []*rule.Selector{
+					{
+						Kind:       "doublestar",
+						Decoration: "matches",
+						Pattern:    "release-[\\d\\.]+",
+					},
+				},
+				ScopeSelectors: map[string][]*rule.Selector{
+					"repository": {
+						{
+							Kind:       "doublestar",
+							Decoration: "matches",
+							Pattern:    ".+",
+						},
+					},
+				},
+			},
+		},
+		Trigger: &Trigger{
+			Kind: "Schedule",
+			Settings: map[string]interface{}{
+				"cron": "* 22 11 * * *",
+			},
+		},
+		Scope: &Scope{
+			Level:     "project",
+			Reference: 1,
+		},
+	}
+	v := &validation.Validation{}
+	ok, err := v.Valid(p)
+	require.Nil(t, err)
+	require.False(t, ok)
+	require.True(t, v.HasErrors())
+	require.EqualValues(t, "Action", v.Errors[0].Field)
+
+	for _, action := range []string{"retain", "delete"} {
+		p.Rules[0].Action = action
+		v = &validation.Validation{}
+		ok, err = v.Valid(p)
+		require.Nil(t, err)
+		require.True(t, ok, "action %s", action)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/pkg/art"
+	"github.com/goharbor/harbor/src/pkg/art/selectors/label"
+	"github.com/goharbor/harbor/src/pkg/project"
+	"github.com/goharbor/harbor/src/pkg/retention/policy"
+	"github.com/goharbor/harbor/src/pkg/retention/policy/lwp"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultPreviewSampleSize is the default max number of the candidates sampled per repository
+	DefaultPreviewSampleSize = 1000
+	// the max time spent on a preview, the repositories not covered in time are skipped
+	previewTimeout = 10 * time.Second
+)
+
+// candidatesLoader loads at most limit candidates of the repository, the labels of the candidates
+// are only loaded when withLabels is true
+type candidatesLoader func(repo *art.Repository, limit int, withLabels bool) ([]*art.Candidate, error)
+
+// PreviewRetention evaluates the rules of the policy against the sampled candidates in memory,
+// nothing is deleted from the registry
+func (r *DefaultAPIController) PreviewRetention(policyID int64, sampleSize int) (*Preview, error) {
+	if sampleSize <= 0 || sampleSize > DefaultPreviewSampleSize {
+		sampleSize = DefaultPreviewSampleSize
+	}
+
+	p, err := r.manager.GetPolicy(policyID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("retention policy %d not found", policyID)
+	}
+
+	preview := &Preview{
+		SampleDeletes: []*PreviewCandidate{},
+	}
+	if len(p.Rules) == 0 {
+		return preview, nil
+	}
+
+	repositoryRules, err := getRepositoryRules(p, r.projectManager, r.repositoryMgr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// evaluate the repositories in a stable order
+	repositories := make([]art.Repository, 0, len(repositoryRules))
+	for repo := range repositoryRules {
+		repositories = append(repositories, repo)
+	}
+	sort.Slice(repositories, func(i, j int) bool {
+		if repositories[i].Namespace != repositories[j].Namespace {
+			return repositories[i].Namespace < repositories[j].Namespace
+		}
+		return repositories[i].Name < repositories[j].Name
+	})
+
+	deadline := time.Now().Add(previewTimeout)
+	for i := range repositories {
+		if time.Now().After(deadline) {
+			preview.Truncated = true
+			break
+		}
+
+		repo := &repositories[i]
+		meta := repositoryRules[*repo]
+		candidates, err := r.loadCandidates(repo, sampleSize, withLabelSelectors(meta))
+		if err != nil {
+			return nil, err
+		}
+
+		deletes, err := evaluate(meta, candidates)
+		if err != nil {
+			return nil, err
+		}
+
+		preview.WouldDelete += len(deletes)
+		preview.WouldRetain += len(candidates) - len(deletes)
+		for _, res := range deletes {
+			if len(preview.SampleDeletes) >= sampleSize {
+				break
+			}
+			c := res.Target
+			preview.SampleDeletes = append(preview.SampleDeletes, &PreviewCandidate{
+				Repository: fmt.Sprintf("%s/%s", c.Namespace, c.Repository),
+				Tag:        c.Tag,
+				Digest:     c.Digest,
+				PushedAt:   time.Unix(c.PushedTime, 0),
+				Action:     res.Action,
+				Reason:     res.Reason,
+			})
+		}
+	}
+
+	return preview, nil
+}
+
+// evaluate runs the rules against the candidates as a dry run and returns the results of the ones would be deleted
+func evaluate(meta *lwp.Metadata, candidates []*art.Candidate) ([]*art.Result, error) {
+	processor, err := policy.NewBuilder(candidates).Build(meta, true)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := processor.Process(context.Background(), candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	var deletes []*art.Result
+	for _, res := range results {
+		if res.Target != nil && res.Error == nil && res.Action == art.ActionDelete {
+			deletes = append(deletes, res)
+		}
+	}
+
+	return deletes, nil
+}
+
+// withLabelSelectors checks whether any rule selects the candidates by labels
+func withLabelSelectors(meta *lwp.Metadata) bool {
+	for _, rule := range meta.Rules {
+		for _, s := range rule.TagSelectors {
+			if s.Kind == label.Kind {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// newDBCandidatesLoader returns the loader which loads the latest pushed candidates of the
+// repository from database
+func newDBCandidatesLoader(projectMgr project.Manager) candidatesLoader {
+	return func(repo *art.Repository, limit int, withLabels bool) ([]*art.Candidate, error) {
+		if repo.Kind != art.Image {
+			return nil, nil
+		}
+
+		pro, err := projectMgr.Get(repo.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if pro == nil {
+			return nil, errors.Errorf("project %s not found", repo.Namespace)
+		}
+		pid := pro.ProjectID
+
+		repoName := fmt.Sprintf("%s/%s", repo.Namespace, repo.Name)
+		afs, err := dao.GetLatestPushedArtifacts(pid, repoName, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := make([]*art.Candidate, 0, len(afs))
+		for _, af := range afs {
+			labels := make([]string, 0)
+			if withLabels {
+				ls, err := dao.GetLabelsOfResource(common.ResourceTypeImage, fmt.Sprintf("%s:%s", repoName, af.Tag))
+				if err != nil {
+					return nil, err
+				}
+				for _, l := range ls {
+					labels = append(labels, l.Name)
+				}
+			}
+			var pulledTime int64
+			if !af.PullTime.IsZero() {
+				pulledTime = af.PullTime.Unix()
+			}
+			candidates = append(candidates, &art.Candidate{
+				NamespaceID:  pid,
+				Namespace:    repo.Namespace,
+				Repository:   repo.Name,
+				Kind:         art.Image,
+				Tag:          af.Tag,
+				Digest:       af.Digest,
+				Labels:       labels,
+				PushedTime:   af.PushTime.Unix(),
+				PulledTime:   pulledTime,
+				CreationTime: af.CreationTime.Unix(),
+			})
+		}
+
+		return candidates, nil
+	}
+}
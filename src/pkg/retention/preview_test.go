@@ -0,0 +1,162 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/pkg/art"
+	_ "github.com/goharbor/harbor/src/pkg/art/selectors/doublestar"
+	"github.com/goharbor/harbor/src/pkg/retention/policy"
+	"github.com/goharbor/harbor/src/pkg/retention/policy/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type previewRetentionManager struct {
+	fakeRetentionManager
+	policy *policy.Metadata
+}
+
+func (p *previewRetentionManager) GetPolicy(ID int64) (*policy.Metadata, error) {
+	return p.policy, nil
+}
+
+func TestPreviewRetention(t *testing.T) {
+	retentionMgr := &previewRetentionManager{
+		policy: &policy.Metadata{
+			ID:        1,
+			Algorithm: "or",
+			Rules: []rule.Metadata{
+				{
+					ID:       1,
+					Priority: 1,
+					Template: "latestPushedK",
+					Action:   "retain",
+					Parameters: rule.Parameters{
+						"latestPushedK": 3,
+					},
+					TagSelectors: []*rule.Selector{
+						{
+							Kind:       "doublestar",
+							Decoration: "matches",
+							Pattern:    "**",
+						},
+					},
+					ScopeSelectors: map[string][]*rule.Selector{
+						"repository": {
+							{
+								Kind:       "doublestar",
+								Decoration: "repoMatches",
+								Pattern:    "**",
+							},
+						},
+					},
+				},
+			},
+			Scope: &policy.Scope{
+				Level:     "project",
+				Reference: 1,
+			},
+		},
+	}
+	repositoryMgr := &fakeRepositoryManager{
+		imageRepositories: []*models.RepoRecord{
+			{
+				Name: "library/hello-world",
+			},
+			{
+				Name: "library/alpine",
+			},
+		},
+	}
+	c := NewAPIController(retentionMgr, &fakeProjectManager{}, repositoryMgr,
+		&fakeRetentionScheduler{}, &fakeLauncher{}).(*DefaultAPIController)
+
+	// every repository has 2000 candidates
+	var limits []int
+	c.loadCandidates = func(repo *art.Repository, limit int, withLabels bool) ([]*art.Candidate, error) {
+		limits = append(limits, limit)
+		var candidates []*art.Candidate
+		for i := 0; i < 2000 && i < limit; i++ {
+			candidates = append(candidates, &art.Candidate{
+				Namespace:  repo.Namespace,
+				Repository: repo.Name,
+				Kind:       art.Image,
+				Tag:        fmt.Sprintf("v%d", i),
+				Digest:     fmt.Sprintf("sha256:%d", i),
+				PushedTime: int64(i),
+			})
+		}
+		return candidates, nil
+	}
+
+	preview, err := c.PreviewRetention(1, 10)
+	require.Nil(t, err)
+	assert.Equal(t, []int{10, 10}, limits)
+	assert.Equal(t, 6, preview.WouldRetain)
+	assert.Equal(t, 14, preview.WouldDelete)
+	assert.Equal(t, 10, len(preview.SampleDeletes))
+	assert.False(t, preview.Truncated)
+	for _, sample := range preview.SampleDeletes {
+		assert.Equal(t, art.ActionDelete, sample.Action)
+		require.NotNil(t, sample.Reason)
+		assert.Equal(t, art.ReasonNotRetained, sample.Reason.Code)
+	}
+
+	// the sample size is capped
+	limits = nil
+	preview, err = c.PreviewRetention(1, 5000)
+	require.Nil(t, err)
+	assert.Equal(t, []int{DefaultPreviewSampleSize, DefaultPreviewSampleSize}, limits)
+	assert.Equal(t, 6, preview.WouldRetain)
+	assert.Equal(t, 2*DefaultPreviewSampleSize-6, preview.WouldDelete)
+	assert.Equal(t, DefaultPreviewSampleSize, len(preview.SampleDeletes))
+}
+
+// TestPreviewCandidateJSON tests the consumers of the legacy preview still parse the response
+func TestPreviewCandidateJSON(t *testing.T) {
+	data, err := json.Marshal(&PreviewCandidate{
+		Repository: "library/hello-world",
+		Tag:        "v1",
+		Digest:     "sha256:1",
+		PushedAt:   time.Unix(1, 0).UTC(),
+		Action:     art.ActionDelete,
+		Reason:     &art.Reason{Code: art.ReasonNotRetained, Message: "not retained by any rule"},
+	})
+	require.Nil(t, err)
+
+	legacy := &struct {
+		Repository string    `json:"repository"`
+		Tag        string    `json:"tag"`
+		Digest     string    `json:"digest"`
+		PushedAt   time.Time `json:"pushed_at"`
+	}{}
+	require.Nil(t, json.Unmarshal(data, legacy))
+	assert.Equal(t, "library/hello-world", legacy.Repository)
+	assert.Equal(t, "v1", legacy.Tag)
+	assert.Equal(t, "sha256:1", legacy.Digest)
+	assert.Equal(t, time.Unix(1, 0).UTC(), legacy.PushedAt)
+
+	// the new fields are omitted if they're empty
+	data, err = json.Marshal(&PreviewCandidate{Repository: "library/hello-world"})
+	require.Nil(t, err)
+	assert.NotContains(t, string(data), "action")
+	assert.NotContains(t, string(data), "reason")
+}
@@ -0,0 +1,88 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/pkg/art"
+)
+
+// deletionPublisher publishes the artifacts deleted by the job through the check-ins asynchronously,
+// the deletions are never blocked or failed by the publishing
+type deletionPublisher struct {
+	ctx   job.Context
+	queue chan *DeletedArtifact
+	done  chan struct{}
+	once  sync.Once
+}
+
+// newDeletionPublisher creates the publisher which can buffer the deletions of the size
+// and starts publishing them
+func newDeletionPublisher(ctx job.Context, size int) *deletionPublisher {
+	p := &deletionPublisher{
+		ctx:   ctx,
+		queue: make(chan *DeletedArtifact, size),
+		done:  make(chan struct{}),
+	}
+	go p.run()
+
+	return p
+}
+
+// publish queues the deleted candidate, it's dropped if the queue is full
+func (p *deletionPublisher) publish(c *art.Candidate) {
+	deleted := &DeletedArtifact{
+		ProjectID:  c.NamespaceID,
+		Namespace:  c.Namespace,
+		Repository: c.Repository,
+		Tag:        c.Tag,
+		Digest:     c.Digest,
+		DeletedAt:  time.Now().Unix(),
+	}
+	select {
+	case p.queue <- deleted:
+	default:
+		p.ctx.GetLogger().Warningf("Drop the deletion event of artifact %s as the queue is full", arn(c))
+	}
+}
+
+// close waits until all the queued deletions are published, it can be called more than once
+func (p *deletionPublisher) close() {
+	p.once.Do(func() {
+		close(p.queue)
+	})
+	<-p.done
+}
+
+func (p *deletionPublisher) run() {
+	defer close(p.done)
+
+	for deleted := range p.queue {
+		data, err := json.Marshal(&DeletionCheckIn{Deleted: deleted})
+		if err != nil {
+			p.ctx.GetLogger().Errorf("Failed to marshal the deletion event of artifact %s/%s:%s: %v",
+				deleted.Namespace, deleted.Repository, deleted.Tag, err)
+			continue
+		}
+		if err := p.ctx.Checkin(string(data)); err != nil {
+			p.ctx.GetLogger().Errorf("Failed to publish the deletion event of artifact %s/%s:%s: %v",
+				deleted.Namespace, deleted.Repository, deleted.Tag, err)
+		}
+	}
+}
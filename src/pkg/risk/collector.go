@@ -0,0 +1,146 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package risk
+
+import (
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/notary"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/middlewares/util"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/pkg/scan/report"
+	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/goharbor/harbor/src/pkg/scan/vuln"
+	"github.com/pkg/errors"
+)
+
+// collect collects the factors of the project from the artifacts, the scan reports,
+// the signatures in notary and the metadata of the project
+func collect(projectID int64) (*Factors, error) {
+	project, err := config.GlobalProjectMgr.Get(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, errors.Errorf("project %d not found", projectID)
+	}
+	artifacts, err := dao.ListArtifacts(&models.ArtifactQuery{PID: projectID})
+	if err != nil {
+		return nil, err
+	}
+
+	factors := &Factors{
+		TotalImages:           len(artifacts),
+		ScanCoveragePercent:   100,
+		PolicyComplianceScore: policyCompliance(project),
+	}
+	if len(artifacts) == 0 {
+		return factors, nil
+	}
+
+	scanned, criticals, unsigned := 0, 0, 0
+	// the signed digests of the tags grouped by repository
+	signatures := map[string]map[string]string{}
+	for _, af := range artifacts {
+		ok, critical, err := criticalCVEs(af.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			scanned++
+			criticals += critical
+		}
+
+		signed, ok := signatures[af.Repo]
+		if !ok {
+			if signed, err = signedDigests(af.Repo); err != nil {
+				return nil, err
+			}
+			signatures[af.Repo] = signed
+		}
+		if d, ok := signed[af.Tag]; !ok || d != af.Digest {
+			unsigned++
+		}
+	}
+
+	total := float64(len(artifacts))
+	factors.ScanCoveragePercent = float64(scanned) / total * 100
+	factors.CriticalCVEDensity = float64(criticals) / total
+	factors.UnsignedImagesPercent = float64(unsigned) / total * 100
+	return factors, nil
+}
+
+// criticalCVEs returns whether the artifact has been scanned successfully and the
+// max number of the critical CVEs found by the scanners
+func criticalCVEs(digest string) (bool, int, error) {
+	reports, err := report.NewManager().GetBy(digest, "", []string{v1.MimeTypeNativeReport})
+	if err != nil {
+		return false, 0, err
+	}
+	scanned, critical := false, 0
+	for _, r := range reports {
+		if r.Status != job.SuccessStatus.String() {
+			continue
+		}
+		sum, err := report.GenerateSummary(r)
+		if err != nil {
+			return false, 0, err
+		}
+		scanned = true
+		nativeSum, ok := sum.(*vuln.NativeReportSummary)
+		if !ok || nativeSum.Summary == nil {
+			continue
+		}
+		if n := nativeSum.Summary.Summary[vuln.Critical]; n > critical {
+			critical = n
+		}
+	}
+	return scanned, critical, nil
+}
+
+// signedDigests returns the digests of the signed tags of the repository,
+// nothing is signed if notary isn't deployed
+func signedDigests(repository string) (map[string]string, error) {
+	signed := map[string]string{}
+	if !config.WithNotary() {
+		return signed, nil
+	}
+	targets, err := notary.GetInternalTargets(config.InternalNotaryEndpoint(), util.TokenUsername, repository)
+	if err != nil {
+		return nil, err
+	}
+	for _, tgt := range targets {
+		digest, err := notary.DigestFromTarget(tgt)
+		if err != nil {
+			return nil, err
+		}
+		signed[tgt.Tag] = digest
+	}
+	return signed, nil
+}
+
+// policyCompliance returns the percentage of the security policies enabled in the project:
+// content trust, preventing vulnerable images from running and scanning on push
+func policyCompliance(project *models.Project) float64 {
+	policies := []bool{project.ContentTrustEnabled(), project.VulPrevented(), project.AutoScan()}
+	enabled := 0
+	for _, p := range policies {
+		if p {
+			enabled++
+		}
+	}
+	return float64(enabled) / float64(len(policies)) * 100
+}
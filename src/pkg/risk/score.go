@@ -0,0 +1,138 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package risk
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	beego_cache "github.com/astaxie/beego/cache"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/pkg/errors"
+)
+
+// the factors combined into the risk score, they are also the keys of the weights
+const (
+	FactorScanCoverage     = "scan_coverage_percent"
+	FactorCriticalDensity  = "critical_cve_density"
+	FactorUnsignedImages   = "unsigned_images_percent"
+	FactorPolicyCompliance = "policy_compliance_score"
+)
+
+// cacheTTL is how long the risk score of a project is cached
+const cacheTTL = 15 * time.Minute
+
+var (
+	scoreCache = beego_cache.NewMemoryCache()
+	// the func to collect the factors of the project, replaced in tests
+	collectFactors = collect
+	// the func to get the configured weights, replaced in tests
+	getWeights = config.RiskScoreWeights
+)
+
+// Factors are the inputs of the risk score
+type Factors struct {
+	// the number of the images in the project
+	TotalImages int `json:"total_images"`
+	// the percentage of the images which have been scanned successfully
+	ScanCoveragePercent float64 `json:"scan_coverage_percent"`
+	// the number of the critical CVEs per image
+	CriticalCVEDensity float64 `json:"critical_cve_density"`
+	// the percentage of the images which are not signed
+	UnsignedImagesPercent float64 `json:"unsigned_images_percent"`
+	// the percentage of the security policies enabled in the project
+	PolicyComplianceScore float64 `json:"policy_compliance_score"`
+}
+
+// Score is the composite security score of a project, 0 means no risk and 100 means the highest risk
+type Score struct {
+	ProjectID int64   `json:"project_id"`
+	Score     float64 `json:"score"`
+	*Factors
+	Weights    map[string]float64 `json:"weights"`
+	ComputedAt time.Time          `json:"computed_at"`
+}
+
+// ComputeScore computes the risk score of the project, the score is cached for 15 minutes
+func ComputeScore(projectID int64) (*Score, error) {
+	key := fmt.Sprintf("%d", projectID)
+	if score, ok := scoreCache.Get(key).(*Score); ok {
+		return score, nil
+	}
+
+	weights, err := getWeights()
+	if err != nil {
+		return nil, errors.Wrap(err, "compute risk score: get weights")
+	}
+	factors, err := collectFactors(projectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "compute risk score: collect factors")
+	}
+	value, err := Compute(factors, weights)
+	if err != nil {
+		return nil, errors.Wrap(err, "compute risk score")
+	}
+
+	score := &Score{
+		ProjectID:  projectID,
+		Score:      value,
+		Factors:    factors,
+		Weights:    weights,
+		ComputedAt: time.Now(),
+	}
+	if err = scoreCache.Put(key, score, cacheTTL); err != nil {
+		log.Warningf("failed to cache the risk score of project %d: %v", projectID, err)
+	}
+	return score, nil
+}
+
+// Compute combines the factors into a score between 0 and 100 by the weighted average of their risks:
+//
+//	scan coverage:     1 - scan_coverage_percent/100
+//	critical density:  min(critical_cve_density, 1)
+//	unsigned images:   unsigned_images_percent/100
+//	policy compliance: 1 - policy_compliance_score/100
+//
+// The score is rounded to 2 decimal places.
+func Compute(factors *Factors, weights map[string]float64) (float64, error) {
+	if factors == nil {
+		return 0, errors.New("nil factors")
+	}
+	risks := map[string]float64{
+		FactorScanCoverage:     1 - factors.ScanCoveragePercent/100,
+		FactorCriticalDensity:  math.Min(factors.CriticalCVEDensity, 1),
+		FactorUnsignedImages:   factors.UnsignedImagesPercent / 100,
+		FactorPolicyCompliance: 1 - factors.PolicyComplianceScore/100,
+	}
+
+	var sum, total float64
+	for name, weight := range weights {
+		risk, ok := risks[name]
+		if !ok {
+			return 0, errors.Errorf("unknown factor %s", name)
+		}
+		if weight < 0 {
+			return 0, errors.Errorf("the weight of factor %s is negative", name)
+		}
+		sum += weight * risk
+		total += weight
+	}
+	if total == 0 {
+		return 0, errors.New("the sum of the weights is zero")
+	}
+	return math.Round(sum/total*100*100) / 100, nil
+}
@@ -0,0 +1,104 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package risk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompute(t *testing.T) {
+	factors := &Factors{
+		TotalImages:           10,
+		ScanCoveragePercent:   80,
+		CriticalCVEDensity:    0.5,
+		UnsignedImagesPercent: 40,
+		PolicyComplianceScore: 50,
+	}
+	weights := map[string]float64{
+		FactorScanCoverage:     40,
+		FactorCriticalDensity:  30,
+		FactorUnsignedImages:   20,
+		FactorPolicyCompliance: 10,
+	}
+	// (40*0.2 + 30*0.5 + 20*0.4 + 10*0.5) / 100 * 100 = 36
+	score, err := Compute(factors, weights)
+	require.Nil(t, err)
+	assert.Equal(t, 36.0, score)
+
+	// the critical CVE density is capped at 1
+	factors.CriticalCVEDensity = 3
+	score, err = Compute(factors, weights)
+	require.Nil(t, err)
+	assert.Equal(t, 51.0, score)
+
+	// rounded to 2 decimal places
+	score, err = Compute(&Factors{PolicyComplianceScore: 100 * 2 / 3.0}, map[string]float64{
+		FactorScanCoverage:     1,
+		FactorPolicyCompliance: 1,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 66.67, score)
+
+	// invalid weights
+	_, err = Compute(factors, map[string]float64{"unknown": 1})
+	assert.NotNil(t, err)
+	_, err = Compute(factors, map[string]float64{FactorScanCoverage: -1, FactorUnsignedImages: 2})
+	assert.NotNil(t, err)
+	_, err = Compute(factors, map[string]float64{FactorScanCoverage: 0})
+	assert.NotNil(t, err)
+}
+
+func TestComputeScore(t *testing.T) {
+	originCollect, originWeights := collectFactors, getWeights
+	defer func() {
+		collectFactors, getWeights = originCollect, originWeights
+	}()
+
+	calls := 0
+	collectFactors = func(projectID int64) (*Factors, error) {
+		calls++
+		return &Factors{
+			TotalImages:           4,
+			ScanCoveragePercent:   50,
+			CriticalCVEDensity:    0.25,
+			UnsignedImagesPercent: 100,
+			PolicyComplianceScore: 100,
+		}, nil
+	}
+	getWeights = func() (map[string]float64, error) {
+		return map[string]float64{
+			FactorScanCoverage:     25,
+			FactorCriticalDensity:  25,
+			FactorUnsignedImages:   25,
+			FactorPolicyCompliance: 25,
+		}, nil
+	}
+
+	// (25*0.5 + 25*0.25 + 25*1 + 25*0) / 100 * 100 = 43.75
+	score, err := ComputeScore(1000)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1000), score.ProjectID)
+	assert.Equal(t, 43.75, score.Score)
+	assert.Equal(t, 4, score.TotalImages)
+
+	// cached
+	score, err = ComputeScore(1000)
+	require.Nil(t, err)
+	assert.Equal(t, 43.75, score.Score)
+	assert.Equal(t, 1, calls)
+}
@@ -1,8 +1,10 @@
 package robot
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/rbac"
 	"github.com/goharbor/harbor/src/common/token"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
@@ -15,6 +17,10 @@ import (
 var (
 	// RobotCtr is a global variable for the default robot account controller implementation
 	RobotCtr = NewController(NewDefaultRobotAccountManager())
+
+	// ErrNoAccess is returned when rotating the token of the robot created before its access is stored
+	// without specifying the access
+	ErrNoAccess = errors.New("the access of the robot account is unknown, it must be specified")
 )
 
 // Controller to handle the requests related with robot account
@@ -33,6 +39,16 @@ type Controller interface {
 
 	// ListRobotAccount ...
 	ListRobotAccount(query *q.Query) ([]*model.Robot, error)
+
+	// UpdateRobotLastAccess records the time the robot account is used
+	UpdateRobotLastAccess(id int64, t time.Time) error
+
+	// GetRobotAccountByTokenID returns the robot account the token with the ID is issued to
+	GetRobotAccountByTokenID(tokenID int64) (*model.Robot, error)
+
+	// RotateRobotToken issues a new token with the access to the robot account, the access stored
+	// with the robot is used if it's empty. The old token stops validating once the overlap elapses
+	RotateRobotToken(r *model.Robot, access []*rbac.Policy, overlap time.Duration) (*model.Robot, error)
 }
 
 // DefaultAPIController ...
@@ -60,6 +76,11 @@ func (d *DefaultAPIController) CreateRobotAccount(robotReq *model.RobotCreate) (
 	tokenDuration := time.Duration(config.RobotTokenDuration()) * time.Minute
 	expiresAt := time.Now().UTC().Add(tokenDuration).Unix()
 	createdName := common.RobotPrefix + robotReq.Name
+	// the access is stored to issue the new token when rotating
+	access, err := json.Marshal(robotReq.Access)
+	if err != nil {
+		return nil, err
+	}
 
 	// first to add a robot account, and get its id.
 	robot := &model.Robot{
@@ -68,6 +89,7 @@ func (d *DefaultAPIController) CreateRobotAccount(robotReq *model.RobotCreate) (
 		ProjectID:   robotReq.ProjectID,
 		ExpiresAt:   expiresAt,
 		Visible:     robotReq.Visible,
+		Access:      string(access),
 	}
 	id, err := d.manager.CreateRobotAccount(robot)
 	if err != nil {
@@ -115,3 +137,60 @@ func (d *DefaultAPIController) UpdateRobotAccount(r *model.Robot) error {
 func (d *DefaultAPIController) ListRobotAccount(query *q.Query) ([]*model.Robot, error) {
 	return d.manager.ListRobotAccount(query)
 }
+
+// UpdateRobotLastAccess ...
+func (d *DefaultAPIController) UpdateRobotLastAccess(id int64, t time.Time) error {
+	return d.manager.UpdateRobotLastAccess(id, t)
+}
+
+// GetRobotAccountByTokenID ...
+func (d *DefaultAPIController) GetRobotAccountByTokenID(tokenID int64) (*model.Robot, error) {
+	return d.manager.GetRobotAccountByTokenID(tokenID)
+}
+
+// RotateRobotToken ...
+func (d *DefaultAPIController) RotateRobotToken(r *model.Robot, access []*rbac.Policy, overlap time.Duration) (*model.Robot, error) {
+	if len(access) == 0 {
+		if len(r.Access) == 0 {
+			return nil, ErrNoAccess
+		}
+		if err := json.Unmarshal([]byte(r.Access), &access); err != nil {
+			return nil, errors.Wrap(err, "failed to parse the access of robot account")
+		}
+	}
+	data, err := json.Marshal(access)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenID, err := d.manager.NextTokenID()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate the token ID")
+	}
+	now := time.Now()
+	expiresAt := now.UTC().Add(time.Duration(config.RobotTokenDuration()) * time.Minute).Unix()
+	jwtToken, err := token.New(tokenID, r.ProjectID, expiresAt, access)
+	if err != nil {
+		return nil, fmt.Errorf("failed to valid parameters to generate token for robot account, %v", err)
+	}
+	rawTk, err := jwtToken.Raw()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign token for robot account, %v", err)
+	}
+
+	// only the token replaced by this rotation is kept in the overlap window
+	r.PreviousTokenID, r.PreviousTokenExpiresAt = 0, 0
+	if overlap > 0 {
+		r.PreviousTokenID = r.CurrentTokenID()
+		r.PreviousTokenExpiresAt = now.Add(overlap).Unix()
+	}
+	r.TokenID = tokenID
+	r.ExpiresAt = expiresAt
+	r.Access = string(data)
+	if err := d.manager.UpdateRobotAccount(r); err != nil {
+		return nil, err
+	}
+
+	r.Token = rawTk
+	return r, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 type ControllerTestSuite struct {
@@ -97,6 +98,51 @@ func (s *ControllerTestSuite) TestRobotAccount() {
 	s.require.Equal(len(robots), 1)
 }
 
+func (s *ControllerTestSuite) TestRotateRobotToken() {
+	policies := []*rbac.Policy{
+		{Resource: "/project/1/repository", Action: rbac.ActionPull},
+	}
+	robot, err := s.ctr.CreateRobotAccount(&model.RobotCreate{
+		Name:      "rotate",
+		ProjectID: 1,
+		Access:    policies,
+	})
+	s.require.Nil(err)
+	defer s.ctr.DeleteRobotAccount(robot.ID)
+
+	// the stored access is used
+	rotated, err := s.ctr.RotateRobotToken(robot, nil, 0)
+	s.require.Nil(err)
+	s.require.NotEmpty(rotated.Token)
+	s.assert.NotEqual(robot.ID, rotated.TokenID)
+	s.assert.Equal(common.RobotPrefix+"rotate", rotated.Name)
+
+	// the old token is rejected without the overlap
+	r, err := s.ctr.GetRobotAccountByTokenID(robot.ID)
+	s.require.Nil(err)
+	s.assert.Nil(r)
+	r, err = s.ctr.GetRobotAccountByTokenID(rotated.TokenID)
+	s.require.Nil(err)
+	s.require.NotNil(r)
+	s.assert.Equal(robot.ID, r.ID)
+
+	// both are accepted during the overlap
+	previous := rotated.TokenID
+	rotated, err = s.ctr.RotateRobotToken(r, policies, time.Minute)
+	s.require.Nil(err)
+	for _, tokenID := range []int64{previous, rotated.TokenID} {
+		r, err = s.ctr.GetRobotAccountByTokenID(tokenID)
+		s.require.Nil(err)
+		s.require.NotNil(r)
+		s.assert.Equal(robot.ID, r.ID)
+	}
+
+	// the robot created before the access is stored
+	r.Access = ""
+	_, err = s.ctr.RotateRobotToken(r, nil, 0)
+	s.assert.Equal(ErrNoAccess, err)
+}
+
 // TearDownSuite clears env for test suite
 func (s *ControllerTestSuite) TearDownSuite() {
 	err := s.ctr.DeleteRobotAccount(s.robotID)
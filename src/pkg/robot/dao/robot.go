@@ -26,6 +26,15 @@ type RobotAccountDao interface {
 
 	// DeleteRobotAccount ...
 	DeleteRobotAccount(id int64) error
+
+	// UpdateRobotLastAccess updates the last access time of the robot account
+	UpdateRobotLastAccess(id int64, t time.Time) error
+
+	// GetRobotAccountByTokenID returns the robot account the token with the ID is issued to
+	GetRobotAccountByTokenID(tokenID int64) (*model.Robot, error)
+
+	// NextTokenID returns a new token ID which never conflicts with the ID of any other token
+	NextTokenID() (int64, error)
 }
 
 // New creates a default implementation for RobotAccountDao
@@ -99,3 +108,38 @@ func (r *robotAccountDao) DeleteRobotAccount(id int64) error {
 	_, err := dao.GetOrmer().QueryTable(&model.Robot{}).Filter("ID", id).Delete()
 	return err
 }
+
+// UpdateRobotLastAccess only updates the last access time, the update time is left untouched
+func (r *robotAccountDao) UpdateRobotLastAccess(id int64, t time.Time) error {
+	_, err := dao.GetOrmer().QueryTable(&model.Robot{}).Filter("ID", id).Update(orm.Params{
+		"last_access_time": t,
+	})
+	return err
+}
+
+// GetRobotAccountByTokenID matches the current token ID, which is the robot ID if the token is never rotated,
+// and the previous token ID which is still in the overlap window of the rotation
+func (r *robotAccountDao) GetRobotAccountByTokenID(tokenID int64) (*model.Robot, error) {
+	robots := []*model.Robot{}
+	_, err := dao.GetOrmer().Raw(`select * from robot
+		where token_id = ? or (token_id = 0 and id = ?)
+		or (previous_token_id = ? and previous_token_expires_at > ?)`,
+		tokenID, tokenID, tokenID, time.Now().Unix()).QueryRows(&robots)
+	if err != nil {
+		return nil, err
+	}
+	if len(robots) == 0 {
+		return nil, nil
+	}
+	return robots[0], nil
+}
+
+// NextTokenID draws the token ID from the sequence of robot ID, as the token ID of the robot is
+// its ID until the token is rotated
+func (r *robotAccountDao) NextTokenID() (int64, error) {
+	var id int64
+	if err := dao.GetOrmer().Raw(`select nextval('robot_id_seq')`).QueryRow(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
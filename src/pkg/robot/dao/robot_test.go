@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 type robotAccountDaoTestSuite struct {
@@ -120,6 +121,72 @@ func (t *robotAccountDaoTestSuite) TestDeleteRobotAccount() {
 	t.require.Nil(err)
 }
 
+func (t *robotAccountDaoTestSuite) TestUpdateRobotLastAccess() {
+	robot := &model.Robot{
+		Name:        "test6",
+		Description: "test6 description",
+		ProjectID:   1,
+	}
+	id, err := t.dao.CreateRobotAccount(robot)
+	t.require.Nil(err)
+	defer t.dao.DeleteRobotAccount(id)
+
+	robot, err = t.dao.GetRobotAccount(id)
+	t.require.Nil(err)
+	t.assert.True(robot.LastAccessTime.IsZero())
+	updateTime := robot.UpdateTime
+
+	now := time.Now().Truncate(time.Second)
+	err = t.dao.UpdateRobotLastAccess(id, now)
+	t.require.Nil(err)
+	robot, err = t.dao.GetRobotAccount(id)
+	t.require.Nil(err)
+	t.assert.True(now.Equal(robot.LastAccessTime))
+	t.assert.True(updateTime.Equal(robot.UpdateTime))
+}
+
+func (t *robotAccountDaoTestSuite) TestGetRobotAccountByTokenID() {
+	robot := &model.Robot{
+		Name:        "test7",
+		Description: "test7 description",
+		ProjectID:   1,
+	}
+	id, err := t.dao.CreateRobotAccount(robot)
+	t.require.Nil(err)
+	defer t.dao.DeleteRobotAccount(id)
+
+	// the robot ID is the token ID before rotating
+	robot, err = t.dao.GetRobotAccountByTokenID(id)
+	t.require.Nil(err)
+	t.require.NotNil(robot)
+	t.assert.Equal(id, robot.ID)
+
+	tokenID, err := t.dao.NextTokenID()
+	t.require.Nil(err)
+	t.assert.True(tokenID > id)
+	robot.TokenID = tokenID
+	robot.PreviousTokenID = id
+	robot.PreviousTokenExpiresAt = time.Now().Add(time.Hour).Unix()
+	t.require.Nil(t.dao.UpdateRobotAccount(robot))
+
+	for _, tid := range []int64{id, tokenID} {
+		robot, err = t.dao.GetRobotAccountByTokenID(tid)
+		t.require.Nil(err)
+		t.require.NotNil(robot)
+		t.assert.Equal(id, robot.ID)
+	}
+
+	// the previous token ID out of the overlap window
+	robot.PreviousTokenExpiresAt = time.Now().Add(-time.Second).Unix()
+	t.require.Nil(t.dao.UpdateRobotAccount(robot))
+	robot, err = t.dao.GetRobotAccountByTokenID(id)
+	t.require.Nil(err)
+	t.assert.Nil(robot)
+	robot, err = t.dao.GetRobotAccountByTokenID(tokenID)
+	t.require.Nil(err)
+	t.require.NotNil(robot)
+}
+
 // TearDownSuite clears env for test suite
 func (t *robotAccountDaoTestSuite) TearDownSuite() {
 	err := t.dao.DeleteRobotAccount(t.id1)
@@ -4,6 +4,7 @@ import (
 	"github.com/goharbor/harbor/src/pkg/q"
 	"github.com/goharbor/harbor/src/pkg/robot/dao"
 	"github.com/goharbor/harbor/src/pkg/robot/model"
+	"time"
 )
 
 var (
@@ -27,6 +28,15 @@ type Manager interface {
 
 	// ListRobotAccount ...
 	ListRobotAccount(query *q.Query) ([]*model.Robot, error)
+
+	// UpdateRobotLastAccess ...
+	UpdateRobotLastAccess(id int64, t time.Time) error
+
+	// GetRobotAccountByTokenID ...
+	GetRobotAccountByTokenID(tokenID int64) (*model.Robot, error)
+
+	// NextTokenID ...
+	NextTokenID() (int64, error)
 }
 
 type defaultRobotManager struct {
@@ -64,3 +74,18 @@ func (drm *defaultRobotManager) UpdateRobotAccount(r *model.Robot) error {
 func (drm *defaultRobotManager) ListRobotAccount(query *q.Query) ([]*model.Robot, error) {
 	return drm.dao.ListRobotAccounts(query)
 }
+
+// UpdateRobotLastAccess ...
+func (drm *defaultRobotManager) UpdateRobotLastAccess(id int64, t time.Time) error {
+	return drm.dao.UpdateRobotLastAccess(id, t)
+}
+
+// GetRobotAccountByTokenID ...
+func (drm *defaultRobotManager) GetRobotAccountByTokenID(tokenID int64) (*model.Robot, error) {
+	return drm.dao.GetRobotAccountByTokenID(tokenID)
+}
+
+// NextTokenID ...
+func (drm *defaultRobotManager) NextTokenID() (int64, error) {
+	return drm.dao.NextTokenID()
+}
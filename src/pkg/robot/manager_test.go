@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"os"
 	"testing"
+	"time"
 )
 
 type mockRobotDao struct {
@@ -48,6 +49,25 @@ func (m *mockRobotDao) ListRobotAccounts(query *q.Query) ([]*model.Robot, error)
 	return rs, args.Error(1)
 }
 
+func (m *mockRobotDao) UpdateRobotLastAccess(id int64, t time.Time) error {
+	args := m.Called(id, t)
+	return args.Error(0)
+}
+
+func (m *mockRobotDao) GetRobotAccountByTokenID(tokenID int64) (*model.Robot, error) {
+	args := m.Called(tokenID)
+	var r *model.Robot
+	if args.Get(0) != nil {
+		r = args.Get(0).(*model.Robot)
+	}
+	return r, args.Error(1)
+}
+
+func (m *mockRobotDao) NextTokenID() (int64, error) {
+	args := m.Called()
+	return int64(args.Int(0)), args.Error(1)
+}
+
 type managerTestingSuite struct {
 	suite.Suite
 	t            *testing.T
@@ -117,6 +137,25 @@ func (m *managerTestingSuite) TestGetRobotAccount() {
 	m.assert.Equal(int64(1), ir.ID)
 }
 
+func (m *managerTestingSuite) TestGetRobotAccountByTokenID() {
+	m.mockRobotDao.On("GetRobotAccountByTokenID", int64(3)).Return(&model.Robot{
+		ID:      1,
+		TokenID: 3,
+	}, nil)
+	ir, err := Mgr.GetRobotAccountByTokenID(3)
+	m.require.Nil(err)
+	m.require.NotNil(ir)
+	m.assert.Equal(int64(1), ir.ID)
+	m.assert.Equal(int64(3), ir.CurrentTokenID())
+}
+
+func (m *managerTestingSuite) TestNextTokenID() {
+	m.mockRobotDao.On("NextTokenID").Return(5, nil)
+	id, err := Mgr.NextTokenID()
+	m.require.Nil(err)
+	m.assert.Equal(int64(5), id)
+}
+
 func (m *managerTestingSuite) ListRobotAccount() {
 	m.mockRobotDao.On("ListRobotAccount", mock.Anything).Return([]model.Robot{
 		{
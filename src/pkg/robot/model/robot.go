@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"github.com/astaxie/beego/orm"
 	"github.com/astaxie/beego/validation"
 	"github.com/goharbor/harbor/src/common/rbac"
@@ -15,18 +16,33 @@ func init() {
 	orm.RegisterModel(&Robot{})
 }
 
+const (
+	// SystemLevelProjectID is the project ID of the system level robots
+	SystemLevelProjectID int64 = 0
+	// AllProjects is the namespace of the policies granting the access to every project,
+	// e.g. "/project/*/repository", it's only allowed for the system level robots
+	AllProjects = rbac.Resource("/project/*")
+	// MaxTokenOverlap is the longest duration the old token keeps validating after rotating the token
+	MaxTokenOverlap = 24 * time.Hour
+)
+
 // Robot holds the details of a robot.
 type Robot struct {
-	ID           int64     `orm:"pk;auto;column(id)" json:"id"`
-	Name         string    `orm:"column(name)" json:"name"`
-	Token        string    `orm:"-" json:"token"`
-	Description  string    `orm:"column(description)" json:"description"`
-	ProjectID    int64     `orm:"column(project_id)" json:"project_id"`
-	ExpiresAt    int64     `orm:"column(expiresat)" json:"expires_at"`
-	Disabled     bool      `orm:"column(disabled)" json:"disabled"`
-	Visible      bool      `orm:"column(visible)" json:"-"`
-	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
-	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+	ID                     int64     `orm:"pk;auto;column(id)" json:"id"`
+	Name                   string    `orm:"column(name)" json:"name"`
+	Token                  string    `orm:"-" json:"token"`
+	Description            string    `orm:"column(description)" json:"description"`
+	ProjectID              int64     `orm:"column(project_id)" json:"project_id"`
+	ExpiresAt              int64     `orm:"column(expiresat)" json:"expires_at"`
+	TokenID                int64     `orm:"column(token_id)" json:"-"`
+	PreviousTokenID        int64     `orm:"column(previous_token_id)" json:"-"`
+	PreviousTokenExpiresAt int64     `orm:"column(previous_token_expires_at)" json:"-"`
+	Access                 string    `orm:"column(access)" json:"-"`
+	Disabled               bool      `orm:"column(disabled)" json:"disabled"`
+	Visible                bool      `orm:"column(visible)" json:"-"`
+	CreationTime           time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime             time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+	LastAccessTime         time.Time `orm:"column(last_access_time);null" json:"last_access_time"`
 }
 
 // TableName ...
@@ -34,6 +50,22 @@ func (r *Robot) TableName() string {
 	return RobotTable
 }
 
+// IsSystemLevel returns true if the robot isn't bound to any project, the access of
+// the system level robot is scoped to the projects listed in its token
+func (r *Robot) IsSystemLevel() bool {
+	return r.ProjectID == SystemLevelProjectID
+}
+
+// CurrentTokenID returns the ID carried by the current token of the robot, the robot ID is used
+// until the token is rotated. The previous token ID keeps validating until PreviousTokenExpiresAt
+// if the rotation has an overlap window
+func (r *Robot) CurrentTokenID() int64 {
+	if r.TokenID > 0 {
+		return r.TokenID
+	}
+	return r.ID
+}
+
 // RobotQuery ...
 type RobotQuery struct {
 	Name           string
@@ -69,6 +101,22 @@ func (rq *RobotCreate) Valid(v *validation.Validation) {
 	}
 }
 
+// RobotTokenRotate is the request to rotate the token of the robot
+type RobotTokenRotate struct {
+	// Access of the new token, the one stored with the robot is used if it's empty
+	Access []*rbac.Policy `json:"access"`
+	// OverlapSeconds is the duration in seconds the old token keeps validating after the rotation
+	OverlapSeconds int64 `json:"overlap_seconds"`
+}
+
+// Valid ...
+func (rr *RobotTokenRotate) Valid(v *validation.Validation) {
+	// compare in seconds as the huge overlap overflows the duration
+	if rr.OverlapSeconds < 0 || rr.OverlapSeconds > int64(MaxTokenOverlap/time.Second) {
+		v.SetError("overlap_seconds", fmt.Sprintf("overlap must be between 0 and %d seconds", int64(MaxTokenOverlap/time.Second)))
+	}
+}
+
 // RobotRep ...
 type RobotRep struct {
 	Name  string `json:"name"`
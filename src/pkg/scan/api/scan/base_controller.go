@@ -15,8 +15,12 @@
 package scan
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	cj "github.com/goharbor/harbor/src/common/job"
@@ -41,8 +45,9 @@ import (
 var DefaultController = NewController()
 
 const (
-	configRegistryEndpoint = "registryEndpoint"
-	configCoreInternalAddr = "coreInternalAddr"
+	configRegistryEndpoint   = "registryEndpoint"
+	configCoreInternalAddr   = "coreInternalAddr"
+	configScanReportCacheTTL = "scanReportCacheTTL"
 )
 
 // uuidGenerator is a func template which is for generating UUID.
@@ -55,6 +60,9 @@ type configGetter func(cfg string) (string, error)
 // jcGetter is a func template which is used to get the job service client.
 type jcGetter func() cj.Client
 
+// projectIDGetter is a func template which is used to get the ID of the project by name.
+type projectIDGetter func(name string) (int64, error)
+
 // basicController is default implementation of api.Controller interface
 type basicController struct {
 	// Manage the scan report records
@@ -69,6 +77,12 @@ type basicController struct {
 	uuid uuidGenerator
 	// Configuration getter func
 	config configGetter
+	// Client pool for talking to adapters
+	clientPool v1.ClientPool
+	// Project ID getter func
+	projectID projectIDGetter
+	// Platforms getter func of the multi-arch artifact
+	platforms platformsGetter
 }
 
 // NewController news a scan API controller
@@ -100,20 +114,43 @@ func NewController() Controller {
 				return config.ExtEndpoint()
 			case configCoreInternalAddr:
 				return config.InternalCoreURL(), nil
+			case configScanReportCacheTTL:
+				return strconv.Itoa(config.ScanReportCacheTTL()), nil
 			default:
 				return "", errors.Errorf("configuration option %s not defined", cfg)
 			}
 		},
+		// Refer to the default client pool
+		clientPool: v1.DefaultClientPool,
+		// Get the project ID with the global project manager
+		projectID: func(name string) (int64, error) {
+			p, err := config.GlobalProjectMgr.Get(name)
+			if err != nil {
+				return 0, err
+			}
+			if p == nil {
+				return 0, errors.Errorf("project %s not found", name)
+			}
+
+			return p.ProjectID, nil
+		},
+		// Get the platforms from the index of the artifact in the registry
+		platforms: getPlatforms,
 	}
 }
 
 // Scan ...
-func (bc *basicController) Scan(artifact *v1.Artifact) error {
+func (bc *basicController) Scan(artifact *v1.Artifact, options ...Option) error {
 	if artifact == nil {
 		return errors.New("nil artifact to scan")
 	}
 
-	r, err := bc.sc.GetRegistrationByProject(artifact.NamespaceID)
+	ops := &Options{}
+	for _, op := range options {
+		op(ops)
+	}
+
+	r, err := bc.getRegistration(artifact)
 	if err != nil {
 		return errors.Wrap(err, "scan controller: scan")
 	}
@@ -125,6 +162,41 @@ func (bc *basicController) Scan(artifact *v1.Artifact) error {
 		return errors.Wrap(err, "scan controller: scan")
 	}
 
+	// The multi-arch artifact is scanned per platform, one job for each platform
+	platforms, err := bc.platforms(artifact)
+	if err != nil {
+		return errors.Wrap(err, "scan controller: scan")
+	}
+
+	if len(platforms) == 0 {
+		return bc.scan(artifact, r, meta, ops)
+	}
+
+	selected := selectPlatforms(platforms, ops.Platforms)
+	if len(selected) == 0 {
+		return errors.Errorf("scan controller: scan: no platform of artifact %s:%s matches %v", artifact.Repository, artifact.Digest, ops.Platforms)
+	}
+
+	failures := make([]string, 0)
+	for _, p := range selected {
+		pa := *artifact
+		pa.Platform = p
+		if err := bc.scan(&pa, r, meta, ops); err != nil {
+			// Continue to scan the other platforms
+			logger.Error(errors.Wrapf(err, "scan platform %s", p))
+			failures = append(failures, fmt.Sprintf("%s: %s", p, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.Errorf("scan controller: scan: %d of %d platforms failed: %s", len(failures), len(selected), strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// scan launches the scan job for the artifact (or the platform of the multi-arch artifact) with the registration
+func (bc *basicController) scan(artifact *v1.Artifact, r *scanner.Registration, meta *v1.ScannerAdapterMetadata, ops *Options) error {
 	// Generate a UUID as track ID which groups the report records generated
 	// by the specified registration for the digest with given mime type.
 	trackID, err := bc.uuid()
@@ -132,18 +204,41 @@ func (bc *basicController) Scan(artifact *v1.Artifact) error {
 		return errors.Wrap(err, "scan controller: scan")
 	}
 
+	// The reports are reused only if the cache is enabled and the scan isn't forced
+	var cacheTTL time.Duration
+	if !ops.Force {
+		cacheTTL = bc.reportCacheTTL()
+	}
+
 	producesMimes := make([]string, 0)
+	reusedMimes := make([]string, 0)
+	// The mime types requested, the same mime type may be produced by several capabilities
+	requested := make(map[string]bool)
 	matched := false
+	// All the capabilities consuming the artifact are requested, e.g: the vulnerability report and the SBOM
 	for _, ca := range meta.Capabilities {
+		consumed := false
 		for _, cm := range ca.ConsumesMimeTypes {
 			if cm == artifact.MimeType {
-				matched = true
+				consumed = true
 				break
 			}
 		}
 
-		if matched {
+		if consumed {
+			matched = true
 			for _, pm := range ca.ProducesMimeTypes {
+				if requested[pm] {
+					continue
+				}
+				requested[pm] = true
+
+				// The same digest may have been scanned via another repository
+				if cacheTTL > 0 && bc.hasFreshReport(artifact.Digest, r.UUID, pm, artifact.Platform.String(), cacheTTL) {
+					reusedMimes = append(reusedMimes, pm)
+					continue
+				}
+
 				// Create report placeholder first
 				reportPlaceholder := &scan.Report{
 					Digest:           artifact.Digest,
@@ -152,6 +247,7 @@ func (bc *basicController) Scan(artifact *v1.Artifact) error {
 					StatusCode:       job.PendingStatus.Code(),
 					TrackID:          trackID,
 					MimeType:         pm,
+					Platform:         artifact.Platform.String(),
 				}
 				_, e := bc.manager.Create(reportPlaceholder)
 				if e != nil {
@@ -168,8 +264,6 @@ func (bc *basicController) Scan(artifact *v1.Artifact) error {
 
 				producesMimes = append(producesMimes, pm)
 			}
-
-			break
 		}
 	}
 
@@ -178,13 +272,34 @@ func (bc *basicController) Scan(artifact *v1.Artifact) error {
 		return errors.Errorf("the configured scanner %s does not support scanning artifact with mime type %s", r.Name, artifact.MimeType)
 	}
 
+	// All the reports are reused
+	if len(producesMimes) == 0 && len(reusedMimes) > 0 && err == nil {
+		logger.Infof("Reuse the fresh reports of %s for artifact %s:%s, skip the scan", reusedMimes, artifact.Repository, artifact.Digest)
+		return nil
+	}
+
 	// If all the record are created failed.
 	if len(producesMimes) == 0 {
 		// Return the last error
 		return errors.Wrap(err, "scan controller: scan")
 	}
 
-	jobID, err := bc.launchScanJob(trackID, artifact, r, producesMimes)
+	// The capabilities are passed to the job for validating the mime types, and the scanner
+	// info is checked in along with the reports
+	rc := *r
+	if meta.Scanner != nil {
+		rc.Scanner = meta.Scanner.Name
+		rc.Vendor = meta.Scanner.Vendor
+		rc.Version = meta.Scanner.Version
+	}
+	for _, ca := range meta.Capabilities {
+		rc.Capabilities = append(rc.Capabilities, &scanner.Capability{
+			ConsumesMimeTypes: ca.ConsumesMimeTypes,
+			ProducesMimeTypes: ca.ProducesMimeTypes,
+		})
+	}
+
+	jobID, err := bc.launchScanJob(trackID, artifact, &rc, producesMimes)
 	if err != nil {
 		// Update the status to the concrete error
 		// Change status code to normal error code
@@ -218,7 +333,7 @@ func (bc *basicController) GetReport(artifact *v1.Artifact, mimeTypes []string)
 	}
 
 	// Get current scanner settings
-	r, err := bc.sc.GetRegistrationByProject(artifact.NamespaceID)
+	r, err := bc.getRegistration(artifact)
 	if err != nil {
 		return nil, errors.Wrap(err, "scan controller: get report")
 	}
@@ -230,6 +345,34 @@ func (bc *basicController) GetReport(artifact *v1.Artifact, mimeTypes []string)
 	return bc.manager.GetBy(artifact.Digest, r.UUID, mimes)
 }
 
+// GetReportMimeTypes ...
+func (bc *basicController) GetReportMimeTypes(artifact *v1.Artifact) ([]string, error) {
+	if artifact == nil {
+		return nil, errors.New("no way to get report mime types for nil artifact")
+	}
+
+	r, err := bc.getRegistration(artifact)
+	if err != nil {
+		return nil, errors.Wrap(err, "scan controller: get report mime types")
+	}
+
+	if r == nil {
+		return nil, errors.New("no scanner registration configured")
+	}
+
+	rps, err := bc.manager.GetBy(artifact.Digest, r.UUID, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "scan controller: get report mime types")
+	}
+
+	mimes := make([]string, 0, len(rps))
+	for _, rp := range rps {
+		mimes = append(mimes, rp.MimeType)
+	}
+
+	return mimes, nil
+}
+
 // GetSummary ...
 func (bc *basicController) GetSummary(artifact *v1.Artifact, mimeTypes []string, options ...report.Option) (map[string]interface{}, error) {
 	if artifact == nil {
@@ -242,14 +385,39 @@ func (bc *basicController) GetSummary(artifact *v1.Artifact, mimeTypes []string,
 		return nil, err
 	}
 
-	summaries := make(map[string]interface{}, len(rps))
+	// The reports of the multi-arch artifact are kept per platform, group them by mime type
+	grouped := make(map[string]map[string]interface{})
 	for _, rp := range rps {
 		sum, err := report.GenerateSummary(rp, options...)
 		if err != nil {
 			return nil, err
 		}
 
-		summaries[rp.MimeType] = sum
+		if _, ok := grouped[rp.MimeType]; !ok {
+			grouped[rp.MimeType] = make(map[string]interface{})
+		}
+		grouped[rp.MimeType][rp.Platform] = sum
+	}
+
+	summaries := make(map[string]interface{}, len(grouped))
+	for mime, sums := range grouped {
+		if sum, ok := sums[""]; ok {
+			// The single platform artifact
+			if len(sums) == 1 {
+				summaries[mime] = sum
+				continue
+			}
+
+			// The report of the multi-arch artifact scanned as a whole before is superseded by the ones of the platforms
+			delete(sums, "")
+		}
+
+		merged, err := report.MergeSummaries(mime, sums)
+		if err != nil {
+			return nil, err
+		}
+
+		summaries[mime] = merged
 	}
 
 	return summaries, nil
@@ -296,11 +464,32 @@ func (bc *basicController) HandleJobHooks(trackID string, change *job.StatusChan
 
 	// Check in data
 	if len(change.CheckIn) > 0 {
+		kind, err := sca.CheckInKindOf(change.CheckIn)
+		if err != nil {
+			return errors.Wrap(err, "scan controller: handle job hook")
+		}
+
+		// The progress of the scan job isn't a report
+		if kind != sca.CheckInKindReport {
+			logger.Debugf("Check in data of kind %s from job %s, skip it", kind, change.JobID)
+			return nil
+		}
+
 		checkInReport := &sca.CheckInReport{}
 		if err := checkInReport.FromJSON(change.CheckIn); err != nil {
 			return errors.Wrap(err, "scan controller: handle job hook")
 		}
 
+		// The raw report may be compressed by the job
+		if err := checkInReport.Decompress(); err != nil {
+			return errors.Wrap(err, "scan controller: handle job hook")
+		}
+
+		// The reports of the other mime types may fail in the same scan
+		for mime, failure := range checkInReport.Failures {
+			logger.Warningf("Scan of artifact %s with mime type %s failed in job %s: %s", checkInReport.Digest, mime, change.JobID, failure)
+		}
+
 		rpl, err := bc.manager.GetBy(
 			checkInReport.Digest,
 			checkInReport.RegistrationUUID,
@@ -309,14 +498,48 @@ func (bc *basicController) HandleJobHooks(trackID string, change *job.StatusChan
 			return errors.Wrap(err, "scan controller: handle job hook")
 		}
 
-		if len(rpl) == 0 {
+		// The reports of the multi-arch artifact are kept per platform
+		var rp *scan.Report
+		for _, r := range rpl {
+			if r.Platform == checkInReport.Platform {
+				rp = r
+				break
+			}
+		}
+
+		if rp == nil {
 			return errors.New("no report found to update data")
 		}
 
-		if err := bc.manager.UpdateReportData(
-			rpl[0].UUID,
+		// The same check-in may be delivered more than once, skip it if it has been processed
+		hash := checkInHash(change.CheckIn)
+		existing, err := bc.manager.GetByCheckInHash(rp.UUID, hash)
+		if err != nil {
+			return errors.Wrap(err, "scan controller: handle job hook")
+		}
+
+		if existing != nil {
+			logger.Debugf("Check in data of report %d has been processed, skip it", existing.ID)
+			return nil
+		}
+
+		// The job ID is unknown in the check-in of the previous versions
+		producer := &scan.Producer{
+			SchemaVersion:  checkInReport.SchemaVersion,
+			JobID:          checkInReport.JobID,
+			ScannerName:    checkInReport.ScannerName,
+			ScannerVersion: checkInReport.ScannerVersion,
+		}
+		if len(producer.JobID) == 0 {
+			producer.JobID = change.JobID
+		}
+
+		if _, err := bc.manager.CheckIn(
+			rp.UUID,
 			checkInReport.RawReport,
-			change.Metadata.Revision); err != nil {
+			change.Metadata.Revision,
+			hash,
+			producer); err != nil {
 			return errors.Wrap(err, "scan controller: handle job hook")
 		}
 
@@ -326,6 +549,58 @@ func (bc *basicController) HandleJobHooks(trackID string, change *job.StatusChan
 	return bc.manager.UpdateStatus(trackID, change.Status, change.Metadata.Revision)
 }
 
+// reportCacheTTL returns how long the report is reused, 0 means the report isn't reused
+func (bc *basicController) reportCacheTTL() time.Duration {
+	v, err := bc.config(configScanReportCacheTTL)
+	if err != nil || len(v) == 0 {
+		return 0
+	}
+
+	ttl, err := strconv.Atoi(v)
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+
+	return time.Duration(ttl) * time.Second
+}
+
+// hasFreshReport checks whether the successful report of the digest (and platform) with the mime type is completed within the TTL
+func (bc *basicController) hasFreshReport(digest, registrationUUID, mimeType, platform string, ttl time.Duration) bool {
+	rps, err := bc.manager.GetBy(digest, registrationUUID, []string{mimeType})
+	if err != nil {
+		logger.Error(errors.Wrap(err, "scan controller: check fresh report"))
+		return false
+	}
+
+	for _, rp := range rps {
+		if rp.Platform != platform {
+			continue
+		}
+
+		if rp.Status == job.SuccessStatus.String() && len(rp.Report) > 0 && time.Since(rp.EndTime) < ttl {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkInHash returns the hex encoded sha256 hash of the check-in data
+func checkInHash(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// getRegistration gets the scanner registration for the artifact by the scanner assignments of the project
+func (bc *basicController) getRegistration(artifact *v1.Artifact) (*scanner.Registration, error) {
+	assignments, err := bc.sc.GetScannerAssignments(artifact.NamespaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return bc.sc.SelectForArtifact(assignments, artifact)
+}
+
 // makeAuthorization creates authorization from a robot account based on the arguments for scanning.
 func (bc *basicController) makeAuthorization(pid int64, repository string, ttl int64) (string, error) {
 	// Use uuid as name to avoid duplicated entries.
@@ -407,13 +682,27 @@ func (bc *basicController) launchScanJob(trackID string, artifact *v1.Artifact,
 	hookURL := fmt.Sprintf("%s/service/notifications/jobs/scan/%s", callbackURL, trackID)
 
 	j := &jm.JobData{
-		Name: job.ImageScanJob,
-		Metadata: &jm.JobMetadata{
-			JobKind: job.KindGeneric,
-		},
+		Name:       job.ImageScanJob,
+		Metadata:   jobMetadata(registration),
 		Parameters: params,
 		StatusHook: hookURL,
 	}
 
 	return bc.jc().SubmitJob(j)
 }
+
+// jobMetadata builds the metadata of the scan job, the retry policy of the registration overrides the
+// one declared by the scan job
+func jobMetadata(registration *scanner.Registration) *jm.JobMetadata {
+	metadata := &jm.JobMetadata{
+		JobKind: job.KindGeneric,
+	}
+	switch {
+	case registration.RetryDisabled:
+		metadata.MaxFails = 1
+	case registration.MaxRetries > 0:
+		metadata.MaxFails = uint(registration.MaxRetries) + 1
+	}
+
+	return metadata
+}
@@ -18,6 +18,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/goharbor/harbor/src/pkg/q"
 	"github.com/goharbor/harbor/src/pkg/robot/model"
 	sca "github.com/goharbor/harbor/src/pkg/scan"
+	sapi "github.com/goharbor/harbor/src/pkg/scan/api/scanner"
 	"github.com/goharbor/harbor/src/pkg/scan/dao/scan"
 	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
 	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
@@ -95,6 +97,8 @@ func (suite *ControllerTestSuite) SetupSuite() {
 
 	sc := &MockScannerController{}
 	sc.On("GetRegistrationByProject", suite.artifact.NamespaceID).Return(suite.registration, nil)
+	sc.On("GetScannerAssignments", suite.artifact.NamespaceID).Return([]sapi.Assignment{}, nil)
+	sc.On("SelectForArtifact", []sapi.Assignment{}, suite.artifact).Return(suite.registration, nil)
 	sc.On("Ping", suite.registration).Return(m, nil)
 
 	mgr := &MockReportManager{}
@@ -152,8 +156,8 @@ func (suite *ControllerTestSuite) SetupSuite() {
 	}
 
 	mgr.On("GetBy", suite.artifact.Digest, suite.registration.UUID, []string{v1.MimeTypeNativeReport}).Return(reports, nil)
+	mgr.On("GetBy", suite.artifact.Digest, suite.registration.UUID, ([]string)(nil)).Return(reports, nil)
 	mgr.On("Get", "rp-uuid-001").Return(reports[0], nil)
-	mgr.On("UpdateReportData", "rp-uuid-001", suite.rawReport, (int64)(10000)).Return(nil)
 	mgr.On("UpdateStatus", "the-uuid-123", "Success", (int64)(10000)).Return(nil)
 
 	rc := &MockRobotController{}
@@ -191,7 +195,16 @@ func (suite *ControllerTestSuite) SetupSuite() {
 	rJSON, err := req.ToJSON()
 	require.NoError(suite.T(), err)
 
-	regJSON, err := suite.registration.ToJSON()
+	// The capabilities of the scanner are passed to the job
+	jobRegistration := *suite.registration
+	jobRegistration.Scanner = m.Scanner.Name
+	jobRegistration.Vendor = m.Scanner.Vendor
+	jobRegistration.Version = m.Scanner.Version
+	jobRegistration.Capabilities = []*scanner.Capability{{
+		ConsumesMimeTypes: m.Capabilities[0].ConsumesMimeTypes,
+		ProducesMimeTypes: m.Capabilities[0].ProducesMimeTypes,
+	}}
+	regJSON, err := jobRegistration.ToJSON()
 	require.NoError(suite.T(), err)
 
 	jc := &MockJobServiceClient{}
@@ -231,6 +244,9 @@ func (suite *ControllerTestSuite) SetupSuite() {
 
 			return "", nil
 		},
+		platforms: func(artifact *v1.Artifact) ([]*v1.Platform, error) {
+			return nil, nil
+		},
 	}
 }
 
@@ -243,6 +259,270 @@ func (suite *ControllerTestSuite) TestScanControllerScan() {
 	require.NoError(suite.T(), err)
 }
 
+// TestJobMetadata tests the retry policy of the registration is applied to the metadata of the scan job
+func (suite *ControllerTestSuite) TestJobMetadata() {
+	// the max fails of the scan job is used by default
+	md := jobMetadata(&scanner.Registration{})
+	suite.Equal(&jm.JobMetadata{JobKind: job.KindGeneric}, md)
+
+	md = jobMetadata(&scanner.Registration{MaxRetries: 6})
+	suite.Equal(&jm.JobMetadata{JobKind: job.KindGeneric, MaxFails: 7}, md)
+
+	// the retry is disabled whatever the max retries is
+	md = jobMetadata(&scanner.Registration{MaxRetries: 6, RetryDisabled: true})
+	suite.Equal(&jm.JobMetadata{JobKind: job.KindGeneric, MaxFails: 1}, md)
+}
+
+// TestScanControllerScanCacheHit ...
+func (suite *ControllerTestSuite) TestScanControllerScanCacheHit() {
+	mgr := suite.cachedReportManager(time.Now().Add(-time.Minute))
+	c := suite.controllerWithCache(mgr, "3600")
+
+	err := c.Scan(suite.artifact)
+	require.NoError(suite.T(), err)
+	mgr.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+// TestScanControllerScanCacheExpired ...
+func (suite *ControllerTestSuite) TestScanControllerScanCacheExpired() {
+	mgr := suite.cachedReportManager(time.Now().Add(-2 * time.Hour))
+	c := suite.controllerWithCache(mgr, "3600")
+
+	err := c.Scan(suite.artifact)
+	require.NoError(suite.T(), err)
+	mgr.AssertCalled(suite.T(), "Create", mock.Anything)
+	mgr.AssertCalled(suite.T(), "UpdateScanJobID", "the-uuid-123", "the-job-id")
+}
+
+// TestScanControllerScanForced ...
+func (suite *ControllerTestSuite) TestScanControllerScanForced() {
+	mgr := suite.cachedReportManager(time.Now().Add(-time.Minute))
+	c := suite.controllerWithCache(mgr, "3600")
+
+	err := c.Scan(suite.artifact, WithForce(true))
+	require.NoError(suite.T(), err)
+	mgr.AssertNotCalled(suite.T(), "GetBy", mock.Anything, mock.Anything, mock.Anything)
+	mgr.AssertCalled(suite.T(), "Create", mock.Anything)
+}
+
+// TestScanControllerScanSBOM tests the SBOM is requested if the scanner advertises the capability
+func (suite *ControllerTestSuite) TestScanControllerScanSBOM() {
+	mimes := suite.requestedMimes([]*v1.ScannerCapability{
+		{
+			ConsumesMimeTypes: []string{v1.MimeTypeDockerArtifact},
+			ProducesMimeTypes: []string{v1.MimeTypeNativeReport},
+		},
+		{
+			ConsumesMimeTypes: []string{v1.MimeTypeOCIArtifact, v1.MimeTypeDockerArtifact},
+			ProducesMimeTypes: []string{v1.MimeTypeSBOMCycloneDX, v1.MimeTypeNativeReport},
+		},
+	})
+	suite.Equal([]string{v1.MimeTypeNativeReport, v1.MimeTypeSBOMCycloneDX}, mimes)
+}
+
+// TestScanControllerScanNoSBOM tests the SBOM isn't requested if the scanner lacks the capability
+func (suite *ControllerTestSuite) TestScanControllerScanNoSBOM() {
+	mimes := suite.requestedMimes([]*v1.ScannerCapability{
+		{
+			ConsumesMimeTypes: []string{v1.MimeTypeDockerArtifact},
+			ProducesMimeTypes: []string{v1.MimeTypeNativeReport},
+		},
+		{
+			// The SBOM of the OCI artifact only
+			ConsumesMimeTypes: []string{v1.MimeTypeOCIArtifact},
+			ProducesMimeTypes: []string{v1.MimeTypeSBOMSPDX},
+		},
+	})
+	suite.Equal([]string{v1.MimeTypeNativeReport}, mimes)
+}
+
+// requestedMimes scans the artifact with the scanner of the capabilities and returns the mime types
+// of the reports requested by the scan job
+func (suite *ControllerTestSuite) requestedMimes(capabilities []*v1.ScannerCapability) []string {
+	sc := &MockScannerController{}
+	sc.On("GetScannerAssignments", suite.artifact.NamespaceID).Return([]sapi.Assignment{}, nil)
+	sc.On("SelectForArtifact", []sapi.Assignment{}, suite.artifact).Return(suite.registration, nil)
+	sc.On("Ping", suite.registration).Return(&v1.ScannerAdapterMetadata{
+		Scanner: &v1.Scanner{
+			Name:    "Clair",
+			Vendor:  "Harbor",
+			Version: "0.1.0",
+		},
+		Capabilities: capabilities,
+	}, nil)
+
+	created := make([]string, 0)
+	mgr := &MockReportManager{}
+	mgr.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(0).(*scan.Report).MimeType)
+	}).Return("r-uuid", nil)
+	mgr.On("UpdateScanJobID", "the-uuid-123", "the-job-id").Return(nil)
+
+	var requested []string
+	jc := &MockJobServiceClient{}
+	jc.On("SubmitJob", mock.Anything).Run(func(args mock.Arguments) {
+		requested = args.Get(0).(*jm.JobData).Parameters[sca.JobParameterMimes].([]string)
+	}).Return("the-job-id", nil)
+
+	c := *(suite.c.(*basicController))
+	c.sc = sc
+	c.manager = mgr
+	c.jc = func() cj.Client {
+		return jc
+	}
+
+	err := c.Scan(suite.artifact)
+	require.NoError(suite.T(), err)
+	// The report placeholders are created for the requested mime types
+	suite.Equal(requested, created)
+
+	return requested
+}
+
+// TestScanControllerScanMultiArch tests one job is launched for each platform of the multi-arch artifact
+func (suite *ControllerTestSuite) TestScanControllerScanMultiArch() {
+	reports, requests := suite.scanMultiArch()
+	suite.Equal([]string{"linux/amd64", "linux/arm64"}, reports)
+	require.Equal(suite.T(), 2, len(requests))
+	for i, p := range []string{"linux/amd64", "linux/arm64"} {
+		// The digest of the index is scanned with the platform
+		suite.Equal(suite.artifact.Digest, requests[i].Artifact.Digest)
+		suite.Equal(p, requests[i].Artifact.Platform.String())
+	}
+
+	// Only the selected platform is scanned
+	reports, requests = suite.scanMultiArch(WithPlatforms("linux/arm64"))
+	suite.Equal([]string{"linux/arm64"}, reports)
+	require.Equal(suite.T(), 1, len(requests))
+	suite.Equal("linux/arm64", requests[0].Artifact.Platform.String())
+
+	// No platform matches
+	c := suite.multiArchController(&MockReportManager{}, &MockJobServiceClient{})
+	suite.Error(c.Scan(suite.artifact, WithPlatforms("windows/amd64")))
+}
+
+// scanMultiArch scans the multi-arch artifact and returns the platforms of the created report
+// placeholders and the scan requests of the launched jobs
+func (suite *ControllerTestSuite) scanMultiArch(options ...Option) ([]string, []*v1.ScanRequest) {
+	reports := make([]string, 0)
+	mgr := &MockReportManager{}
+	mgr.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		reports = append(reports, args.Get(0).(*scan.Report).Platform)
+	}).Return("r-uuid", nil)
+	mgr.On("UpdateScanJobID", "the-uuid-123", "the-job-id").Return(nil)
+
+	requests := make([]*v1.ScanRequest, 0)
+	jc := &MockJobServiceClient{}
+	jc.On("SubmitJob", mock.Anything).Run(func(args mock.Arguments) {
+		req := &v1.ScanRequest{}
+		require.NoError(suite.T(), req.FromJSON(args.Get(0).(*jm.JobData).Parameters[sca.JobParameterRequest].(string)))
+		requests = append(requests, req)
+	}).Return("the-job-id", nil)
+
+	err := suite.multiArchController(mgr, jc).Scan(suite.artifact, options...)
+	require.NoError(suite.T(), err)
+
+	return reports, requests
+}
+
+// multiArchController returns a copy of the suite controller which treats the artifact as an index
+// with the amd64 and arm64 children
+func (suite *ControllerTestSuite) multiArchController(mgr *MockReportManager, jc *MockJobServiceClient) Controller {
+	c := *(suite.c.(*basicController))
+	c.manager = mgr
+	c.jc = func() cj.Client {
+		return jc
+	}
+	c.platforms = func(artifact *v1.Artifact) ([]*v1.Platform, error) {
+		return []*v1.Platform{
+			{OS: "linux", Architecture: "amd64"},
+			{OS: "linux", Architecture: "arm64"},
+		}, nil
+	}
+
+	return &c
+}
+
+// TestPlatformsOf tests parsing the platforms from the index
+func (suite *ControllerTestSuite) TestPlatformsOf() {
+	index := `{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.docker.distribution.manifest.list.v2+json",
+		"manifests": [
+			{"digest": "sha256:amd64", "platform": {"architecture": "amd64", "os": "linux"}},
+			{"digest": "sha256:arm64", "platform": {"architecture": "arm64", "os": "linux", "variant": "v8"}},
+			{"digest": "sha256:attestation", "platform": {"architecture": "unknown", "os": "unknown"}}
+		]
+	}`
+
+	platforms, err := platformsOf("application/vnd.docker.distribution.manifest.list.v2+json", []byte(index))
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(platforms))
+	suite.Equal("linux/amd64", platforms[0].String())
+	suite.Equal("linux/arm64/v8", platforms[1].String())
+
+	// The OCI index shares the same format
+	platforms, err = platformsOf(mediaTypeOCIImageIndex, []byte(index))
+	require.NoError(suite.T(), err)
+	suite.Equal(2, len(platforms))
+
+	// Not an index
+	platforms, err = platformsOf("application/vnd.docker.distribution.manifest.v2+json", []byte("{}"))
+	require.NoError(suite.T(), err)
+	suite.Nil(platforms)
+
+	_, err = platformsOf(mediaTypeOCIImageIndex, []byte("{"))
+	suite.Error(err)
+
+	// The variant is optional for selecting
+	suite.Equal(platforms, selectPlatforms(platforms, nil))
+	suite.Equal(1, len(selectPlatforms([]*v1.Platform{{OS: "linux", Architecture: "arm64", Variant: "v8"}}, []string{"linux/arm64"})))
+	suite.Equal(0, len(selectPlatforms([]*v1.Platform{{OS: "linux", Architecture: "arm64", Variant: "v8"}}, []string{"linux/arm64/v7"})))
+}
+
+// cachedReportManager returns a report manager with the successful report of the artifact completed at the given time
+func (suite *ControllerTestSuite) cachedReportManager(endTime time.Time) *MockReportManager {
+	reports := []*scan.Report{
+		{
+			ID:               12,
+			UUID:             "rp-uuid-002",
+			Digest:           suite.artifact.Digest,
+			RegistrationUUID: suite.registration.UUID,
+			MimeType:         v1.MimeTypeNativeReport,
+			Status:           "Success",
+			StatusCode:       3,
+			TrackID:          "the-uuid-000",
+			JobID:            "the-job-id-000",
+			Report:           suite.rawReport,
+			StartTime:        endTime.Add(-time.Minute),
+			EndTime:          endTime,
+		},
+	}
+
+	mgr := &MockReportManager{}
+	mgr.On("GetBy", suite.artifact.Digest, suite.registration.UUID, []string{v1.MimeTypeNativeReport}).Return(reports, nil)
+	mgr.On("Create", mock.Anything).Return("r-uuid", nil)
+	mgr.On("UpdateScanJobID", "the-uuid-123", "the-job-id").Return(nil)
+
+	return mgr
+}
+
+// controllerWithCache returns a copy of the suite controller with the given manager and report cache TTL
+func (suite *ControllerTestSuite) controllerWithCache(mgr *MockReportManager, ttl string) Controller {
+	c := *(suite.c.(*basicController))
+	c.manager = mgr
+	config := c.config
+	c.config = func(cfg string) (string, error) {
+		if cfg == configScanReportCacheTTL {
+			return ttl, nil
+		}
+
+		return config(cfg)
+	}
+
+	return &c
+}
+
 // TestScanControllerGetReport ...
 func (suite *ControllerTestSuite) TestScanControllerGetReport() {
 	rep, err := suite.c.GetReport(suite.artifact, []string{v1.MimeTypeNativeReport})
@@ -250,6 +530,13 @@ func (suite *ControllerTestSuite) TestScanControllerGetReport() {
 	assert.Equal(suite.T(), 1, len(rep))
 }
 
+// TestScanControllerGetReportMimeTypes ...
+func (suite *ControllerTestSuite) TestScanControllerGetReportMimeTypes() {
+	mimes, err := suite.c.GetReportMimeTypes(suite.artifact)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{v1.MimeTypeNativeReport}, mimes)
+}
+
 // TestScanControllerGetSummary ...
 func (suite *ControllerTestSuite) TestScanControllerGetSummary() {
 	sum, err := suite.c.GetSummary(suite.artifact, []string{v1.MimeTypeNativeReport})
@@ -288,8 +575,210 @@ func (suite *ControllerTestSuite) TestScanControllerHandleJobHooks() {
 		},
 	}
 
+	hash := checkInHash(statusChange.CheckIn)
+	mgr := suite.c.(*basicController).manager.(*MockReportManager)
+	mgr.On("GetByCheckInHash", "rp-uuid-001", hash).Return(nil, nil).Once()
+	// The check-in of the previous versions is checked in with the defaulted producer
+	producer := &scan.Producer{SchemaVersion: sca.CheckInSchemaVersionLegacy, JobID: "the-job-id"}
+	mgr.On("CheckIn", "rp-uuid-001", suite.rawReport, (int64)(10000), hash, producer).Return((int64)(1), nil).Once()
+	mgr.On("GetByCheckInHash", "rp-uuid-001", hash).Return(&scan.Report{ID: 1, UUID: "rp-uuid-001"}, nil)
+
+	err = suite.c.HandleJobHooks("the-uuid-123", statusChange)
+	require.NoError(suite.T(), err)
+
+	// The same check-in is delivered again
 	err = suite.c.HandleJobHooks("the-uuid-123", statusChange)
 	require.NoError(suite.T(), err)
+
+	mgr.AssertNumberOfCalls(suite.T(), "CheckIn", 1)
+}
+
+// TestScanControllerHandleJobHooksProgress tests the progress check-in isn't treated as a report
+func (suite *ControllerTestSuite) TestScanControllerHandleJobHooksProgress() {
+	p := &sca.CheckInProgress{
+		Kind:      sca.CheckInKindProgress,
+		Phase:     sca.PhaseAnalyzing,
+		MimeTypes: map[string]string{v1.MimeTypeNativeReport: sca.PhaseAnalyzing},
+		Elapsed:   10,
+	}
+	pJSON, err := p.ToJSON()
+	require.NoError(suite.T(), err)
+
+	statusChange := &job.StatusChange{
+		JobID:   "the-job-id",
+		Status:  "Running",
+		CheckIn: pJSON,
+		Metadata: &job.StatsInfo{
+			Revision: (int64)(10002),
+		},
+	}
+
+	mgr := suite.c.(*basicController).manager.(*MockReportManager)
+	err = suite.c.HandleJobHooks("the-uuid-123", statusChange)
+	require.NoError(suite.T(), err)
+	mgr.AssertNotCalled(suite.T(), "GetByCheckInHash", mock.Anything, checkInHash(pJSON))
+	mgr.AssertNotCalled(suite.T(), "CheckIn", "rp-uuid-001", mock.Anything, (int64)(10002), mock.Anything, mock.Anything)
+
+	// Malformed check-in data
+	statusChange.CheckIn = "{"
+	require.Error(suite.T(), suite.c.HandleJobHooks("the-uuid-123", statusChange))
+}
+
+// TestScanControllerHandleJobHooksCompressed tests the compressed raw report is decompressed before checking in
+func (suite *ControllerTestSuite) TestScanControllerHandleJobHooksCompressed() {
+	cReport := &sca.CheckInReport{
+		Digest:           "digest-code",
+		RegistrationUUID: suite.registration.UUID,
+		MimeType:         v1.MimeTypeNativeReport,
+		RawReport:        suite.rawReport,
+	}
+	require.NoError(suite.T(), cReport.Compress())
+
+	cRpJSON, err := cReport.ToJSON()
+	require.NoError(suite.T(), err)
+
+	statusChange := &job.StatusChange{
+		JobID:   "the-job-id",
+		Status:  "Success",
+		CheckIn: string(cRpJSON),
+		Metadata: &job.StatsInfo{
+			Revision: (int64)(10001),
+		},
+	}
+
+	hash := checkInHash(statusChange.CheckIn)
+	mgr := suite.c.(*basicController).manager.(*MockReportManager)
+	mgr.On("GetByCheckInHash", "rp-uuid-001", hash).Return(nil, nil).Once()
+	mgr.On("CheckIn", "rp-uuid-001", suite.rawReport, (int64)(10001), hash, mock.Anything).Return((int64)(1), nil).Once()
+
+	err = suite.c.HandleJobHooks("the-uuid-123", statusChange)
+	require.NoError(suite.T(), err)
+	mgr.AssertCalled(suite.T(), "CheckIn", "rp-uuid-001", suite.rawReport, (int64)(10001), hash, mock.Anything)
+}
+
+// TestScanControllerHandleJobHooksProducer tests the producer of the report is checked in along with the report
+func (suite *ControllerTestSuite) TestScanControllerHandleJobHooksProducer() {
+	cReport := &sca.CheckInReport{
+		Kind:             sca.CheckInKindReport,
+		Digest:           "digest-code",
+		RegistrationUUID: suite.registration.UUID,
+		MimeType:         v1.MimeTypeNativeReport,
+		RawReport:        suite.rawReport,
+		SchemaVersion:    sca.CheckInSchemaVersion,
+		JobID:            "the-job-id-in-check-in",
+		ScannerName:      "Clair",
+		ScannerVersion:   "0.1.0",
+	}
+
+	cRpJSON, err := cReport.ToJSON()
+	require.NoError(suite.T(), err)
+
+	statusChange := &job.StatusChange{
+		JobID:   "the-job-id",
+		Status:  "Running",
+		CheckIn: string(cRpJSON),
+		Metadata: &job.StatsInfo{
+			Revision: (int64)(10003),
+		},
+	}
+
+	producer := &scan.Producer{
+		SchemaVersion:  sca.CheckInSchemaVersion,
+		JobID:          "the-job-id-in-check-in",
+		ScannerName:    "Clair",
+		ScannerVersion: "0.1.0",
+	}
+
+	hash := checkInHash(statusChange.CheckIn)
+	mgr := suite.c.(*basicController).manager.(*MockReportManager)
+	mgr.On("GetByCheckInHash", "rp-uuid-001", hash).Return(nil, nil).Once()
+	mgr.On("CheckIn", "rp-uuid-001", suite.rawReport, (int64)(10003), hash, producer).Return((int64)(1), nil).Once()
+
+	err = suite.c.HandleJobHooks("the-uuid-123", statusChange)
+	require.NoError(suite.T(), err)
+	mgr.AssertCalled(suite.T(), "CheckIn", "rp-uuid-001", suite.rawReport, (int64)(10003), hash, producer)
+}
+
+// TestScanControllerMultiArchReports tests the reports of the platforms are checked in separately
+// and merged into one summary
+func (suite *ControllerTestSuite) TestScanControllerMultiArchReports() {
+	artifact := &v1.Artifact{
+		NamespaceID: suite.artifact.NamespaceID,
+		Repository:  suite.artifact.Repository,
+		Tag:         "multi-arch",
+		Digest:      "digest-index",
+		MimeType:    v1.MimeTypeDockerArtifact,
+	}
+
+	reports := make([]*scan.Report, 0, 2)
+	for _, p := range []string{"linux/amd64", "linux/arm64"} {
+		reports = append(reports, &scan.Report{
+			UUID:             "rp-uuid-" + p,
+			Digest:           artifact.Digest,
+			RegistrationUUID: suite.registration.UUID,
+			MimeType:         v1.MimeTypeNativeReport,
+			Status:           "Success",
+			StatusCode:       3,
+			Report:           suite.rawReport,
+			StartTime:        time.Now(),
+			EndTime:          time.Now().Add(2 * time.Second),
+			Platform:         p,
+		})
+	}
+
+	sc := &MockScannerController{}
+	sc.On("GetScannerAssignments", artifact.NamespaceID).Return([]sapi.Assignment{}, nil)
+	sc.On("SelectForArtifact", []sapi.Assignment{}, artifact).Return(suite.registration, nil)
+
+	mgr := &MockReportManager{}
+	mgr.On("GetBy", artifact.Digest, suite.registration.UUID, []string{v1.MimeTypeNativeReport}).Return(reports, nil)
+	mgr.On("GetByCheckInHash", mock.Anything, mock.Anything).Return(nil, nil)
+	mgr.On("CheckIn", mock.Anything, suite.rawReport, (int64)(10004), mock.Anything, mock.Anything).Return((int64)(1), nil)
+
+	c := *(suite.c.(*basicController))
+	c.sc = sc
+	c.manager = mgr
+
+	for _, p := range []string{"linux/arm64", "linux/amd64"} {
+		cReport := &sca.CheckInReport{
+			Kind:             sca.CheckInKindReport,
+			Digest:           artifact.Digest,
+			RegistrationUUID: suite.registration.UUID,
+			MimeType:         v1.MimeTypeNativeReport,
+			RawReport:        suite.rawReport,
+			Platform:         p,
+			SchemaVersion:    sca.CheckInSchemaVersion,
+		}
+		cRpJSON, err := cReport.ToJSON()
+		require.NoError(suite.T(), err)
+
+		err = c.HandleJobHooks("the-uuid-"+p, &job.StatusChange{
+			JobID:   "the-job-id-" + p,
+			Status:  "Success",
+			CheckIn: cRpJSON,
+			Metadata: &job.StatsInfo{
+				Revision: (int64)(10004),
+			},
+		})
+		require.NoError(suite.T(), err)
+
+		// The report of the platform is checked in
+		mgr.AssertCalled(suite.T(), "CheckIn", "rp-uuid-"+p, suite.rawReport, (int64)(10004), mock.Anything, mock.Anything)
+	}
+	mgr.AssertNumberOfCalls(suite.T(), "CheckIn", 2)
+
+	sums, err := c.GetSummary(artifact, []string{v1.MimeTypeNativeReport})
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(sums))
+
+	sum, ok := sums[v1.MimeTypeNativeReport].(*vuln.NativeReportSummary)
+	require.True(suite.T(), ok)
+	suite.Equal(vuln.High, sum.Severity)
+	suite.Equal("Success", sum.ScanStatus)
+	suite.Equal(2, sum.Summary.Total)
+	suite.Equal(2, sum.Summary.Summary[vuln.High])
+	suite.Equal(2, len(sum.Platforms))
+	suite.Equal(1, sum.Platforms["linux/arm64"].Summary.Total)
 }
 
 // Mock things
@@ -325,6 +814,22 @@ func (mrm *MockReportManager) UpdateReportData(uuid string, report string, rev i
 	return args.Error(0)
 }
 
+func (mrm *MockReportManager) CheckIn(uuid string, report string, rev int64, hash string, producer *scan.Producer) (int64, error) {
+	args := mrm.Called(uuid, report, rev, hash, producer)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mrm *MockReportManager) GetByCheckInHash(uuid string, hash string) (*scan.Report, error) {
+	args := mrm.Called(uuid, hash)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*scan.Report), args.Error(1)
+}
+
 func (mrm *MockReportManager) GetBy(digest string, registrationUUID string, mimeTypes []string) ([]*scan.Report, error) {
 	args := mrm.Called(digest, registrationUUID, mimeTypes)
 
@@ -458,6 +963,35 @@ func (msc *MockScannerController) IsScannerAvailable(projectID int64) (bool, err
 	return args.Bool(0), args.Error(1)
 }
 
+// SetScannerAssignments ...
+func (msc *MockScannerController) SetScannerAssignments(projectID int64, assignments []sapi.Assignment) error {
+	args := msc.Called(projectID, assignments)
+
+	return args.Error(0)
+}
+
+// GetScannerAssignments ...
+func (msc *MockScannerController) GetScannerAssignments(projectID int64) ([]sapi.Assignment, error) {
+	args := msc.Called(projectID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]sapi.Assignment), args.Error(1)
+}
+
+// SelectForArtifact ...
+func (msc *MockScannerController) SelectForArtifact(assignments []sapi.Assignment, artifact *v1.Artifact) (*scanner.Registration, error) {
+	args := msc.Called(assignments, artifact)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*scanner.Registration), args.Error(1)
+}
+
 // MockJobServiceClient ...
 type MockJobServiceClient struct {
 	mock.Mock
@@ -496,6 +1030,30 @@ func (mjc *MockJobServiceClient) GetExecutions(uuid string) ([]job.Stats, error)
 	return args.Get(0).([]job.Stats), args.Error(1)
 }
 
+// UpdateJobSchedule ...
+func (mjc *MockJobServiceClient) UpdateJobSchedule(uuid, cron string) error {
+	args := mjc.Called(uuid, cron)
+
+	return args.Error(0)
+}
+
+// DeleteJobLog ...
+func (mjc *MockJobServiceClient) DeleteJobLog(uuid string) error {
+	args := mjc.Called(uuid)
+
+	return args.Error(0)
+}
+
+// GetJobLogStream ...
+func (mjc *MockJobServiceClient) GetJobLogStream(uuid, rangeHeader string) (*http.Response, error) {
+	args := mjc.Called(uuid, rangeHeader)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*http.Response), args.Error(1)
+}
+
 // MockRobotController ...
 type MockRobotController struct {
 	mock.Mock
@@ -544,3 +1102,30 @@ func (mrc *MockRobotController) ListRobotAccount(query *q.Query) ([]*model.Robot
 
 	return args.Get(0).([]*model.Robot), args.Error(1)
 }
+
+// UpdateRobotLastAccess ...
+func (mrc *MockRobotController) UpdateRobotLastAccess(id int64, t time.Time) error {
+	args := mrc.Called(id, t)
+
+	return args.Error(0)
+}
+
+// GetRobotAccountByTokenID ...
+func (mrc *MockRobotController) GetRobotAccountByTokenID(tokenID int64) (*model.Robot, error) {
+	args := mrc.Called(tokenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Robot), args.Error(1)
+}
+
+// RotateRobotToken ...
+func (mrc *MockRobotController) RotateRobotToken(r *model.Robot, access []*rbac.Policy, overlap time.Duration) (*model.Robot, error) {
+	args := mrc.Called(r, access, overlap)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Robot), args.Error(1)
+}
@@ -0,0 +1,140 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"context"
+	"time"
+
+	sca "github.com/goharbor/harbor/src/pkg/scan"
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
+	"github.com/goharbor/harbor/src/pkg/scan/report"
+	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/goharbor/harbor/src/pkg/scan/vuln"
+	"github.com/pkg/errors"
+)
+
+const (
+	// BenchmarkProject is the project of the artifact scanned by the benchmark
+	BenchmarkProject = "library"
+	// BenchmarkRepository is the repository of the artifact scanned by the benchmark
+	BenchmarkRepository = "library/alpine"
+	// BenchmarkTag is the tag of the artifact scanned by the benchmark
+	BenchmarkTag = "latest"
+
+	// BenchmarkStatusSuccess means the report is got from the scanner
+	BenchmarkStatusSuccess = "Success"
+	// BenchmarkStatusError means the scanner fails to scan the artifact
+	BenchmarkStatusError = "Error"
+	// BenchmarkStatusTimeout means the scanner doesn't return the report in time
+	BenchmarkStatusTimeout = "Timeout"
+)
+
+// benchmarkTimeout is how long to wait for the report of the benchmark
+var benchmarkTimeout = 5 * time.Minute
+
+// BenchmarkResult is the performance of the scanner scanning the benchmark artifact
+type BenchmarkResult struct {
+	ScannerName string `json:"scanner_name"`
+	DurationMS  int64  `json:"duration_ms"`
+	CVECount    int    `json:"cve_count"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BenchmarkScanner ...
+func (bc *basicController) BenchmarkScanner(reg *scanner.Registration, testDigest string) (*BenchmarkResult, error) {
+	if reg == nil {
+		return nil, errors.New("nil registration to benchmark")
+	}
+
+	pid, err := bc.projectID(BenchmarkProject)
+	if err != nil {
+		return nil, errors.Wrap(err, "scan controller: benchmark scanner")
+	}
+
+	client, err := bc.clientPool.Get(reg)
+	if err != nil {
+		return nil, errors.Wrap(err, "scan controller: benchmark scanner")
+	}
+
+	externalURL, err := bc.config(configRegistryEndpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "scan controller: benchmark scanner")
+	}
+
+	// Make authorization from a robot account which lives as long as the benchmark
+	authorization, err := bc.makeAuthorization(pid, BenchmarkRepository, int64(benchmarkTimeout/time.Second))
+	if err != nil {
+		return nil, errors.Wrap(err, "scan controller: benchmark scanner")
+	}
+
+	req := &v1.ScanRequest{
+		Registry: &v1.Registry{
+			URL:           externalURL,
+			Authorization: authorization,
+		},
+		Artifact: &v1.Artifact{
+			NamespaceID: pid,
+			Repository:  BenchmarkRepository,
+			Tag:         BenchmarkTag,
+			Digest:      testDigest,
+			MimeType:    v1.MimeTypeDockerArtifact,
+		},
+	}
+
+	result := &BenchmarkResult{
+		ScannerName: reg.Name,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), benchmarkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rawReport, err := submitAndWait(ctx, client, req)
+	elapsed := time.Since(start)
+	result.DurationMS = int64(elapsed / time.Millisecond)
+	if err != nil {
+		result.Status = BenchmarkStatusError
+		if elapsed >= benchmarkTimeout {
+			result.Status = BenchmarkStatusTimeout
+		}
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	data, err := report.ResolveData(v1.MimeTypeNativeReport, []byte(rawReport))
+	if err != nil {
+		result.Status = BenchmarkStatusError
+		result.Error = err.Error()
+		return result, nil
+	}
+	if rp, ok := data.(*vuln.Report); ok {
+		result.CVECount = len(rp.Vulnerabilities)
+	}
+	result.Status = BenchmarkStatusSuccess
+
+	return result, nil
+}
+
+// submitAndWait submits the scan request and waits for the native report
+func submitAndWait(ctx context.Context, client v1.Client, req *v1.ScanRequest) (string, error) {
+	resp, err := client.SubmitScan(ctx, req)
+	if err != nil {
+		return "", errors.Wrap(err, "submit scan request")
+	}
+
+	return sca.WaitForReport(ctx, client, resp.ID, v1.MimeTypeNativeReport, benchmarkTimeout, sca.DefaultReportCheckInterval, nil, nil)
+}
@@ -0,0 +1,134 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/pkg/robot/model"
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
+	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/goharbor/harbor/src/pkg/scan/vuln"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newMockAdapter starts a scanner adapter which returns the report after the delay,
+// the report is never ready if the delay is negative
+func newMockAdapter(t *testing.T, delay time.Duration) *httptest.Server {
+	rp := &vuln.Report{
+		Severity: vuln.High,
+		Vulnerabilities: []*vuln.VulnerabilityItem{
+			{ID: "CVE-2019-0001", Package: "musl", Severity: vuln.High},
+			{ID: "CVE-2019-0002", Package: "busybox", Severity: vuln.Low},
+		},
+	}
+	data, err := json.Marshal(rp)
+	require.NoError(t, err)
+
+	var (
+		lock      sync.Mutex
+		submitted time.Time
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/scan", func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		submitted = time.Now()
+		lock.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"id":"benchmark-request"}`))
+	})
+	mux.HandleFunc("/api/v1/scan/benchmark-request/report", func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		ready := delay >= 0 && time.Since(submitted) >= delay
+		lock.Unlock()
+		if !ready {
+			w.Header().Set("Refresh-After", "1")
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		_, _ = w.Write(data)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func newBenchmarkController() *basicController {
+	rc := &MockRobotController{}
+	rc.On("CreateRobotAccount", mock.Anything).Return(&model.Robot{
+		Name:  "robot$benchmark",
+		Token: "token",
+	}, nil)
+
+	return &basicController{
+		rc: rc,
+		uuid: func() (string, error) {
+			return "the-uuid-123", nil
+		},
+		config: func(cfg string) (string, error) {
+			return "https://core.com", nil
+		},
+		clientPool: v1.DefaultClientPool,
+		projectID: func(name string) (int64, error) {
+			return 1, nil
+		},
+	}
+}
+
+func TestBenchmarkScanner(t *testing.T) {
+	adapter := newMockAdapter(t, 100*time.Millisecond)
+	defer adapter.Close()
+
+	reg := &scanner.Registration{
+		UUID: "benchmark-scanner",
+		Name: "benchmark-scanner",
+		URL:  adapter.URL,
+	}
+	result, err := newBenchmarkController().BenchmarkScanner(reg, "sha256:alpine")
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, "benchmark-scanner", result.ScannerName)
+	assert.Equal(t, BenchmarkStatusSuccess, result.Status)
+	assert.Equal(t, 2, result.CVECount)
+	assert.True(t, result.DurationMS >= 100)
+}
+
+func TestBenchmarkScannerTimeout(t *testing.T) {
+	timeout := benchmarkTimeout
+	benchmarkTimeout = 500 * time.Millisecond
+	defer func() {
+		benchmarkTimeout = timeout
+	}()
+
+	adapter := newMockAdapter(t, -1)
+	defer adapter.Close()
+
+	reg := &scanner.Registration{
+		UUID: "benchmark-scanner-timeout",
+		Name: "benchmark-scanner-timeout",
+		URL:  adapter.URL,
+	}
+	result, err := newBenchmarkController().BenchmarkScanner(reg, "sha256:alpine")
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, BenchmarkStatusTimeout, result.Status)
+	assert.Equal(t, 0, result.CVECount)
+}
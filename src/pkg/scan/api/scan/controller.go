@@ -17,6 +17,7 @@ package scan
 import (
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/pkg/scan/dao/scan"
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
 	"github.com/goharbor/harbor/src/pkg/scan/report"
 	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
 )
@@ -25,14 +26,15 @@ import (
 // TODO: Here the artifact object is reused the v1 one which is sent to the adapter,
 //  it should be pointed to the general artifact object in future once it's ready.
 type Controller interface {
-	// Scan the given artifact
+	// Scan the given artifact, the fresh reports of the same digest are reused unless it's forced
 	//
 	//   Arguments:
 	//     artifact *v1.Artifact : artifact to be scanned
+	//     options ...Option     : optional scan options, specify if needed
 	//
 	//   Returns:
 	//     error  : non nil error if any errors occurred
-	Scan(artifact *v1.Artifact) error
+	Scan(artifact *v1.Artifact, options ...Option) error
 
 	// GetReport gets the reports for the given artifact identified by the digest
 	//
@@ -45,6 +47,16 @@ type Controller interface {
 	//     error          : non nil error if any errors occurred
 	GetReport(artifact *v1.Artifact, mimeTypes []string) ([]*scan.Report, error)
 
+	// GetReportMimeTypes gets the mime types of the reports stored for the given artifact
+	//
+	//   Arguments:
+	//     artifact *v1.Artifact : the scanned artifact
+	//
+	//   Returns:
+	//     []string : the mime types of the stored reports
+	//     error    : non nil error if any errors occurred
+	GetReportMimeTypes(artifact *v1.Artifact) ([]string, error)
+
 	// GetSummary gets the summaries of the reports with given types.
 	//
 	//   Arguments:
@@ -77,4 +89,16 @@ type Controller interface {
 	//   Returns:
 	//     error  : non nil error if any errors occurred
 	HandleJobHooks(trackID string, change *job.StatusChange) error
+
+	// BenchmarkScanner scans the benchmark artifact with the given scanner directly rather than
+	// by the job service, and measures how long it takes to get the native report.
+	//
+	//   Arguments:
+	//     reg *scanner.Registration : the scanner to benchmark
+	//     testDigest string         : the digest of the benchmark artifact
+	//
+	//   Returns:
+	//     *BenchmarkResult : the performance of the scanner
+	//     error            : non nil error if the benchmark can not be started
+	BenchmarkScanner(reg *scanner.Registration, testDigest string) (*BenchmarkResult, error)
 }
@@ -0,0 +1,41 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+// Options of the scan.
+type Options struct {
+	// If it is set, the artifact is scanned even though its fresh report exists.
+	Force bool
+	// The platforms in the format of `os/architecture[/variant]` to scan for the multi-arch artifact,
+	// all the platforms are scanned if it's empty.
+	Platforms []string
+}
+
+// Option for the scan with func template way.
+type Option func(options *Options)
+
+// WithForce is an option of forcing the scan.
+func WithForce(force bool) Option {
+	return func(options *Options) {
+		options.Force = force
+	}
+}
+
+// WithPlatforms is an option of selecting the platforms of the multi-arch artifact to scan.
+func WithPlatforms(platforms ...string) Option {
+	return func(options *Options) {
+		options.Platforms = platforms
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"encoding/json"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// mediaTypeOCIImageIndex is the media type of the OCI image index, it shares the same
+	// format with the docker manifest list
+	mediaTypeOCIImageIndex = "application/vnd.oci.image.index.v1+json"
+	// mediaTypeOCIImageManifest is the media type of the OCI image manifest
+	mediaTypeOCIImageManifest = "application/vnd.oci.image.manifest.v1+json"
+	// platformUnknown is the OS and architecture of the child manifest which isn't an image, e.g: the attestation
+	platformUnknown = "unknown"
+)
+
+// platformsGetter is a func template which is used to get the platforms of the child manifests
+// of the multi-arch artifact, nil is returned for the single platform artifact.
+type platformsGetter func(artifact *v1.Artifact) ([]*v1.Platform, error)
+
+// getPlatforms gets the platforms of the artifact by pulling its manifest from the registry
+func getPlatforms(artifact *v1.Artifact) ([]*v1.Platform, error) {
+	client, err := coreutils.NewRepositoryClientForUI("harbor-core", artifact.Repository)
+	if err != nil {
+		return nil, errors.Wrap(err, "get platforms")
+	}
+
+	_, mediaType, payload, err := client.PullManifest(artifact.Digest, []string{
+		manifestlist.MediaTypeManifestList,
+		mediaTypeOCIImageIndex,
+		schema2.MediaTypeManifest,
+		mediaTypeOCIImageManifest,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "get platforms")
+	}
+
+	return platformsOf(mediaType, payload)
+}
+
+// platformsOf parses the platforms of the child manifests from the manifest of the index.
+// Nil is returned if the manifest isn't an index.
+func platformsOf(mediaType string, payload []byte) ([]*v1.Platform, error) {
+	if mediaType != manifestlist.MediaTypeManifestList && mediaType != mediaTypeOCIImageIndex {
+		return nil, nil
+	}
+
+	index := &manifestlist.ManifestList{}
+	if err := json.Unmarshal(payload, index); err != nil {
+		return nil, errors.Wrap(err, "parse platforms")
+	}
+
+	platforms := make([]*v1.Platform, 0, len(index.Manifests))
+	existing := make(map[string]bool)
+	for _, m := range index.Manifests {
+		p := &v1.Platform{
+			OS:           m.Platform.OS,
+			Architecture: m.Platform.Architecture,
+			Variant:      m.Platform.Variant,
+		}
+
+		// Skip the child manifests which aren't images and the duplicated platforms
+		if len(p.OS) == 0 || p.OS == platformUnknown || p.Architecture == platformUnknown || existing[p.String()] {
+			continue
+		}
+		existing[p.String()] = true
+
+		platforms = append(platforms, p)
+	}
+
+	return platforms, nil
+}
+
+// selectPlatforms selects the platforms matching the given ones, all the platforms are selected
+// if no one is given.
+func selectPlatforms(platforms []*v1.Platform, selected []string) []*v1.Platform {
+	if len(selected) == 0 {
+		return platforms
+	}
+
+	l := make([]*v1.Platform, 0, len(platforms))
+	for _, p := range platforms {
+		for _, s := range selected {
+			if p.Match(s) {
+				l = append(l, p)
+				break
+			}
+		}
+	}
+
+	return l
+}
@@ -0,0 +1,155 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"encoding/json"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
+	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	proScannerAssignmentsMetaKey = "scanner_assignments"
+)
+
+// Assignment assigns the scanner to the artifacts whose tag matches the pattern
+type Assignment struct {
+	// The doublestar pattern to match the tag of the artifact, e.g: `*-alpine`
+	TagPattern string `json:"tag_pattern"`
+	// The UUID of the assigned scanner registration
+	ScannerUUID string `json:"scanner_uuid"`
+}
+
+// Validate the assignment
+func (a Assignment) Validate() error {
+	if len(a.TagPattern) == 0 {
+		return errors.New("missing tag pattern of scanner assignment")
+	}
+	// Match the pattern against itself to make it parsed as far as possible
+	if _, err := doublestar.Match(a.TagPattern, a.TagPattern); err != nil {
+		return errors.Wrapf(err, "invalid tag pattern %s of scanner assignment", a.TagPattern)
+	}
+	if len(a.ScannerUUID) == 0 {
+		return errors.New("missing scanner UUID of scanner assignment")
+	}
+
+	return nil
+}
+
+// SetScannerAssignments ...
+func (bc *basicController) SetScannerAssignments(projectID int64, assignments []Assignment) error {
+	if projectID == 0 {
+		return errors.New("invalid project ID")
+	}
+
+	for _, a := range assignments {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+		if !bc.RegistrationExists(a.ScannerUUID) {
+			return errors.Errorf("scanner %s of scanner assignment not found", a.ScannerUUID)
+		}
+	}
+
+	m, err := bc.proMetaMgr.Get(projectID, proScannerAssignmentsMetaKey)
+	if err != nil {
+		return errors.Wrap(err, "api controller: set scanner assignments")
+	}
+
+	// Clear the assignments
+	if len(assignments) == 0 {
+		if len(m) > 0 {
+			if err := bc.proMetaMgr.Delete(projectID, proScannerAssignmentsMetaKey); err != nil {
+				return errors.Wrap(err, "api controller: set scanner assignments")
+			}
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(assignments)
+	if err != nil {
+		return errors.Wrap(err, "api controller: set scanner assignments")
+	}
+
+	meta := map[string]string{
+		proScannerAssignmentsMetaKey: string(data),
+	}
+	if len(m) > 0 {
+		err = bc.proMetaMgr.Update(projectID, meta)
+	} else {
+		err = bc.proMetaMgr.Add(projectID, meta)
+	}
+	if err != nil {
+		return errors.Wrap(err, "api controller: set scanner assignments")
+	}
+
+	return nil
+}
+
+// GetScannerAssignments ...
+func (bc *basicController) GetScannerAssignments(projectID int64) ([]Assignment, error) {
+	if projectID == 0 {
+		return nil, errors.New("invalid project ID")
+	}
+
+	m, err := bc.proMetaMgr.Get(projectID, proScannerAssignmentsMetaKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "api controller: get scanner assignments")
+	}
+
+	assignments := make([]Assignment, 0)
+	if data, ok := m[proScannerAssignmentsMetaKey]; ok && len(data) > 0 {
+		if err := json.Unmarshal([]byte(data), &assignments); err != nil {
+			return nil, errors.Wrap(err, "api controller: get scanner assignments")
+		}
+	}
+
+	return assignments, nil
+}
+
+// SelectForArtifact ...
+func (bc *basicController) SelectForArtifact(assignments []Assignment, artifact *v1.Artifact) (*scanner.Registration, error) {
+	if artifact == nil {
+		return nil, errors.New("nil artifact to select scanner for")
+	}
+
+	// The first matched assignment wins
+	for _, a := range assignments {
+		matched, err := doublestar.Match(a.TagPattern, artifact.Tag)
+		if err != nil {
+			return nil, errors.Wrap(err, "api controller: select scanner for artifact")
+		}
+		if !matched {
+			continue
+		}
+
+		registration, err := bc.manager.Get(a.ScannerUUID)
+		if err != nil {
+			return nil, errors.Wrap(err, "api controller: select scanner for artifact")
+		}
+		// The assigned scanner might be deleted or disabled by the admin, try the next one
+		if registration == nil || registration.Disabled {
+			continue
+		}
+
+		return registration, nil
+	}
+
+	// Fall back to the scanner of the project
+	return bc.GetRegistrationByProject(artifact.NamespaceID)
+}
@@ -116,7 +116,14 @@ func (bc *basicController) UpdateRegistration(registration *scanner.Registration
 		return errors.Errorf("default registration %s can not be marked to disabled", registration.UUID)
 	}
 
-	return bc.manager.Update(registration)
+	if err := bc.manager.Update(registration); err != nil {
+		return err
+	}
+
+	// Rebuild the cached clients with the updated settings, e.g: the rotated certificate
+	bc.clientPool.Invalidate(registration.UUID)
+
+	return nil
 }
 
 // SetDefaultRegistration ...
@@ -131,6 +138,8 @@ func (bc *basicController) DeleteRegistration(registrationUUID string) (*scanner
 		return nil, errors.Wrap(err, "api controller: delete registration")
 	}
 
+	bc.clientPool.Invalidate(registrationUUID)
+
 	return registration, nil
 }
 
@@ -15,6 +15,7 @@
 package scanner
 
 import (
+	"context"
 	"testing"
 
 	"github.com/goharbor/harbor/src/common/models"
@@ -34,6 +35,7 @@ type ControllerTestSuite struct {
 	c     *basicController
 	mMgr  *MockScannerManager
 	mMeta *MockProMetaManager
+	mPool *MockClientPool
 
 	sample *scanner.Registration
 }
@@ -78,12 +80,12 @@ func (suite *ControllerTestSuite) SetupSuite() {
 	mc := &MockClient{}
 	mc.On("GetMetadata").Return(m, nil)
 
-	mcp := &MockClientPool{}
-	mcp.On("Get", suite.sample).Return(mc, nil)
+	suite.mPool = &MockClientPool{}
+	suite.mPool.On("Get", suite.sample).Return(mc, nil)
 	suite.c = &basicController{
 		manager:    suite.mMgr,
 		proMetaMgr: suite.mMeta,
-		clientPool: mcp,
+		clientPool: suite.mPool,
 	}
 }
 
@@ -149,8 +151,11 @@ func (suite *ControllerTestSuite) TestUpdateRegistration() {
 	suite.sample.UUID = "uuid"
 	suite.mMgr.On("Update", suite.sample).Return(nil)
 
+	suite.mPool.On("Invalidate", "uuid").Return()
+
 	err := suite.c.UpdateRegistration(suite.sample)
 	require.NoError(suite.T(), err)
+	suite.mPool.AssertCalled(suite.T(), "Invalidate", "uuid")
 }
 
 // TestDeleteRegistration tests DeleteRegistration
@@ -158,11 +163,13 @@ func (suite *ControllerTestSuite) TestDeleteRegistration() {
 	suite.sample.UUID = "uuid"
 	suite.mMgr.On("Get", "uuid").Return(suite.sample, nil)
 	suite.mMgr.On("Delete", "uuid").Return(nil)
+	suite.mPool.On("Invalidate", "uuid").Return()
 
 	r, err := suite.c.DeleteRegistration("uuid")
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), r)
 	assert.Equal(suite.T(), "forUT", r.Name)
+	suite.mPool.AssertCalled(suite.T(), "Invalidate", "uuid")
 }
 
 // TestSetDefaultRegistration tests SetDefaultRegistration
@@ -224,6 +231,82 @@ func (suite *ControllerTestSuite) TestGetRegistrationByProject() {
 	assert.Equal(suite.T(), "forUT", r.Name)
 }
 
+// TestSetScannerAssignments tests SetScannerAssignments
+func (suite *ControllerTestSuite) TestSetScannerAssignments() {
+	var pid int64 = 2000
+	assignments := []Assignment{{TagPattern: "*-alpine", ScannerUUID: "os-scanner"}}
+
+	suite.mMgr.On("Get", "os-scanner").Return(&scanner.Registration{UUID: "os-scanner"}, nil)
+	suite.mMgr.On("Get", "missing-scanner").Return(nil, nil)
+	suite.mMeta.On("Get", pid, []string{proScannerAssignmentsMetaKey}).Return(map[string]string{}, nil)
+	suite.mMeta.On("Add", pid, map[string]string{
+		proScannerAssignmentsMetaKey: `[{"tag_pattern":"*-alpine","scanner_uuid":"os-scanner"}]`,
+	}).Return(nil)
+
+	err := suite.c.SetScannerAssignments(pid, assignments)
+	require.NoError(suite.T(), err)
+
+	// Invalid pattern
+	err = suite.c.SetScannerAssignments(pid, []Assignment{{TagPattern: "[", ScannerUUID: "os-scanner"}})
+	assert.Error(suite.T(), err)
+
+	// Scanner not existing
+	err = suite.c.SetScannerAssignments(pid, []Assignment{{TagPattern: "*", ScannerUUID: "missing-scanner"}})
+	assert.Error(suite.T(), err)
+}
+
+// TestSelectForArtifact tests SelectForArtifact
+func (suite *ControllerTestSuite) TestSelectForArtifact() {
+	osScanner := &scanner.Registration{UUID: "os-scanner", Name: "os"}
+	langScanner := &scanner.Registration{UUID: "lang-scanner", Name: "lang"}
+	disabledScanner := &scanner.Registration{UUID: "disabled-scanner", Name: "disabled", Disabled: true}
+	suite.mMgr.On("Get", "os-scanner").Return(osScanner, nil)
+	suite.mMgr.On("Get", "lang-scanner").Return(langScanner, nil)
+	suite.mMgr.On("Get", "disabled-scanner").Return(disabledScanner, nil)
+	suite.mMgr.On("Get", "deleted-scanner").Return(nil, nil)
+
+	assignments := []Assignment{
+		{TagPattern: "*-deleted", ScannerUUID: "deleted-scanner"},
+		{TagPattern: "*-disabled", ScannerUUID: "disabled-scanner"},
+		{TagPattern: "*-alpine", ScannerUUID: "os-scanner"},
+		{TagPattern: "*-node*", ScannerUUID: "lang-scanner"},
+		{TagPattern: "**", ScannerUUID: "os-scanner"},
+	}
+
+	cases := []struct {
+		tag      string
+		expected string
+	}{
+		// the first matched assignment wins
+		{tag: "1.0-alpine", expected: "os"},
+		{tag: "1.0-node12", expected: "lang"},
+		// deleted or disabled scanner is skipped
+		{tag: "1.0-deleted", expected: "os"},
+		{tag: "1.0-disabled", expected: "os"},
+	}
+	for _, c := range cases {
+		r, err := suite.c.SelectForArtifact(assignments, &v1.Artifact{NamespaceID: 2001, Tag: c.tag})
+		require.NoError(suite.T(), err)
+		require.NotNil(suite.T(), r)
+		assert.Equal(suite.T(), c.expected, r.Name, c.tag)
+	}
+
+	// Fall back to the scanner of the project
+	var pid int64 = 2002
+	suite.mMeta.On("Get", pid, []string{proScannerMetaKey}).Return(map[string]string{}, nil)
+	suite.mMgr.On("GetDefault").Return(suite.sample, nil)
+
+	r, err := suite.c.SelectForArtifact(assignments[:4], &v1.Artifact{NamespaceID: pid, Tag: "latest"})
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), r)
+	assert.Equal(suite.T(), "forUT", r.Name)
+
+	r, err = suite.c.SelectForArtifact(nil, &v1.Artifact{NamespaceID: pid, Tag: "1.0-alpine"})
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), r)
+	assert.Equal(suite.T(), "forUT", r.Name)
+}
+
 // TestPing ...
 func (suite *ControllerTestSuite) TestPing() {
 	meta, err := suite.c.Ping(suite.sample)
@@ -344,6 +427,21 @@ func (mcp *MockClientPool) Get(r *scanner.Registration) (v1.Client, error) {
 	return args.Get(0).(v1.Client), args.Error(1)
 }
 
+// Invalidate clients
+func (mcp *MockClientPool) Invalidate(registrationUUID string) {
+	mcp.Called(registrationUUID)
+}
+
+// Stats of pool
+func (mcp *MockClientPool) Stats() *v1.PoolStats {
+	args := mcp.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+
+	return args.Get(0).(*v1.PoolStats)
+}
+
 // MockClient is defined and referred in other UT cases.
 type MockClient struct {
 	mock.Mock
@@ -360,7 +458,7 @@ func (mc *MockClient) GetMetadata() (*v1.ScannerAdapterMetadata, error) {
 }
 
 // SubmitScan ...
-func (mc *MockClient) SubmitScan(req *v1.ScanRequest) (*v1.ScanResponse, error) {
+func (mc *MockClient) SubmitScan(ctx context.Context, req *v1.ScanRequest) (*v1.ScanResponse, error) {
 	args := mc.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -370,7 +468,7 @@ func (mc *MockClient) SubmitScan(req *v1.ScanRequest) (*v1.ScanResponse, error)
 }
 
 // GetScanReport ...
-func (mc *MockClient) GetScanReport(scanRequestID, reportMIMEType string) (string, error) {
+func (mc *MockClient) GetScanReport(ctx context.Context, scanRequestID, reportMIMEType string) (string, error) {
 	args := mc.Called(scanRequestID, reportMIMEType)
 
 	return args.String(0), args.Error(1)
@@ -145,4 +145,37 @@ type Controller interface {
 	//     bool  : the scanner if configured for the specified project
 	//     error : non nil error if any errors occurred
 	IsScannerAvailable(projectID int64) (bool, error)
+
+	// SetScannerAssignments sets the tag pattern based scanner assignments of the given project.
+	// The existing assignments are cleared if the given assignments are empty.
+	//
+	//   Arguments:
+	//     projectID int64          : the ID of the given project
+	//     assignments []Assignment : the ordered scanner assignments
+	//
+	//   Returns:
+	//     error : non nil error if any errors occurred
+	SetScannerAssignments(projectID int64, assignments []Assignment) error
+
+	// GetScannerAssignments returns the tag pattern based scanner assignments of the given project.
+	//
+	//   Arguments:
+	//     projectID int64 : the ID of the given project
+	//
+	//   Returns:
+	//     []Assignment : the ordered scanner assignments
+	//     error        : non nil error if any errors occurred
+	GetScannerAssignments(projectID int64) ([]Assignment, error)
+
+	// SelectForArtifact selects the scanner registration of the first assignment whose tag pattern
+	// matches the tag of the artifact. The registration of the project is returned if none matches.
+	//
+	//   Arguments:
+	//     assignments []Assignment : the ordered scanner assignments
+	//     artifact *v1.Artifact    : the artifact to scan
+	//
+	//   Returns:
+	//     *scanner.Registration : the selected scanner registration
+	//     error                 : non nil error if any errors occurred
+	SelectForArtifact(assignments []Assignment, artifact *v1.Artifact) (*scanner.Registration, error)
 }
@@ -17,7 +17,8 @@ package scan
 import "time"
 
 // Report of the scan.
-// Identified by the `digest`, `registration_uuid` and `mime_type`.
+// Identified by the `digest`, `registration_uuid`, `mime_type` and `platform`.
+// The multi-arch artifact is scanned per platform, the platform is empty for the single platform artifact.
 type Report struct {
 	ID               int64     `orm:"pk;auto;column(id)"`
 	UUID             string    `orm:"unique;column(uuid)"`
@@ -30,10 +31,24 @@ type Report struct {
 	StatusCode       int       `orm:"column(status_code)"`
 	StatusRevision   int64     `orm:"column(status_rev)"`
 	Report           string    `orm:"column(report);type(json)"`
+	CheckInHash      string    `orm:"column(checkin_hash)"`
+	SchemaVersion    int       `orm:"column(schema_version)"`
+	ScannerName      string    `orm:"column(scanner_name)"`
+	ScannerVersion   string    `orm:"column(scanner_version)"`
+	Platform         string    `orm:"column(platform)"`
 	StartTime        time.Time `orm:"column(start_time);auto_now_add;type(datetime)"`
 	EndTime          time.Time `orm:"column(end_time);type(datetime)"`
 }
 
+// Producer of the report checked in, it's recorded for telling how the report is produced
+type Producer struct {
+	// Version of the check-in schema
+	SchemaVersion  int
+	JobID          string
+	ScannerName    string
+	ScannerVersion string
+}
+
 // TableName for Report
 func (r *Report) TableName() string {
 	return "scan_report"
@@ -43,6 +58,6 @@ func (r *Report) TableName() string {
 func (r *Report) TableUnique() [][]string {
 	return [][]string{
 		{"uuid"},
-		{"digest", "registration_uuid", "mime_type"},
+		{"digest", "registration_uuid", "mime_type", "platform"},
 	}
 }
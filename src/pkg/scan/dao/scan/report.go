@@ -103,6 +103,72 @@ func UpdateReportData(uuid string, report string, statusRev int64) error {
 	return nil
 }
 
+// GetScanReportByHash returns the report with the uuid whose data has been checked in with the content hash,
+// nil is returned if there is no such report. The lookup is scoped to the report as the same data may be
+// checked in to different reports, e.g. the reports of the same artifact scanned by different registrations.
+func GetScanReportByHash(uuid string, hash string) (*Report, error) {
+	r := &Report{UUID: uuid, CheckInHash: hash}
+	if err := dao.GetOrmer().Read(r, "uuid", "checkin_hash"); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// CheckInReportData updates the `report` column with the data checked in and records the content
+// hash of the check-in as the idempotency key. The report row is locked to serialize the concurrent
+// check-ins, the data with the same hash is only updated once.
+// The ID of the report and whether the data is updated are returned.
+func CheckInReportData(uuid string, report string, statusRev int64, hash string, producer *Producer) (int64, bool, error) {
+	var (
+		id      int64
+		updated bool
+	)
+	err := dao.WithTransaction(func(o orm.Ormer) error {
+		r := &Report{UUID: uuid}
+		if err := o.ReadForUpdate(r, "uuid"); err != nil {
+			if err == orm.ErrNoRows {
+				return errors.Errorf("no report with uuid %s found", uuid)
+			}
+			return err
+		}
+		id = r.ID
+
+		// already processed
+		if r.CheckInHash == hash {
+			return nil
+		}
+
+		if r.StatusRevision > statusRev {
+			return errors.Errorf("no report with uuid %s updated", uuid)
+		}
+
+		data := make(orm.Params)
+		data["report"] = report
+		data["status_rev"] = statusRev
+		data["checkin_hash"] = hash
+		if producer != nil {
+			data["schema_version"] = producer.SchemaVersion
+			data["scanner_name"] = producer.ScannerName
+			data["scanner_version"] = producer.ScannerVersion
+			if len(producer.JobID) > 0 {
+				data["job_id"] = producer.JobID
+			}
+		}
+		if _, err := o.QueryTable(new(Report)).Filter("uuid", uuid).Update(data); err != nil {
+			return err
+		}
+		updated = true
+
+		return nil
+	})
+
+	return id, updated, err
+}
+
 // UpdateReportStatus updates the report `status` with conditions matched.
 func UpdateReportStatus(trackID string, status string, statusCode int, statusRev int64) error {
 	o := dao.GetOrmer()
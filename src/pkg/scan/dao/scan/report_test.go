@@ -119,6 +119,78 @@ func (suite *ReportTestSuite) TestReportUpdateReportData() {
 	require.Error(suite.T(), err)
 }
 
+// TestReportCheckInReportData tests check in the same report data twice.
+func (suite *ReportTestSuite) TestReportCheckInReportData() {
+	r, err := GetScanReportByHash("uuid", "hash-001")
+	require.NoError(suite.T(), err)
+	require.Nil(suite.T(), r)
+
+	id, updated, err := CheckInReportData("uuid", "{\"a\": 1000}", 1000, "hash-001", nil)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), updated)
+
+	id2, updated, err := CheckInReportData("uuid", "{\"a\": 1000}", 1000, "hash-001", nil)
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), updated)
+	assert.Equal(suite.T(), id, id2)
+
+	l, err := ListReports(nil)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, len(l))
+	assert.Equal(suite.T(), "hash-001", l[0].CheckInHash)
+
+	r, err = GetScanReportByHash("uuid", "hash-001")
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), r)
+	assert.Equal(suite.T(), id, r.ID)
+
+	// The same data checked in to another report isn't treated as processed
+	r2 := &Report{
+		UUID:             "uuid2",
+		TrackID:          "track-uuid2",
+		Digest:           "digest1001",
+		RegistrationUUID: "ruuid2",
+		MimeType:         v1.MimeTypeNativeReport,
+		Status:           job.PendingStatus.String(),
+		StatusCode:       job.PendingStatus.Code(),
+	}
+	_, err = CreateReport(r2)
+	require.NoError(suite.T(), err)
+	defer func() {
+		require.NoError(suite.T(), DeleteReport("uuid2"))
+	}()
+	r, err = GetScanReportByHash("uuid2", "hash-001")
+	require.NoError(suite.T(), err)
+	require.Nil(suite.T(), r)
+	_, updated, err = CheckInReportData("uuid2", "{\"a\": 1000}", 1000, "hash-001", nil)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), updated)
+
+	_, _, err = CheckInReportData("uuid", "{\"a\": 900}", 900, "hash-002", nil)
+	require.Error(suite.T(), err)
+}
+
+// TestReportCheckInReportDataProducer tests the producer is checked in along with the report data.
+func (suite *ReportTestSuite) TestReportCheckInReportDataProducer() {
+	producer := &Producer{
+		SchemaVersion:  2,
+		JobID:          "job-id-002",
+		ScannerName:    "Clair",
+		ScannerVersion: "0.1.0",
+	}
+	_, updated, err := CheckInReportData("uuid", "{\"a\": 1001}", 1001, "hash-003", producer)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), updated)
+
+	r, err := GetScanReportByHash("uuid", "hash-003")
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), r)
+	assert.Equal(suite.T(), 2, r.SchemaVersion)
+	assert.Equal(suite.T(), "job-id-002", r.JobID)
+	assert.Equal(suite.T(), "Clair", r.ScannerName)
+	assert.Equal(suite.T(), "0.1.0", r.ScannerVersion)
+}
+
 // TestReportUpdateStatus tests update the report status.
 func (suite *ReportTestSuite) TestReportUpdateStatus() {
 	err := UpdateReportStatus("track-uuid", job.RunningStatus.String(), job.RunningStatus.Code(), 1000)
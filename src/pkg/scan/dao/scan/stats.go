@@ -0,0 +1,61 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/jobservice/job"
+)
+
+// ScanDurationStats is the distribution of the durations of the completed scans done by one scanner
+type ScanDurationStats struct {
+	RegistrationUUID string  `orm:"column(registration_uuid)" json:"registration_uuid"`
+	ScannerName      string  `orm:"column(scanner_name)" json:"scanner_name"`
+	TotalScans       int64   `orm:"column(total_scans)" json:"total_scans"`
+	AvgDurationMS    float64 `orm:"column(avg_duration_ms)" json:"avg_duration_ms"`
+	P50DurationMS    float64 `orm:"column(p50_duration_ms)" json:"p50_duration_ms"`
+	P95DurationMS    float64 `orm:"column(p95_duration_ms)" json:"p95_duration_ms"`
+	P99DurationMS    float64 `orm:"column(p99_duration_ms)" json:"p99_duration_ms"`
+}
+
+// GetScanDurationStats returns the duration distribution of the completed scans of the artifacts
+// under the project, grouped by the scanner. The percentiles are interpolated between the
+// adjacent durations and the scanner name falls back to the registration UUID if the scanner
+// has been removed.
+func GetScanDurationStats(projectID int64) ([]*ScanDurationStats, error) {
+	sql := `SELECT d.registration_uuid,
+			COALESCE(s.name, d.registration_uuid) AS scanner_name,
+			COUNT(*) AS total_scans,
+			AVG(d.duration) AS avg_duration_ms,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY d.duration) AS p50_duration_ms,
+			PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY d.duration) AS p95_duration_ms,
+			PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY d.duration) AS p99_duration_ms
+		FROM (
+			SELECT r.registration_uuid,
+				(EXTRACT(EPOCH FROM (r.end_time - r.start_time)) * 1000)::float8 AS duration
+			FROM scan_report AS r
+			WHERE r.status = ? AND r.end_time >= r.start_time
+				AND EXISTS (SELECT 1 FROM artifact AS a WHERE a.digest = r.digest AND a.project_id = ?)
+		) AS d
+		LEFT JOIN scanner_registration AS s ON s.uuid = d.registration_uuid
+		GROUP BY d.registration_uuid, s.name
+		ORDER BY scanner_name`
+
+	stats := []*ScanDurationStats{}
+	if _, err := dao.GetOrmer().Raw(sql, job.SuccessStatus.String(), projectID).QueryRows(&stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
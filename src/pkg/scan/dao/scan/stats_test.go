@@ -0,0 +1,145 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
+	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// StatsTestSuite is test suite of testing the scan duration stats.
+type StatsTestSuite struct {
+	suite.Suite
+
+	artifactIDs []int64
+	reportUUIDs []string
+}
+
+// TestStats is the entry of StatsTestSuite.
+func TestStats(t *testing.T) {
+	suite.Run(t, &StatsTestSuite{})
+}
+
+// SetupSuite prepares the artifacts, the scanner and the scan reports.
+func (suite *StatsTestSuite) SetupSuite() {
+	dao.PrepareTestForPostgresSQL()
+
+	_, err := scanner.AddRegistration(&scanner.Registration{
+		UUID: "stats-scanner-a",
+		Name: "stats-scanner-a",
+		URL:  "http://stats-scanner-a:8080",
+	})
+	require.NoError(suite.T(), err)
+
+	// the artifacts of project 1 and another project
+	for i, pid := range []int64{1, 1, 1, 2} {
+		id, err := dao.AddArtifact(&models.Artifact{
+			PID:    pid,
+			Repo:   fmt.Sprintf("stats/repo%d", i),
+			Tag:    "latest",
+			Digest: fmt.Sprintf("stats-digest-%d", i),
+			Kind:   "Docker-Image",
+		})
+		require.NoError(suite.T(), err)
+		suite.artifactIDs = append(suite.artifactIDs, id)
+	}
+
+	start := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+	// scanner a scans the artifact 0 of project 1 in 100ms, 200ms, ..., 1000ms
+	for i := 1; i <= 10; i++ {
+		suite.addReport("stats-digest-0", "stats-scanner-a", fmt.Sprintf("mime-type-%d", i),
+			job.SuccessStatus, start, start.Add(time.Duration(i*100)*time.Millisecond))
+	}
+	// the scanner b has been removed
+	suite.addReport("stats-digest-1", "stats-scanner-b", v1.MimeTypeNativeReport,
+		job.SuccessStatus, start, start.Add(300*time.Millisecond))
+	// the failed scan isn't counted
+	suite.addReport("stats-digest-2", "stats-scanner-a", v1.MimeTypeNativeReport,
+		job.ErrorStatus, start, start.Add(time.Hour))
+	// the scan of the artifact under project 2 isn't counted
+	suite.addReport("stats-digest-3", "stats-scanner-a", v1.MimeTypeNativeReport,
+		job.SuccessStatus, start, start.Add(time.Hour))
+}
+
+func (suite *StatsTestSuite) addReport(digest, registrationUUID, mimeType string, status job.Status, start, end time.Time) {
+	uuid := fmt.Sprintf("%s-%s-%s", digest, registrationUUID, mimeType)
+	_, err := CreateReport(&Report{
+		UUID:             uuid,
+		TrackID:          uuid,
+		Digest:           digest,
+		RegistrationUUID: registrationUUID,
+		MimeType:         mimeType,
+		Status:           status.String(),
+		StatusCode:       status.Code(),
+	})
+	require.NoError(suite.T(), err)
+	suite.reportUUIDs = append(suite.reportUUIDs, uuid)
+
+	// the start time is always set to now when inserting
+	_, err = dao.GetOrmer().QueryTable(new(Report)).Filter("uuid", uuid).Update(orm.Params{
+		"start_time": start,
+		"end_time":   end,
+	})
+	require.NoError(suite.T(), err)
+}
+
+// TearDownSuite clears env for test suite.
+func (suite *StatsTestSuite) TearDownSuite() {
+	for _, uuid := range suite.reportUUIDs {
+		_ = DeleteReport(uuid)
+	}
+	for _, id := range suite.artifactIDs {
+		_ = dao.DeleteArtifact(id)
+	}
+	_ = scanner.DeleteRegistration("stats-scanner-a")
+}
+
+// TestGetScanDurationStats tests GetScanDurationStats.
+func (suite *StatsTestSuite) TestGetScanDurationStats() {
+	stats, err := GetScanDurationStats(1)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 2, len(stats))
+
+	a := stats[0]
+	assert.Equal(suite.T(), "stats-scanner-a", a.RegistrationUUID)
+	assert.Equal(suite.T(), "stats-scanner-a", a.ScannerName)
+	assert.Equal(suite.T(), int64(10), a.TotalScans)
+	assert.InDelta(suite.T(), 550, a.AvgDurationMS, 0.01)
+	assert.InDelta(suite.T(), 550, a.P50DurationMS, 0.01)
+	assert.InDelta(suite.T(), 955, a.P95DurationMS, 0.01)
+	assert.InDelta(suite.T(), 991, a.P99DurationMS, 0.01)
+
+	b := stats[1]
+	assert.Equal(suite.T(), "stats-scanner-b", b.ScannerName)
+	assert.Equal(suite.T(), int64(1), b.TotalScans)
+	assert.InDelta(suite.T(), 300, b.AvgDurationMS, 0.01)
+	assert.InDelta(suite.T(), 300, b.P50DurationMS, 0.01)
+	assert.InDelta(suite.T(), 300, b.P99DurationMS, 0.01)
+
+	stats, err = GetScanDurationStats(3)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, len(stats))
+}
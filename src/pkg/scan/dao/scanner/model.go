@@ -20,11 +20,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/pkg/scan/rest/auth"
 
 	"github.com/pkg/errors"
 )
 
+// MaxRetriesLimit is the upper limit of the retries of the scan job, which is bound by the max fails
+// allowed by the job service
+var MaxRetriesLimit = int(job.MaxFailsLimit) - 1
+
 // Registration represents a named configuration for invoking a scanner via its adapter.
 // UUID will be used to track the scanner.Endpoint as unique ID
 type Registration struct {
@@ -47,16 +52,43 @@ type Registration struct {
 	// Http connection settings
 	SkipCertVerify bool `orm:"column(skip_cert_verify);default(false)" json:"skip_certVerify"`
 
+	// Report polling settings in seconds, the defaults of the scan job are used if they're not set
+	ReportCheckTimeout  int64 `orm:"column(report_check_timeout);default(0)" json:"report_check_timeout,omitempty"`
+	ReportCheckInterval int64 `orm:"column(report_check_interval);default(0)" json:"report_check_interval,omitempty"`
+
+	// The max number of the scans submitted but unfinished at the same time, 0 means unlimited.
+	// It's enforced by each jobservice instance separately, so the scanner may receive up to
+	// the limit times the number of the jobservice instances
+	MaxConcurrentScans int64 `orm:"column(max_concurrent_scans);default(0)" json:"max_concurrent_scans,omitempty"`
+
+	// Retry policy of the scan jobs, the one of the scan job is used if the retries isn't set
+	MaxRetries    int  `orm:"column(max_retries);default(0)" json:"max_retries,omitempty"`
+	RetryDisabled bool `orm:"column(retry_disabled);default(false)" json:"retry_disabled,omitempty"`
+
 	// Extra info about the scanner
 	Scanner string `orm:"-" json:"scanner,omitempty"`
 	Vendor  string `orm:"-" json:"vendor,omitempty"`
 	Version string `orm:"-" json:"version,omitempty"`
+	// The capabilities declared by the scanner, it's passed to the scan job for validating the mime types
+	Capabilities []*Capability `orm:"-" json:"capabilities,omitempty"`
 
 	// Timestamps
 	CreateTime time.Time `orm:"column(create_time);auto_now_add;type(datetime)" json:"create_time"`
 	UpdateTime time.Time `orm:"column(update_time);auto_now;type(datetime)" json:"update_time"`
 }
 
+// Capability is the set of the artifact mime types consumed and the report mime types produced by the scanner,
+// it's aligned with the capability in the metadata of the scanner adapter.
+type Capability struct {
+	ConsumesMimeTypes []string `json:"consumes_mime_types"`
+	ProducesMimeTypes []string `json:"produces_mime_types"`
+}
+
+// Supports checks whether the report with the mime type can be produced for the artifact with the mime type
+func (c *Capability) Supports(artifactMimeType, reportMimeType string) bool {
+	return contains(c.ConsumesMimeTypes, artifactMimeType) && contains(c.ProducesMimeTypes, reportMimeType)
+}
+
 // TableName for Endpoint
 func (r *Registration) TableName() string {
 	return "scanner_registration"
@@ -81,6 +113,16 @@ func (r *Registration) ToJSON() (string, error) {
 	return string(data), nil
 }
 
+// Redacted returns a copy of the registration with the credential material blanked,
+// it's used when the registration is logged
+func (r *Registration) Redacted() *Registration {
+	redacted := *r
+	redacted.AccessCredential = ""
+	redacted.URL = redactURL(r.URL)
+
+	return &redacted
+}
+
 // Validate registration
 func (r *Registration) Validate(checkUUID bool) error {
 	if checkUUID && len(r.UUID) == 0 {
@@ -107,9 +149,45 @@ func (r *Registration) Validate(checkUUID bool) error {
 		return errors.Errorf("access_credential is required for auth type %s", r.Auth)
 	}
 
+	if r.ReportCheckTimeout < 0 || r.ReportCheckInterval < 0 {
+		return errors.New("report_check_timeout and report_check_interval can not be negative")
+	}
+
+	if r.MaxConcurrentScans < 0 {
+		return errors.New("max_concurrent_scans can not be negative")
+	}
+
+	if r.MaxRetries < 0 || r.MaxRetries > MaxRetriesLimit {
+		return errors.Errorf("max_retries must be between 0 and %d", MaxRetriesLimit)
+	}
+
 	return nil
 }
 
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactURL hides the password in the user info of the URL
+func redactURL(u string) string {
+	uri, err := url.Parse(u)
+	if err != nil || uri.User == nil {
+		return u
+	}
+
+	if _, ok := uri.User.Password(); ok {
+		uri.User = url.UserPassword(uri.User.Username(), "xxxxx")
+	}
+
+	return uri.String()
+}
+
 // Check the registration URL with url package
 func checkURL(u string) error {
 	if len(strings.TrimSpace(u)) == 0 {
@@ -16,9 +16,16 @@ package scan
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -37,26 +44,123 @@ const (
 	JobParameterRequest = "scanRequest"
 	// JobParameterMimes ...
 	JobParameterMimes = "mimeTypes"
+	// JobParameterStrict is the optional bool parameter to fail the job once the report of any mime type fails,
+	// otherwise the job succeeds as long as the report of one mime type is checked in
+	JobParameterStrict = "strict"
 
-	checkTimeout       = 30 * time.Minute
-	firstCheckInterval = 2 * time.Second
+	// DefaultReportCheckTimeout is the timeout of polling the report if it's not set in the registration
+	DefaultReportCheckTimeout = 30 * time.Minute
+	// DefaultReportCheckInterval is the interval of the first report check if it's not set in the registration
+	DefaultReportCheckInterval = 2 * time.Second
+
+	// maxRetryBackoff is the max backoff before retrying the transient errors of getting the report
+	maxRetryBackoff = time.Minute
+
+	// CheckInEncodingGzip means the raw report checked in is gzip compressed and base64 encoded
+	CheckInEncodingGzip = "gzip"
+
+	// CheckInSchemaVersion is the version of the report check-in schema produced by the current job
+	CheckInSchemaVersion = 2
+	// CheckInSchemaVersionLegacy is the version of the report check-in without the schema version
+	CheckInSchemaVersionLegacy = 1
 )
 
 // CheckInReport defines model for checking in the scan report with specified mime.
 type CheckInReport struct {
+	// Kind of the check-in, it's CheckInKindReport or empty for the report
+	Kind             string `json:"kind,omitempty"`
 	Digest           string `json:"digest"`
 	RegistrationUUID string `json:"registration_uuid"`
 	MimeType         string `json:"mime_type"`
 	RawReport        string `json:"raw_report"`
+	// Platform of the multi-arch artifact the report is produced for, empty for the single platform artifact
+	Platform string `json:"platform,omitempty"`
+	// Encoding of the raw report, empty means the raw report is kept as it is
+	Encoding string `json:"encoding,omitempty"`
+	// Failures of the other mime types of the same scan keyed by the mime type
+	Failures map[string]string `json:"failures,omitempty"`
+	// Version of the check-in schema, the check-in without it is CheckInSchemaVersionLegacy
+	SchemaVersion int `json:"schema_version,omitempty"`
+	// The job and the scanner producing the report, they're empty in the legacy check-in
+	JobID          string `json:"job_id,omitempty"`
+	ScannerName    string `json:"scanner_name,omitempty"`
+	ScannerVersion string `json:"scanner_version,omitempty"`
+}
+
+// Compress gzip compresses the raw report and encodes it with base64
+func (cir *CheckInReport) Compress() error {
+	if len(cir.Encoding) > 0 {
+		return errors.Errorf("raw report has been encoded with %s", cir.Encoding)
+	}
+
+	buf := &strings.Builder{}
+	// Stream the raw report through the compressor and encoder
+	encoder := base64.NewEncoder(base64.StdEncoding, buf)
+	zw := gzip.NewWriter(encoder)
+	if _, err := zw.Write([]byte(cir.RawReport)); err != nil {
+		return errors.Wrap(err, "compress: CheckInReport")
+	}
+	if err := zw.Close(); err != nil {
+		return errors.Wrap(err, "compress: CheckInReport")
+	}
+	if err := encoder.Close(); err != nil {
+		return errors.Wrap(err, "compress: CheckInReport")
+	}
+
+	cir.RawReport = buf.String()
+	cir.Encoding = CheckInEncodingGzip
+
+	return nil
 }
 
-// FromJSON parse json to CheckInReport
+// Decompress restores the raw report according to its encoding, the raw report without
+// encoding is left as it is
+func (cir *CheckInReport) Decompress() error {
+	switch cir.Encoding {
+	case "":
+		return nil
+	case CheckInEncodingGzip:
+		decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(cir.RawReport))
+		zr, err := gzip.NewReader(decoder)
+		if err != nil {
+			return errors.Wrap(err, "decompress: CheckInReport")
+		}
+		defer func() {
+			_ = zr.Close()
+		}()
+
+		data, err := ioutil.ReadAll(zr)
+		if err != nil {
+			return errors.Wrap(err, "decompress: CheckInReport")
+		}
+
+		cir.RawReport = string(data)
+		cir.Encoding = ""
+
+		return nil
+	default:
+		return errors.Errorf("unsupported raw report encoding %s", cir.Encoding)
+	}
+}
+
+// FromJSON parse json to CheckInReport, the fields missing in the check-in of the previous versions are defaulted
 func (cir *CheckInReport) FromJSON(jsonData string) error {
 	if len(jsonData) == 0 {
 		return errors.New("empty JSON data")
 	}
 
-	return json.Unmarshal([]byte(jsonData), cir)
+	if err := json.Unmarshal([]byte(jsonData), cir); err != nil {
+		return err
+	}
+
+	if len(cir.Kind) == 0 {
+		cir.Kind = CheckInKindReport
+	}
+	if cir.SchemaVersion == 0 {
+		cir.SchemaVersion = CheckInSchemaVersionLegacy
+	}
+
+	return nil
 }
 
 // ToJSON marshal CheckInReport to JSON
@@ -89,15 +193,30 @@ func (j *Job) Validate(params job.Parameters) error {
 		return errors.New("missing parameter of scan job")
 	}
 
-	if _, err := extractRegistration(params); err != nil {
+	r, err := extractRegistration(params)
+	if err != nil {
 		return errors.Wrap(err, "job validate")
 	}
 
-	if _, err := ExtractScanReq(params); err != nil {
+	if _, _, err := reportCheckPolicy(r); err != nil {
 		return errors.Wrap(err, "job validate")
 	}
 
-	if _, err := extractMimeTypes(params); err != nil {
+	req, err := ExtractScanReq(params)
+	if err != nil {
+		return errors.Wrap(err, "job validate")
+	}
+
+	mimes, err := extractMimeTypes(params)
+	if err != nil {
+		return errors.Wrap(err, "job validate")
+	}
+
+	if err := checkMimeTypes(r, req.Artifact.MimeType, mimes); err != nil {
+		return errors.Wrap(err, "job validate")
+	}
+
+	if _, err := extractStrict(params); err != nil {
 		return errors.Wrap(err, "job validate")
 	}
 
@@ -113,11 +232,14 @@ func (j *Job) Run(ctx job.Context, params job.Parameters) error {
 	r, _ := extractRegistration(params)
 	req, _ := ExtractScanReq(params)
 	mimes, _ := extractMimeTypes(params)
+	strict, _ := extractStrict(params)
+	timeout, interval, _ := reportCheckPolicy(r)
 
 	// Print related infos to log
-	printJSONParameter(JobParamRegistration, params[JobParamRegistration].(string), myLogger)
+	printJSONParameter(JobParamRegistration, removeRegistrationAuthInfo(r), myLogger)
 	printJSONParameter(JobParameterRequest, removeAuthInfo(req), myLogger)
 	myLogger.Infof("Report mime types: %v\n", mimes)
+	myLogger.Infof("Report check timeout: %v, first check interval: %v\n", timeout, interval)
 
 	// Submit scan request to the scanner adapter
 	client, err := v1.DefaultClientPool.Get(r)
@@ -125,13 +247,63 @@ func (j *Job) Run(ctx job.Context, params job.Parameters) error {
 		return logAndWrapError(myLogger, err, "scan job: get client")
 	}
 
-	resp, err := client.SubmitScan(req)
+	// The in-flight requests to the scanner adapter are aborted once the job is stopped
+	sysCtx, cancel := context.WithCancel(ctx.SystemContext())
+	defer cancel()
+	go watchStop(sysCtx, ctx, interval, cancel)
+
+	// Wait for the slot if the concurrent scans of the scanner are limited, the job fails
+	// and will be retried later if the slot isn't available within the report check timeout.
+	// The slots are counted by this jobservice instance only
+	if r.MaxConcurrentScans > 0 {
+		myLogger.Infof("At most %d scans are allowed by scanner %s at the same time in this jobservice instance, waiting for the slot", r.MaxConcurrentScans, r.Name)
+	}
+	waitCtx, cancelWait := context.WithTimeout(sysCtx, timeout)
+	release, err := DefaultScanLimiter.Acquire(waitCtx, r)
+	cancelWait()
 	if err != nil {
-		return logAndWrapError(myLogger, err, "scan job: submit scan request")
+		if sysCtx.Err() != nil {
+			myLogger.Info("Scan job is stopped")
+			return nil
+		}
+		return logAndWrapError(myLogger, err, "scan job: wait for the scan slot")
 	}
+	defer release()
+
+	// The retried run of the job resumes polling the reports of the scan request submitted
+	// by the previous run unless the scan request is unknown to the scanner adapter
+	var resp *v1.ScanResponse
+	if id := submittedScanRequest(ctx, req.Artifact.Digest, r.UUID); len(id) > 0 && len(mimes) > 0 {
+		if resumable(sysCtx, client, id, mimes[0]) {
+			myLogger.Infof("Resume polling the reports of scan request %s submitted by the previous run", id)
+			resp = &v1.ScanResponse{ID: id}
+		} else {
+			myLogger.Infof("Scan request %s submitted by the previous run is unknown to the scanner, submit a new one", id)
+		}
+	}
+
+	if resp == nil {
+		resp, err = client.SubmitScan(sysCtx, req)
+		if err != nil {
+			if sysCtx.Err() != nil {
+				myLogger.Info("Scan job is stopped")
+				return nil
+			}
+			return logAndWrapError(myLogger, err, "scan job: submit scan request")
+		}
+	}
+
+	// The job ID is checked in along with the reports
+	jobID := currentJobID(ctx)
+
+	// Report the progress to make the scan status visible
+	progress := newProgressTracker(ctx, mimes)
+	progress.submitted(req.Artifact.Digest, r.UUID, resp.ID)
 
 	// For collecting errors
 	errs := make([]error, len(mimes))
+	// For collecting the reports to check in
+	cirs := make([]*CheckInReport, len(mimes))
 
 	// Concurrently retrieving report by different mime types
 	wg := &sync.WaitGroup{}
@@ -144,73 +316,109 @@ func (j *Job) Run(ctx job.Context, params job.Parameters) error {
 			// Log info
 			myLogger.Infof("Get report for mime type: %s", m)
 
-			// Loop check if the report is ready
-			tm := time.NewTimer(firstCheckInterval)
-			defer tm.Stop()
-
-			for {
-				select {
-				case t := <-tm.C:
-					myLogger.Debugf("check scan report for mime %s at %s", m, t.Format("2006/01/02 15:04:05"))
-
-					rawReport, err := client.GetScanReport(resp.ID, m)
-					if err != nil {
-						// Not ready yet
-						if notReadyErr, ok := err.(*v1.ReportNotReadyError); ok {
-							// Reset to the new check interval
-							tm.Reset(time.Duration(notReadyErr.RetryAfter) * time.Second)
-							myLogger.Infof("Report with mime type %s is not ready yet, retry after %d seconds", m, notReadyErr.RetryAfter)
-
-							continue
-						}
+			rawReport, err := WaitForReport(sysCtx, client, resp.ID, m, timeout, interval, func(retryAfter int) {
+				myLogger.Infof("Report with mime type %s is not ready yet, retry after %d seconds", m, retryAfter)
+				progress.update(m, PhaseAnalyzing)
+			}, func(retries int, err error, backoff time.Duration) {
+				myLogger.Warningf("Get report with mime type %s error: %s, retry #%d after %v", m, err, retries, backoff)
+			})
+			if err != nil {
+				// Terminated by system or stopped
+				if sysCtx.Err() != nil {
+					return
+				}
 
-						errs[i] = errors.Wrap(err, fmt.Sprintf("check scan report with mime type %s", m))
-						return
-					}
+				progress.update(m, PhaseError)
+				errs[i] = errors.Wrap(err, fmt.Sprintf("check scan report with mime type %s", m))
+				return
+			}
 
-					// Make sure the data is aligned with the v1 spec.
-					if _, err = report.ResolveData(m, []byte(rawReport)); err != nil {
-						errs[i] = errors.Wrap(err, "scan job: resolve report data")
-						return
-					}
+			progress.update(m, PhaseFetchingReport)
 
-					// Check in
-					cir := &CheckInReport{
-						Digest:           req.Artifact.Digest,
-						RegistrationUUID: r.UUID,
-						MimeType:         m,
-						RawReport:        rawReport,
-					}
+			// Make sure the data is aligned with the v1 spec.
+			if _, err = report.ResolveData(m, []byte(rawReport)); err != nil {
+				progress.update(m, PhaseError)
+				errs[i] = errors.Wrap(err, "scan job: resolve report data")
+				return
+			}
 
-					var (
-						jsonData string
-						er       error
-					)
-					if jsonData, er = cir.ToJSON(); er == nil {
-						if er = ctx.Checkin(jsonData); er == nil {
-							// Done!
-							myLogger.Infof("Report with mime type %s is checked in", m)
-							return
-						}
-					}
+			cir := &CheckInReport{
+				Kind:             CheckInKindReport,
+				Digest:           req.Artifact.Digest,
+				RegistrationUUID: r.UUID,
+				MimeType:         m,
+				RawReport:        rawReport,
+				Platform:         req.Artifact.Platform.String(),
+				SchemaVersion:    CheckInSchemaVersion,
+				JobID:            jobID,
+				ScannerName:      r.Scanner,
+				ScannerVersion:   r.Version,
+			}
 
-					// Send error and exit
-					errs[i] = errors.Wrap(er, fmt.Sprintf("check in scan report for mime type %s", m))
-					return
-				case <-ctx.SystemContext().Done():
-					// Terminated by system
-					return
-				case <-time.After(checkTimeout):
-					errs[i] = errors.New("check scan report timeout")
-					return
-				}
+			// Compress the raw report to reduce the size of the check-in data
+			if err := cir.Compress(); err != nil {
+				progress.update(m, PhaseError)
+				errs[i] = errors.Wrap(err, fmt.Sprintf("check in scan report for mime type %s", m))
+				return
 			}
+
+			cirs[i] = cir
 		}(i, mt)
 	}
 
 	// Wait for all the retrieving routines are completed
 	wg.Wait()
 
+	if sysCtx.Err() != nil {
+		myLogger.Info("Scan job is stopped")
+	}
+
+	// The failures of the mime types are checked in along with the reports
+	failures := make(map[string]string)
+	for i, e := range errs {
+		if e != nil {
+			failures[mimes[i]] = e.Error()
+		}
+	}
+
+	// Check in
+	checkedIn := 0
+	for i, cir := range cirs {
+		if cir == nil {
+			continue
+		}
+
+		if len(failures) > 0 {
+			cir.Failures = failures
+		}
+
+		jsonData, er := cir.ToJSON()
+		if er == nil {
+			er = ctx.Checkin(jsonData)
+		}
+		if er != nil {
+			progress.update(cir.MimeType, PhaseError)
+			errs[i] = errors.Wrap(er, fmt.Sprintf("check in scan report for mime type %s", cir.MimeType))
+			continue
+		}
+
+		// Done!
+		myLogger.Infof("Report with mime type %s is checked in", cir.MimeType)
+		progress.update(cir.MimeType, PhaseCheckedIn)
+		checkedIn++
+	}
+
+	// The job succeeds with the partial failures logged if it's not strict
+	if !strict && checkedIn > 0 {
+		for i, e := range errs {
+			if e != nil {
+				myLogger.Warningf("Scan with mime type %s failed: %s", mimes[i], e)
+			}
+		}
+
+		return nil
+	}
+
 	// Merge errors
 	for _, e := range errs {
 		if e != nil {
@@ -230,6 +438,195 @@ func (j *Job) Run(ctx job.Context, params job.Parameters) error {
 	return err
 }
 
+// currentJobID returns the ID of the running job, empty is returned if it's unknown
+func currentJobID(ctx job.Context) string {
+	tracker := ctx.Tracker()
+	if tracker == nil {
+		return ""
+	}
+
+	stats := tracker.Job()
+	if stats == nil || stats.Info == nil {
+		return ""
+	}
+
+	return stats.Info.JobID
+}
+
+// resumable checks whether the scan request is still known to the scanner adapter, the scan request
+// which is not found or gone (e.g. expired) isn't resumable. The other errors are left to the polling.
+func resumable(ctx context.Context, client v1.Client, scanRequestID, mimeType string) bool {
+	_, err := client.GetScanReport(ctx, scanRequestID, mimeType)
+	if e, ok := errors.Cause(err).(*v1.UnexpectedStatusCodeError); ok {
+		return e.Code != http.StatusNotFound && e.Code != http.StatusGone
+	}
+
+	return true
+}
+
+// WaitForReport checks the report with the mime type of the scan request until it's ready.
+// The check starts after the interval and then follows the retry after hint of the scanner adapter,
+// the notReady func is called with the hint every time the report isn't ready if it's not nil.
+// The transient errors are retried with the exponential backoff and jitter, the retry func is called
+// with the retry count, the error and the backoff every time before retrying if it's not nil.
+// An error is returned once the timeout is reached.
+func WaitForReport(ctx context.Context, client v1.Client, scanRequestID, mimeType string,
+	timeout, interval time.Duration, notReady func(retryAfter int), retry func(retries int, err error, backoff time.Duration)) (string, error) {
+	// Loop check if the report is ready
+	tm := time.NewTimer(interval)
+	defer tm.Stop()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	retries := 0
+	var lastErr error
+
+	for {
+		select {
+		case <-tm.C:
+			rawReport, err := client.GetScanReport(ctx, scanRequestID, mimeType)
+			if err != nil {
+				// Not ready yet
+				if notReadyErr, ok := err.(*v1.ReportNotReadyError); ok {
+					// Reset to the new check interval
+					tm.Reset(time.Duration(notReadyErr.RetryAfter) * time.Second)
+					if notReady != nil {
+						notReady(notReadyErr.RetryAfter)
+					}
+
+					continue
+				}
+
+				// Stopped
+				if ctx.Err() != nil {
+					return "", ctx.Err()
+				}
+
+				if v1.IsRetryableError(err) {
+					retries++
+					lastErr = err
+					backoff := retryBackoff(interval, retries)
+					tm.Reset(backoff)
+					if retry != nil {
+						retry(retries, err, backoff)
+					}
+
+					continue
+				}
+
+				if retries > 0 {
+					return "", errors.Wrap(err, fmt.Sprintf("failed after %d retries", retries))
+				}
+
+				return "", err
+			}
+
+			return rawReport, nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-deadline.C:
+			if lastErr != nil {
+				return "", errors.Wrap(lastErr, fmt.Sprintf("check scan report timeout after %d retries", retries))
+			}
+
+			return "", errors.New("check scan report timeout")
+		}
+	}
+}
+
+// retryBackoff returns the backoff before the nth retry, it's doubled from the base for each retry
+// and capped by maxRetryBackoff, a random jitter in [backoff/2, backoff) is applied to spread the retries.
+func retryBackoff(base time.Duration, retries int) time.Duration {
+	backoff := base
+	for i := 1; i < retries && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+
+	half := backoff / 2
+	if half <= 0 {
+		return backoff
+	}
+
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// watchStop checks the operation command of the job every interval and calls the cancel func
+// once the job is stopped, it exits when the context is done
+func watchStop(ctx context.Context, jobCtx job.Context, interval time.Duration, cancel context.CancelFunc) {
+	tk := time.NewTicker(interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			if cmd, ok := jobCtx.OPCommand(); ok && cmd.IsStop() {
+				cancel()
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkMimeTypes checks the report mime types can be produced for the artifact by the capabilities
+// of the registration, the check is skipped if the registration doesn't declare the capabilities
+func checkMimeTypes(r *scanner.Registration, artifactMimeType string, mimes []string) error {
+	if len(r.Capabilities) == 0 {
+		return nil
+	}
+
+	unsupported := make([]string, 0)
+	for _, m := range mimes {
+		supported := false
+		for _, ca := range r.Capabilities {
+			if ca != nil && ca.Supports(artifactMimeType, m) {
+				supported = true
+				break
+			}
+		}
+
+		if !supported {
+			unsupported = append(unsupported, m)
+		}
+	}
+
+	if len(unsupported) > 0 {
+		return errors.Errorf("report mime types %s are not supported by scanner %s for artifact with mime type %s",
+			strings.Join(unsupported, ", "), r.Name, artifactMimeType)
+	}
+
+	return nil
+}
+
+// reportCheckPolicy returns the timeout and the first check interval of polling the report,
+// the defaults are used if they're not set in the registration
+func reportCheckPolicy(r *scanner.Registration) (timeout, interval time.Duration, err error) {
+	if r.ReportCheckTimeout < 0 {
+		return 0, 0, errors.Errorf("invalid report check timeout %d", r.ReportCheckTimeout)
+	}
+	if r.ReportCheckInterval < 0 {
+		return 0, 0, errors.Errorf("invalid report check interval %d", r.ReportCheckInterval)
+	}
+
+	timeout, interval = DefaultReportCheckTimeout, DefaultReportCheckInterval
+	if r.ReportCheckTimeout > 0 {
+		timeout = time.Duration(r.ReportCheckTimeout) * time.Second
+	}
+	if r.ReportCheckInterval > 0 {
+		interval = time.Duration(r.ReportCheckInterval) * time.Second
+	}
+	if timeout < interval {
+		return 0, 0, errors.Errorf("report check timeout %v is less than the interval %v", timeout, interval)
+	}
+
+	return timeout, interval, nil
+}
+
 // ExtractScanReq extracts the scan request from the job parameters.
 func ExtractScanReq(params job.Parameters) (*v1.ScanRequest, error) {
 	v, ok := params[JobParameterRequest]
@@ -279,6 +676,15 @@ func printPrettyJSON(in []byte, logger logger.Interface) {
 	logger.Infof("%s\n", out.String())
 }
 
+func removeRegistrationAuthInfo(r *scanner.Registration) string {
+	str, err := r.Redacted().ToJSON()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "scan job: remove registration auth"))
+	}
+
+	return str
+}
+
 func removeAuthInfo(sr *v1.ScanRequest) string {
 	req := &v1.ScanRequest{
 		Artifact: sr.Artifact,
@@ -323,6 +729,24 @@ func extractRegistration(params job.Parameters) (*scanner.Registration, error) {
 	return r, nil
 }
 
+func extractStrict(params job.Parameters) (bool, error) {
+	v, ok := params[JobParameterStrict]
+	if !ok {
+		return false, nil
+	}
+
+	strict, ok := v.(bool)
+	if !ok {
+		return false, errors.Errorf(
+			"malformed job parameter '%s', expecting bool but got %s",
+			JobParameterStrict,
+			reflect.TypeOf(v).String(),
+		)
+	}
+
+	return strict, nil
+}
+
 func extractMimeTypes(params job.Parameters) ([]string, error) {
 	v, ok := params[JobParameterMimes]
 	if !ok {
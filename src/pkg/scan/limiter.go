@@ -0,0 +1,91 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"context"
+	"sync"
+
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
+	"github.com/pkg/errors"
+)
+
+// DefaultScanLimiter is the default limiter shared by the scan jobs of the jobservice process.
+var DefaultScanLimiter = NewScanLimiter()
+
+// ScanLimiter limits the number of the scans submitted but unfinished at the same time
+// for each scanner registration. The slots live in the memory of the process, so the limit
+// is per jobservice instance rather than global when multiple jobservice instances are deployed.
+type ScanLimiter struct {
+	lock  sync.Mutex
+	slots map[string]*scanSlots
+}
+
+// scanSlots is the semaphore of the scanner registration
+type scanSlots struct {
+	limit int64
+	ch    chan struct{}
+}
+
+// NewScanLimiter news a scan limiter.
+func NewScanLimiter() *ScanLimiter {
+	return &ScanLimiter{
+		slots: make(map[string]*scanSlots),
+	}
+}
+
+// Acquire a slot of the scanner registration, it waits until a slot is released or the context is done.
+// The returned func must be called to release the slot once the scan is finished.
+// There is no limit if the max concurrent scans of the registration isn't positive.
+func (sl *ScanLimiter) Acquire(ctx context.Context, r *scanner.Registration) (func(), error) {
+	if r == nil {
+		return nil, errors.New("nil scanner registration")
+	}
+
+	if r.MaxConcurrentScans <= 0 {
+		return func() {}, nil
+	}
+
+	s := sl.slotsOf(r)
+	select {
+	case s.ch <- struct{}{}:
+		once := &sync.Once{}
+		return func() {
+			once.Do(func() {
+				<-s.ch
+			})
+		}, nil
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "scan limiter: acquire")
+	}
+}
+
+// slotsOf returns the slots of the registration, the slots are recreated if the limit is changed,
+// the scans holding the previous slots release them as usual.
+func (sl *ScanLimiter) slotsOf(r *scanner.Registration) *scanSlots {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	s, ok := sl.slots[r.UUID]
+	if !ok || s.limit != r.MaxConcurrentScans {
+		s = &scanSlots{
+			limit: r.MaxConcurrentScans,
+			ch:    make(chan struct{}, r.MaxConcurrentScans),
+		}
+		sl.slots[r.UUID] = s
+	}
+
+	return s
+}
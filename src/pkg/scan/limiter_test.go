@@ -0,0 +1,75 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScanLimiter tests the slots of the registration are limited
+func TestScanLimiter(t *testing.T) {
+	sl := NewScanLimiter()
+	r := &scanner.Registration{UUID: "limited", MaxConcurrentScans: 2}
+
+	release1, err := sl.Acquire(context.TODO(), r)
+	require.NoError(t, err)
+	release2, err := sl.Acquire(context.TODO(), r)
+	require.NoError(t, err)
+
+	// No slot left
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	_, err = sl.Acquire(ctx, r)
+	require.Error(t, err)
+
+	// The other registrations are not affected
+	release, err := sl.Acquire(context.TODO(), &scanner.Registration{UUID: "other", MaxConcurrentScans: 1})
+	require.NoError(t, err)
+	release()
+
+	// Released twice by mistake doesn't free more slots
+	release1()
+	release1()
+	release3, err := sl.Acquire(context.TODO(), r)
+	require.NoError(t, err)
+
+	ctx2, cancel2 := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel2()
+	_, err = sl.Acquire(ctx2, r)
+	require.Error(t, err)
+
+	release2()
+	release3()
+}
+
+// TestScanLimiterUnlimited tests there is no limit if the max concurrent scans isn't set
+func TestScanLimiterUnlimited(t *testing.T) {
+	sl := NewScanLimiter()
+	r := &scanner.Registration{UUID: "unlimited"}
+
+	for i := 0; i < 10; i++ {
+		_, err := sl.Acquire(context.TODO(), r)
+		require.NoError(t, err)
+	}
+
+	_, err := sl.Acquire(context.TODO(), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,176 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CheckInKindReport is the kind of the check-in carrying the scan report
+	CheckInKindReport = "report"
+	// CheckInKindProgress is the kind of the check-in carrying the progress of the scan job
+	CheckInKindProgress = "progress"
+
+	// PhaseSubmitted means the scan request has been accepted by the scanner adapter
+	PhaseSubmitted = "submitted"
+	// PhaseAnalyzing means the scanner adapter is still analyzing the artifact
+	PhaseAnalyzing = "analyzing"
+	// PhaseFetchingReport means the report is ready and being fetched and checked in
+	PhaseFetchingReport = "fetching-report"
+	// PhaseCheckedIn means the report has been checked in
+	PhaseCheckedIn = "checked-in"
+	// PhaseError means the report can not be got
+	PhaseError = "error"
+)
+
+// CheckInProgress defines model for checking in the progress of the scan job.
+type CheckInProgress struct {
+	Kind  string `json:"kind"`
+	Phase string `json:"phase"`
+	// Phases of the reports keyed by the mime types
+	MimeTypes map[string]string `json:"mime_types"`
+	// Seconds elapsed since the job started
+	Elapsed int64 `json:"elapsed"`
+	// The scan request submitted to the scanner adapter, it's used by the retried run
+	// of the same job to resume polling the reports instead of submitting a new one
+	Digest           string `json:"digest,omitempty"`
+	RegistrationUUID string `json:"registration_uuid,omitempty"`
+	ScanRequestID    string `json:"scan_request_id,omitempty"`
+}
+
+// ToJSON marshal CheckInProgress to JSON
+func (cip *CheckInProgress) ToJSON() (string, error) {
+	jsonData, err := json.Marshal(cip)
+	if err != nil {
+		return "", errors.Wrap(err, "To JSON: CheckInProgress")
+	}
+
+	return string(jsonData), nil
+}
+
+// CheckInKindOf returns the kind of the check-in data, the data without kind is a report
+// which is checked in by the job of the previous versions.
+func CheckInKindOf(jsonData string) (string, error) {
+	v := struct {
+		Kind string `json:"kind"`
+	}{}
+	if err := json.Unmarshal([]byte(jsonData), &v); err != nil {
+		return "", errors.Wrap(err, "check in kind")
+	}
+
+	if len(v.Kind) == 0 {
+		return CheckInKindReport, nil
+	}
+
+	return v.Kind, nil
+}
+
+// progressTracker tracks the phases of the reports and checks in the progress once it's changed
+type progressTracker struct {
+	ctx   job.Context
+	start time.Time
+
+	lock     sync.Mutex
+	progress *CheckInProgress
+}
+
+func newProgressTracker(ctx job.Context, mimeTypes []string) *progressTracker {
+	mts := make(map[string]string, len(mimeTypes))
+	for _, m := range mimeTypes {
+		mts[m] = PhaseSubmitted
+	}
+
+	return &progressTracker{
+		ctx:   ctx,
+		start: time.Now(),
+		progress: &CheckInProgress{
+			Kind:      CheckInKindProgress,
+			Phase:     PhaseSubmitted,
+			MimeTypes: mts,
+		},
+	}
+}
+
+// submitted records the scan request and checks in the progress after the scan request is accepted
+func (pt *progressTracker) submitted(digest, registrationUUID, scanRequestID string) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+
+	pt.progress.Digest = digest
+	pt.progress.RegistrationUUID = registrationUUID
+	pt.progress.ScanRequestID = scanRequestID
+	pt.checkIn()
+}
+
+// update sets the phase of the report with the mime type and checks in the progress,
+// the phase of the job is the latest phase of the reports
+func (pt *progressTracker) update(mimeType, phase string) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+
+	pt.progress.MimeTypes[mimeType] = phase
+	pt.progress.Phase = phase
+	pt.checkIn()
+}
+
+// checkIn must be called with the lock held, the failure isn't fatal to the job
+func (pt *progressTracker) checkIn() {
+	pt.progress.Elapsed = int64(time.Since(pt.start) / time.Second)
+	jsonData, err := pt.progress.ToJSON()
+	if err == nil {
+		err = pt.ctx.Checkin(jsonData)
+	}
+
+	if err != nil {
+		pt.ctx.GetLogger().Warningf("Check in scan progress error: %s", err)
+	}
+}
+
+// submittedScanRequest returns the ID of the scan request of the artifact submitted to the scanner
+// by the previous run of the same job, it's recorded in the last progress checked in by that run.
+// Empty is returned if there is no such submission.
+func submittedScanRequest(ctx job.Context, digest, registrationUUID string) string {
+	tracker := ctx.Tracker()
+	if tracker == nil {
+		return ""
+	}
+
+	stats := tracker.Job()
+	if stats == nil || stats.Info == nil || len(stats.Info.CheckIn) == 0 {
+		return ""
+	}
+
+	kind, err := CheckInKindOf(stats.Info.CheckIn)
+	if err != nil || kind != CheckInKindProgress {
+		return ""
+	}
+
+	cip := &CheckInProgress{}
+	if err := json.Unmarshal([]byte(stats.Info.CheckIn), cip); err != nil {
+		return ""
+	}
+
+	if cip.Digest != digest || cip.RegistrationUUID != registrationUUID {
+		return ""
+	}
+
+	return cip.ScanRequestID
+}
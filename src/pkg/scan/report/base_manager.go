@@ -45,10 +45,11 @@ func (bm *basicManager) Create(r *scan.Report) (string, error) {
 
 	// Check if there is existing report copy
 	// Limit only one scanning performed by a given provider on the specified artifact can be there
-	kws := make(map[string]interface{}, 3)
+	kws := make(map[string]interface{}, 4)
 	kws["digest"] = r.Digest
 	kws["registration_uuid"] = r.RegistrationUUID
 	kws["mime_type"] = []interface{}{r.MimeType}
+	kws["platform"] = r.Platform
 
 	existingCopies, err := scan.ListReports(&q.Query{
 		PageNumber: 1,
@@ -192,3 +193,34 @@ func (bm *basicManager) UpdateReportData(uuid string, report string, rev int64)
 
 	return scan.UpdateReportData(uuid, report, rev)
 }
+
+// CheckIn ...
+func (bm *basicManager) CheckIn(uuid string, report string, rev int64, hash string, producer *scan.Producer) (int64, error) {
+	if len(uuid) == 0 {
+		return 0, errors.New("missing uuid")
+	}
+
+	if len(report) == 0 {
+		return 0, errors.New("missing report JSON data")
+	}
+
+	if len(hash) == 0 {
+		return 0, errors.New("missing check-in hash")
+	}
+
+	id, _, err := scan.CheckInReportData(uuid, report, rev, hash, producer)
+	return id, err
+}
+
+// GetByCheckInHash ...
+func (bm *basicManager) GetByCheckInHash(uuid string, hash string) (*scan.Report, error) {
+	if len(uuid) == 0 {
+		return nil, errors.New("empty uuid")
+	}
+
+	if len(hash) == 0 {
+		return nil, errors.New("empty check-in hash")
+	}
+
+	return scan.GetScanReportByHash(uuid, hash)
+}
@@ -89,6 +89,28 @@ func (suite *TestManagerSuite) TestManagerCreateWithExisting() {
 	suite.rpUUID = uuid
 }
 
+// TestManagerCreatePerPlatform tests the reports of the platforms of the multi-arch artifact are kept separately.
+func (suite *TestManagerSuite) TestManagerCreatePerPlatform() {
+	rp := &scan.Report{
+		Digest:           "d1000",
+		RegistrationUUID: "ruuid",
+		MimeType:         v1.MimeTypeNativeReport,
+		TrackID:          "tid003",
+		Platform:         "linux/arm64",
+	}
+
+	uuid, err := suite.m.Create(rp)
+	require.NoError(suite.T(), err)
+	require.NotEmpty(suite.T(), uuid)
+	defer func() {
+		require.NoError(suite.T(), scan.DeleteReport(uuid))
+	}()
+
+	l, err := suite.m.GetBy("d1000", "ruuid", []string{v1.MimeTypeNativeReport})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(l))
+}
+
 // TestManagerGet tests the get method.
 func (suite *TestManagerSuite) TestManagerGet() {
 	sr, err := suite.m.Get(suite.rpUUID)
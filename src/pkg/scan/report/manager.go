@@ -64,6 +64,33 @@ type Manager interface {
 	//
 	UpdateReportData(uuid string, report string, rev int64) error
 
+	// Check in the report data (with JSON format) of the given report with the content hash.
+	// The data with the same content hash is only processed once.
+	//
+	//  Arguments:
+	//    uuid string    : uuid to identify the report
+	//    report string  : report JSON data
+	//    rev int64      : data revision info
+	//    hash string    : content hash of the check-in data
+	//    producer *scan.Producer : producer of the report, nil if it's unknown
+	//
+	//  Returns:
+	//    int64  : ID of the report
+	//    error  : non nil error if any errors occurred
+	//
+	CheckIn(uuid string, report string, rev int64, hash string, producer *scan.Producer) (int64, error)
+
+	// Get the report which the data with the given content hash has been checked in.
+	//
+	//  Arguments:
+	//    uuid string : uuid of the report
+	//    hash string : content hash of the check-in data
+	//
+	//  Returns:
+	//    *scan.Report : scan report, nil if not found
+	//    error        : non nil error if any errors occurred
+	GetByCheckInHash(uuid string, hash string) (*scan.Report, error)
+
 	// Get the reports for the given digest by other properties.
 	//
 	//  Arguments:
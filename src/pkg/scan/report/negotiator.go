@@ -0,0 +1,150 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentNegotiator selects the report mime types acceptable by the client
+// from the mime types of the available reports.
+type ContentNegotiator struct {
+	available []string
+}
+
+// NewContentNegotiator creates a negotiator with the mime types of the available reports.
+func NewContentNegotiator(available ...string) *ContentNegotiator {
+	return &ContentNegotiator{
+		available: available,
+	}
+}
+
+// mediaRange is the parsed media range in the Accept header
+type mediaRange struct {
+	mediaType string
+	params    map[string]string
+	quality   float64
+}
+
+// Negotiate returns the available mime types matching the values of the Accept header,
+// the more preferred one comes first. Empty list is returned if nothing matched.
+func (c *ContentNegotiator) Negotiate(accepts ...string) []string {
+	ranges := parseAccept(accepts...)
+
+	type candidate struct {
+		mimeType string
+		quality  float64
+	}
+	candidates := make([]candidate, 0)
+	for _, av := range c.available {
+		mt, params, err := mime.ParseMediaType(av)
+		if err != nil {
+			continue
+		}
+
+		quality := -1.0
+		for _, r := range ranges {
+			if r.matches(mt, params) && r.quality > quality {
+				quality = r.quality
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{mimeType: av, quality: quality})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	mimes := make([]string, 0, len(candidates))
+	for _, cd := range candidates {
+		mimes = append(mimes, cd.mimeType)
+	}
+	return mimes
+}
+
+// matches checks whether the media type with the parameters is in the range
+func (r *mediaRange) matches(mediaType string, params map[string]string) bool {
+	if r.mediaType != "*/*" {
+		if strings.HasSuffix(r.mediaType, "/*") {
+			if !strings.HasPrefix(mediaType, strings.TrimSuffix(r.mediaType, "*")) {
+				return false
+			}
+		} else if r.mediaType != mediaType {
+			return false
+		}
+	}
+
+	for k, v := range r.params {
+		if params[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAccept parses the values of the Accept header, the invalid media ranges are ignored
+func parseAccept(accepts ...string) []*mediaRange {
+	ranges := make([]*mediaRange, 0)
+	for _, accept := range accepts {
+		for _, v := range splitAccept(accept) {
+			mt, params, err := mime.ParseMediaType(v)
+			if err != nil {
+				continue
+			}
+
+			r := &mediaRange{
+				mediaType: mt,
+				params:    params,
+				quality:   1,
+			}
+			if q, ok := params["q"]; ok {
+				delete(params, "q")
+				if r.quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+// splitAccept splits the Accept header value into media ranges,
+// the commas inside the quoted parameter values are kept
+func splitAccept(accept string) []string {
+	values := make([]string, 0)
+	quoted := false
+	start := 0
+	for i, c := range accept {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				values = append(values, strings.TrimSpace(accept[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(accept[start:]); len(last) > 0 {
+		values = append(values, last)
+	}
+	return values
+}
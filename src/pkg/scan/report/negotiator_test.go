@@ -0,0 +1,56 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"testing"
+
+	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+const trivyRawReport = "application/vnd.aquasec.trivy.report.os-pkgs.json.v1"
+
+func TestNegotiateNativeReport(t *testing.T) {
+	n := NewContentNegotiator(trivyRawReport, v1.MimeTypeNativeReport)
+	assert.Equal(t, []string{v1.MimeTypeNativeReport}, n.Negotiate(v1.MimeTypeNativeReport))
+	// the parameters are case insensitive and the spaces are ignored
+	assert.Equal(t, []string{v1.MimeTypeNativeReport},
+		n.Negotiate("application/vnd.scanner.adapter.vuln.report.harbor+json;Version=1.0"))
+	// the version doesn't match
+	assert.Equal(t, []string{}, n.Negotiate("application/vnd.scanner.adapter.vuln.report.harbor+json; version=2.0"))
+}
+
+func TestNegotiateRawReport(t *testing.T) {
+	n := NewContentNegotiator(v1.MimeTypeNativeReport, trivyRawReport)
+	assert.Equal(t, []string{trivyRawReport}, n.Negotiate(trivyRawReport))
+}
+
+func TestNegotiateNotAcceptable(t *testing.T) {
+	n := NewContentNegotiator(v1.MimeTypeNativeReport, trivyRawReport)
+	assert.Equal(t, []string{}, n.Negotiate("application/xml"))
+	assert.Equal(t, []string{}, n.Negotiate("invalid mime type"))
+	assert.Equal(t, []string{}, NewContentNegotiator().Negotiate("*/*"))
+}
+
+func TestNegotiateWildcardAndQuality(t *testing.T) {
+	n := NewContentNegotiator(v1.MimeTypeNativeReport, trivyRawReport)
+	assert.Equal(t, []string{v1.MimeTypeNativeReport, trivyRawReport}, n.Negotiate("*/*"))
+	assert.Equal(t, []string{v1.MimeTypeNativeReport, trivyRawReport}, n.Negotiate("application/*"))
+	assert.Equal(t, []string{trivyRawReport, v1.MimeTypeNativeReport},
+		n.Negotiate(trivyRawReport+", */*;q=0.5"))
+	assert.Equal(t, []string{trivyRawReport},
+		n.Negotiate(v1.MimeTypeNativeReport+";q=0", trivyRawReport+";q=0.8"))
+}
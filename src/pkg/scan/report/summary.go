@@ -16,6 +16,7 @@ package report
 
 import (
 	"reflect"
+	"sort"
 
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/pkg/scan/dao/scan"
@@ -66,6 +67,26 @@ func GenerateSummary(r *scan.Report, options ...Option) (interface{}, error) {
 	return g(r, options...)
 }
 
+// SupportedMergers declares mappings between mime type and summary merger func.
+var SupportedMergers = map[string]SummaryMerger{
+	v1.MimeTypeNativeReport: MergeNativeSummaries,
+}
+
+// MergeSummaries is a helper function to merge the summaries of the reports
+// with the given mime type produced for the platforms of the multi-arch artifact.
+func MergeSummaries(mimeType string, summaries map[string]interface{}) (interface{}, error) {
+	m, ok := SupportedMergers[mimeType]
+	if !ok {
+		return nil, errors.Errorf("no merger bound with mime type %s", mimeType)
+	}
+
+	return m(summaries)
+}
+
+// SummaryMerger is a func template which used to merge the report summaries
+// keyed by the platform for relevant mime type.
+type SummaryMerger func(summaries map[string]interface{}) (interface{}, error)
+
 // SummaryGenerator is a func template which used to generated report
 // summary for relevant mime type.
 type SummaryGenerator func(r *scan.Report, options ...Option) (interface{}, error)
@@ -150,3 +171,89 @@ func GenerateNativeSummary(r *scan.Report, options ...Option) (interface{}, erro
 
 	return sum, nil
 }
+
+// MergeNativeSummaries merges the native report summaries of the platforms.
+// The overall severity is the highest one of the platforms and the numbers of the vulnerabilities
+// are added up, so the vulnerability found in several platforms is counted several times.
+// The status is the least progressed one and the summaries of the platforms are kept as well.
+func MergeNativeSummaries(summaries map[string]interface{}) (interface{}, error) {
+	platforms := make([]string, 0, len(summaries))
+	for p := range summaries {
+		platforms = append(platforms, p)
+	}
+	sort.Strings(platforms)
+
+	merged := &vuln.NativeReportSummary{
+		Platforms: make(map[string]*vuln.NativeReportSummary, len(summaries)),
+	}
+	// The CVEs bypassed by the whitelist in several platforms are listed once
+	bypassed := make(CVESet)
+	for _, p := range platforms {
+		sum, ok := summaries[p].(*vuln.NativeReportSummary)
+		if !ok {
+			return nil, errors.Errorf("type mismatch: expect *vuln.NativeReportSummary but got %s", reflect.TypeOf(summaries[p]))
+		}
+		merged.Platforms[p] = sum
+
+		merged.ScanStatus = mergeStatus(merged.ScanStatus, sum.ScanStatus)
+		// The severity is empty if the platform isn't scanned successfully
+		if len(sum.Severity) > 0 && (len(merged.Severity) == 0 || sum.Severity.Code() > merged.Severity.Code()) {
+			merged.Severity = sum.Severity
+		}
+
+		if sum.Summary != nil {
+			if merged.Summary == nil {
+				merged.Summary = &vuln.VulnerabilitySummary{
+					Summary: make(vuln.SeveritySummary),
+				}
+			}
+
+			merged.Summary.Total += sum.Summary.Total
+			for sev, num := range sum.Summary.Summary {
+				merged.Summary.Summary[sev] += num
+			}
+		}
+
+		for _, cve := range sum.CVEBypassed {
+			if !bypassed.Contains(cve) {
+				bypassed[cve] = struct{}{}
+				merged.CVEBypassed = append(merged.CVEBypassed, cve)
+			}
+		}
+
+		if !sum.StartTime.IsZero() && (merged.StartTime.IsZero() || sum.StartTime.Before(merged.StartTime)) {
+			merged.StartTime = sum.StartTime
+		}
+		if sum.EndTime.After(merged.EndTime) {
+			merged.EndTime = sum.EndTime
+		}
+	}
+	merged.Duration = merged.EndTime.Unix() - merged.StartTime.Unix()
+
+	return merged, nil
+}
+
+// mergeStatus returns the status of the merged summaries, the running one is kept till all are completed
+// and the error one is kept among the completed ones.
+func mergeStatus(s1, s2 string) string {
+	if len(s1) == 0 {
+		return s2
+	}
+
+	st1, st2 := job.Status(s1), job.Status(s2)
+	if c := st1.Compare(st2); c != 0 {
+		if c < 0 {
+			return s1
+		}
+		return s2
+	}
+
+	// Both are completed
+	for _, st := range []job.Status{job.ErrorStatus, job.StoppedStatus} {
+		if st1 == st || st2 == st {
+			return st.String()
+		}
+	}
+
+	return s1
+}
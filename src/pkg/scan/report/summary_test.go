@@ -129,3 +129,89 @@ func (suite *SummaryTestSuite) TestSummaryGenerateSummaryWrongMime() {
 	_, err := GenerateSummary(suite.r)
 	require.Error(suite.T(), err)
 }
+
+// TestSummaryMergeSummaries tests merging the summaries of the platforms of the multi-arch artifact
+func (suite *SummaryTestSuite) TestSummaryMergeSummaries() {
+	now := time.Now()
+	amd64 := &vuln.NativeReportSummary{
+		ReportID:   "r-uuid-amd64",
+		ScanStatus: "Success",
+		Severity:   vuln.Medium,
+		Summary: &vuln.VulnerabilitySummary{
+			Total:   2,
+			Summary: vuln.SeveritySummary{vuln.Medium: 1, vuln.Low: 1},
+		},
+		CVEBypassed: []string{"2019-0980-0909"},
+		StartTime:   now,
+		EndTime:     now.Add(time.Minute),
+	}
+	arm64 := &vuln.NativeReportSummary{
+		ReportID:   "r-uuid-arm64",
+		ScanStatus: "Success",
+		Severity:   vuln.High,
+		Summary: &vuln.VulnerabilitySummary{
+			Total:   1,
+			Summary: vuln.SeveritySummary{vuln.High: 1},
+		},
+		CVEBypassed: []string{"2019-0980-0909"},
+		StartTime:   now.Add(-time.Minute),
+		EndTime:     now.Add(2 * time.Minute),
+	}
+
+	merged, err := MergeSummaries(v1.MimeTypeNativeReport, map[string]interface{}{
+		"linux/amd64": amd64,
+		"linux/arm64": arm64,
+	})
+	require.NoError(suite.T(), err)
+
+	sum, ok := merged.(*vuln.NativeReportSummary)
+	require.True(suite.T(), ok)
+	suite.Equal(vuln.High, sum.Severity)
+	suite.Equal("Success", sum.ScanStatus)
+	suite.Equal(3, sum.Summary.Total)
+	suite.Equal(vuln.SeveritySummary{vuln.High: 1, vuln.Medium: 1, vuln.Low: 1}, sum.Summary.Summary)
+	suite.Equal([]string{"2019-0980-0909"}, sum.CVEBypassed)
+	suite.Equal(arm64.StartTime, sum.StartTime)
+	suite.Equal(arm64.EndTime, sum.EndTime)
+	suite.Equal(int64(180), sum.Duration)
+	suite.Equal(amd64, sum.Platforms["linux/amd64"])
+	suite.Equal(arm64, sum.Platforms["linux/arm64"])
+
+	// The platform being scanned has no vulnerability summary yet
+	merged, err = MergeSummaries(v1.MimeTypeNativeReport, map[string]interface{}{
+		"linux/amd64": amd64,
+		"linux/arm64": &vuln.NativeReportSummary{ScanStatus: "Running"},
+	})
+	require.NoError(suite.T(), err)
+	sum = merged.(*vuln.NativeReportSummary)
+	suite.Equal("Running", sum.ScanStatus)
+	suite.Equal(vuln.Medium, sum.Severity)
+	suite.Equal(2, sum.Summary.Total)
+
+	_, err = MergeSummaries("wrong-mime", map[string]interface{}{"linux/amd64": amd64})
+	suite.Error(err)
+
+	_, err = MergeSummaries(v1.MimeTypeNativeReport, map[string]interface{}{"linux/amd64": "not a summary"})
+	suite.Error(err)
+}
+
+// TestSummaryMergeStatus tests the status of the merged summaries
+func (suite *SummaryTestSuite) TestSummaryMergeStatus() {
+	cases := []struct {
+		s1, s2   string
+		expected string
+	}{
+		{"", "Success", "Success"},
+		{"Success", "Success", "Success"},
+		{"Success", "Pending", "Pending"},
+		{"Running", "Pending", "Pending"},
+		{"Error", "Running", "Running"},
+		{"Success", "Error", "Error"},
+		{"Stopped", "Success", "Stopped"},
+		{"Stopped", "Error", "Error"},
+	}
+
+	for _, c := range cases {
+		suite.Equal(c.expected, mergeStatus(c.s1, c.s2), "%s + %s", c.s1, c.s2)
+	}
+}
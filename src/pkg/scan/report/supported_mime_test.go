@@ -77,3 +77,24 @@ func (suite *SupportedMimesSuite) TestResolveData() {
 		return
 	})
 }
+
+// TestResolveDataSBOM tests the SBOM is validated and kept as it is.
+func (suite *SupportedMimesSuite) TestResolveDataSBOM() {
+	obj, err := ResolveData(v1.MimeTypeSBOMCycloneDX, []byte(`{"bomFormat":"CycloneDX","specVersion":"1.4","components":[]}`))
+	require.NoError(suite.T(), err)
+	suite.Nil(obj)
+
+	_, err = ResolveData(v1.MimeTypeSBOMCycloneDX, []byte(`{"bomFormat":"unknown"}`))
+	suite.Error(err)
+
+	_, err = ResolveData(v1.MimeTypeSBOMSPDX, []byte(`{"spdxVersion":"SPDX-2.2","SPDXID":"SPDXRef-DOCUMENT"}`))
+	suite.NoError(err)
+
+	_, err = ResolveData(v1.MimeTypeSBOMSPDX, []byte(`not json`))
+	suite.Error(err)
+
+	// No validator for the raw report
+	obj, err = ResolveData(v1.MimeTypeRawReport, []byte(`not json`))
+	require.NoError(suite.T(), err)
+	suite.Nil(obj)
+}
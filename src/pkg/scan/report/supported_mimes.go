@@ -16,9 +16,11 @@ package report
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 
 	v1 "github.com/goharbor/harbor/src/pkg/scan/rest/v1"
+	"github.com/goharbor/harbor/src/pkg/scan/sbom"
 	"github.com/goharbor/harbor/src/pkg/scan/vuln"
 	"github.com/pkg/errors"
 )
@@ -29,15 +31,41 @@ var SupportedMimes = map[string]interface{}{
 	v1.MimeTypeNativeReport: (*vuln.Report)(nil),
 }
 
+// ValidatedMimes indicates what mime types are checked with the structure before being stored,
+// the reports of them are kept and served as they are.
+var ValidatedMimes = map[string]Validator{
+	// The SBOM types
+	v1.MimeTypeSBOMCycloneDX: (*sbom.CycloneDX)(nil),
+	v1.MimeTypeSBOMSPDX:      (*sbom.SPDX)(nil),
+}
+
+// Validator checks the structure of the parsed report data
+type Validator interface {
+	Validate() error
+}
+
 // ResolveData is a helper func to parse the JSON data with the given mime type.
 func ResolveData(mime string, jsonData []byte) (interface{}, error) {
 	// If no resolver defined for the given mime types, directly ignore it.
 	// The raw data will be used.
 	t, ok := SupportedMimes[mime]
 	if !ok {
+		// The raw data is still checked if there is a validator
+		if v, ok := ValidatedMimes[mime]; ok {
+			if _, err := parseData(v, jsonData); err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("validate report with mime type %s", mime))
+			}
+		}
+
 		return nil, nil
 	}
 
+	return parseData(t, jsonData)
+}
+
+// parseData parses the JSON data to the object with the type of t, the parsed object is
+// validated if it's a Validator.
+func parseData(t interface{}, jsonData []byte) (interface{}, error) {
 	if len(jsonData) == 0 {
 		return nil, errors.New("empty JSON data")
 	}
@@ -54,5 +82,11 @@ func ResolveData(mime string, jsonData []byte) (interface{}, error) {
 		return nil, err
 	}
 
+	if v, ok := rp.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	return rp, nil
 }
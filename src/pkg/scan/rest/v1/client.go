@@ -16,12 +16,15 @@ package v1
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"os"
 	"strconv"
 	"time"
 
@@ -36,6 +39,10 @@ const (
 	defaultRefreshInterval = 5
 	// refreshAfterHeader provides the refresh interval value
 	refreshAfterHeader = "Refresh-After"
+	// DefaultMaxReportSize is the default max size in bytes of the raw report read from the adapter
+	DefaultMaxReportSize = 64 * 1024 * 1024
+	// maxReportSizeEnv is the env var which overrides the max size in bytes of the raw report
+	maxReportSizeEnv = "SCAN_REPORT_MAX_SIZE"
 )
 
 // Client defines the methods to access the adapter services that
@@ -52,12 +59,13 @@ type Client interface {
 	// Returns `nil` if the request was accepted, a non `nil` error otherwise.
 	//
 	//   Arguments:
-	//     req *ScanRequest : request including the registry and artifact data
+	//     ctx context.Context : the context which aborts the request once it's done
+	//     req *ScanRequest    : request including the registry and artifact data
 	//
 	//   Returns:
 	//     *ScanResponse : response with UUID for tracking the scan results
 	//     error         : non nil error if any errors occurred
-	SubmitScan(req *ScanRequest) (*ScanResponse, error)
+	SubmitScan(ctx context.Context, req *ScanRequest) (*ScanResponse, error)
 
 	// GetScanReport gets the scan result for the corresponding ScanRequest identifier.
 	// Note that this is a blocking method which either returns a non `nil` scan report or error.
@@ -65,19 +73,21 @@ type Client interface {
 	// to the specified MIME type.
 	//
 	//   Arguments:
+	//     ctx context.Context   : the context which aborts the request once it's done
 	//     scanRequestID string  : the ID of the scan submitted before
 	//     reportMIMEType string : the report mime type
 	//   Returns:
 	//     string : the scan report of the given artifact
 	//     error  : non nil error if any errors occurred
-	GetScanReport(scanRequestID, reportMIMEType string) (string, error)
+	GetScanReport(ctx context.Context, scanRequestID, reportMIMEType string) (string, error)
 }
 
 // basicClient is default implementation of the Client interface
 type basicClient struct {
-	httpClient *http.Client
-	spec       *Spec
-	authorizer auth.Authorizer
+	httpClient    *http.Client
+	spec          *Spec
+	authorizer    auth.Authorizer
+	maxReportSize int64
 }
 
 // NewClient news a basic client
@@ -109,11 +119,25 @@ func NewClient(r *scanner.Registration) (Client, error) {
 				return http.ErrUseLastResponse
 			},
 		},
-		spec:       NewSpec(r.URL),
-		authorizer: authorizer,
+		spec:          NewSpec(r.URL),
+		authorizer:    authorizer,
+		maxReportSize: maxReportSize(),
 	}, nil
 }
 
+// maxReportSize returns the max size of the raw report, which can be overridden by the env var
+func maxReportSize() int64 {
+	if v := os.Getenv(maxReportSizeEnv); len(v) > 0 {
+		size, err := strconv.ParseInt(v, 10, 64)
+		if err == nil && size > 0 {
+			return size
+		}
+		logger.Errorf("Invalid value of %s: %s, use the default %d", maxReportSizeEnv, v, DefaultMaxReportSize)
+	}
+
+	return DefaultMaxReportSize
+}
+
 // GetMetadata ...
 func (c *basicClient) GetMetadata() (*ScannerAdapterMetadata, error) {
 	def := c.spec.Metadata()
@@ -142,7 +166,7 @@ func (c *basicClient) GetMetadata() (*ScannerAdapterMetadata, error) {
 }
 
 // SubmitScan ...
-func (c *basicClient) SubmitScan(req *ScanRequest) (*ScanResponse, error) {
+func (c *basicClient) SubmitScan(ctx context.Context, req *ScanRequest) (*ScanResponse, error) {
 	if req == nil {
 		return nil, errors.New("nil request")
 	}
@@ -157,6 +181,7 @@ func (c *basicClient) SubmitScan(req *ScanRequest) (*ScanResponse, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "v1 client: submit scan")
 	}
+	request = request.WithContext(ctx)
 
 	respData, err := c.send(request, generalResponseHandler(http.StatusAccepted))
 	if err != nil {
@@ -172,7 +197,7 @@ func (c *basicClient) SubmitScan(req *ScanRequest) (*ScanResponse, error) {
 }
 
 // GetScanReport ...
-func (c *basicClient) GetScanReport(scanRequestID, reportMIMEType string) (string, error) {
+func (c *basicClient) GetScanReport(ctx context.Context, scanRequestID, reportMIMEType string) (string, error) {
 	if len(scanRequestID) == 0 {
 		return "", errors.New("empty scan request ID")
 	}
@@ -187,8 +212,9 @@ func (c *basicClient) GetScanReport(scanRequestID, reportMIMEType string) (strin
 	if err != nil {
 		return "", errors.Wrap(err, "v1 client: get scan report")
 	}
+	req = req.WithContext(ctx)
 
-	respData, err := c.send(req, reportResponseHandler())
+	respData, err := c.send(req, reportResponseHandler(c.maxReportSize))
 	if err != nil {
 		// This error should not be wrapped
 		return "", err
@@ -231,7 +257,8 @@ func generalResponseHandler(expectedCode int) responseHandler {
 }
 
 // reportResponseHandler creates response handler for get report special case.
-func reportResponseHandler() responseHandler {
+// The report larger than the max size is rejected without being read fully.
+func reportResponseHandler(maxSize int64) responseHandler {
 	return func(code int, resp *http.Response) ([]byte, error) {
 		if code == http.StatusFound {
 			// Set default
@@ -250,6 +277,22 @@ func reportResponseHandler() responseHandler {
 			return nil, &ReportNotReadyError{RetryAfter: retryAfter}
 		}
 
+		if code == http.StatusOK && maxSize > 0 {
+			if resp.ContentLength > maxSize {
+				return nil, errors.Errorf("report size %d exceeds the limit of %d bytes", resp.ContentLength, maxSize)
+			}
+
+			buf, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+			if err != nil {
+				return nil, err
+			}
+			if int64(len(buf)) > maxSize {
+				return nil, errors.Errorf("report size exceeds the limit of %d bytes", maxSize)
+			}
+
+			return buf, nil
+		}
+
 		return generalRespHandlerFunc(http.StatusOK, code, resp)
 	}
 }
@@ -262,30 +305,38 @@ func generalRespHandlerFunc(expectedCode, code int, resp *http.Response) ([]byte
 	}
 
 	if code != expectedCode {
-		if len(buf) > 0 {
-			// Try to read error response
-			eResp := &ErrorResponse{
-				Err: &Error{},
-			}
+		return nil, &UnexpectedStatusCodeError{
+			Code: code,
+			Err:  unexpectedStatusError(expectedCode, code, buf),
+		}
+	}
 
-			err := json.Unmarshal(buf, eResp)
-			if err != nil {
-				return nil, errors.Wrap(err, "general response handler")
-			}
+	return buf, nil
+}
 
-			// Append more contexts
-			eResp.Err.Message = fmt.Sprintf(
-				"%s: general response handler: unexpected status code: %d, expected: %d",
-				eResp.Err.Message,
-				code,
-				expectedCode,
-			)
+// unexpectedStatusError parses the error from the response with the unexpected status code
+func unexpectedStatusError(expectedCode, code int, buf []byte) error {
+	if len(buf) > 0 {
+		// Try to read error response
+		eResp := &ErrorResponse{
+			Err: &Error{},
+		}
 
-			return nil, eResp
+		err := json.Unmarshal(buf, eResp)
+		if err != nil {
+			return errors.Wrap(err, "general response handler")
 		}
 
-		return nil, errors.Errorf("general response handler: unexpected status code: %d, expected: %d", code, expectedCode)
+		// Append more contexts
+		eResp.Err.Message = fmt.Sprintf(
+			"%s: general response handler: unexpected status code: %d, expected: %d",
+			eResp.Err.Message,
+			code,
+			expectedCode,
+		)
+
+		return eResp
 	}
 
-	return buf, nil
+	return errors.Errorf("general response handler: unexpected status code: %d, expected: %d", code, expectedCode)
 }
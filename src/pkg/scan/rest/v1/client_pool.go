@@ -22,13 +22,16 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/goharbor/harbor/src/jobservice/logger"
 	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
 	"github.com/pkg/errors"
 )
 
 const (
-	defaultDeadCheckInterval = 1 * time.Minute
-	defaultExpireTime        = 5 * time.Minute
+	defaultDeadCheckInterval   = 1 * time.Minute
+	defaultExpireTime          = 5 * time.Minute
+	defaultHealthCheckInterval = 1 * time.Minute
+	defaultMaxProbeFailures    = 3
 )
 
 // DefaultClientPool is a default client pool.
@@ -45,6 +48,19 @@ type ClientPool interface {
 	//   Client : v1 client
 	//   error  : non nil error if any errors occurred
 	Get(r *scanner.Registration) (Client, error)
+
+	// Invalidate evicts all the cached clients of the specified registration,
+	// the subsequent Get will build a fresh client.
+	//
+	//  Arguments:
+	//   registrationUUID string : UUID of the registration
+	Invalidate(registrationUUID string)
+
+	// Stats returns the statistics of the pool.
+	//
+	//  Returns:
+	//   *PoolStats : statistics of the pool
+	Stats() *PoolStats
 }
 
 // PoolConfig provides configurations for the client pool.
@@ -53,24 +69,49 @@ type PoolConfig struct {
 	DeadCheckInterval time.Duration
 	// Expire time for the instance to be marked as dead.
 	ExpireTime time.Duration
+	// Interval for probing the health of the instance by the adapter metadata endpoint.
+	HealthCheckInterval time.Duration
+	// Consecutive failed probes for the instance to be evicted.
+	MaxProbeFailures int
+}
+
+// PoolStats provides the statistics of the client pool.
+type PoolStats struct {
+	// Number of the cached clients
+	Size int `json:"size"`
+	// Number of the clients evicted for failing the health probes
+	Evictions int64 `json:"evictions"`
+	// Number of the clients evicted by invalidating the registrations
+	Invalidations int64 `json:"invalidations"`
+	// Number of the clients expired
+	Expirations int64 `json:"expirations"`
 }
 
 // poolItem append timestamp for the caching client instance.
 type poolItem struct {
 	c         Client
+	uuid      string
 	timestamp time.Time
+	// consecutive failed probes
+	failures int
+	// closed when the item is removed from the pool
+	done chan struct{}
 }
 
 // basicClientPool is default implementation of client pool interface.
 type basicClientPool struct {
-	pool   *sync.Map
+	lock   *sync.Mutex
+	pool   map[string]*poolItem
+	stats  *PoolStats
 	config *PoolConfig
 }
 
 // NewClientPool news a basic client pool.
 func NewClientPool(config *PoolConfig) ClientPool {
 	bcp := &basicClientPool{
-		pool:   &sync.Map{},
+		lock:   &sync.Mutex{},
+		pool:   make(map[string]*poolItem),
+		stats:  &PoolStats{},
 		config: config,
 	}
 
@@ -87,16 +128,21 @@ func NewClientPool(config *PoolConfig) ClientPool {
 		bcp.config.ExpireTime = defaultExpireTime
 	}
 
+	if bcp.config.HealthCheckInterval == 0 {
+		bcp.config.HealthCheckInterval = defaultHealthCheckInterval
+	}
+
+	if bcp.config.MaxProbeFailures <= 0 {
+		bcp.config.MaxProbeFailures = defaultMaxProbeFailures
+	}
+
 	return bcp
 }
 
 // Get client for the specified registration.
-// So far, there will not be too many scanner registrations. An then
-// no need to do client instance clear work.
-// If one day, we have to clear unactivated client instances in the pool,
-// add the following func after the first time initializing the client.
-// pool item represents the client with a timestamp of last accessed.
-
+// The cached client is evicted when it's expired, or it fails
+// the configured number of consecutive health probes, e.g: the certificate of the scanner
+// endpoint is rotated. The subsequent Get builds a fresh one for the evicted client.
 func (bcp *basicClientPool) Get(r *scanner.Registration) (Client, error) {
 	if r == nil {
 		return nil, errors.New("nil scanner registration")
@@ -108,7 +154,10 @@ func (bcp *basicClientPool) Get(r *scanner.Registration) (Client, error) {
 
 	k := key(r)
 
-	item, ok := bcp.pool.Load(k)
+	bcp.lock.Lock()
+	defer bcp.lock.Unlock()
+
+	item, ok := bcp.pool[k]
 	if !ok {
 		nc, err := NewClient(r)
 		if err != nil {
@@ -116,19 +165,43 @@ func (bcp *basicClientPool) Get(r *scanner.Registration) (Client, error) {
 		}
 
 		// Cache it
-		npi := &poolItem{
+		item = &poolItem{
 			c:         nc,
+			uuid:      r.UUID,
 			timestamp: time.Now().UTC(),
+			done:      make(chan struct{}),
 		}
-
-		bcp.pool.Store(k, npi)
-		item = npi
+		bcp.pool[k] = item
 
 		// dead check
-		bcp.deadCheck(k, npi)
+		bcp.deadCheck(k, item)
+	}
+
+	return item.c, nil
+}
+
+// Invalidate the cached clients of the specified registration.
+func (bcp *basicClientPool) Invalidate(registrationUUID string) {
+	bcp.lock.Lock()
+	defer bcp.lock.Unlock()
+
+	for k, item := range bcp.pool {
+		if item.uuid == registrationUUID {
+			bcp.remove(k)
+			bcp.stats.Invalidations++
+		}
 	}
+}
+
+// Stats of the pool.
+func (bcp *basicClientPool) Stats() *PoolStats {
+	bcp.lock.Lock()
+	defer bcp.lock.Unlock()
+
+	stats := *bcp.stats
+	stats.Size = len(bcp.pool)
 
-	return item.(*poolItem).c, nil
+	return &stats
 }
 
 func (bcp *basicClientPool) deadCheck(key string, item *poolItem) {
@@ -138,18 +211,29 @@ func (bcp *basicClientPool) deadCheck(key string, item *poolItem) {
 		// exit the goroutine correctly.
 		sig := make(chan os.Signal, 1)
 		signal.Notify(sig, os.Interrupt, syscall.SIGTERM, os.Kill)
+		defer signal.Stop(sig)
 
 		tk := time.NewTicker(bcp.config.DeadCheckInterval)
 		defer tk.Stop()
 
+		probe := time.NewTicker(bcp.config.HealthCheckInterval)
+		defer probe.Stop()
+
 		for {
 			select {
 			case t := <-tk.C:
-				if item.timestamp.Add(bcp.config.ExpireTime).Before(t.UTC()) {
-					// Expired
-					bcp.pool.Delete(key)
+				if bcp.expire(key, item, t.UTC()) {
+					return
+				}
+			case <-probe.C:
+				// Probe outside the lock as it's a remote call
+				_, err := item.c.GetMetadata()
+				if bcp.checkHealth(key, item, err) {
 					return
 				}
+			case <-item.done:
+				// Removed from the pool
+				return
 			case <-sig:
 				// Terminated by system
 				return
@@ -158,6 +242,62 @@ func (bcp *basicClientPool) deadCheck(key string, item *poolItem) {
 	}()
 }
 
+// expire removes the item if it has been cached for the expire time.
+// Returns true if the item is no longer in the pool.
+func (bcp *basicClientPool) expire(key string, item *poolItem, t time.Time) bool {
+	bcp.lock.Lock()
+	defer bcp.lock.Unlock()
+
+	if bcp.pool[key] != item {
+		return true
+	}
+
+	if item.timestamp.Add(bcp.config.ExpireTime).Before(t) {
+		bcp.remove(key)
+		bcp.stats.Expirations++
+		return true
+	}
+
+	return false
+}
+
+// checkHealth records the probe result of the item and evicts it when the consecutive
+// failures reach the limit. Returns true if the item is no longer in the pool.
+func (bcp *basicClientPool) checkHealth(key string, item *poolItem, err error) bool {
+	bcp.lock.Lock()
+	defer bcp.lock.Unlock()
+
+	if bcp.pool[key] != item {
+		return true
+	}
+
+	if err == nil {
+		item.failures = 0
+		return false
+	}
+
+	item.failures++
+	logger.Warningf("Client pool: health probe of scanner %s failed (%d/%d): %s",
+		item.uuid, item.failures, bcp.config.MaxProbeFailures, err)
+
+	if item.failures >= bcp.config.MaxProbeFailures {
+		bcp.remove(key)
+		bcp.stats.Evictions++
+		logger.Warningf("Client pool: client of scanner %s is evicted", item.uuid)
+		return true
+	}
+
+	return false
+}
+
+// remove the item from the pool, the caller should hold the lock.
+func (bcp *basicClientPool) remove(key string) {
+	if item, ok := bcp.pool[key]; ok {
+		delete(bcp.pool, key)
+		close(item.done)
+	}
+}
+
 func key(r *scanner.Registration) string {
 	return fmt.Sprintf("%s:%s:%s:%v",
 		r.URL,
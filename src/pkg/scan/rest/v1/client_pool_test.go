@@ -15,7 +15,11 @@
 package v1
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -80,3 +84,90 @@ func (suite *ClientPoolTestSuite) TestClientPoolGet() {
 	p3 := fmt.Sprintf("%p", client3.(*basicClient))
 	assert.NotEqual(suite.T(), p2, p3)
 }
+
+// TestClientPoolEviction tests the client failing the health probes is evicted and rebuilt.
+func (suite *ClientPoolTestSuite) TestClientPoolEviction() {
+	var healthy int32 = 1
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", MimeTypeAdapterMeta)
+		_ = json.NewEncoder(w).Encode(&ScannerAdapterMetadata{})
+	}))
+	defer ts.Close()
+
+	pool := NewClientPool(&PoolConfig{
+		DeadCheckInterval:   time.Hour,
+		ExpireTime:          time.Hour,
+		HealthCheckInterval: 50 * time.Millisecond,
+		MaxProbeFailures:    2,
+	})
+
+	r := &scanner.Registration{
+		Name: "TestClientPoolEviction",
+		UUID: "eviction",
+		URL:  ts.URL,
+	}
+
+	client1, err := pool.Get(r)
+	require.NoError(suite.T(), err)
+
+	// Healthy adapter keeps the cached client
+	<-time.After(200 * time.Millisecond)
+	client2, err := pool.Get(r)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fmt.Sprintf("%p", client1), fmt.Sprintf("%p", client2))
+	assert.Equal(suite.T(), int64(0), pool.Stats().Evictions)
+
+	// Adapter starts failing
+	atomic.StoreInt32(&healthy, 0)
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.Stats().Evictions == 0 && time.Now().Before(deadline) {
+		<-time.After(20 * time.Millisecond)
+	}
+	assert.Equal(suite.T(), int64(1), pool.Stats().Evictions)
+	assert.Equal(suite.T(), 0, pool.Stats().Size)
+
+	client3, err := pool.Get(r)
+	require.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), fmt.Sprintf("%p", client2), fmt.Sprintf("%p", client3))
+	assert.Equal(suite.T(), 1, pool.Stats().Size)
+}
+
+// TestClientPoolInvalidate tests invalidating the clients of the registration.
+func (suite *ClientPoolTestSuite) TestClientPoolInvalidate() {
+	pool := NewClientPool(nil)
+
+	r := &scanner.Registration{
+		Name: "TestClientPoolInvalidate",
+		UUID: "invalidate",
+		URL:  "http://a.b.c",
+	}
+	other := &scanner.Registration{
+		Name: "other",
+		UUID: "other",
+		URL:  "http://d.e.f",
+	}
+
+	client1, err := pool.Get(r)
+	require.NoError(suite.T(), err)
+	_, err = pool.Get(other)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, pool.Stats().Size)
+
+	pool.Invalidate("invalidate")
+	stats := pool.Stats()
+	assert.Equal(suite.T(), 1, stats.Size)
+	assert.Equal(suite.T(), int64(1), stats.Invalidations)
+
+	client2, err := pool.Get(r)
+	require.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), fmt.Sprintf("%p", client1), fmt.Sprintf("%p", client2))
+
+	// Unknown registration is ignored
+	pool.Invalidate("unknown")
+	assert.Equal(suite.T(), 2, pool.Stats().Size)
+}
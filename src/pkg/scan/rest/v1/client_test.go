@@ -15,14 +15,19 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/goharbor/harbor/src/pkg/scan/dao/scanner"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -70,7 +75,7 @@ func (suite *ClientTestSuite) TestClientMetadata() {
 
 // TestClientSubmitScan tests the scan submission of client
 func (suite *ClientTestSuite) TestClientSubmitScan() {
-	res, err := suite.client.SubmitScan(&ScanRequest{})
+	res, err := suite.client.SubmitScan(context.TODO(), &ScanRequest{})
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), res)
 
@@ -79,24 +84,72 @@ func (suite *ClientTestSuite) TestClientSubmitScan() {
 
 // TestClientGetScanReportError tests getting report failed
 func (suite *ClientTestSuite) TestClientGetScanReportError() {
-	_, err := suite.client.GetScanReport("id1", MimeTypeNativeReport)
+	_, err := suite.client.GetScanReport(context.TODO(), "id1", MimeTypeNativeReport)
 	require.Error(suite.T(), err)
 	assert.Condition(suite.T(), func() (success bool) {
 		success = strings.Index(err.Error(), "error") != -1
 		return
 	})
+	assert.False(suite.T(), IsRetryableError(err))
+}
+
+// TestIsRetryableError tests the classification of the errors
+func (suite *ClientTestSuite) TestIsRetryableError() {
+	netErr := &url.Error{Op: "Get", URL: "http://scanner", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	canceled := &url.Error{Op: "Get", URL: "http://scanner", Err: context.Canceled}
+
+	assert.True(suite.T(), IsRetryableError(&UnexpectedStatusCodeError{Code: http.StatusServiceUnavailable, Err: errors.New("503")}))
+	assert.True(suite.T(), IsRetryableError(errors.Wrap(&UnexpectedStatusCodeError{Code: http.StatusBadGateway, Err: errors.New("502")}, "wrapped")))
+	assert.True(suite.T(), IsRetryableError(netErr))
+	assert.False(suite.T(), IsRetryableError(canceled))
+	assert.False(suite.T(), IsRetryableError(&UnexpectedStatusCodeError{Code: http.StatusBadRequest, Err: errors.New("400")}))
+	assert.False(suite.T(), IsRetryableError(&ReportNotReadyError{RetryAfter: 1}))
+	assert.False(suite.T(), IsRetryableError(errors.New("report size exceeds the limit of 1 bytes")))
 }
 
 // TestClientGetScanReport tests getting report
 func (suite *ClientTestSuite) TestClientGetScanReport() {
-	res, err := suite.client.GetScanReport("id2", MimeTypeNativeReport)
+	res, err := suite.client.GetScanReport(context.TODO(), "id2", MimeTypeNativeReport)
 	require.NoError(suite.T(), err)
 	require.NotEmpty(suite.T(), res)
 }
 
+// TestClientGetScanReportTooLarge tests the report exceeding the max size is rejected
+func (suite *ClientTestSuite) TestClientGetScanReportTooLarge() {
+	c := suite.client.(*basicClient)
+	defer func(size int64) {
+		c.maxReportSize = size
+	}(c.maxReportSize)
+
+	c.maxReportSize = 1
+	_, err := suite.client.GetScanReport(context.TODO(), "id2", MimeTypeNativeReport)
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "exceeds the limit of 1 bytes")
+
+	c.maxReportSize = 2
+	res, err := suite.client.GetScanReport(context.TODO(), "id2", MimeTypeNativeReport)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{}", res)
+}
+
+// TestMaxReportSize tests the max report size can be overridden by the env var
+func (suite *ClientTestSuite) TestMaxReportSize() {
+	defer func() {
+		_ = os.Unsetenv(maxReportSizeEnv)
+	}()
+
+	assert.Equal(suite.T(), int64(DefaultMaxReportSize), maxReportSize())
+
+	require.NoError(suite.T(), os.Setenv(maxReportSizeEnv, "1024"))
+	assert.Equal(suite.T(), int64(1024), maxReportSize())
+
+	require.NoError(suite.T(), os.Setenv(maxReportSizeEnv, "-1"))
+	assert.Equal(suite.T(), int64(DefaultMaxReportSize), maxReportSize())
+}
+
 // TestClientGetScanReportNotReady tests the case that the report is not ready
 func (suite *ClientTestSuite) TestClientGetScanReportNotReady() {
-	_, err := suite.client.GetScanReport("id3", MimeTypeNativeReport)
+	_, err := suite.client.GetScanReport(context.TODO(), "id3", MimeTypeNativeReport)
 	require.Error(suite.T(), err)
 	require.Condition(suite.T(), func() (success bool) {
 		_, success = err.(*ReportNotReadyError)
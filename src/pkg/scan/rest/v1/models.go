@@ -15,8 +15,11 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 
 	"github.com/pkg/errors"
 )
@@ -81,6 +84,43 @@ type Artifact struct {
 	Digest string `json:"digest"`
 	// The mime type of the scanned artifact
 	MimeType string `json:"mime_type"`
+	// The platform of the multi-arch artifact to be scanned, the `digest` is the digest of the index.
+	// It's omitted for the single platform artifact.
+	Platform *Platform `json:"platform,omitempty"`
+}
+
+// Platform identifies the platform of the child manifest in the index of the multi-arch artifact.
+type Platform struct {
+	// The operating system, e.g: `linux`
+	OS string `json:"os"`
+	// The CPU architecture, e.g: `amd64`, `arm64`
+	Architecture string `json:"architecture"`
+	// The variant of the CPU, e.g: `v8` of `arm64`
+	Variant string `json:"variant,omitempty"`
+}
+
+// String returns the platform in the format of `os/architecture[/variant]`, it's empty for nil platform
+func (p *Platform) String() string {
+	if p == nil {
+		return ""
+	}
+
+	s := fmt.Sprintf("%s/%s", p.OS, p.Architecture)
+	if len(p.Variant) > 0 {
+		s = fmt.Sprintf("%s/%s", s, p.Variant)
+	}
+
+	return s
+}
+
+// Match checks whether the platform matches the given one in the format of `os/architecture[/variant]`,
+// the variant is ignored if it's not specified.
+func (p *Platform) Match(platform string) bool {
+	if p == nil {
+		return false
+	}
+
+	return platform == p.String() || platform == fmt.Sprintf("%s/%s", p.OS, p.Architecture)
 }
 
 // Registry represents Registry connection settings.
@@ -176,3 +216,36 @@ type ReportNotReadyError struct {
 func (rnr *ReportNotReadyError) Error() string {
 	return fmt.Sprintf("report is not ready yet, retry after %d", rnr.RetryAfter)
 }
+
+// UnexpectedStatusCodeError is an error to indicate the scanner adapter responds with an unexpected status code
+type UnexpectedStatusCodeError struct {
+	// The status code responded
+	Code int
+	// The error parsed from the response
+	Err error
+}
+
+// Error for UnexpectedStatusCodeError
+func (usc *UnexpectedStatusCodeError) Error() string {
+	return usc.Err.Error()
+}
+
+// IsRetryableError checks whether the error is a transient one which may be gone by retrying,
+// they're the network errors and the 5xx responses of the scanner adapter.
+func IsRetryableError(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case *UnexpectedStatusCodeError:
+		return e.Code >= http.StatusInternalServerError
+	case net.Error:
+		// The canceled requests are not transient
+		if ue, ok := e.(interface{ Unwrap() error }); ok {
+			if cause := ue.Unwrap(); cause == context.Canceled || cause == context.DeadlineExceeded {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
+}
@@ -33,6 +33,10 @@ const (
 	MimeTypeNativeReport = "application/vnd.scanner.adapter.vuln.report.harbor+json; version=1.0"
 	// MimeTypeRawReport defines the mime type for raw report
 	MimeTypeRawReport = "application/vnd.scanner.adapter.vuln.report.raw"
+	// MimeTypeSBOMCycloneDX defines the mime type for the SBOM in CycloneDX JSON format
+	MimeTypeSBOMCycloneDX = "application/vnd.cyclonedx+json"
+	// MimeTypeSBOMSPDX defines the mime type for the SBOM in SPDX JSON format
+	MimeTypeSBOMSPDX = "application/spdx+json"
 	// MimeTypeAdapterMeta defines the mime type for adapter metadata
 	MimeTypeAdapterMeta = "application/vnd.scanner.adapter.metadata+json; version=1.0"
 	// MimeTypeScanRequest defines the mime type for scan request
@@ -0,0 +1,112 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// bomFormatCycloneDX is the value of the bomFormat of the CycloneDX document
+	bomFormatCycloneDX = "CycloneDX"
+	// spdxVersionPrefix is the prefix of the spdxVersion of the SPDX document
+	spdxVersionPrefix = "SPDX-"
+)
+
+// CycloneDX is the basic structure of the CycloneDX document in JSON format,
+// only the fields used for checking the structure are declared.
+type CycloneDX struct {
+	// The format of the BOM, it must be CycloneDX
+	BOMFormat string `json:"bomFormat"`
+	// The version of the CycloneDX specification the BOM conforms to
+	SpecVersion string `json:"specVersion"`
+	// The components of the BOM
+	Components []*CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXComponent is the component declared in the CycloneDX document
+type CycloneDXComponent struct {
+	// e.g: library, application or operating-system
+	Type string `json:"type"`
+	// Name of the component
+	Name string `json:"name"`
+	// Version of the component
+	Version string `json:"version"`
+	// Package URL of the component
+	PURL string `json:"purl,omitempty"`
+}
+
+// Validate checks the basic structure of the CycloneDX document
+func (c *CycloneDX) Validate() error {
+	if c.BOMFormat != bomFormatCycloneDX {
+		return errors.Errorf("invalid CycloneDX bomFormat %q", c.BOMFormat)
+	}
+
+	if len(c.SpecVersion) == 0 {
+		return errors.New("missing CycloneDX specVersion")
+	}
+
+	for i, cp := range c.Components {
+		if cp == nil || len(cp.Name) == 0 {
+			return errors.Errorf("missing name of the CycloneDX component %d", i)
+		}
+	}
+
+	return nil
+}
+
+// SPDX is the basic structure of the SPDX document in JSON format,
+// only the fields used for checking the structure are declared.
+type SPDX struct {
+	// The version of the SPDX specification, e.g: SPDX-2.2
+	SPDXVersion string `json:"spdxVersion"`
+	// The identifier of the document, it's SPDXRef-DOCUMENT
+	SPDXID string `json:"SPDXID"`
+	// Name of the document
+	Name string `json:"name"`
+	// The packages of the document
+	Packages []*SPDXPackage `json:"packages"`
+}
+
+// SPDXPackage is the package declared in the SPDX document
+type SPDXPackage struct {
+	// The identifier of the package
+	SPDXID string `json:"SPDXID"`
+	// Name of the package
+	Name string `json:"name"`
+	// Version of the package
+	VersionInfo string `json:"versionInfo,omitempty"`
+}
+
+// Validate checks the basic structure of the SPDX document
+func (s *SPDX) Validate() error {
+	if !strings.HasPrefix(s.SPDXVersion, spdxVersionPrefix) {
+		return errors.Errorf("invalid SPDX spdxVersion %q", s.SPDXVersion)
+	}
+
+	if len(s.SPDXID) == 0 {
+		return errors.New("missing SPDX SPDXID")
+	}
+
+	for i, p := range s.Packages {
+		if p == nil || len(p.SPDXID) == 0 || len(p.Name) == 0 {
+			return errors.Errorf("missing SPDXID or name of the SPDX package %d", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCycloneDXValidate tests the structure check of the CycloneDX document
+func TestCycloneDXValidate(t *testing.T) {
+	cases := map[string]bool{
+		`{"bomFormat":"CycloneDX","specVersion":"1.4","components":[{"type":"library","name":"openssl","version":"1.1.1d"}]}`: true,
+		`{"bomFormat":"CycloneDX","specVersion":"1.4"}`:                                                                       true,
+		`{"bomFormat":"SPDX","specVersion":"1.4"}`:                                                                            false,
+		`{"bomFormat":"CycloneDX"}`:                                                                                           false,
+		`{"bomFormat":"CycloneDX","specVersion":"1.4","components":[{"type":"library","version":"1.1.1d"}]}`:                  false,
+	}
+
+	for doc, valid := range cases {
+		c := &CycloneDX{}
+		require.NoError(t, json.Unmarshal([]byte(doc), c))
+		assert.Equal(t, valid, c.Validate() == nil, doc)
+	}
+}
+
+// TestSPDXValidate tests the structure check of the SPDX document
+func TestSPDXValidate(t *testing.T) {
+	cases := map[string]bool{
+		`{"spdxVersion":"SPDX-2.2","SPDXID":"SPDXRef-DOCUMENT","name":"alpine","packages":[{"SPDXID":"SPDXRef-Package-musl","name":"musl","versionInfo":"1.1.24"}]}`: true,
+		`{"spdxVersion":"SPDX-2.2","SPDXID":"SPDXRef-DOCUMENT"}`:                                   true,
+		`{"spdxVersion":"2.2","SPDXID":"SPDXRef-DOCUMENT"}`:                                        false,
+		`{"spdxVersion":"SPDX-2.2"}`:                                                               false,
+		`{"spdxVersion":"SPDX-2.2","SPDXID":"SPDXRef-DOCUMENT","packages":[{"name":"musl"}]}`:      false,
+		`{"spdxVersion":"SPDX-2.2","SPDXID":"SPDXRef-DOCUMENT","packages":[{"SPDXID":"SPDXRef"}]}`: false,
+	}
+
+	for doc, valid := range cases {
+		s := &SPDX{}
+		require.NoError(t, json.Unmarshal([]byte(doc), s))
+		assert.Equal(t, valid, s.Validate() == nil, doc)
+	}
+}
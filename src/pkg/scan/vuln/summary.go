@@ -29,6 +29,9 @@ type NativeReportSummary struct {
 	CVEBypassed []string              `json:"-"`
 	StartTime   time.Time             `json:"start_time"`
 	EndTime     time.Time             `json:"end_time"`
+	// Summaries of the platforms keyed by the platform, it's only set in the summary merged from
+	// the reports of the platforms of the multi-arch artifact
+	Platforms map[string]*NativeReportSummary `json:"platforms,omitempty"`
 }
 
 // VulnerabilitySummary contains the total number of the found vulnerabilities number
@@ -39,6 +39,8 @@ import { VulnerabilityPageComponent } from './vulnerability-page/vulnerability-p
 import { GcPageComponent } from './gc-page/gc-page.component';
 import { OidcOnboardModule } from './oidc-onboard/oidc-onboard.module';
 import { LicenseModule } from './license/license.module';
+import { HTTP_INTERCEPTORS } from '@angular/common/http';
+import { CsrfInterceptor } from './csrf-interceptor.service';
 registerLocaleData(zh, 'zh-cn');
 registerLocaleData(es, 'es-es');
 registerLocaleData(localeFr, 'fr-fr');
@@ -86,7 +88,8 @@ export function getCurrentLanguage(translateService: TranslateService) {
         deps: [ AppConfigService, SkinableConfig],
         multi: true
       },
-      {provide: LOCALE_ID, useValue: "en-US"}
+      {provide: LOCALE_ID, useValue: "en-US"},
+      {provide: HTTP_INTERCEPTORS, useClass: CsrfInterceptor, multi: true}
     ],
     schemas: [
         CUSTOM_ELEMENTS_SCHEMA
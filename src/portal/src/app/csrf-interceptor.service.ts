@@ -0,0 +1,58 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+import { Injectable } from '@angular/core';
+import {
+    HttpEvent, HttpHandler, HttpInterceptor, HttpRequest, HttpResponse
+} from '@angular/common/http';
+import { Observable, of } from "rxjs";
+import { filter, map, switchMap, tap } from "rxjs/operators";
+
+export const CSRF_TOKEN_HEADER = 'X-Harbor-CSRF-Token';
+const csrfTokenEndpoint = '/c/csrf_token';
+const sessionEndpoints = ['/c/login', '/c/log_out'];
+const readMethods = ['GET', 'HEAD', 'OPTIONS'];
+
+/**
+ * Attach the CSRF token of the session to the state-changing API requests,
+ * the token is fetched once and dropped when the session changes
+ *
+ **
+ * class CsrfInterceptor
+ */
+@Injectable()
+export class CsrfInterceptor implements HttpInterceptor {
+    private token: string;
+
+    intercept(req: HttpRequest<any>, next: HttpHandler): Observable<HttpEvent<any>> {
+        if (sessionEndpoints.indexOf(req.url) !== -1) {
+            this.token = null;
+            return next.handle(req);
+        }
+        if (readMethods.indexOf(req.method) !== -1 || !req.url.startsWith('/api/')) {
+            return next.handle(req);
+        }
+        return this.getToken(next).pipe(switchMap(token =>
+            next.handle(req.clone({ setHeaders: { [CSRF_TOKEN_HEADER]: token } }))));
+    }
+
+    private getToken(next: HttpHandler): Observable<string> {
+        if (this.token) {
+            return of(this.token);
+        }
+        return next.handle(new HttpRequest<any>('GET', csrfTokenEndpoint)).pipe(
+            filter(event => event instanceof HttpResponse),
+            map((res: HttpResponse<any>) => res.body.token),
+            tap(token => this.token = token));
+    }
+}
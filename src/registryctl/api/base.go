@@ -24,6 +24,11 @@ func handleInternalServerError(w http.ResponseWriter) {
 		http.StatusInternalServerError)
 }
 
+func handleBadRequestError(w http.ResponseWriter) {
+	http.Error(w, http.StatusText(http.StatusBadRequest),
+		http.StatusBadRequest)
+}
+
 func handleUnauthorized(w http.ResponseWriter) {
 	http.Error(w, http.StatusText(http.StatusUnauthorized),
 		http.StatusUnauthorized)
@@ -29,3 +29,13 @@ func TestHandleInternalServerError(t *testing.T) {
 	}
 
 }
+
+func TestHandleBadRequestError(t *testing.T) {
+	w := httptest.NewRecorder()
+	handleBadRequestError(w)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unexpected status code: %d != %d", w.Code, http.StatusBadRequest)
+	}
+
+}
@@ -16,7 +16,9 @@ package api
 
 import (
 	"bytes"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"os/exec"
@@ -36,9 +38,23 @@ type GCResult struct {
 	EndTime   time.Time `json:"endtime"`
 }
 
-// StartGC ...
+// StartGC starts the garbage collection of registry, the untagged manifests are deleted
+// unless the query parameter "delete_untagged" is false. Nothing is deleted if the query
+// parameter "dry_run" is true, the blobs eligible for deletion are only listed in the output.
 func StartGC(w http.ResponseWriter, r *http.Request) {
-	cmd := exec.Command("/bin/bash", "-c", "registry garbage-collect --delete-untagged=true "+regConf)
+	deleteUntagged, err := parseBoolQuery(r, "delete_untagged", true)
+	if err != nil {
+		log.Errorf("%v", err)
+		handleBadRequestError(w)
+		return
+	}
+	dryRun, err := parseBoolQuery(r, "dry_run", false)
+	if err != nil {
+		log.Errorf("%v", err)
+		handleBadRequestError(w)
+		return
+	}
+	cmd := exec.Command("/bin/bash", "-c", fmt.Sprintf("registry garbage-collect --delete-untagged=%t --dry-run=%t %s", deleteUntagged, dryRun, regConf))
 	var outBuf, errBuf bytes.Buffer
 	cmd.Stdout = &outBuf
 	cmd.Stderr = &errBuf
@@ -58,3 +74,16 @@ func StartGC(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Debugf("Successful to execute garbage collection...")
 }
+
+// parseBoolQuery returns the bool query parameter, or the default value if it isn't specified
+func parseBoolQuery(r *http.Request, key string, defaultValue bool) (bool, error) {
+	v := r.URL.Query().Get(key)
+	if len(v) == 0 {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", key, v)
+	}
+	return b, nil
+}
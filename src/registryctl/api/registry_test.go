@@ -0,0 +1,47 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBoolQuery(t *testing.T) {
+	cases := []struct {
+		url   string
+		value bool
+		isErr bool
+	}{
+		{url: "/api/registry/gc", value: true},
+		{url: "/api/registry/gc?dry_run=false", value: false},
+		{url: "/api/registry/gc?dry_run=true", value: true},
+		{url: "/api/registry/gc?dry_run=ture", isErr: true},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest(http.MethodPost, c.url, nil)
+		require.Nil(t, err)
+		value, err := parseBoolQuery(req, "dry_run", true)
+		if c.isErr {
+			assert.NotNil(t, err)
+			continue
+		}
+		require.Nil(t, err)
+		assert.Equal(t, c.value, value)
+	}
+}
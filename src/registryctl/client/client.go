@@ -32,8 +32,11 @@ import (
 type Client interface {
 	// Health tests the connection with registry server
 	Health() error
-	// StartGC enable the gc of registry server
-	StartGC() (*api.GCResult, error)
+	// StartGC enable the gc of registry server, the untagged manifests are deleted if deleteUntagged is true
+	StartGC(deleteUntagged bool) (*api.GCResult, error)
+	// DryRunGC runs the gc of registry without deleting anything, the blobs eligible for deletion
+	// are listed in the message of the result
+	DryRunGC(deleteUntagged bool) (*api.GCResult, error)
 }
 
 type client struct {
@@ -72,8 +75,16 @@ func (c *client) Health() error {
 }
 
 // StartGC ...
-func (c *client) StartGC() (*api.GCResult, error) {
-	url := c.baseURL + "/api/registry/gc"
+func (c *client) StartGC(deleteUntagged bool) (*api.GCResult, error) {
+	return c.gc(fmt.Sprintf("%s/api/registry/gc?delete_untagged=%t", c.baseURL, deleteUntagged))
+}
+
+// DryRunGC ...
+func (c *client) DryRunGC(deleteUntagged bool) (*api.GCResult, error) {
+	return c.gc(fmt.Sprintf("%s/api/registry/gc?delete_untagged=%t&dry_run=true", c.baseURL, deleteUntagged))
+}
+
+func (c *client) gc(url string) (*api.GCResult, error) {
 	gcr := &api.GCResult{}
 
 	req, err := http.NewRequest(http.MethodPost, url, nil)
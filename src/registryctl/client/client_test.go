@@ -44,7 +44,7 @@ func TesHealth(t *testing.T) {
 }
 
 func TesStartGC(t *testing.T) {
-	gcr, err := c.StartGC()
+	gcr, err := c.StartGC(true)
 	assert.NotNil(t, err)
 	assert.Equal(t, gcr.Msg, "hello-world")
 	assert.Equal(t, gcr.Status, true)
@@ -59,6 +59,14 @@ type ImageRegistry interface {
 	PushBlob(repository, digest string, size int64, blob io.Reader) error
 }
 
+// BlobMounter defines the capabilities that an image registry supporting the cross repository
+// blob mount should have, the blobs are mounted without transferring the data
+type BlobMounter interface {
+	// MountBlob mounts the blob from the source repository to the destination one in the
+	// same registry, the "mounted" is false if the registry doesn't mount it
+	MountBlob(srcRepository, digest, dstRepository string) (mounted bool, err error)
+}
+
 // ChartRegistry defines the capabilities that a chart registry should have
 type ChartRegistry interface {
 	FetchCharts(filters []*model.Filter) ([]*model.Resource, error)
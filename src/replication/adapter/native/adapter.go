@@ -353,6 +353,15 @@ func (a *Adapter) PushBlob(repository, digest string, size int64, blob io.Reader
 	return client.PushBlob(digest, size, blob)
 }
 
+// MountBlob ...
+func (a *Adapter) MountBlob(srcRepository, digest, dstRepository string) (bool, error) {
+	client, err := a.getClient(dstRepository)
+	if err != nil {
+		return false, err
+	}
+	return client.TryMountBlob(digest, srcRepository)
+}
+
 func isDigest(str string) bool {
 	return strings.Contains(str, ":")
 }
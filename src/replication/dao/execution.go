@@ -79,6 +79,65 @@ func executionQueryConditions(query ...*models.ExecutionQuery) orm.QuerySeter {
 	return qs
 }
 
+// MaxExecutionSummaries is the max count of the execution summaries returned at one time
+const MaxExecutionSummaries = 200
+
+// GetReplicationExecutionsByStatus returns the executions of all the policies which are in the specified
+// status and started after the time "since", the latest executions are returned first. As fillExecution does,
+// the status and the failed count of the unfinished executions are aggregated from their tasks, because they
+// aren't written back to the execution until the execution is finished
+func GetReplicationExecutionsByStatus(status string, since time.Time, limit int) ([]*models.ExecutionSummary, error) {
+	if limit <= 0 || limit > MaxExecutionSummaries {
+		limit = MaxExecutionSummaries
+	}
+	sql := `with stats as (
+			select t.execution_id,
+				count(*) filter (where t.status in (?, ?, ?)) as in_progress,
+				count(*) filter (where t.status = ?) as failed,
+				count(*) filter (where t.status = ?) as stopped
+			from replication_task t
+			join replication_execution e on t.execution_id = e.id
+			where e.start_time >= ?
+			group by t.execution_id
+		), executions as (
+			select e.id, e.policy_id, e.start_time, e.end_time,
+				case when e.status in (?, ?, ?) or s.execution_id is null then e.status
+					when s.in_progress > 0 then ?
+					when s.failed > 0 then ?
+					when s.stopped > 0 then ?
+					else ? end as status,
+				case when e.status in (?, ?, ?) or s.execution_id is null then e.failed
+					else s.failed end as failed
+			from replication_execution e
+			left join stats s on s.execution_id = e.id
+			where e.start_time >= ?
+		)
+		select x.policy_id, p.name as policy_name, x.id as execution_id, x.start_time, x.end_time, x.failed
+		from executions x
+		join replication_policy p on x.policy_id = p.id
+		where x.status = ?
+		order by x.start_time desc
+		limit ?`
+	finished := []interface{}{models.ExecutionStatusFailed, models.ExecutionStatusSucceed, models.ExecutionStatusStopped}
+	params := []interface{}{
+		models.TaskStatusInitialized, models.TaskStatusPending, models.TaskStatusInProgress,
+		models.TaskStatusFailed,
+		models.TaskStatusStopped,
+		since,
+	}
+	params = append(params, finished...)
+	params = append(params, models.ExecutionStatusInProgress, models.ExecutionStatusFailed,
+		models.ExecutionStatusStopped, models.ExecutionStatusSucceed)
+	params = append(params, finished...)
+	params = append(params, since, status, limit)
+
+	summaries := []*models.ExecutionSummary{}
+	if _, err := dao.GetOrmer().Raw(sql, params...).QueryRows(&summaries); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
 // GetExecution ...
 func GetExecution(id int64) (*models.Execution, error) {
 	o := dao.GetOrmer()
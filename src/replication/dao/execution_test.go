@@ -292,3 +292,82 @@ func TestExecutionFill2(t *testing.T) {
 	assert.Equal(t, 1, exes[0].Failed)
 	assert.Equal(t, 0, exes[0].Succeed)
 }
+
+func TestGetReplicationExecutionsByStatus(t *testing.T) {
+	policyID1, err := AddRepPolicy(&models.RepPolicy{
+		Name:    "cross policy execution test 1",
+		Trigger: "{\"type\":\"\",\"trigger_settings\":null}",
+		Filters: "[]",
+	})
+	require.Nil(t, err)
+	defer DeleteRepPolicy(policyID1)
+	policyID2, err := AddRepPolicy(&models.RepPolicy{
+		Name:    "cross policy execution test 2",
+		Trigger: "{\"type\":\"\",\"trigger_settings\":null}",
+		Filters: "[]",
+	})
+	require.Nil(t, err)
+	defer DeleteRepPolicy(policyID2)
+
+	now := time.Now()
+	executions := []*models.Execution{
+		{PolicyID: policyID1, Status: models.ExecutionStatusFailed, Failed: 1},
+		{PolicyID: policyID2, Status: models.ExecutionStatusFailed, Failed: 3},
+		{PolicyID: policyID2, Status: models.ExecutionStatusSucceed},
+	}
+	for _, e := range executions {
+		_, err := AddExecution(e)
+		require.Nil(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer func() {
+		DeleteAllExecutions(policyID1)
+		DeleteAllExecutions(policyID2)
+	}()
+
+	summaries, err := GetReplicationExecutionsByStatus(models.ExecutionStatusFailed, now.Add(-time.Second), 10)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(summaries))
+	// the latest one is returned first
+	assert.Equal(t, policyID2, summaries[0].PolicyID)
+	assert.Equal(t, "cross policy execution test 2", summaries[0].PolicyName)
+	assert.Equal(t, 3, summaries[0].FailedArtifactsCount)
+	assert.Equal(t, policyID1, summaries[1].PolicyID)
+	assert.Equal(t, "cross policy execution test 1", summaries[1].PolicyName)
+	assert.Equal(t, 1, summaries[1].FailedArtifactsCount)
+
+	// the status and failed count of the unfinished execution are aggregated from its tasks
+	id, err := AddExecution(&models.Execution{PolicyID: policyID1, Status: models.ExecutionStatusInProgress})
+	require.Nil(t, err)
+	for _, status := range []string{models.TaskStatusFailed, models.TaskStatusFailed, models.TaskStatusSucceed} {
+		_, err = AddTask(&models.Task{ExecutionID: id, Status: status})
+		require.Nil(t, err)
+	}
+	defer DeleteAllTasks(id)
+	summaries, err = GetReplicationExecutionsByStatus(models.ExecutionStatusFailed, now.Add(-time.Second), 10)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(summaries))
+	assert.Equal(t, id, summaries[0].ExecutionID)
+	assert.Equal(t, 2, summaries[0].FailedArtifactsCount)
+	summaries, err = GetReplicationExecutionsByStatus(models.ExecutionStatusInProgress, now.Add(-time.Second), 10)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(summaries))
+
+	// still in progress if any task isn't finished
+	_, err = AddTask(&models.Task{ExecutionID: id, Status: models.TaskStatusPending})
+	require.Nil(t, err)
+	summaries, err = GetReplicationExecutionsByStatus(models.ExecutionStatusInProgress, now.Add(-time.Second), 10)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(summaries))
+	assert.Equal(t, id, summaries[0].ExecutionID)
+
+	// limit
+	summaries, err = GetReplicationExecutionsByStatus(models.ExecutionStatusFailed, now.Add(-time.Second), 1)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(summaries))
+
+	// since
+	summaries, err = GetReplicationExecutionsByStatus(models.ExecutionStatusFailed, time.Now().Add(time.Hour), 10)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(summaries))
+}
@@ -92,6 +92,7 @@ var TaskPropsName = TaskFieldsName{
 	Status:       "Status",
 	StartTime:    "StartTime",
 	EndTime:      "EndTime",
+	TransferMode: "TransferMode",
 }
 
 // TaskFieldsName defines the props of Task
@@ -105,6 +106,7 @@ type TaskFieldsName struct {
 	Status       string
 	StartTime    string
 	EndTime      string
+	TransferMode string
 }
 
 // Task represent the tasks in one execution.
@@ -120,6 +122,9 @@ type Task struct {
 	StatusRevision int64     `orm:"column(status_revision)"`
 	StartTime      time.Time `orm:"column(start_time)" json:"start_time"`
 	EndTime        time.Time `orm:"column(end_time)" json:"end_time,omitempty"`
+	// the mode the blobs are transferred in, "copy" or "mount", it's empty for the
+	// tasks not transferring the image blobs
+	TransferMode string `orm:"column(transfer_mode)" json:"transfer_mode"`
 }
 
 // TableName is required by by beego orm to map Execution to table replication_execution
@@ -149,6 +154,16 @@ type TaskQuery struct {
 	Pagination
 }
 
+// ExecutionSummary is the brief info of the execution across the policies
+type ExecutionSummary struct {
+	PolicyID             int64     `orm:"column(policy_id)" json:"policy_id"`
+	PolicyName           string    `orm:"column(policy_name)" json:"policy_name"`
+	ExecutionID          int64     `orm:"column(execution_id)" json:"execution_id"`
+	StartedAt            time.Time `orm:"column(start_time)" json:"started_at"`
+	EndedAt              time.Time `orm:"column(end_time)" json:"ended_at"`
+	FailedArtifactsCount int       `orm:"column(failed)" json:"failed_artifacts_count"`
+}
+
 // TaskStat holds statistics of task by status
 type TaskStat struct {
 	Status string `orm:"column(status)"`
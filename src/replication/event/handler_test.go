@@ -47,6 +47,9 @@ func (f *fakedOperationController) GetTask(id int64) (*models.Task, error) {
 func (f *fakedOperationController) UpdateTaskStatus(id int64, status string, statusRevision int64, statusCondition ...string) error {
 	return nil
 }
+func (f *fakedOperationController) UpdateTaskTransferMode(id int64, mode string) error {
+	return nil
+}
 func (f *fakedOperationController) GetTaskLog(int64) ([]byte, error) {
 	return nil, nil
 }
@@ -40,6 +40,7 @@ type Controller interface {
 	ListTasks(...*models.TaskQuery) (int64, []*models.Task, error)
 	GetTask(int64) (*models.Task, error)
 	UpdateTaskStatus(id int64, status string, statusRevision int64, statusCondition ...string) error
+	UpdateTaskTransferMode(id int64, mode string) error
 	GetTaskLog(int64) ([]byte, error)
 }
 
@@ -235,6 +236,12 @@ func (c *controller) GetTask(id int64) (*models.Task, error) {
 func (c *controller) UpdateTaskStatus(id int64, status string, statusRevision int64, statusCondition ...string) error {
 	return c.executionMgr.UpdateTaskStatus(id, status, statusRevision, statusCondition...)
 }
+func (c *controller) UpdateTaskTransferMode(id int64, mode string) error {
+	return c.executionMgr.UpdateTask(&models.Task{
+		ID:           id,
+		TransferMode: mode,
+	}, models.TaskPropsName.TransferMode)
+}
 func (c *controller) GetTaskLog(taskID int64) ([]byte, error) {
 	return c.executionMgr.GetTaskLog(taskID)
 }
@@ -15,9 +15,13 @@
 package hook
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/replication/dao/models"
 	"github.com/goharbor/harbor/src/replication/operation"
+	"github.com/goharbor/harbor/src/replication/transfer"
 )
 
 // UpdateTask update the status of the task
@@ -45,3 +49,16 @@ func UpdateTask(ctl operation.Controller, id int64, status string, statusRevisio
 	}
 	return ctl.UpdateTaskStatus(id, s, statusRevision, preStatus...)
 }
+
+// UpdateTaskTransferMode updates the transfer mode of the task according to the
+// transfer stats checked in by the replication job
+func UpdateTaskTransferMode(ctl operation.Controller, id int64, checkIn string) error {
+	stats := &transfer.Stats{}
+	if err := json.Unmarshal([]byte(checkIn), stats); err != nil {
+		return err
+	}
+	if stats.TransferMode != transfer.TransferModeCopy && stats.TransferMode != transfer.TransferModeMount {
+		return fmt.Errorf("unknown transfer mode: %s", stats.TransferMode)
+	}
+	return ctl.UpdateTaskTransferMode(id, stats.TransferMode)
+}
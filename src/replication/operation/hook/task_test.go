@@ -26,6 +26,7 @@ import (
 
 type fakedOperationController struct {
 	status string
+	mode   string
 }
 
 func (f *fakedOperationController) StartReplication(*model.Policy, *model.Resource, model.TriggerType) (int64, error) {
@@ -50,6 +51,10 @@ func (f *fakedOperationController) UpdateTaskStatus(id int64, status string, sta
 	f.status = status
 	return nil
 }
+func (f *fakedOperationController) UpdateTaskTransferMode(id int64, mode string) error {
+	f.mode = mode
+	return nil
+}
 func (f *fakedOperationController) GetTaskLog(int64) ([]byte, error) {
 	return nil, nil
 }
@@ -92,3 +97,18 @@ func TestUpdateTask(t *testing.T) {
 		assert.Equal(t, c.expectedStatus, mgr.status)
 	}
 }
+
+func TestUpdateTaskTransferMode(t *testing.T) {
+	mgr := &fakedOperationController{}
+	err := UpdateTaskTransferMode(mgr, 1, `{"transfer_mode":"mount","mounted_bytes":1024,"copied_bytes":0}`)
+	require.Nil(t, err)
+	assert.Equal(t, "mount", mgr.mode)
+
+	// invalid check in data
+	err = UpdateTaskTransferMode(mgr, 1, "progress data")
+	assert.NotNil(t, err)
+
+	// unknown transfer mode
+	err = UpdateTaskTransferMode(mgr, 1, `{"transfer_mode":"unknown"}`)
+	assert.NotNil(t, err)
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"encoding/json"
+	"net/http"
 	"testing"
 
 	"github.com/goharbor/harbor/src/common/job/models"
@@ -22,12 +23,21 @@ func (client TestClient) SubmitJob(*models.JobData) (string, error) {
 func (client TestClient) GetJobLog(uuid string) ([]byte, error) {
 	return []byte("job log"), nil
 }
+func (client TestClient) GetJobLogStream(uuid, rangeHeader string) (*http.Response, error) {
+	return nil, nil
+}
 func (client TestClient) PostAction(uuid, action string) error {
 	return nil
 }
 func (client TestClient) GetExecutions(uuid string) ([]job.Stats, error) {
 	return nil, nil
 }
+func (client TestClient) UpdateJobSchedule(uuid, cron string) error {
+	return nil
+}
+func (client TestClient) DeleteJobLog(uuid string) error {
+	return nil
+}
 
 func TestPreprocess(t *testing.T) {
 	items, err := generateData()
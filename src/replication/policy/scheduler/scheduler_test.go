@@ -16,6 +16,7 @@ package scheduler
 
 import (
 	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/goharbor/harbor/src/common/job/models"
@@ -56,6 +57,15 @@ func (f *fakedJobserviceClient) GetExecutions(uuid string) ([]job.Stats, error)
 	f.stopped = true
 	return nil, nil
 }
+func (f *fakedJobserviceClient) UpdateJobSchedule(uuid, cron string) error {
+	return nil
+}
+func (f *fakedJobserviceClient) DeleteJobLog(uuid string) error {
+	return nil
+}
+func (f *fakedJobserviceClient) GetJobLogStream(uuid, rangeHeader string) (*http.Response, error) {
+	return nil, nil
+}
 
 type fakedScheduleJobDAO struct {
 	idCounter int64
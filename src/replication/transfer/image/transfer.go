@@ -45,6 +45,9 @@ func factory(logger trans.Logger, stopFunc trans.StopFunc) (trans.Transfer, erro
 	return &transfer{
 		logger:    logger,
 		isStopped: stopFunc,
+		stats: trans.Stats{
+			TransferMode: trans.TransferModeCopy,
+		},
 	}, nil
 }
 
@@ -53,6 +56,13 @@ type transfer struct {
 	isStopped trans.StopFunc
 	src       adapter.ImageRegistry
 	dst       adapter.ImageRegistry
+	stats     trans.Stats
+}
+
+// Stats returns the statistics of the transfer
+func (t *transfer) Stats() *trans.Stats {
+	stats := t.stats
+	return &stats
 }
 
 func (t *transfer) Transfer(src *model.Resource, dst *model.Resource) error {
@@ -105,9 +115,24 @@ func (t *transfer) initialize(src *model.Resource, dst *model.Resource) error {
 	t.logger.Infof("client for destination registry [type: %s, URL: %s, insecure: %v] created",
 		dst.Registry.Type, dst.Registry.URL, dst.Registry.Insecure)
 
+	t.stats.TransferMode = t.transferMode(src.Registry, dst.Registry)
 	return nil
 }
 
+// transferMode returns the mount mode if both the source and destination registries are Harbor and
+// the destination adapter can mount the blobs, otherwise returns the copy mode. Whether the mount
+// really works is only known by mounting the first blob, the transfer falls back to copy if it fails
+func (t *transfer) transferMode(src, dst *model.Registry) string {
+	if src.Type != model.RegistryTypeHarbor || dst.Type != model.RegistryTypeHarbor {
+		return trans.TransferModeCopy
+	}
+	if _, ok := t.dst.(adapter.BlobMounter); !ok {
+		return trans.TransferModeCopy
+	}
+	t.logger.Info("both the source and destination registries are Harbor, try to mount the blobs")
+	return trans.TransferModeMount
+}
+
 func createRegistry(reg *model.Registry) (adapter.ImageRegistry, error) {
 	factory, err := adapter.GetFactory(reg.Type)
 	if err != nil {
@@ -150,6 +175,8 @@ func (t *transfer) copy(src *repository, dst *repository, override bool) error {
 
 	t.logger.Infof("copy %s:[%s](source registry) to %s:[%s](destination registry) completed",
 		srcRepo, strings.Join(src.tags, ","), dstRepo, strings.Join(dst.tags, ","))
+	t.logger.Infof("transfer mode: %s, %d bytes mounted, %d bytes copied",
+		t.stats.TransferMode, t.stats.MountedBytes, t.stats.CopiedBytes)
 	return nil
 }
 
@@ -219,12 +246,12 @@ func (t *transfer) copyContent(content distribution.Descriptor, srcRepo, dstRepo
 	// the media type of the layer or config can be "application/octet-stream",
 	// schema1.MediaTypeManifestLayer, schema2.MediaTypeLayer, schema2.MediaTypeImageConfig
 	default:
-		return t.copyBlob(srcRepo, dstRepo, digest)
+		return t.copyBlob(srcRepo, dstRepo, digest, content.Size)
 	}
 }
 
 // copy the layer or image config from the source registry to destination
-func (t *transfer) copyBlob(srcRepo, dstRepo, digest string) error {
+func (t *transfer) copyBlob(srcRepo, dstRepo, digest string, size int64) error {
 	if t.shouldStop() {
 		return nil
 	}
@@ -239,6 +266,11 @@ func (t *transfer) copyBlob(srcRepo, dstRepo, digest string) error {
 		return nil
 	}
 
+	if t.stats.TransferMode == trans.TransferModeMount && t.mountBlob(srcRepo, dstRepo, digest, size) {
+		t.logger.Infof("mount the blob %s completed", digest)
+		return nil
+	}
+
 	size, data, err := t.src.PullBlob(srcRepo, digest)
 	if err != nil {
 		t.logger.Errorf("failed to pulling the blob %s: %v", digest, err)
@@ -249,10 +281,29 @@ func (t *transfer) copyBlob(srcRepo, dstRepo, digest string) error {
 		t.logger.Errorf("failed to pushing the blob %s: %v", digest, err)
 		return err
 	}
+	t.stats.CopiedBytes += size
 	t.logger.Infof("copy the blob %s completed", digest)
 	return nil
 }
 
+// mountBlob tries to mount the blob from the source repository in the destination registry. The
+// transfer falls back to the copy mode once a blob can't be mounted, as that means the destination
+// registry doesn't share the storage with the source one
+func (t *transfer) mountBlob(srcRepo, dstRepo, digest string, size int64) bool {
+	mounted, err := t.dst.(adapter.BlobMounter).MountBlob(srcRepo, digest, dstRepo)
+	if err != nil {
+		t.logger.Warningf("failed to mount the blob %s from %s: %v, fall back to copy", digest, srcRepo, err)
+	} else if !mounted {
+		t.logger.Warningf("the blob %s can't be mounted from %s, fall back to copy", digest, srcRepo)
+	}
+	if err != nil || !mounted {
+		t.stats.TransferMode = trans.TransferModeCopy
+		return false
+	}
+	t.stats.MountedBytes += size
+	return true
+}
+
 func (t *transfer) pullManifest(repository, reference string) (
 	distribution.Manifest, string, error) {
 	if t.shouldStop() {
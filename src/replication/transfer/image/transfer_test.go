@@ -155,3 +155,128 @@ func TestDelete(t *testing.T) {
 	err := tr.delete(repo)
 	require.Nil(t, err)
 }
+
+// fakeMountRegistry shares the storage with the source registry if "shared" is true
+type fakeMountRegistry struct {
+	fakeRegistry
+	shared bool
+	// the bytes pushed to the registry
+	pushed int64
+}
+
+func (f *fakeMountRegistry) PushBlob(repository, digest string, size int64, blob io.Reader) error {
+	n, err := io.Copy(ioutil.Discard, blob)
+	f.pushed += n
+	return err
+}
+
+func (f *fakeMountRegistry) MountBlob(srcRepository, digest, dstRepository string) (bool, error) {
+	return f.shared, nil
+}
+
+// fakeLayerRegistry serves the blobs with the size declared in the manifest
+type fakeLayerRegistry struct {
+	fakeRegistry
+}
+
+func (f *fakeLayerRegistry) PullBlob(repository, digest string) (int64, io.ReadCloser, error) {
+	sizes := map[string]int64{
+		"sha256:b5b2b2c507a0944348e0303114d8d93aaaa081732b86451d9bce1f432a537bc7": 7023,
+		"sha256:e692418e4cbaf90ca69d05a66403747baa33ee08806650b51fab815ad7fc331f": 32654,
+		"sha256:3c3a4604a545cdc127456d94e421cd355bca5b528f4a9c1905b15da2eb4a4c6b": 16724,
+		"sha256:ec4b8955958665577945c89419d1af06b5f7636b4ac3da7f12184802ad867736": 73109,
+	}
+	size := sizes[digest]
+	return size, ioutil.NopCloser(bytes.NewReader(make([]byte, size))), nil
+}
+
+// the total size of the config and layers in the manifest of fakeRegistry
+const fakeImageSize = 7023 + 32654 + 16724 + 73109
+
+func TestTransferMode(t *testing.T) {
+	tr := &transfer{
+		logger: log.DefaultLogger(),
+		dst:    &fakeMountRegistry{},
+	}
+	harbor := &model.Registry{Type: model.RegistryTypeHarbor}
+	docker := &model.Registry{Type: model.RegistryTypeDockerRegistry}
+	assert.Equal(t, trans.TransferModeMount, tr.transferMode(harbor, harbor))
+	assert.Equal(t, trans.TransferModeCopy, tr.transferMode(docker, harbor))
+	assert.Equal(t, trans.TransferModeCopy, tr.transferMode(harbor, docker))
+
+	// the destination registry doesn't support mount
+	tr.dst = &fakeRegistry{}
+	assert.Equal(t, trans.TransferModeCopy, tr.transferMode(harbor, harbor))
+}
+
+func TestCopyWithMount(t *testing.T) {
+	cases := []struct {
+		shared       bool
+		mode         string
+		mountedBytes int64
+		copiedBytes  int64
+	}{
+		// all the blobs are mounted
+		{
+			shared:       true,
+			mode:         trans.TransferModeMount,
+			mountedBytes: fakeImageSize,
+		},
+		// fall back to copy
+		{
+			shared:      false,
+			mode:        trans.TransferModeCopy,
+			copiedBytes: fakeImageSize,
+		},
+	}
+	for _, c := range cases {
+		dst := &fakeMountRegistry{shared: c.shared}
+		tr := &transfer{
+			logger:    log.DefaultLogger(),
+			isStopped: func() bool { return false },
+			src:       &fakeLayerRegistry{},
+			dst:       dst,
+			stats: trans.Stats{
+				TransferMode: trans.TransferModeMount,
+			},
+		}
+		err := tr.copy(&repository{
+			repository: "source",
+			tags:       []string{"a1"},
+		}, &repository{
+			repository: "destination",
+			tags:       []string{"b2"},
+		}, true)
+		require.Nil(t, err)
+		stats := tr.Stats()
+		assert.Equal(t, c.mode, stats.TransferMode)
+		assert.Equal(t, c.mountedBytes, stats.MountedBytes)
+		assert.Equal(t, c.copiedBytes, stats.CopiedBytes)
+		assert.Equal(t, c.copiedBytes, dst.pushed)
+	}
+}
+
+// BenchmarkTransfer compares the bytes sent to the destination registry in the copy and mount modes
+func BenchmarkTransfer(b *testing.B) {
+	for _, mode := range []string{trans.TransferModeCopy, trans.TransferModeMount} {
+		b.Run(mode, func(b *testing.B) {
+			dst := &fakeMountRegistry{shared: true}
+			for i := 0; i < b.N; i++ {
+				tr := &transfer{
+					logger:    log.DefaultLogger(),
+					isStopped: func() bool { return false },
+					src:       &fakeLayerRegistry{},
+					dst:       dst,
+					stats: trans.Stats{
+						TransferMode: mode,
+					},
+				}
+				if err := tr.copyImage("source", "a1", "destination", "b2", true); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.Logf("%d bytes transferred per image in %s mode, %d bytes saved",
+				dst.pushed/int64(b.N), mode, fakeImageSize-dst.pushed/int64(b.N))
+		})
+	}
+}
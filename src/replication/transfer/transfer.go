@@ -37,6 +37,29 @@ type Transfer interface {
 	Transfer(src *model.Resource, dst *model.Resource) error
 }
 
+const (
+	// TransferModeCopy means the blobs are pulled from the source registry and pushed to the destination
+	TransferModeCopy = "copy"
+	// TransferModeMount means the blobs are mounted across repositories in the destination registry
+	// without transferring the data
+	TransferModeMount = "mount"
+)
+
+// Stats is the statistics of the transfer
+type Stats struct {
+	// the transfer mode, "copy" or "mount"
+	TransferMode string `json:"transfer_mode"`
+	// the bytes of the blobs mounted instead of copied
+	MountedBytes int64 `json:"mounted_bytes"`
+	// the bytes of the blobs copied
+	CopiedBytes int64 `json:"copied_bytes"`
+}
+
+// StatsReporter is implemented by the transfers reporting their statistics
+type StatsReporter interface {
+	Stats() *Stats
+}
+
 // Logger defines an interface for logging
 type Logger interface {
 	// For debuging
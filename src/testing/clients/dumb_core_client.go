@@ -15,8 +15,11 @@
 package clients
 
 import (
+	"context"
+
 	"github.com/goharbor/harbor/src/chartserver"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/pkg/clients/core"
 )
 
 // DumbCoreClient provides an empty implement for pkg/clients/core.Client
@@ -28,16 +31,31 @@ func (d *DumbCoreClient) ListAllImages(project, repository string) ([]*models.Ta
 	return nil, nil
 }
 
+// GetImage ...
+func (d *DumbCoreClient) GetImage(project, repository, tag string) (*models.TagResp, error) {
+	return nil, nil
+}
+
 // DeleteImage ...
 func (d *DumbCoreClient) DeleteImage(project, repository, tag string) error {
 	return nil
 }
 
+// DeleteImages ...
+func (d *DumbCoreClient) DeleteImages(project, repository string, tags []string) (*models.TagsDeletionResult, error) {
+	return &models.TagsDeletionResult{Deleted: tags}, nil
+}
+
 // DeleteImageRepository ...
 func (d *DumbCoreClient) DeleteImageRepository(project, repository string) error {
 	return nil
 }
 
+// GetReclaimableSizes ...
+func (d *DumbCoreClient) GetReclaimableSizes(project, repository string, tags []string) (map[string]int64, error) {
+	return nil, nil
+}
+
 // ListAllCharts ...
 func (d *DumbCoreClient) ListAllCharts(project, repository string) ([]*chartserver.ChartVersion, error) {
 	return nil, nil
@@ -52,3 +70,8 @@ func (d *DumbCoreClient) DeleteChart(project, repository, version string) error
 func (d *DumbCoreClient) DeleteChartRepository(project, repository string) error {
 	return nil
 }
+
+// WithContext ...
+func (d *DumbCoreClient) WithContext(ctx context.Context) core.Client {
+	return d
+}
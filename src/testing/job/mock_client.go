@@ -1,8 +1,12 @@
 package job
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
+	nethttp "net/http"
+	"net/http/httptest"
+	"time"
 
 	"github.com/goharbor/harbor/src/common/http"
 	"github.com/goharbor/harbor/src/common/job/models"
@@ -12,6 +16,10 @@ import (
 // MockJobClient ...
 type MockJobClient struct {
 	JobUUID []string
+	// JobLog is the log of the valid jobs, "some log" is used if it's empty
+	JobLog string
+	// DeletedLogs is the UUIDs of the jobs whose logs are deleted
+	DeletedLogs []string
 }
 
 // GetJobLog ...
@@ -20,11 +28,53 @@ func (mjc *MockJobClient) GetJobLog(uuid string) ([]byte, error) {
 		return nil, &http.Error{500, "server side error"}
 	}
 	if mjc.validUUID(uuid) {
-		return []byte("some log"), nil
+		return []byte(mjc.jobLog()), nil
 	}
 	return nil, &http.Error{404, "not Found"}
 }
 
+// GetJobLogStream ...
+func (mjc *MockJobClient) GetJobLogStream(uuid, rangeHeader string) (*nethttp.Response, error) {
+	if uuid == "500" {
+		return nil, &http.Error{Code: 500, Message: "server side error"}
+	}
+	if !mjc.validUUID(uuid) {
+		return nil, &http.Error{Code: 404, Message: "not Found"}
+	}
+	// serve the log as the job service does, the range header is honored
+	req := httptest.NewRequest(nethttp.MethodGet, "/api/v1/jobs/"+uuid+"/log", nil)
+	if len(rangeHeader) > 0 {
+		req.Header.Set("Range", rangeHeader)
+	}
+	rec := httptest.NewRecorder()
+	nethttp.ServeContent(rec, req, "", time.Time{}, bytes.NewReader([]byte(mjc.jobLog())))
+	resp := rec.Result()
+	if resp.StatusCode != nethttp.StatusOK && resp.StatusCode != nethttp.StatusPartialContent {
+		defer resp.Body.Close()
+		return nil, &http.Error{Code: resp.StatusCode, Message: resp.Status}
+	}
+	return resp, nil
+}
+
+// DeleteJobLog ...
+func (mjc *MockJobClient) DeleteJobLog(uuid string) error {
+	if uuid == "500" {
+		return &http.Error{Code: 500, Message: "server side error"}
+	}
+	if !mjc.validUUID(uuid) {
+		return &http.Error{Code: 404, Message: "not Found"}
+	}
+	mjc.DeletedLogs = append(mjc.DeletedLogs, uuid)
+	return nil
+}
+
+func (mjc *MockJobClient) jobLog() string {
+	if len(mjc.JobLog) > 0 {
+		return mjc.JobLog
+	}
+	return "some log"
+}
+
 // SubmitJob ...
 func (mjc *MockJobClient) SubmitJob(data *models.JobData) (string, error) {
 	uuid := fmt.Sprintf("u-%d", rand.Int())
@@ -48,6 +98,17 @@ func (mjc *MockJobClient) GetExecutions(uuid string) ([]job.Stats, error) {
 	return nil, nil
 }
 
+// UpdateJobSchedule ...
+func (mjc *MockJobClient) UpdateJobSchedule(uuid, cron string) error {
+	if "500" == uuid {
+		return &http.Error{Code: 500, Message: "server side error"}
+	}
+	if !mjc.validUUID(uuid) {
+		return &http.Error{Code: 404, Message: "not Found"}
+	}
+	return nil
+}
+
 func (mjc *MockJobClient) validUUID(uuid string) bool {
 	for _, u := range mjc.JobUUID {
 		if uuid == u {